@@ -83,6 +83,18 @@ type BindHandle struct {
 
 	// pendingVerifyBindRecordMap indicates the pending verify bind records that found during query.
 	pendingVerifyBindRecordMap tmpBindRecordMap
+
+	// bindingHits records how many times the bindings are used, keyed by the bind SQL.
+	bindingHits struct {
+		sync.Mutex
+		hits map[string]*BindingHit
+	}
+}
+
+// BindingHit is the usage of a global binding.
+type BindingHit struct {
+	Count       int64
+	LastHitTime time.Time
 }
 
 // Lease influences the duration of loading bind info and handling invalid bind.
@@ -108,6 +120,7 @@ func NewBindHandle(ctx sessionctx.Context) *BindHandle {
 	handle.sctx.Context = ctx
 	handle.bindInfo.Value.Store(make(cache, 32))
 	handle.bindInfo.parser = parser.New()
+	handle.bindingHits.hits = make(map[string]*BindingHit)
 	handle.invalidBindRecordMap.Value.Store(make(map[string]*bindRecordUpdate))
 	handle.invalidBindRecordMap.flushFunc = func(record *BindRecord) error {
 		return handle.DropBindRecord(record.OriginalSQL, record.Db, &record.Bindings[0])
@@ -538,6 +551,29 @@ func (h *BindHandle) GetAllBindRecord() (bindRecords []*BindRecord) {
 	return bindRecords
 }
 
+// RecordBindingHit records that the binding is used to build the plan.
+func (h *BindHandle) RecordBindingHit(bindSQL string) {
+	h.bindingHits.Lock()
+	defer h.bindingHits.Unlock()
+	hit, ok := h.bindingHits.hits[bindSQL]
+	if !ok {
+		hit = &BindingHit{}
+		h.bindingHits.hits[bindSQL] = hit
+	}
+	hit.Count++
+	hit.LastHitTime = time.Now()
+}
+
+// GetBindingHit returns the usage of the binding.
+func (h *BindHandle) GetBindingHit(bindSQL string) BindingHit {
+	h.bindingHits.Lock()
+	defer h.bindingHits.Unlock()
+	if hit, ok := h.bindingHits.hits[bindSQL]; ok {
+		return *hit
+	}
+	return BindingHit{}
+}
+
 // newBindRecord builds BindRecord from a tuple in storage.
 func (h *BindHandle) newBindRecord(row chunk.Row) (string, *BindRecord, error) {
 	hint := Binding{
@@ -589,7 +625,25 @@ func (h *BindHandle) removeBindRecord(hash string, meta *BindRecord) {
 	oldRecord := newCache.getBindRecord(hash, meta.OriginalSQL, meta.Db)
 	newCache.removeDeletedBindRecord(hash, meta)
 	h.bindInfo.Value.Store(newCache)
-	updateMetrics(metrics.ScopeGlobal, oldRecord, newCache.getBindRecord(hash, meta.OriginalSQL, meta.Db), false)
+	newRecord := newCache.getBindRecord(hash, meta.OriginalSQL, meta.Db)
+	updateMetrics(metrics.ScopeGlobal, oldRecord, newRecord, false)
+	if oldRecord == nil {
+		return
+	}
+	// Forget the usage of the removed bindings.
+	remained := make(map[string]struct{})
+	if newRecord != nil {
+		for _, binding := range newRecord.Bindings {
+			remained[binding.BindSQL] = struct{}{}
+		}
+	}
+	h.bindingHits.Lock()
+	for _, binding := range oldRecord.Bindings {
+		if _, ok := remained[binding.BindSQL]; !ok {
+			delete(h.bindingHits.hits, binding.BindSQL)
+		}
+	}
+	h.bindingHits.Unlock()
 }
 
 // removeDeletedBindRecord removes the BindRecord which has same originSQL and db with specified BindRecord.
@@ -106,7 +106,10 @@ type Config struct {
 	OOMUseTmpStorage bool   `toml:"oom-use-tmp-storage" json:"oom-use-tmp-storage"`
 	TempStoragePath  string `toml:"tmp-storage-path" json:"tmp-storage-path"`
 	OOMAction        string `toml:"oom-action" json:"oom-action"`
-	MemQuotaQuery    int64  `toml:"mem-quota-query" json:"mem-quota-query"`
+	// OOMDiagnosticFiles is the number of the diagnostic files kept for the queries cancelled by the oom-action,
+	// 0 means no file is dumped.
+	OOMDiagnosticFiles int   `toml:"oom-diagnostic-files" json:"oom-diagnostic-files"`
+	MemQuotaQuery      int64 `toml:"mem-quota-query" json:"mem-quota-query"`
 	// TempStorageQuota describe the temporary storage Quota during query exector when OOMUseTmpStorage is enabled
 	// If the quota exceed the capacity of the TempStoragePath, the tidb-server would exit with fatal error
 	TempStorageQuota int64 `toml:"tmp-storage-quota" json:"tmp-storage-quota"` // Bytes
@@ -125,6 +128,7 @@ type Config struct {
 	PreparedPlanCache          PreparedPlanCache  `toml:"prepared-plan-cache" json:"prepared-plan-cache"`
 	OpenTracing                OpenTracing        `toml:"opentracing" json:"opentracing"`
 	ProxyProtocol              ProxyProtocol      `toml:"proxy-protocol" json:"proxy-protocol"`
+	XProtocol                  XProtocol          `toml:"x-protocol" json:"x-protocol"`
 	PDClient                   tikvcfg.PDClient   `toml:"pd-client" json:"pd-client"`
 	TiKVClient                 tikvcfg.TiKVClient `toml:"tikv-client" json:"tikv-client"`
 	Binlog                     Binlog             `toml:"binlog" json:"binlog"`
@@ -154,6 +158,11 @@ type Config struct {
 	IsolationRead IsolationRead `toml:"isolation-read" json:"isolation-read"`
 	// MaxServerConnections is the maximum permitted number of simultaneous client connections.
 	MaxServerConnections uint32 `toml:"max-server-connections" json:"max-server-connections"`
+	// AllowedStmtClasses restricts the tidb-server to execute only the statements of the classes, regardless of the
+	// privileges of the users. All the statements are allowed if it's empty.
+	AllowedStmtClasses []string `toml:"allowed-statement-classes" json:"allowed-statement-classes"`
+	// AdmissionControl limits the number of the interactive and batch statements executed at the same time.
+	AdmissionControl AdmissionControl `toml:"admission-control" json:"admission-control"`
 	// NewCollationsEnabledOnFirstBootstrap indicates if the new collations are enabled, it effects only when a TiDB cluster bootstrapped on the first time.
 	NewCollationsEnabledOnFirstBootstrap bool `toml:"new_collations_enabled_on_first_bootstrap" json:"new_collations_enabled_on_first_bootstrap"`
 	// Experimental contains parameters for experimental features.
@@ -367,6 +376,14 @@ type Log struct {
 	ExpensiveThreshold  uint       `toml:"expensive-threshold" json:"expensive-threshold"`
 	QueryLogMaxLen      uint64     `toml:"query-log-max-len" json:"query-log-max-len"`
 	RecordPlanInSlowLog uint32     `toml:"record-plan-in-slow-log" json:"record-plan-in-slow-log"`
+	// SlowQueryFormat is the format of slow query entries, one of text or json.
+	SlowQueryFormat string `toml:"slow-query-format" json:"slow-query-format"`
+	// SlowQueryCollectorURL is the HTTP or gRPC (grpc://host:port) endpoint that slow query entries are pushed to in json,
+	// empty means disabled.
+	SlowQueryCollectorURL string `toml:"slow-query-collector-url" json:"slow-query-collector-url"`
+	// SlowQueryCollectorBufferSize is the max number of slow query entries buffered for the collector.
+	// Entries are dropped if the buffer is full.
+	SlowQueryCollectorBufferSize uint `toml:"slow-query-collector-buffer-size" json:"slow-query-collector-buffer-size"`
 }
 
 func (l *Log) getDisableTimestamp() bool {
@@ -419,6 +436,8 @@ type Security struct {
 	MinTLSVersion   string `toml:"tls-version" json:"tls-version"`
 	RSAKeySize      int    `toml:"rsa-key-size" json:"rsa-key-size"`
 	SecureBootstrap bool   `toml:"secure-bootstrap" json:"secure-bootstrap"`
+	// ForeignServerKeyPath is the path of the file whose content is the key to encrypt the passwords of the foreign servers.
+	ForeignServerKeyPath string `toml:"foreign-server-key-path" json:"foreign-server-key-path"`
 }
 
 // The ErrConfigValidationFailed error is used so that external callers can do a type assertion
@@ -450,6 +469,12 @@ type Status struct {
 	MetricsInterval uint   `toml:"metrics-interval" json:"metrics-interval"`
 	ReportStatus    bool   `toml:"report-status" json:"report-status"`
 	RecordQPSbyDB   bool   `toml:"record-db-qps" json:"record-db-qps"`
+	// MetricsLabelValueLimit is the max number of distinct values of each high cardinality label like the store
+	// address, the values beyond the limit are aggregated into "other". 0 means no limit.
+	MetricsLabelValueLimit int64 `toml:"metrics-label-value-limit" json:"metrics-label-value-limit"`
+	// MetricsHistogramSampleRate is the rate of the statements that are observed by the sampled histograms.
+	// 0 disables the sampled histograms.
+	MetricsHistogramSampleRate float64 `toml:"metrics-histogram-sample-rate" json:"metrics-histogram-sample-rate"`
 	// After a duration of this time in seconds if the server doesn't see any activity it pings
 	// the client to see if the transport is still alive.
 	GRPCKeepAliveTime uint `toml:"grpc-keepalive-time" json:"grpc-keepalive-time"`
@@ -495,6 +520,18 @@ type Performance struct {
 	EnforceMPP           bool   `toml:"enforce-mpp" json:"enforce-mpp"`
 	StatsLoadConcurrency uint   `toml:"stats-load-concurrency" json:"stats-load-concurrency"`
 	StatsLoadQueueSize   uint   `toml:"stats-load-queue-size" json:"stats-load-queue-size"`
+
+	// ProtocolCompression allows the clients to negotiate the compressed MySQL protocol, zlib or zstd.
+	ProtocolCompression bool `toml:"protocol-compression" json:"protocol-compression"`
+	// ProtocolCompressionLevel is the zlib level of the compressed protocol, the zstd level is chosen by the client.
+	ProtocolCompressionLevel int `toml:"protocol-compression-level" json:"protocol-compression-level"`
+
+	// MaxBatchCopQueries limits the number of the batch cop requests running in the tidb-server at the same time,
+	// the others wait in a queue by their priorities. 0 means no limit.
+	MaxBatchCopQueries uint `toml:"max-batch-cop-queries" json:"max-batch-cop-queries"`
+	// BatchCopQueueSize limits the number of the batch cop requests waiting in the queue, the others are rejected.
+	// 0 means no limit.
+	BatchCopQueueSize uint `toml:"batch-cop-queue-size" json:"batch-cop-queue-size"`
 }
 
 // PlanCache is the PlanCache section of the config.
@@ -548,6 +585,15 @@ type ProxyProtocol struct {
 	HeaderTimeout uint `toml:"header-timeout" json:"header-timeout"`
 }
 
+// XProtocol is the X Protocol section of the config.
+type XProtocol struct {
+	// Enable starts the listener of the X Protocol for the document store clients. The X Protocol doesn't support
+	// TLS, so it can't be enabled together with require-secure-transport.
+	Enable bool `toml:"enable" json:"enable"`
+	// Port is the port of the X Protocol, the listener uses the same host as the MySQL protocol.
+	Port uint `toml:"port" json:"port"`
+}
+
 // Binlog is the config for binlog.
 type Binlog struct {
 	Enable bool `toml:"enable" json:"enable"`
@@ -561,6 +607,19 @@ type Binlog struct {
 	Strategy string `toml:"strategy" json:"strategy"`
 }
 
+// AdmissionControl is the config for the admission queue of the statements. The statements are classified into the
+// interactive and batch classes, and the statements over the max concurrency of their class wait in the queue.
+type AdmissionControl struct {
+	// BatchCostThreshold is the estimated cost of the plan from which a statement is regarded as a batch one,
+	// unless it's classified by the QUERY_TYPE hint.
+	BatchCostThreshold float64 `toml:"batch-cost-threshold" json:"batch-cost-threshold"`
+	// MaxInteractiveConcurrency is the max number of the interactive statements executed at the same time,
+	// 0 means unlimited.
+	MaxInteractiveConcurrency uint `toml:"max-interactive-concurrency" json:"max-interactive-concurrency"`
+	// MaxBatchConcurrency is the max number of the batch statements executed at the same time, 0 means unlimited.
+	MaxBatchConcurrency uint `toml:"max-batch-concurrency" json:"max-batch-concurrency"`
+}
+
 // PessimisticTxn is the config for pessimistic transaction.
 type PessimisticTxn struct {
 	// The max count of retry for a single statement in a pessimistic transaction.
@@ -644,6 +703,7 @@ var defaultConf = Config{
 	RepairMode:                   false,
 	RepairTableList:              []string{},
 	MaxServerConnections:         0,
+	AllowedStmtClasses:           []string{},
 	TxnLocalLatches:              defTiKVCfg.TxnLocalLatches,
 	LowerCaseTableNames:          2,
 	GracefulWaitBeforeShutdown:   0,
@@ -662,18 +722,22 @@ var defaultConf = Config{
 		QueryLogMaxLen:      logutil.DefaultQueryLogMaxLen,
 		RecordPlanInSlowLog: logutil.DefaultRecordPlanInSlowLog,
 		EnableSlowLog:       *NewAtomicBool(logutil.DefaultTiDBEnableSlowLog),
+
+		SlowQueryFormat:              logutil.SlowLogFormatText,
+		SlowQueryCollectorBufferSize: logutil.DefaultSlowQueryCollectorBufferSize,
 	},
 	Status: Status{
-		ReportStatus:          true,
-		StatusHost:            DefStatusHost,
-		StatusPort:            DefStatusPort,
-		MetricsInterval:       15,
-		RecordQPSbyDB:         false,
-		GRPCKeepAliveTime:     10,
-		GRPCKeepAliveTimeout:  3,
-		GRPCConcurrentStreams: 1024,
-		GRPCInitialWindowSize: 2 * 1024 * 1024,
-		GRPCMaxSendMsgSize:    10 * 1024 * 1024,
+		ReportStatus:               true,
+		StatusHost:                 DefStatusHost,
+		StatusPort:                 DefStatusPort,
+		MetricsInterval:            15,
+		RecordQPSbyDB:              false,
+		MetricsHistogramSampleRate: 0,
+		GRPCKeepAliveTime:          10,
+		GRPCKeepAliveTimeout:       3,
+		GRPCConcurrentStreams:      1024,
+		GRPCInitialWindowSize:      2 * 1024 * 1024,
+		GRPCMaxSendMsgSize:         10 * 1024 * 1024,
 	},
 	Performance: Performance{
 		MaxMemory:             0,
@@ -702,11 +766,21 @@ var defaultConf = Config{
 		PlanReplayerGCLease:  "10m",
 		StatsLoadConcurrency: 5,
 		StatsLoadQueueSize:   1000,
+
+		ProtocolCompression:      false,
+		ProtocolCompressionLevel: 6,
+
+		MaxBatchCopQueries: 0,
+		BatchCopQueueSize:  0,
 	},
 	ProxyProtocol: ProxyProtocol{
 		Networks:      "",
 		HeaderTimeout: 5,
 	},
+	XProtocol: XProtocol{
+		Enable: false,
+		Port:   33060,
+	},
 	PreparedPlanCache: PreparedPlanCache{
 		Enabled:          false,
 		Capacity:         1000,
@@ -731,6 +805,9 @@ var defaultConf = Config{
 		Load: "",
 	},
 	PessimisticTxn: DefaultPessimisticTxn(),
+	AdmissionControl: AdmissionControl{
+		BatchCostThreshold: 1e7,
+	},
 	IsolationRead: IsolationRead{
 		Engines: []string{"tikv", "tiflash", "tidb"},
 	},
@@ -900,6 +977,9 @@ func (c *Config) Valid() error {
 		// if two options conflict, we will use the value of EnableTimestamp
 		c.Log.DisableTimestamp = nbUnset
 	}
+	if c.Log.SlowQueryFormat != logutil.SlowLogFormatText && c.Log.SlowQueryFormat != logutil.SlowLogFormatJSON {
+		return fmt.Errorf("slow-query-format should be one of %s and %s", logutil.SlowLogFormatText, logutil.SlowLogFormatJSON)
+	}
 	if c.Security.SkipGrantTable && !hasRootPrivilege() {
 		return fmt.Errorf("TiDB run with skip-grant-table need root privilege")
 	}
@@ -921,6 +1001,12 @@ func (c *Config) Valid() error {
 	if c.IndexLimit < DefIndexLimit || c.IndexLimit > DefMaxOfIndexLimit {
 		return fmt.Errorf("index-limit should be [%d, %d]", DefIndexLimit, DefMaxOfIndexLimit)
 	}
+	if err := c.Status.validMetricsCardinality(); err != nil {
+		return err
+	}
+	if c.Performance.ProtocolCompressionLevel < 1 || c.Performance.ProtocolCompressionLevel > 9 {
+		return fmt.Errorf("protocol-compression-level should be [1, 9]")
+	}
 	if c.Log.File.MaxSize > MaxLogFileSize {
 		return fmt.Errorf("invalid max log file size=%v which is larger than max=%v", c.Log.File.MaxSize, MaxLogFileSize)
 	}
@@ -970,6 +1056,16 @@ func (c *Config) Valid() error {
 		}
 	}
 
+	for _, class := range c.AllowedStmtClasses {
+		if !isValidStmtClass(class) {
+			return fmt.Errorf("allowed-statement-classes can't contain %v, it should be one of %s", class, strings.Join(stmtClasses, ", "))
+		}
+	}
+
+	if c.AdmissionControl.BatchCostThreshold < 0 {
+		return fmt.Errorf("admission-control.batch-cost-threshold should not be negative")
+	}
+
 	// test security
 	c.Security.SpilledFileEncryptionMethod = strings.ToLower(c.Security.SpilledFileEncryptionMethod)
 	switch c.Security.SpilledFileEncryptionMethod {
@@ -1000,6 +1096,54 @@ func UpdateGlobal(f func(conf *Config)) {
 	StoreGlobalConfig(&newConf)
 }
 
+// onlineChangeableItems are the config items that can be changed by `SET CONFIG tidb` without restarting TiDB.
+var onlineChangeableItems = map[string]func(conf *Config, v float64){
+	"status.metrics-label-value-limit": func(conf *Config, v float64) {
+		conf.Status.MetricsLabelValueLimit = int64(v)
+	},
+	"status.metrics-histogram-sample-rate": func(conf *Config, v float64) {
+		conf.Status.MetricsHistogramSampleRate = v
+	},
+}
+
+// IsOnlineChangeable returns whether the config item can be changed by `SET CONFIG tidb`.
+func IsOnlineChangeable(item string) bool {
+	_, ok := onlineChangeableItems[item]
+	return ok
+}
+
+// UpdateOnline changes the items of the global config by `SET CONFIG tidb`, the items are in the JSON format that
+// is sent by SET CONFIG, e.g. {"status.metrics-label-value-limit":100}.
+func UpdateOnline(items map[string]interface{}) error {
+	newConf := *GetGlobalConfig()
+	for item, v := range items {
+		update, ok := onlineChangeableItems[item]
+		if !ok {
+			return fmt.Errorf("config item %s can't be changed online", item)
+		}
+		val, ok := v.(float64)
+		if !ok {
+			return fmt.Errorf("invalid value %v of config item %s", v, item)
+		}
+		update(&newConf, val)
+	}
+	if err := newConf.Status.validMetricsCardinality(); err != nil {
+		return err
+	}
+	StoreGlobalConfig(&newConf)
+	return nil
+}
+
+func (s *Status) validMetricsCardinality() error {
+	if s.MetricsLabelValueLimit < 0 {
+		return fmt.Errorf("metrics-label-value-limit in [status] should be at least 0")
+	}
+	if s.MetricsHistogramSampleRate < 0 || s.MetricsHistogramSampleRate > 1 {
+		return fmt.Errorf("metrics-histogram-sample-rate in [status] should be [0, 1]")
+	}
+	return nil
+}
+
 // RestoreFunc gets a function that restore the config to the current value.
 func RestoreFunc() (restore func()) {
 	g := GetGlobalConfig()
@@ -1024,7 +1168,11 @@ var TableLockDelayClean = func() uint64 {
 
 // ToLogConfig converts *Log to *logutil.LogConfig.
 func (l *Log) ToLogConfig() *logutil.LogConfig {
-	return logutil.NewLogConfig(l.Level, l.Format, l.SlowQueryFile, l.File, l.getDisableTimestamp(), func(config *zaplog.Config) { config.DisableErrorVerbose = l.getDisableErrorStack() })
+	c := logutil.NewLogConfig(l.Level, l.Format, l.SlowQueryFile, l.File, l.getDisableTimestamp(), func(config *zaplog.Config) { config.DisableErrorVerbose = l.getDisableErrorStack() })
+	c.SlowQueryFormat = l.SlowQueryFormat
+	c.SlowQueryCollectorURL = l.SlowQueryCollectorURL
+	c.SlowQueryCollectorBufferSize = l.SlowQueryCollectorBufferSize
+	return c
 }
 
 // ToTracingConfig converts *OpenTracing to *tracing.Configuration.
@@ -1072,6 +1220,36 @@ const (
 	OOMActionLog    = "log"
 )
 
+// The following constants represents the classes of the statements in the configuration AllowedStmtClasses.
+const (
+	// StmtClassRead is the read-only queries, like SELECT without locking the rows.
+	StmtClassRead = "read"
+	// StmtClassExplain is the EXPLAIN statements, EXPLAIN ANALYZE is the class of the explained statement.
+	StmtClassExplain = "explain"
+	// StmtClassShow is the SHOW statements.
+	StmtClassShow = "show"
+	// StmtClassSession is the statements only changing the session state, like USE, SET SESSION, BEGIN and PREPARE.
+	StmtClassSession = "session"
+	// StmtClassDML is the statements writing the data, like INSERT, UPDATE and SELECT FOR UPDATE.
+	StmtClassDML = "dml"
+	// StmtClassDDL is the statements changing the schema.
+	StmtClassDDL = "ddl"
+	// StmtClassAdmin is all the other statements, like GRANT, ANALYZE, ADMIN and SET GLOBAL.
+	StmtClassAdmin = "admin"
+)
+
+var stmtClasses = []string{StmtClassRead, StmtClassExplain, StmtClassShow, StmtClassSession, StmtClassDML, StmtClassDDL, StmtClassAdmin}
+
+// isValidStmtClass checks whether the class is one of the statement classes.
+func isValidStmtClass(class string) bool {
+	for _, c := range stmtClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
 // hideConfig is used to filter a single line of config for hiding.
 var hideConfig = []string{
 	"index-usage-sync-lease",
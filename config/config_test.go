@@ -493,6 +493,24 @@ func TestOOMActionValid(t *testing.T) {
 	}
 }
 
+func TestAllowedStmtClassesValid(t *testing.T) {
+	conf := NewConfig()
+	tests := []struct {
+		classes []string
+		valid   bool
+	}{
+		{nil, true},
+		{[]string{"read", "explain", "show", "session"}, true},
+		{[]string{"dml", "ddl", "admin"}, true},
+		{[]string{"read", "write"}, false},
+		{[]string{"Read"}, false},
+	}
+	for _, tt := range tests {
+		conf.AllowedStmtClasses = tt.classes
+		require.Equal(t, tt.valid, conf.Valid() == nil)
+	}
+}
+
 func TestTxnTotalSizeLimitValid(t *testing.T) {
 	conf := NewConfig()
 	tests := []struct {
@@ -683,3 +701,14 @@ func TestStatsLoadLimit(t *testing.T) {
 	checkQueueSizeValid(DefMaxOfStatsLoadQueueSizeLimit, true)
 	checkQueueSizeValid(DefMaxOfStatsLoadQueueSizeLimit+1, false)
 }
+
+func TestSlowQueryFormat(t *testing.T) {
+	conf := NewConfig()
+	checkValid := func(format string, shouldBeValid bool) {
+		conf.Log.SlowQueryFormat = format
+		require.Equal(t, shouldBeValid, conf.Valid() == nil)
+	}
+	checkValid(logutil.SlowLogFormatText, true)
+	checkValid(logutil.SlowLogFormatJSON, true)
+	checkValid("yaml", false)
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math"
 	"strconv"
 	"sync/atomic"
 	"time"
@@ -31,6 +32,7 @@ import (
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/clustersetting"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/store/copr"
 	"github.com/pingcap/tidb/store/driver/backoff"
@@ -39,8 +41,10 @@ import (
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/logutil"
 	decoder "github.com/pingcap/tidb/util/rowDecoder"
+	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/tikv/client-go/v2/tikv"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
 type backfillWorkerType byte
@@ -135,6 +139,7 @@ type backfillTaskContext struct {
 	done          bool
 	addedCount    int
 	scanCount     int
+	writtenBytes  int
 	warnings      map[errors.ErrorID]*terror.Error
 	warningsCount map[errors.ErrorID]int64
 }
@@ -250,6 +255,10 @@ func (w *backfillWorker) handleBackfillTask(d *ddlCtx, task *reorgBackfillTask,
 
 		bf.AddMetricInfo(float64(taskCtx.addedCount))
 		mergeBackfillCtxToResult(&taskCtx, result)
+		if err = w.waitWriteQuota(d, taskCtx.writtenBytes); err != nil {
+			result.err = err
+			return result
+		}
 
 		// Although `handleRange` is for data in one region, but back fill worker still split it into many
 		// small reorg batch size slices and reorg them in many different kv txn.
@@ -287,6 +296,32 @@ func (w *backfillWorker) handleBackfillTask(d *ddlCtx, task *reorgBackfillTask,
 	return result
 }
 
+// waitWriteQuota blocks until the bytes written by the worker are allowed by the reorg write limiter.
+// The limiter is shared by all the backfill workers of the DDL owner, which is the only TiDB running the
+// reorg jobs, so the limit applies to the whole cluster.
+func (w *backfillWorker) waitWriteQuota(d *ddlCtx, n int) error {
+	limiter := d.reorgWriteLimiter
+	for n > 0 && limiter.Limit() != rate.Inf {
+		// WaitN fails if n exceeds the burst, which is the bytes allowed in one second.
+		m := n
+		if burst := limiter.Burst(); m > burst {
+			m = burst
+		}
+		if err := limiter.WaitN(w.ddlWorker.ctx, m); err != nil {
+			// The burst may be decreased concurrently, check the worker and try again.
+			if err := w.ddlWorker.isReorgRunnable(d); err != nil {
+				return err
+			}
+			continue
+		}
+		n -= m
+		if err := w.ddlWorker.isReorgRunnable(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (w *backfillWorker) run(d *ddlCtx, bf backfiller, job *model.Job) {
 	logutil.BgLogger().Info("[ddl] backfill worker start", zap.Int("workerID", w.id))
 	defer func() {
@@ -508,6 +543,56 @@ var (
 	TestCheckReorgTimeout = int32(0)
 )
 
+// reorgMaxWriteSpeed limits the bytes written by the backfill workers per second, 0 means no limit.
+var reorgMaxWriteSpeed = &clustersetting.Setting{
+	Name:     "tidb_ddl_reorg_max_write_speed",
+	Type:     clustersetting.TypeInt,
+	Default:  "0",
+	MaxValue: math.MaxInt32,
+	Comment:  "The max bytes written by the DDL reorg workers per second, 0 means no limit.",
+}
+
+func init() {
+	clustersetting.Register(reorgMaxWriteSpeed)
+}
+
+// setReorgWriteSpeed sets the rate and the burst of the limiter to the bytes per second.
+func setReorgWriteSpeed(limiter *rate.Limiter, speed int64) {
+	if speed <= 0 {
+		limiter.SetLimit(rate.Inf)
+		return
+	}
+	limiter.SetLimit(rate.Limit(speed))
+	limiter.SetBurst(int(speed))
+}
+
+// loadReorgWriteSpeed loads the max write speed of the backfill workers, so it can be adjusted when the job is running.
+func loadReorgWriteSpeed(w *worker, d *ddlCtx) error {
+	ctx, err := w.sessPool.get()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer w.sessPool.put(ctx)
+	executor, ok := ctx.(sqlexec.RestrictedSQLExecutor)
+	// `mock.Context` is used in tests, which doesn't implement RestrictedSQLExecutor
+	if !ok {
+		return nil
+	}
+	val, ok, err := clustersetting.Load(w.ddlJobCtx, executor, reorgMaxWriteSpeed.Name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !ok {
+		val = reorgMaxWriteSpeed.Default
+	}
+	speed, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	setReorgWriteSpeed(d.reorgWriteLimiter, speed)
+	return nil
+}
+
 func loadDDLReorgVars(w *worker) error {
 	// Get sessionctx from context resource pool.
 	var ctx sessionctx.Context
@@ -591,6 +676,9 @@ func (w *worker) writePhysicalTableRecord(t table.PhysicalTable, bfWorkerType ba
 		if err := loadDDLReorgVars(w); err != nil {
 			logutil.BgLogger().Error("[ddl] load DDL reorganization variable failed", zap.Error(err))
 		}
+		if err := loadReorgWriteSpeed(w, reorgInfo.d); err != nil {
+			logutil.BgLogger().Error("[ddl] load DDL reorganization write speed failed", zap.Error(err))
+		}
 		workerCnt = variable.GetDDLReorgWorkerCounter()
 		rowFormat := variable.GetDDLReorgRowFormat()
 		// If only have 1 range, we can only start 1 worker.
@@ -0,0 +1,53 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/owner"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestBackfillWriteQuota(t *testing.T) {
+	manager := owner.NewMockManager(context.Background(), "test")
+	require.NoError(t, manager.CampaignOwner())
+	d := &ddlCtx{ownerManager: manager, reorgWriteLimiter: rate.NewLimiter(rate.Inf, 0)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &backfillWorker{ddlWorker: &worker{ctx: ctx, reorgCtx: &reorgCtx{}}}
+
+	// No limit by default.
+	start := time.Now()
+	require.NoError(t, w.waitWriteQuota(d, 1<<30))
+	require.Less(t, time.Since(start), time.Second)
+
+	// The bytes exceeding the burst are waited for in pieces.
+	setReorgWriteSpeed(d.reorgWriteLimiter, 1000)
+	require.Equal(t, 1000, d.reorgWriteLimiter.Burst())
+	start = time.Now()
+	require.NoError(t, w.waitWriteQuota(d, 1500))
+	require.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+
+	// The waiting stops when the job can't run anymore.
+	w.ddlWorker.reorgCtx.notifyReorgCancel()
+	require.ErrorIs(t, w.waitWriteQuota(d, 5000), errCancelledDDLJob)
+
+	setReorgWriteSpeed(d.reorgWriteLimiter, 0)
+	require.Equal(t, rate.Inf, d.reorgWriteLimiter.Limit())
+}
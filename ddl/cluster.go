@@ -0,0 +1,418 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/failpoint"
+	"github.com/pingcap/tidb/domain/infosync"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+// pdScheduleKeys are the items of the PD schedule config which are set to 0 during the flashback, so the regions
+// are not moved, merged or split by PD while their data is rewritten.
+var pdScheduleKeys = []string{
+	"hot-region-schedule-limit",
+	"leader-schedule-limit",
+	"merge-schedule-limit",
+	"region-schedule-limit",
+	"replica-schedule-limit",
+}
+
+// flashbackBatchSize is the number of the keys rewritten in a transaction.
+var flashbackBatchSize = 1024
+
+const (
+	flashbackGCFlagIndexInJobArgs     = 1
+	flashbackPDScheduleIndexInJobArgs = 2
+	flashbackReadOnlyIndexInJobArgs   = 3
+	flashbackKeyRangesIndexInJobArgs  = 4
+)
+
+// FlashbackCluster flashbacks the data and the schemas of the user databases to flashbackTS.
+func (d *ddl) FlashbackCluster(ctx sessionctx.Context, flashbackTS uint64) error {
+	job := &model.Job{
+		Type:       model.ActionFlashbackCluster,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{flashbackTS, recoverTableCheckFlagNone, map[string]interface{}{}, "", []kv.KeyRange{}},
+	}
+	err := d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// Flashback cluster divides into 3 steps like recover table, so the GC, the PD schedules and the read-only mode
+// are changed only after their states are saved in the job:
+//  1. Check the flashback timestamp and the running DDL jobs, save the states of the GC, the PD schedules and
+//     tidb_super_read_only.
+//  2. Pause the GC and the PD schedules, make the cluster read-only by tidb_super_read_only so the user writes are
+//     blocked until the job finishes, and save the key ranges of the user databases to rewrite.
+//  3. Rewrite the keys of the ranges to their values at the flashback timestamp, and update the schema version so
+//     the schemas are loaded again. The GC, the PD schedules and the read-only mode are restored once the job is
+//     finished, see finishFlashbackCluster.
+//
+// TiKV doesn't provide a way to rewrite the data of the regions to a version, so the keys are rewritten by the
+// transactions of TiDB. Like the reorg jobs, the rewrite runs for at most ReorgWaitTimeout each time, and the ranges
+// not rewritten yet are saved in the job, so the new owner resumes from them.
+func (w *worker) onFlashbackCluster(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64, err error) {
+	var flashbackTS uint64
+	var gcFlag int64
+	var pdSchedule map[string]interface{}
+	var readOnly string
+	var keyRanges []kv.KeyRange
+	if err = job.DecodeArgs(&flashbackTS, &gcFlag, &pdSchedule, &readOnly, &keyRanges); err != nil {
+		// Invalid arguments, cancel this job.
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	switch job.SchemaState {
+	case model.StateNone:
+		// none -> write only
+		if err = checkFlashbackCluster(w, d, t, job, flashbackTS); err != nil {
+			job.State = model.JobStateCancelled
+			return ver, errors.Trace(err)
+		}
+		gcEnable, err := checkGCEnable(w)
+		if err != nil {
+			job.State = model.JobStateCancelled
+			return ver, errors.Trace(err)
+		}
+		if gcEnable {
+			job.Args[flashbackGCFlagIndexInJobArgs] = recoverTableCheckFlagEnableGC
+		} else {
+			job.Args[flashbackGCFlagIndexInJobArgs] = recoverTableCheckFlagDisableGC
+		}
+		pdSchedule, err = getPDSchedule(w.ddlJobCtx)
+		if err != nil {
+			job.State = model.JobStateCancelled
+			return ver, errors.Wrapf(err, "failed to get the schedule config from PD")
+		}
+		job.Args[flashbackPDScheduleIndexInJobArgs] = pdSchedule
+		readOnly, err = getTiDBSuperReadOnly(w)
+		if err != nil {
+			job.State = model.JobStateCancelled
+			return ver, errors.Trace(err)
+		}
+		job.Args[flashbackReadOnlyIndexInJobArgs] = readOnly
+		job.SchemaState = model.StateWriteOnly
+	case model.StateWriteOnly:
+		// write only -> write reorganization
+		if gcFlag == recoverTableCheckFlagEnableGC {
+			if err = disableGC(w); err != nil {
+				return ver, errors.Errorf("disable gc failed, try again later. err: %v", err)
+			}
+		}
+		if err = pausePDSchedule(w.ddlJobCtx); err != nil {
+			return ver, errors.Wrapf(err, "failed to pause the PD schedules")
+		}
+		if err = setTiDBSuperReadOnly(w, variable.On); err != nil {
+			return ver, errors.Trace(err)
+		}
+		// The GC is disabled now, the safe point can't pass the flashback timestamp any more.
+		if err = checkSafePoint(w, flashbackTS); err != nil {
+			return ver, errors.Trace(err)
+		}
+		// The ranges are saved before any key is rewritten, since the tables found by the current schemas change
+		// once the meta keys are rewritten.
+		keyRanges, err = getFlashbackKeyRanges(d, t, flashbackTS)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		job.Args[flashbackKeyRangesIndexInJobArgs] = keyRanges
+		job.SchemaState = model.StateWriteReorganization
+	case model.StateWriteReorganization:
+		// write reorganization -> public
+		if len(keyRanges) > 0 {
+			// The ranges left are saved even if the rewrite fails, the job is retried from them.
+			keyRanges, err = flashbackKeyRanges(w.ddlJobCtx, d.store, keyRanges, flashbackTS, ReorgWaitTimeout)
+			job.Args[flashbackKeyRangesIndexInJobArgs] = keyRanges
+			if err != nil {
+				return ver, errors.Trace(err)
+			}
+			if len(keyRanges) > 0 {
+				return ver, nil
+			}
+		}
+		failpoint.Inject("mockFlashbackClusterErr", func(val failpoint.Value) {
+			if val.(bool) {
+				failpoint.Return(ver, errors.New("mock flashback cluster error"))
+			}
+		})
+		ver, err = updateSchemaVersion(t, job)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		job.FinishDBJob(model.JobStateDone, model.StatePublic, ver, nil)
+	default:
+		return ver, ErrInvalidDDLState.GenWithStackByArgs("flashback cluster", job.SchemaState)
+	}
+	return ver, nil
+}
+
+// checkFlashbackCluster checks whether the cluster can be flashed back to flashbackTS.
+func checkFlashbackCluster(w *worker, d *ddlCtx, t *meta.Meta, job *model.Job, flashbackTS uint64) error {
+	currentVer, err := d.store.CurrentVersion(kv.GlobalTxnScope)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if flashbackTS >= currentVer.Ver {
+		return errors.Errorf("can't flashback cluster to a future timestamp %d", flashbackTS)
+	}
+	if err = checkSafePoint(w, flashbackTS); err != nil {
+		return errors.Trace(err)
+	}
+	// The other DDL jobs may be broken by the schemas flashed back.
+	for _, listKey := range []meta.JobListKeyType{meta.DefaultJobListKey, meta.AddIndexJobListKey} {
+		jobs, err := t.GetAllDDLJobsInQueue(listKey)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, other := range jobs {
+			if other.ID != job.ID {
+				return errors.Errorf("can't flashback cluster while the DDL job %d is running", other.ID)
+			}
+		}
+	}
+	return nil
+}
+
+func getPDSchedule(ctx context.Context) (map[string]interface{}, error) {
+	config, err := infosync.GetPDScheduleConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	saved := make(map[string]interface{}, len(pdScheduleKeys))
+	for _, key := range pdScheduleKeys {
+		if v, ok := config[key]; ok {
+			saved[key] = v
+		}
+	}
+	return saved, nil
+}
+
+func pausePDSchedule(ctx context.Context) error {
+	config := make(map[string]interface{}, len(pdScheduleKeys))
+	for _, key := range pdScheduleKeys {
+		config[key] = 0
+	}
+	return infosync.SetPDScheduleConfig(ctx, config)
+}
+
+// getFlashbackKeyRanges returns the key ranges of the user databases, which exist either now or at flashbackTS.
+// The system databases are not flashed back, since they hold the states of the cluster, like the GC safe point
+// and the DDL jobs.
+func getFlashbackKeyRanges(d *ddlCtx, t *meta.Meta, flashbackTS uint64) ([]kv.KeyRange, error) {
+	snapshotMeta := meta.NewSnapshotMeta(d.store.GetSnapshot(kv.NewVersion(flashbackTS)))
+	var keyRanges []kv.KeyRange
+	dbIDs := make(map[int64]struct{})
+	physicalIDs := make(map[int64]struct{})
+	for _, m := range []*meta.Meta{t, snapshotMeta} {
+		dbs, err := m.ListDatabases()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, db := range dbs {
+			if util.IsMemOrSysDB(db.Name.L) {
+				continue
+			}
+			if _, ok := dbIDs[db.ID]; !ok {
+				dbIDs[db.ID] = struct{}{}
+				keyRanges = append(keyRanges, t.DBKeyRanges(db.ID)...)
+			}
+			tbls, err := m.ListTables(db.ID)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			for _, tbl := range tbls {
+				physicalIDs[tbl.ID] = struct{}{}
+				if pi := tbl.GetPartitionInfo(); pi != nil {
+					for _, def := range pi.Definitions {
+						physicalIDs[def.ID] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+	ids := make([]int64, 0, len(physicalIDs))
+	for id := range physicalIDs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		keyRanges = append(keyRanges, kv.KeyRange{StartKey: tablecodec.EncodeTablePrefix(id), EndKey: tablecodec.EncodeTablePrefix(id + 1)})
+	}
+	return keyRanges, nil
+}
+
+// flashbackKeyRanges rewrites the keys in the ranges to their values at flashbackTS in batches until all the ranges
+// are rewritten or the duration elapses, the keys not existing at flashbackTS are deleted. At least a batch is
+// rewritten each time. It returns the ranges not rewritten yet, the first of which starts from the key its next batch
+// starts from. Rewriting the keys again doesn't change them, so the ranges can simply be rewritten again after the
+// job fails.
+func flashbackKeyRanges(ctx context.Context, store kv.Storage, keyRanges []kv.KeyRange, flashbackTS uint64, duration time.Duration) ([]kv.KeyRange, error) {
+	snapshot := store.GetSnapshot(kv.NewVersion(flashbackTS))
+	deadline := time.Now().Add(duration)
+	for len(keyRanges) > 0 {
+		r := keyRanges[0]
+		var next kv.Key
+		err := kv.RunInNewTxn(ctx, store, true, func(ctx context.Context, txn kv.Transaction) error {
+			var err error
+			next, err = flashbackBatch(txn, snapshot, r.StartKey, r.EndKey)
+			return err
+		})
+		if err != nil {
+			return keyRanges, errors.Trace(err)
+		}
+		if next.Cmp(r.EndKey) < 0 {
+			keyRanges[0].StartKey = next
+		} else {
+			keyRanges = keyRanges[1:]
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+	}
+	return keyRanges, nil
+}
+
+// flashbackBatch rewrites a batch of the keys from start, and returns the key the next batch starts from.
+func flashbackBatch(txn kv.Transaction, snapshot kv.Snapshot, start, end kv.Key) (kv.Key, error) {
+	oldKeys, oldValues, oldEnd, err := scanKeyValues(snapshot, start, end, flashbackBatchSize)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	curKeys, curValues, curEnd, err := scanKeyValues(txn, start, end, flashbackBatchSize)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Both scans cover the keys before the smaller end.
+	batchEnd := oldEnd
+	if curEnd.Cmp(batchEnd) < 0 {
+		batchEnd = curEnd
+	}
+	var rewritten, deleted int
+	i, j := 0, 0
+	for {
+		hasOld := i < len(oldKeys) && oldKeys[i].Cmp(batchEnd) < 0
+		hasCur := j < len(curKeys) && curKeys[j].Cmp(batchEnd) < 0
+		if !hasOld && !hasCur {
+			break
+		}
+		switch {
+		case hasOld && (!hasCur || oldKeys[i].Cmp(curKeys[j]) < 0):
+			err = txn.Set(oldKeys[i], oldValues[i])
+			rewritten++
+			i++
+		case hasCur && (!hasOld || curKeys[j].Cmp(oldKeys[i]) < 0):
+			err = txn.Delete(curKeys[j])
+			deleted++
+			j++
+		default:
+			if !bytes.Equal(oldValues[i], curValues[j]) {
+				err = txn.Set(oldKeys[i], oldValues[i])
+				rewritten++
+			}
+			i++
+			j++
+		}
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if rewritten > 0 || deleted > 0 {
+		logutil.BgLogger().Debug("[ddl] flashback keys", zap.Stringer("start", start), zap.Stringer("end", batchEnd),
+			zap.Int("rewritten", rewritten), zap.Int("deleted", deleted))
+	}
+	return batchEnd, nil
+}
+
+// scanKeyValues scans at most limit pairs in [start, end), and returns them with the key the scanned pairs end
+// before, which is end if all the pairs in the range are scanned.
+func scanKeyValues(r kv.Retriever, start, end kv.Key, limit int) (keys []kv.Key, values [][]byte, scanEnd kv.Key, err error) {
+	it, err := r.Iter(start, end)
+	if err != nil {
+		return nil, nil, nil, errors.Trace(err)
+	}
+	defer it.Close()
+	for it.Valid() && len(keys) < limit {
+		keys = append(keys, it.Key().Clone())
+		values = append(values, append([]byte(nil), it.Value()...))
+		if err = it.Next(); err != nil {
+			return nil, nil, nil, errors.Trace(err)
+		}
+	}
+	if it.Valid() {
+		return keys, values, keys[len(keys)-1].Next(), nil
+	}
+	return keys, values, end, nil
+}
+
+func getTiDBSuperReadOnly(w *worker) (string, error) {
+	ctx, err := w.sessPool.get()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	defer w.sessPool.put(ctx)
+
+	return ctx.GetSessionVars().GlobalVarsAccessor.GetGlobalSysVar(variable.TiDBSuperReadOnly)
+}
+
+func setTiDBSuperReadOnly(w *worker, value string) error {
+	ctx, err := w.sessPool.get()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer w.sessPool.put(ctx)
+
+	return ctx.GetSessionVars().GlobalVarsAccessor.SetGlobalSysVar(variable.TiDBSuperReadOnly, value)
+}
+
+// finishFlashbackCluster resumes the GC and the PD schedules paused by the job, and restores the read-only mode.
+func finishFlashbackCluster(w *worker, job *model.Job) error {
+	var flashbackTS uint64
+	var gcFlag int64
+	var pdSchedule map[string]interface{}
+	var readOnly string
+	if err := job.DecodeArgs(&flashbackTS, &gcFlag, &pdSchedule, &readOnly); err != nil {
+		return errors.Trace(err)
+	}
+	if err := infosync.SetPDScheduleConfig(w.ddlJobCtx, pdSchedule); err != nil {
+		return errors.Trace(err)
+	}
+	// The read-only mode isn't saved if the job is cancelled before it's changed.
+	if readOnly != "" {
+		if err := setTiDBSuperReadOnly(w, readOnly); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if gcFlag == recoverTableCheckFlagEnableGC {
+		return errors.Trace(enableGC(w))
+	}
+	return nil
+}
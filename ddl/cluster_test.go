@@ -0,0 +1,193 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pingcap/failpoint"
+	"github.com/pingcap/tidb/ddl"
+	"github.com/pingcap/tidb/domain/infosync"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/parser/model"
+	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/pingcap/tidb/util/gcutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlashbackCluster(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+
+	timeBeforeDrop, _, safePointSQL, resetGC := testkit.MockGC(tk)
+	defer resetGC()
+	tk.MustExec(fmt.Sprintf(safePointSQL, timeBeforeDrop))
+	require.NoError(t, gcutil.EnableGC(tk.Session()))
+	require.NoError(t, infosync.SetPDScheduleConfig(context.Background(), map[string]interface{}{"leader-schedule-limit": float64(4)}))
+
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int primary key, b int, key(b))")
+	tk.MustExec("create table t_drop (a int)")
+	tk.MustExec("create table t_part (a int) partition by hash(a) partitions 2")
+	tk.MustExec("insert into t values (1, 1), (2, 2)")
+	tk.MustExec("insert into t_drop values (1)")
+	tk.MustExec("insert into t_part values (1), (2)")
+	time.Sleep(10 * time.Millisecond)
+	flashbackTime := tk.MustQuery("select now(3)").Rows()[0][0].(string)
+	time.Sleep(10 * time.Millisecond)
+
+	tk.MustExec("update t set b = 10 where a = 1")
+	tk.MustExec("delete from t where a = 2")
+	tk.MustExec("insert into t values (3, 3)")
+	tk.MustExec("alter table t add column c int")
+	tk.MustExec("drop table t_drop")
+	tk.MustExec("truncate table t_part")
+	tk.MustExec("create table t_new (a int)")
+	tk.MustExec("create database db_new")
+	tk.MustExec("insert into mysql.tidb values ('flashback_cluster_test', 'v', '')")
+
+	tk.MustExec(fmt.Sprintf("flashback cluster to timestamp '%s'", flashbackTime))
+
+	tk.MustQuery("select * from t").Sort().Check(testkit.Rows("1 1", "2 2"))
+	tk.MustQuery("select a from t use index(b) where b > 0").Sort().Check(testkit.Rows("1", "2"))
+	tk.MustQuery("select * from t_drop").Check(testkit.Rows("1"))
+	tk.MustQuery("select * from t_part").Sort().Check(testkit.Rows("1", "2"))
+	tk.MustGetErrCode("select * from t_new", 1146)
+	tk.MustQuery("show databases like 'db_new'").Check(testkit.Rows())
+	tk.MustExec("admin check table t")
+	tk.MustExec("create table t_new (a int)")
+	tk.MustExec("insert into t values (3, 3)")
+
+	// The system tables are not flashed back.
+	tk.MustQuery("select variable_value from mysql.tidb where variable_name = 'flashback_cluster_test'").Check(testkit.Rows("v"))
+	// The GC and the PD schedules are resumed.
+	enable, err := gcutil.CheckGCEnable(tk.Session())
+	require.NoError(t, err)
+	require.True(t, enable)
+	config, err := infosync.GetPDScheduleConfig(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, float64(4), config["leader-schedule-limit"])
+	require.Equal(t, 0, config["region-schedule-limit"])
+}
+
+func TestFlashbackClusterCheck(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+
+	timeBeforeDrop, _, safePointSQL, resetGC := testkit.MockGC(tk)
+	defer resetGC()
+	tk.MustExec(fmt.Sprintf(safePointSQL, timeBeforeDrop))
+	require.NoError(t, gcutil.EnableGC(tk.Session()))
+
+	err := tk.ExecToErr(fmt.Sprintf("flashback cluster to timestamp '%s'", time.Now().Add(time.Hour).Format("2006-01-02 15:04:05")))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "can't flashback cluster to a future timestamp")
+
+	err = tk.ExecToErr("flashback cluster to timestamp '2000-01-01 00:00:00'")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "snapshot is older than GC safe point")
+
+	// The failed job is retried until it succeeds, and the GC is resumed after it.
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int)")
+	time.Sleep(10 * time.Millisecond)
+	flashbackTime := tk.MustQuery("select now(3)").Rows()[0][0].(string)
+	time.Sleep(10 * time.Millisecond)
+	tk.MustExec("insert into t values (1)")
+	require.NoError(t, failpoint.Enable("github.com/pingcap/tidb/ddl/mockFlashbackClusterErr", `1*return(true)`))
+	defer func() {
+		require.NoError(t, failpoint.Disable("github.com/pingcap/tidb/ddl/mockFlashbackClusterErr"))
+	}()
+	tk.MustExec(fmt.Sprintf("flashback cluster to timestamp '%s'", flashbackTime))
+	tk.MustQuery("select * from t").Check(testkit.Rows())
+	enable, err := gcutil.CheckGCEnable(tk.Session())
+	require.NoError(t, err)
+	require.True(t, enable)
+}
+
+func TestFlashbackClusterResume(t *testing.T) {
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+
+	timeBeforeDrop, _, safePointSQL, resetGC := testkit.MockGC(tk)
+	defer resetGC()
+	tk.MustExec(fmt.Sprintf(safePointSQL, timeBeforeDrop))
+	require.NoError(t, gcutil.EnableGC(tk.Session()))
+
+	tk.MustExec("use test")
+	tk.MustExec("create table t1 (a int)")
+	tk.MustExec("create table t2 (a int)")
+	tk.MustExec("insert into t1 values (1)")
+	tk.MustExec("insert into t2 values (1)")
+	time.Sleep(10 * time.Millisecond)
+	flashbackTime := tk.MustQuery("select now(3)").Rows()[0][0].(string)
+	time.Sleep(10 * time.Millisecond)
+	tk.MustExec("insert into t1 values (2)")
+	tk.MustExec("insert into t2 values (2)")
+
+	// Record the key ranges left and try to write each time before the job rewrites the keys.
+	tk2 := testkit.NewTestKit(t, store)
+	require.True(t, tk2.Session().Auth(&auth.UserIdentity{Username: "root", Hostname: "%"}, nil, nil))
+	var rangeCounts []int
+	var writeErrs []error
+	hook := &ddl.TestDDLCallback{}
+	hook.OnJobRunBeforeExported = func(job *model.Job) {
+		if job.Type != model.ActionFlashbackCluster || job.SchemaState != model.StateWriteReorganization {
+			return
+		}
+		var flashbackTS uint64
+		var gcFlag int64
+		var pdSchedule map[string]interface{}
+		var readOnly string
+		var keyRanges []kv.KeyRange
+		if err := (&model.Job{RawArgs: job.RawArgs}).DecodeArgs(&flashbackTS, &gcFlag, &pdSchedule, &readOnly, &keyRanges); err != nil {
+			writeErrs = append(writeErrs, err)
+			return
+		}
+		rangeCounts = append(rangeCounts, len(keyRanges))
+		writeErrs = append(writeErrs, tk2.ExecToErr("insert into test.t1 values (3)"))
+	}
+	originalHook := dom.DDL().GetHook()
+	dom.DDL().SetHook(hook)
+	defer dom.DDL().SetHook(originalHook)
+
+	// Every time the job rewrites a batch, it saves the ranges left and the next run resumes from them.
+	defer func(timeout time.Duration) {
+		ddl.ReorgWaitTimeout = timeout
+	}(ddl.ReorgWaitTimeout)
+	ddl.ReorgWaitTimeout = 0
+	tk.MustExec(fmt.Sprintf("flashback cluster to timestamp '%s'", flashbackTime))
+	require.Greater(t, len(rangeCounts), 2)
+	for i := 1; i < len(rangeCounts); i++ {
+		require.Equal(t, rangeCounts[i-1]-1, rangeCounts[i])
+	}
+	// The user writes are blocked while the keys are rewritten.
+	for _, err := range writeErrs {
+		require.True(t, plannercore.ErrSQLInReadOnlyMode.Equal(err), "%v", err)
+	}
+
+	tk.MustQuery("select * from t1").Check(testkit.Rows("1"))
+	tk.MustQuery("select * from t2").Check(testkit.Rows("1"))
+	tk.MustQuery("select @@global.tidb_super_read_only").Check(testkit.Rows("0"))
+	tk.MustExec("insert into t1 values (3)")
+}
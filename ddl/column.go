@@ -34,6 +34,7 @@ import (
 	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/metrics"
 	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/charset"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
@@ -687,7 +688,10 @@ func onSetDefaultValue(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	return updateColumnDefaultValue(t, job, newCol, &newCol.Name)
 }
 
-func needChangeColumnData(oldCol, newCol *model.ColumnInfo) bool {
+func needChangeColumnData(tblInfo *model.TableInfo, oldCol, newCol *model.ColumnInfo) bool {
+	if needRewriteCharsetAndCollation(tblInfo, oldCol, newCol) {
+		return true
+	}
 	toUnsigned := mysql.HasUnsignedFlag(newCol.Flag)
 	originUnsigned := mysql.HasUnsignedFlag(oldCol.Flag)
 	needTruncationOrToggleSign := func() bool {
@@ -743,6 +747,18 @@ func needChangeColumnData(oldCol, newCol *model.ColumnInfo) bool {
 	return true
 }
 
+// needRewriteCharsetAndCollation checks whether converting the string column to another charset or collation
+// needs to rewrite the data. The rows are rewritten if the charset isn't compatible, and the indexes on the
+// column are rewritten if the sort keys of the collation aren't compatible.
+func needRewriteCharsetAndCollation(tblInfo *model.TableInfo, oldCol, newCol *model.ColumnInfo) bool {
+	if !types.IsString(oldCol.Tp) || !types.IsString(newCol.Tp) || len(oldCol.Charset) == 0 ||
+		oldCol.Charset == charset.CharsetBin || newCol.Charset == charset.CharsetBin {
+		return false
+	}
+	err := checkModifyCharsetAndCollation(newCol.Charset, newCol.Collate, oldCol.Charset, oldCol.Collate, isColumnWithIndex(oldCol.Name.L, tblInfo.Indices))
+	return errUnsupportedModifyCharset.Equal(err) || errUnsupportedModifyCollation.Equal(err)
+}
+
 // Column type conversion between varchar to char need reorganization because
 // 1. varchar -> char: char type is stored with the padding removed. All the indexes need to be rewritten.
 // 2. char -> varchar: the index value encoding of secondary index on clustered primary key tables is different.
@@ -843,7 +859,7 @@ func (w *worker) onModifyColumn(d *ddlCtx, t *meta.Meta, job *model.Job) (ver in
 
 	if job.IsRollingback() {
 		// For those column-type-change jobs which don't reorg the data.
-		if !needChangeColumnData(oldCol, jobParam.newCol) {
+		if !needChangeColumnData(tblInfo, oldCol, jobParam.newCol) {
 			return rollbackModifyColumnJob(t, tblInfo, job, jobParam.newCol, oldCol, jobParam.modifyColumnTp)
 		}
 		// For those column-type-change jobs which reorg the data.
@@ -873,7 +889,7 @@ func (w *worker) onModifyColumn(d *ddlCtx, t *meta.Meta, job *model.Job) (ver in
 		return ver, errors.Trace(err)
 	}
 
-	if !needChangeColumnData(oldCol, jobParam.newCol) {
+	if !needChangeColumnData(tblInfo, oldCol, jobParam.newCol) {
 		return w.doModifyColumn(d, t, job, dbInfo, tblInfo, jobParam.newCol, oldCol, jobParam.pos)
 	}
 
@@ -1456,6 +1472,7 @@ func (w *updateColumnWorker) BackfillDataInTxn(handleRange reorgBackfillTask) (t
 
 		// Collect the warnings.
 		taskCtx.warnings, taskCtx.warningsCount = warningsMap, warningsCountMap
+		taskCtx.writtenBytes = txn.Size()
 
 		return nil
 	})
@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/session"
 	"github.com/pingcap/tidb/store/mockstore"
 	"github.com/pingcap/tidb/testkit"
+	"github.com/pingcap/tidb/util/clustercap"
 	"github.com/stretchr/testify/require"
 )
 
@@ -79,6 +80,28 @@ func TestAlterViewTableCache(t *testing.T) {
 	tk.MustGetErrCode("alter table v cache", errno.ErrWrongObject)
 }
 
+func TestAlterTableCacheBeforeUpgrade(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	defer clustercap.Reset()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table cache_upgrade_t (id int)")
+	clustercap.Update(map[clustercap.Component][]string{
+		clustercap.TiDB: {"5.7.25-TiDB-v6.0.0", "5.7.25-TiDB-v5.4.0"},
+	})
+	tk.MustGetErrMsg("alter table cache_upgrade_t cache", "[ddl:8200]Unsupported before the cluster is upgraded, "+
+		"cached table requires all the tidb nodes to be v6.0.0 or later, but some of them are v5.4.0")
+	checkTableCacheStatus(t, tk.Session(), "test", "cache_upgrade_t", model.TableCacheStatusDisable)
+
+	clustercap.Update(map[clustercap.Component][]string{
+		clustercap.TiDB: {"5.7.25-TiDB-v6.0.0", "5.7.25-TiDB-v6.0.0"},
+	})
+	tk.MustExec("alter table cache_upgrade_t cache")
+	checkTableCacheStatus(t, tk.Session(), "test", "cache_upgrade_t", model.TableCacheStatusEnable)
+}
+
 func TestAlterTableNoCache(t *testing.T) {
 	store, clean := testkit.CreateMockStore(t)
 	defer clean()
@@ -827,10 +827,11 @@ func TestChangingTableCharset(t *testing.T) {
 	}
 	checkCharset(charset.CharsetUTF8MB4, charset.CollationUTF8MB4)
 
-	// Test when column charset can not convert to the target charset.
+	// Test when column charset is converted by rewriting the data.
 	tk.MustExec("drop table t;")
 	tk.MustExec("create table t(a varchar(10) character set ascii) charset utf8mb4")
-	tk.MustGetErrCode("alter table t convert to charset utf8mb4;", errno.ErrUnsupportedDDLOperation)
+	tk.MustExec("alter table t convert to charset utf8mb4;")
+	checkCharset(charset.CharsetUTF8MB4, charset.CollationUTF8MB4)
 
 	tk.MustExec("drop table t;")
 	tk.MustExec("create table t(a varchar(10) character set utf8) charset utf8")
@@ -1071,6 +1072,47 @@ func TestIndexOnMultipleGeneratedColumn5(t *testing.T) {
 	tk.MustExec("admin check table t")
 }
 
+func TestConvertTableCharsetWithReorg(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+
+	tk.MustExec("use test")
+	tk.MustExec("create table t(id int primary key, a varchar(10) collate utf8mb4_bin, b varchar(10) charset latin1, index idx_a(a), unique key uk_b(b)) charset utf8mb4 collate utf8mb4_bin")
+	tk.MustExec("insert into t values (1, 'A', 'x'), (2, 'b', 'y'), (3, 'c', 'z')")
+	tk.MustExec("alter table t convert to charset utf8mb4 collate utf8mb4_general_ci")
+	tk.MustExec("admin check table t")
+	tbl := testkit.TestGetTableByName(t, tk.Session(), "test", "t")
+	for _, col := range tbl.Meta().Columns[1:] {
+		require.Equal(t, charset.CharsetUTF8MB4, col.Charset)
+		require.Equal(t, "utf8mb4_general_ci", col.Collate)
+	}
+	tk.MustQuery("select id from t use index(idx_a) where a = 'a'").Check(testkit.Rows("1"))
+	tk.MustQuery("select id from t use index(uk_b) where b = 'X'").Check(testkit.Rows("1"))
+	// Every rewritten column is reported as a job with its progress.
+	tk.MustQuery("select job_type, state from information_schema.ddl_jobs where table_name = 't' order by job_id desc limit 3").Check(testkit.RowsWithSep("|",
+		"modify table charset and collate|synced", "modify column|synced", "modify column|synced"))
+
+	// The converted columns are converted back if a column fails.
+	tk.MustExec("drop table t")
+	tk.MustExec("create table t(id int primary key, a varchar(10) charset latin1, b varchar(10) collate utf8mb4_bin, unique key uk_b(b)) charset utf8mb4 collate utf8mb4_bin")
+	tk.MustExec("insert into t values (1, 'x', 'a'), (2, 'y', 'A')")
+	tk.MustGetErrCode("alter table t convert to charset utf8mb4 collate utf8mb4_general_ci", errno.ErrDupEntry)
+	tk.MustExec("admin check table t")
+	tbl = testkit.TestGetTableByName(t, tk.Session(), "test", "t")
+	require.Equal(t, "latin1", tbl.Meta().Columns[1].Charset)
+	require.Equal(t, "utf8mb4_bin", tbl.Meta().Columns[2].Collate)
+	require.Equal(t, "utf8mb4_bin", tbl.Meta().Collate)
+
+	// The columns can't be rewritten are rejected before the conversion.
+	tk.MustExec("drop table t")
+	tk.MustExec("create table t(a varchar(10) charset latin1 primary key, b int)")
+	tk.MustGetErrCode("alter table t convert to charset utf8mb4", errno.ErrUnsupportedDDLOperation)
+	tk.MustExec("drop table t")
+	tk.MustExec("create table t(a varchar(10) charset latin1, b int) partition by hash(b) partitions 2")
+	tk.MustGetErrCode("alter table t convert to charset utf8mb4", errno.ErrUnsupportedDDLOperation)
+}
+
 func TestCaseInsensitiveCharsetAndCollate(t *testing.T) {
 	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
 	defer clean()
@@ -3870,3 +3912,18 @@ func TestDDLLastInfo(t *testing.T) {
 	tk.MustExec("drop table t, t2")
 	tk.MustQuery("select json_extract(@@tidb_last_ddl_info, '$.query'), json_extract(@@tidb_last_ddl_info, '$.seq_num')").Check(testkit.Rows(fmt.Sprintf("\"drop table t, t2\" %d", firstSequence+3)))
 }
+
+func TestReorgMaxWriteSpeed(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int, b int)")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3)")
+	tk.MustExec("admin set cluster setting tidb_ddl_reorg_max_write_speed = 1024")
+	tk.MustExec("alter table t add index idx(b)")
+	tk.MustExec("admin check table t")
+	tk.MustExec("admin set cluster setting tidb_ddl_reorg_max_write_speed = 0")
+	tk.MustGetErrCode("admin set cluster setting tidb_ddl_reorg_max_write_speed = -1", errno.ErrWrongValueForClusterSetting)
+}
@@ -52,6 +52,7 @@ import (
 	"go.etcd.io/etcd/clientv3"
 	atomicutil "go.uber.org/atomic"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -102,6 +103,7 @@ type DDL interface {
 	CreateView(ctx sessionctx.Context, stmt *ast.CreateViewStmt) error
 	DropTable(ctx sessionctx.Context, tableIdent ast.Ident) (err error)
 	RecoverTable(ctx sessionctx.Context, recoverInfo *RecoverInfo) (err error)
+	FlashbackCluster(ctx sessionctx.Context, flashbackTS uint64) error
 	DropView(ctx sessionctx.Context, tableIdent ast.Ident) (err error)
 	CreateIndex(ctx sessionctx.Context, tableIdent ast.Ident, keyType ast.IndexKeyType, indexName model.CIStr,
 		columnNames []*ast.IndexPartSpecification, indexOption *ast.IndexOption, ifNotExists bool) error
@@ -210,6 +212,8 @@ type ddlCtx struct {
 	statsHandle  *handle.Handle
 	tableLockCkr util.DeadTableLockChecker
 	etcdCli      *clientv3.Client
+	// reorgWriteLimiter limits the bytes written by all the backfill workers per second.
+	reorgWriteLimiter *rate.Limiter
 
 	// hook may be modified.
 	mu struct {
@@ -325,6 +329,8 @@ func newDDL(ctx context.Context, options ...Option) *ddl {
 		infoCache:    opt.InfoCache,
 		tableLockCkr: deadLockCkr,
 		etcdCli:      opt.EtcdCli,
+
+		reorgWriteLimiter: rate.NewLimiter(rate.Inf, 0),
 	}
 	ddlCtx.mu.hook = opt.Hook
 	ddlCtx.mu.interceptor = &BaseInterceptor{}
@@ -53,6 +53,7 @@ import (
 	driver "github.com/pingcap/tidb/types/parser_driver"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/clustercap"
 	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/domainutil"
 	"github.com/pingcap/tidb/util/logutil"
@@ -1930,6 +1931,9 @@ func buildTableInfoWithStmt(ctx sessionctx.Context, s *ast.CreateTableStmt, dbCh
 	if err = setTemporaryType(ctx, tbInfo, s); err != nil {
 		return nil, errors.Trace(err)
 	}
+	if err = setForeignTable(tbInfo, s); err != nil {
+		return nil, errors.Trace(err)
+	}
 
 	if err = setTableAutoRandomBits(ctx, tbInfo, colDefs); err != nil {
 		return nil, errors.Trace(err)
@@ -2033,6 +2037,32 @@ func setTemporaryType(ctx sessionctx.Context, tbInfo *model.TableInfo, s *ast.Cr
 	return nil
 }
 
+// setForeignTable sets the remote table of CREATE FOREIGN TABLE. The rows of a foreign table
+// are always read from the foreign server, so it can't have indexes or partitions.
+func setForeignTable(tbInfo *model.TableInfo, s *ast.CreateTableStmt) error {
+	if s.Foreign == nil {
+		return nil
+	}
+	if len(tbInfo.Indices) > 0 || tbInfo.PKIsHandle {
+		return errUnsupportedOnForeignTable.GenWithStackByArgs("index")
+	}
+	if s.Partition != nil {
+		return errUnsupportedOnForeignTable.GenWithStackByArgs("partition")
+	}
+	tbInfo.Foreign = &model.ForeignTableInfo{Server: model.NewCIStr(s.Foreign.Server)}
+	for _, option := range s.Foreign.Options {
+		switch option.Name {
+		case "DATABASE":
+			tbInfo.Foreign.RemoteDB = option.Value
+		case "TABLE":
+			tbInfo.Foreign.RemoteTable = option.Value
+		default:
+			return errUnsupportedOnForeignTable.GenWithStackByArgs(fmt.Sprintf("option %s", option.Name))
+		}
+	}
+	return nil
+}
+
 // createTableWithInfoJob returns the table creation job.
 // WARNING: it may return a nil job, which means you don't need to submit any DDL job.
 // WARNING!!!: if retainID == true, it will not allocate ID by itself. That means if the caller
@@ -2605,6 +2635,11 @@ func handleTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo) err
 			}
 		}
 	}
+	ttlInfo, _, err := getTTLInfoInOptions(options, tbInfo)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	tbInfo.TTLInfo = ttlInfo
 	shardingBits := shardingBits(tbInfo)
 	if tbInfo.PreSplitRegions > shardingBits {
 		tbInfo.PreSplitRegions = shardingBits
@@ -2777,6 +2812,9 @@ func (d *ddl) AlterTable(ctx context.Context, sctx sessionctx.Context, ident ast
 	if is.TableIsView(ident.Schema, ident.Name) || is.TableIsSequence(ident.Schema, ident.Name) {
 		return ErrWrongObject.GenWithStackByArgs(ident.Schema, ident.Name, "BASE TABLE")
 	}
+	if tb, err := is.TableByName(ident.Schema, ident.Name); err == nil && tb.Meta().IsForeignTable() {
+		return errUnsupportedOnForeignTable.GenWithStackByArgs("ALTER TABLE")
+	}
 
 	err = checkMultiSpecs(sctx, validSpecs)
 	if err != nil {
@@ -2802,6 +2840,7 @@ func (d *ddl) AlterTable(ctx context.Context, sctx sessionctx.Context, ident ast
 
 	for _, spec := range validSpecs {
 		var handledCharsetOrCollate bool
+		var handledTTL bool
 		switch spec.Tp {
 		case ast.AlterTableAddColumns:
 			if len(spec.NewColumns) != 1 {
@@ -2937,6 +2976,13 @@ func (d *ddl) AlterTable(ctx context.Context, sctx sessionctx.Context, ident ast
 					placementPolicyRef = &model.PolicyRefInfo{
 						Name: model.NewCIStr(opt.StrValue),
 					}
+				case ast.TableOptionTTL, ast.TableOptionTTLEnable:
+					// All the TTL options are handled in one job.
+					if handledTTL {
+						continue
+					}
+					err = d.AlterTableTTLInfo(sctx, ident, spec.Options)
+					handledTTL = true
 				case ast.TableOptionEngine:
 				default:
 					err = errUnsupportedAlterTableOption
@@ -2978,6 +3024,8 @@ func (d *ddl) AlterTable(ctx context.Context, sctx sessionctx.Context, ident ast
 			err = d.AlterTableCache(sctx, ident)
 		case ast.AlterTableNoCache:
 			err = d.AlterTableNoCache(sctx, ident)
+		case ast.AlterTableRemoveTTL:
+			err = d.AlterTableRemoveTTL(sctx, ident)
 		default:
 			// Nothing to do now.
 		}
@@ -3865,6 +3913,9 @@ func checkIsDroppableColumn(ctx sessionctx.Context, t table.Table, spec *ast.Alt
 	if err = isDroppableColumn(ctx.GetSessionVars().EnableChangeMultiSchema, tblInfo, colName); err != nil {
 		return false, errors.Trace(err)
 	}
+	if err = checkTTLColumnChange(tblInfo, colName, "dropped"); err != nil {
+		return false, errors.Trace(err)
+	}
 	// We don't support dropping column with PK handle covered now.
 	if col.IsPKHandleColumn(tblInfo) {
 		return false, errUnsupportedPKHandle
@@ -4244,6 +4295,14 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, sctx sessionctx.Contex
 	if newColName.L == model.ExtraHandleName.L {
 		return nil, ErrWrongColumnName.GenWithStackByArgs(newColName.L)
 	}
+	if tblInfo := t.Meta(); tblInfo.TTLInfo != nil && tblInfo.TTLInfo.ColumnName.L == originalColName.L {
+		if newColName.L != originalColName.L {
+			return nil, errTTLColumnChange.GenWithStackByArgs(originalColName.O, "renamed")
+		}
+		if err := checkTTLColumnType(originalColName, specNewColumn.Tp); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 	// If we want to rename the column name, we need to check whether it already exists.
 	if newColName.L != originalColName.L {
 		c := table.FindCol(t.Cols(), newColName.L)
@@ -4336,7 +4395,7 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, sctx sessionctx.Contex
 		}
 		return nil, errors.Trace(err)
 	}
-	needChangeColData := needChangeColumnData(col.ColumnInfo, newCol.ColumnInfo)
+	needChangeColData := needChangeColumnData(t.Meta(), col.ColumnInfo, newCol.ColumnInfo)
 	if needChangeColData {
 		if err = isGeneratedRelatedColumn(t.Meta(), newCol.ColumnInfo, col.ColumnInfo); err != nil {
 			return nil, errors.Trace(err)
@@ -4590,6 +4649,9 @@ func (d *ddl) RenameColumn(ctx sessionctx.Context, ident ast.Ident, spec *ast.Al
 	if oldCol == nil {
 		return infoschema.ErrColumnNotExists.GenWithStackByArgs(oldColName, ident.Name)
 	}
+	if err := checkTTLColumnChange(tbl.Meta(), oldColName, "renamed"); err != nil {
+		return errors.Trace(err)
+	}
 
 	allCols := tbl.Cols()
 	colWithNewNameAlreadyExist := table.FindCol(allCols, newColName.L) != nil
@@ -4808,6 +4870,12 @@ func (d *ddl) AlterTableCharsetAndCollate(ctx sessionctx.Context, ident ast.Iden
 	if doNothing {
 		return nil
 	}
+	var converted []*model.ColumnInfo
+	if needsOverwriteCols {
+		if converted, err = d.convertColumnsCharsetAndCollate(ctx, schema, tb.Meta(), toCharset, toCollate); err != nil {
+			return errors.Trace(err)
+		}
+	}
 
 	job := &model.Job{
 		SchemaID:   schema.ID,
@@ -4819,6 +4887,9 @@ func (d *ddl) AlterTableCharsetAndCollate(ctx sessionctx.Context, ident ast.Iden
 	}
 	err = d.doDDLJob(ctx, job)
 	err = d.callHookOnChanged(err)
+	if err != nil {
+		d.revertConvertedColumns(ctx, schema, tb.Meta().ID, converted)
+	}
 	return errors.Trace(err)
 }
 
@@ -5022,7 +5093,10 @@ func checkAlterTableCharset(tblInfo *model.TableInfo, dbInfo *model.DBInfo, toCh
 	}
 
 	if err = checkModifyCharsetAndCollation(toCharset, toCollate, origCharset, origCollate, false); err != nil {
-		return doNothing, err
+		// The columns are converted by rewriting the data, so the table can be converted to any charset except binary.
+		if !needsOverwriteCols || toCharset == charset.CharsetBin || !errUnsupportedModifyCharset.Equal(err) {
+			return doNothing, err
+		}
 	}
 	if !needsOverwriteCols {
 		// If we don't change the charset and collation of columns, skip the next checks.
@@ -5041,17 +5115,110 @@ func checkAlterTableCharset(tblInfo *model.TableInfo, dbInfo *model.DBInfo, toCh
 		if len(col.Charset) == 0 {
 			continue
 		}
-		if err = checkModifyCharsetAndCollation(toCharset, toCollate, col.Charset, col.Collate, isColumnWithIndex(col.Name.L, tblInfo.Indices)); err != nil {
-			if strings.Contains(err.Error(), "Unsupported modifying collation") {
-				colErrMsg := "Unsupported converting collation of column '%s' from '%s' to '%s' when index is defined on it."
-				err = errUnsupportedModifyCollation.GenWithStack(colErrMsg, col.Name.L, col.Collate, toCollate)
+		newCol := col.Clone()
+		newCol.Charset, newCol.Collate = toCharset, toCollate
+		if needRewriteCharsetAndCollation(tblInfo, col, newCol) {
+			if err = checkConvertColumnData(tblInfo, col, newCol); err != nil {
+				return doNothing, err
 			}
+			continue
+		}
+		if err = checkModifyCharsetAndCollation(toCharset, toCollate, col.Charset, col.Collate, isColumnWithIndex(col.Name.L, tblInfo.Indices)); err != nil {
 			return doNothing, err
 		}
 	}
 	return doNothing, nil
 }
 
+// checkConvertColumnData checks whether the data of the column can be rewritten to convert it to the charset
+// and collation of newCol.
+func checkConvertColumnData(tblInfo *model.TableInfo, col, newCol *model.ColumnInfo) error {
+	var msg string
+	switch {
+	case mysql.HasPriKeyFlag(col.Flag):
+		msg = "this column has primary key flag"
+	case tblInfo.Partition != nil:
+		msg = "table is partition table"
+	case col.IsGenerated():
+		msg = "this column is a generated column"
+	}
+	if len(msg) == 0 {
+		if ok, dep, _ := hasDependentByGeneratedColumn(tblInfo, col.Name); ok {
+			msg = fmt.Sprintf("this column is a dependent column '%s' for generated column", dep)
+		}
+	}
+	if len(msg) > 0 {
+		colErrMsg := "Unsupported converting charset of column '%s' from '%s' to '%s': %s"
+		return errUnsupportedModifyCharset.GenWithStack(colErrMsg, col.Name.O, col.Collate, newCol.Collate, msg)
+	}
+	return checkColumnWithIndexConstraint(tblInfo, col, newCol)
+}
+
+// convertColumnsCharsetAndCollate rewrites the data of the columns which can't be converted to the charset
+// and collation by changing the metadata. Every column is converted by a job modifying the column, which
+// rewrites the rows and the indexes on the column in the reorg framework, and its progress is reported as
+// the row count of the job. If a column fails, the converted columns are converted back, so the table is
+// left unchanged. It returns the original columns which are converted.
+func (d *ddl) convertColumnsCharsetAndCollate(ctx sessionctx.Context, schema *model.DBInfo, tblInfo *model.TableInfo, toCharset, toCollate string) ([]*model.ColumnInfo, error) {
+	var converted []*model.ColumnInfo
+	for _, col := range tblInfo.Columns {
+		newCol := col.Clone()
+		newCol.Charset, newCol.Collate = toCharset, toCollate
+		if !needRewriteCharsetAndCollation(tblInfo, col, newCol) {
+			continue
+		}
+		err := d.doDDLJob(ctx, buildConvertColumnJob(ctx, schema, tblInfo, newCol))
+		err = d.callHookOnChanged(err)
+		if err != nil {
+			d.revertConvertedColumns(ctx, schema, tblInfo.ID, converted)
+			return nil, errors.Trace(err)
+		}
+		converted = append(converted, col)
+	}
+	return converted, nil
+}
+
+// revertConvertedColumns converts the columns back to their original charset and collation.
+func (d *ddl) revertConvertedColumns(ctx sessionctx.Context, schema *model.DBInfo, tableID int64, origCols []*model.ColumnInfo) {
+	for i := len(origCols) - 1; i >= 0; i-- {
+		origCol := origCols[i]
+		tbl, ok := d.infoCache.GetLatest().TableByID(tableID)
+		if !ok {
+			return
+		}
+		col := model.FindColumnInfo(tbl.Meta().Columns, origCol.Name.L)
+		if col == nil {
+			continue
+		}
+		newCol := col.Clone()
+		newCol.Charset, newCol.Collate = origCol.Charset, origCol.Collate
+		err := d.doDDLJob(ctx, buildConvertColumnJob(ctx, schema, tbl.Meta(), newCol))
+		err = d.callHookOnChanged(err)
+		if err != nil {
+			logutil.BgLogger().Warn("[ddl] convert the column back to the original charset failed",
+				zap.String("table", tbl.Meta().Name.O), zap.String("column", col.Name.O), zap.Error(err))
+		}
+	}
+}
+
+// buildConvertColumnJob builds the job modifying the column to newCol, which always rewrites the data.
+func buildConvertColumnJob(ctx sessionctx.Context, schema *model.DBInfo, tblInfo *model.TableInfo, newCol *model.ColumnInfo) *model.Job {
+	return &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tblInfo.ID,
+		SchemaName: schema.Name.L,
+		Type:       model.ActionModifyColumn,
+		BinlogInfo: &model.HistoryInfo{},
+		ReorgMeta: &model.DDLReorgMeta{
+			SQLMode:       ctx.GetSessionVars().SQLMode,
+			Warnings:      make(map[errors.ErrorID]*terror.Error),
+			WarningsCount: make(map[errors.ErrorID]int64),
+		},
+		CtxVars: []interface{}{true},
+		Args:    []interface{}{newCol, newCol.Name, &ast.ColumnPosition{Tp: ast.ColumnPositionNone}, byte(0), uint64(0)},
+	}
+}
+
 // RenameIndex renames an index.
 // In TiDB, indexes are case-insensitive (so index 'a' and 'A" are considered the same index),
 // but index names are case-sensitive (we can rename index 'a' to 'A')
@@ -5159,6 +5326,9 @@ func (d *ddl) TruncateTable(ctx sessionctx.Context, ti ast.Ident) error {
 	if tb.Meta().IsView() || tb.Meta().IsSequence() {
 		return infoschema.ErrTableNotExists.GenWithStackByArgs(schema.Name.O, tb.Meta().Name.O)
 	}
+	if tb.Meta().IsForeignTable() {
+		return errUnsupportedOnForeignTable.GenWithStackByArgs("TRUNCATE TABLE")
+	}
 	genIDs, err := d.genGlobalIDs(1)
 	if err != nil {
 		return errors.Trace(err)
@@ -5546,6 +5716,9 @@ func (d *ddl) CreateIndex(ctx sessionctx.Context, ti ast.Ident, keyType ast.Inde
 	if t.Meta().TableCacheStatusType != model.TableCacheStatusDisable {
 		return errors.Trace(ErrOptOnCacheTable.GenWithStackByArgs("Create Index"))
 	}
+	if t.Meta().IsForeignTable() {
+		return errors.Trace(errUnsupportedOnForeignTable.GenWithStackByArgs("index"))
+	}
 	// Deal with anonymous index.
 	if len(indexName.L) == 0 {
 		colName := model.NewCIStr("expression_index")
@@ -6698,6 +6871,9 @@ func (d *ddl) CreatePlacementPolicy(ctx sessionctx.Context, stmt *ast.CreatePlac
 	if checkIgnorePlacementDDL(ctx) {
 		return nil
 	}
+	if err := clustercap.Check(clustercap.FeaturePlacementPolicy); err != nil {
+		return errUnsupportedByCluster.GenWithStackByArgs(err.Error())
+	}
 	policyName := stmt.PolicyName
 	if policyName.L == defaultPlacementPolicyName {
 		return errors.Trace(infoschema.ErrReservedSyntax.GenWithStackByArgs(policyName))
@@ -6822,6 +6998,9 @@ func (d *ddl) AlterTableCache(ctx sessionctx.Context, ti ast.Ident) (err error)
 	if t.Meta().Partition != nil {
 		return ErrOptOnCacheTable.GenWithStackByArgs("partition mode")
 	}
+	if err := clustercap.Check(clustercap.FeatureCachedTable); err != nil {
+		return errUnsupportedByCluster.GenWithStackByArgs(err.Error())
+	}
 
 	succ, err := checkCacheTableSize(d.store, t.Meta().ID)
 	if err != nil {
@@ -416,6 +416,8 @@ func (w *worker) finishDDLJob(t *meta.Meta, job *model.Job) (err error) {
 	switch job.Type {
 	case model.ActionRecoverTable:
 		err = finishRecoverTable(w, job)
+	case model.ActionFlashbackCluster:
+		err = finishFlashbackCluster(w, job)
 	case model.ActionCreateTables:
 		if job.IsCancelled() {
 			// it may be too large that it can not be added to the history queue, too
@@ -856,6 +858,8 @@ func (w *worker) runDDLJob(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64,
 		ver, err = onModifyTableCharsetAndCollate(t, job)
 	case model.ActionRecoverTable:
 		ver, err = w.onRecoverTable(d, t, job)
+	case model.ActionFlashbackCluster:
+		ver, err = w.onFlashbackCluster(d, t, job)
 	case model.ActionLockTable:
 		ver, err = onLockTables(t, job)
 	case model.ActionUnlockTable:
@@ -890,6 +894,10 @@ func (w *worker) runDDLJob(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64,
 		ver, err = onAlterCacheTable(t, job)
 	case model.ActionAlterNoCacheTable:
 		ver, err = onAlterNoCacheTable(t, job)
+	case model.ActionAlterTTLInfo:
+		ver, err = onAlterTTLInfo(t, job)
+	case model.ActionAlterTTLRemove:
+		ver, err = onAlterTTLRemove(t, job)
 	default:
 		// Invalid job, cancel it.
 		job.State = model.JobStateCancelled
@@ -298,6 +298,10 @@ var (
 	// ErrOptOnCacheTable returns when exec unsupported opt at cache mode
 	ErrOptOnCacheTable                  = dbterror.ClassDDL.NewStd(mysql.ErrOptOnCacheTable)
 	errUnsupportedOnCommitPreserve      = dbterror.ClassDDL.NewStdErr(mysql.ErrUnsupportedDDLOperation, parser_mysql.Message("TiDB doesn't support ON COMMIT PRESERVE ROWS for now", nil))
+	errUnsupportedOnForeignTable        = dbterror.ClassDDL.NewStdErr(mysql.ErrUnsupportedDDLOperation, parser_mysql.Message("%s is not supported on foreign tables", nil))
+	errUnsupportedTTLColumn             = dbterror.ClassDDL.NewStdErr(mysql.ErrUnsupportedDDLOperation, parser_mysql.Message("Column '%s' of type %s can't be the TTL column, only the DATE, DATETIME and TIMESTAMP columns can", nil))
+	errTTLEnableWithoutTTL              = dbterror.ClassDDL.NewStdErr(mysql.ErrUnsupportedDDLOperation, parser_mysql.Message("TTL_ENABLE can't be set on a table without TTL", nil))
+	errTTLColumnChange                  = dbterror.ClassDDL.NewStdErr(mysql.ErrUnsupportedDDLOperation, parser_mysql.Message("Column '%s' is the TTL column, it can't be %s", nil))
 	errUnsupportedClusteredSecondaryKey = dbterror.ClassDDL.NewStdErr(mysql.ErrUnsupportedDDLOperation, parser_mysql.Message("CLUSTERED/NONCLUSTERED keyword is only supported for primary key", nil))
 
 	// ErrUnsupportedLocalTempTableDDL returns when ddl operation unsupported for local temporary table
@@ -312,4 +316,6 @@ var (
 	errFunctionalIndexOnBlob = dbterror.ClassDDL.NewStd(mysql.ErrFunctionalIndexOnBlob)
 	// ErrIncompatibleTiFlashAndPlacement when placement and tiflash replica options are set at the same time
 	ErrIncompatibleTiFlashAndPlacement = dbterror.ClassDDL.NewStdErr(mysql.ErrUnsupportedDDLOperation, parser_mysql.Message("Placement and tiflash replica options cannot be set at the same time", nil))
+	// errUnsupportedByCluster returns when the feature is not supported by some nodes of the cluster which are not upgraded yet.
+	errUnsupportedByCluster = dbterror.ClassDDL.NewStdErr(mysql.ErrUnsupportedDDLOperation, parser_mysql.Message("Unsupported before the cluster is upgraded, %s", nil))
 )
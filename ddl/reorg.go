@@ -45,6 +45,7 @@ import (
 	"github.com/pingcap/tidb/util/ranger"
 	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/pingcap/tipb/go-tipb"
+	pd "github.com/tikv/pd/client"
 	"go.uber.org/zap"
 )
 
@@ -217,6 +218,7 @@ func (w *worker) runReorgJob(t *meta.Meta, reorgInfo *reorgInfo, tblInfo *model.
 		w.reorgCtx.setCurrentElement(reorgInfo.currElement)
 		w.reorgCtx.mu.warnings = make(map[errors.ErrorID]*terror.Error)
 		w.reorgCtx.mu.warningsCount = make(map[errors.ErrorID]int64)
+		reorgInfo.updateServiceSafePoint()
 		go func() {
 			defer w.wg.Done()
 			w.reorgCtx.doneCh <- f()
@@ -245,6 +247,7 @@ func (w *worker) runReorgJob(t *meta.Meta, reorgInfo *reorgInfo, tblInfo *model.
 		w.mergeWarningsIntoJob(job)
 
 		w.reorgCtx.clean()
+		reorgInfo.removeServiceSafePoint()
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -281,6 +284,7 @@ func (w *worker) runReorgJob(t *meta.Meta, reorgInfo *reorgInfo, tblInfo *model.
 		// Since daemon-worker is triggered by timer to store the info half-way.
 		// you should keep these infos is read-only (like job) / atomic (like doneKey & element) / concurrent safe.
 		err := t.UpdateDDLReorgStartHandle(job, currentElement, doneKey)
+		reorgInfo.updateServiceSafePoint()
 
 		logutil.BgLogger().Info("[ddl] run reorg job wait timeout",
 			zap.Duration("waitTime", waitTimeout),
@@ -295,6 +299,58 @@ func (w *worker) runReorgJob(t *meta.Meta, reorgInfo *reorgInfo, tblInfo *model.
 	return nil
 }
 
+// reorgServiceSafePointTTL is the TTL in seconds of the service safe point of a reorg job. It's refreshed
+// at each checkpoint of the reorg job, the TTL only takes effect after the DDL owner quits.
+const reorgServiceSafePointTTL = 10 * 60
+
+func reorgServiceSafePointID(job *model.Job) string {
+	return fmt.Sprintf("ddl_reorg_%d", job.ID)
+}
+
+// getPDClient returns nil if the store isn't backed by PD. The reorgInfo built manually by the
+// early tests has no ddlCtx, it's nil in that case too.
+func (r *reorgInfo) getPDClient() pd.Client {
+	if r.d == nil {
+		return nil
+	}
+	s, ok := r.d.store.(kv.StorageWithPD)
+	if !ok {
+		return nil
+	}
+	return s.GetPDClient()
+}
+
+// updateServiceSafePoint registers the snapshot version of the reorg job as a service safe point in PD,
+// so GC won't advance beyond it however long the backfilling lasts.
+func (r *reorgInfo) updateServiceSafePoint() {
+	pdCli := r.getPDClient()
+	if pdCli == nil || r.Job.SnapshotVer == 0 {
+		return
+	}
+	minSafePoint, err := pdCli.UpdateServiceGCSafePoint(context.Background(), reorgServiceSafePointID(r.Job), reorgServiceSafePointTTL, r.Job.SnapshotVer)
+	if err != nil {
+		logutil.BgLogger().Warn("[ddl] update the service safe point of reorg job failed", zap.Int64("jobID", r.Job.ID), zap.Error(err))
+		return
+	}
+	if minSafePoint > r.Job.SnapshotVer {
+		logutil.BgLogger().Warn("[ddl] the snapshot version of reorg job is older than the GC safe point",
+			zap.Int64("jobID", r.Job.ID), zap.Uint64("snapshotVer", r.Job.SnapshotVer), zap.Uint64("minSafePoint", minSafePoint))
+	}
+}
+
+// removeServiceSafePoint removes the service safe point of the reorg job after the backfilling is done.
+func (r *reorgInfo) removeServiceSafePoint() {
+	pdCli := r.getPDClient()
+	if pdCli == nil {
+		return
+	}
+	// A TTL of 0 removes the service safe point.
+	_, err := pdCli.UpdateServiceGCSafePoint(context.Background(), reorgServiceSafePointID(r.Job), 0, 0)
+	if err != nil {
+		logutil.BgLogger().Warn("[ddl] remove the service safe point of reorg job failed", zap.Int64("jobID", r.Job.ID), zap.Error(err))
+	}
+}
+
 func (w *worker) mergeWarningsIntoJob(job *model.Job) {
 	w.reorgCtx.mu.Lock()
 	partWarnings := w.reorgCtx.mu.warnings
@@ -16,6 +16,7 @@ package ddl
 
 import (
 	"context"
+	"math"
 	"time"
 
 	"github.com/pingcap/tidb/kv"
@@ -259,3 +260,42 @@ func (s *testDDLSuiteToVerify) TestReorgOwner() {
 	})
 	require.NoError(s.T(), err)
 }
+
+func (s *testDDLSuiteToVerify) TestReorgServiceSafePoint() {
+	store := testCreateStore(s.T(), "test_reorg_service_safe_point")
+	defer func() {
+		err := store.Close()
+		require.NoError(s.T(), err)
+	}()
+
+	pdCli := store.(kv.StorageWithPD).GetPDClient()
+	getMinSafePoint := func() uint64 {
+		// The probe with a TTL of 0 isn't registered, but the minimal service safe point is returned.
+		minSafePoint, err := pdCli.UpdateServiceGCSafePoint(context.Background(), "test", 0, 0)
+		require.NoError(s.T(), err)
+		return minSafePoint
+	}
+	require.Equal(s.T(), uint64(math.MaxUint64), getMinSafePoint())
+
+	d := &ddlCtx{store: store}
+	info := &reorgInfo{Job: &model.Job{ID: 1}, d: d}
+	info.updateServiceSafePoint()
+	require.Equal(s.T(), uint64(math.MaxUint64), getMinSafePoint())
+
+	info.Job.SnapshotVer = 100
+	info.updateServiceSafePoint()
+	require.Equal(s.T(), uint64(100), getMinSafePoint())
+	info2 := &reorgInfo{Job: &model.Job{ID: 2, SnapshotVer: 200}, d: d}
+	info2.updateServiceSafePoint()
+	require.Equal(s.T(), uint64(100), getMinSafePoint())
+
+	info.removeServiceSafePoint()
+	require.Equal(s.T(), uint64(200), getMinSafePoint())
+	info2.removeServiceSafePoint()
+	require.Equal(s.T(), uint64(math.MaxUint64), getMinSafePoint())
+
+	// The reorgInfo without ddlCtx is ignored.
+	info = &reorgInfo{Job: &model.Job{ID: 3, SnapshotVer: 300}}
+	info.updateServiceSafePoint()
+	require.Equal(s.T(), uint64(math.MaxUint64), getMinSafePoint())
+}
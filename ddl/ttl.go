@@ -0,0 +1,190 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"strconv"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/parser/types"
+	"github.com/pingcap/tidb/sessionctx"
+)
+
+// getTTLInfoInOptions returns the TTL info of the table after the TTL and TTL_ENABLE options are applied, and whether
+// the options contain any of them. TTL_ENABLE can be set alone if the table already has TTL.
+func getTTLInfoInOptions(options []*ast.TableOption, tblInfo *model.TableInfo) (*model.TTLInfo, bool, error) {
+	var ttlInfo *model.TTLInfo
+	if tblInfo.TTLInfo != nil {
+		ttlInfo = tblInfo.TTLInfo.Clone()
+	}
+	var enable *bool
+	found := false
+	for _, op := range options {
+		switch op.Tp {
+		case ast.TableOptionTTL:
+			newTTLInfo := &model.TTLInfo{
+				ColumnName:       op.ColumnName.Name,
+				IntervalExprStr:  strconv.FormatUint(op.UintValue, 10),
+				IntervalTimeUnit: op.TimeUnitValue.Unit.String(),
+				Enable:           true,
+			}
+			// Changing the TTL doesn't change whether it's enabled.
+			if ttlInfo != nil {
+				newTTLInfo.Enable = ttlInfo.Enable
+			}
+			ttlInfo = newTTLInfo
+			found = true
+		case ast.TableOptionTTLEnable:
+			enable = &op.BoolValue
+			found = true
+		}
+	}
+	if !found {
+		return nil, false, nil
+	}
+	if ttlInfo == nil {
+		return nil, true, errTTLEnableWithoutTTL
+	}
+	if enable != nil {
+		ttlInfo.Enable = *enable
+	}
+	if tblInfo.TempTableType != model.TempTableNone {
+		return nil, true, ErrOptOnTemporaryTable.GenWithStackByArgs("ttl")
+	}
+	if err := checkTTLColumn(tblInfo, ttlInfo.ColumnName); err != nil {
+		return nil, true, err
+	}
+	return ttlInfo, true, nil
+}
+
+// checkTTLColumn checks whether the column can be the TTL column, only the date and time columns can.
+func checkTTLColumn(tblInfo *model.TableInfo, colName model.CIStr) error {
+	col := model.FindColumnInfo(tblInfo.Columns, colName.L)
+	if col == nil {
+		return infoschema.ErrColumnNotExists.GenWithStackByArgs(colName.O, tblInfo.Name.O)
+	}
+	return checkTTLColumnType(col.Name, &col.FieldType)
+}
+
+func checkTTLColumnType(colName model.CIStr, tp *types.FieldType) error {
+	switch tp.Tp {
+	case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp:
+		return nil
+	default:
+		return errUnsupportedTTLColumn.GenWithStackByArgs(colName.O, types.TypeToStr(tp.Tp, tp.Charset))
+	}
+}
+
+// checkTTLColumnChange returns an error if the column is the TTL column, the columns must be changed after the TTL
+// is removed.
+func checkTTLColumnChange(tblInfo *model.TableInfo, colName model.CIStr, change string) error {
+	if tblInfo.TTLInfo != nil && tblInfo.TTLInfo.ColumnName.L == colName.L {
+		return errTTLColumnChange.GenWithStackByArgs(colName.O, change)
+	}
+	return nil
+}
+
+// AlterTableTTLInfo changes the TTL or TTL_ENABLE of the table.
+func (d *ddl) AlterTableTTLInfo(ctx sessionctx.Context, ident ast.Ident, options []*ast.TableOption) error {
+	schema, tb, err := d.getSchemaAndTableByIdent(ctx, ident)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ttlInfo, _, err := getTTLInfoInOptions(options, tb.Meta())
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tb.Meta().ID,
+		SchemaName: schema.Name.L,
+		Type:       model.ActionAlterTTLInfo,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{ttlInfo},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// AlterTableRemoveTTL removes the TTL of the table.
+func (d *ddl) AlterTableRemoveTTL(ctx sessionctx.Context, ident ast.Ident) error {
+	schema, tb, err := d.getSchemaAndTableByIdent(ctx, ident)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if tb.Meta().TTLInfo == nil {
+		return nil
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tb.Meta().ID,
+		SchemaName: schema.Name.L,
+		Type:       model.ActionAlterTTLRemove,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+func onAlterTTLInfo(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	ttlInfo := &model.TTLInfo{}
+	if err := job.DecodeArgs(ttlInfo); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tblInfo, err := getTableInfoAndCancelFaultJob(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	// The column may be changed after the job is submitted.
+	if err := checkTTLColumn(tblInfo, ttlInfo.ColumnName); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tblInfo.TTLInfo = ttlInfo
+	ver, err = updateVersionAndTableInfo(t, job, tblInfo, true)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.FinishTableJob(model.JobStateDone, model.StatePublic, ver, tblInfo)
+	return ver, nil
+}
+
+func onAlterTTLRemove(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	tblInfo, err := getTableInfoAndCancelFaultJob(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	tblInfo.TTLInfo = nil
+	ver, err = updateVersionAndTableInfo(t, job, tblInfo, true)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.FinishTableJob(model.JobStateDone, model.StatePublic, ver, tblInfo)
+	return ver, nil
+}
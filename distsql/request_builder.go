@@ -19,10 +19,12 @@ import (
 	"math"
 	"sort"
 	"sync/atomic"
+	"time"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/ddl/placement"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
@@ -157,6 +159,14 @@ func (builder *RequestBuilder) SetDAGRequest(dag *tipb.DAGRequest) *RequestBuild
 	return builder
 }
 
+// SetTiKVFallbackDAGRequest sets "TiKVFallbackData" for "kv.Request", the fallback is disabled if dag is nil.
+func (builder *RequestBuilder) SetTiKVFallbackDAGRequest(dag *tipb.DAGRequest) *RequestBuilder {
+	if builder.err == nil && dag != nil {
+		builder.Request.TiKVFallbackData, builder.err = dag.Marshal()
+	}
+	return builder
+}
+
 // SetAnalyzeRequest sets the request type to "ReqTypeAnalyze" and construct request data.
 func (builder *RequestBuilder) SetAnalyzeRequest(ana *tipb.AnalyzeReq) *RequestBuilder {
 	if builder.err == nil {
@@ -239,6 +249,7 @@ func (builder *RequestBuilder) getKVPriority(sv *variable.SessionVars) int {
 
 // SetFromSessionVars sets the following fields for "kv.Request" from session variables:
 // "Concurrency", "IsolationLevel", "NotFillCache", "TaskID", "Priority", "ReplicaRead", "ResourceGroupTagger".
+// The sequence numbers of the cop tasks are allocated from the statement if tidb_trace_cop_task_seq is on.
 func (builder *RequestBuilder) SetFromSessionVars(sv *variable.SessionVars) *RequestBuilder {
 	if builder.Request.Concurrency == 0 {
 		// Concurrency may be set to 1 by SetDAGRequest
@@ -251,8 +262,25 @@ func (builder *RequestBuilder) SetFromSessionVars(sv *variable.SessionVars) *Req
 	}
 	builder.Request.NotFillCache = sv.StmtCtx.NotFillCache
 	builder.Request.TaskID = sv.StmtCtx.TaskID
+	if sv.TraceCopTaskSeq {
+		builder.Request.CopTaskSeqAllocator = sv.StmtCtx.AllocCopTaskSeq
+	}
 	builder.Request.Priority = builder.getKVPriority(sv)
 	builder.Request.ReplicaRead = sv.GetReplicaRead()
+	builder.Request.PreferredZone = sv.GetTiFlashPreferredZone()
+	builder.Request.TiFlashReplicaReadNodes = sv.GetTiFlashReplicaReadNodes()
+	builder.Request.BatchCopRespChanSize = sv.BatchCopResponseChannelSize
+	builder.Request.BatchCopRespMaxBytes = sv.BatchCopResponseMaxBytes
+	builder.Request.BatchCopRespSpill = sv.EnableBatchCopResponseSpill && config.GetGlobalConfig().OOMUseTmpStorage
+	builder.Request.MaxRegionsPerBatchCopTask = sv.MaxRegionsPerBatchCopTask
+	builder.Request.BatchCopConcurrency = sv.BatchCopConcurrency
+	builder.Request.BatchCopStreamStallThreshold = sv.BatchCopStreamStallThreshold
+	builder.Request.BatchCopStreamStallReconnect = sv.BatchCopStreamStallReconnect
+	builder.Request.BatchCopTaskCache = sv.EnableBatchCopTaskCache
+	_, builder.Request.AllowFallbackToTiKV = sv.AllowFallbackToTiKV[kv.TiFlash]
+	if maxExecutionTime := sv.GetMaxExecutionTime(); maxExecutionTime > 0 {
+		builder.Request.MaxExecutionDeadline = sv.StartTime.Add(time.Duration(maxExecutionTime) * time.Millisecond)
+	}
 	builder.SetResourceGroupTagger(sv.StmtCtx)
 	return builder
 }
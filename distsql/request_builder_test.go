@@ -16,6 +16,7 @@ package distsql
 
 import (
 	"testing"
+	"time"
 
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser/mysql"
@@ -263,17 +264,18 @@ func TestRequestBuilder1(t *testing.T) {
 				EndKey:   kv.Key{0x74, 0x80, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0xc, 0x5f, 0x72, 0x80, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x23},
 			},
 		},
-		Cacheable:        true,
-		KeepOrder:        false,
-		Desc:             false,
-		Concurrency:      variable.DefDistSQLScanConcurrency,
-		IsolationLevel:   0,
-		Priority:         0,
-		NotFillCache:     false,
-		SyncLog:          false,
-		Streaming:        false,
-		ReplicaRead:      kv.ReplicaReadLeader,
-		ReadReplicaScope: kv.GlobalReplicaScope,
+		Cacheable:            true,
+		KeepOrder:            false,
+		Desc:                 false,
+		Concurrency:          variable.DefDistSQLScanConcurrency,
+		IsolationLevel:       0,
+		Priority:             0,
+		NotFillCache:         false,
+		SyncLog:              false,
+		Streaming:            false,
+		ReplicaRead:          kv.ReplicaReadLeader,
+		ReadReplicaScope:     kv.GlobalReplicaScope,
+		BatchCopRespChanSize: variable.DefTiDBBatchCopResponseChannelSize,
 	}
 	require.Equal(t, expect, actual)
 }
@@ -345,17 +347,18 @@ func TestRequestBuilder2(t *testing.T) {
 				EndKey:   kv.Key{0x74, 0x80, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0xc, 0x5f, 0x69, 0x80, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0xf, 0x3, 0x80, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x23},
 			},
 		},
-		Cacheable:        true,
-		KeepOrder:        false,
-		Desc:             false,
-		Concurrency:      variable.DefDistSQLScanConcurrency,
-		IsolationLevel:   0,
-		Priority:         0,
-		NotFillCache:     false,
-		SyncLog:          false,
-		Streaming:        false,
-		ReplicaRead:      kv.ReplicaReadLeader,
-		ReadReplicaScope: kv.GlobalReplicaScope,
+		Cacheable:            true,
+		KeepOrder:            false,
+		Desc:                 false,
+		Concurrency:          variable.DefDistSQLScanConcurrency,
+		IsolationLevel:       0,
+		Priority:             0,
+		NotFillCache:         false,
+		SyncLog:              false,
+		Streaming:            false,
+		ReplicaRead:          kv.ReplicaReadLeader,
+		ReadReplicaScope:     kv.GlobalReplicaScope,
+		BatchCopRespChanSize: variable.DefTiDBBatchCopResponseChannelSize,
 	}
 	require.Equal(t, expect, actual)
 }
@@ -393,17 +396,18 @@ func TestRequestBuilder3(t *testing.T) {
 				EndKey:   kv.Key{0x74, 0x80, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0xf, 0x5f, 0x72, 0x80, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x65},
 			},
 		},
-		Cacheable:        true,
-		KeepOrder:        false,
-		Desc:             false,
-		Concurrency:      variable.DefDistSQLScanConcurrency,
-		IsolationLevel:   0,
-		Priority:         0,
-		NotFillCache:     false,
-		SyncLog:          false,
-		Streaming:        false,
-		ReplicaRead:      kv.ReplicaReadLeader,
-		ReadReplicaScope: kv.GlobalReplicaScope,
+		Cacheable:            true,
+		KeepOrder:            false,
+		Desc:                 false,
+		Concurrency:          variable.DefDistSQLScanConcurrency,
+		IsolationLevel:       0,
+		Priority:             0,
+		NotFillCache:         false,
+		SyncLog:              false,
+		Streaming:            false,
+		ReplicaRead:          kv.ReplicaReadLeader,
+		ReadReplicaScope:     kv.GlobalReplicaScope,
+		BatchCopRespChanSize: variable.DefTiDBBatchCopResponseChannelSize,
 	}
 	require.Equal(t, expect, actual)
 }
@@ -437,21 +441,22 @@ func TestRequestBuilder4(t *testing.T) {
 		Build()
 	require.NoError(t, err)
 	expect := &kv.Request{
-		Tp:               103,
-		StartTs:          0x0,
-		Data:             []uint8{0x18, 0x0, 0x20, 0x0, 0x40, 0x0, 0x5a, 0x0},
-		KeyRanges:        keyRanges,
-		Cacheable:        true,
-		KeepOrder:        false,
-		Desc:             false,
-		Concurrency:      variable.DefDistSQLScanConcurrency,
-		IsolationLevel:   0,
-		Priority:         0,
-		Streaming:        true,
-		NotFillCache:     false,
-		SyncLog:          false,
-		ReplicaRead:      kv.ReplicaReadLeader,
-		ReadReplicaScope: kv.GlobalReplicaScope,
+		Tp:                   103,
+		StartTs:              0x0,
+		Data:                 []uint8{0x18, 0x0, 0x20, 0x0, 0x40, 0x0, 0x5a, 0x0},
+		KeyRanges:            keyRanges,
+		Cacheable:            true,
+		KeepOrder:            false,
+		Desc:                 false,
+		Concurrency:          variable.DefDistSQLScanConcurrency,
+		IsolationLevel:       0,
+		Priority:             0,
+		Streaming:            true,
+		NotFillCache:         false,
+		SyncLog:              false,
+		ReplicaRead:          kv.ReplicaReadLeader,
+		ReadReplicaScope:     kv.GlobalReplicaScope,
+		BatchCopRespChanSize: variable.DefTiDBBatchCopResponseChannelSize,
 	}
 	require.Equal(t, expect, actual)
 }
@@ -553,18 +558,19 @@ func TestRequestBuilder7(t *testing.T) {
 				Build()
 			require.NoError(t, err)
 			expect := &kv.Request{
-				Tp:               0,
-				StartTs:          0x0,
-				KeepOrder:        false,
-				Desc:             false,
-				Concurrency:      concurrency,
-				IsolationLevel:   0,
-				Priority:         0,
-				NotFillCache:     false,
-				SyncLog:          false,
-				Streaming:        false,
-				ReplicaRead:      replicaRead.replicaReadType,
-				ReadReplicaScope: kv.GlobalReplicaScope,
+				Tp:                   0,
+				StartTs:              0x0,
+				KeepOrder:            false,
+				Desc:                 false,
+				Concurrency:          concurrency,
+				IsolationLevel:       0,
+				Priority:             0,
+				NotFillCache:         false,
+				SyncLog:              false,
+				Streaming:            false,
+				ReplicaRead:          replicaRead.replicaReadType,
+				ReadReplicaScope:     kv.GlobalReplicaScope,
+				BatchCopRespChanSize: variable.DefTiDBBatchCopResponseChannelSize,
 			}
 			require.Equal(t, expect, actual)
 		})
@@ -578,17 +584,34 @@ func TestRequestBuilder8(t *testing.T) {
 		Build()
 	require.NoError(t, err)
 	expect := &kv.Request{
-		Tp:               0,
-		StartTs:          0x0,
-		Data:             []uint8(nil),
-		Concurrency:      variable.DefDistSQLScanConcurrency,
-		IsolationLevel:   0,
-		Priority:         0,
-		MemTracker:       (*memory.Tracker)(nil),
-		SchemaVar:        0,
-		ReadReplicaScope: kv.GlobalReplicaScope,
+		Tp:                   0,
+		StartTs:              0x0,
+		Data:                 []uint8(nil),
+		Concurrency:          variable.DefDistSQLScanConcurrency,
+		IsolationLevel:       0,
+		Priority:             0,
+		MemTracker:           (*memory.Tracker)(nil),
+		SchemaVar:            0,
+		ReadReplicaScope:     kv.GlobalReplicaScope,
+		BatchCopRespChanSize: variable.DefTiDBBatchCopResponseChannelSize,
 	}
 	require.Equal(t, expect, actual)
+
+	// The deadline is derived from the start time and max_execution_time of the statement.
+	sv.StartTime = time.Now()
+	sv.MaxExecutionTime = 1000
+	actual, err = (&RequestBuilder{}).
+		SetFromSessionVars(sv).
+		Build()
+	require.NoError(t, err)
+	require.Equal(t, sv.StartTime.Add(time.Second), actual.MaxExecutionDeadline)
+	sv.StmtCtx.HasMaxExecutionTime = true
+	sv.StmtCtx.MaxExecutionTime = 500
+	actual, err = (&RequestBuilder{}).
+		SetFromSessionVars(sv).
+		Build()
+	require.NoError(t, err)
+	require.Equal(t, sv.StartTime.Add(500*time.Millisecond), actual.MaxExecutionDeadline)
 }
 
 func TestTableRangesToKVRangesWithFbs(t *testing.T) {
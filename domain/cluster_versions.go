@@ -0,0 +1,103 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/ddl/placement"
+	"github.com/pingcap/tidb/domain/infosync"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/clustercap"
+	"github.com/pingcap/tidb/util/logutil"
+	pd "github.com/tikv/pd/client"
+	"go.uber.org/zap"
+)
+
+const (
+	// clusterVersionsRefreshInterval is how often the versions of the nodes are loaded, so the version-gated
+	// features are enabled soon after the cluster is upgraded.
+	clusterVersionsRefreshInterval = time.Minute
+	// clusterVersionsLoadTimeout is the timeout to load the versions of the nodes from etcd and PD.
+	clusterVersionsLoadTimeout = 10 * time.Second
+)
+
+// clusterVersionsKeeper refreshes the versions of the nodes in the cluster, which gate the features used by
+// the planner and DDL.
+func (do *Domain) clusterVersionsKeeper() {
+	defer util.Recover(metrics.LabelDomain, "clusterVersionsKeeper", nil, false)
+	ticker := time.NewTicker(clusterVersionsRefreshInterval)
+	defer func() {
+		ticker.Stop()
+		do.wg.Done()
+		logutil.BgLogger().Info("clusterVersionsKeeper exited.")
+	}()
+
+	for {
+		select {
+		case <-ticker.C:
+			do.refreshClusterVersions()
+		case <-do.exit:
+			return
+		}
+	}
+}
+
+func (do *Domain) refreshClusterVersions() {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterVersionsLoadTimeout)
+	defer cancel()
+	versions, err := loadClusterVersions(ctx, do.store)
+	if err != nil {
+		// The versions loaded last time are kept.
+		logutil.BgLogger().Warn("load the versions of the cluster failed", zap.Error(err))
+		return
+	}
+	clustercap.Update(versions)
+}
+
+// loadClusterVersions loads the versions of the TiDB servers from etcd, and the versions of the stores from PD.
+func loadClusterVersions(ctx context.Context, store kv.Storage) (map[clustercap.Component][]string, error) {
+	servers, err := infosync.GetAllServerInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[clustercap.Component][]string, 3)
+	for _, server := range servers {
+		versions[clustercap.TiDB] = append(versions[clustercap.TiDB], server.Version)
+	}
+	pdStore, ok := store.(kv.StorageWithPD)
+	if !ok {
+		return versions, nil
+	}
+	stores, err := pdStore.GetPDClient().GetAllStores(ctx, pd.WithExcludeTombstone())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, s := range stores {
+		component := clustercap.TiKV
+		for _, label := range s.GetLabels() {
+			if label.GetKey() == placement.EngineLabelKey && label.GetValue() == placement.EngineLabelTiFlash {
+				component = clustercap.TiFlash
+				break
+			}
+		}
+		versions[component] = append(versions[component], s.GetVersion())
+	}
+	return versions, nil
+}
@@ -283,6 +283,10 @@ func (do *Domain) tryLoadSchemaDiffs(m *meta.Meta, usedVersion, newVersion int64
 			// If diff is missing for any version between used and new version, we fall back to full reload.
 			return nil, nil, fmt.Errorf("failed to get schemadiff")
 		}
+		if diff.Type == model.ActionFlashbackCluster {
+			// Flashback cluster may change all the schemas, we fall back to full reload.
+			return nil, nil, fmt.Errorf("schemas are flashed back")
+		}
 		diffs = append(diffs, diff)
 	}
 	builder := infoschema.NewBuilder(do.Store(), do.renewLeaseCh, do.sysFacHack).InitWithOldInfoSchema(do.infoCache.GetLatest())
@@ -862,11 +866,14 @@ func (do *Domain) Init(ddlLease time.Duration, sysExecutorFactory func(*Domain)
 		// Local store needs to get the change information for every DDL state in each session.
 		go do.loadSchemaInLoop(ctx, ddlLease)
 	}
-	do.wg.Add(4)
+	// The versions are loaded before serving, so the statements don't use the features some nodes don't support.
+	do.refreshClusterVersions()
+	do.wg.Add(5)
 	go do.topNSlowQueryLoop()
 	go do.infoSyncerKeeper()
 	go do.renewLease()
 	go do.globalConfigSyncerKeeper()
+	go do.clusterVersionsKeeper()
 	if !skipRegisterToDashboard {
 		do.wg.Add(1)
 		go do.topologySyncerKeeper()
@@ -1238,6 +1245,12 @@ func (do *Domain) PlanReplayerLoop() {
 	}()
 }
 
+// IndexUsageSyncLease returns the lease of syncing the index usages to mysql.schema_index_usage, the index usages
+// are not synced if it's 0.
+func (do *Domain) IndexUsageSyncLease() time.Duration {
+	return do.indexUsageSyncLease
+}
+
 // StatsHandle returns the statistic handle.
 func (do *Domain) StatsHandle() *handle.Handle {
 	return (*handle.Handle)(atomic.LoadPointer(&do.statsHandle))
@@ -105,6 +105,7 @@ type InfoSyncer struct {
 	labelRuleManager        LabelRuleManager
 	placementManager        PlacementManager
 	tiflashPlacementManager TiFlashPlacementManager
+	scheduleManager         ScheduleManager
 }
 
 // ServerInfo is server static information.
@@ -187,10 +188,12 @@ func GlobalInfoSyncerInit(ctx context.Context, id string, serverIDGetter func()
 		is.labelRuleManager = initLabelRuleManager(etcdCli.Endpoints())
 		is.placementManager = initPlacementManager(etcdCli.Endpoints())
 		is.tiflashPlacementManager = initTiFlashPlacementManager(etcdCli.Endpoints())
+		is.scheduleManager = initScheduleManager(etcdCli.Endpoints())
 	} else {
 		is.labelRuleManager = initLabelRuleManager([]string{})
 		is.placementManager = initPlacementManager([]string{})
 		is.tiflashPlacementManager = initTiFlashPlacementManager([]string{})
+		is.scheduleManager = initScheduleManager([]string{})
 	}
 	setGlobalInfoSyncer(is)
 	return is, nil
@@ -232,6 +235,13 @@ func initPlacementManager(addrs []string) PlacementManager {
 	return &PDPlacementManager{addrs: addrs}
 }
 
+func initScheduleManager(addrs []string) ScheduleManager {
+	if len(addrs) == 0 {
+		return &mockScheduleManager{}
+	}
+	return &PDScheduleManager{addrs: addrs}
+}
+
 func initTiFlashPlacementManager(addrs []string) TiFlashPlacementManager {
 	if len(addrs) == 0 {
 		m := mockTiFlashPlacementManager{}
@@ -464,6 +474,26 @@ func PutRuleBundles(ctx context.Context, bundles []*placement.Bundle) error {
 	return is.placementManager.PutRuleBundles(ctx, bundles)
 }
 
+// GetPDScheduleConfig is used to get the schedule config of PD.
+func GetPDScheduleConfig(ctx context.Context) (map[string]interface{}, error) {
+	is, err := getGlobalInfoSyncer()
+	if err != nil {
+		return nil, err
+	}
+
+	return is.scheduleManager.GetPDScheduleConfig(ctx)
+}
+
+// SetPDScheduleConfig is used to set the items of the schedule config of PD.
+func SetPDScheduleConfig(ctx context.Context, config map[string]interface{}) error {
+	is, err := getGlobalInfoSyncer()
+	if err != nil {
+		return err
+	}
+
+	return is.scheduleManager.SetPDScheduleConfig(ctx, config)
+}
+
 // PutRuleBundlesWithRetry will retry for specified times when PutRuleBundles failed
 func PutRuleBundlesWithRetry(ctx context.Context, bundles []*placement.Bundle, maxRetry int, interval time.Duration) (err error) {
 	if maxRetry < 0 {
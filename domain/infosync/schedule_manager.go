@@ -0,0 +1,90 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infosync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path"
+	"sync"
+
+	"github.com/pingcap/tidb/util/pdapi"
+)
+
+// ScheduleManager manages the schedule config of PD.
+type ScheduleManager interface {
+	// GetPDScheduleConfig is used to get the schedule config of PD.
+	GetPDScheduleConfig(ctx context.Context) (map[string]interface{}, error)
+	// SetPDScheduleConfig is used to set the items of the schedule config of PD.
+	SetPDScheduleConfig(ctx context.Context, config map[string]interface{}) error
+}
+
+// PDScheduleManager manages the schedule config with pd
+type PDScheduleManager struct {
+	addrs []string
+}
+
+// GetPDScheduleConfig is used to get the schedule config of PD.
+func (m *PDScheduleManager) GetPDScheduleConfig(ctx context.Context) (map[string]interface{}, error) {
+	var config map[string]interface{}
+	res, err := doRequest(ctx, m.addrs, path.Join(pdapi.Config, "schedule"), "GET", nil)
+	if err == nil && res != nil {
+		err = json.Unmarshal(res, &config)
+	}
+	return config, err
+}
+
+// SetPDScheduleConfig is used to set the items of the schedule config of PD.
+func (m *PDScheduleManager) SetPDScheduleConfig(ctx context.Context, config map[string]interface{}) error {
+	if len(config) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	_, err = doRequest(ctx, m.addrs, pdapi.Config, "POST", bytes.NewReader(b))
+	return err
+}
+
+type mockScheduleManager struct {
+	sync.Mutex
+	config map[string]interface{}
+}
+
+func (m *mockScheduleManager) GetPDScheduleConfig(_ context.Context) (map[string]interface{}, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	config := make(map[string]interface{}, len(m.config))
+	for k, v := range m.config {
+		config[k] = v
+	}
+	return config, nil
+}
+
+func (m *mockScheduleManager) SetPDScheduleConfig(_ context.Context, config map[string]interface{}) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.config == nil {
+		m.config = make(map[string]interface{})
+	}
+	for k, v := range config {
+		m.config[k] = v
+	}
+	return nil
+}
@@ -196,6 +196,8 @@ func (do *Domain) checkEnableServerGlobalVar(name, sVal string) {
 		err = stmtsummary.StmtSummaryByDigestMap.SetMaxStmtCount(uint(variable.TidbOptInt(sVal, variable.DefTiDBStmtSummaryMaxStmtCount)))
 	case variable.TiDBStmtSummaryMaxSQLLength:
 		err = stmtsummary.StmtSummaryByDigestMap.SetMaxSQLLength(variable.TidbOptInt(sVal, variable.DefTiDBStmtSummaryMaxSQLLength))
+	case variable.TiDBStmtSummaryPlanHistorySize:
+		err = stmtsummary.StmtSummaryByDigestMap.SetPlanHistorySize(variable.TidbOptInt(sVal, variable.DefTiDBStmtSummaryPlanHistorySize))
 	case variable.TiDBTopSQLMaxTimeSeriesCount:
 		var val int64
 		val, err = strconv.ParseInt(sVal, 10, 64)
@@ -908,6 +908,9 @@ const (
 	ErrFunctionalIndexDataIsTooLong                          = 3907
 	ErrFunctionalIndexNotApplicable                          = 3909
 	ErrDynamicPrivilegeNotRegistered                         = 3929
+	ErrXBadMessage                                           = 5000
+	ErrXCapabilitiesPrepareFailed                            = 5001
+	ErrXCapabilityNotFound                                   = 5002
 	// MariaDB errors.
 	ErrOnlyOneDefaultPartionAllowed         = 4030
 	ErrWrongPartitionTypeExpectedSystemTime = 4113
@@ -1061,6 +1064,17 @@ const (
 	ErrPlacementPolicyInUse               = 8241
 	ErrOptOnCacheTable                    = 8242
 	ErrHTTPServiceError                   = 8243
+	ErrUnknownClusterSetting              = 8244
+	ErrClusterSettingReadOnly             = 8245
+	ErrWrongValueForClusterSetting        = 8246
+	ErrWriteInHistoryRead                 = 8247
+	ErrStorageNotTiKV                     = 8248
+	ErrCommutativeUpdateCantRetry         = 8249
+	ErrRowSizeTooLarge                    = 8250
+	ErrColumnValueTooLarge                = 8251
+	ErrStmtClassNotAllowed                = 8252
+	ErrBatchCopQueueFull                  = 8253
+	ErrReturningCantRetry                 = 8254
 	// TiKV/PD/TiFlash errors.
 	ErrPDServerTimeout           = 9001
 	ErrTiKVServerTimeout         = 9002
@@ -911,6 +911,9 @@ var MySQLErrName = map[uint16]*mysql.ErrMessage{
 	ErrCTERecursiveForbiddenJoinOrder:                        mysql.Message("In recursive query block of Recursive Common Table Expression '%s', the recursive table must neither be in the right argument of a LEFT JOIN, nor be forced to be non-first with join order hints", nil),
 	ErrInvalidRequiresSingleReference:                        mysql.Message("In recursive query block of Recursive Common Table Expression '%s', the recursive table must be referenced only once, and not in any subquery", nil),
 	ErrCTEMaxRecursionDepth:                                  mysql.Message("Recursive query aborted after %d iterations. Try increasing @@cte_max_recursion_depth to a larger value", nil),
+	ErrXBadMessage:                                           mysql.Message("Invalid message: %s", nil),
+	ErrXCapabilitiesPrepareFailed:                            mysql.Message("Capability prepare failed for '%s'", nil),
+	ErrXCapabilityNotFound:                                   mysql.Message("Capability '%s' doesn't exist", nil),
 	// MariaDB errors.
 	ErrOnlyOneDefaultPartionAllowed:         mysql.Message("Only one DEFAULT partition allowed", nil),
 	ErrWrongPartitionTypeExpectedSystemTime: mysql.Message("Wrong partitioning type, expected type: `SYSTEM_TIME`", nil),
@@ -1067,6 +1070,17 @@ var MySQLErrName = map[uint16]*mysql.ErrMessage{
 	ErrPlacementPolicyWithDirectOption: mysql.Message("Placement policy '%s' can't co-exist with direct placement options", nil),
 	ErrPlacementPolicyInUse:            mysql.Message("Placement policy '%-.192s' is still in use", nil),
 	ErrOptOnCacheTable:                 mysql.Message("'%s' is unsupported on cache tables.", nil),
+	ErrUnknownClusterSetting:           mysql.Message("Unknown cluster setting '%-.64s'", nil),
+	ErrClusterSettingReadOnly:          mysql.Message("Cluster setting '%-.64s' is read-only, it's maintained by TiDB", nil),
+	ErrWrongValueForClusterSetting:     mysql.Message("Cluster setting '%-.64s' can't be set to the value of '%-.200s': %s", nil),
+	ErrWriteInHistoryRead:              mysql.Message("can not execute write statement when '%s' is set", nil),
+	ErrStorageNotTiKV:                  mysql.Message("%s is only supported when the storage is TiKV", nil),
+	ErrCommutativeUpdateCantRetry:      mysql.Message("[%d] can not retry the commutative updates as the updated rows changed", nil),
+	ErrRowSizeTooLarge:                 mysql.Message("The row of table '%-.192s' is %d bytes, exceeds the max row size %d bytes, the largest column is '%-.192s' of %d bytes", nil),
+	ErrColumnValueTooLarge:             mysql.Message("The value of column '%-.192s' is %d bytes, exceeds the max column size %d bytes", nil),
+	ErrStmtClassNotAllowed:             mysql.Message("The %s statements are not allowed on this server, the allowed statement classes are %s", nil),
+	ErrBatchCopQueueFull:               mysql.Message("Too many batch cop requests are running on this server, %d are running and %d are waiting, please retry later or raise max-batch-cop-queries and batch-cop-queue-size", nil),
+	ErrReturningCantRetry:              mysql.Message("[%d] can not retry the statement with a RETURNING clause", nil),
 	// TiKV/PD errors.
 	ErrPDServerTimeout:           mysql.Message("PD server timeout", nil),
 	ErrTiKVServerTimeout:         mysql.Message("TiKV server timeout", nil),
@@ -0,0 +1,152 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errno
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/pingcap/tidb/parser/mysql"
+)
+
+const (
+	// tidbErrorCodeFirst is the first TiDB specific error code, smaller codes come from MySQL or MariaDB.
+	tidbErrorCodeFirst = 8000
+	// errorDocURLPrefix is the prefix of the documentation page of TiDB specific error codes.
+	errorDocURLPrefix = "https://docs.pingcap.com/tidb/stable/error-codes#error-"
+)
+
+// tidbState maps TiDB specific error codes to SQLSTATE values. MySQL error codes are mapped
+// by mysql.MySQLState, so only codes that do not exist in MySQL should be listed here.
+var tidbState = map[uint16]string{
+	// Errors that can be resolved by retrying the transaction are reported as serialization failures.
+	ErrWriteConflict:       "40001",
+	ErrWriteConflictInTiDB: "40001",
+	ErrTxnRetryable:        "40001",
+	ErrInfoSchemaChanged:   "40001",
+	ErrLockExpire:          "40001",
+	ErrResolveLockTimeout:  "40001",
+	// Errors caused by the memory quota are reported as memory allocation errors.
+	ErrMemExceedThreshold: "HY001",
+	ErrTxnTooLarge:        "HY001",
+	ErrEntryTooLarge:      "HY001",
+	// Errors caused by the unavailable storage layer, like ErrTiKVServerTimeout, are kept on HY000. The class 08
+	// means a connection exception, the drivers and the connection pools would evict the connection for them.
+}
+
+// ErrorInfo is the user facing information of an error code.
+type ErrorInfo struct {
+	// Class is the class name of the error, e.g. "executor" or "kv".
+	Class    string
+	Code     uint16
+	SQLState string
+	// DocURL points to the documentation of the error. It is empty for MySQL error codes.
+	DocURL string
+	// Message is the message template of the error, the arguments are formatted by the printf verbs.
+	Message string
+}
+
+// printfVerbRegexp matches the printf verbs in the message templates.
+var printfVerbRegexp = regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]+)?[a-zA-Z]`)
+
+// messageArgRegexp matches the quoted strings and the numbers in the formatted messages, which are usually the
+// arguments of the messages.
+var messageArgRegexp = regexp.MustCompile("'[^']*'|\"[^\"]*\"|`[^`]*`|\\b[0-9]+([.:][0-9]+)*")
+
+var registry struct {
+	sync.RWMutex
+	infos map[uint16]*ErrorInfo
+}
+
+func init() {
+	registry.infos = make(map[uint16]*ErrorInfo)
+}
+
+// RegisterErrorInfo registers the class of an error code. It is called when the error is defined, so that
+// the class of an error can be found by its code only. The first registered class wins if a code is
+// shared by several classes.
+func RegisterErrorInfo(class string, code uint16) {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.infos[code]; ok {
+		return
+	}
+	registry.infos[code] = &ErrorInfo{
+		Class:    class,
+		Code:     code,
+		SQLState: SQLState(code),
+		DocURL:   DocURL(code),
+		Message:  messageTemplate(code),
+	}
+}
+
+// GetErrorInfo returns the registered information of an error code.
+func GetErrorInfo(code uint16) (ErrorInfo, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	if info, ok := registry.infos[code]; ok {
+		return *info, true
+	}
+	return ErrorInfo{Code: code, SQLState: SQLState(code), DocURL: DocURL(code), Message: messageTemplate(code)}, false
+}
+
+func messageTemplate(code uint16) string {
+	if msg, ok := MySQLErrName[code]; ok {
+		return msg.Raw
+	}
+	return ""
+}
+
+// SQLState returns the stable SQLSTATE of an error code.
+func SQLState(code uint16) string {
+	if state, ok := mysql.MySQLState[code]; ok {
+		return state
+	}
+	if state, ok := tidbState[code]; ok {
+		return state
+	}
+	return mysql.DefaultMySQLState
+}
+
+// DocURL returns the documentation URL of a TiDB specific error code, or an empty string for MySQL
+// error codes which are documented by MySQL.
+func DocURL(code uint16) string {
+	if code < tidbErrorCodeFirst {
+		return ""
+	}
+	return fmt.Sprintf("%s%d", errorDocURLPrefix, code)
+}
+
+// FormatMessage appends the documentation URL to the message of an error if it has one.
+// The message is expected to be redacted already, the URL itself contains no user data.
+func FormatMessage(code uint16, msg string) string {
+	info, _ := GetErrorInfo(code)
+	if info.DocURL == "" {
+		return msg
+	}
+	return fmt.Sprintf("%s, see %s", msg, info.DocURL)
+}
+
+// RedactTemplate replaces the printf verbs in a message template by "?".
+func RedactTemplate(template string) string {
+	return printfVerbRegexp.ReplaceAllString(template, "?")
+}
+
+// RedactMessage replaces the quoted strings and the numbers in a formatted message by "?". It's used to redact the
+// messages of the errors that aren't defined by TiDB, whose arguments are unknown.
+func RedactMessage(msg string) string {
+	return messageArgRegexp.ReplaceAllString(msg, "?")
+}
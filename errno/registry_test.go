@@ -0,0 +1,59 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errno
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLState(t *testing.T) {
+	// MySQL error codes keep the MySQL SQLSTATE.
+	require.Equal(t, "23000", SQLState(ErrDupEntry))
+	require.Equal(t, "HY000", SQLState(ErrUnknown))
+	// TiDB error codes are mapped by their meaning.
+	require.Equal(t, "40001", SQLState(ErrWriteConflict))
+	require.Equal(t, "HY001", SQLState(ErrMemExceedThreshold))
+	require.Equal(t, "HY000", SQLState(ErrTiKVServerTimeout))
+	require.Equal(t, "HY000", SQLState(ErrAdminCheckTable))
+}
+
+func TestErrorInfo(t *testing.T) {
+	RegisterErrorInfo("kv", ErrWriteConflict)
+	// The first registered class wins.
+	RegisterErrorInfo("executor", ErrWriteConflict)
+	info, ok := GetErrorInfo(ErrWriteConflict)
+	require.True(t, ok)
+	require.Equal(t, "kv", info.Class)
+	require.Equal(t, "40001", info.SQLState)
+	require.Equal(t, "https://docs.pingcap.com/tidb/stable/error-codes#error-9007", info.DocURL)
+
+	info, ok = GetErrorInfo(ErrDupEntry)
+	require.False(t, ok)
+	require.Equal(t, "23000", info.SQLState)
+	require.Equal(t, "", info.DocURL)
+
+	require.Equal(t, "Duplicate entry", FormatMessage(ErrDupEntry, "Duplicate entry"))
+	require.Equal(t, "Write conflict, see https://docs.pingcap.com/tidb/stable/error-codes#error-9007", FormatMessage(ErrWriteConflict, "Write conflict"))
+
+	// The arguments of the message templates are redacted.
+	require.Equal(t, "Duplicate entry '?' for key '?'", RedactTemplate(MySQLErrName[ErrDupEntry].Raw))
+	require.Equal(t, "Unknown column '?' in '?'", RedactTemplate(MySQLErrName[ErrBadField].Raw))
+	// The quoted strings and the numbers of the formatted messages are redacted.
+	require.Equal(t, "key ? not found", RedactMessage("key 'secret' not found"))
+	require.Equal(t, "dial tcp ?: i/o timeout", RedactMessage("dial tcp 10.0.0.1:20160: i/o timeout"))
+	require.Equal(t, "context canceled", RedactMessage("context canceled"))
+}
@@ -47,6 +47,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessiontxn"
+	derr "github.com/pingcap/tidb/store/driver/error"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/execdetails"
@@ -88,6 +89,9 @@ type recordSet struct {
 	stmt       *ExecStmt
 	lastErr    error
 	txnStartTS uint64
+	// returned is whether any rows are returned, the statement can't be retried after that.
+	returned   bool
+	retryCount int
 }
 
 func (a *recordSet) Fields() []*ast.ResultField {
@@ -147,11 +151,24 @@ func (a *recordSet) Next(ctx context.Context, req *chunk.Chunk) (err error) {
 	}()
 
 	err = Next(ctx, a.executor, req)
+	for err != nil && a.canRetry(err) {
+		a.retryCount++
+		logutil.Logger(ctx).Warn("read-only statement meets transient store error, execute it again",
+			zap.Int("retry count", a.retryCount), zap.Uint64("txnStartTS", a.txnStartTS), zap.Error(err))
+		if err = a.rebuildExecutor(ctx); err != nil {
+			break
+		}
+		req.Reset()
+		err = Next(ctx, a.executor, req)
+	}
 	if err != nil {
 		a.lastErr = err
 		return err
 	}
 	numRows := req.NumRows()
+	if numRows > 0 {
+		a.returned = true
+	}
 	if numRows == 0 {
 		if a.stmt != nil {
 			a.stmt.Ctx.GetSessionVars().LastFoundRows = a.stmt.Ctx.GetSessionVars().StmtCtx.FoundRows()
@@ -164,6 +181,86 @@ func (a *recordSet) Next(ctx context.Context, req *chunk.Chunk) (err error) {
 	return nil
 }
 
+// canRetry returns whether the statement can be executed again after meeting the error. Only the read-only
+// statements that have not returned any rows are retried, they read the same snapshot of the transaction
+// again, so the client sees nothing but a slower statement. The statements calling the functions which give
+// different results or have side effects when they are called again are not retried.
+func (a *recordSet) canRetry(err error) bool {
+	if a.stmt == nil || a.returned {
+		return false
+	}
+	vars := a.stmt.Ctx.GetSessionVars()
+	return a.retryCount < vars.ReadOnlyStmtRetryLimit && derr.IsTransientStoreError(err) && a.stmt.IsReadOnly(vars) &&
+		!hasRetryUnsafeFunc(a.stmt.StmtNode, vars)
+}
+
+// retryUnsafeFunctions are the functions giving different results or having side effects when they are called
+// again. The time functions other than sysdate are not included since they return the same time in a statement.
+var retryUnsafeFunctions = map[string]struct{}{
+	ast.Rand:            {},
+	ast.RandomBytes:     {},
+	ast.UUID:            {},
+	ast.UUIDShort:       {},
+	ast.Sleep:           {},
+	ast.Sysdate:         {},
+	ast.NextVal:         {},
+	ast.SetVal:          {},
+	ast.GetLock:         {},
+	ast.ReleaseLock:     {},
+	ast.ReleaseAllLocks: {},
+	ast.IsFreeLock:      {},
+	ast.IsUsedLock:      {},
+}
+
+// hasRetryUnsafeFunc checks whether the statement calls any of retryUnsafeFunctions.
+func hasRetryUnsafeFunc(node ast.StmtNode, vars *variable.SessionVars) bool {
+	if execStmt, ok := node.(*ast.ExecuteStmt); ok {
+		prepared, err := plannercore.GetPreparedStmt(execStmt, vars)
+		if err != nil {
+			return true
+		}
+		node = prepared.PreparedAst.Stmt
+	}
+	checker := &retryUnsafeFuncChecker{}
+	node.Accept(checker)
+	return checker.found
+}
+
+type retryUnsafeFuncChecker struct {
+	found bool
+}
+
+// Enter implements ast.Visitor interface.
+func (c *retryUnsafeFuncChecker) Enter(in ast.Node) (ast.Node, bool) {
+	if fn, ok := in.(*ast.FuncCallExpr); ok {
+		if _, ok := retryUnsafeFunctions[fn.FnName.L]; ok {
+			c.found = true
+		}
+	}
+	return in, c.found
+}
+
+// Leave implements ast.Visitor interface.
+func (c *retryUnsafeFuncChecker) Leave(in ast.Node) (ast.Node, bool) {
+	return in, !c.found
+}
+
+// rebuildExecutor replaces the executor with a new one built from the same plan. The old executor is kept
+// if the new one can't be opened, so it's still closed by Close.
+func (a *recordSet) rebuildExecutor(ctx context.Context) error {
+	e, err := a.stmt.buildExecutor()
+	if err != nil {
+		return err
+	}
+	if err = e.Open(ctx); err != nil {
+		terror.Call(e.Close)
+		return err
+	}
+	terror.Call(a.executor.Close)
+	a.executor = e
+	return nil
+}
+
 // NewChunk create a chunk base on top-level executor's newFirstChunk().
 func (a *recordSet) NewChunk(alloc chunk.Allocator) *chunk.Chunk {
 	if alloc == nil {
@@ -219,11 +316,15 @@ type ExecStmt struct {
 	isSelectForUpdate bool
 	retryCount        uint
 	retryStartTime    time.Time
+	// planCacheHit is the cached plan used by the prepared statement, it's nil if the plan is not cached.
+	planCacheHit *plannercore.PlanCacheHit
 
 	// OutputNames will be set if using cached plan
 	OutputNames []*types.FieldName
 	PsStmt      *plannercore.CachedPrepareStmt
 	Ti          *TelemetryInfo
+	// admission releases the statement from the AdmissionQueue, it's nil if the statement is not admitted by it.
+	admission func()
 }
 
 // PointGet short path for point exec directly from plan, keep only necessary steps
@@ -345,7 +446,7 @@ func (a *ExecStmt) setPlanLabelForTopSQL(ctx context.Context) context.Context {
 // Exec builds an Executor from a plan. If the Executor doesn't return result,
 // like the INSERT, UPDATE statements, it executes in this function, if the Executor returns
 // result, execution is done after this function returns, in the returned sqlexec.RecordSet Next method.
-func (a *ExecStmt) Exec(ctx context.Context) (_ sqlexec.RecordSet, err error) {
+func (a *ExecStmt) Exec(ctx context.Context) (rs sqlexec.RecordSet, err error) {
 	defer func() {
 		r := recover()
 		if r == nil {
@@ -358,7 +459,7 @@ func (a *ExecStmt) Exec(ctx context.Context) (_ sqlexec.RecordSet, err error) {
 			}
 			return
 		}
-		if str, ok := r.(string); !ok || !strings.Contains(str, memory.PanicMemoryExceed) {
+		if str, ok := r.(string); !ok || !(strings.Contains(str, memory.PanicMemoryExceed) || strings.Contains(str, memory.PanicStorageExceed)) {
 			panic(r)
 		}
 		err = errors.Errorf("%v", r)
@@ -404,30 +505,23 @@ func (a *ExecStmt) Exec(ctx context.Context) (_ sqlexec.RecordSet, err error) {
 	ctx = a.setPlanLabelForTopSQL(ctx)
 	a.observeStmtBeginForTopSQL()
 
+	// The statement is classified by its plan, so it's admitted after `a.buildExecutor` too.
+	if err = a.admit(ctx); err != nil {
+		return nil, err
+	}
+	defer func() {
+		// The statements returning the record sets are released when the record sets are closed.
+		if rs == nil {
+			a.releaseAdmission()
+		}
+	}()
+
 	if err = e.Open(ctx); err != nil {
 		terror.Call(e.Close)
 		return nil, err
 	}
 
-	cmd32 := atomic.LoadUint32(&sctx.GetSessionVars().CommandValue)
-	cmd := byte(cmd32)
-	var pi processinfoSetter
-	if raw, ok := sctx.(processinfoSetter); ok {
-		pi = raw
-		sql := a.OriginText()
-		if simple, ok := a.Plan.(*plannercore.Simple); ok && simple.Statement != nil {
-			if ss, ok := simple.Statement.(ast.SensitiveStmtNode); ok {
-				// Use SecureText to avoid leak password information.
-				sql = ss.SecureText()
-			}
-		}
-		maxExecutionTime := getMaxExecutionTime(sctx)
-		// Update processinfo, ShowProcess() will use it.
-		pi.SetProcessInfo(sql, time.Now(), cmd, maxExecutionTime)
-		if a.Ctx.GetSessionVars().StmtCtx.StmtType == "" {
-			a.Ctx.GetSessionVars().StmtCtx.StmtType = GetStmtLabel(a.StmtNode)
-		}
-	}
+	a.setProcessInfo()
 
 	isPessimistic := sctx.GetSessionVars().TxnCtx.IsPessimistic
 
@@ -485,10 +579,24 @@ func (a *ExecStmt) handleNoDelay(ctx context.Context, e Executor, isPessimistic
 	if toCheck.Schema().Len() == 0 {
 		handled = !isExplainAnalyze
 		if isPessimistic {
-			return handled, nil, a.handlePessimisticDML(ctx, toCheck)
+			_, err := a.handlePessimisticDML(ctx, toCheck)
+			return handled, nil, err
 		}
 		r, err := a.handleNoDelayExecutor(ctx, toCheck)
 		return handled, r, err
+	} else if getDMLReturning(toCheck) != nil && !isExplainAnalyze {
+		// The results of the DML with a RETURNING clause are streamed to the client chunk by chunk,
+		// unless the keys must be locked after the execution, in which case the DML is executed without
+		// delay and its results are buffered. The optimistic txn can't retry once the results are returned,
+		// like SELECT FOR UPDATE.
+		if isPessimistic {
+			r, err := a.handlePessimisticDML(ctx, toCheck)
+			return true, r, err
+		}
+		if err := checkWriteInHistoryRead(a.Ctx, toCheck); err != nil {
+			return true, nil, err
+		}
+		atomic.StoreUint32(&a.Ctx.GetSessionVars().TxnCtx.Returning, 1)
 	} else if proj, ok := toCheck.(*ProjectionExec); ok && proj.calculateNoDelay {
 		// Currently this is only for the "DO" statement. Take "DO 1, @a=2;" as an example:
 		// the Projection has two expressions and two columns in the schema, but we should
@@ -521,12 +629,31 @@ func isNoResultPlan(p plannercore.Plan) bool {
 	return false
 }
 
+// setProcessInfo updates the processinfo of the session, ShowProcess() will use it.
+func (a *ExecStmt) setProcessInfo() {
+	sctx := a.Ctx
+	pi, ok := sctx.(processinfoSetter)
+	if !ok {
+		return
+	}
+	cmd := byte(atomic.LoadUint32(&sctx.GetSessionVars().CommandValue))
+	sql := a.OriginText()
+	if simple, ok := a.Plan.(*plannercore.Simple); ok && simple.Statement != nil {
+		if ss, ok := simple.Statement.(ast.SensitiveStmtNode); ok {
+			// Use SecureText to avoid leak password information.
+			sql = ss.SecureText()
+		}
+	}
+	maxExecutionTime := getMaxExecutionTime(sctx)
+	pi.SetProcessInfo(sql, time.Now(), cmd, maxExecutionTime)
+	if sctx.GetSessionVars().StmtCtx.StmtType == "" {
+		sctx.GetSessionVars().StmtCtx.StmtType = GetStmtLabel(a.StmtNode)
+	}
+}
+
 // getMaxExecutionTime get the max execution timeout value.
 func getMaxExecutionTime(sctx sessionctx.Context) uint64 {
-	if sctx.GetSessionVars().StmtCtx.HasMaxExecutionTime {
-		return sctx.GetSessionVars().StmtCtx.MaxExecutionTime
-	}
-	return sctx.GetSessionVars().MaxExecutionTime
+	return sctx.GetSessionVars().GetMaxExecutionTime()
 }
 
 type chunkRowRecordSet struct {
@@ -618,42 +745,81 @@ func (a *ExecStmt) handleNoDelayExecutor(ctx context.Context, e Executor) (sqlex
 		a.logAudit()
 	}()
 
-	// Check if "tidb_snapshot" is set for the write executors.
-	// In history read mode, we can not do write operations.
+	if err = checkWriteInHistoryRead(sctx, e); err != nil {
+		return nil, err
+	}
+
+	if getDMLReturning(e) != nil {
+		var rows []chunk.Row
+		req := newFirstChunk(e)
+		for {
+			if err = Next(ctx, e, req); err != nil {
+				return nil, err
+			}
+			if req.NumRows() == 0 {
+				fields := colNames2ResultFields(e.Schema(), a.OutputNames, sctx.GetSessionVars().CurrentDB)
+				return &chunkRowRecordSet{rows: rows, fields: fields, e: e, execStmt: a}, nil
+			}
+			iter := chunk.NewIterator4Chunk(req)
+			for r := iter.Begin(); r != iter.End(); r = iter.Next() {
+				rows = append(rows, r)
+			}
+			req = chunk.Renew(req, sctx.GetSessionVars().MaxChunkSize)
+		}
+	}
+
+	err = Next(ctx, e, newFirstChunk(e))
+	if err != nil {
+		return nil, err
+	}
+	return nil, err
+}
+
+// checkWriteInHistoryRead checks if "tidb_snapshot" is set for the write executors.
+// In history read mode, we can not do write operations.
+func checkWriteInHistoryRead(sctx sessionctx.Context, e Executor) error {
 	switch e.(type) {
 	case *DeleteExec, *InsertExec, *UpdateExec, *ReplaceExec, *LoadDataExec, *DDLExec:
 		snapshotTS := sctx.GetSessionVars().SnapshotTS
 		if snapshotTS != 0 {
-			return nil, errors.New("can not execute write statement when 'tidb_snapshot' is set")
+			return ErrWriteInHistoryRead.GenWithStackByArgs(variable.TiDBSnapshot)
 		}
 		lowResolutionTSO := sctx.GetSessionVars().LowResolutionTSO
 		if lowResolutionTSO {
-			return nil, errors.New("can not execute write statement when 'tidb_low_resolution_tso' is set")
+			return ErrWriteInHistoryRead.GenWithStackByArgs(variable.TiDBLowResolutionTSO)
 		}
 	}
+	return nil
+}
 
-	err = Next(ctx, e, newFirstChunk(e))
-	if err != nil {
-		return nil, err
+// getDMLReturning returns the RETURNING clause of the UPDATE or DELETE executor, or nil if there is none.
+func getDMLReturning(e Executor) *dmlReturning {
+	switch x := e.(type) {
+	case *DeleteExec:
+		return x.returning
+	case *UpdateExec:
+		return x.returning
 	}
-	return nil, err
+	return nil
 }
 
-func (a *ExecStmt) handlePessimisticDML(ctx context.Context, e Executor) error {
+// handlePessimisticDML executes the DML in a pessimistic transaction, and returns the results of the
+// last attempt if the DML has a RETURNING clause, because it may be rebuilt and retried when it meets a lock conflict.
+func (a *ExecStmt) handlePessimisticDML(ctx context.Context, e Executor) (sqlexec.RecordSet, error) {
 	sctx := a.Ctx
 	// Do not active the transaction here.
 	// When autocommit = 0 and transaction in pessimistic mode,
 	// statements like set xxx = xxx; should not active the transaction.
 	txn, err := sctx.Txn(false)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	txnCtx := sctx.GetSessionVars().TxnCtx
 	for {
 		startPointGetLocking := time.Now()
-		_, err = a.handleNoDelayExecutor(ctx, e)
+		rs, err := a.handleNoDelayExecutor(ctx, e)
 		if !txn.Valid() {
-			return err
+			return rs, err
 		}
 		if err != nil {
 			// It is possible the DML has point get plan that locks the key.
@@ -662,17 +828,17 @@ func (a *ExecStmt) handlePessimisticDML(ctx context.Context, e Executor) error {
 				if ErrDeadlock.Equal(err) {
 					metrics.StatementDeadlockDetectDuration.Observe(time.Since(startPointGetLocking).Seconds())
 				}
-				return err
+				return nil, err
 			}
 			continue
 		}
 		keys, err1 := txn.(pessimisticTxn).KeysNeedToLock()
 		if err1 != nil {
-			return err1
+			return nil, err1
 		}
 		keys = txnCtx.CollectUnchangedRowKeys(keys)
 		if len(keys) == 0 {
-			return nil
+			return rs, nil
 		}
 		keys = filterTemporaryTableKeys(sctx.GetSessionVars(), keys)
 		seVars := sctx.GetSessionVars()
@@ -686,7 +852,7 @@ func (a *ExecStmt) handlePessimisticDML(ctx context.Context, e Executor) error {
 			seVars.StmtCtx.MergeLockKeysExecDetails(lockKeyStats)
 		}
 		if err == nil {
-			return nil
+			return rs, nil
 		}
 		e, err = a.handlePessimisticLockError(ctx, err)
 		if err != nil {
@@ -694,7 +860,7 @@ func (a *ExecStmt) handlePessimisticDML(ctx context.Context, e Executor) error {
 			if ErrDeadlock.Equal(err) {
 				metrics.StatementDeadlockDetectDuration.Observe(time.Since(startLocking).Seconds())
 			}
-			return err
+			return nil, err
 		}
 	}
 }
@@ -867,6 +1033,7 @@ func (a *ExecStmt) buildExecutor() (Executor, error) {
 		a.OutputNames = executorExec.outputNames
 		a.isPreparedStmt = true
 		a.Plan = executorExec.plan
+		a.planCacheHit = executorExec.cacheHit
 		if executorExec.lowerPriority {
 			ctx.GetSessionVars().StmtCtx.Priority = kv.PriorityLow
 		}
@@ -947,6 +1114,10 @@ func (a *ExecStmt) FinishExecuteStmt(txnTS uint64, err error, hasMoreResults boo
 	a.LogSlowQuery(txnTS, succ, hasMoreResults)
 	a.SummaryStmt(succ)
 	a.observeStmtFinishedForTopSQL()
+	a.recordTableWorkload()
+	if succ && a.planCacheHit != nil {
+		plannercore.ObservePlanCacheHit(a.Ctx, a.planCacheHit)
+	}
 	if sessVars.StmtCtx.IsTiFlash.Load() {
 		if succ {
 			totalTiFlashQuerySuccCounter.Inc()
@@ -974,6 +1145,7 @@ func (a *ExecStmt) FinishExecuteStmt(txnTS uint64, err error, hasMoreResults boo
 
 // CloseRecordSet will finish the execution of current statement and do some record work
 func (a *ExecStmt) CloseRecordSet(txnStartTS uint64, lastErr error) {
+	a.releaseAdmission()
 	a.FinishExecuteStmt(txnStartTS, lastErr, false)
 	a.logAudit()
 	// Detach the Memory and disk tracker for the previous stmtCtx from GlobalMemoryUsageTracker and GlobalDiskUsageTracker
@@ -1041,6 +1213,7 @@ func (a *ExecStmt) LogSlowQuery(txnTS uint64, succ bool, hasMoreResults bool) {
 	_, planDigest := getPlanDigest(a.Ctx, a.Plan)
 	slowItems := &variable.SlowQueryLogItems{
 		TxnTS:             txnTS,
+		TraceID:           sessVars.StmtCtx.TaskID,
 		SQL:               sql.String(),
 		Digest:            digest.String(),
 		TimeTotal:         costTime,
@@ -1066,6 +1239,8 @@ func (a *ExecStmt) LogSlowQuery(txnTS uint64, succ bool, hasMoreResults bool) {
 		PDTotal:           time.Duration(atomic.LoadInt64(&tikvExecDetail.WaitPDRespDuration)),
 		BackoffTotal:      time.Duration(atomic.LoadInt64(&tikvExecDetail.BackoffDuration)),
 		WriteSQLRespTotal: stmtDetail.WriteSQLRespDuration,
+		ClientStallTotal:  stmtDetail.ClientStallDuration,
+		TiDBExecTime:      execdetails.TiDBExecDuration(costTime-sessVars.DurationParse-sessVars.DurationCompile, &stmtDetail, &tikvExecDetail),
 		ResultRows:        GetResultRowsCount(a.Ctx, a.Plan),
 		ExecRetryCount:    a.retryCount,
 		IsExplicitTxn:     sessVars.TxnCtx.IsExplicit,
@@ -1077,7 +1252,17 @@ func (a *ExecStmt) LogSlowQuery(txnTS uint64, succ bool, hasMoreResults bool) {
 	if _, ok := a.StmtNode.(*ast.CommitStmt); ok {
 		slowItems.PrevStmt = sessVars.PrevStmt.String()
 	}
-	slowLog := sessVars.SlowLogFormat(slowItems)
+	slowLogEntry := sessVars.NewSlowLogEntry(slowItems)
+	var slowLog, jsonLog string
+	if cfg.Log.SlowQueryFormat == logutil.SlowLogFormatJSON || logutil.SlowQueryCollectorEnabled() {
+		jsonLog = slowLogEntry.JSON()
+		logutil.PushSlowQuery(jsonLog)
+	}
+	if cfg.Log.SlowQueryFormat == logutil.SlowLogFormatJSON {
+		slowLog = jsonLog
+	} else {
+		slowLog = slowLogEntry.Text()
+	}
 	if trace.IsEnabled() {
 		trace.Log(a.GoCtx, "details", slowLog)
 	}
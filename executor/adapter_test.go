@@ -15,10 +15,15 @@
 package executor_test
 
 import (
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/pingcap/failpoint"
+	"github.com/pingcap/tidb/executor"
+	derr "github.com/pingcap/tidb/store/driver/error"
 	"github.com/pingcap/tidb/testkit"
+	"github.com/pingcap/tidb/util/admission"
 	"github.com/stretchr/testify/require"
 )
 
@@ -40,3 +45,119 @@ func TestQueryTime(t *testing.T) {
 	costTime = time.Since(tk.Session().GetSessionVars().StartTime)
 	require.Less(t, costTime, time.Second)
 }
+
+func TestReadOnlyStmtRetry(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int)")
+	tk.MustExec("insert into t values(1), (2), (3)")
+
+	fpName := "github.com/pingcap/tidb/store/copr/handleTaskOnceTransientError"
+	require.NoError(t, failpoint.Enable(fpName, `1*return(true)`))
+	err := tk.QueryToErr("select * from t")
+	require.True(t, derr.ErrTiKVServerTimeout.Equal(err))
+	require.NoError(t, failpoint.Disable(fpName))
+
+	// The statement is executed again from the same snapshot.
+	tk.MustExec("set @@tidb_read_only_stmt_retry_limit = 1")
+	tk.MustExec("begin")
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("3"))
+	tk2 := testkit.NewTestKit(t, store)
+	tk2.MustExec("use test")
+	tk2.MustExec("insert into t values(4)")
+	require.NoError(t, failpoint.Enable(fpName, `1*return(true)`))
+	tk.MustQuery("select * from t").Sort().Check(testkit.Rows("1", "2", "3"))
+	require.NoError(t, failpoint.Disable(fpName))
+	tk.MustExec("commit")
+
+	// The retry limit is respected.
+	require.NoError(t, failpoint.Enable(fpName, `2*return(true)`))
+	err = tk.QueryToErr("select * from t")
+	require.True(t, derr.ErrTiKVServerTimeout.Equal(err))
+	require.NoError(t, failpoint.Disable(fpName))
+
+	// The statements calling the functions with side effects are not retried.
+	tk.MustExec("create sequence seq")
+	tk.MustExec("set tidb_enable_noop_functions = 1")
+	for _, sql := range []string{
+		"select nextval(seq), a from t",
+		"select * from t where a > rand()",
+		"select get_lock('l', 1), a from t",
+	} {
+		require.NoError(t, failpoint.Enable(fpName, `1*return(true)`))
+		err = tk.QueryToErr(sql)
+		require.True(t, derr.ErrTiKVServerTimeout.Equal(err), "sql %s, err %v", sql, err)
+		require.NoError(t, failpoint.Disable(fpName))
+	}
+}
+
+func TestAdmissionQueue(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	defer func(q *admission.Queue) { executor.AdmissionQueue = q }(executor.AdmissionQueue)
+	executor.AdmissionQueue = admission.NewQueue(0, 1)
+
+	tk1 := testkit.NewTestKit(t, store)
+	tk1.MustExec("use test")
+	tk1.MustExec("create table t(a int)")
+	tk1.MustExec("insert into t values(1), (2), (3)")
+	tk2 := testkit.NewTestKit(t, store)
+	tk2.MustExec("use test")
+
+	// The batch statement holds the only slot of the class until its record set is closed.
+	rs, err := tk1.Exec("select /*+ QUERY_TYPE(OLAP) */ * from t")
+	require.NoError(t, err)
+	queued, running := executor.AdmissionQueue.Stats(admission.Batch)
+	require.Equal(t, 0, queued)
+	require.Equal(t, 1, running)
+
+	done := make(chan *testkit.Result)
+	go func() {
+		done <- tk2.MustQuery("select /*+ QUERY_TYPE(OLAP) */ count(*) from t")
+	}()
+	require.Eventually(t, func() bool {
+		queued, _ := executor.AdmissionQueue.Stats(admission.Batch)
+		return queued == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "queued", tk2.Session().ShowProcess().ToRowForShow(false)[6])
+
+	// The interactive statements are not blocked by the batch ones.
+	tk3 := testkit.NewTestKit(t, store)
+	tk3.MustQuery("select /*+ QUERY_TYPE(OLTP) */ a from test.t where a = 1").Check(testkit.Rows("1"))
+
+	require.NoError(t, rs.Close())
+	(<-done).Check(testkit.Rows("3"))
+	queued, running = executor.AdmissionQueue.Stats(admission.Batch)
+	require.Equal(t, 0, queued)
+	require.Equal(t, 0, running)
+
+	// The queued statement stops waiting once it's killed.
+	rs, err = tk1.Exec("select /*+ QUERY_TYPE(OLAP) */ * from t")
+	require.NoError(t, err)
+	errCh := make(chan error)
+	go func() {
+		errCh <- tk2.ExecToErr("select /*+ QUERY_TYPE(OLAP) */ * from t")
+	}()
+	require.Eventually(t, func() bool {
+		queued, _ := executor.AdmissionQueue.Stats(admission.Batch)
+		return queued == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	atomic.StoreUint32(&tk2.Session().GetSessionVars().Killed, 1)
+	require.True(t, executor.ErrQueryInterrupted.Equal(<-errCh))
+	atomic.StoreUint32(&tk2.Session().GetSessionVars().Killed, 0)
+
+	// The statements of a transaction holding the locks are not queued.
+	tk2.MustExec("begin pessimistic")
+	tk2.MustExec("update t set a = a + 10 where a = 1")
+	tk2.MustQuery("select /*+ QUERY_TYPE(OLAP) */ count(*) from t").Check(testkit.Rows("3"))
+	tk2.MustExec("rollback")
+	require.NoError(t, rs.Close())
+	queued, running = executor.AdmissionQueue.Stats(admission.Batch)
+	require.Equal(t, 0, queued)
+	require.Equal(t, 0, running)
+}
@@ -15,6 +15,7 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"math"
 
@@ -47,6 +48,7 @@ var (
 	_ Executor = &CheckIndexRangeExec{}
 	_ Executor = &RecoverIndexExec{}
 	_ Executor = &CleanupIndexExec{}
+	_ Executor = &RepairIndexExec{}
 )
 
 // CheckIndexRangeExec outputs the index values which has handle between begin and end.
@@ -197,6 +199,9 @@ type RecoverIndexExec struct {
 	idxValsBufs [][]types.Datum
 	idxKeyBufs  [][]byte
 	batchKeys   []kv.Key
+
+	// repair indicates the index is being repaired, the added entries are logged one by one.
+	repair bool
 }
 
 func (e *RecoverIndexExec) columnsTypes() []*types.FieldType {
@@ -472,6 +477,11 @@ func (e *RecoverIndexExec) backfillIndexInTxn(ctx context.Context, txn kv.Transa
 			return result, err
 		}
 		result.addedCount++
+		if e.repair {
+			logutil.Logger(ctx).Info("repair index: add the missing index entry", zap.String("table", e.table.Meta().Name.O),
+				zap.String("index", e.index.Meta().Name.O), zap.Stringer("handle", row.handle),
+				zap.String("indexValues", types.DatumsToStrNoErr(row.idxVals)))
+		}
 	}
 	return result, nil
 }
@@ -542,6 +552,13 @@ type CleanupIndexExec struct {
 	idxValsBufs [][]types.Datum
 	lastIdxKey  []byte
 	scanRowCnt  uint64
+
+	// repair indicates the index is being repaired, the removed entries are logged one by one and the rows are
+	// locked before their entries are removed.
+	repair bool
+	// rowIdxCols are the index columns decoded from the rows if the index entries not matching their rows are
+	// removed too, it's nil if only the dangling entries are removed.
+	rowIdxCols []*table.Column
 }
 
 func (e *CleanupIndexExec) getIdxColTypes() []*types.FieldType {
@@ -567,27 +584,61 @@ func (e *CleanupIndexExec) batchGetRecord(txn kv.Transaction) (map[string][]byte
 	return values, nil
 }
 
-func (e *CleanupIndexExec) deleteDanglingIdx(txn kv.Transaction, values map[string][]byte) error {
+func (e *CleanupIndexExec) deleteDanglingIdx(ctx context.Context, txn kv.Transaction, values map[string][]byte) error {
+	sc := e.ctx.GetSessionVars().StmtCtx
 	for _, k := range e.batchKeys {
-		if _, found := values[string(k)]; !found {
-			_, handle, err := tablecodec.DecodeRecordKey(k)
+		value, found := values[string(k)]
+		if found && e.rowIdxCols == nil {
+			continue
+		}
+		_, handle, err := tablecodec.DecodeRecordKey(k)
+		if err != nil {
+			return err
+		}
+		handleIdxValsGroup, ok := e.idxValues.Get(handle)
+		if !ok {
+			return errors.Trace(errors.Errorf("batch keys are inconsistent with handles"))
+		}
+		// rowIdxKey is the index key generated from the row, the index entries of the row with other keys are removed.
+		var rowIdxKey kv.Key
+		if found {
+			rowIdxVals, _, err := tables.DecodeRawRowData(e.ctx, e.table.Meta(), handle, e.rowIdxCols, value)
 			if err != nil {
 				return err
 			}
-			handleIdxValsGroup, ok := e.idxValues.Get(handle)
-			if !ok {
-				return errors.Trace(errors.Errorf("batch keys are inconsistent with handles"))
+			rowIdxKey, _, err = e.index.GenIndexKey(sc, rowIdxVals, handle, nil)
+			if err != nil {
+				return err
 			}
-			for _, handleIdxVals := range handleIdxValsGroup.([][]types.Datum) {
-				if err := e.index.Delete(e.ctx.GetSessionVars().StmtCtx, txn, handleIdxVals, handle); err != nil {
+		}
+		for _, handleIdxVals := range handleIdxValsGroup.([][]types.Datum) {
+			if found {
+				idxKey, _, err := e.index.GenIndexKey(sc, handleIdxVals, handle, nil)
+				if err != nil {
 					return err
 				}
-				e.removeCnt++
-				if e.removeCnt%e.batchSize == 0 {
-					logutil.BgLogger().Info("clean up dangling index", zap.String("table", e.table.Meta().Name.String()),
-						zap.String("index", e.index.Meta().Name.String()), zap.Uint64("count", e.removeCnt))
+				if bytes.Equal(idxKey, rowIdxKey) {
+					continue
 				}
 			}
+			if e.repair {
+				// Lock the row, so the repair conflicts with the concurrent writes of the row and is retried.
+				if err := txn.LockKeys(ctx, new(kv.LockCtx), k); err != nil {
+					return err
+				}
+			}
+			if err := e.index.Delete(sc, txn, handleIdxVals, handle); err != nil {
+				return err
+			}
+			e.removeCnt++
+			if e.repair {
+				logutil.BgLogger().Info("repair index: remove the inconsistent index entry", zap.String("table", e.table.Meta().Name.String()),
+					zap.String("index", e.index.Meta().Name.String()), zap.Stringer("handle", handle),
+					zap.Bool("rowExists", found), zap.String("indexValues", types.DatumsToStrNoErr(handleIdxVals)))
+			} else if e.removeCnt%e.batchSize == 0 {
+				logutil.BgLogger().Info("clean up dangling index", zap.String("table", e.table.Meta().Name.String()),
+					zap.String("index", e.index.Meta().Name.String()), zap.Uint64("count", e.removeCnt))
+			}
 		}
 	}
 	return nil
@@ -705,7 +756,7 @@ func (e *CleanupIndexExec) cleanTableIndex(ctx context.Context) error {
 			if err != nil {
 				return err
 			}
-			err = e.deleteDanglingIdx(txn, values)
+			err = e.deleteDanglingIdx(ctx, txn, values)
 			if err != nil {
 				return err
 			}
@@ -820,3 +871,40 @@ func (e *CleanupIndexExec) constructLimitPB() *tipb.Executor {
 func (e *CleanupIndexExec) Close() error {
 	return nil
 }
+
+// RepairIndexExec represents a repair index executor.
+// It is built from "admin repair index" statement, is used to rebuild the index entries
+// inconsistent with the row data online. The entries whose rows don't exist or don't match
+// them are removed first, then the missing entries are added back from the rows.
+type RepairIndexExec struct {
+	baseExecutor
+
+	done bool
+	// cleanup and recover are the children of the executor.
+	cleanup *CleanupIndexExec
+	recover *RecoverIndexExec
+}
+
+// Next implements the Executor Next interface.
+func (e *RepairIndexExec) Next(ctx context.Context, req *chunk.Chunk) error {
+	req.Reset()
+	if e.done {
+		return nil
+	}
+	e.done = true
+	cleanupChk := newFirstChunk(e.cleanup)
+	if err := e.cleanup.Next(ctx, cleanupChk); err != nil {
+		return err
+	}
+	recoverChk := newFirstChunk(e.recover)
+	if err := e.recover.Next(ctx, recoverChk); err != nil {
+		return err
+	}
+	removedCnt, addedCnt := cleanupChk.GetRow(0).GetUint64(0), recoverChk.GetRow(0).GetInt64(0)
+	logutil.Logger(ctx).Info("repair index", zap.String("table", e.cleanup.table.Meta().Name.O),
+		zap.String("index", e.cleanup.index.Meta().Name.O), zap.Uint64("removedCount", removedCnt),
+		zap.Int64("addedCount", addedCnt))
+	req.AppendUint64(0, removedCnt)
+	req.AppendInt64(1, addedCnt)
+	return nil
+}
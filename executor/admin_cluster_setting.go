@@ -0,0 +1,105 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+
+	"github.com/pingcap/tidb/sessionctx/clustersetting"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+// AdminShowClusterSettingsExec is an executor for ADMIN SHOW CLUSTER SETTINGS.
+type AdminShowClusterSettingsExec struct {
+	baseExecutor
+	done bool
+}
+
+// Next implements the Executor Next interface.
+func (e *AdminShowClusterSettingsExec) Next(ctx context.Context, req *chunk.Chunk) error {
+	req.Reset()
+	if e.done {
+		return nil
+	}
+	e.done = true
+	restrictedCtx, err := e.getSysSession()
+	if err != nil {
+		return err
+	}
+	defer e.releaseSysSession(restrictedCtx)
+	values, err := clustersetting.LoadAll(ctx, restrictedCtx.(sqlexec.RestrictedSQLExecutor))
+	if err != nil {
+		return err
+	}
+	for _, s := range clustersetting.GetAll() {
+		req.AppendString(0, s.Name)
+		if v, ok := values[s.Name]; ok {
+			req.AppendString(1, v)
+		} else if s.ReadOnly {
+			req.AppendNull(1)
+		} else {
+			req.AppendString(1, s.Default)
+		}
+		req.AppendString(2, s.Type.String())
+		if s.ReadOnly {
+			req.AppendNull(3)
+			req.AppendString(4, "YES")
+		} else {
+			req.AppendString(3, s.Default)
+			req.AppendString(4, "NO")
+		}
+		req.AppendString(5, s.Comment)
+	}
+	return nil
+}
+
+// AdminSetClusterSettingExec is an executor for ADMIN SET CLUSTER SETTING.
+type AdminSetClusterSettingExec struct {
+	baseExecutor
+	name  string
+	value string
+	done  bool
+}
+
+// Next implements the Executor Next interface.
+func (e *AdminSetClusterSettingExec) Next(ctx context.Context, _ *chunk.Chunk) error {
+	if e.done {
+		return nil
+	}
+	e.done = true
+	s := clustersetting.Get(e.name)
+	if s == nil {
+		return clustersetting.ErrUnknownClusterSetting.GenWithStackByArgs(e.name)
+	}
+	if s.ReadOnly {
+		return clustersetting.ErrClusterSettingReadOnly.GenWithStackByArgs(s.Name)
+	}
+	value, err := s.Validate(e.value)
+	if err != nil {
+		return err
+	}
+
+	restrictedCtx, err := e.getSysSession()
+	if err != nil {
+		return err
+	}
+	defer e.releaseSysSession(restrictedCtx)
+	var user, host string
+	if u := e.ctx.GetSessionVars().User; u != nil {
+		user, host = u.Username, u.Hostname
+	}
+	return clustersetting.Change(ctx, restrictedCtx.(clustersetting.Executor), s.Name, value, user, host)
+}
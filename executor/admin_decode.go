@@ -0,0 +1,244 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/store/helper"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/keydecoder"
+	"github.com/pingcap/tidb/util/plancodec"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+// planDigestLen is the length of the hex plan digests, a plan shorter than it can't be an encoded plan either.
+const planDigestLen = 64
+
+// decodeHexKey decodes a hex key, the memcomparable keys in the logs of TiKV and PD are decoded as well.
+func decodeHexKey(s string) ([]byte, error) {
+	key, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, errors.Errorf("invalid hex key %s", s)
+	}
+	if !tablecodec.IsRecordKey(key) && !tablecodec.IsIndexKey(key) {
+		if _, decoded, err := codec.DecodeBytes(key, nil); err == nil {
+			key = decoded
+		}
+	}
+	return key, nil
+}
+
+// AdminDecodeKeyExec is an executor for ADMIN DECODE KEY.
+type AdminDecodeKeyExec struct {
+	baseExecutor
+	key  string
+	done bool
+}
+
+// Next implements the Executor Next interface.
+func (e *AdminDecodeKeyExec) Next(ctx context.Context, req *chunk.Chunk) error {
+	req.Reset()
+	if e.done {
+		return nil
+	}
+	e.done = true
+	key, err := decodeHexKey(e.key)
+	if err != nil {
+		return err
+	}
+	decoded, err := keydecoder.DecodeKey(key, e.ctx.GetInfoSchema().(infoschema.InfoSchema))
+	if err != nil {
+		return err
+	}
+	appendStringOrNull(req, 0, decoded.DbName)
+	appendStringOrNull(req, 1, decoded.TableName)
+	req.AppendInt64(2, decoded.TableID)
+	appendStringOrNull(req, 3, decoded.PartitionName)
+	appendInt64OrNull(req, 4, decoded.PartitionID)
+	appendStringOrNull(req, 5, string(decoded.HandleType))
+	appendStringOrNull(req, 6, decoded.HandleValue)
+	appendStringOrNull(req, 7, decoded.IndexName)
+	appendInt64OrNull(req, 8, decoded.IndexID)
+	if len(decoded.IndexValues) > 0 {
+		req.AppendString(9, strings.Join(decoded.IndexValues, ", "))
+	} else {
+		req.AppendNull(9)
+	}
+	return nil
+}
+
+func appendStringOrNull(req *chunk.Chunk, colIdx int, s string) {
+	if s == "" {
+		req.AppendNull(colIdx)
+		return
+	}
+	req.AppendString(colIdx, s)
+}
+
+func appendInt64OrNull(req *chunk.Chunk, colIdx int, v int64) {
+	if v == 0 {
+		req.AppendNull(colIdx)
+		return
+	}
+	req.AppendInt64(colIdx, v)
+}
+
+// AdminDecodePlanExec is an executor for ADMIN DECODE PLAN.
+type AdminDecodePlanExec struct {
+	baseExecutor
+	plan string
+	done bool
+}
+
+// Next implements the Executor Next interface.
+func (e *AdminDecodePlanExec) Next(ctx context.Context, req *chunk.Chunk) error {
+	req.Reset()
+	if e.done {
+		return nil
+	}
+	e.done = true
+	var (
+		plan string
+		err  error
+	)
+	if isPlanDigest(e.plan) {
+		plan, err = e.loadPlanByDigest(ctx)
+	} else {
+		plan, err = plancodec.DecodePlan(e.plan)
+	}
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(plan, "\n")
+	// The first line is the header of the plan.
+	for _, line := range lines[1:] {
+		// Every line starts with a separator for the alignment.
+		fields := strings.Split(strings.TrimPrefix(line, "\t"), "\t")
+		for i := 0; i < req.NumCols(); i++ {
+			if i < len(fields) {
+				req.AppendString(i, strings.TrimRight(fields[i], " "))
+			} else {
+				req.AppendNull(i)
+			}
+		}
+	}
+	return nil
+}
+
+func isPlanDigest(s string) bool {
+	if len(s) != planDigestLen {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// loadPlanByDigest loads the decoded plan of the digest from the statements summary, including the history.
+func (e *AdminDecodePlanExec) loadPlanByDigest(ctx context.Context) (string, error) {
+	exec := e.ctx.(sqlexec.RestrictedSQLExecutor)
+	rows, _, err := exec.ExecRestrictedSQL(ctx, nil,
+		"select plan from information_schema.statements_summary where plan_digest = %? union all "+
+			"select plan from information_schema.statements_summary_history where plan_digest = %? limit 1",
+		e.plan, e.plan)
+	if err != nil {
+		return "", err
+	}
+	if len(rows) == 0 {
+		return "", errors.Errorf("plan digest %s is not found in the statements summary", e.plan)
+	}
+	return rows[0].GetString(0), nil
+}
+
+// AdminShowMVCCByKeyExec is an executor for ADMIN SHOW MVCC KEY.
+type AdminShowMVCCByKeyExec struct {
+	baseExecutor
+	key  string
+	done bool
+}
+
+// The kinds of the MVCC versions of ADMIN SHOW MVCC KEY.
+const (
+	mvccKindLock  = "LOCK"
+	mvccKindWrite = "WRITE"
+	mvccKindValue = "VALUE"
+)
+
+// Next implements the Executor Next interface.
+func (e *AdminShowMVCCByKeyExec) Next(ctx context.Context, req *chunk.Chunk) error {
+	req.Reset()
+	if e.done {
+		return nil
+	}
+	e.done = true
+	tikvStore, ok := e.ctx.GetStore().(helper.Storage)
+	if !ok {
+		return ErrStorageNotTiKV.GenWithStackByArgs("ADMIN SHOW MVCC KEY")
+	}
+	key, err := decodeHexKey(e.key)
+	if err != nil {
+		return err
+	}
+	resp, err := helper.NewHelper(tikvStore).GetMvccByEncodedKey(key)
+	if err != nil {
+		return err
+	}
+	if resp.RegionError != nil {
+		return errors.Errorf("get MVCC of key %s failed: %s", e.key, resp.RegionError.String())
+	}
+	if resp.Error != "" {
+		return errors.Errorf("get MVCC of key %s failed: %s", e.key, resp.Error)
+	}
+	info := resp.Info
+	if info == nil {
+		return nil
+	}
+	if lock := info.Lock; lock != nil {
+		req.AppendString(0, mvccKindLock)
+		req.AppendUint64(1, lock.StartTs)
+		req.AppendNull(2)
+		req.AppendString(3, lock.Type.String())
+		appendHexOrNull(req, 4, lock.ShortValue)
+	}
+	for _, w := range info.Writes {
+		req.AppendString(0, mvccKindWrite)
+		req.AppendUint64(1, w.StartTs)
+		req.AppendUint64(2, w.CommitTs)
+		req.AppendString(3, w.Type.String())
+		appendHexOrNull(req, 4, w.ShortValue)
+	}
+	for _, v := range info.Values {
+		req.AppendString(0, mvccKindValue)
+		req.AppendUint64(1, v.StartTs)
+		req.AppendNull(2)
+		req.AppendNull(3)
+		appendHexOrNull(req, 4, v.Value)
+	}
+	return nil
+}
+
+func appendHexOrNull(req *chunk.Chunk, colIdx int, v []byte) {
+	if len(v) == 0 {
+		req.AppendNull(colIdx)
+		return
+	}
+	req.AppendString(colIdx, strings.ToUpper(hex.EncodeToString(v)))
+}
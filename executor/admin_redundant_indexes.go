@@ -0,0 +1,75 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+// AdminShowRedundantIndexesExec is an executor for ADMIN SHOW REDUNDANT INDEXES.
+type AdminShowRedundantIndexesExec struct {
+	baseExecutor
+	done bool
+}
+
+// Next implements the Executor Next interface.
+func (e *AdminShowRedundantIndexesExec) Next(ctx context.Context, req *chunk.Chunk) error {
+	req.Reset()
+	if e.done {
+		return nil
+	}
+	e.done = true
+	usages, err := loadIndexUsages(ctx, e.ctx)
+	if err != nil {
+		return err
+	}
+	loc := e.ctx.GetSessionVars().Location()
+	dbs := e.ctx.GetInfoSchema().(infoschema.InfoSchema).AllSchemas()
+	sort.Sort(infoschema.SchemasSorter(dbs))
+	for _, db := range dbs {
+		if util.IsMemOrSysDB(db.Name.L) {
+			continue
+		}
+		// The tables are shared by the info schema, so they're sorted in a copy.
+		tables := append([]*model.TableInfo(nil), db.Tables...)
+		sort.Slice(tables, func(i, j int) bool { return tables[i].Name.L < tables[j].Name.L })
+		for _, tbl := range tables {
+			for _, r := range findRedundantIndexes(tbl, usages) {
+				req.AppendString(0, db.Name.O)
+				req.AppendString(1, tbl.Name.O)
+				req.AppendString(2, r.index.Name.O)
+				req.AppendString(3, r.reason)
+				if r.covering != nil {
+					req.AppendString(4, r.covering.Name.O)
+				} else {
+					req.AppendNull(4)
+				}
+				if !r.lastUsedAt.IsZero() {
+					req.AppendString(5, r.lastUsedAt.In(loc).Format(types.TimeFormat))
+				} else {
+					req.AppendNull(5)
+				}
+			}
+		}
+	}
+	return nil
+}
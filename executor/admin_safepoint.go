@@ -0,0 +1,120 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/helper"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/gcutil"
+	"github.com/tikv/client-go/v2/oracle"
+	pd "github.com/tikv/pd/client"
+)
+
+// AdminShowSafePointsExec is an executor for ADMIN SHOW SAFEPOINTS.
+type AdminShowSafePointsExec struct {
+	baseExecutor
+	done bool
+}
+
+// Next implements the Executor Next interface.
+func (e *AdminShowSafePointsExec) Next(ctx context.Context, req *chunk.Chunk) error {
+	req.Reset()
+	if e.done {
+		return nil
+	}
+	e.done = true
+	tikvStore, ok := e.ctx.GetStore().(helper.Storage)
+	if !ok {
+		return errors.New("the service safe points are only available on TiKV")
+	}
+	safePoints, err := helper.NewHelper(tikvStore).GetServiceGCSafePoints()
+	if err != nil {
+		return err
+	}
+	sort.Slice(safePoints.ServiceGCSafePoints, func(i, j int) bool {
+		return safePoints.ServiceGCSafePoints[i].ServiceID < safePoints.ServiceGCSafePoints[j].ServiceID
+	})
+	loc := e.ctx.GetSessionVars().Location()
+	for _, sp := range safePoints.ServiceGCSafePoints {
+		req.AppendString(0, sp.ServiceID)
+		req.AppendUint64(1, sp.SafePoint)
+		req.AppendString(2, oracle.GetTimeFromTS(sp.SafePoint).In(loc).Format(types.TimeFormat))
+		// The safe point set with the max TTL never expires.
+		if sp.ExpiredAt == math.MaxInt64 {
+			req.AppendNull(3)
+		} else {
+			req.AppendString(3, time.Unix(sp.ExpiredAt, 0).In(loc).Format(types.TimeFormat))
+		}
+	}
+	return nil
+}
+
+// AdminSetSafePointExec is an executor for ADMIN SET SAFEPOINT.
+type AdminSetSafePointExec struct {
+	baseExecutor
+	serviceID string
+	safePoint uint64
+	ttl       int64
+	done      bool
+}
+
+// Next implements the Executor Next interface.
+func (e *AdminSetSafePointExec) Next(ctx context.Context, _ *chunk.Chunk) error {
+	if e.done {
+		return nil
+	}
+	e.done = true
+	pdClient, err := getPDClient(e.ctx.GetStore())
+	if err != nil {
+		return err
+	}
+	return gcutil.SetServiceSafePoint(ctx, pdClient, e.serviceID, e.safePoint, e.ttl)
+}
+
+// AdminExpireSafePointExec is an executor for ADMIN EXPIRE SAFEPOINT.
+type AdminExpireSafePointExec struct {
+	baseExecutor
+	serviceID string
+	done      bool
+}
+
+// Next implements the Executor Next interface.
+func (e *AdminExpireSafePointExec) Next(ctx context.Context, _ *chunk.Chunk) error {
+	if e.done {
+		return nil
+	}
+	e.done = true
+	pdClient, err := getPDClient(e.ctx.GetStore())
+	if err != nil {
+		return err
+	}
+	return gcutil.ExpireServiceSafePoint(ctx, pdClient, e.serviceID)
+}
+
+func getPDClient(store kv.Storage) (pd.Client, error) {
+	tikvStore, ok := store.(helper.Storage)
+	if !ok {
+		return nil, errors.New("the service safe points are only available on TiKV")
+	}
+	return tikvStore.GetRegionCache().PDClient(), nil
+}
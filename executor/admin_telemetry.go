@@ -44,13 +44,18 @@ func (e *AdminShowTelemetryExec) Next(ctx context.Context, req *chunk.Chunk) err
 	if err != nil {
 		return err
 	}
-	previewData, err := telemetry.PreviewUsageData(e.ctx, dom.GetEtcdClient())
+	previewData, err := telemetry.AuditUsageData(e.ctx, dom.GetEtcdClient())
+	if err != nil {
+		return err
+	}
+	categories, err := telemetry.GetReportCategories(e.ctx)
 	if err != nil {
 		return err
 	}
 	req.AppendString(0, id)
 	req.AppendString(1, status)
 	req.AppendString(2, previewData)
+	req.AppendString(3, categories)
 	return nil
 }
 
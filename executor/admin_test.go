@@ -16,32 +16,44 @@ package executor_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/log"
 	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/domain/infosync"
 	mysql "github.com/pingcap/tidb/errno"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/auth"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/planner/core"
 	"github.com/pingcap/tidb/session"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/store/helper"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/testkit"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/logutil/consistency"
 	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/pdapi"
+	"github.com/pingcap/tidb/util/tableworkload"
 	"github.com/stretchr/testify/require"
+	"github.com/tikv/client-go/v2/tikv"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -839,6 +851,65 @@ func TestClusteredAdminCleanupIndex(t *testing.T) {
 	tk.MustExec("admin check table admin_test")
 }
 
+func TestAdminRepairIndex(t *testing.T) {
+	store, domain, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists admin_test")
+	tk.MustExec("create table admin_test (c1 int primary key, c2 int, key k2 (c2))")
+	tk.MustExec("insert admin_test values (1, 2), (3, 4), (5, 6), (7, 8)")
+	tk.MustQuery("admin repair index admin_test k2").Check(testkit.Rows("0 0"))
+
+	ctx := mock.NewContext()
+	ctx.Store = store
+	tbl, err := domain.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("admin_test"))
+	require.NoError(t, err)
+	tblInfo := tbl.Meta()
+	indexOpr := tables.NewIndex(tblInfo.ID, tblInfo, tblInfo.FindIndexByName("k2"))
+
+	txn, err := store.Begin()
+	require.NoError(t, err)
+	sc := ctx.GetSessionVars().StmtCtx
+	// The entry of row 3 has a wrong value.
+	require.NoError(t, indexOpr.Delete(sc, txn, types.MakeDatums(4), kv.IntHandle(3)))
+	_, err = indexOpr.Create(ctx, txn, types.MakeDatums(40), kv.IntHandle(3), nil)
+	require.NoError(t, err)
+	// The entry of row 5 is missing.
+	require.NoError(t, indexOpr.Delete(sc, txn, types.MakeDatums(6), kv.IntHandle(5)))
+	// The entry of row 100 is dangling.
+	_, err = indexOpr.Create(ctx, txn, types.MakeDatums(100), kv.IntHandle(100), nil)
+	require.NoError(t, err)
+	require.NoError(t, txn.Commit(context.Background()))
+	require.Error(t, tk.ExecToErr("admin check index admin_test k2"))
+
+	tk.MustQuery("admin repair index admin_test k2").Check(testkit.Rows("2 2"))
+	tk.MustExec("admin check index admin_test k2")
+	tk.MustQuery("select c1, c2 from admin_test use index(k2) order by c2").Check(testkit.Rows("1 2", "3 4", "5 6", "7 8"))
+	tk.MustQuery("admin repair index admin_test k2").Check(testkit.Rows("0 0"))
+
+	err = tk.ExecToErr("admin repair index admin_test k3")
+	require.EqualError(t, err, "index `k3` is not found in table `admin_test`.")
+
+	// The rows of the clustered table are matched by their common handles.
+	tk.MustExec("create table admin_test2 (c1 varchar(10), c2 int, primary key (c1) clustered, key k2 (c2))")
+	tk.MustExec("insert admin_test2 values ('a', 1), ('b', 2)")
+	tbl, err = domain.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("admin_test2"))
+	require.NoError(t, err)
+	tblInfo = tbl.Meta()
+	indexOpr = tables.NewIndex(tblInfo.ID, tblInfo, tblInfo.FindIndexByName("k2"))
+	txn, err = store.Begin()
+	require.NoError(t, err)
+	handle := testkit.MustNewCommonHandle(t, "b")
+	require.NoError(t, indexOpr.Delete(sc, txn, types.MakeDatums(2), handle))
+	_, err = indexOpr.Create(ctx, txn, types.MakeDatums(20), handle, nil)
+	require.NoError(t, err)
+	require.NoError(t, txn.Commit(context.Background()))
+	tk.MustQuery("admin repair index admin_test2 k2").Check(testkit.Rows("1 1"))
+	tk.MustExec("admin check table admin_test2")
+}
+
 func TestAdminCheckPartitionTableFailed(t *testing.T) {
 	store, domain, clean := testkit.CreateMockStoreAndDomain(t)
 	defer clean()
@@ -1641,3 +1712,284 @@ func TestAdminCheckTableFailed(t *testing.T) {
 	require.NoError(t, err)
 	tk.MustExec("admin check table admin_test")
 }
+
+func TestAdminReclaimTable(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int primary key, b int)")
+	tk.MustExec("create temporary table tmp (a int)")
+	tk.MustGetErrMsg("admin reclaim table tmp", "[planner:8006]`admin reclaim table` is unsupported on temporary tables.")
+	// The mock store has no PD, so the region statistics are unknown.
+	err := tk.QueryToErr("admin reclaim table t compact")
+	require.EqualError(t, err, "pd unavailable")
+
+	// The mock PD reports that all the regions of the table are empty except the last one.
+	var merges []string
+	router := mux.NewRouter()
+	router.HandleFunc(pdapi.ScanRegions, func(w http.ResponseWriter, r *http.Request) {
+		_, startKey, err := codec.DecodeBytes([]byte(r.URL.Query().Get("key")), nil)
+		require.NoError(t, err)
+		_, endKey, err := codec.DecodeBytes([]byte(r.URL.Query().Get("end_key")), nil)
+		require.NoError(t, err)
+		bo := tikv.NewBackofferWithVars(context.Background(), 1000, nil)
+		regions, err := store.(helper.Storage).GetRegionCache().LoadRegionsInKeyRange(bo, startKey, endKey)
+		require.NoError(t, err)
+		var regionsInfo helper.RegionsInfo
+		for i, region := range regions {
+			info := helper.RegionInfo{ID: int64(region.GetID())}
+			if i == len(regions)-1 {
+				info.ApproximateKeys = 10
+			}
+			regionsInfo.Regions = append(regionsInfo.Regions, info)
+		}
+		regionsInfo.Count = int64(len(regionsInfo.Regions))
+		require.NoError(t, json.NewEncoder(w).Encode(regionsInfo))
+	})
+	router.HandleFunc(pdapi.Operators, func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		merges = append(merges, string(body))
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+	tk.MustQuery("split table t between (0) and (30000) regions 3").Check(testkit.Rows("2 1"))
+	tk = testkit.NewTestKit(t, &mockStore{store.(helper.Storage), strings.TrimPrefix(server.URL, "http://")})
+	tk.MustExec("use test")
+	tk.MustQuery("admin reclaim table t").Check(testkit.Rows("test t 3 2 1 0"))
+	require.Len(t, merges, 1)
+	require.Contains(t, merges[0], `"name":"merge-region"`)
+	tk.MustQuery("admin reclaim table t compact").Check(testkit.Rows("test t 3 2 1 0"))
+}
+
+func TestAdminClusterSetting(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	require.True(t, tk.Session().Auth(&auth.UserIdentity{Username: "root", Hostname: "localhost"}, nil, nil))
+	// The values written by the bootstrap depend on the environment.
+	bootstrapValue := func(name string) string {
+		return fmt.Sprint(tk.MustQuery("select variable_value from mysql.tidb where variable_name = ?", name).Rows()[0][0])
+	}
+	tk.MustQuery("admin show cluster settings").Check(testkit.Rows(
+		"bootstrapped True string <nil> YES Bootstrap flag. Do not delete.",
+		"default_memory_quota_query <nil> string <nil> YES The default value of mem-quota-query of a cluster upgraded from v3.0.x.",
+		"default_oom_action <nil> string <nil> YES The default value of oom-action of a cluster upgraded from v3.0.x.",
+		fmt.Sprintf("new_collation_enabled %s string <nil> YES If the new collations are enabled. Do not edit it.", bootstrapValue("new_collation_enabled")),
+		fmt.Sprintf("system_tz %s string <nil> YES TiDB Global System Timezone.", bootstrapValue("system_tz")),
+		"tidb_ddl_reorg_max_write_speed 0 int 0 NO The max bytes written by the DDL reorg workers per second, 0 means no limit.",
+		"tidb_disable_column_tracking_time <nil> string <nil> YES Record the last time tidb_enable_column_tracking is set off",
+		"tidb_enable_historical_stats false bool false NO Current historical statistics enable status",
+		fmt.Sprintf("tidb_server_version %s string <nil> YES TiDB bootstrap version.", bootstrapValue("tidb_server_version")),
+		"tikv_gc_auto_concurrency true bool true NO Let TiDB pick the concurrency automatically. If set false, tikv_gc_concurrency will be used",
+		"tikv_gc_concurrency 2 int 2 NO How many goroutines used to do GC parallel, [1, 128], default 2",
+		"tikv_gc_enable true bool true NO Current GC enable status",
+		"tikv_gc_last_run_time <nil> string <nil> YES The time when last GC starts. (DO NOT EDIT)",
+		"tikv_gc_leader_desc <nil> string <nil> YES Host name and pid of current GC leader. (DO NOT EDIT)",
+		"tikv_gc_leader_lease <nil> string <nil> YES Current GC worker leader lease. (DO NOT EDIT)",
+		"tikv_gc_leader_uuid <nil> string <nil> YES Current GC worker leader UUID. (DO NOT EDIT)",
+		"tikv_gc_life_time 10m0s duration 10m0s NO All versions within life time will not be collected by GC, at least 10m, in Go format.",
+		"tikv_gc_mode distributed enum distributed NO Mode of GC, \"central\" or \"distributed\"",
+		"tikv_gc_run_interval 10m0s duration 10m0s NO GC run interval, at least 10m, in Go format.",
+		"tikv_gc_safe_point <nil> string <nil> YES All versions after safe point can be accessed. (DO NOT EDIT)",
+		"tikv_gc_scan_lock_mode legacy enum legacy NO Mode of scanning locks, \"physical\" or \"legacy\"",
+	))
+
+	tk.MustExec("admin set cluster setting tikv_gc_life_time = '1h'")
+	tk.MustExec("admin set cluster setting TIKV_GC_LIFE_TIME = '20m'")
+	tk.MustExec("admin set cluster setting tikv_gc_concurrency = 4")
+	tk.MustExec("admin set cluster setting tikv_gc_enable = 'off'")
+	tk.MustExec("admin set cluster setting tikv_gc_mode = 'Central'")
+	tk.MustQuery("select variable_name, variable_value from mysql.tidb where variable_name in ('tikv_gc_life_time', 'tikv_gc_concurrency', 'tikv_gc_enable', 'tikv_gc_mode') order by variable_name").Check(testkit.Rows(
+		"tikv_gc_concurrency 4", "tikv_gc_enable false", "tikv_gc_life_time 20m0s", "tikv_gc_mode central"))
+	tk.MustQuery("select setting_name, old_value, new_value, user, host from mysql.cluster_setting_history order by change_time").Check(testkit.Rows(
+		"tikv_gc_life_time <nil> 1h0m0s root localhost",
+		"tikv_gc_life_time 1h0m0s 20m0s root localhost",
+		"tikv_gc_concurrency <nil> 4 root localhost",
+		"tikv_gc_enable <nil> false root localhost",
+		"tikv_gc_mode <nil> central root localhost",
+	))
+	rows := tk.MustQuery("admin show cluster settings").Rows()
+	require.Equal(t, "tikv_gc_life_time", rows[16][0])
+	require.Equal(t, "20m0s", rows[16][1])
+
+	tk.MustGetErrCode("admin set cluster setting tikv_gc_unknown = 1", mysql.ErrUnknownClusterSetting)
+	tk.MustGetErrCode("admin set cluster setting tikv_gc_safe_point = '20210101-00:00:00'", mysql.ErrClusterSettingReadOnly)
+	tk.MustGetErrCode("admin set cluster setting tikv_gc_life_time = '1m'", mysql.ErrWrongValueForClusterSetting)
+	tk.MustGetErrCode("admin set cluster setting tikv_gc_concurrency = 129", mysql.ErrWrongValueForClusterSetting)
+	tk.MustGetErrCode("admin set cluster setting tikv_gc_mode = 'unknown'", mysql.ErrWrongValueForClusterSetting)
+	tk.MustGetErrCode("admin set cluster setting tikv_gc_enable = null", mysql.ErrWrongValueForClusterSetting)
+	tk.MustQuery("select count(*) from mysql.cluster_setting_history").Check(testkit.Rows("5"))
+
+	// The system variables stored in mysql.tidb are changed by the same path.
+	tk.MustExec("set global tidb_gc_life_time = '30m'")
+	tk.MustExec("set global tidb_gc_concurrency = 8")
+	tk.MustQuery("select @@global.tidb_gc_life_time, @@global.tidb_gc_concurrency").Check(testkit.Rows("30m0s 8"))
+	tk.MustQuery("select setting_name, old_value, new_value, user, host from mysql.cluster_setting_history order by change_time limit 5, 3").Check(testkit.Rows(
+		"tikv_gc_life_time 20m0s 30m0s root localhost",
+		"tikv_gc_auto_concurrency <nil> false root localhost",
+		"tikv_gc_concurrency 4 8 root localhost",
+	))
+}
+
+func TestAdminSafePoint(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	pdClient := store.(helper.Storage).GetRegionCache().PDClient()
+	minSafePoint := func() uint64 {
+		// Removing a service safe point that doesn't exist returns the minimal one.
+		minSafePoint, err := pdClient.UpdateServiceGCSafePoint(context.Background(), "test", 0, 0)
+		require.NoError(t, err)
+		return minSafePoint
+	}
+
+	tk.MustExec("admin set safepoint 'br' = 100 ttl 60")
+	require.Equal(t, uint64(100), minSafePoint())
+	tk.MustExec("admin set safepoint 'br' = 200 ttl 60")
+	require.Equal(t, uint64(200), minSafePoint())
+	err := tk.ExecToErr("admin set safepoint 'cdc' = 150 ttl 60")
+	require.EqualError(t, err, "the service safe point 150 is earlier than the minimal service safe point 200")
+
+	tk.MustExec("admin expire safepoint 'br'")
+	tk.MustExec("admin set safepoint 'cdc' = 150 ttl 60")
+	require.Equal(t, uint64(150), minSafePoint())
+
+	require.Error(t, tk.ExecToErr("admin set safepoint 'cdc' = 150 ttl 0"))
+	require.Error(t, tk.ExecToErr("admin set safepoint '' = 150 ttl 60"))
+	require.Error(t, tk.ExecToErr("admin set safepoint 'gc_worker' = 150 ttl 60"))
+	require.Error(t, tk.ExecToErr("admin expire safepoint 'gc_worker'"))
+}
+
+func TestAdminShowRedundantIndexes(t *testing.T) {
+	// The indexes are reported as never used only if the index usages are synced.
+	session.SetIndexUsageSyncLease(time.Hour)
+	defer session.SetIndexUsageSyncLease(0)
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tableworkload.Reset()
+	defer tableworkload.Reset()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int, b varchar(10), c int, unique key uk(a, c), key ka(a), key kab(a, b), " +
+		"key kb(b(3)), key kb2(b), key kc(c), key kc2(c), key kd(c, a) invisible)")
+	tk.MustQuery("select a from t use index(kb2) where b = 'x'")
+	tk.MustQuery("select a from t use index(kc) where c = 1")
+	tk.MustQuery("admin show redundant indexes").Check(testkit.Rows(
+		"test t ka prefix of another index uk <nil>",
+		"test t kab never used <nil> <nil>",
+		"test t kb prefix of another index kb2 <nil>",
+		"test t kc2 prefix of another index kc <nil>",
+		"test t kd never used <nil> <nil>",
+	))
+
+	// A used index is reported only if it's covered by another one.
+	tk.MustQuery("select a from t use index(kab) where a = 1")
+	tk.MustQuery("select a from t use index(ka) where a = 1")
+	rows := tk.MustQuery("admin show redundant indexes").Rows()
+	require.Len(t, rows, 4)
+	require.Equal(t, "ka", rows[0][2])
+	require.NotEqual(t, "<nil>", rows[0][5])
+	require.Equal(t, "kb", rows[1][2])
+
+	// The tables of the system databases are not checked.
+	for _, row := range rows {
+		require.Equal(t, "test", row[0])
+	}
+}
+
+func TestAdminShowRedundantIndexesNotSynced(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tableworkload.Reset()
+	defer tableworkload.Reset()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int, b int, key ka(a), key kb(b))")
+	tk.MustQuery("select a from t use index(ka) where a = 1")
+	// The unused indexes may be used by the other instances, so only the start time of this instance is reported.
+	info, err := infosync.GetServerInfo()
+	require.NoError(t, err)
+	startTime := time.Unix(info.StartTimestamp, 0).In(tk.Session().GetSessionVars().Location()).Format(types.TimeFormat)
+	rows := tk.MustQuery("admin show redundant indexes").Rows()
+	require.Len(t, rows, 1)
+	require.Equal(t, "kb", rows[0][2])
+	require.Equal(t, "not used on this instance since "+startTime, rows[0][3])
+}
+
+func TestAdminDecodeKeyAndPlan(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int primary key, b int, key kb(b))")
+	tk.MustExec("insert into t values (1, 10)")
+	tbl, err := domain.GetDomain(tk.Session()).InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	require.NoError(t, err)
+	tid := tbl.Meta().ID
+
+	rowKey := tablecodec.EncodeRowKeyWithHandle(tid, kv.IntHandle(1))
+	expected := testkit.Rows(fmt.Sprintf("test t %d <nil> <nil> int 1 <nil> <nil> <nil>", tid))
+	tk.MustQuery(fmt.Sprintf("admin decode key '%X'", []byte(rowKey))).Check(expected)
+	// The memcomparable keys in the logs of TiKV and PD are decoded as well.
+	tk.MustQuery(fmt.Sprintf("admin decode key '%X'", codec.EncodeBytes(nil, rowKey))).Check(expected)
+	require.EqualError(t, tk.QueryToErr("admin decode key 'xyz'"), "invalid hex key xyz")
+
+	tk.MustQuery("admin decode plan 'rwPwcTAJNV8xNAkwCTEJZnVuY3M6bWF4KHRlc3QudC5hKS0+Q29sdW1uIzQJMQl0aW1lOj" +
+		"IyMy45MzXCtXMsIGxvb3BzOjIJMTI4IEJ5dGVzCU4vQQoxCTE2XzE4CTAJMQlvZmZzZXQ6MCwgY291bnQ6MQkxCQlHFDE4LjQyMjJHAAhOL0" +
+		"EBBCAKMgkzMl8yOAkBlEBpbmRleDpMaW1pdF8yNwkxCQ0+DDYuODUdPSwxLCBycGMgbnVtOiANDAUpGDE1MC44MjQFKjhwcm9jIGtleXM6MA" +
+		"kxOTgdsgAzAbIAMgFearIAFDU3LjM5NgVKAGwN+BGxIDQJMTNfMjYJMQGgHGFibGU6dCwgCbqwaWR4KGEpLCByYW5nZTooMCwraW5mXSwga2" +
+		"VlcCBvcmRlcjp0cnVlLCBkZXNjAT8kaW1lOjU2LjY2MR1rJDEJTi9BCU4vQQo='").Check(testkit.RowsWithSep("|",
+		"StreamAgg_14|root|1|funcs:max(test.t.a)->Column#4|1|time:223.935µs, loops:2|128 Bytes|N/A",
+		"└─Limit_18|root|1|offset:0, count:1|1|time:218.422µs, loops:2|N/A|N/A",
+		"  └─IndexReader_28|root|1|index:Limit_27|1|time:216.85µs, loops:1, rpc num: 1, rpc time:150.824µs, proc keys:0|198 Bytes|N/A",
+		"    └─Limit_27|cop|1|offset:0, count:1|1|time:57.396µs, loops:2|N/A|N/A",
+		"      └─IndexScan_26|cop|1|table:t, index:idx(a), range:(0,+inf], keep order:true, desc|1|time:56.661µs, loops:1|N/A|N/A",
+	))
+
+	// The plan of a plan digest is loaded from the statements summary.
+	require.True(t, tk.Session().Auth(&auth.UserIdentity{Username: "root", Hostname: "%"}, nil, nil))
+	tk.MustExec("set global tidb_enable_stmt_summary = 1")
+	tk.MustQuery("select b from t where b > 5")
+	digest := tk.MustQuery("select plan_digest from information_schema.statements_summary " +
+		"where query_sample_text = 'select b from t where b > 5'").Rows()
+	require.Len(t, digest, 1)
+	rows := tk.MustQuery(fmt.Sprintf("admin decode plan '%s'", digest[0][0])).Rows()
+	require.Len(t, rows, 2)
+	require.True(t, strings.HasPrefix(rows[0][0].(string), "IndexReader"))
+	require.True(t, strings.HasPrefix(rows[1][0].(string), "└─IndexRangeScan"))
+	require.EqualError(t, tk.QueryToErr("admin decode plan '"+strings.Repeat("0", 64)+"'"),
+		"plan digest "+strings.Repeat("0", 64)+" is not found in the statements summary")
+}
+
+func TestAdminShowMVCCByKey(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int primary key, b int)")
+	tk.MustExec("insert into t values (1, 10)")
+	tk.MustExec("update t set b = 20 where a = 1")
+	tbl, err := domain.GetDomain(tk.Session()).InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	require.NoError(t, err)
+
+	rowKey := tablecodec.EncodeRowKeyWithHandle(tbl.Meta().ID, kv.IntHandle(1))
+	rows := tk.MustQuery(fmt.Sprintf("admin show mvcc key '%X'", []byte(rowKey))).Rows()
+	writes := 0
+	for _, row := range rows {
+		require.NotEqual(t, "LOCK", row[0])
+		if row[0] == "WRITE" {
+			writes++
+			require.Equal(t, "Put", row[3])
+		}
+	}
+	require.Equal(t, 2, writes)
+}
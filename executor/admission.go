@@ -0,0 +1,105 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/pingcap/tidb/config"
+	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/util/admission"
+)
+
+// AdmissionQueue limits the number of the interactive and batch statements executed at the same time, the statements
+// are not queued if it's nil.
+var AdmissionQueue *admission.Queue
+
+// admit waits in the AdmissionQueue until the statement is admitted by its class. The internal statements are not
+// queued, since the statements of the users may wait for them. Neither are the statements of a transaction which
+// has locked or written some keys, otherwise the locks are held while it waits, and the statements it waits for
+// may be blocked by the locks.
+func (a *ExecStmt) admit(ctx context.Context) error {
+	sessVars := a.Ctx.GetSessionVars()
+	if AdmissionQueue == nil || sessVars.InRestrictedSQL {
+		return nil
+	}
+	if txn, err := a.Ctx.Txn(false); err == nil && txn.Valid() && !txn.IsReadOnly() {
+		return nil
+	}
+	class := stmtAdmissionClass(sessVars.StmtCtx, a.Plan, config.GetGlobalConfig().AdmissionControl.BatchCostThreshold)
+	release, ok := AdmissionQueue.TryAdmit(class)
+	if !ok {
+		// The statement is shown as queued in the processlist while it waits.
+		sessVars.StmtCtx.Queued.Store(true)
+		a.setProcessInfo()
+		var err error
+		release, err = AdmissionQueue.Admit(ctx, class, func() error {
+			if atomic.LoadUint32(&sessVars.Killed) == 1 {
+				return ErrQueryInterrupted
+			}
+			return nil
+		})
+		sessVars.StmtCtx.Queued.Store(false)
+		if err != nil {
+			return err
+		}
+	}
+	a.admission = release
+	return nil
+}
+
+// releaseAdmission releases the statement from the AdmissionQueue once it finishes.
+func (a *ExecStmt) releaseAdmission() {
+	if a.admission != nil {
+		a.admission()
+		a.admission = nil
+	}
+}
+
+// stmtAdmissionClass classifies the statement by the QUERY_TYPE hint, or the estimated cost of its plan if there is
+// no such hint.
+func stmtAdmissionClass(sc *stmtctx.StatementContext, p plannercore.Plan, batchCostThreshold float64) admission.Class {
+	if sc.HasQueryTypeHint {
+		if sc.QueryType == "olap" {
+			return admission.Batch
+		}
+		return admission.Interactive
+	}
+	if planCost(p) >= batchCostThreshold {
+		return admission.Batch
+	}
+	return admission.Interactive
+}
+
+// planCost returns the estimated cost of the plan, the cost of the DML statements is the cost of their reads.
+func planCost(p plannercore.Plan) float64 {
+	switch x := p.(type) {
+	case plannercore.PhysicalPlan:
+		return x.Cost()
+	case *plannercore.Insert:
+		return planCost(x.SelectPlan)
+	case *plannercore.Update:
+		return planCost(x.SelectPlan)
+	case *plannercore.Delete:
+		return planCost(x.SelectPlan)
+	case *plannercore.Explain:
+		if x.Analyze {
+			return planCost(x.TargetPlan)
+		}
+	}
+	return 0
+}
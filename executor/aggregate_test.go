@@ -794,6 +794,19 @@ func TestOnlyFullGroupBy(t *testing.T) {
 	require.Truef(t, terror.ErrorEqual(err, plannercore.ErrFieldNotInGroupBy), "err %v", err)
 	err = tk.ExecToErr("select t.b, x.* from t right join x on t.b = x.b group by t.b, x.d")
 	require.Truef(t, terror.ErrorEqual(err, plannercore.ErrFieldNotInGroupBy), "err %v", err)
+	// test transitive functional dependency
+	tk.MustQuery("select c from t where c = d group by d")
+	tk.MustQuery("select x.* from t, x where t.a = x.b and x.b = x.a group by t.a")
+	tk.MustQuery("select x.* from t, x where x.a = t.d and x.a = t.b group by t.d")
+	tk.MustQuery("select x.*, y.* from t join (x join t as y on x.a = y.a) on t.a = x.a group by t.a")
+	tk.MustQuery("select t.b, x.* from t left join x on t.b = x.b where x.b = x.d group by t.b")
+	tk.MustQuery("select x.c, count(*) from t, x where t.a = 1 and t.a = t.d and x.a = t.d")
+	err = tk.ExecToErr("select c from t where c = d group by b")
+	require.Truef(t, terror.ErrorEqual(err, plannercore.ErrFieldNotInGroupBy), "err %v", err)
+	err = tk.ExecToErr("select t.* from t left join x on t.a = x.a group by x.a")
+	require.Truef(t, terror.ErrorEqual(err, plannercore.ErrFieldNotInGroupBy), "err %v", err)
+	err = tk.ExecToErr("select x.c, count(*) from t, x where t.b = 1 and x.a = t.d")
+	require.Truef(t, terror.ErrorEqual(err, plannercore.ErrMixOfGroupFuncAndFields), "err %v", err)
 
 	// FixMe: test functional dependency of derived table
 	// tk.MustQuery("select * from (select * from t) as e group by a")
@@ -704,7 +704,7 @@ func analyzeColumnsPushdown(colExec *AnalyzeColumnsExec) *statistics.AnalyzeResu
 		// Because the process of analyzing will keep the order of results be the same as the colsInfo in the analyze task,
 		// and in `buildAnalyzeFullSamplingTask` we always place the _tidb_rowid at the last of colsInfo, so if there are
 		// stats for _tidb_rowid, it must be at the end of the column stats.
-		// Virtual column has no histogram yet. So we check nil here.
+		// Virtual column has no histogram if it's not analyzed. So we check nil here.
 		if hists[cLen-1] != nil && hists[cLen-1].ID == -1 {
 			cLen -= 1
 		}
@@ -807,6 +807,8 @@ type AnalyzeColumnsExec struct {
 	schemaForVirtualColEval *expression.Schema
 	baseCount               int64
 	baseModifyCnt           int64
+	// analyzeVirtualCols means the stats of the virtual generated columns are built from the samples, see Fix783.
+	analyzeVirtualCols bool
 }
 
 func (e *AnalyzeColumnsExec) open(ranges []*ranger.Range) error {
@@ -890,6 +892,56 @@ func (e AnalyzeColumnsExec) decodeSampleDataWithVirtualColumn(
 	return nil
 }
 
+// buildVirtualColumnSketches rebuilds the null counts, FM sketches and total sizes of the virtual columns. The virtual
+// columns are not stored in TiKV, so the ones collected by TiKV are invalid. They're estimated from the evaluated
+// values of the samples, unless the virtual column is the only column of an index, e.g. an expression index, whose
+// FM sketch and null count are collected from all the rows.
+func (e *AnalyzeColumnsExec) buildVirtualColumnSketches(collector statistics.RowSampleCollector, idxResults map[int64]*statistics.AnalyzeResults) error {
+	sc := e.ctx.GetSessionVars().StmtCtx
+	base := collector.Base()
+	for i, col := range e.colsInfo {
+		if !col.IsGenerated() || col.GeneratedStored {
+			continue
+		}
+		fms := statistics.NewFMSketch(maxSketchSize)
+		var nullCount, totalSize int64
+		for _, sample := range base.Samples {
+			val := sample.Columns[i]
+			if val.IsNull() {
+				nullCount++
+				continue
+			}
+			if err := fms.InsertValue(sc, val); err != nil {
+				return err
+			}
+			encoded, err := codec.EncodeValue(sc, nil, val)
+			if err != nil {
+				return err
+			}
+			// Minus one is to remove the flag byte.
+			totalSize += int64(len(encoded)) - 1
+		}
+		if sampleNum := len(base.Samples); sampleNum > 0 {
+			sampleFactor := float64(base.Count) / float64(sampleNum)
+			nullCount = int64(float64(nullCount) * sampleFactor)
+			totalSize = int64(float64(totalSize) * sampleFactor)
+		}
+		for _, idx := range e.indexes {
+			if len(idx.Columns) != 1 || idx.Columns[0].Offset != i || idx.Columns[0].Length != types.UnspecifiedLength {
+				continue
+			}
+			if ret, ok := idxResults[idx.ID]; ok {
+				fms, nullCount = ret.Ars[0].Fms[0].Copy(), ret.Count
+				break
+			}
+		}
+		base.NullCount[i] = nullCount
+		base.FMSketches[i] = fms
+		base.TotalSizes[i] = totalSize
+	}
+	return nil
+}
+
 func readDataAndSendTask(handler *tableResultHandler, mergeTaskCh chan []byte) error {
 	defer close(mergeTaskCh)
 	for {
@@ -1019,6 +1071,23 @@ func (e *AnalyzeColumnsExec) buildSamplingStats(
 		go e.subBuildWorker(buildResultChan, buildTaskChan, hists, topns, sampleCollectors, i == 0)
 	}
 
+	indexPushedDownResult := <-idxNDVPushDownCh
+	if indexPushedDownResult.err != nil {
+		close(buildTaskChan)
+		return 0, nil, nil, nil, nil, indexPushedDownResult.err
+	}
+	for _, offset := range indexesWithVirtualColOffsets {
+		ret := indexPushedDownResult.results[e.indexes[offset].ID]
+		rootRowCollector.Base().NullCount[colLen+offset] = ret.Count
+		rootRowCollector.Base().FMSketches[colLen+offset] = ret.Ars[0].Fms[0]
+	}
+	if len(virtualColIdx) > 0 && e.analyzeVirtualCols {
+		if err = e.buildVirtualColumnSketches(rootRowCollector, indexPushedDownResult.results); err != nil {
+			close(buildTaskChan)
+			return 0, nil, nil, nil, nil, err
+		}
+	}
+
 	for i, col := range e.colsInfo {
 		buildTaskChan <- &samplingBuildTask{
 			id:               col.ID,
@@ -1030,16 +1099,6 @@ func (e *AnalyzeColumnsExec) buildSamplingStats(
 		fmSketches = append(fmSketches, rootRowCollector.Base().FMSketches[i])
 	}
 
-	indexPushedDownResult := <-idxNDVPushDownCh
-	if indexPushedDownResult.err != nil {
-		return 0, nil, nil, nil, nil, indexPushedDownResult.err
-	}
-	for _, offset := range indexesWithVirtualColOffsets {
-		ret := indexPushedDownResult.results[e.indexes[offset].ID]
-		rootRowCollector.Base().NullCount[colLen+offset] = ret.Count
-		rootRowCollector.Base().FMSketches[colLen+offset] = ret.Ars[0].Fms[0]
-	}
-
 	// build index stats
 	for i, idx := range e.indexes {
 		buildTaskChan <- &samplingBuildTask{
@@ -1335,7 +1394,7 @@ workLoop:
 		}
 		var collector *statistics.SampleCollector
 		if task.isColumn {
-			if e.colsInfo[task.slicePos].IsGenerated() && !e.colsInfo[task.slicePos].GeneratedStored {
+			if !e.analyzeVirtualCols && e.colsInfo[task.slicePos].IsGenerated() && !e.colsInfo[task.slicePos].GeneratedStored {
 				hists[task.slicePos] = nil
 				topns[task.slicePos] = nil
 				continue
@@ -1370,7 +1429,7 @@ workLoop:
 			var err error
 			idx := e.indexes[task.slicePos-colLen]
 			sampleItems := make([]*statistics.SampleItem, 0, task.rootRowCollector.Base().Samples.Len())
-			for _, row := range task.rootRowCollector.Base().Samples {
+			for j, row := range task.rootRowCollector.Base().Samples {
 				if len(idx.Columns) == 1 && row.Columns[idx.Columns[0].Offset].IsNull() {
 					continue
 				}
@@ -1394,7 +1453,8 @@ workLoop:
 					}
 				}
 				sampleItems = append(sampleItems, &statistics.SampleItem{
-					Value: types.NewBytesDatum(b),
+					Value:   types.NewBytesDatum(b),
+					Ordinal: j,
 				})
 			}
 			collector = &statistics.SampleCollector{
@@ -1157,6 +1157,15 @@ func TestIssue20874(t *testing.T) {
 		"1 1 3 0 6 2 0",
 		"1 2 2 0 6 2 0",
 	))
+	// The correlation of the indexes is collected if the fix control turns it on.
+	tk.MustExec("set @@tidb_opt_fix_control = '784:ON'")
+	tk.MustExec("analyze table t")
+	tk.MustQuery("select is_index, hist_id, distinct_count, null_count, tot_col_size, stats_ver, correlation from mysql.stats_histograms").Sort().Check(testkit.Rows(
+		"0 1 3 0 6 2 1",
+		"0 2 2 0 6 2 -0.5",
+		"1 1 3 0 6 2 1",
+		"1 2 2 0 6 2 -0.5",
+	))
 }
 
 func TestAnalyzeClusteredIndexPrimary(t *testing.T) {
@@ -2416,6 +2425,26 @@ func TestAnalyzeColumnsWithVirtualColumnIndex(t *testing.T) {
 					"test t  b 0 1 3 1 3 3 0",
 					"test t  idx 1 0 2 1 2 3 0",
 					"test t  idx 1 1 3 1 4 4 0"))
+
+			// virtual column c is analyzed with the fix 783 since it's needed by the index
+			tk.MustExec("set @@tidb_opt_fix_control = '783:ON'")
+			if choice == model.ColumnList {
+				tk.MustExec("analyze table t columns b with 2 topn, 2 buckets")
+			} else {
+				tk.MustExec("analyze table t predicate columns with 2 topn, 2 buckets")
+			}
+			rows = tk.MustQuery("show column_stats_usage where db_name = 'test' and table_name = 't' and last_analyzed_at is not null").Sort().Rows()
+			require.Equal(t, 2, len(rows))
+			require.Equal(t, "b", rows[0][3])
+			require.Equal(t, "c", rows[1][3])
+			tk.MustQuery("show stats_topn where db_name = 'test' and table_name = 't' and column_name = 'c'").Sort().Check(
+				testkit.Rows("test t  c 0 5 2",
+					"test t  c 0 6 3"))
+			tk.MustQuery(fmt.Sprintf("select distinct_count, null_count, stats_ver, truncate(correlation,2) from mysql.stats_histograms where table_id = %d and is_index = 0 and hist_id = 3", tblID)).Check(
+				testkit.Rows("5 1 2 1"))
+			tk.MustQuery("show stats_buckets where db_name = 'test' and table_name = 't' and column_name = 'c'").Sort().Check(
+				testkit.Rows("test t  c 0 0 2 1 2 3 0",
+					"test t  c 0 1 3 1 4 4 0"))
 		}(val)
 	}
 }
@@ -93,6 +93,62 @@ func getKeysNeedCheck(ctx context.Context, sctx sessionctx.Context, t table.Tabl
 	return toBeCheckRows, nil
 }
 
+// batchKeyChecker tells whether a to-be-checked row may conflict with the existing keys, by the values
+// that are prefetched by BatchGet at the beginning of the batch and the keys that are written by the
+// rows before it in the same batch. The rows that can't conflict are the majority of bulk INSERT IGNORE
+// and REPLACE statements, they are inserted directly without the per-row kv.Transaction Get calls.
+// A nil batchKeyChecker means the keys are not prefetched, and all the rows need to be checked.
+type batchKeyChecker struct {
+	prefetched map[string][]byte
+	written    map[string]struct{}
+}
+
+func newBatchKeyChecker(prefetched map[string][]byte, rows int) *batchKeyChecker {
+	if prefetched == nil {
+		return nil
+	}
+	return &batchKeyChecker{prefetched: prefetched, written: make(map[string]struct{}, rows)}
+}
+
+// mayConflict returns false if the handle key and all the unique keys of the row don't exist.
+// The keys that are removed in the batch are still treated as existing, which only makes the
+// row go through the normal check.
+func (c *batchKeyChecker) mayConflict(r toBeCheckedRow) bool {
+	if c == nil {
+		return true
+	}
+	if r.handleKey != nil && c.exists(r.handleKey.newKey) {
+		return true
+	}
+	for _, uk := range r.uniqueKeys {
+		if c.exists(uk.newKey) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *batchKeyChecker) exists(key kv.Key) bool {
+	if _, ok := c.prefetched[string(key)]; ok {
+		return true
+	}
+	_, ok := c.written[string(key)]
+	return ok
+}
+
+// markWritten records the keys of the row that may be written to the transaction.
+func (c *batchKeyChecker) markWritten(r toBeCheckedRow) {
+	if c == nil {
+		return
+	}
+	if r.handleKey != nil {
+		c.written[string(r.handleKey.newKey)] = struct{}{}
+	}
+	for _, uk := range r.uniqueKeys {
+		c.written[string(uk.newKey)] = struct{}{}
+	}
+}
+
 func getKeysNeedCheckOneRow(ctx sessionctx.Context, t table.Table, row []types.Datum, nUnique int, handleCols []*table.Column,
 	pkIdxInfo *model.IndexInfo, result []toBeCheckedRow) ([]toBeCheckedRow, error) {
 	var err error
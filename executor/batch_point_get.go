@@ -39,6 +39,7 @@ import (
 	"github.com/pingcap/tidb/util/hack"
 	"github.com/pingcap/tidb/util/logutil/consistency"
 	"github.com/pingcap/tidb/util/math"
+	"github.com/pingcap/tidb/util/netsim"
 	"github.com/pingcap/tidb/util/rowcodec"
 	"github.com/tikv/client-go/v2/txnkv/txnsnapshot"
 )
@@ -261,6 +262,7 @@ func datumsContainNull(vals []types.Datum) bool {
 }
 
 func (e *BatchPointGetExec) initialize(ctx context.Context) error {
+	ctx = netsim.WithSimulation(ctx, e.ctx.GetSessionVars().StoreNetworkSimulation)
 	var handleVals map[string][]byte
 	var indexKeys []kv.Key
 	var err error
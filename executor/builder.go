@@ -43,6 +43,7 @@ import (
 	"github.com/pingcap/tidb/parser/mysql"
 	plannercore "github.com/pingcap/tidb/planner/core"
 	plannerutil "github.com/pingcap/tidb/planner/util"
+	"github.com/pingcap/tidb/planner/util/fixcontrol"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
@@ -164,10 +165,14 @@ func (b *executorBuilder) build(p plannercore.Plan) Executor {
 		return b.buildRecoverIndex(v)
 	case *plannercore.CleanupIndex:
 		return b.buildCleanupIndex(v)
+	case *plannercore.RepairIndex:
+		return b.buildRepairIndex(v)
 	case *plannercore.CheckIndexRange:
 		return b.buildCheckIndexRange(v)
 	case *plannercore.ChecksumTable:
 		return b.buildChecksumTable(v)
+	case *plannercore.ReclaimTable:
+		return b.buildReclaimTable(v)
 	case *plannercore.ReloadExprPushdownBlacklist:
 		return b.buildReloadExprPushdownBlacklist(v)
 	case *plannercore.ReloadOptRuleBlacklist:
@@ -292,6 +297,24 @@ func (b *executorBuilder) build(p plannercore.Plan) Executor {
 		return b.buildAdminShowTelemetry(v)
 	case *plannercore.AdminResetTelemetryID:
 		return b.buildAdminResetTelemetryID(v)
+	case *plannercore.AdminShowClusterSettings:
+		return b.buildAdminShowClusterSettings(v)
+	case *plannercore.AdminSetClusterSetting:
+		return b.buildAdminSetClusterSetting(v)
+	case *plannercore.AdminShowSafePoints:
+		return &AdminShowSafePointsExec{baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID())}
+	case *plannercore.AdminShowRedundantIndexes:
+		return &AdminShowRedundantIndexesExec{baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID())}
+	case *plannercore.AdminDecodeKey:
+		return &AdminDecodeKeyExec{baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()), key: v.Key}
+	case *plannercore.AdminDecodePlan:
+		return &AdminDecodePlanExec{baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()), plan: v.Plan}
+	case *plannercore.AdminShowMVCCByKey:
+		return &AdminShowMVCCByKeyExec{baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()), key: v.Key}
+	case *plannercore.AdminSetSafePoint:
+		return b.buildAdminSetSafePoint(v)
+	case *plannercore.AdminExpireSafePoint:
+		return &AdminExpireSafePointExec{baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()), serviceID: v.ServiceID}
 	case *plannercore.PhysicalCTE:
 		return b.buildCTE(v)
 	case *plannercore.PhysicalCTETable:
@@ -543,20 +566,28 @@ func buildIdxColsConcatHandleCols(tblInfo *model.TableInfo, indexInfo *model.Ind
 }
 
 func (b *executorBuilder) buildRecoverIndex(v *plannercore.RecoverIndex) Executor {
-	tblInfo := v.Table.TableInfo
-	t, err := b.is.TableByName(v.Table.Schema, tblInfo.Name)
+	e := b.buildRecoverIndexExec(v.Table, v.IndexName, newBaseExecutor(b.ctx, v.Schema(), v.ID()))
+	if b.err != nil {
+		return nil
+	}
+	return e
+}
+
+func (b *executorBuilder) buildRecoverIndexExec(tn *ast.TableName, indexName string, base baseExecutor) *RecoverIndexExec {
+	tblInfo := tn.TableInfo
+	t, err := b.is.TableByName(tn.Schema, tblInfo.Name)
 	if err != nil {
 		b.err = err
 		return nil
 	}
-	idxName := strings.ToLower(v.IndexName)
+	idxName := strings.ToLower(indexName)
 	index := tables.GetWritableIndexByName(idxName, t)
 	if index == nil {
-		b.err = errors.Errorf("index `%v` is not found in table `%v`.", v.IndexName, v.Table.Name.O)
+		b.err = errors.Errorf("index `%v` is not found in table `%v`.", indexName, tn.Name.O)
 		return nil
 	}
 	e := &RecoverIndexExec{
-		baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()),
+		baseExecutor: base,
 		columns:      buildIdxColsConcatHandleCols(tblInfo, index.Meta()),
 		index:        index,
 		table:        t,
@@ -593,13 +624,21 @@ func buildHandleColsForExec(sctx *stmtctx.StatementContext, tblInfo *model.Table
 }
 
 func (b *executorBuilder) buildCleanupIndex(v *plannercore.CleanupIndex) Executor {
-	tblInfo := v.Table.TableInfo
-	t, err := b.is.TableByName(v.Table.Schema, tblInfo.Name)
+	e := b.buildCleanupIndexExec(v.Table, v.IndexName, newBaseExecutor(b.ctx, v.Schema(), v.ID()))
+	if b.err != nil {
+		return nil
+	}
+	return e
+}
+
+func (b *executorBuilder) buildCleanupIndexExec(tn *ast.TableName, indexName string, base baseExecutor) *CleanupIndexExec {
+	tblInfo := tn.TableInfo
+	t, err := b.is.TableByName(tn.Schema, tblInfo.Name)
 	if err != nil {
 		b.err = err
 		return nil
 	}
-	idxName := strings.ToLower(v.IndexName)
+	idxName := strings.ToLower(indexName)
 	var index table.Index
 	for _, idx := range t.Indices() {
 		if idx.Meta().State != model.StatePublic {
@@ -612,11 +651,11 @@ func (b *executorBuilder) buildCleanupIndex(v *plannercore.CleanupIndex) Executo
 	}
 
 	if index == nil {
-		b.err = errors.Errorf("index `%v` is not found in table `%v`.", v.IndexName, v.Table.Name.O)
+		b.err = errors.Errorf("index `%v` is not found in table `%v`.", indexName, tn.Name.O)
 		return nil
 	}
 	e := &CleanupIndexExec{
-		baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()),
+		baseExecutor: base,
 		columns:      buildIdxColsConcatHandleCols(tblInfo, index.Meta()),
 		index:        index,
 		table:        t,
@@ -628,6 +667,43 @@ func (b *executorBuilder) buildCleanupIndex(v *plannercore.CleanupIndex) Executo
 	return e
 }
 
+func (b *executorBuilder) buildRepairIndex(v *plannercore.RepairIndex) Executor {
+	cleanupSchema := expression.NewSchema(&expression.Column{RetType: types.NewFieldType(mysql.TypeLonglong)})
+	cleanup := b.buildCleanupIndexExec(v.Table, v.IndexName, newBaseExecutor(b.ctx, cleanupSchema, 0))
+	if b.err != nil {
+		return nil
+	}
+	cleanup.repair = true
+	// The index entries not matching their rows can't be found if the index has virtual generated columns,
+	// since their values are not stored in the rows.
+	rowIdxCols := make([]*table.Column, 0, len(cleanup.index.Meta().Columns))
+	for _, idxCol := range cleanup.index.Meta().Columns {
+		col := v.Table.TableInfo.Columns[idxCol.Offset]
+		if col.IsGenerated() && !col.GeneratedStored {
+			rowIdxCols = nil
+			b.ctx.GetSessionVars().StmtCtx.AppendWarning(errors.Errorf(
+				"index `%v` has virtual generated columns, only the dangling index entries are repaired", v.IndexName))
+			break
+		}
+		rowIdxCols = append(rowIdxCols, table.ToColumn(col))
+	}
+	cleanup.rowIdxCols = rowIdxCols
+
+	recoverSchema := expression.NewSchema(
+		&expression.Column{RetType: types.NewFieldType(mysql.TypeLonglong)},
+		&expression.Column{RetType: types.NewFieldType(mysql.TypeLonglong)})
+	recover := b.buildRecoverIndexExec(v.Table, v.IndexName, newBaseExecutor(b.ctx, recoverSchema, 0))
+	if b.err != nil {
+		return nil
+	}
+	recover.repair = true
+	return &RepairIndexExec{
+		baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID(), cleanup, recover),
+		cleanup:      cleanup,
+		recover:      recover,
+	}
+}
+
 func (b *executorBuilder) buildCheckIndexRange(v *plannercore.CheckIndexRange) Executor {
 	tb, err := b.is.TableByName(v.Table.Schema, v.Table.Name)
 	if err != nil {
@@ -668,6 +744,14 @@ func (b *executorBuilder) buildChecksumTable(v *plannercore.ChecksumTable) Execu
 	return e
 }
 
+func (b *executorBuilder) buildReclaimTable(v *plannercore.ReclaimTable) Executor {
+	return &ReclaimTableExec{
+		baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()),
+		tables:       v.Tables,
+		compact:      v.Compact,
+	}
+}
+
 func (b *executorBuilder) buildReloadExprPushdownBlacklist(v *plannercore.ReloadExprPushdownBlacklist) Executor {
 	return &ReloadExprPushdownBlacklistExec{baseExecutor{ctx: b.ctx}}
 }
@@ -798,6 +882,7 @@ func (b *executorBuilder) buildExecute(v *plannercore.Execute) Executor {
 		id:           v.ExecID,
 		stmt:         v.Stmt,
 		plan:         v.Plan,
+		cacheHit:     v.CacheHit,
 		outputNames:  v.OutputNames(),
 	}
 	failpoint.Inject("assertExecutePrepareStatementStalenessOption", func(val failpoint.Value) {
@@ -1294,6 +1379,7 @@ func (b *executorBuilder) buildHashJoin(v *plannercore.PhysicalHashJoin) Executo
 		joinType:        v.JoinType,
 		isOuterJoin:     v.JoinType.IsOuterJoin(),
 		useOuterToBuild: v.UseOuterToBuild,
+		isNullAware:     len(v.NAEqualConditions) > 0,
 	}
 	defaultValues := v.DefaultValues
 	lhsTypes, rhsTypes := retTypes(leftExec), retTypes(rightExec)
@@ -1344,10 +1430,15 @@ func (b *executorBuilder) buildHashJoin(v *plannercore.PhysicalHashJoin) Executo
 	}
 	e.buildSideEstCount = b.buildSideEstCount(v)
 	childrenUsedSchema := markChildrenUsedCols(v.Schema(), v.Children()[0].Schema(), v.Children()[1].Schema())
+	otherConditions := v.OtherConditions
+	if e.isNullAware {
+		// The joiner tells whether the result of `NOT IN` is NULL by the null-aware equal conditions.
+		otherConditions = append(expression.ScalarFuncs2Exprs(v.NAEqualConditions), v.OtherConditions...)
+	}
 	e.joiners = make([]joiner, e.concurrency)
 	for i := uint(0); i < e.concurrency; i++ {
 		e.joiners[i] = newJoiner(b.ctx, v.JoinType, v.InnerChildIdx == 0, defaultValues,
-			v.OtherConditions, lhsTypes, rhsTypes, childrenUsedSchema)
+			otherConditions, lhsTypes, rhsTypes, childrenUsedSchema)
 	}
 	executorCountHashJoinExec.Inc()
 
@@ -1372,6 +1463,11 @@ func (b *executorBuilder) buildHashJoin(v *plannercore.PhysicalHashJoin) Executo
 		leftTypes[i].Charset, leftTypes[i].Collate = chs, coll
 		rightTypes[i].Charset, rightTypes[i].Collate = chs, coll
 	}
+	for i := range v.NAEqualConditions {
+		chs, coll := v.NAEqualConditions[i].CharsetAndCollation()
+		leftTypes[len(v.EqualConditions)+i].Charset, leftTypes[len(v.EqualConditions)+i].Collate = chs, coll
+		rightTypes[len(v.EqualConditions)+i].Charset, rightTypes[len(v.EqualConditions)+i].Collate = chs, coll
+	}
 	if leftIsBuildSide {
 		e.buildTypes, e.probeTypes = leftTypes, rightTypes
 	} else {
@@ -1497,6 +1593,9 @@ func (b *executorBuilder) buildSelection(v *plannercore.PhysicalSelection) Execu
 		baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID(), childExec),
 		filters:      v.Conditions,
 	}
+	if b.ctx.GetSessionVars().EnableVectorizedExpression {
+		e.fused = expression.FuseFilters(v.Conditions)
+	}
 	return e
 }
 
@@ -1597,6 +1696,39 @@ func (b *executorBuilder) getReadTS() (uint64, error) {
 }
 
 func (b *executorBuilder) buildMemTable(v *plannercore.PhysicalMemTable) Executor {
+	if extractor, ok := v.Extractor.(*plannercore.ExternalTableExtractor); ok {
+		filters := make([]expression.Expression, 0, len(extractor.Filters))
+		for _, filter := range extractor.Filters {
+			filter, err := filter.ResolveIndices(v.Schema())
+			if err != nil {
+				b.err = err
+				return nil
+			}
+			filters = append(filters, filter)
+		}
+		return &MemTableReaderExec{
+			baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()),
+			table:        v.Table,
+			retriever: &externalTableRetriever{
+				source:      extractor.Source,
+				fileColumns: len(v.Table.Columns),
+				columns:     v.Columns,
+				filters:     filters,
+			},
+		}
+	}
+	if v.Table.IsForeignTable() {
+		return &MemTableReaderExec{
+			baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()),
+			table:        v.Table,
+			retriever: &foreignTableRetriever{
+				dbName:    v.DBName,
+				table:     v.Table,
+				columns:   v.Columns,
+				extractor: v.Extractor.(*plannercore.ForeignTableExtractor),
+			},
+		}
+	}
 	switch v.DBName.L {
 	case util.MetricSchemaName.L:
 		return &MemTableReaderExec{
@@ -1748,13 +1880,26 @@ func (b *executorBuilder) buildMemTable(v *plannercore.PhysicalMemTable) Executo
 			strings.ToLower(infoschema.TableClientErrorsSummaryByUser),
 			strings.ToLower(infoschema.TableClientErrorsSummaryByHost),
 			strings.ToLower(infoschema.TableAttributes),
-			strings.ToLower(infoschema.TablePlacementPolicies):
+			strings.ToLower(infoschema.TablePlacementPolicies),
+			strings.ToLower(infoschema.TablePlanCache),
+			strings.ToLower(infoschema.ClusterTablePlanCache),
+			strings.ToLower(infoschema.TableBindingCache),
+			strings.ToLower(infoschema.ClusterTableBindingCache),
+			strings.ToLower(infoschema.TableTableWorkload),
+			strings.ToLower(infoschema.ClusterTableTableWorkload),
+			strings.ToLower(infoschema.TableIndexUsage),
+			strings.ToLower(infoschema.ClusterTableIndexUsage),
+			strings.ToLower(infoschema.TableDDLBlockers),
+			strings.ToLower(infoschema.ClusterTableDDLBlockers),
+			strings.ToLower(infoschema.TablePlanHistory),
+			strings.ToLower(infoschema.ClusterTablePlanHistory):
 			return &MemTableReaderExec{
 				baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()),
 				table:        v.Table,
 				retriever: &memtableRetriever{
-					table:   v.Table,
-					columns: v.Columns,
+					table:     v.Table,
+					columns:   v.Columns,
+					extractor: v.Extractor,
 				},
 			}
 		case strings.ToLower(infoschema.TableTiDBTrx),
@@ -2094,7 +2239,9 @@ func (b *executorBuilder) buildUpdate(v *plannercore.Update) Executor {
 		return nil
 	}
 	base := newBaseExecutor(b.ctx, v.Schema(), v.ID(), selExec)
-	base.initCap = chunk.ZeroCapacity
+	if v.Returning == nil {
+		base.initCap = chunk.ZeroCapacity
+	}
 	var assignFlag []int
 	assignFlag, b.err = getAssignFlag(b.ctx, v, selExec.Schema().Len())
 	if b.err != nil {
@@ -2115,6 +2262,10 @@ func (b *executorBuilder) buildUpdate(v *plannercore.Update) Executor {
 		tblColPosInfos:            v.TblColPosInfos,
 		assignFlag:                assignFlag,
 	}
+	if v.Returning != nil {
+		// The RETURNING clause is evaluated on the new values of the row followed by the old values.
+		updateExec.returning = newDMLReturning(v.Returning, append(retTypes(selExec), retTypes(selExec)...))
+	}
 	return updateExec
 }
 
@@ -2151,13 +2302,18 @@ func (b *executorBuilder) buildDelete(v *plannercore.Delete) Executor {
 		return nil
 	}
 	base := newBaseExecutor(b.ctx, v.Schema(), v.ID(), selExec)
-	base.initCap = chunk.ZeroCapacity
+	if v.Returning == nil {
+		base.initCap = chunk.ZeroCapacity
+	}
 	deleteExec := &DeleteExec{
 		baseExecutor:   base,
 		tblID2Table:    tblID2table,
 		IsMultiTable:   v.IsMultiTable,
 		tblColPosInfos: v.TblColPosInfos,
 	}
+	if v.Returning != nil {
+		deleteExec.returning = newDMLReturning(v.Returning, retTypes(selExec))
+	}
 	return deleteExec
 }
 
@@ -2371,6 +2527,7 @@ func (b *executorBuilder) buildAnalyzeSamplingPushdown(task plannercore.AnalyzeC
 		schemaForVirtualColEval: schemaForVirtualColEval,
 		baseCount:               count,
 		baseModifyCnt:           modifyCount,
+		analyzeVirtualCols:      fixcontrol.GetBoolWithDefault(b.ctx.GetSessionVars().OptimizerFixControl, fixcontrol.Fix783, false),
 	}
 	sampleRate := new(float64)
 	if opts[ast.AnalyzeOptNumSamples] == 0 {
@@ -3193,10 +3350,68 @@ func buildNoRangeTableReader(b *executorBuilder, v *plannercore.PhysicalTableRea
 	for i := range v.Schema().Columns {
 		dagReq.OutputOffsets = append(dagReq.OutputOffsets, uint32(i))
 	}
+	if v.StoreType == kv.TiFlash && b.ctx.GetSessionVars().TiFlashFallbackRangesToTiKV {
+		e.tikvFallbackDAG = constructTiKVFallbackDAGReq(b.ctx, v.TablePlans)
+		if e.tikvFallbackDAG != nil {
+			e.tikvFallbackDAG.OutputOffsets = dagReq.OutputOffsets
+			e.tikvFallbackDAG.CollectRangeCounts = dagReq.CollectRangeCounts
+		}
+	}
 
 	return e, nil
 }
 
+// constructTiKVFallbackDAGReq constructs the DAG request reading the regions of a TiFlash table reader from TiKV, it
+// returns nil if any operator or expression pushed down to TiFlash isn't supported by TiKV.
+func constructTiKVFallbackDAGReq(ctx sessionctx.Context, plans []plannercore.PhysicalPlan) *tipb.DAGRequest {
+	sc := ctx.GetSessionVars().StmtCtx
+	client := ctx.GetClient()
+	canAggsPushDown := func(aggFuncs []*aggregation.AggFuncDesc) bool {
+		for _, f := range aggFuncs {
+			if !aggregation.CheckAggPushDown(f, kv.TiKV) || !expression.CanExprsPushDown(sc, f.Args, client, kv.TiKV) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, p := range plans {
+		var exprs []expression.Expression
+		switch x := p.(type) {
+		case *plannercore.PhysicalTableScan:
+			if x.IsGlobalRead {
+				return nil
+			}
+		case *plannercore.PhysicalSelection:
+			exprs = x.Conditions
+		case *plannercore.PhysicalLimit:
+		case *plannercore.PhysicalTopN:
+			for _, item := range x.ByItems {
+				exprs = append(exprs, item.Expr)
+			}
+		case *plannercore.PhysicalHashAgg:
+			if !canAggsPushDown(x.AggFuncs) {
+				return nil
+			}
+			exprs = x.GroupByItems
+		case *plannercore.PhysicalStreamAgg:
+			if !canAggsPushDown(x.AggFuncs) {
+				return nil
+			}
+			exprs = x.GroupByItems
+		default:
+			return nil
+		}
+		if !expression.CanExprsPushDown(sc, exprs, client, kv.TiKV) {
+			return nil
+		}
+	}
+	dagReq, _, err := constructDAGReq(ctx, plans, kv.TiKV)
+	if err != nil {
+		return nil
+	}
+	return dagReq
+}
+
 func extraPIDColumnIndex(schema *expression.Schema) offsetOptional {
 	for idx, col := range schema.Columns {
 		if col.ID == model.ExtraPidColID {
@@ -3968,6 +4183,9 @@ func (builder *dataReaderBuilder) buildTableReaderForIndexJoin(ctx context.Conte
 				kvRanges = append(tmp, kvRanges...)
 			}
 		}
+		if canReorderHandles {
+			kvRanges = sortAndMergeKvRanges(kvRanges)
+		}
 		return builder.buildTableReaderFromKvRanges(ctx, e, kvRanges)
 	}
 
@@ -4016,6 +4234,9 @@ func (builder *dataReaderBuilder) buildTableReaderForIndexJoin(ctx context.Conte
 			kvRanges = append(kvRanges, tmp...)
 		}
 	}
+	if canReorderHandles {
+		kvRanges = sortAndMergeKvRanges(kvRanges)
+	}
 	return builder.buildTableReaderFromKvRanges(ctx, e, kvRanges)
 }
 
@@ -4351,10 +4572,7 @@ func buildKvRangesForIndexJoin(ctx sessionctx.Context, tableID, indexID int64, l
 		memTracker.Consume(2 * int64(len(tmpDatumRanges)) * types.EstimatedMemUsage(tmpDatumRanges[0].LowVal, len(tmpDatumRanges)))
 	}
 	if cwc == nil {
-		sort.Slice(kvRanges, func(i, j int) bool {
-			return bytes.Compare(kvRanges[i].StartKey, kvRanges[j].StartKey) < 0
-		})
-		return kvRanges, nil
+		return sortAndMergeKvRanges(kvRanges), nil
 	}
 
 	tmpDatumRanges, err = ranger.UnionRanges(ctx, tmpDatumRanges, true)
@@ -4368,6 +4586,30 @@ func buildKvRangesForIndexJoin(ctx sessionctx.Context, tableID, indexID int64, l
 	return distsql.IndexRangesToKVRangesWithInterruptSignal(ctx.GetSessionVars().StmtCtx, tableID, indexID, tmpDatumRanges, nil, memTracker, interruptSignal)
 }
 
+// sortAndMergeKvRanges sorts the ranges by the start key and merges the overlapped
+// or adjacent ones, so that the ranges of one outer batch falling in the same region
+// are sent in one cop task instead of one task per run of the unsorted ranges.
+func sortAndMergeKvRanges(kvRanges []kv.KeyRange) []kv.KeyRange {
+	if len(kvRanges) < 2 {
+		return kvRanges
+	}
+	sort.Slice(kvRanges, func(i, j int) bool {
+		return bytes.Compare(kvRanges[i].StartKey, kvRanges[j].StartKey) < 0
+	})
+	merged := kvRanges[:1]
+	for _, r := range kvRanges[1:] {
+		last := &merged[len(merged)-1]
+		if bytes.Compare(r.StartKey, last.EndKey) > 0 {
+			merged = append(merged, r)
+			continue
+		}
+		if bytes.Compare(r.EndKey, last.EndKey) > 0 {
+			last.EndKey = r.EndKey
+		}
+	}
+	return merged
+}
+
 func (b *executorBuilder) buildWindow(v *plannercore.PhysicalWindow) Executor {
 	childExec := b.build(v.Children()[0])
 	if b.err != nil {
@@ -4715,6 +4957,27 @@ func (b *executorBuilder) buildAdminResetTelemetryID(v *plannercore.AdminResetTe
 	return &AdminResetTelemetryIDExec{baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID())}
 }
 
+func (b *executorBuilder) buildAdminShowClusterSettings(v *plannercore.AdminShowClusterSettings) Executor {
+	return &AdminShowClusterSettingsExec{baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID())}
+}
+
+func (b *executorBuilder) buildAdminSetClusterSetting(v *plannercore.AdminSetClusterSetting) Executor {
+	return &AdminSetClusterSettingExec{
+		baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()),
+		name:         v.Name,
+		value:        v.Value,
+	}
+}
+
+func (b *executorBuilder) buildAdminSetSafePoint(v *plannercore.AdminSetSafePoint) Executor {
+	return &AdminSetSafePointExec{
+		baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()),
+		serviceID:    v.ServiceID,
+		safePoint:    v.SafePoint,
+		ttl:          v.TTL,
+	}
+}
+
 func partitionPruning(ctx sessionctx.Context, tbl table.PartitionedTable, conds []expression.Expression, partitionNames []model.CIStr,
 	columns []*expression.Column, columnNames types.NameSlice) ([]table.PhysicalTable, error) {
 	idxArr, err := plannercore.PartitionPruning(ctx, tbl, conds, partitionNames, columns, columnNames)
@@ -166,6 +166,8 @@ func (e *DDLExec) Next(ctx context.Context, req *chunk.Chunk) (err error) {
 		err = e.executeRecoverTable(x)
 	case *ast.FlashBackTableStmt:
 		err = e.executeFlashbackTable(x)
+	case *ast.FlashBackClusterStmt:
+		err = e.executeFlashbackCluster(x)
 	case *ast.RenameTableStmt:
 		err = e.executeRenameTable(x)
 	case *ast.TruncateTableStmt:
@@ -757,6 +759,14 @@ func (e *DDLExec) executeFlashbackTable(s *ast.FlashBackTableStmt) error {
 	return err
 }
 
+func (e *DDLExec) executeFlashbackCluster(s *ast.FlashBackClusterStmt) error {
+	flashbackTS, err := core.CalculateTsExpr(e.ctx, &ast.AsOfClause{TsExpr: s.FlashbackTS})
+	if err != nil {
+		return err
+	}
+	return domain.GetDomain(e.ctx).DDL().FlashbackCluster(e.ctx, flashbackTS)
+}
+
 func (e *DDLExec) executeLockTables(s *ast.LockTablesStmt) error {
 	if !config.TableLockEnabled() {
 		e.ctx.GetSessionVars().StmtCtx.AppendWarning(ErrFuncNotEnabled.GenWithStackByArgs("LOCK TABLES", "enable-table-lock"))
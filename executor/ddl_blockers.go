@@ -0,0 +1,135 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"sort"
+
+	"github.com/pingcap/tidb/parser/model"
+	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/session/txninfo"
+)
+
+// RelatedTableIDs returns the IDs of the tables accessed by the statement, the partitions are returned as
+// their tables.
+func (a *ExecStmt) RelatedTableIDs() []int64 {
+	if a.Plan == nil {
+		return nil
+	}
+	tableIDs := make(map[int64]struct{})
+	collectRelatedTableIDs(a.Plan, tableIDs)
+	result := make([]int64, 0, len(tableIDs))
+	for id := range tableIDs {
+		result = append(result, id)
+	}
+	return result
+}
+
+func collectRelatedTableIDs(p plannercore.Plan, tableIDs map[int64]struct{}) {
+	switch x := p.(type) {
+	case *plannercore.PhysicalTableReader:
+		collectScannedTableIDs(x.TablePlans, tableIDs)
+	case *plannercore.PhysicalIndexReader:
+		collectScannedTableIDs(x.IndexPlans, tableIDs)
+	case *plannercore.PhysicalIndexLookUpReader:
+		collectScannedTableIDs(x.IndexPlans, tableIDs)
+	case *plannercore.PhysicalIndexMergeReader:
+		for _, partialPlans := range x.PartialPlans {
+			collectScannedTableIDs(partialPlans, tableIDs)
+		}
+	case *plannercore.PointGetPlan:
+		tableIDs[x.TblInfo.ID] = struct{}{}
+	case *plannercore.BatchPointGetPlan:
+		tableIDs[x.TblInfo.ID] = struct{}{}
+	case *plannercore.PhysicalCTE:
+		if x.SeedPlan != nil {
+			collectRelatedTableIDs(x.SeedPlan, tableIDs)
+		}
+		if x.RecurPlan != nil {
+			collectRelatedTableIDs(x.RecurPlan, tableIDs)
+		}
+	case plannercore.PhysicalPlan:
+		for _, child := range x.Children() {
+			collectRelatedTableIDs(child, tableIDs)
+		}
+	case *plannercore.Execute:
+		collectRelatedTableIDs(x.Plan, tableIDs)
+	case *plannercore.Insert:
+		tableIDs[x.Table.Meta().ID] = struct{}{}
+		if x.SelectPlan != nil {
+			collectRelatedTableIDs(x.SelectPlan, tableIDs)
+		}
+	case *plannercore.Update:
+		for _, info := range x.TblColPosInfos {
+			tableIDs[info.TblID] = struct{}{}
+		}
+		if x.SelectPlan != nil {
+			collectRelatedTableIDs(x.SelectPlan, tableIDs)
+		}
+	case *plannercore.Delete:
+		for _, info := range x.TblColPosInfos {
+			tableIDs[info.TblID] = struct{}{}
+		}
+		if x.SelectPlan != nil {
+			collectRelatedTableIDs(x.SelectPlan, tableIDs)
+		}
+	}
+}
+
+// collectScannedTableIDs collects the tables scanned by the pushed down plans, the MPP plans may scan
+// several tables.
+func collectScannedTableIDs(copPlans []plannercore.PhysicalPlan, tableIDs map[int64]struct{}) {
+	for _, p := range copPlans {
+		switch scan := p.(type) {
+		case *plannercore.PhysicalTableScan:
+			tableIDs[scan.Table.ID] = struct{}{}
+		case *plannercore.PhysicalIndexScan:
+			tableIDs[scan.Table.ID] = struct{}{}
+		}
+	}
+}
+
+// ddlBlocker is a transaction blocking a running DDL job. The transaction is started before the job is
+// submitted and has accessed the table changed by the job, so it keeps using the old schema of the table
+// until it ends.
+type ddlBlocker struct {
+	job *model.Job
+	txn *txninfo.TxnInfo
+}
+
+// findDDLBlockers returns the transactions blocking the running DDL jobs, ordered by the job ID and the
+// start ts of the transactions.
+func findDDLBlockers(jobs []*model.Job, txns []*txninfo.TxnInfo) []ddlBlocker {
+	sort.Slice(txns, func(i, j int) bool { return txns[i].StartTS < txns[j].StartTS })
+	var result []ddlBlocker
+	for _, job := range jobs {
+		if !job.IsRunning() && !job.IsRollingback() {
+			continue
+		}
+		for _, txn := range txns {
+			if txn.StartTS >= job.StartTS {
+				break
+			}
+			for _, id := range txn.RelatedTableIDs {
+				if id == job.TableID {
+					result = append(result, ddlBlocker{job: job, txn: txn})
+					break
+				}
+			}
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].job.ID < result[j].job.ID })
+	return result
+}
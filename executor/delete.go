@@ -43,6 +43,14 @@ type DeleteExec struct {
 	// the columns ordinals is present in ordinal range format, @see plannercore.TblColPosInfos
 	tblColPosInfos plannercore.TblColPosInfoSlice
 	memTracker     *memory.Tracker
+	// returning is not nil if the DELETE has a RETURNING clause.
+	returning *dmlReturning
+	// chk, memUsageOfChk and rowCount are the states of deleting the single table, they are kept across
+	// the calls of Next when the results of RETURNING are returned chunk by chunk.
+	chk           *chunk.Chunk
+	memUsageOfChk int64
+	rowCount      int
+	drained       bool
 }
 
 // Next implements the Executor Next interface.
@@ -51,7 +59,10 @@ func (e *DeleteExec) Next(ctx context.Context, req *chunk.Chunk) error {
 	if e.IsMultiTable {
 		return e.deleteMultiTablesByChunk(ctx)
 	}
-	return e.deleteSingleTableByChunk(ctx)
+	if e.drained {
+		return nil
+	}
+	return e.deleteSingleTableByChunk(ctx, req)
 }
 
 func (e *DeleteExec) deleteOneRow(tbl table.Table, handleCols plannercore.HandleCols, isExtraHandle bool, row []types.Datum) error {
@@ -70,12 +81,13 @@ func (e *DeleteExec) deleteOneRow(tbl table.Table, handleCols plannercore.Handle
 	return nil
 }
 
-func (e *DeleteExec) deleteSingleTableByChunk(ctx context.Context) error {
+// deleteSingleTableByChunk deletes the rows of the child until it's drained. If the DELETE has a RETURNING clause,
+// it returns once the results of a chunk of the child are appended to req.
+func (e *DeleteExec) deleteSingleTableByChunk(ctx context.Context, req *chunk.Chunk) error {
 	var (
 		tbl           table.Table
 		isExtrahandle bool
 		handleCols    plannercore.HandleCols
-		rowCount      int
 	)
 	for _, info := range e.tblColPosInfos {
 		tbl = e.tblID2Table[info.TblID]
@@ -90,7 +102,12 @@ func (e *DeleteExec) deleteSingleTableByChunk(ctx context.Context) error {
 	batchDelete := e.ctx.GetSessionVars().BatchDelete && !e.ctx.GetSessionVars().InTxn() &&
 		config.GetGlobalConfig().EnableBatchDML && batchDMLSize > 0
 	fields := retTypes(e.children[0])
-	chk := newFirstChunk(e.children[0])
+	if e.chk == nil {
+		e.chk = newFirstChunk(e.children[0])
+	}
+	if e.returning != nil {
+		e.returning.output = req
+	}
 	columns := e.children[0].Schema().Columns
 	if len(columns) != len(fields) {
 		logutil.BgLogger().Error("schema columns and fields mismatch",
@@ -99,25 +116,27 @@ func (e *DeleteExec) deleteSingleTableByChunk(ctx context.Context) error {
 		// Should never run here, so the error code is not defined.
 		return errors.New("schema columns and fields mismatch")
 	}
-	memUsageOfChk := int64(0)
 	for {
-		e.memTracker.Consume(-memUsageOfChk)
+		chk := e.chk
+		e.memTracker.Consume(-e.memUsageOfChk)
 		iter := chunk.NewIterator4Chunk(chk)
 		err := Next(ctx, e.children[0], chk)
 		if err != nil {
 			return err
 		}
 		if chk.NumRows() == 0 {
+			e.memUsageOfChk = 0
+			e.drained = true
 			break
 		}
-		memUsageOfChk = chk.MemoryUsage()
-		e.memTracker.Consume(memUsageOfChk)
+		e.memUsageOfChk = chk.MemoryUsage()
+		e.memTracker.Consume(e.memUsageOfChk)
 		for chunkRow := iter.Begin(); chunkRow != iter.End(); chunkRow = iter.Next() {
-			if batchDelete && rowCount >= batchDMLSize {
+			if batchDelete && e.rowCount >= batchDMLSize {
 				if err := e.doBatchDelete(ctx); err != nil {
 					return err
 				}
-				rowCount = 0
+				e.rowCount = 0
 			}
 
 			datumRow := make([]types.Datum, 0, len(fields))
@@ -134,9 +153,17 @@ func (e *DeleteExec) deleteSingleTableByChunk(ctx context.Context) error {
 			if err != nil {
 				return err
 			}
-			rowCount++
+			if e.returning != nil {
+				if err = e.returning.appendRow(chunkRow); err != nil {
+					return err
+				}
+			}
+			e.rowCount++
+		}
+		e.chk = chunk.Renew(chk, e.maxChunkSize)
+		if req.NumRows() > 0 {
+			break
 		}
-		chk = chunk.Renew(chk, e.maxChunkSize)
 	}
 
 	return nil
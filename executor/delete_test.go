@@ -19,8 +19,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pingcap/tidb/errno"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/testkit"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDeleteLockKey(t *testing.T) {
@@ -130,3 +132,40 @@ func TestIssue21200(t *testing.T) {
 	tk.MustExec("execute stmt using @a")
 	tk.MustQuery("select * from t").Check(testkit.Rows("2"))
 }
+
+func TestDeleteReturning(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t(id int primary key, v varchar(10), key(v))")
+	tk.MustExec("create table t1(a int)")
+	tk.MustExec("insert into t values(1, 'a'), (2, 'b'), (3, 'c'), (4, 'd'), (5, 'e')")
+
+	tk.MustQuery("delete from t where id = 1 returning *").Check(testkit.Rows("1 a"))
+	rs, err := tk.Exec("delete from t order by id limit 2 returning v, id * 10 as x")
+	require.NoError(t, err)
+	require.Equal(t, "x", rs.Fields()[1].ColumnAsName.O)
+	tk.ResultSetToResult(rs, "").Check(testkit.Rows("b 20", "c 30"))
+	tk.MustQuery("delete from t where id > 10 returning id").Check(testkit.Rows())
+	tk.MustQuery("select * from t").Check(testkit.Rows("4 d", "5 e"))
+
+	// The returned rows are the rows deleted by the last attempt of a pessimistic DML.
+	tk.MustExec("begin pessimistic")
+	tk.MustQuery("delete from t where v >= 'd' returning id").Check(testkit.Rows("4", "5"))
+	tk.MustExec("rollback")
+	tk.MustQuery("select * from t").Check(testkit.Rows("4 d", "5 e"))
+
+	tk.MustExec("set @@tidb_max_chunk_size = 32")
+	tk.MustExec("insert into t1 values (1)")
+	for i := 0; i < 6; i++ {
+		tk.MustExec("insert into t1 select * from t1")
+	}
+	require.Len(t, tk.MustQuery("delete from t1 returning a").Rows(), 64)
+
+	tk.MustGetErrCode("delete t, t1 from t, t1 returning t.id", errno.ErrParse)
+	tk.MustGetErrCode("delete from t returning (select a from t1 where t1.a = t.id)", errno.ErrNotSupportedYet)
+	tk.MustGetErrCode("delete from t returning b", errno.ErrBadField)
+}
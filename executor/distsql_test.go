@@ -20,23 +20,34 @@ import (
 	"fmt"
 	"math/rand"
 	"runtime/pprof"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/store/copr"
 	"github.com/pingcap/tidb/store/mockstore"
+	"github.com/pingcap/tidb/store/mockstore/unistore"
 	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/testkit"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/israce"
 	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/netsim"
 	"github.com/stretchr/testify/require"
 	"github.com/tikv/client-go/v2/testutils"
+	"github.com/tikv/client-go/v2/tikv"
+	"github.com/tikv/client-go/v2/tikvrpc"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // checkGoroutineExists
@@ -422,3 +433,238 @@ func TestPartitionTableIndexJoinIndexLookUp(t *testing.T) {
 		tk.MustQuery("select /*+ TIDB_INLJ(t1, t2) */ t1.* from t t1, t t2 use index(a) where t1.a=t2.b and " + cond).Sort().Check(result)
 	}
 }
+
+func TestSimulatedStoreNetwork(t *testing.T) {
+	var storeIDs, peerIDs []uint64
+	var leaderPeer uint64
+	store, clean := testkit.CreateMockStore(t,
+		mockstore.WithClusterInspector(func(c testutils.Cluster) {
+			storeIDs, peerIDs, _, leaderPeer = mockstore.BootstrapWithMultiStores(c, 2)
+		}),
+		mockstore.WithClientHijacker(netsim.NewClient),
+	)
+	defer clean()
+	leader, follower := storeIDs[0], storeIDs[1]
+	if peerIDs[1] == leaderPeer {
+		leader, follower = follower, leader
+	}
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int primary key, b int)")
+	tk.MustExec("insert into t values (1, 1), (2, 2)")
+
+	rtt := 500 * time.Millisecond
+	sqls := []string{"select * from t where a = 1", "select * from t where a in (1, 2)", "select count(*) from t"}
+	tk.MustExec(fmt.Sprintf("set @@tidb_simulated_store_rtt = '%d=%s'", leader, rtt))
+	for _, sql := range sqls {
+		start := time.Now()
+		tk.MustQuery(sql)
+		require.GreaterOrEqual(t, time.Since(start), rtt, sql)
+	}
+	tk.MustExec(fmt.Sprintf("set @@tidb_simulated_store_rtt = '%d=%s'", follower, rtt))
+	for _, sql := range sqls {
+		start := time.Now()
+		tk.MustQuery(sql)
+		require.Less(t, time.Since(start), rtt, sql)
+	}
+}
+
+type batchCopCountingClient struct {
+	tikv.Client
+	unsupported  atomic.Bool
+	batchCopReqs atomic.Int32
+	copReqs      atomic.Int32
+}
+
+func (c *batchCopCountingClient) SendRequest(ctx context.Context, addr string, req *tikvrpc.Request, timeout time.Duration) (*tikvrpc.Response, error) {
+	switch req.Type {
+	case tikvrpc.CmdBatchCop:
+		c.batchCopReqs.Inc()
+		if c.unsupported.Load() {
+			return nil, status.Error(codes.Unimplemented, "unknown service tikvpb.Tikv")
+		}
+	case tikvrpc.CmdCop:
+		c.copReqs.Inc()
+	}
+	return c.Client.SendRequest(ctx, addr, req, timeout)
+}
+
+func TestTiKVBatchCop(t *testing.T) {
+	var client *batchCopCountingClient
+	store, clean := testkit.CreateMockStore(t,
+		mockstore.WithClusterInspector(func(c testutils.Cluster) {
+			mockstore.BootstrapWithMultiStores(c, 2)
+		}),
+		mockstore.WithClientHijacker(func(c tikv.Client) tikv.Client {
+			client = &batchCopCountingClient{Client: c}
+			return client
+		}),
+	)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int primary key, b int)")
+	for i := 0; i < 10; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t values (%d, %d)", i*100, i))
+	}
+	tk.MustQuery("split table t between (0) and (10000) regions 5").Check(testkit.Rows("4 1"))
+
+	client.batchCopReqs.Store(0)
+	tk.MustQuery("select count(*), sum(b) from t").Check(testkit.Rows("10 45"))
+	require.Zero(t, client.batchCopReqs.Load())
+
+	tk.MustExec("set @@tidb_enable_tikv_batch_cop = 1")
+	client.copReqs.Store(0)
+	tk.MustQuery("select count(*), sum(b) from t").Check(testkit.Rows("10 45"))
+	tk.MustQuery("select * from t where b < 3").Sort().Check(testkit.Rows("0 0", "100 1", "200 2"))
+	require.Positive(t, client.batchCopReqs.Load())
+	require.Zero(t, client.copReqs.Load())
+	// The order is kept by the coprocessor requests.
+	client.batchCopReqs.Store(0)
+	tk.MustQuery("select a from t order by a limit 3").Check(testkit.Rows("0", "100", "200"))
+	require.Zero(t, client.batchCopReqs.Load())
+
+	// The regions are read by the coprocessor requests if the stores don't support the batch cop requests.
+	client.unsupported.Store(true)
+	client.batchCopReqs.Store(0)
+	client.copReqs.Store(0)
+	tk.MustQuery("select count(*), sum(b) from t").Check(testkit.Rows("10 45"))
+	require.Positive(t, client.batchCopReqs.Load())
+	require.Positive(t, client.copReqs.Load())
+	// The stores not supporting the batch cop requests are remembered.
+	client.batchCopReqs.Store(0)
+	tk.MustQuery("select count(*), sum(b) from t").Check(testkit.Rows("10 45"))
+	require.Zero(t, client.batchCopReqs.Load())
+}
+
+type copTaskIDRecordingClient struct {
+	tikv.Client
+	mu      sync.Mutex
+	taskIDs []uint64
+}
+
+func (c *copTaskIDRecordingClient) SendRequest(ctx context.Context, addr string, req *tikvrpc.Request, timeout time.Duration) (*tikvrpc.Response, error) {
+	if req.Type == tikvrpc.CmdCop {
+		c.mu.Lock()
+		c.taskIDs = append(c.taskIDs, req.TaskId)
+		c.mu.Unlock()
+	}
+	return c.Client.SendRequest(ctx, addr, req, timeout)
+}
+
+func (c *copTaskIDRecordingClient) takeTaskIDs() []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	taskIDs := c.taskIDs
+	c.taskIDs = nil
+	return taskIDs
+}
+
+func TestCopTaskTraceID(t *testing.T) {
+	var client *copTaskIDRecordingClient
+	store, clean := testkit.CreateMockStore(t,
+		mockstore.WithClientHijacker(func(c tikv.Client) tikv.Client {
+			client = &copTaskIDRecordingClient{Client: c}
+			return client
+		}),
+	)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int primary key, b int)")
+	tk.MustExec("insert into t values (1, 1), (1000, 2), (2000, 3)")
+	tk.MustQuery("split table t between (0) and (3000) regions 3").Check(testkit.Rows("2 1"))
+
+	client.takeTaskIDs()
+	tk.MustQuery("select sum(b) from t").Check(testkit.Rows("6"))
+	traceID := tk.Session().GetSessionVars().StmtCtx.TaskID
+	taskIDs := client.takeTaskIDs()
+	require.Greater(t, len(taskIDs), 1)
+	for _, taskID := range taskIDs {
+		require.Equal(t, traceID, taskID)
+	}
+
+	// The cop tasks of the statement are numbered from 1.
+	tk.MustExec("set @@tidb_trace_cop_task_seq = 1")
+	tk.MustQuery("select sum(b) from t").Check(testkit.Rows("6"))
+	traceID = tk.Session().GetSessionVars().StmtCtx.TaskID
+	taskIDs = client.takeTaskIDs()
+	require.Greater(t, len(taskIDs), 1)
+	sort.Slice(taskIDs, func(i, j int) bool { return taskIDs[i] < taskIDs[j] })
+	for i, taskID := range taskIDs {
+		require.Equal(t, traceID+uint64(i+1), taskID)
+	}
+}
+
+type cmdTypeRecordingClient struct {
+	tikv.Client
+	mu       sync.Mutex
+	cmdTypes map[tikvrpc.CmdType]int
+}
+
+func (c *cmdTypeRecordingClient) SendRequest(ctx context.Context, addr string, req *tikvrpc.Request, timeout time.Duration) (*tikvrpc.Response, error) {
+	c.mu.Lock()
+	c.cmdTypes[req.Type]++
+	c.mu.Unlock()
+	return c.Client.SendRequest(ctx, addr, req, timeout)
+}
+
+func (c *cmdTypeRecordingClient) takeCmdTypes() map[tikvrpc.CmdType]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cmdTypes := c.cmdTypes
+	c.cmdTypes = make(map[tikvrpc.CmdType]int)
+	return cmdTypes
+}
+
+func TestTiFlashFallbackRangesToTiKV(t *testing.T) {
+	var (
+		cluster        *unistore.Cluster
+		client         *cmdTypeRecordingClient
+		tiflashStoreID uint64
+	)
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t,
+		mockstore.WithClusterInspector(func(c testutils.Cluster) {
+			cluster = c.(*unistore.Cluster)
+			mockstore.BootstrapWithSingleStore(c)
+			tiflashStoreID = c.AllocID()
+			cluster.AddStore(tiflashStoreID, "tiflash0", &metapb.StoreLabel{Key: "engine", Value: "tiflash"})
+		}),
+		mockstore.WithClientHijacker(func(c tikv.Client) tikv.Client {
+			client = &cmdTypeRecordingClient{Client: c, cmdTypes: make(map[tikvrpc.CmdType]int)}
+			return client
+		}),
+		mockstore.WithStoreType(mockstore.EmbedUnistore),
+	)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int primary key, b int)")
+	tk.MustExec("alter table t set tiflash replica 1")
+	tbl, err := dom.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	require.NoError(t, err)
+	require.NoError(t, dom.DDL().UpdateTableReplicaInfo(tk.Session(), tbl.Meta().ID, true))
+	tk.MustExec("insert into t values (1, 1), (1000, 2), (2000, 3)")
+
+	// The rows from 1000 are in a new region, whose TiFlash replica is not synced yet.
+	key := tablecodec.EncodeRowKeyWithHandle(tbl.Meta().ID, kv.IntHandle(1000))
+	region, _ := cluster.GetRegionByKey(codec.EncodeBytes(nil, key))
+	peerID := cluster.AllocID()
+	cluster.Split(region.GetId(), cluster.AllocID(), key, []uint64{peerID}, peerID)
+	cluster.AddPeer(region.GetId(), tiflashStoreID, cluster.AllocID())
+
+	tk.MustExec("set @@tidb_isolation_read_engines = 'tiflash'")
+	tk.MustExec("set @@tidb_allow_mpp = 0")
+	tk.MustExec("set @@tidb_allow_batch_cop = 2")
+	tk.MustExec("set @@tidb_tiflash_fallback_ranges_to_tikv = 1")
+	client.takeCmdTypes()
+	tk.MustQuery("select count(*), max(b) from t").Check(testkit.Rows("3 3"))
+	tk.MustQuery("select a from t where b > 1 order by a").Check(testkit.Rows("1000", "2000"))
+	cmdTypes := client.takeCmdTypes()
+	require.Greater(t, cmdTypes[tikvrpc.CmdBatchCop], 0)
+	require.Greater(t, cmdTypes[tikvrpc.CmdCop], 0)
+}
@@ -53,6 +53,8 @@ var (
 	ErrIllegalPrivilegeLevel         = dbterror.ClassExecutor.NewStd(mysql.ErrIllegalPrivilegeLevel)
 	ErrInvalidSplitRegionRanges      = dbterror.ClassExecutor.NewStd(mysql.ErrInvalidSplitRegionRanges)
 	ErrViewInvalid                   = dbterror.ClassExecutor.NewStd(mysql.ErrViewInvalid)
+	ErrWriteInHistoryRead            = dbterror.ClassExecutor.NewStd(mysql.ErrWriteInHistoryRead)
+	ErrStorageNotTiKV                = dbterror.ClassExecutor.NewStd(mysql.ErrStorageNotTiKV)
 
 	ErrBRIEBackupFailed      = dbterror.ClassExecutor.NewStd(mysql.ErrBRIEBackupFailed)
 	ErrBRIERestoreFailed     = dbterror.ClassExecutor.NewStd(mysql.ErrBRIERestoreFailed)
@@ -64,6 +66,12 @@ var (
 	ErrSetPasswordAuthPlugin = dbterror.ClassExecutor.NewStd(mysql.ErrSetPasswordAuthPlugin)
 	ErrFuncNotEnabled        = dbterror.ClassExecutor.NewStdErr(mysql.ErrNotSupportedYet, parser_mysql.Message("%-.32s is not supported. To enable this experimental feature, set '%-.32s' in the configuration file.", nil))
 
+	ErrForeignServerExists        = dbterror.ClassExecutor.NewStd(mysql.ErrForeignServerExists)
+	ErrForeignServerDoesntExist   = dbterror.ClassExecutor.NewStd(mysql.ErrForeignServerDoesntExist)
+	ErrForeignDataStringInvalid   = dbterror.ClassExecutor.NewStd(mysql.ErrForeignDataStringInvalid)
+	ErrConnectToForeignDataSource = dbterror.ClassExecutor.NewStd(mysql.ErrConnectToForeignDataSource)
+	ErrQueryOnForeignDataSource   = dbterror.ClassExecutor.NewStd(mysql.ErrQueryOnForeignDataSource)
+
 	errUnsupportedFlashbackTmpTable = dbterror.ClassDDL.NewStdErr(mysql.ErrUnsupportedDDLOperation, parser_mysql.Message("Recover/flashback table is not supported on temporary tables", nil))
 	errTruncateWrongInsertValue     = dbterror.ClassTable.NewStdErr(mysql.ErrTruncatedWrongValue, parser_mysql.Message("Incorrect %-.32s value: '%-.128s' for column '%.192s' at row %d", nil))
 )
@@ -1291,6 +1291,7 @@ type SelectionExec struct {
 
 	batched     bool
 	filters     []expression.Expression
+	fused       *expression.FusedFilter
 	selected    []bool
 	inputIter   *chunk.Iterator4Chunk
 	inputRow    chunk.Row
@@ -1366,7 +1367,11 @@ func (e *SelectionExec) Next(ctx context.Context, req *chunk.Chunk) error {
 		if e.childResult.NumRows() == 0 {
 			return nil
 		}
-		e.selected, err = expression.VectorizedFilter(e.ctx, e.filters, e.inputIter, e.selected)
+		if e.fused != nil {
+			e.selected, err = e.fused.Filter(e.ctx, e.childResult, e.selected)
+		} else {
+			e.selected, err = expression.VectorizedFilter(e.ctx, e.filters, e.inputIter, e.selected)
+		}
 		if err != nil {
 			return err
 		}
@@ -1732,7 +1737,10 @@ func ResetContextOfStmt(ctx sessionctx.Context, s ast.StmtNode) (err error) {
 	sc.SysdateIsNow = ctx.GetSessionVars().SysdateIsNow
 
 	sc.InitMemTracker(memory.LabelForSQLText, vars.MemQuotaQuery)
-	sc.InitDiskTracker(memory.LabelForSQLText, -1)
+	sc.InitDiskTracker(memory.LabelForSQLText, vars.TmpStorageQuota)
+	if vars.TmpStorageQuota > 0 {
+		sc.DiskTracker.SetActionOnExceed(&memory.PanicOnStorageExceed{ConnID: vars.ConnectionID})
+	}
 	sc.MemTracker.AttachToGlobalTracker(GlobalMemoryUsageTracker)
 	globalConfig := config.GetGlobalConfig()
 	if globalConfig.OOMUseTmpStorage && GlobalDiskUsageTracker != nil {
@@ -1742,6 +1750,7 @@ func ResetContextOfStmt(ctx sessionctx.Context, s ast.StmtNode) (err error) {
 	case config.OOMActionCancel:
 		action := &memory.PanicOnExceed{ConnID: ctx.GetSessionVars().ConnectionID}
 		action.SetLogHook(domain.GetDomain(ctx).ExpensiveQueryHandle().LogOnQueryExceedMemQuota)
+		action.SetDumpHook(domain.GetDomain(ctx).ExpensiveQueryHandle().DumpOnQueryExceedMemQuota)
 		sc.MemTracker.SetActionOnExceed(action)
 	case config.OOMActionLog:
 		fallthrough
@@ -200,6 +200,23 @@ func TestBuildKvRangesForIndexJoinWithoutCwc(t *testing.T) {
 	}
 }
 
+func TestSortAndMergeKvRanges(t *testing.T) {
+	kvRanges := []kv.KeyRange{
+		{StartKey: kv.Key("e"), EndKey: kv.Key("f")},
+		{StartKey: kv.Key("a"), EndKey: kv.Key("b")},
+		{StartKey: kv.Key("c"), EndKey: kv.Key("d")},
+		{StartKey: kv.Key("b"), EndKey: kv.Key("c")},
+		{StartKey: kv.Key("e"), EndKey: kv.Key("ea")},
+		{StartKey: kv.Key("g"), EndKey: kv.Key("h")},
+	}
+	kvRanges = sortAndMergeKvRanges(kvRanges)
+	require.Equal(t, []kv.KeyRange{
+		{StartKey: kv.Key("a"), EndKey: kv.Key("d")},
+		{StartKey: kv.Key("e"), EndKey: kv.Key("f")},
+		{StartKey: kv.Key("g"), EndKey: kv.Key("h")},
+	}, kvRanges)
+}
+
 func generateIndexRange(vals ...int64) *ranger.Range {
 	lowDatums := generateDatumSlice(vals...)
 	highDatums := make([]types.Datum, len(vals))
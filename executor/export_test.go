@@ -0,0 +1,26 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import "database/sql"
+
+// SetOpenForeignServerForTesting replaces the function to open the foreign servers, the returned function restores it.
+func SetOpenForeignServerForTesting(open func(dsn string) (*sql.DB, error)) func() {
+	origin := openForeignServer
+	openForeignServer = open
+	return func() {
+		openForeignServer = origin
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"io"
+
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/execdetails"
+	"github.com/pingcap/tidb/util/extfile"
+)
+
+// externalTableRetriever streams and decodes the rows of the EXTERNAL table function.
+type externalTableRetriever struct {
+	source *extfile.Source
+	// fileColumns is the number of columns of the file.
+	fileColumns int
+	// columns are the columns read by the query, the offset is the index in the file.
+	columns []*model.ColumnInfo
+	filters []expression.Expression
+
+	reader *extfile.Reader
+	row    chunk.MutRow
+	done   bool
+}
+
+// retrieve implements the memTableRetriever interface
+func (e *externalTableRetriever) retrieve(ctx context.Context, sctx sessionctx.Context) ([][]types.Datum, error) {
+	if e.done {
+		return nil, nil
+	}
+	if e.reader == nil {
+		reader, err := e.source.Open(ctx)
+		if err != nil {
+			return nil, err
+		}
+		reader.SetColumns(e.fileColumns)
+		e.reader = reader
+		fts := make([]*types.FieldType, 0, len(e.columns))
+		for _, col := range e.columns {
+			fts = append(fts, &col.FieldType)
+		}
+		e.row = chunk.MutRowFromTypes(fts)
+	}
+
+	sc := sctx.GetSessionVars().StmtCtx
+	maxCount := sctx.GetSessionVars().MaxChunkSize
+	rows := make([][]types.Datum, 0, maxCount)
+	for len(rows) < maxCount {
+		fileRow, err := e.reader.Next()
+		if err == io.EOF {
+			e.done = true
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Only the read columns are converted.
+		row := make([]types.Datum, len(e.columns))
+		for i, col := range e.columns {
+			if row[i], err = fileRow[col.Offset].ConvertTo(sc, &col.FieldType); err != nil {
+				return nil, err
+			}
+		}
+		if len(e.filters) > 0 {
+			e.row.SetDatums(row...)
+			matched, _, err := expression.EvalBool(sctx, e.filters, e.row.ToRow())
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (e *externalTableRetriever) close() error {
+	// The retriever is reopened when it's the inner side of an apply, so the file is read again.
+	e.done = false
+	if e.reader != nil {
+		reader := e.reader
+		e.reader = nil
+		return reader.Close()
+	}
+	return nil
+}
+
+func (e *externalTableRetriever) getRuntimeStats() execdetails.RuntimeStats {
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/testkit"
+	_ "github.com/pingcap/tidb/util/extfile/parquet"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+func TestExternalTable(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "t.csv")
+	require.NoError(t, os.WriteFile(csvPath, []byte("id,name\n1,a\n2,b\n3,\\N\n"), 0644))
+	tk.MustQuery(fmt.Sprintf("select * from external('%s')", csvPath)).Check(testkit.Rows("1 a", "2 b", "3 <nil>"))
+	tk.MustQuery(fmt.Sprintf("select e.name from external('%s', format => 'csv') as e where e.id > 1", csvPath)).Check(testkit.Rows("b", "<nil>"))
+
+	// The filters are evaluated when the rows are decoded, and only the read columns are converted.
+	rows := tk.MustQuery(fmt.Sprintf("explain format = 'brief' select name from external('%s') where id > 1", csvPath)).Rows()
+	require.Len(t, rows, 2)
+	require.Equal(t, "MemTableScan", fmt.Sprintf("%v", rows[1][0])[len("└─"):])
+	require.Equal(t, fmt.Sprintf("file:%s, format:csv, filters:[gt(cast(Column#1, double BINARY), 1)]", csvPath), rows[1][4])
+
+	tk.MustExec("create table t (id int, v int)")
+	tk.MustExec("insert into t values (1, 10), (2, 20), (4, 40)")
+	tk.MustQuery(fmt.Sprintf("select t.v, e.name from t join external('%s') e on t.id = e.id order by t.v", csvPath)).Check(testkit.Rows("10 a", "20 b"))
+	// The inner side of the apply is reopened for every outer row.
+	tk.MustQuery(fmt.Sprintf("select t.id, (select count(*) from external('%s') e where e.id <= t.id) from t order by t.id", csvPath)).Check(
+		testkit.Rows("1 1", "2 2", "4 3"))
+	tk.MustExec("create table t2 (id int, name varchar(10))")
+	tk.MustExec(fmt.Sprintf("insert into t2 select id, name from external('%s')", csvPath))
+	tk.MustQuery("select * from t2").Sort().Check(testkit.Rows("1 a", "2 b", "3 <nil>"))
+
+	// The columns are named c1, c2, ... if the file has no header, short rows are padded with NULL.
+	noHeaderPath := filepath.Join(dir, "no_header.csv")
+	require.NoError(t, os.WriteFile(noHeaderPath, []byte("1|x|p\n2|y\n"), 0644))
+	tk.MustQuery(fmt.Sprintf("select c1, c3 from external('%s', header => 'false', separator => '|')", noHeaderPath)).Check(testkit.Rows("1 p", "2 <nil>"))
+
+	type row struct {
+		ID   int64  `parquet:"name=id, type=INT64"`
+		Name string `parquet:"name=name, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	}
+	parquetPath := filepath.Join(dir, "t.parquet")
+	pf, err := local.NewLocalFileWriter(parquetPath)
+	require.NoError(t, err)
+	pw, err := writer.NewParquetWriter(pf, new(row), 1)
+	require.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, pw.Write(&row{ID: int64(i), Name: fmt.Sprintf("n%d", i)}))
+	}
+	require.NoError(t, pw.WriteStop())
+	require.NoError(t, pf.Close())
+	tk.MustQuery(fmt.Sprintf("select id + 1, name from external('%s') where id >= 2", parquetPath)).Check(testkit.Rows("3 n2", "4 n3"))
+	tk.MustQuery(fmt.Sprintf("select count(*), sum(id) from external('%s', format => 'PARQUET')", parquetPath)).Check(testkit.Rows("3 6"))
+
+	tk.MustGetErrCode(fmt.Sprintf("select * from external('%s', format => 'json')", csvPath), errno.ErrWrongArguments)
+	tk.MustGetErrCode(fmt.Sprintf("select * from external('%s', compression => 'gzip')", csvPath), errno.ErrWrongArguments)
+	tk.MustGetErrCode(fmt.Sprintf("select * from external('%s', header => 'maybe')", csvPath), errno.ErrWrongArguments)
+	_, err = tk.Exec(fmt.Sprintf("select * from external('%s')", filepath.Join(dir, "missing.csv")))
+	require.Error(t, err)
+
+	// Reading external files requires the FILE privilege.
+	tk.MustExec("create user 'external_user'@'%'")
+	tk.MustExec("grant select on test.* to 'external_user'@'%'")
+	tk1 := testkit.NewTestKit(t, store)
+	require.True(t, tk1.Session().Auth(&auth.UserIdentity{Username: "external_user", Hostname: "%"}, nil, nil))
+	tk1.MustGetErrCode(fmt.Sprintf("select * from external('%s')", csvPath), errno.ErrSpecificAccessDenied)
+	// The privilege is checked before the file is read to infer the columns.
+	tk1.MustGetErrCode(fmt.Sprintf("select * from external('%s')", filepath.Join(dir, "missing.csv")), errno.ErrSpecificAccessDenied)
+	tk1.MustGetErrCode(fmt.Sprintf("explain select * from external('%s')", csvPath), errno.ErrSpecificAccessDenied)
+	tk.MustExec("grant file on *.* to 'external_user'@'%'")
+	tk1.MustQuery(fmt.Sprintf("select count(*) from external('%s')", csvPath)).Check(testkit.Rows("3"))
+}
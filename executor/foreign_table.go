@@ -0,0 +1,267 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
+	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/encrypt"
+	"github.com/pingcap/tidb/util/execdetails"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+const foreignServerConnectTimeout = 10 * time.Second
+
+// openForeignServer opens the connection pool of a foreign server, it's replaced in tests.
+var openForeignServer = func(dsn string) (*sql.DB, error) {
+	return sql.Open("mysql", dsn)
+}
+
+// foreignTableRetriever streams the rows of a foreign table from its foreign server.
+type foreignTableRetriever struct {
+	dbName    model.CIStr
+	table     *model.TableInfo
+	columns   []*model.ColumnInfo
+	extractor *plannercore.ForeignTableExtractor
+
+	db   *sql.DB
+	rows *sql.Rows
+	done bool
+}
+
+// retrieve implements the memTableRetriever interface
+func (e *foreignTableRetriever) retrieve(ctx context.Context, sctx sessionctx.Context) ([][]types.Datum, error) {
+	if e.done {
+		return nil, nil
+	}
+	if e.rows == nil {
+		if err := e.open(ctx, sctx); err != nil {
+			return nil, err
+		}
+	}
+
+	sc := sctx.GetSessionVars().StmtCtx
+	raw := make([]sql.RawBytes, len(e.columns))
+	dest := make([]interface{}, len(e.columns))
+	for i := range raw {
+		dest[i] = &raw[i]
+	}
+	maxCount := sctx.GetSessionVars().MaxChunkSize
+	rows := make([][]types.Datum, 0, maxCount)
+	for len(rows) < maxCount {
+		if !e.rows.Next() {
+			e.done = true
+			if err := e.rows.Err(); err != nil {
+				return nil, ErrQueryOnForeignDataSource.GenWithStackByArgs(err.Error())
+			}
+			break
+		}
+		if err := e.rows.Scan(dest...); err != nil {
+			return nil, ErrQueryOnForeignDataSource.GenWithStackByArgs(err.Error())
+		}
+		row := make([]types.Datum, len(e.columns))
+		for i, col := range e.columns {
+			if raw[i] == nil {
+				row[i].SetNull()
+				continue
+			}
+			d := types.NewStringDatum(string(raw[i]))
+			converted, err := d.ConvertTo(sc, &col.FieldType)
+			if err != nil {
+				return nil, err
+			}
+			row[i] = converted
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// open connects to the foreign server and sends the query of the foreign table.
+func (e *foreignTableRetriever) open(ctx context.Context, sctx sessionctx.Context) error {
+	remoteDB, remoteTable := e.table.Foreign.RemoteDB, e.table.Foreign.RemoteTable
+	if e.db == nil {
+		server := e.table.Foreign.Server
+		exec := sctx.(sqlexec.RestrictedSQLExecutor)
+		rows, _, err := exec.ExecRestrictedSQL(ctx, nil, `SELECT Host, Port, Socket, Username, Password, Db FROM %n.%n WHERE Server_name=%?;`,
+			mysql.SystemDB, mysql.ServersTable, server.L)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return ErrForeignServerDoesntExist.GenWithStackByArgs(server.O)
+		}
+		row := rows[0]
+		cfg := mysqldriver.NewConfig()
+		cfg.User = row.GetString(3)
+		if cfg.Passwd, err = decryptForeignServerPassword(row.GetString(4)); err != nil {
+			return ErrConnectToForeignDataSource.GenWithStackByArgs(fmt.Sprintf("can't decrypt the password of %s, recreate the server: %v", server.O, err))
+		}
+		cfg.Timeout = foreignServerConnectTimeout
+		if socket := row.GetString(2); socket != "" {
+			cfg.Net, cfg.Addr = "unix", socket
+		} else {
+			port := row.GetInt64(1)
+			if port == 0 {
+				port = 3306
+			}
+			cfg.Net, cfg.Addr = "tcp", net.JoinHostPort(row.GetString(0), strconv.FormatInt(port, 10))
+		}
+		if remoteDB == "" {
+			remoteDB = row.GetString(5)
+		}
+		if e.db, err = openForeignServer(cfg.FormatDSN()); err != nil {
+			return ErrConnectToForeignDataSource.GenWithStackByArgs(err.Error())
+		}
+	}
+	if remoteDB == "" {
+		remoteDB = e.dbName.O
+	}
+	if remoteTable == "" {
+		remoteTable = e.table.Name.O
+	}
+
+	rows, err := e.db.QueryContext(ctx, e.buildQuery(remoteDB, remoteTable))
+	if err != nil {
+		if _, ok := err.(*mysqldriver.MySQLError); ok {
+			return ErrQueryOnForeignDataSource.GenWithStackByArgs(err.Error())
+		}
+		return ErrConnectToForeignDataSource.GenWithStackByArgs(err.Error())
+	}
+	e.rows = rows
+	return nil
+}
+
+// buildQuery returns the query sent to the foreign server.
+func (e *foreignTableRetriever) buildQuery(remoteDB, remoteTable string) string {
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	for i, col := range e.columns {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(quoteForeignName(col.Name.O))
+	}
+	sb.WriteString(" FROM ")
+	sb.WriteString(quoteForeignName(remoteDB))
+	sb.WriteString(".")
+	sb.WriteString(quoteForeignName(remoteTable))
+	if e.extractor != nil && len(e.extractor.Conditions) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(e.extractor.Conditions, " AND "))
+	}
+	return sb.String()
+}
+
+func quoteForeignName(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (e *foreignTableRetriever) close() error {
+	// The retriever is reopened when it's the inner side of an apply, so the query is sent again.
+	var err error
+	if e.rows != nil {
+		err = e.rows.Close()
+	}
+	if e.db != nil {
+		if closeErr := e.db.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	e.db, e.rows, e.done = nil, nil, false
+	return err
+}
+
+func (e *foreignTableRetriever) getRuntimeStats() execdetails.RuntimeStats {
+	return nil
+}
+
+// foreignServerKey returns the key to encrypt the passwords of the foreign servers, which is the SHA-256 of the
+// content of the file security.foreign-server-key-path.
+func foreignServerKey() ([]byte, error) {
+	path := config.GetGlobalConfig().Security.ForeignServerKeyPath
+	if path == "" {
+		return nil, ErrFuncNotEnabled.GenWithStackByArgs("Foreign server passwords", "security.foreign-server-key-path")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	key := sha256.Sum256(bytes.TrimSpace(content))
+	return key[:], nil
+}
+
+// encryptForeignServerPassword encrypts the password of a foreign server with AES-256-CBC, the result is the hex
+// of the random IV followed by the cipher text.
+func encryptForeignServerPassword(password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+	key, err := foreignServerKey()
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return "", errors.Trace(err)
+	}
+	crypted, err := encrypt.AESEncryptWithCBC([]byte(password), key, iv)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(append(iv, crypted...)), nil
+}
+
+func decryptForeignServerPassword(stored string) (string, error) {
+	if stored == "" {
+		return "", nil
+	}
+	key, err := foreignServerKey()
+	if err != nil {
+		return "", err
+	}
+	data, err := hex.DecodeString(stored)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if len(data) <= aes.BlockSize {
+		return "", errors.New("the encrypted password is too short")
+	}
+	password, err := encrypt.AESDecryptWithCBC(data[aes.BlockSize:], key, data[:aes.BlockSize])
+	if err != nil {
+		return "", err
+	}
+	return string(password), nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
+	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForeignTableRetriever(t *testing.T) {
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	columns := []*model.ColumnInfo{
+		{Name: model.NewCIStr("a"), FieldType: *types.NewFieldType(mysql.TypeLonglong)},
+		{Name: model.NewCIStr("b"), FieldType: *types.NewFieldType(mysql.TypeVarchar)},
+	}
+	e := &foreignTableRetriever{
+		dbName: model.NewCIStr("test"),
+		table: &model.TableInfo{
+			Name:    model.NewCIStr("t"),
+			Columns: columns,
+			Foreign: &model.ForeignTableInfo{Server: model.NewCIStr("s"), RemoteDB: "remote"},
+		},
+		columns:   columns,
+		extractor: &plannercore.ForeignTableExtractor{Conditions: []string{"`a` > 1", "`a` IS NULL"}},
+		db:        db,
+	}
+	dbMock.ExpectQuery(regexp.QuoteMeta("SELECT `a`, `b` FROM `remote`.`t` WHERE `a` > 1 AND `a` IS NULL")).
+		WillReturnRows(sqlmock.NewRows([]string{"a", "b"}).AddRow(2, "x").AddRow(3, nil).AddRow(4, "z"))
+	dbMock.ExpectClose()
+
+	sctx := mock.NewContext()
+	sctx.GetSessionVars().MaxChunkSize = 2
+	var rows [][]types.Datum
+	for batches := 0; ; batches++ {
+		batch, err := e.retrieve(context.Background(), sctx)
+		require.NoError(t, err)
+		if len(batch) == 0 {
+			require.Equal(t, 2, batches)
+			break
+		}
+		require.LessOrEqual(t, len(batch), 2)
+		rows = append(rows, batch...)
+	}
+	require.Len(t, rows, 3)
+	require.Equal(t, int64(2), rows[0][0].GetInt64())
+	require.Equal(t, "x", rows[0][1].GetString())
+	require.Equal(t, int64(3), rows[1][0].GetInt64())
+	require.True(t, rows[1][1].IsNull())
+	require.Equal(t, int64(4), rows[2][0].GetInt64())
+
+	require.NoError(t, e.close())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/parser/terror"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForeignTable(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+
+	// The passwords can't be stored without the key.
+	tk.MustGetErrCode("create server s1 foreign data wrapper mysql options (host '127.0.0.1', user 'root', password 'pwd')", errno.ErrNotSupportedYet)
+	keyPath := filepath.Join(t.TempDir(), "foreign_server.key")
+	require.NoError(t, os.WriteFile(keyPath, []byte("secret\n"), 0600))
+	defer config.RestoreFunc()()
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.Security.ForeignServerKeyPath = keyPath
+	})
+
+	// Nothing listens on port 1, so reading the foreign table fails to connect.
+	tk.MustExec("create server s1 foreign data wrapper mysql options (host '127.0.0.1', port 1, user 'root', password 'pwd', database 'remote')")
+	tk.MustGetErrCode("create server S1 foreign data wrapper mysql options (host '127.0.0.1')", errno.ErrForeignServerExists)
+	tk.MustExec("create server if not exists s1 foreign data wrapper mysql options (host '127.0.0.1')")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Note 1476 The foreign server, s1, you are trying to create already exists."))
+	tk.MustGetErrCode("create server s2 foreign data wrapper postgresql options (host '127.0.0.1')", errno.ErrForeignDataStringInvalid)
+	tk.MustGetErrCode("create server s2 foreign data wrapper mysql options (sock '/tmp/mysql.sock')", errno.ErrForeignDataStringInvalid)
+	// The password is encrypted with a random IV.
+	tk.MustQuery("select server_name, host, port, db, username, password <> 'pwd', length(password), wrapper from mysql.servers").
+		Check(testkit.Rows("s1 127.0.0.1 1 remote root 1 64 mysql"))
+
+	tk.MustExec("create foreign table ft (a int, b varchar(10)) server s1 options (table 'remote_t')")
+	tk.MustQuery("show create table ft").Check(testkit.Rows("ft CREATE FOREIGN TABLE `ft` (\n" +
+		"  `a` int(11) DEFAULT NULL,\n" +
+		"  `b` varchar(10) DEFAULT NULL\n" +
+		") SERVER `s1` OPTIONS (TABLE 'remote_t')"))
+	tk.MustGetErrCode("create foreign table ft2 (a int primary key) server s1", errno.ErrUnsupportedDDLOperation)
+	tk.MustGetErrCode("create foreign table ft2 (a int, index idx(a)) server s1", errno.ErrUnsupportedDDLOperation)
+	tk.MustGetErrCode("create foreign table ft2 (a int) server s1 options (host 'localhost')", errno.ErrUnsupportedDDLOperation)
+
+	// Foreign tables are read-only.
+	tk.MustGetErrCode("insert into ft values (1, 'a')", errno.ErrNonUpdatableTable)
+	tk.MustGetErrCode("replace into ft values (1, 'a')", errno.ErrNonUpdatableTable)
+	tk.MustGetErrCode("update ft set a = 1", errno.ErrNonUpdatableTable)
+	tk.MustGetErrCode("delete from ft", errno.ErrNonUpdatableTable)
+	tk.MustGetErrCode("truncate table ft", errno.ErrUnsupportedDDLOperation)
+	tk.MustGetErrCode("alter table ft add column c int", errno.ErrUnsupportedDDLOperation)
+	tk.MustGetErrCode("create index idx on ft (a)", errno.ErrUnsupportedDDLOperation)
+
+	// Only the simple filters on integer and decimal columns are pushed to the foreign server,
+	// all filters are still evaluated by TiDB.
+	tk.MustQuery("explain format = 'brief' select a from ft where a > 1 and 10 >= a and a in (3, 4) and b = 'x'").Check(testkit.Rows(
+		"Projection 8000.00 root  Column#1",
+		"└─Selection 8000.00 root  eq(Column#2, \"x\"), ge(10, Column#1), gt(Column#1, 1), in(Column#1, 3, 4)",
+		"  └─MemTableScan 10000.00 root table:ft remote_filter:[`a` > 1, `a` <= 10, `a` IN (3, 4)]",
+	))
+
+	err := tk.QueryToErr("select * from ft")
+	require.True(t, terror.ErrorEqual(err, executor.ErrConnectToForeignDataSource), "%v", err)
+
+	// The inner side of the apply is reopened for every outer row, and the query is sent again.
+	opened := 0
+	defer executor.SetOpenForeignServerForTesting(func(dsn string) (*sql.DB, error) {
+		require.Contains(t, dsn, "root:pwd@tcp(127.0.0.1:1)/")
+		db, dbMock, err := sqlmock.New()
+		require.NoError(t, err)
+		dbMock.ExpectQuery(regexp.QuoteMeta("SELECT `a` FROM `remote`.`remote_t`")).
+			WillReturnRows(sqlmock.NewRows([]string{"a"}).AddRow(1).AddRow(2).AddRow(3))
+		opened++
+		return db, nil
+	})()
+	tk.MustExec("create table t (a int)")
+	tk.MustExec("insert into t values (1), (3)")
+	tk.MustQuery("select t.a, (select count(*) from ft where ft.a <= t.a) from t order by t.a").Check(testkit.Rows("1 1", "3 3"))
+	require.Equal(t, 2, opened)
+
+	tk.MustExec("drop server s1")
+	tk.MustGetErrCode("drop server s1", errno.ErrForeignServerDoesntExist)
+	tk.MustExec("drop server if exists s1")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Note 1477 The foreign server name you are trying to reference does not exist. Data source :  s1"))
+	err = tk.QueryToErr("select * from ft")
+	require.True(t, terror.ErrorEqual(err, executor.ErrForeignServerDoesntExist), "%v", err)
+	tk.MustExec("drop table ft")
+}
@@ -83,6 +83,9 @@ type hashRowContainer struct {
 	hashTable baseHashTable
 
 	rowContainer *chunk.RowContainer
+	// nullKeyRows stores the RowPtrs of the rows whose join keys contain NULL if it's not nil,
+	// they are not put into the hash table but are needed by the null-aware anti semi join.
+	nullKeyRows *[]chunk.RowPtr
 }
 
 func newHashRowContainer(sCtx sessionctx.Context, estCount int, hCtx *hashContext, allTypes []*types.FieldType) *hashRowContainer {
@@ -179,11 +182,17 @@ func (c *hashRowContainer) PutChunkSelected(chk *chunk.Chunk, selected, ignoreNu
 		}
 	}
 	for i := 0; i < numRows; i++ {
-		if (selected != nil && !selected[i]) || c.hCtx.hasNull[i] {
+		if selected != nil && !selected[i] {
 			continue
 		}
-		key := c.hCtx.hashVals[i].Sum64()
 		rowPtr := chunk.RowPtr{ChkIdx: chkIdx, RowIdx: uint32(i)}
+		if c.hCtx.hasNull[i] {
+			if c.nullKeyRows != nil {
+				*c.nullKeyRows = append(*c.nullKeyRows, rowPtr)
+			}
+			continue
+		}
+		key := c.hCtx.hashVals[i].Sum64()
 		c.hashTable.Put(key, rowPtr)
 	}
 	return nil
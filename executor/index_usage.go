@@ -0,0 +1,174 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/domain/infosync"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/statistics/handle"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/pingcap/tidb/util/tableworkload"
+)
+
+const (
+	redundantReasonNeverUsed = "never used"
+	redundantReasonPrefix    = "prefix of another index"
+	// redundantReasonNotUsedSince replaces redundantReasonNeverUsed if the index usages are not synced to
+	// mysql.schema_index_usage, since the indexes may be used by the other instances or before the restart.
+	redundantReasonNotUsedSince = "not used on this instance since %s"
+)
+
+// indexUsages combines the index reads recorded by the instance since it starts with the last used time
+// persisted in mysql.schema_index_usage, which survives the restarts.
+type indexUsages struct {
+	workloads map[int64]tableworkload.TableWorkload
+	persisted map[handle.GlobalIndexID]time.Time
+	// notUsedReason is the reason of the redundant indexes not used at all.
+	notUsedReason string
+}
+
+func loadIndexUsages(ctx context.Context, sctx sessionctx.Context) (*indexUsages, error) {
+	exec := sctx.(sqlexec.RestrictedSQLExecutor)
+	rows, _, err := exec.ExecRestrictedSQL(ctx, nil, "select table_id, index_id, last_used_at from mysql.schema_index_usage")
+	if err != nil {
+		return nil, err
+	}
+	u := &indexUsages{
+		workloads:     make(map[int64]tableworkload.TableWorkload),
+		persisted:     make(map[handle.GlobalIndexID]time.Time, len(rows)),
+		notUsedReason: redundantReasonNeverUsed,
+	}
+	if domain.GetDomain(sctx).IndexUsageSyncLease() == 0 {
+		info, err := infosync.GetServerInfo()
+		if err != nil {
+			return nil, err
+		}
+		startTime := time.Unix(info.StartTimestamp, 0).In(sctx.GetSessionVars().Location())
+		u.notUsedReason = fmt.Sprintf(redundantReasonNotUsedSince, startTime.Format(types.TimeFormat))
+	}
+	for _, row := range rows {
+		if row.IsNull(2) {
+			continue
+		}
+		lastUsedAt, err := row.GetTime(2).GoTime(time.Local)
+		if err != nil {
+			return nil, err
+		}
+		u.persisted[handle.GlobalIndexID{TableID: row.GetInt64(0), IndexID: row.GetInt64(1)}] = lastUsedAt
+	}
+	for _, w := range tableworkload.Snapshot() {
+		u.workloads[w.TableID] = w
+	}
+	return u, nil
+}
+
+// get returns how many times the index is read since the instance starts, summed over the partitions,
+// and when it's used last time, which is zero if it's never used.
+func (u *indexUsages) get(tbl *model.TableInfo, idx *model.IndexInfo) (queryCount int64, lastUsedAt time.Time) {
+	physicalIDs := []int64{tbl.ID}
+	if pi := tbl.GetPartitionInfo(); pi != nil {
+		for _, def := range pi.Definitions {
+			physicalIDs = append(physicalIDs, def.ID)
+		}
+	}
+	for _, id := range physicalIDs {
+		w, ok := u.workloads[id]
+		if !ok {
+			continue
+		}
+		queryCount += w.IndexReads[idx.Name.O]
+		if t := w.IndexLastReadTime[idx.Name.O]; t.After(lastUsedAt) {
+			lastUsedAt = t
+		}
+	}
+	if t := u.persisted[handle.GlobalIndexID{TableID: tbl.ID, IndexID: idx.ID}]; t.After(lastUsedAt) {
+		lastUsedAt = t
+	}
+	return queryCount, lastUsedAt
+}
+
+// redundantIndex is an index reported by ADMIN SHOW REDUNDANT INDEXES.
+type redundantIndex struct {
+	index  *model.IndexInfo
+	reason string
+	// covering is the index making the redundant one useless, it's nil if the index is never used.
+	covering   *model.IndexInfo
+	lastUsedAt time.Time
+}
+
+// findRedundantIndexes returns the non-unique indexes of the table which are never used or the prefix of
+// another index. The unique indexes and the primary key are never reported as they are constraints. If the
+// index usages are not synced, the unused indexes are reported as not used since the instance starts.
+func findRedundantIndexes(tbl *model.TableInfo, usages *indexUsages) []redundantIndex {
+	var result []redundantIndex
+	for _, idx := range tbl.Indices {
+		if idx.State != model.StatePublic || idx.Unique || idx.Primary {
+			continue
+		}
+		_, lastUsedAt := usages.get(tbl, idx)
+		if covering := findCoveringIndex(tbl, idx); covering != nil {
+			result = append(result, redundantIndex{index: idx, reason: redundantReasonPrefix, covering: covering, lastUsedAt: lastUsedAt})
+		} else if lastUsedAt.IsZero() {
+			result = append(result, redundantIndex{index: idx, reason: usages.notUsedReason})
+		}
+	}
+	return result
+}
+
+// findCoveringIndex returns a visible index which can serve all the queries using the index. Of two indexes
+// with the same columns, the later created one is redundant unless the earlier one is unique.
+func findCoveringIndex(tbl *model.TableInfo, idx *model.IndexInfo) *model.IndexInfo {
+	for _, other := range tbl.Indices {
+		if other.ID == idx.ID || other.State != model.StatePublic || other.Invisible {
+			continue
+		}
+		isPrefix, isSame := isIndexPrefix(idx, other)
+		if !isPrefix {
+			continue
+		}
+		if !isSame || other.Unique || other.Primary || other.ID < idx.ID {
+			return other
+		}
+	}
+	return nil
+}
+
+// isIndexPrefix checks whether the columns of idx are the leading columns of other, and every column of
+// other indexes no shorter prefix than the one of idx. isSame is true if both indexes have the same columns
+// and prefix lengths.
+func isIndexPrefix(idx, other *model.IndexInfo) (isPrefix, isSame bool) {
+	if len(idx.Columns) > len(other.Columns) {
+		return false, false
+	}
+	isSame = len(idx.Columns) == len(other.Columns)
+	for i, col := range idx.Columns {
+		otherCol := other.Columns[i]
+		if col.Name.L != otherCol.Name.L {
+			return false, false
+		}
+		if otherCol.Length != types.UnspecifiedLength && (col.Length == types.UnspecifiedLength || col.Length > otherCol.Length) {
+			return false, false
+		}
+		isSame = isSame && col.Length == otherCol.Length
+	}
+	return true, isSame
+}
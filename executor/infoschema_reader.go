@@ -28,18 +28,21 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unsafe"
 
 	"github.com/cznic/mathutil"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/deadlock"
 	"github.com/pingcap/tidb/ddl/label"
+	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/domain/infosync"
 	"github.com/pingcap/tidb/errno"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta/autoid"
+	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/charset"
 	"github.com/pingcap/tidb/parser/model"
@@ -58,6 +61,7 @@ import (
 	"github.com/pingcap/tidb/types"
 	binaryJson "github.com/pingcap/tidb/types/json"
 	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/admin"
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/deadlockhistory"
@@ -70,6 +74,8 @@ import (
 	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/pingcap/tidb/util/stmtsummary"
 	"github.com/pingcap/tidb/util/stringutil"
+	"github.com/pingcap/tidb/util/tableworkload"
+	"github.com/tikv/client-go/v2/oracle"
 	"go.uber.org/zap"
 )
 
@@ -77,12 +83,79 @@ type memtableRetriever struct {
 	dummyCloser
 	table       *model.TableInfo
 	columns     []*model.ColumnInfo
+	extractor   plannercore.MemTablePredicateExtractor
 	rows        [][]types.Datum
 	rowIdx      int
 	retrieved   bool
 	initialized bool
 }
 
+// infoSchemaNameFilter filters the schemas and the tables by the names extracted from the predicates, so the rows
+// of the tables that can't be selected are not built. The names are compared in lower case, a nil filter matches
+// all the names.
+type infoSchemaNameFilter struct {
+	schemas        set.StringSet
+	tables         set.StringSet
+	schemaPrefixes []string
+	tablePrefixes  []string
+	schemaRegexps  []*regexp.Regexp
+	tableRegexps   []*regexp.Regexp
+}
+
+func newTablesNameFilter(extractor *plannercore.TablesTableExtractor) *infoSchemaNameFilter {
+	return &infoSchemaNameFilter{
+		schemas:        extractor.TableSchema,
+		tables:         extractor.TableName,
+		schemaPrefixes: extractor.TableSchemaPrefixes,
+		tablePrefixes:  extractor.TableNamePrefixes,
+	}
+}
+
+func newColumnsNameFilter(extractor *plannercore.ColumnsTableExtractor) *infoSchemaNameFilter {
+	f := &infoSchemaNameFilter{
+		schemas: extractor.TableSchema,
+		tables:  extractor.TableName,
+	}
+	for _, pattern := range extractor.TableSchemaPatterns {
+		f.schemaRegexps = append(f.schemaRegexps, regexp.MustCompile(pattern))
+	}
+	for _, pattern := range extractor.TableNamePatterns {
+		f.tableRegexps = append(f.tableRegexps, regexp.MustCompile(pattern))
+	}
+	return f
+}
+
+func (f *infoSchemaNameFilter) matchSchema(name model.CIStr) bool {
+	if f == nil {
+		return true
+	}
+	return matchInfoSchemaName(name.L, f.schemas, f.schemaPrefixes, f.schemaRegexps)
+}
+
+func (f *infoSchemaNameFilter) matchTable(name model.CIStr) bool {
+	if f == nil {
+		return true
+	}
+	return matchInfoSchemaName(name.L, f.tables, f.tablePrefixes, f.tableRegexps)
+}
+
+func matchInfoSchemaName(name string, names set.StringSet, prefixes []string, regexps []*regexp.Regexp) bool {
+	if names.Count() > 0 && !names.Exist(name) {
+		return false
+	}
+	for _, prefix := range prefixes {
+		if !strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	for _, re := range regexps {
+		if !re.MatchString(name) {
+			return false
+		}
+	}
+	return true
+}
+
 // retrieve implements the infoschemaRetriever interface
 func (e *memtableRetriever) retrieve(ctx context.Context, sctx sessionctx.Context) ([][]types.Datum, error) {
 	if e.table.Name.O == infoschema.TableClusterInfo && !hasPriv(sctx, mysql.ProcessPriv) {
@@ -92,6 +165,19 @@ func (e *memtableRetriever) retrieve(ctx context.Context, sctx sessionctx.Contex
 		return nil, nil
 	}
 
+	// The tests simulate the states of the cluster with the mocked rows.
+	if !e.initialized {
+		if rows, ok := infoschema.GetMemTableMockRows(e.table.Name.O); ok {
+			e.rows = make([][]types.Datum, 0, len(rows))
+			for _, row := range rows {
+				fullRow := make([]types.Datum, len(e.table.Columns))
+				copy(fullRow, row)
+				e.rows = append(e.rows, fullRow)
+			}
+			e.initialized = true
+		}
+	}
+
 	// Cache the ret full rows in schemataRetriever
 	if !e.initialized {
 		is := sctx.GetInfoSchema().(infoschema.InfoSchema)
@@ -156,6 +242,9 @@ func (e *memtableRetriever) retrieve(ctx context.Context, sctx sessionctx.Contex
 		case infoschema.TableStatementsSummaryEvicted,
 			infoschema.ClusterTableStatementsSummaryEvicted:
 			err = e.setDataForStatementsSummaryEvicted(sctx)
+		case infoschema.TablePlanHistory,
+			infoschema.ClusterTablePlanHistory:
+			err = e.setDataForPlanHistory(sctx)
 		case infoschema.TableClientErrorsSummaryGlobal,
 			infoschema.TableClientErrorsSummaryByUser,
 			infoschema.TableClientErrorsSummaryByHost:
@@ -164,6 +253,16 @@ func (e *memtableRetriever) retrieve(ctx context.Context, sctx sessionctx.Contex
 			err = e.setDataForAttributes(sctx)
 		case infoschema.TablePlacementPolicies:
 			err = e.setDataFromPlacementPolicies(sctx)
+		case infoschema.TablePlanCache, infoschema.ClusterTablePlanCache:
+			err = e.setDataForPlanCache(sctx)
+		case infoschema.TableBindingCache, infoschema.ClusterTableBindingCache:
+			err = e.setDataForBindingCache(sctx)
+		case infoschema.TableTableWorkload, infoschema.ClusterTableTableWorkload:
+			err = e.setDataForTableWorkload(sctx)
+		case infoschema.TableIndexUsage, infoschema.ClusterTableIndexUsage:
+			err = e.setDataForIndexUsage(ctx, sctx, dbs)
+		case infoschema.TableDDLBlockers, infoschema.ClusterTableDDLBlockers:
+			err = e.setDataForDDLBlockers(sctx)
 		}
 		if err != nil {
 			return nil, err
@@ -301,6 +400,144 @@ func (c *statsCache) get(ctx context.Context, sctx sessionctx.Context) (map[int6
 	return tableRows, colLength, nil
 }
 
+// TableRegionStatsCacheExpiry is the expiry time for the approximate sizes of the tables loaded from PD.
+var TableRegionStatsCacheExpiry = 30 * time.Second
+
+// tableRegionStat is the approximate size of a physical table reported by PD.
+type tableRegionStat struct {
+	recordKeys  uint64
+	dataLength  uint64
+	indexLength uint64
+	loadTime    time.Time
+}
+
+// regionStatsCache caches the approximate sizes of the physical tables, so PD is not requested for every table
+// whenever information_schema.TABLES is read.
+type regionStatsCache struct {
+	mu        sync.Mutex
+	tables    map[int64]tableRegionStat
+	sweepTime time.Time
+}
+
+var tableRegionStatsCache = &regionStatsCache{}
+
+func (c *regionStatsCache) get(h *helper.Helper, physicalID int64) (tableRegionStat, error) {
+	now := time.Now()
+	c.mu.Lock()
+	stat, ok := c.tables[physicalID]
+	c.mu.Unlock()
+	if ok && now.Sub(stat.loadTime) < TableRegionStatsCacheExpiry {
+		return stat, nil
+	}
+	stat, err := loadTableRegionStat(h, physicalID)
+	if err != nil {
+		return stat, err
+	}
+	stat.loadTime = now
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tables == nil {
+		c.tables = make(map[int64]tableRegionStat)
+	}
+	// Drop the expired tables once in a while, so the dropped tables are not kept.
+	if now.Sub(c.sweepTime) >= TableRegionStatsCacheExpiry {
+		for id, s := range c.tables {
+			if now.Sub(s.loadTime) >= TableRegionStatsCacheExpiry {
+				delete(c.tables, id)
+			}
+		}
+		c.sweepTime = now
+	}
+	c.tables[physicalID] = stat
+	return stat, nil
+}
+
+// loadTableRegionStat loads the approximate size of the records and indexes of the physical table from PD. The
+// sizes are of the whole regions overlapping the table, so a small table sharing its region with the others is
+// reported as large as the region.
+func loadTableRegionStat(h *helper.Helper, physicalID int64) (tableRegionStat, error) {
+	failpoint.Inject("mockTableRegionStats", func(val failpoint.Value) {
+		// The records take 2/3 of the table.
+		size := int64(val.(int))
+		failpoint.Return(newTableRegionStat(&helper.PDRegionStats{StorageSize: size * 2, StorageKeys: size * 200},
+			&helper.PDRegionStats{StorageSize: size * 3, StorageKeys: size * 300}), nil)
+	})
+	var records, all helper.PDRegionStats
+	if err := h.GetPDRegionStats(physicalID, &records, true); err != nil {
+		return tableRegionStat{}, err
+	}
+	if err := h.GetPDRegionStats(physicalID, &all, false); err != nil {
+		return tableRegionStat{}, err
+	}
+	return newTableRegionStat(&records, &all), nil
+}
+
+// newTableRegionStat builds the stat of a table from the stats of the regions of its records and the whole table.
+func newTableRegionStat(records, all *helper.PDRegionStats) tableRegionStat {
+	stat := tableRegionStat{}
+	if records.StorageKeys > 0 {
+		stat.recordKeys = uint64(records.StorageKeys)
+	}
+	// The storage sizes reported by PD are in MiB.
+	if records.StorageSize > 0 {
+		stat.dataLength = uint64(records.StorageSize) << 20
+	}
+	if all.StorageSize > records.StorageSize {
+		stat.indexLength = uint64(all.StorageSize-records.StorageSize) << 20
+	}
+	return stat
+}
+
+// tableSizeEstimator estimates TABLE_ROWS, DATA_LENGTH and INDEX_LENGTH of the tables in information_schema.
+type tableSizeEstimator struct {
+	sctx      sessionctx.Context
+	tableRows map[int64]uint64
+	colLength map[tableHistID]uint64
+	// helper loads the approximate sizes of the tables from PD when tidb_table_stats_estimation is ACCURATE, it's
+	// reset to nil once PD fails, then the rest tables are estimated from the stats.
+	helper *helper.Helper
+}
+
+func newTableSizeEstimator(ctx context.Context, sctx sessionctx.Context) (*tableSizeEstimator, error) {
+	tableRows, colLength, err := tableStatsCache.get(ctx, sctx)
+	if err != nil {
+		return nil, err
+	}
+	e := &tableSizeEstimator{sctx: sctx, tableRows: tableRows, colLength: colLength}
+	if sctx.GetSessionVars().TableStatsEstimation == variable.TableStatsEstimationAccurate {
+		if store, ok := sctx.GetStore().(helper.Storage); ok {
+			e.helper = helper.NewHelper(store)
+		} else {
+			sctx.GetSessionVars().StmtCtx.AppendWarning(ErrStorageNotTiKV.GenWithStackByArgs("Loading the sizes of the tables from PD"))
+		}
+	}
+	return e, nil
+}
+
+// estimate returns the rows, data length and index length of the physical table. The row count comes from the
+// stats, which follows the modifications of the table since it's analyzed. In ACCURATE mode, the lengths are the
+// approximate sizes reported by PD, and the rows fall back to the keys reported by PD if the table has no rows in
+// the stats, like the tables imported without updating the stats.
+func (e *tableSizeEstimator) estimate(table *model.TableInfo, physicalID int64) (rowCount, dataLength, indexLength uint64) {
+	rowCount = e.tableRows[physicalID]
+	if e.helper != nil {
+		stat, err := tableRegionStatsCache.get(e.helper, physicalID)
+		if err == nil && stat.dataLength > 0 {
+			if rowCount == 0 {
+				rowCount = stat.recordKeys
+			}
+			return rowCount, stat.dataLength, stat.indexLength
+		}
+		if err != nil {
+			e.sctx.GetSessionVars().StmtCtx.AppendWarning(err)
+			e.helper = nil
+		}
+	}
+	dataLength, indexLength = getDataAndIndexLength(table, physicalID, rowCount, e.colLength)
+	return rowCount, dataLength, indexLength
+}
+
 func getAutoIncrementID(ctx sessionctx.Context, schema *model.DBInfo, tblInfo *model.TableInfo) (int64, error) {
 	is := ctx.GetInfoSchema().(infoschema.InfoSchema)
 	tbl, err := is.TableByName(schema.Name, tblInfo.Name)
@@ -503,17 +740,34 @@ func (e *memtableRetriever) setDataFromReferConst(ctx context.Context, sctx sess
 }
 
 func (e *memtableRetriever) setDataFromTables(ctx context.Context, sctx sessionctx.Context, schemas []*model.DBInfo) error {
-	tableRowsMap, colLengthMap, err := tableStatsCache.get(ctx, sctx)
+	estimator, err := newTableSizeEstimator(ctx, sctx)
 	if err != nil {
 		return err
 	}
 
 	checker := privilege.GetPrivilegeManager(sctx)
+	var filter *infoSchemaNameFilter
+	if extractor, ok := e.extractor.(*plannercore.TablesTableExtractor); ok {
+		if extractor.SkipRequest {
+			return nil
+		}
+		filter = newTablesNameFilter(extractor)
+	}
 
 	var rows [][]types.Datum
 	createTimeTp := mysql.TypeDatetime
 	for _, schema := range schemas {
+		if !filter.matchSchema(schema.Name) {
+			continue
+		}
 		for _, table := range schema.Tables {
+			// The tables are filtered by the names and the privileges before any field of them is built.
+			if !filter.matchTable(table.Name) {
+				continue
+			}
+			if checker != nil && !checker.RequestVerification(sctx.GetSessionVars().ActiveRoles, schema.Name.L, table.Name.L, "", mysql.AllPrivMask) {
+				continue
+			}
 			collation := table.Collate
 			if collation == "" {
 				collation = mysql.DefaultCollationName
@@ -521,10 +775,6 @@ func (e *memtableRetriever) setDataFromTables(ctx context.Context, sctx sessionc
 			createTime := types.NewTime(types.FromGoTime(table.GetUpdateTime()), createTimeTp, types.DefaultFsp)
 
 			createOptions := ""
-
-			if checker != nil && !checker.RequestVerification(sctx.GetSessionVars().ActiveRoles, schema.Name.L, table.Name.L, "", mysql.AllPrivMask) {
-				continue
-			}
 			pkType := "NONCLUSTERED"
 			if !table.IsView() {
 				if table.GetPartitionInfo() != nil {
@@ -543,12 +793,11 @@ func (e *memtableRetriever) setDataFromTables(ctx context.Context, sctx sessionc
 
 				var rowCount, dataLength, indexLength uint64
 				if table.GetPartitionInfo() == nil {
-					rowCount = tableRowsMap[table.ID]
-					dataLength, indexLength = getDataAndIndexLength(table, table.ID, rowCount, colLengthMap)
+					rowCount, dataLength, indexLength = estimator.estimate(table, table.ID)
 				} else {
 					for _, pi := range table.GetPartitionInfo().Definitions {
-						rowCount += tableRowsMap[pi.ID]
-						parDataLen, parIndexLen := getDataAndIndexLength(table, pi.ID, tableRowsMap[pi.ID], colLengthMap)
+						parRowCount, parDataLen, parIndexLen := estimator.estimate(table, pi.ID)
+						rowCount += parRowCount
 						dataLength += parDataLen
 						indexLength += parIndexLen
 					}
@@ -641,12 +890,25 @@ func (e *memtableRetriever) setDataFromTables(ctx context.Context, sctx sessionc
 func (e *hugeMemTableRetriever) setDataForColumns(ctx context.Context, sctx sessionctx.Context, extractor *plannercore.ColumnsTableExtractor) error {
 	checker := privilege.GetPrivilegeManager(sctx)
 	e.rows = e.rows[:0]
+	if extractor.SkipRequest {
+		return nil
+	}
+	if e.filter == nil {
+		e.filter = newColumnsNameFilter(extractor)
+	}
 	batch := 1024
 	for ; e.dbsIdx < len(e.dbs); e.dbsIdx++ {
 		schema := e.dbs[e.dbsIdx]
+		if !e.filter.matchSchema(schema.Name) {
+			continue
+		}
 		for e.tblIdx < len(schema.Tables) {
 			table := schema.Tables[e.tblIdx]
 			e.tblIdx++
+			// The tables are filtered by the names before the privileges are checked.
+			if !e.filter.matchTable(table.Name) {
+				continue
+			}
 			hasPrivs := false
 			var priv mysql.PrivilegeType
 			if checker != nil {
@@ -676,25 +938,11 @@ func (e *hugeMemTableRetriever) dataForColumnsInTable(ctx context.Context, sctx
 		sctx.GetSessionVars().StmtCtx.AppendWarning(err)
 		return
 	}
-	var tableSchemaRegexp, tableNameRegexp, columnsRegexp []*regexp.Regexp
-	var tableSchemaFilterEnable,
-		tableNameFilterEnable, columnsFilterEnable bool
+	// The schemas and the tables are filtered by setDataForColumns.
+	var columnsRegexp []*regexp.Regexp
+	var columnsFilterEnable bool
 	if !extractor.SkipRequest {
-		tableSchemaFilterEnable = extractor.TableSchema.Count() > 0
-		tableNameFilterEnable = extractor.TableName.Count() > 0
 		columnsFilterEnable = extractor.ColumnName.Count() > 0
-		if len(extractor.TableSchemaPatterns) > 0 {
-			tableSchemaRegexp = make([]*regexp.Regexp, len(extractor.TableSchemaPatterns))
-			for i, pattern := range extractor.TableSchemaPatterns {
-				tableSchemaRegexp[i] = regexp.MustCompile(pattern)
-			}
-		}
-		if len(extractor.TableNamePatterns) > 0 {
-			tableNameRegexp = make([]*regexp.Regexp, len(extractor.TableNamePatterns))
-			for i, pattern := range extractor.TableNamePatterns {
-				tableNameRegexp[i] = regexp.MustCompile(pattern)
-			}
-		}
 		if len(extractor.ColumnNamePatterns) > 0 {
 			columnsRegexp = make([]*regexp.Regexp, len(extractor.ColumnNamePatterns))
 			for i, pattern := range extractor.ColumnNamePatterns {
@@ -708,25 +956,9 @@ ForColumnsTag:
 			continue
 		}
 		if !extractor.SkipRequest {
-			if tableSchemaFilterEnable && !extractor.TableSchema.Exist(schema.Name.L) {
-				continue
-			}
-			if tableNameFilterEnable && !extractor.TableName.Exist(tbl.Name.L) {
-				continue
-			}
 			if columnsFilterEnable && !extractor.ColumnName.Exist(col.Name.L) {
 				continue
 			}
-			for _, re := range tableSchemaRegexp {
-				if !re.MatchString(schema.Name.L) {
-					continue ForColumnsTag
-				}
-			}
-			for _, re := range tableNameRegexp {
-				if !re.MatchString(tbl.Name.L) {
-					continue ForColumnsTag
-				}
-			}
 			for _, re := range columnsRegexp {
 				if !re.MatchString(col.Name.L) {
 					continue ForColumnsTag
@@ -823,7 +1055,7 @@ func calcCharOctLength(lenInChar int, cs string) int {
 }
 
 func (e *memtableRetriever) setDataFromPartitions(ctx context.Context, sctx sessionctx.Context, schemas []*model.DBInfo) error {
-	tableRowsMap, colLengthMap, err := tableStatsCache.get(ctx, sctx)
+	estimator, err := newTableSizeEstimator(ctx, sctx)
 	if err != nil {
 		return err
 	}
@@ -839,8 +1071,7 @@ func (e *memtableRetriever) setDataFromPartitions(ctx context.Context, sctx sess
 
 			var rowCount, dataLength, indexLength uint64
 			if table.GetPartitionInfo() == nil {
-				rowCount = tableRowsMap[table.ID]
-				dataLength, indexLength = getDataAndIndexLength(table, table.ID, rowCount, colLengthMap)
+				rowCount, dataLength, indexLength = estimator.estimate(table, table.ID)
 				avgRowLength := uint64(0)
 				if rowCount != 0 {
 					avgRowLength = dataLength / rowCount
@@ -877,8 +1108,7 @@ func (e *memtableRetriever) setDataFromPartitions(ctx context.Context, sctx sess
 				rows = append(rows, record)
 			} else {
 				for i, pi := range table.GetPartitionInfo().Definitions {
-					rowCount = tableRowsMap[pi.ID]
-					dataLength, indexLength = getDataAndIndexLength(table, pi.ID, tableRowsMap[pi.ID], colLengthMap)
+					rowCount, dataLength, indexLength = estimator.estimate(table, pi.ID)
 
 					avgRowLength := uint64(0)
 					if rowCount != 0 {
@@ -1095,7 +1325,7 @@ func (e *memtableRetriever) setDataFromViews(ctx sessionctx.Context, schemas []*
 func (e *memtableRetriever) dataForTiKVStoreStatus(ctx sessionctx.Context) (err error) {
 	tikvStore, ok := ctx.GetStore().(helper.Storage)
 	if !ok {
-		return errors.New("Information about TiKV store status can be gotten only when the storage is TiKV")
+		return ErrStorageNotTiKV.GenWithStackByArgs("Information about TiKV store status")
 	}
 	tikvHelper := &helper.Helper{
 		Store:       tikvStore,
@@ -1472,7 +1702,7 @@ func keyColumnUsageInTable(schema *model.DBInfo, table *model.TableInfo) [][]typ
 func (e *memtableRetriever) setDataForTiKVRegionStatus(ctx sessionctx.Context) error {
 	tikvStore, ok := ctx.GetStore().(helper.Storage)
 	if !ok {
-		return errors.New("Information about TiKV region status can be gotten only when the storage is TiKV")
+		return ErrStorageNotTiKV.GenWithStackByArgs("Information about TiKV region status")
 	}
 	tikvHelper := &helper.Helper{
 		Store:       tikvStore,
@@ -1535,7 +1765,7 @@ const (
 func (e *memtableRetriever) setDataForTiDBHotRegions(ctx sessionctx.Context) error {
 	tikvStore, ok := ctx.GetStore().(helper.Storage)
 	if !ok {
-		return errors.New("Information about hot region can be gotten only when the storage is TiKV")
+		return ErrStorageNotTiKV.GenWithStackByArgs("Information about hot region")
 	}
 	allSchemas := ctx.GetInfoSchema().(infoschema.InfoSchema).AllSchemas()
 	tikvHelper := &helper.Helper{
@@ -1738,7 +1968,7 @@ func (e *tableStorageStatsRetriever) initialize(sctx sessionctx.Context) error {
 	// Cache the helper and return an error if PD unavailable.
 	tikvStore, ok := sctx.GetStore().(helper.Storage)
 	if !ok {
-		return errors.Errorf("Information about TiKV region status can be gotten only when the storage is TiKV")
+		return ErrStorageNotTiKV.GenWithStackByArgs("Information about TiKV region status")
 	}
 	e.helper = helper.NewHelper(tikvStore)
 	_, err := e.helper.GetPDAddr()
@@ -1980,6 +2210,267 @@ func (e *memtableRetriever) setDataForStatementsSummaryEvicted(ctx sessionctx.Co
 	return nil
 }
 
+func (e *memtableRetriever) setDataForPlanHistory(ctx sessionctx.Context) error {
+	if !hasPriv(ctx, mysql.ProcessPriv) {
+		return plannercore.ErrSpecificAccessDenied.GenWithStackByArgs("PROCESS")
+	}
+	e.rows = stmtsummary.StmtSummaryByDigestMap.ToPlanHistoryDatum(ctx.GetSessionVars().Location())
+	switch e.table.Name.O {
+	case infoschema.ClusterTablePlanHistory:
+		rows, err := infoschema.AppendHostInfoToRows(ctx, e.rows)
+		if err != nil {
+			return err
+		}
+		e.rows = rows
+	}
+	return nil
+}
+
+func (e *memtableRetriever) setDataForPlanCache(ctx sessionctx.Context) error {
+	// Seeing the plans cached by other sessions requires the PROCESS privilege.
+	hasProcessPriv := hasPriv(ctx, mysql.ProcessPriv)
+	connID := ctx.GetSessionVars().ConnectionID
+	var rows [][]types.Datum
+	for _, item := range plannercore.PlanCacheItems() {
+		if !hasProcessPriv && item.ConnID != connID {
+			continue
+		}
+		var evictReason interface{}
+		if item.EvictReason != "" {
+			evictReason = item.EvictReason
+		}
+		rows = append(rows, types.MakeDatums(
+			item.ConnID,
+			item.SchemaName,
+			item.Digest,
+			item.DigestText,
+			item.PlanDigest,
+			item.MemSize,
+			item.HitCount,
+			timestampDatumOrNull(item.CreateTime),
+			timestampDatumOrNull(item.LastHitTime),
+			timestampDatumOrNull(item.EvictTime),
+			evictReason,
+		))
+	}
+	e.rows = rows
+	if e.table.Name.O == infoschema.ClusterTablePlanCache {
+		rows, err := infoschema.AppendHostInfoToRows(ctx, e.rows)
+		if err != nil {
+			return err
+		}
+		e.rows = rows
+	}
+	return nil
+}
+
+func (e *memtableRetriever) setDataForBindingCache(ctx sessionctx.Context) error {
+	if !hasPriv(ctx, mysql.ProcessPriv) {
+		return plannercore.ErrSpecificAccessDenied.GenWithStackByArgs("PROCESS")
+	}
+	bindHandle := domain.GetDomain(ctx).BindHandle()
+	if bindHandle == nil {
+		return nil
+	}
+	var rows [][]types.Datum
+	for _, record := range bindHandle.GetAllBindRecord() {
+		digest := parser.DigestNormalized(record.OriginalSQL).String()
+		for _, binding := range record.Bindings {
+			hit := bindHandle.GetBindingHit(binding.BindSQL)
+			memSize := int64(unsafe.Sizeof(binding)) + int64(len(record.OriginalSQL)+len(record.Db)+len(binding.BindSQL)+len(binding.ID))
+			rows = append(rows, types.MakeDatums(
+				record.OriginalSQL,
+				binding.BindSQL,
+				record.Db,
+				binding.Status,
+				binding.Source,
+				digest,
+				memSize,
+				hit.Count,
+				timestampDatumOrNull(hit.LastHitTime),
+			))
+		}
+	}
+	e.rows = rows
+	if e.table.Name.O == infoschema.ClusterTableBindingCache {
+		rows, err := infoschema.AppendHostInfoToRows(ctx, e.rows)
+		if err != nil {
+			return err
+		}
+		e.rows = rows
+	}
+	return nil
+}
+
+func (e *memtableRetriever) setDataForTableWorkload(ctx sessionctx.Context) error {
+	// The workload is resolved with the latest schema, the tables missing in it are dropped.
+	is := domain.GetDomain(ctx).InfoSchema()
+	checker := privilege.GetPrivilegeManager(ctx)
+	var rows [][]types.Datum
+	var dropped []int64
+	for _, w := range tableworkload.Snapshot() {
+		var partitionName interface{}
+		tbl, ok := is.TableByID(w.TableID)
+		if !ok {
+			var partition *model.PartitionDefinition
+			tbl, _, partition = is.FindTableByPartitionID(w.TableID)
+			if tbl == nil {
+				dropped = append(dropped, w.TableID)
+				continue
+			}
+			partitionName = partition.Name.O
+		}
+		schema, ok := is.SchemaByTable(tbl.Meta())
+		if !ok {
+			continue
+		}
+		if checker != nil && !checker.RequestVerification(ctx.GetSessionVars().ActiveRoles, schema.Name.L, tbl.Meta().Name.L, "", mysql.AllPrivMask) {
+			continue
+		}
+		var hotIndex interface{}
+		hotIndexName, hotIndexReads := w.HotIndex()
+		if hotIndexName != "" {
+			hotIndex = hotIndexName
+		}
+		indexUsage := make(map[string]interface{}, len(w.IndexReads))
+		for idx, cnt := range w.IndexReads {
+			indexUsage[idx] = cnt
+		}
+		rows = append(rows, types.MakeDatums(
+			schema.Name.O,
+			tbl.Meta().Name.O,
+			partitionName,
+			w.TableID,
+			w.ReadCount,
+			w.ScanKeys,
+			int64(w.CopTime),
+			w.WriteCount,
+			w.WriteRows,
+			hotIndex,
+			hotIndexReads,
+			binaryJson.CreateBinary(indexUsage),
+			timestampDatumOrNull(w.LastReadTime),
+			timestampDatumOrNull(w.LastWriteTime),
+		))
+	}
+	tableworkload.Remove(dropped...)
+	e.rows = rows
+	if e.table.Name.O == infoschema.ClusterTableTableWorkload {
+		rows, err := infoschema.AppendHostInfoToRows(ctx, e.rows)
+		if err != nil {
+			return err
+		}
+		e.rows = rows
+	}
+	return nil
+}
+
+func (e *memtableRetriever) setDataForIndexUsage(ctx context.Context, sctx sessionctx.Context, schemas []*model.DBInfo) error {
+	usages, err := loadIndexUsages(ctx, sctx)
+	if err != nil {
+		return err
+	}
+	checker := privilege.GetPrivilegeManager(sctx)
+	var rows [][]types.Datum
+	for _, schema := range schemas {
+		if util.IsMemDB(schema.Name.L) {
+			continue
+		}
+		for _, tb := range schema.Tables {
+			if checker != nil && !checker.RequestVerification(sctx.GetSessionVars().ActiveRoles, schema.Name.L, tb.Name.L, "", mysql.AllPrivMask) {
+				continue
+			}
+			for _, idx := range tb.Indices {
+				if idx.State != model.StatePublic {
+					continue
+				}
+				queryCount, lastUsedAt := usages.get(tb, idx)
+				rows = append(rows, types.MakeDatums(
+					schema.Name.O,
+					tb.Name.O,
+					idx.Name.O,
+					idx.ID,
+					queryCount,
+					timestampDatumOrNull(lastUsedAt),
+				))
+			}
+		}
+	}
+	e.rows = rows
+	if e.table.Name.O == infoschema.ClusterTableIndexUsage {
+		rows, err := infoschema.AppendHostInfoToRows(sctx, e.rows)
+		if err != nil {
+			return err
+		}
+		e.rows = rows
+	}
+	return nil
+}
+
+func (e *memtableRetriever) setDataForDDLBlockers(sctx sessionctx.Context) error {
+	sm := sctx.GetSessionManager()
+	if sm == nil {
+		return nil
+	}
+	txn, err := sctx.Txn(true)
+	if err != nil {
+		return err
+	}
+	jobs, err := admin.GetDDLJobs(txn)
+	if err != nil {
+		return err
+	}
+	blockers := findDDLBlockers(jobs, sm.ShowTxnList())
+	// Like PROCESSLIST, the sessions of the other users are only visible with the PROCESS privilege.
+	loginUser := sctx.GetSessionVars().User
+	hasProcessPriv := hasPriv(sctx, mysql.ProcessPriv)
+	processes := sm.ShowProcessList()
+	is := sctx.GetInfoSchema().(infoschema.InfoSchema)
+	rows := make([][]types.Datum, 0, len(blockers))
+	for _, b := range blockers {
+		if !hasProcessPriv && loginUser != nil && b.txn.Username != loginUser.Username {
+			continue
+		}
+		schemaName := b.job.SchemaName
+		if len(schemaName) == 0 {
+			schemaName = getSchemaName(is, b.job.SchemaID)
+		}
+		var currentSQL interface{}
+		if pi, ok := processes[b.txn.ConnectionID]; ok && len(pi.Info) > 0 {
+			currentSQL = pi.Info
+		}
+		txnStart := time.Unix(0, oracle.ExtractPhysical(b.txn.StartTS)*1e6)
+		rows = append(rows, types.MakeDatums(
+			b.job.ID,
+			schemaName,
+			getTableName(is, b.job.TableID),
+			b.job.Type.String(),
+			b.job.SchemaState.String(),
+			b.job.Query,
+			b.txn.ConnectionID,
+			types.NewTime(types.FromGoTime(txnStart), mysql.TypeTimestamp, types.MaxFsp),
+			currentSQL,
+		))
+	}
+	e.rows = rows
+	if e.table.Name.O == infoschema.ClusterTableDDLBlockers {
+		rows, err := infoschema.AppendHostInfoToRows(sctx, e.rows)
+		if err != nil {
+			return err
+		}
+		e.rows = rows
+	}
+	return nil
+}
+
+// timestampDatumOrNull returns the NULL datum for the zero time.
+func timestampDatumOrNull(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return types.NewTime(types.FromGoTime(t), mysql.TypeTimestamp, types.MaxFsp)
+}
+
 func (e *memtableRetriever) setDataForClientErrorsSummary(ctx sessionctx.Context, tableName string) error {
 	// Seeing client errors should require the PROCESS privilege, with the exception of errors for your own user.
 	// This is similar to information_schema.processlist, which is the closest comparison.
@@ -2489,6 +2980,7 @@ func (r *deadlocksTableRetriever) retrieve(ctx context.Context, sctx sessionctx.
 type hugeMemTableRetriever struct {
 	dummyCloser
 	extractor   *plannercore.ColumnsTableExtractor
+	filter      *infoSchemaNameFilter
 	table       *model.TableInfo
 	columns     []*model.ColumnInfo
 	retrieved   bool
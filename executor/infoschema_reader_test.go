@@ -452,6 +452,53 @@ func (s *testInfoschemaTableSerialSuite) TestDataForTableStatsField(c *C) {
 		testkit.Rows("3 18 54 6"))
 }
 
+func (s *testInfoschemaTableSerialSuite) TestTableStatsEstimation(c *C) {
+	s.dom.SetStatsUpdating(true)
+	oldExpiryTime := executor.TableStatsCacheExpiry
+	executor.TableStatsCacheExpiry = 0
+	defer func() { executor.TableStatsCacheExpiry = oldExpiryTime }()
+	oldRegionExpiryTime := executor.TableRegionStatsCacheExpiry
+	executor.TableRegionStatsCacheExpiry = 0
+	defer func() { executor.TableRegionStatsCacheExpiry = oldRegionExpiryTime }()
+	h := s.dom.StatsHandle()
+	h.Clear()
+	tk := testkit.NewTestKit(c, s.store)
+
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (c int, d int, e char(5), index idx(e))")
+	c.Assert(h.HandleDDLEvent(<-h.DDLEventCh()), IsNil)
+	tk.MustExec(`insert into t(c, d, e) values(1, 2, "c"), (2, 3, "d"), (3, 4, "e")`)
+	tk.MustQuery("select @@tidb_table_stats_estimation").Check(testkit.Rows("FAST"))
+	tk.MustQuery("select table_rows, avg_row_length, data_length, index_length from information_schema.tables where table_name='t'").Check(
+		testkit.Rows("0 0 0 0"))
+
+	// The mock store has no PD, so the rows and sizes are estimated from the stats with a warning.
+	tk.MustExec("set @@tidb_table_stats_estimation = accurate")
+	tk.MustQuery("select @@tidb_table_stats_estimation").Check(testkit.Rows("ACCURATE"))
+	tk.MustQuery("select table_rows, avg_row_length, data_length, index_length from information_schema.tables where table_name='t'").Check(
+		testkit.Rows("0 0 0 0"))
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+
+	// The rows come from PD until the stats are updated, and the sizes always come from PD.
+	fpName := "github.com/pingcap/tidb/executor/mockTableRegionStats"
+	c.Assert(failpoint.Enable(fpName, "return(1)"), IsNil)
+	defer func() { c.Assert(failpoint.Disable(fpName), IsNil) }()
+	tk.MustQuery("select table_rows, avg_row_length, data_length, index_length from information_schema.tables where table_name='t'").Check(
+		testkit.Rows("200 10485 2097152 1048576"))
+	c.Assert(h.DumpStatsDeltaToKV(handle.DumpAll), IsNil)
+	tk.MustQuery("select table_rows, avg_row_length, data_length, index_length from information_schema.tables where table_name='t'").Check(
+		testkit.Rows("3 699050 2097152 1048576"))
+	tk.MustQuery("select table_rows, avg_row_length, data_length, index_length from information_schema.partitions where table_name='t'").Check(
+		testkit.Rows("3 699050 2097152 1048576"))
+	tk.MustQuery("show table status where name='t'").CheckAt([]int{4, 5, 6, 8}, testkit.Rows("3 699050 2097152 1048576"))
+
+	tk.MustExec("set @@tidb_table_stats_estimation = fast")
+	tk.MustQuery("select table_rows, avg_row_length, data_length, index_length from information_schema.tables where table_name='t'").Check(
+		testkit.Rows("3 18 54 6"))
+	c.Assert(tk.ExecToErr("set @@tidb_table_stats_estimation = slow"), NotNil)
+}
+
 func (s *testInfoschemaTableSerialSuite) TestPartitionsTable(c *C) {
 	s.dom.SetStatsUpdating(true)
 	oldExpiryTime := executor.TableStatsCacheExpiry
@@ -970,3 +1017,34 @@ func (s *testInfoschemaTableSuite) TestTablesPKType(c *C) {
 	tk.MustQuery("SELECT TIDB_PK_TYPE FROM information_schema.tables where table_schema = 'test' and table_name = 't_common'").Check(testkit.Rows("CLUSTERED"))
 	tk.MustQuery("SELECT TIDB_PK_TYPE FROM information_schema.tables where table_schema = 'INFORMATION_SCHEMA' and table_name = 'TABLES'").Check(testkit.Rows("NONCLUSTERED"))
 }
+
+func (s *testInfoschemaTableSuite) TestTablesNameFilter(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("create database name_filter_db")
+	tk.MustExec("use name_filter_db")
+	tk.MustExec("create table t_a1 (a int)")
+	tk.MustExec("create table t_a2 (a int)")
+	tk.MustExec("create table tb (b int)")
+	tk.MustExec("create table other (c int)")
+	tk.MustQuery("select table_name from information_schema.tables where table_schema = 'name_filter_db' and table_name like 't\\_a%' order by table_name").Check(testkit.Rows("t_a1", "t_a2"))
+	tk.MustQuery("select table_name from information_schema.tables where table_schema = 'name_filter_db' and table_name like 't_a_' order by table_name").Check(testkit.Rows("t_a1", "t_a2"))
+	tk.MustQuery("select table_name from information_schema.tables where table_schema like 'name\\_filter%' and table_name like '%b'").Check(testkit.Rows("tb"))
+	tk.MustQuery("select table_name from information_schema.tables where table_schema = 'name_filter_db' and table_name in ('tb', 'other') order by table_name").Check(testkit.Rows("other", "tb"))
+	tk.MustQuery("select table_name from information_schema.tables where table_name = 't_a1' and table_name = 't_a2'").Check(testkit.Rows())
+	tk.MustQuery("select count(*) from information_schema.tables where table_schema = 'name_filter_db' or table_name = 'TABLES'").Check(testkit.Rows("5"))
+
+	// Only the tables the user has privileges on are visible.
+	tk.MustExec("create user name_filter_tester")
+	tk.MustExec("grant select on name_filter_db.t_a1 to name_filter_tester")
+	tk.MustExec("grant select on name_filter_db.other to name_filter_tester")
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use information_schema")
+	c.Assert(tk1.Se.Auth(&auth.UserIdentity{
+		Username: "name_filter_tester",
+		Hostname: "127.0.0.1",
+	}, nil, nil), IsTrue)
+	tk1.MustQuery("select table_name from information_schema.tables where table_schema = 'name_filter_db' order by table_name").Check(testkit.Rows("other", "t_a1"))
+	tk1.MustQuery("select table_name from information_schema.tables where table_schema = 'name_filter_db' and table_name like 't%'").Check(testkit.Rows("t_a1"))
+	tk1.MustQuery("select table_name, column_name from information_schema.columns where table_schema = 'name_filter_db' and table_name like 't_a%'").Check(testkit.Rows("t_a1 a"))
+	tk1.MustQuery("select table_name from information_schema.columns where table_schema = 'name_filter_db' and table_name = 't_a2'").Check(testkit.Rows())
+}
@@ -170,10 +170,12 @@ func prefetchConflictedOldRows(ctx context.Context, txn kv.Transaction, rows []t
 	return err
 }
 
-func (e *InsertValues) prefetchDataCache(ctx context.Context, txn kv.Transaction, rows []toBeCheckedRow) error {
+// prefetchDataCache fills the cache of the transaction by BatchGet, and returns the values of the
+// handle keys and the unique keys of the rows, or nil if the prefetch is skipped.
+func (e *InsertValues) prefetchDataCache(ctx context.Context, txn kv.Transaction, rows []toBeCheckedRow) (map[string][]byte, error) {
 	// Temporary table need not to do prefetch because its all data are stored in the memory.
 	if e.Table.Meta().TempTableType != model.TempTableNone {
-		return nil
+		return nil, nil
 	}
 
 	if span := opentracing.SpanFromContext(ctx); span != nil && span.Tracer() != nil {
@@ -183,9 +185,9 @@ func (e *InsertValues) prefetchDataCache(ctx context.Context, txn kv.Transaction
 	}
 	values, err := prefetchUniqueIndices(ctx, txn, rows)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return prefetchConflictedOldRows(ctx, txn, rows, values)
+	return values, prefetchConflictedOldRows(ctx, txn, rows, values)
 }
 
 // updateDupRow updates a duplicate row to a new row.
@@ -231,7 +233,7 @@ func (e *InsertExec) batchUpdateDupRows(ctx context.Context, newRows [][]types.D
 	prefetchStart := time.Now()
 	// Use BatchGet to fill cache.
 	// It's an optimization and could be removed without affecting correctness.
-	if err = e.prefetchDataCache(ctx, txn, toBeCheckedRows); err != nil {
+	if _, err = e.prefetchDataCache(ctx, txn, toBeCheckedRows); err != nil {
 		return err
 	}
 	if e.stats != nil {
@@ -33,6 +33,7 @@ import (
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
@@ -1072,8 +1073,9 @@ func (e *InsertValues) batchCheckAndInsert(ctx context.Context, rows [][]types.D
 	prefetchStart := time.Now()
 	// Fill cache using BatchGet, the following Get requests don't need to visit TiKV.
 	// Temporary table need not to do prefetch because its all data are stored in the memory.
+	var prefetched map[string][]byte
 	if e.Table.Meta().TempTableType == model.TempTableNone {
-		if _, err = prefetchUniqueIndices(ctx, txn, toBeCheckedRows); err != nil {
+		if prefetched, err = prefetchUniqueIndices(ctx, txn, toBeCheckedRows); err != nil {
 			return err
 		}
 	}
@@ -1082,11 +1084,27 @@ func (e *InsertValues) batchCheckAndInsert(ctx context.Context, rows [][]types.D
 		e.stats.Prefetch += time.Since(prefetchStart)
 	}
 
+	// The duplicate warnings are appended at once rather than locking the statement context for each row.
+	var warns []stmtctx.SQLWarn
+	defer func() {
+		if len(warns) > 0 {
+			e.ctx.GetSessionVars().StmtCtx.AppendWarnings(warns)
+		}
+	}()
+	checker := newBatchKeyChecker(prefetched, len(toBeCheckedRows))
 	// append warnings and get no duplicated error rows
 	for i, r := range toBeCheckedRows {
 		if r.ignored {
 			continue
 		}
+		if !checker.mayConflict(r) {
+			e.ctx.GetSessionVars().StmtCtx.AddCopiedRows(1)
+			if err = addRecord(ctx, rows[i]); err != nil {
+				return err
+			}
+			checker.markWritten(r)
+			continue
+		}
 		skip := false
 		if r.handleKey != nil {
 			_, err := txn.Get(ctx, r.handleKey.newKey)
@@ -1097,7 +1115,7 @@ func (e *InsertValues) batchCheckAndInsert(ctx context.Context, rows [][]types.D
 						return err2
 					}
 				} else {
-					e.ctx.GetSessionVars().StmtCtx.AppendWarning(r.handleKey.dupErr)
+					warns = append(warns, stmtctx.SQLWarn{Level: stmtctx.WarnLevelWarning, Err: r.handleKey.dupErr})
 					continue
 				}
 			} else if !kv.IsErrNotFound(err) {
@@ -1108,7 +1126,7 @@ func (e *InsertValues) batchCheckAndInsert(ctx context.Context, rows [][]types.D
 			_, err := txn.Get(ctx, uk.newKey)
 			if err == nil {
 				// If duplicate keys were found in BatchGet, mark row = nil.
-				e.ctx.GetSessionVars().StmtCtx.AppendWarning(uk.dupErr)
+				warns = append(warns, stmtctx.SQLWarn{Level: stmtctx.WarnLevelWarning, Err: uk.dupErr})
 				skip = true
 				break
 			}
@@ -1126,6 +1144,7 @@ func (e *InsertValues) batchCheckAndInsert(ctx context.Context, rows [][]types.D
 			if err != nil {
 				return err
 			}
+			checker.markWritten(r)
 		}
 	}
 	if e.stats != nil {
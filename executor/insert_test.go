@@ -1924,3 +1924,41 @@ func TestReplaceAllocatingAutoID(t *testing.T) {
 	// Note that this error is different from MySQL's duplicated primary key error.
 	tk.MustGetErrCode("REPLACE INTO t1 VALUES (0,'newmaxvalue');", errno.ErrAutoincReadFailed)
 }
+
+func TestBatchInsertIgnoreAndReplace(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int primary key, b int, c int, unique key(b))")
+	tk.MustExec("insert into t values(1, 1, 1), (2, 2, 2)")
+
+	// The rows conflict with both the existing rows and the rows before them in the same statement.
+	tk.MustExec("insert ignore into t values(3, 3, 3), (1, 10, 10), (4, 2, 4), (5, 5, 5), (5, 6, 6), (6, 5, 6)")
+	require.Equal(t, uint64(2), tk.Session().AffectedRows())
+	tk.MustQuery("show warnings").Check(testkit.Rows(
+		"Warning 1062 Duplicate entry '1' for key 'PRIMARY'",
+		"Warning 1062 Duplicate entry '2' for key 'b'",
+		"Warning 1062 Duplicate entry '5' for key 'PRIMARY'",
+		"Warning 1062 Duplicate entry '5' for key 'b'",
+	))
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1 1", "2 2 2", "3 3 3", "5 5 5"))
+
+	tk.MustExec("replace into t values(7, 7, 7), (1, 3, 1), (8, 8, 8), (8, 9, 9), (10, 9, 10), (2, 2, 2)")
+	require.Equal(t, uint64(10), tk.Session().AffectedRows())
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 3 1", "2 2 2", "5 5 5", "7 7 7", "10 9 10"))
+
+	// Inside a transaction, the keys written by the former statements are also checked.
+	tk.MustExec("begin")
+	tk.MustExec("insert into t values(11, 11, 11)")
+	tk.MustExec("insert ignore into t values(11, 12, 12), (12, 11, 12), (13, 13, 13)")
+	tk.MustQuery("show warnings").Check(testkit.Rows(
+		"Warning 1062 Duplicate entry '11' for key 'PRIMARY'",
+		"Warning 1062 Duplicate entry '11' for key 'b'",
+	))
+	tk.MustExec("replace into t values(14, 11, 14), (15, 15, 15)")
+	tk.MustExec("commit")
+	tk.MustQuery("select * from t where a > 10").Check(testkit.Rows("13 13 13", "14 11 14", "15 15 15"))
+	tk.MustExec("admin check table t")
+}
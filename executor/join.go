@@ -59,6 +59,8 @@ type HashJoinExec struct {
 	isNullEQ          []bool
 	probeTypes        []*types.FieldType
 	buildTypes        []*types.FieldType
+	// isNullAware indicates the join is a null-aware anti semi join, whose join keys come from `NOT IN`.
+	isNullAware bool
 
 	// concurrency is the number of partition, build and join workers.
 	concurrency   uint
@@ -557,6 +559,68 @@ func (e *HashJoinExec) joinMatchedProbeSideRow2Chunk(workerID uint, probeKey uin
 	return true, joinResult
 }
 
+// joinNullAwareProbeSideRow2Chunk joins a probe side row of the null-aware anti semi join. `a NOT IN (b)` is NULL
+// rather than TRUE if a or b is NULL, so when the row doesn't match the build side rows with the same join keys,
+// the build side rows with NULL keys are checked, or all the build side rows are checked if the join keys of
+// the row contain NULL. These rows can't match the row, so the check stops at the first NULL result.
+func (e *HashJoinExec) joinNullAwareProbeSideRow2Chunk(workerID uint, probeKey uint64, probeSideRow chunk.Row, probeKeyIsNull bool,
+	hCtx *hashContext, rowContainer *hashRowContainer, joinResult *hashjoinWorkerResult) (bool, *hashjoinWorkerResult) {
+	joiner := e.joiners[workerID]
+	hasNull := false
+	if !probeKeyIsNull {
+		buildSideRows, _, err := rowContainer.GetMatchedRowsAndPtrs(probeKey, probeSideRow, hCtx)
+		if err != nil {
+			joinResult.err = err
+			return false, joinResult
+		}
+		iter := chunk.NewIterator4Slice(buildSideRows)
+		for iter.Begin(); iter.Current() != iter.End(); {
+			matched, isNull, err := joiner.tryToMatchInners(probeSideRow, iter, joinResult.chk)
+			if err != nil {
+				joinResult.err = err
+				return false, joinResult
+			}
+			if matched {
+				return true, joinResult
+			}
+			hasNull = hasNull || isNull
+		}
+	}
+
+	buildSideRow := make([]chunk.Row, 1)
+	iter := chunk.NewIterator4Slice(buildSideRow)
+	checkNull := func(ptr chunk.RowPtr) (err error) {
+		buildSideRow[0], err = rowContainer.GetRow(ptr)
+		if err != nil {
+			return err
+		}
+		iter.Begin()
+		_, hasNull, err = joiner.tryToMatchInners(probeSideRow, iter, joinResult.chk)
+		return err
+	}
+	var err error
+	if probeKeyIsNull {
+		for chkIdx := 0; chkIdx < rowContainer.NumChunks() && !hasNull && err == nil; chkIdx++ {
+			for rowIdx := 0; rowIdx < rowContainer.NumRowsOfChunk(chkIdx) && !hasNull && err == nil; rowIdx++ {
+				err = checkNull(chunk.RowPtr{ChkIdx: uint32(chkIdx), RowIdx: uint32(rowIdx)})
+			}
+		}
+	} else {
+		for _, ptr := range *rowContainer.nullKeyRows {
+			if hasNull || err != nil {
+				break
+			}
+			err = checkNull(ptr)
+		}
+	}
+	if err != nil {
+		joinResult.err = err
+		return false, joinResult
+	}
+	joiner.onMissMatch(hasNull, probeSideRow, joinResult.chk)
+	return true, joinResult
+}
+
 func (e *HashJoinExec) getNewJoinResult(workerID uint) (bool, *hashjoinWorkerResult) {
 	joinResult := &hashjoinWorkerResult{
 		src: e.joinChkResourceCh[workerID],
@@ -600,8 +664,14 @@ func (e *HashJoinExec) join2Chunk(workerID uint, probeSideChk *chunk.Chunk, hCtx
 			joinResult.err = ErrQueryInterrupted
 			return false, joinResult
 		}
-		if !selected[i] || hCtx.hasNull[i] { // process unmatched probe side rows
+		if !selected[i] || (hCtx.hasNull[i] && !e.isNullAware) { // process unmatched probe side rows
 			e.joiners[workerID].onMissMatch(false, probeSideChk.GetRow(i), joinResult.chk)
+		} else if e.isNullAware {
+			probeKey, probeRow := hCtx.hashVals[i].Sum64(), probeSideChk.GetRow(i)
+			ok, joinResult = e.joinNullAwareProbeSideRow2Chunk(workerID, probeKey, probeRow, hCtx.hasNull[i], hCtx, rowContainer, joinResult)
+			if !ok {
+				return false, joinResult
+			}
 		} else { // process matched probe side rows
 			probeKey, probeRow := hCtx.hashVals[i].Sum64(), probeSideChk.GetRow(i)
 			ok, joinResult = e.joinMatchedProbeSideRow2Chunk(workerID, probeKey, probeRow, hCtx, rowContainer, joinResult)
@@ -673,6 +743,9 @@ func (e *HashJoinExec) Next(ctx context.Context, req *chunk.Chunk) (err error) {
 			keyColIdx: buildKeyColIdx,
 		}
 		e.rowContainer = newHashRowContainer(e.ctx, int(e.buildSideEstCount), hCtx, retTypes(e.buildSideExec))
+		if e.isNullAware {
+			e.rowContainer.nullKeyRows = new([]chunk.RowPtr)
+		}
 		// we shallow copies rowContainer for each probe worker to avoid lock contention
 		e.rowContainerForProbe = make([]*hashRowContainer, e.concurrency)
 		for i := uint(0); i < e.concurrency; i++ {
@@ -2834,3 +2834,59 @@ func TestIssue31129(t *testing.T) {
 	require.NoError(t, failpoint.Disable(fpName1))
 	require.NoError(t, failpoint.Disable(fpName2))
 }
+
+func TestNullAwareAntiJoin(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, s, s1, s2")
+	tk.MustExec("create table t(a int, b int, c int)")
+	tk.MustExec("create table s(a int, b int, c int)")
+	tk.MustExec("create table s1(a int, b int, c int)")
+	tk.MustExec("create table s2(a int, b int, c int)")
+	tk.MustExec("insert into t values(1, 1, 1), (2, 2, 2), (null, 3, 3), (4, null, 4), (5, 5, null)")
+	tk.MustExec("insert into s values(1, 1, 1), (3, null, 3), (null, 5, 5)")
+	tk.MustExec("insert into s1 values(1, 1, 1), (2, 3, 3)")
+
+	tk.MustExec("set @@tidb_enable_null_aware_anti_join = on")
+	tk.MustQuery("explain format = 'brief' select * from t where a not in (select a from s)").Check(testkit.Rows(
+		"HashJoin 8000.00 root  Null-aware anti semi join, equal:[eq(test.t.a, test.s.a)]",
+		"├─TableReader(Build) 10000.00 root  data:TableFullScan",
+		"│ └─TableFullScan 10000.00 cop[tikv] table:s keep order:false, stats:pseudo",
+		"└─TableReader(Probe) 10000.00 root  data:TableFullScan",
+		"  └─TableFullScan 10000.00 cop[tikv] table:t keep order:false, stats:pseudo",
+	))
+	tk.MustQuery("explain format = 'brief' select a not in (select a from s where s.c > t.c) from t").Check(testkit.Rows(
+		"HashJoin 10000.00 root  Null-aware anti left outer semi join, equal:[eq(test.t.a, test.s.a)], other cond:gt(test.s.c, test.t.c)",
+		"├─TableReader(Build) 10000.00 root  data:TableFullScan",
+		"│ └─TableFullScan 10000.00 cop[tikv] table:s keep order:false, stats:pseudo",
+		"└─TableReader(Probe) 10000.00 root  data:TableFullScan",
+		"  └─TableFullScan 10000.00 cop[tikv] table:t keep order:false, stats:pseudo",
+	))
+	tk.MustQuery("select * from t where a not in (select a from s)").Check(testkit.Rows())
+	tk.MustQuery("select * from t where a not in (select a from s1)").Sort().Check(testkit.Rows("4 <nil> 4", "5 5 <nil>"))
+	tk.MustQuery("select * from t where a not in (select a from s2)").Sort().Check(testkit.Rows("1 1 1", "2 2 2", "4 <nil> 4", "5 5 <nil>", "<nil> 3 3"))
+	tk.MustQuery("select a, a not in (select a from s) from t").Sort().Check(testkit.Rows("1 0", "2 <nil>", "4 <nil>", "5 <nil>", "<nil> <nil>"))
+	tk.MustQuery("select * from t where (a, b) not in (select a, b from s)").Check(testkit.Rows("2 2 2"))
+
+	queries := []string{
+		"select * from t where a not in (select a from s where s.c > t.c)",
+		"select * from t where (a, b) not in (select a, b from s1)",
+		"select * from t where (a, b) not in (select a, b from s where s.c < t.c)",
+		"select * from t where b not in (select a from s where s.b is not null)",
+		"select a, b not in (select b from s) from t",
+		"select a, (a, c) not in (select a, c from s1) from t",
+		"select a, a not in (select a from s2) from t",
+		"select a, a not in (select a from s where s.c > t.c) from t",
+	}
+	// The results should be the same as the ones of the cartesian anti semi join.
+	results := make([][][]interface{}, 0, len(queries))
+	for _, q := range queries {
+		results = append(results, tk.MustQuery(q).Sort().Rows())
+	}
+	tk.MustExec("set @@tidb_enable_null_aware_anti_join = off")
+	for i, q := range queries {
+		tk.MustQuery(q).Sort().Check(results[i])
+	}
+}
@@ -894,7 +894,7 @@ func (e *hotRegionsHistoryRetriver) retrieve(ctx context.Context, sctx sessionct
 	var finalRows [][]types.Datum
 	tikvStore, ok := sctx.GetStore().(helper.Storage)
 	if !ok {
-		return nil, errors.New("Information about hot region can be gotten only when the storage is TiKV")
+		return nil, ErrStorageNotTiKV.GenWithStackByArgs("Information about hot region")
 	}
 	tikvHelper := &helper.Helper{
 		Store:       tikvStore,
@@ -998,7 +998,7 @@ func (e *tikvRegionPeersRetriever) retrieve(ctx context.Context, sctx sessionctx
 	e.retrieved = true
 	tikvStore, ok := sctx.GetStore().(helper.Storage)
 	if !ok {
-		return nil, errors.New("Information about hot region can be gotten only when the storage is TiKV")
+		return nil, ErrStorageNotTiKV.GenWithStackByArgs("Information about hot region")
 	}
 	tikvHelper := &helper.Helper{
 		Store:       tikvStore,
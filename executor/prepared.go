@@ -267,6 +267,7 @@ type ExecuteExec struct {
 	stmtExec      Executor
 	stmt          ast.StmtNode
 	plan          plannercore.Plan
+	cacheHit      *plannercore.PlanCacheHit
 	id            uint32
 	lowerPriority bool
 	outputNames   []*types.FieldName
@@ -330,9 +331,9 @@ func (e *DeallocateExec) Next(ctx context.Context, req *chunk.Chunk) error {
 	prepared := preparedObj.PreparedAst
 	delete(vars.PreparedStmtNameToID, e.Name)
 	if plannercore.PreparedPlanCacheEnabled() {
-		e.ctx.PreparedPlanCache().Delete(plannercore.NewPlanCacheKey(
+		plannercore.DeletePlanCache(e.ctx.PreparedPlanCache(), plannercore.NewPlanCacheKey(
 			vars, id, prepared.SchemaVersion,
-		))
+		), plannercore.PlanCacheEvictDeallocate)
 	}
 	vars.RemovePreparedStmt(id)
 	return nil
@@ -0,0 +1,293 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+
+	"github.com/cznic/mathutil"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/debugpb"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/store/helper"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/tikv/client-go/v2/tikv"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var _ Executor = &ReclaimTableExec{}
+
+// ReclaimTableExec represents ReclaimTable executor. It asks PD to merge the empty regions
+// that are left by huge deletes, and optionally compacts the key ranges of the tables on TiKV.
+type ReclaimTableExec struct {
+	baseExecutor
+
+	tables  []*ast.TableName
+	compact bool
+	done    bool
+}
+
+// reclaimRegion is the region information that is needed to decide which regions to merge.
+type reclaimRegion struct {
+	id              uint64
+	approximateKeys int64
+}
+
+// regionMerge merges the source region into its adjacent target region.
+type regionMerge struct {
+	source uint64
+	target uint64
+}
+
+const (
+	// reclaimLoadRegionsBackoff is the max backoff time in milliseconds to load the regions of a table.
+	reclaimLoadRegionsBackoff = 20000
+	// reclaimScanRegionsLimit is the max number of regions whose statistics are fetched from PD in a request.
+	reclaimScanRegionsLimit = 1024
+)
+
+// Next implements the Executor Next interface.
+func (e *ReclaimTableExec) Next(ctx context.Context, req *chunk.Chunk) error {
+	req.Reset()
+	if e.done {
+		return nil
+	}
+	e.done = true
+	tikvStore, ok := e.ctx.GetStore().(helper.Storage)
+	if !ok {
+		return ErrStorageNotTiKV.GenWithStackByArgs("admin reclaim table")
+	}
+	if etcd, ok := tikvStore.(kv.EtcdBackend); !ok {
+		return ErrStorageNotTiKV.GenWithStackByArgs("admin reclaim table")
+	} else if addrs, err := etcd.EtcdAddrs(); err != nil {
+		return err
+	} else if len(addrs) == 0 {
+		return errors.New("pd unavailable")
+	}
+	pdHelper := helper.NewHelper(tikvStore)
+	for _, t := range e.tables {
+		var total, empty, merged, compacted int
+		// storeRanges is the key ranges of the partitions on each store, so that a store is compacted by one connection.
+		storeRanges := make(map[uint64][]kv.KeyRange)
+		for _, pid := range physicalIDs(t) {
+			startKey, endKey := tablecodec.EncodeTablePrefix(pid), tablecodec.EncodeTablePrefix(pid+1)
+			bo := tikv.NewBackofferWithVars(ctx, reclaimLoadRegionsBackoff, nil)
+			regions, err := tikvStore.GetRegionCache().LoadRegionsInKeyRange(bo, startKey, endKey)
+			if err != nil {
+				return err
+			}
+			infos, err := loadReclaimRegions(pdHelper, regions, startKey, endKey)
+			if err != nil {
+				return err
+			}
+			for _, info := range infos {
+				if info.approximateKeys == 0 {
+					empty++
+				}
+			}
+			total += len(regions)
+			for _, m := range planRegionMerges(infos) {
+				if err := pdHelper.MergeRegion(m.source, m.target); err != nil {
+					// PD may reject a merge, e.g. when the region is being scheduled. It doesn't affect the others.
+					e.ctx.GetSessionVars().StmtCtx.AppendWarning(err)
+					continue
+				}
+				merged++
+			}
+			if e.compact {
+				keyRange := kv.KeyRange{StartKey: startKey, EndKey: endKey}
+				for storeID := range regionStores(regions) {
+					storeRanges[storeID] = append(storeRanges[storeID], keyRange)
+				}
+			}
+		}
+		if e.compact {
+			n, err := compactKeyRanges(ctx, tikvStore, storeRanges)
+			if err != nil {
+				return err
+			}
+			compacted = n
+		}
+		req.AppendString(0, t.Schema.O)
+		req.AppendString(1, t.Name.O)
+		req.AppendInt64(2, int64(total))
+		req.AppendInt64(3, int64(empty))
+		req.AppendInt64(4, int64(merged))
+		req.AppendInt64(5, int64(compacted))
+	}
+	return nil
+}
+
+// loadReclaimRegions fetches the statistics of the regions in the key range from PD in batches, the regions are
+// sorted by their keys. The regions that are unknown to PD, e.g. they are just split, are regarded as non-empty.
+func loadReclaimRegions(pdHelper *helper.Helper, regions []*tikv.Region, startKey, endKey kv.Key) ([]reclaimRegion, error) {
+	approximateKeys := make(map[uint64]int64, len(regions))
+	for start := 0; start < len(regions); start += reclaimScanRegionsLimit {
+		batch := regions[start:mathutil.Min(start+reclaimScanRegionsLimit, len(regions))]
+		// The first region may start before the key range, so the scan starts from the key range in that case.
+		scanKey := kv.Key(batch[0].StartKey())
+		if scanKey.Cmp(startKey) < 0 {
+			scanKey = startKey
+		}
+		regionsInfo, err := pdHelper.ScanRegionsInfo(scanKey, endKey, len(batch))
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range regionsInfo.Regions {
+			approximateKeys[uint64(info.ID)] = info.ApproximateKeys
+		}
+	}
+	infos := make([]reclaimRegion, 0, len(regions))
+	for _, r := range regions {
+		keys, ok := approximateKeys[r.GetID()]
+		if !ok {
+			keys = -1
+		}
+		infos = append(infos, reclaimRegion{id: r.GetID(), approximateKeys: keys})
+	}
+	return infos, nil
+}
+
+// regionStores returns the stores that hold the peers of the regions.
+func regionStores(regions []*tikv.Region) map[uint64]struct{} {
+	storeIDs := make(map[uint64]struct{})
+	for _, r := range regions {
+		for _, peer := range r.GetMeta().Peers {
+			storeIDs[peer.StoreId] = struct{}{}
+		}
+	}
+	return storeIDs
+}
+
+func physicalIDs(t *ast.TableName) []int64 {
+	if pi := t.TableInfo.GetPartitionInfo(); pi != nil {
+		ids := make([]int64, 0, len(pi.Definitions))
+		for _, def := range pi.Definitions {
+			ids = append(ids, def.ID)
+		}
+		return ids
+	}
+	return []int64{t.TableInfo.ID}
+}
+
+// planRegionMerges decides the merges of the empty regions. The regions are sorted by their keys, each
+// empty region is merged into its next region, or its previous one if the next region is already involved
+// in another merge. A region takes part in at most one merge, so that the merges don't conflict in PD.
+func planRegionMerges(regions []reclaimRegion) []regionMerge {
+	var merges []regionMerge
+	used := make([]bool, len(regions))
+	for i, r := range regions {
+		if used[i] || r.approximateKeys != 0 {
+			continue
+		}
+		target := -1
+		if i+1 < len(regions) && !used[i+1] {
+			target = i + 1
+		} else if i > 0 && !used[i-1] {
+			target = i - 1
+		}
+		if target < 0 {
+			continue
+		}
+		used[i], used[target] = true, true
+		merges = append(merges, regionMerge{source: r.id, target: regions[target].id})
+	}
+	return merges
+}
+
+// compactKeyRanges compacts the key ranges on the TiKV stores, each store is connected once to compact all its ranges.
+// It returns the number of the key ranges that are compacted on the stores, i.e. the sum over the partitions of the
+// number of the stores that are compacted.
+func compactKeyRanges(ctx context.Context, tikvStore helper.Storage, storeRanges map[uint64][]kv.KeyRange) (int, error) {
+	if len(storeRanges) == 0 {
+		return 0, nil
+	}
+	stores, err := tikvStore.GetRegionCache().PDClient().GetAllStores(ctx)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	compacted := 0
+	for _, store := range stores {
+		ranges, ok := storeRanges[store.Id]
+		if !ok || isTiFlashStore(store) {
+			continue
+		}
+		n, err := compactStore(ctx, store.Address, ranges)
+		if err != nil {
+			logutil.Logger(ctx).Warn("compact tikv store failed", zap.String("address", store.Address), zap.Error(err))
+		}
+		compacted += n
+	}
+	return compacted, nil
+}
+
+// compactStore compacts the key ranges on the store, and returns the number of the key ranges that are compacted.
+func compactStore(ctx context.Context, address string, ranges []kv.KeyRange) (int, error) {
+	opt := grpc.WithInsecure()
+	security := config.GetGlobalConfig().Security
+	if len(security.ClusterSSLCA) != 0 {
+		clusterSecurity := security.ClusterSecurity()
+		tlsConfig, err := clusterSecurity.ToTLSConfig()
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		opt = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+	}
+	conn, err := grpc.Dial(address, opt)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			logutil.BgLogger().Error("close grpc connection error", zap.Error(err))
+		}
+	}()
+	client := debugpb.NewDebugClient(conn)
+	for i, r := range ranges {
+		// The keys in the kv engine of TiKV are encoded and prefixed by 'z'.
+		from := append([]byte{'z'}, codec.EncodeBytes(nil, r.StartKey)...)
+		to := append([]byte{'z'}, codec.EncodeBytes(nil, r.EndKey)...)
+		for _, cf := range []string{"default", "write"} {
+			_, err = client.Compact(ctx, &debugpb.CompactRequest{
+				Db:                        debugpb.DB_KV,
+				Cf:                        cf,
+				FromKey:                   from,
+				ToKey:                     to,
+				BottommostLevelCompaction: debugpb.BottommostLevelCompaction_IfHaveCompactionFilter,
+			})
+			if err != nil {
+				return i, errors.Trace(err)
+			}
+		}
+	}
+	return len(ranges), nil
+}
+
+func isTiFlashStore(store *metapb.Store) bool {
+	for _, label := range store.Labels {
+		if label.Key == "engine" && label.Value == "tiflash" {
+			return true
+		}
+	}
+	return false
+}
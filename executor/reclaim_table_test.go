@@ -0,0 +1,43 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanRegionMerges(t *testing.T) {
+	cases := []struct {
+		regions []reclaimRegion
+		merges  []regionMerge
+	}{
+		{nil, nil},
+		{[]reclaimRegion{{1, 0}}, nil},
+		{[]reclaimRegion{{1, 10}, {2, 20}}, nil},
+		// An empty region is merged into the next one.
+		{[]reclaimRegion{{1, 0}, {2, 20}}, []regionMerge{{1, 2}}},
+		// The last empty region is merged into the previous one.
+		{[]reclaimRegion{{1, 10}, {2, 0}}, []regionMerge{{2, 1}}},
+		// A region takes part in at most one merge.
+		{[]reclaimRegion{{1, 0}, {2, 0}, {3, 0}}, []regionMerge{{1, 2}}},
+		{[]reclaimRegion{{1, 0}, {2, 0}, {3, 0}, {4, 5}}, []regionMerge{{1, 2}, {3, 4}}},
+		{[]reclaimRegion{{1, 0}, {2, 5}, {3, 0}}, []regionMerge{{1, 2}}},
+	}
+	for _, ca := range cases {
+		require.Equal(t, ca.merges, planRegionMerges(ca.regions))
+	}
+}
@@ -227,7 +227,8 @@ func (e *ReplaceExec) exec(ctx context.Context, newRows [][]types.Datum) error {
 	prefetchStart := time.Now()
 	// Use BatchGet to fill cache.
 	// It's an optimization and could be removed without affecting correctness.
-	if err = e.prefetchDataCache(ctx, txn, toBeCheckedRows); err != nil {
+	prefetched, err := e.prefetchDataCache(ctx, txn, toBeCheckedRows)
+	if err != nil {
 		return err
 	}
 
@@ -235,11 +236,17 @@ func (e *ReplaceExec) exec(ctx context.Context, newRows [][]types.Datum) error {
 		e.stats.Prefetch = time.Since(prefetchStart)
 	}
 	e.ctx.GetSessionVars().StmtCtx.AddRecordRows(uint64(len(newRows)))
+	checker := newBatchKeyChecker(prefetched, len(toBeCheckedRows))
 	for _, r := range toBeCheckedRows {
-		err = e.replaceRow(ctx, r)
+		if checker.mayConflict(r) {
+			err = e.replaceRow(ctx, r)
+		} else {
+			err = e.addRecord(ctx, r.row)
+		}
 		if err != nil {
 			return err
 		}
+		checker.markWritten(r)
 	}
 	e.memTracker.Consume(int64(txn.Size() - txnSize))
 	return nil
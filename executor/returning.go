@@ -0,0 +1,63 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+// dmlReturning evaluates the RETURNING clause of an UPDATE or DELETE on the affected rows.
+// The results of every chunk of the child are returned by the Next of the DML executor, so they
+// are streamed to the client. A pessimistic DML buffers the results in ExecStmt instead, because
+// it may be retried from the beginning when it meets a lock conflict.
+type dmlReturning struct {
+	exprs []expression.Expression
+	input chunk.MutRow
+	// output is the chunk that the results are appended to, it's set by the Next of the DML executor.
+	output *chunk.Chunk
+}
+
+func newDMLReturning(exprs []expression.Expression, inputTypes []*types.FieldType) *dmlReturning {
+	return &dmlReturning{
+		exprs: exprs,
+		input: chunk.MutRowFromTypes(inputTypes),
+	}
+}
+
+// appendRow evaluates the RETURNING clause on row.
+func (r *dmlReturning) appendRow(row chunk.Row) error {
+	for i, expr := range r.exprs {
+		d, err := expr.Eval(row)
+		if err != nil {
+			return err
+		}
+		r.output.AppendDatum(i, &d)
+	}
+	return nil
+}
+
+// appendDatums evaluates the RETURNING clause on the row composed by the datums of rows.
+func (r *dmlReturning) appendDatums(rows ...[]types.Datum) error {
+	idx := 0
+	for _, row := range rows {
+		for _, d := range row {
+			r.input.SetDatum(idx, d)
+			idx++
+		}
+	}
+	return r.appendRow(r.input.ToRow())
+}
@@ -24,6 +24,7 @@ import (
 	"strings"
 
 	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/parser/mysql"
@@ -46,12 +47,13 @@ type SetConfigExec struct {
 
 // Open implements the Executor Open interface.
 func (s *SetConfigExec) Open(ctx context.Context) error {
+	s.p.Name = strings.ToLower(s.p.Name)
 	if s.p.Type != "" {
 		s.p.Type = strings.ToLower(s.p.Type)
 		if s.p.Type != "tikv" && s.p.Type != "tidb" && s.p.Type != "pd" {
 			return errors.Errorf("unknown type %v", s.p.Type)
 		}
-		if s.p.Type == "tidb" {
+		if s.p.Type == "tidb" && !config.IsOnlineChangeable(s.p.Name) {
 			return errors.Errorf("TiDB doesn't support to change configs online, please use SQL variables")
 		}
 	}
@@ -61,7 +63,6 @@ func (s *SetConfigExec) Open(ctx context.Context) error {
 			return errors.Errorf("invalid instance %v", s.p.Instance)
 		}
 	}
-	s.p.Name = strings.ToLower(s.p.Name)
 
 	body, err := ConvertConfigItem2JSON(s.ctx, s.p.Name, s.p.Value)
 	s.jsonBody = body
@@ -107,7 +108,11 @@ func (s *SetConfigExec) Next(ctx context.Context, req *chunk.Chunk) error {
 		case "tikv":
 			url = fmt.Sprintf("%s://%s/config", util.InternalHTTPSchema(), serverInfo.StatusAddr)
 		case "tidb":
-			return errors.Errorf("TiDB doesn't support to change configs online, please use SQL variables")
+			// Only a few items of TiDB can be changed online, the others should be changed by SQL variables.
+			if !config.IsOnlineChangeable(s.p.Name) {
+				return errors.Errorf("TiDB doesn't support to change configs online, please use SQL variables")
+			}
+			url = fmt.Sprintf("%s://%s/config", util.InternalHTTPSchema(), serverInfo.StatusAddr)
 		default:
 			return errors.Errorf("Unknown server type %s", serverInfo.ServerType)
 		}
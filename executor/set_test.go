@@ -1482,6 +1482,24 @@ func (s *testSuite5) TestSetClusterConfig(c *C) {
 	tk.MustExec("set config '127.0.0.1:5555' log.level='info'")
 	c.Assert(httpCnt, Equals, 1)
 
+	// Only the online changeable items of TiDB can be changed.
+	var urls []string
+	tk.Se.SetValue(executor.TestSetConfigHTTPHandlerKey, func(req *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(req.Body)
+		c.Assert(err, IsNil)
+		urls = append(urls, req.URL.String()+" "+string(body))
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(nil)}, nil
+	})
+	tk.MustExec("set config tidb `status.metrics-label-value-limit`=100")
+	c.Assert(urls, DeepEquals, []string{
+		`http://127.0.0.1:1111/config {"status.metrics-label-value-limit":100}`,
+		`http://127.0.0.1:2222/config {"status.metrics-label-value-limit":100}`,
+	})
+	urls = nil
+	tk.MustExec("set config '127.0.0.1:2222' `status.metrics-histogram-sample-rate`=0.5")
+	c.Assert(urls, DeepEquals, []string{`http://127.0.0.1:2222/config {"status.metrics-histogram-sample-rate":0.5}`})
+	c.Assert(tk.ExecToErr("set config '127.0.0.1:2222' log.level='info'"), ErrorMatches, "TiDB doesn't support to change configs online, please use SQL variables")
+
 	httpCnt = 0
 	tk.Se.SetValue(executor.TestSetConfigHTTPHandlerKey, func(*http.Request) (*http.Response, error) {
 		return nil, errors.New("something wrong")
@@ -825,6 +825,45 @@ func getDefaultCollate(charsetName string) string {
 	return ch.DefaultCollation
 }
 
+// showCreateOptions are the session options that affect the output of SHOW CREATE TABLE.
+type showCreateOptions struct {
+	sqlMode mysql.SQLMode
+	// quoteAll indicates whether all the identifiers are quoted, see sql_quote_show_create.
+	quoteAll bool
+	// mysqlCompatible indicates whether the TiDB specific attributes are omitted.
+	mysqlCompatible bool
+}
+
+func newShowCreateOptions(vars *variable.SessionVars) showCreateOptions {
+	return showCreateOptions{
+		sqlMode:         vars.SQLMode,
+		quoteAll:        vars.QuoteShowCreate,
+		mysqlCompatible: vars.ShowCreateTableMySQLCompatible,
+	}
+}
+
+// escape quotes the identifier. Like MySQL, if sql_quote_show_create is OFF, the identifier is only quoted when it
+// can't be used unquoted.
+func (o showCreateOptions) escape(name string) string {
+	if !o.quoteAll && !identifierNeedsQuote(name) {
+		return name
+	}
+	return stringutil.Escape(name, o.sqlMode)
+}
+
+func identifierNeedsQuote(name string) bool {
+	if len(name) == 0 || (name[0] >= '0' && name[0] <= '9') || parser.IsKeyword(name) {
+		return true
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') && c != '_' && c != '$' && c < 0x80 {
+			return true
+		}
+	}
+	return false
+}
+
 // ConstructResultOfShowCreateTable constructs the result for show create table.
 func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.TableInfo, allocators autoid.Allocators, buf *bytes.Buffer) (err error) {
 	if tableInfo.IsView() {
@@ -846,15 +885,19 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 		tblCollate = getDefaultCollate(tblCharset)
 	}
 
-	sqlMode := ctx.GetSessionVars().SQLMode
-	tableName := stringutil.Escape(tableInfo.Name.O, sqlMode)
+	opts := newShowCreateOptions(ctx.GetSessionVars())
+	tableName := opts.escape(tableInfo.Name.O)
 	switch tableInfo.TempTableType {
 	case model.TempTableGlobal:
 		fmt.Fprintf(buf, "CREATE GLOBAL TEMPORARY TABLE %s (\n", tableName)
 	case model.TempTableLocal:
 		fmt.Fprintf(buf, "CREATE TEMPORARY TABLE %s (\n", tableName)
 	default:
-		fmt.Fprintf(buf, "CREATE TABLE %s (\n", tableName)
+		if tableInfo.IsForeignTable() {
+			fmt.Fprintf(buf, "CREATE FOREIGN TABLE %s (\n", tableName)
+		} else {
+			fmt.Fprintf(buf, "CREATE TABLE %s (\n", tableName)
+		}
 	}
 	var pkCol *model.ColumnInfo
 	var hasAutoIncID bool
@@ -866,7 +909,7 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 		if needAddComma {
 			buf.WriteString(",\n")
 		}
-		fmt.Fprintf(buf, "  %s %s", stringutil.Escape(col.Name.O, sqlMode), col.GetTypeDesc())
+		fmt.Fprintf(buf, "  %s %s", opts.escape(col.Name.O), col.GetTypeDesc())
 		if col.Charset != "binary" {
 			if col.Charset != tblCharset {
 				fmt.Fprintf(buf, " CHARACTER SET %s", col.Charset)
@@ -938,7 +981,7 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 				buf.WriteString(table.OptionalFsp(&col.FieldType))
 			}
 		}
-		if ddl.IsAutoRandomColumnID(tableInfo, col.ID) {
+		if ddl.IsAutoRandomColumnID(tableInfo, col.ID) && !opts.mysqlCompatible {
 			buf.WriteString(fmt.Sprintf(" /*T![auto_rand] AUTO_RANDOM(%d) */", tableInfo.AutoRandomBits))
 		}
 		if len(col.Comment) > 0 {
@@ -955,8 +998,10 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 	if pkCol != nil {
 		// If PKIsHandle, pk info is not in tb.Indices(). We should handle it here.
 		buf.WriteString(",\n")
-		fmt.Fprintf(buf, "  PRIMARY KEY (%s)", stringutil.Escape(pkCol.Name.O, sqlMode))
-		buf.WriteString(" /*T![clustered_index] CLUSTERED */")
+		fmt.Fprintf(buf, "  PRIMARY KEY (%s)", opts.escape(pkCol.Name.O))
+		if !opts.mysqlCompatible {
+			buf.WriteString(" /*T![clustered_index] CLUSTERED */")
+		}
 	}
 
 	publicIndices := make([]*model.IndexInfo, 0, len(tableInfo.Indices))
@@ -973,9 +1018,9 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 		if idxInfo.Primary {
 			buf.WriteString("  PRIMARY KEY ")
 		} else if idxInfo.Unique {
-			fmt.Fprintf(buf, "  UNIQUE KEY %s ", stringutil.Escape(idxInfo.Name.O, sqlMode))
+			fmt.Fprintf(buf, "  UNIQUE KEY %s ", opts.escape(idxInfo.Name.O))
 		} else {
-			fmt.Fprintf(buf, "  KEY %s ", stringutil.Escape(idxInfo.Name.O, sqlMode))
+			fmt.Fprintf(buf, "  KEY %s ", opts.escape(idxInfo.Name.O))
 		}
 
 		cols := make([]string, 0, len(idxInfo.Columns))
@@ -984,7 +1029,7 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 			if tableInfo.Columns[c.Offset].Hidden {
 				colInfo = fmt.Sprintf("(%s)", tableInfo.Columns[c.Offset].GeneratedExprString)
 			} else {
-				colInfo = stringutil.Escape(c.Name.O, sqlMode)
+				colInfo = opts.escape(c.Name.O)
 				if c.Length != types.UnspecifiedLength {
 					colInfo = fmt.Sprintf("%s(%s)", colInfo, strconv.Itoa(c.Length))
 				}
@@ -998,7 +1043,7 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 		if idxInfo.Comment != "" {
 			fmt.Fprintf(buf, ` COMMENT '%s'`, format.OutputFormat(idxInfo.Comment))
 		}
-		if idxInfo.Primary {
+		if idxInfo.Primary && !opts.mysqlCompatible {
 			if tableInfo.HasClusteredIndex() {
 				buf.WriteString(" /*T![clustered_index] CLUSTERED */")
 			} else {
@@ -1013,16 +1058,16 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 	// Foreign Keys are supported by data dictionary even though
 	// they are not enforced by DDL. This is still helpful to applications.
 	for _, fk := range tableInfo.ForeignKeys {
-		buf.WriteString(fmt.Sprintf(",\n  CONSTRAINT %s FOREIGN KEY ", stringutil.Escape(fk.Name.O, sqlMode)))
+		buf.WriteString(fmt.Sprintf(",\n  CONSTRAINT %s FOREIGN KEY ", opts.escape(fk.Name.O)))
 		colNames := make([]string, 0, len(fk.Cols))
 		for _, col := range fk.Cols {
-			colNames = append(colNames, stringutil.Escape(col.O, sqlMode))
+			colNames = append(colNames, opts.escape(col.O))
 		}
 		buf.WriteString(fmt.Sprintf("(%s)", strings.Join(colNames, ",")))
-		buf.WriteString(fmt.Sprintf(" REFERENCES %s ", stringutil.Escape(fk.RefTable.O, sqlMode)))
+		buf.WriteString(fmt.Sprintf(" REFERENCES %s ", opts.escape(fk.RefTable.O)))
 		refColNames := make([]string, 0, len(fk.Cols))
 		for _, refCol := range fk.RefCols {
-			refColNames = append(refColNames, stringutil.Escape(refCol.O, sqlMode))
+			refColNames = append(refColNames, opts.escape(refCol.O))
 		}
 		buf.WriteString(fmt.Sprintf("(%s)", strings.Join(refColNames, ",")))
 		if ast.ReferOptionType(fk.OnDelete) != 0 {
@@ -1035,6 +1080,11 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 
 	buf.WriteString("\n")
 
+	if tableInfo.IsForeignTable() {
+		appendForeignTableInfo(tableInfo.Foreign, buf, opts)
+		return nil
+	}
+
 	buf.WriteString(") ENGINE=InnoDB")
 	// We need to explicitly set the default charset and collation
 	// to make it work on MySQL server which has default collate utf8_general_ci.
@@ -1065,12 +1115,12 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 		}
 	}
 
-	if tableInfo.AutoIdCache != 0 {
+	if tableInfo.AutoIdCache != 0 && !opts.mysqlCompatible {
 		fmt.Fprintf(buf, " /*T![auto_id_cache] AUTO_ID_CACHE=%d */", tableInfo.AutoIdCache)
 	}
 
 	randomAllocator := allocators.Get(autoid.AutoRandomType)
-	if randomAllocator != nil {
+	if randomAllocator != nil && !opts.mysqlCompatible {
 		autoRandID, err := randomAllocator.NextGlobalAutoID()
 		if err != nil {
 			return errors.Trace(err)
@@ -1081,7 +1131,7 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 		}
 	}
 
-	if tableInfo.ShardRowIDBits > 0 {
+	if tableInfo.ShardRowIDBits > 0 && !opts.mysqlCompatible {
 		fmt.Fprintf(buf, " /*T! SHARD_ROW_ID_BITS=%d ", tableInfo.ShardRowIDBits)
 		if tableInfo.PreSplitRegions > 0 {
 			fmt.Fprintf(buf, "PRE_SPLIT_REGIONS=%d ", tableInfo.PreSplitRegions)
@@ -1097,21 +1147,45 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 		fmt.Fprintf(buf, " ON COMMIT DELETE ROWS")
 	}
 
-	if tableInfo.PlacementPolicyRef != nil {
-		fmt.Fprintf(buf, " /*T![placement] PLACEMENT POLICY=%s */", stringutil.Escape(tableInfo.PlacementPolicyRef.Name.String(), sqlMode))
+	if tableInfo.PlacementPolicyRef != nil && !opts.mysqlCompatible {
+		fmt.Fprintf(buf, " /*T![placement] PLACEMENT POLICY=%s */", opts.escape(tableInfo.PlacementPolicyRef.Name.String()))
 	}
 
-	if tableInfo.TableCacheStatusType == model.TableCacheStatusEnable {
+	if ttlInfo := tableInfo.TTLInfo; ttlInfo != nil && !opts.mysqlCompatible {
+		onOrOff := "OFF"
+		if ttlInfo.Enable {
+			onOrOff = "ON"
+		}
+		fmt.Fprintf(buf, " /*T![ttl] TTL=%s + INTERVAL %s %s */ /*T![ttl] TTL_ENABLE='%s' */",
+			opts.escape(ttlInfo.ColumnName.O), ttlInfo.IntervalExprStr, ttlInfo.IntervalTimeUnit, onOrOff)
+	}
+
+	if tableInfo.TableCacheStatusType == model.TableCacheStatusEnable && !opts.mysqlCompatible {
 		// This is not meant to be understand by other components, so it's not written as /*T![cached] */
 		// For all external components, cached table is just a normal table.
 		fmt.Fprintf(buf, " /* CACHED ON */")
 	}
 
 	// add partition info here.
-	appendPartitionInfo(tableInfo.Partition, buf, sqlMode)
+	appendPartitionInfo(tableInfo.Partition, buf, opts)
 	return nil
 }
 
+// appendForeignTableInfo appends the foreign server and the options of a foreign table.
+func appendForeignTableInfo(foreign *model.ForeignTableInfo, buf *bytes.Buffer, opts showCreateOptions) {
+	fmt.Fprintf(buf, ") SERVER %s", opts.escape(foreign.Server.O))
+	options := make([]string, 0, 2)
+	if foreign.RemoteDB != "" {
+		options = append(options, fmt.Sprintf("DATABASE '%s'", format.OutputFormat(foreign.RemoteDB)))
+	}
+	if foreign.RemoteTable != "" {
+		options = append(options, fmt.Sprintf("TABLE '%s'", format.OutputFormat(foreign.RemoteTable)))
+	}
+	if len(options) > 0 {
+		fmt.Fprintf(buf, " OPTIONS (%s)", strings.Join(options, ", "))
+	}
+}
+
 // ConstructResultOfShowCreateSequence constructs the result for show create sequence.
 func ConstructResultOfShowCreateSequence(ctx sessionctx.Context, tableInfo *model.TableInfo, buf *bytes.Buffer) {
 	sqlMode := ctx.GetSessionVars().SQLMode
@@ -1241,7 +1315,7 @@ func fetchShowCreateTable4View(ctx sessionctx.Context, tb *model.TableInfo, buf
 	fmt.Fprintf(buf, ") AS %s", tb.View.SelectStmt)
 }
 
-func appendPartitionInfo(partitionInfo *model.PartitionInfo, buf *bytes.Buffer, sqlMode mysql.SQLMode) {
+func appendPartitionInfo(partitionInfo *model.PartitionInfo, buf *bytes.Buffer, opts showCreateOptions) {
 	if partitionInfo == nil {
 		return
 	}
@@ -1273,7 +1347,7 @@ func appendPartitionInfo(partitionInfo *model.PartitionInfo, buf *bytes.Buffer,
 		// Notice that MySQL uses two spaces between LIST and COLUMNS...
 		fmt.Fprintf(buf, "\nPARTITION BY %s COLUMNS(", partitionInfo.Type.String())
 		for i, col := range partitionInfo.Columns {
-			buf.WriteString(stringutil.Escape(col.O, sqlMode))
+			buf.WriteString(opts.escape(col.O))
 			if i < len(partitionInfo.Columns)-1 {
 				buf.WriteString(",")
 			}
@@ -1287,7 +1361,7 @@ func appendPartitionInfo(partitionInfo *model.PartitionInfo, buf *bytes.Buffer,
 		if i > 0 {
 			fmt.Fprintf(buf, ",\n ")
 		}
-		fmt.Fprintf(buf, "PARTITION %s", stringutil.Escape(def.Name.O, sqlMode))
+		fmt.Fprintf(buf, "PARTITION %s", opts.escape(def.Name.O))
 		// PartitionTypeHash does not have any VALUES definition
 		if partitionInfo.Type == model.PartitionTypeRange {
 			lessThans := strings.Join(def.LessThan, ",")
@@ -1311,9 +1385,9 @@ func appendPartitionInfo(partitionInfo *model.PartitionInfo, buf *bytes.Buffer,
 		if len(def.Comment) > 0 {
 			buf.WriteString(fmt.Sprintf(" COMMENT '%s'", format.OutputFormat(def.Comment)))
 		}
-		if def.PlacementPolicyRef != nil {
+		if def.PlacementPolicyRef != nil && !opts.mysqlCompatible {
 			// add placement ref info here
-			fmt.Fprintf(buf, " /*T![placement] PLACEMENT POLICY=%s */", stringutil.Escape(def.PlacementPolicyRef.Name.O, sqlMode))
+			fmt.Fprintf(buf, " /*T![placement] PLACEMENT POLICY=%s */", opts.escape(def.PlacementPolicyRef.Name.O))
 		}
 	}
 	buf.WriteString(")")
@@ -1321,12 +1395,12 @@ func appendPartitionInfo(partitionInfo *model.PartitionInfo, buf *bytes.Buffer,
 
 // ConstructResultOfShowCreateDatabase constructs the result for show create database.
 func ConstructResultOfShowCreateDatabase(ctx sessionctx.Context, dbInfo *model.DBInfo, ifNotExists bool, buf *bytes.Buffer) (err error) {
-	sqlMode := ctx.GetSessionVars().SQLMode
+	opts := newShowCreateOptions(ctx.GetSessionVars())
 	var ifNotExistsStr string
 	if ifNotExists {
 		ifNotExistsStr = "/*!32312 IF NOT EXISTS*/ "
 	}
-	fmt.Fprintf(buf, "CREATE DATABASE %s%s", ifNotExistsStr, stringutil.Escape(dbInfo.Name.O, sqlMode))
+	fmt.Fprintf(buf, "CREATE DATABASE %s%s", ifNotExistsStr, opts.escape(dbInfo.Name.O))
 	if dbInfo.Charset != "" {
 		fmt.Fprintf(buf, " /*!40100 DEFAULT CHARACTER SET %s ", dbInfo.Charset)
 		defaultCollate, err := charset.GetDefaultCollation(dbInfo.Charset)
@@ -1350,9 +1424,9 @@ func ConstructResultOfShowCreateDatabase(ctx sessionctx.Context, dbInfo *model.D
 	}
 	// MySQL 5.7 always show the charset info but TiDB may ignore it, which makes a slight difference. We keep this
 	// behavior unchanged because it is trivial enough.
-	if dbInfo.PlacementPolicyRef != nil {
+	if dbInfo.PlacementPolicyRef != nil && !opts.mysqlCompatible {
 		// add placement ref info here
-		fmt.Fprintf(buf, " /*T![placement] PLACEMENT POLICY=%s */", stringutil.Escape(dbInfo.PlacementPolicyRef.Name.O, sqlMode))
+		fmt.Fprintf(buf, " /*T![placement] PLACEMENT POLICY=%s */", opts.escape(dbInfo.PlacementPolicyRef.Name.O))
 	}
 	return nil
 }
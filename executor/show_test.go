@@ -1240,6 +1240,81 @@ func TestShowCreateTableAutoRandom(t *testing.T) {
 	))
 }
 
+func TestShowCreateTableMySQLCompatible(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create placement policy x FOLLOWERS=2")
+	defer tk.MustExec("drop placement policy if exists x")
+	tk.MustExec("create table t1 (a bigint primary key auto_random(3), b int) auto_random_base = 100 comment 'c' placement policy x")
+	tk.MustExec("create table t2 (a int, b int, primary key (a) nonclustered) shard_row_id_bits = 4 pre_split_regions = 2 " +
+		"partition by range (a) (partition p0 values less than (10) placement policy x, partition p1 values less than (maxvalue))")
+	defer tk.MustExec("drop table if exists t1, t2")
+
+	tk.MustExec("set @@session.tidb_show_create_table_mysql_compatible = on")
+	tk.MustQuery("show create table t1").Check(testutil.RowsWithSep("|",
+		"t1 CREATE TABLE `t1` (\n"+
+			"  `a` bigint(20) NOT NULL,\n"+
+			"  `b` int(11) DEFAULT NULL,\n"+
+			"  PRIMARY KEY (`a`)\n"+
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='c'",
+	))
+	tk.MustQuery("show create table t2").Check(testutil.RowsWithSep("|",
+		"t2 CREATE TABLE `t2` (\n"+
+			"  `a` int(11) NOT NULL,\n"+
+			"  `b` int(11) DEFAULT NULL,\n"+
+			"  PRIMARY KEY (`a`)\n"+
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin\n"+
+			"PARTITION BY RANGE (`a`)\n"+
+			"(PARTITION `p0` VALUES LESS THAN (10),\n"+
+			" PARTITION `p1` VALUES LESS THAN (MAXVALUE))",
+	))
+
+	tk.MustExec("set @@session.tidb_show_create_table_mysql_compatible = off")
+	tk.MustQuery("show create table t1").Check(testutil.RowsWithSep("|",
+		"t1 CREATE TABLE `t1` (\n"+
+			"  `a` bigint(20) NOT NULL /*T![auto_rand] AUTO_RANDOM(3) */,\n"+
+			"  `b` int(11) DEFAULT NULL,\n"+
+			"  PRIMARY KEY (`a`) /*T![clustered_index] CLUSTERED */\n"+
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin /*T![auto_rand_base] AUTO_RANDOM_BASE=100 */ COMMENT='c' /*T![placement] PLACEMENT POLICY=`x` */",
+	))
+}
+
+func TestShowCreateTableQuoteShowCreate(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int, `key` int, `b c` int, `1a` int, `d$é` int, index idx (a, `key`))")
+	defer tk.MustExec("drop table if exists t")
+
+	tk.MustExec("set @@session.sql_quote_show_create = off")
+	tk.MustQuery("show create table t").Check(testutil.RowsWithSep("|",
+		"t CREATE TABLE t (\n"+
+			"  a int(11) DEFAULT NULL,\n"+
+			"  `key` int(11) DEFAULT NULL,\n"+
+			"  `b c` int(11) DEFAULT NULL,\n"+
+			"  `1a` int(11) DEFAULT NULL,\n"+
+			"  d$é int(11) DEFAULT NULL,\n"+
+			"  KEY idx (a,`key`)\n"+
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin",
+	))
+	tk.MustQuery("show create database test").Check(testkit.Rows("test CREATE DATABASE test /*!40100 DEFAULT CHARACTER SET utf8mb4 */"))
+
+	tk.MustExec("set @@session.sql_quote_show_create = on")
+	tk.MustQuery("show create table t").Check(testutil.RowsWithSep("|",
+		"t CREATE TABLE `t` (\n"+
+			"  `a` int(11) DEFAULT NULL,\n"+
+			"  `key` int(11) DEFAULT NULL,\n"+
+			"  `b c` int(11) DEFAULT NULL,\n"+
+			"  `1a` int(11) DEFAULT NULL,\n"+
+			"  `d$é` int(11) DEFAULT NULL,\n"+
+			"  KEY `idx` (`a`,`key`)\n"+
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin",
+	))
+}
+
 // TestAutoIdCache overrides testAutoRandomSuite to test auto id cache.
 func TestAutoIdCache(t *testing.T) {
 	store, clean := testkit.CreateMockStore(t)
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -143,6 +144,10 @@ func (e *SimpleExec) Next(ctx context.Context, req *chunk.Chunk) (err error) {
 		err = e.executeDropUser(ctx, x)
 	case *ast.RenameUserStmt:
 		err = e.executeRenameUser(x)
+	case *ast.CreateServerStmt:
+		err = e.executeCreateServer(ctx, x)
+	case *ast.DropServerStmt:
+		err = e.executeDropServer(ctx, x)
 	case *ast.SetPwdStmt:
 		err = e.executeSetPwd(ctx, x)
 	case *ast.KillStmt:
@@ -1597,12 +1602,86 @@ func (e *SimpleExec) executeDropStats(s *ast.DropStatsStmt) (err error) {
 
 func (e *SimpleExec) autoNewTxn() bool {
 	switch e.Statement.(type) {
-	case *ast.CreateUserStmt, *ast.AlterUserStmt, *ast.DropUserStmt, *ast.RenameUserStmt:
+	case *ast.CreateUserStmt, *ast.AlterUserStmt, *ast.DropUserStmt, *ast.RenameUserStmt,
+		*ast.CreateServerStmt, *ast.DropServerStmt:
 		return true
 	}
 	return false
 }
 
+func (e *SimpleExec) executeCreateServer(ctx context.Context, s *ast.CreateServerStmt) error {
+	// Only MySQL-compatible servers are supported for now.
+	if !strings.EqualFold(s.Wrapper, "mysql") {
+		return ErrForeignDataStringInvalid.GenWithStackByArgs(s.Wrapper)
+	}
+	var host, db, user, password, socket, owner string
+	port := 0
+	for _, option := range s.Options {
+		switch option.Name {
+		case "HOST":
+			host = option.Value
+		case "DATABASE":
+			db = option.Value
+		case "USER":
+			user = option.Value
+		case "PASSWORD":
+			password = option.Value
+		case "SOCKET":
+			socket = option.Value
+		case "OWNER":
+			owner = option.Value
+		case "PORT":
+			var err error
+			if port, err = strconv.Atoi(option.Value); err != nil {
+				return ErrForeignDataStringInvalid.GenWithStackByArgs(option.Value)
+			}
+		default:
+			return ErrForeignDataStringInvalid.GenWithStackByArgs(option.Name)
+		}
+	}
+
+	name := strings.ToLower(s.Name)
+	exec := e.ctx.(sqlexec.RestrictedSQLExecutor)
+	rows, _, err := exec.ExecRestrictedSQL(ctx, nil, `SELECT 1 FROM %n.%n WHERE Server_name=%?;`, mysql.SystemDB, mysql.ServersTable, name)
+	if err != nil {
+		return err
+	}
+	if len(rows) > 0 {
+		err := ErrForeignServerExists.GenWithStackByArgs(s.Name)
+		if s.IfNotExists {
+			e.ctx.GetSessionVars().StmtCtx.AppendNote(err)
+			return nil
+		}
+		return err
+	}
+	// The password is needed to connect to the foreign server, so it's encrypted instead of hashed.
+	if password, err = encryptForeignServerPassword(password); err != nil {
+		return err
+	}
+	_, _, err = exec.ExecRestrictedSQL(ctx, nil, `INSERT INTO %n.%n (Server_name, Host, Db, Username, Password, Port, Socket, Wrapper, Owner) VALUES (%?, %?, %?, %?, %?, %?, %?, %?, %?);`,
+		mysql.SystemDB, mysql.ServersTable, name, host, db, user, password, port, socket, strings.ToLower(s.Wrapper), owner)
+	return err
+}
+
+func (e *SimpleExec) executeDropServer(ctx context.Context, s *ast.DropServerStmt) error {
+	name := strings.ToLower(s.Name)
+	exec := e.ctx.(sqlexec.RestrictedSQLExecutor)
+	rows, _, err := exec.ExecRestrictedSQL(ctx, nil, `SELECT 1 FROM %n.%n WHERE Server_name=%?;`, mysql.SystemDB, mysql.ServersTable, name)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		err := ErrForeignServerDoesntExist.GenWithStackByArgs(s.Name)
+		if s.IfExists {
+			e.ctx.GetSessionVars().StmtCtx.AppendNote(err)
+			return nil
+		}
+		return err
+	}
+	_, _, err = exec.ExecRestrictedSQL(ctx, nil, `DELETE FROM %n.%n WHERE Server_name=%?;`, mysql.SystemDB, mysql.ServersTable, name)
+	return err
+}
+
 func (e *SimpleExec) executeShutdown(s *ast.ShutdownStmt) error {
 	sessVars := e.ctx.GetSessionVars()
 	logutil.BgLogger().Info("execute shutdown statement", zap.Uint64("conn", sessVars.ConnectionID))
@@ -1676,7 +1755,7 @@ func (e *SimpleExec) executeAdminFlushPlanCache(s *ast.AdminStmt) error {
 	}
 	now := types.NewTime(types.FromGoTime(time.Now().In(e.ctx.GetSessionVars().StmtCtx.TimeZone)), mysql.TypeTimestamp, 3)
 	e.ctx.GetSessionVars().LastUpdateTime4PC = now
-	e.ctx.PreparedPlanCache().DeleteAll()
+	plannercore.DeleteAllPlanCache(e.ctx.PreparedPlanCache(), plannercore.PlanCacheEvictFlush)
 	if s.StatementScope == ast.StatementScopeInstance {
 		// Record the timestamp. When other sessions want to use the plan cache,
 		// it will check the timestamp first to decide whether the plan cache should be flushed.
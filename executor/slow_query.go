@@ -17,6 +17,7 @@ package executor
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -313,6 +314,9 @@ func (e *slowQueryRetriever) getBatchLog(ctx context.Context, reader *bufio.Read
 			}
 			line = string(hack.String(lineByte))
 			log = append(log, line)
+			if strings.HasPrefix(line, variable.SlowLogJSONStartPrefixStr) {
+				break
+			}
 			if strings.HasSuffix(line, variable.SlowLogSQLSuffixStr) {
 				if strings.HasPrefix(line, "use") || strings.HasPrefix(line, variable.SlowLogRowPrefixStr) {
 					continue
@@ -364,13 +368,14 @@ func (e *slowQueryRetriever) getBatchLogForReversedScan(ctx context.Context, rea
 			return nil, err
 		}
 		line = string(hack.String(lineByte))
-		if !hasStartFlag && strings.HasPrefix(line, variable.SlowLogStartPrefixStr) {
+		isJSON := strings.HasPrefix(line, variable.SlowLogJSONStartPrefixStr)
+		if !hasStartFlag && (isJSON || strings.HasPrefix(line, variable.SlowLogStartPrefixStr)) {
 			hasStartFlag = true
 		}
 		if hasStartFlag {
 			log = append(log, line)
-			if strings.HasSuffix(line, variable.SlowLogSQLSuffixStr) {
-				if strings.HasPrefix(line, "use") || strings.HasPrefix(line, variable.SlowLogRowPrefixStr) {
+			if isJSON || strings.HasSuffix(line, variable.SlowLogSQLSuffixStr) {
+				if !isJSON && (strings.HasPrefix(line, "use") || strings.HasPrefix(line, variable.SlowLogRowPrefixStr)) {
 					continue
 				}
 				logs = append(logs, log)
@@ -549,6 +554,14 @@ func (e *slowQueryRetriever) parseLog(ctx context.Context, sctx sessionctx.Conte
 			return nil, ctx.Err()
 		}
 		fileLine := getLineIndex(offset, index)
+		if strings.HasPrefix(line, variable.SlowLogJSONStartPrefixStr) {
+			// An entry in json format is a whole line, the text entry before it, if any, is incomplete.
+			startFlag = false
+			if row := e.parseJSONLog(sctx, tz, line, fileLine); row != nil {
+				data = append(data, row)
+			}
+			continue
+		}
 		if !startFlag && strings.HasPrefix(line, variable.SlowLogStartPrefixStr) {
 			row = make([]types.Datum, len(e.outputCols))
 			user = ""
@@ -621,6 +634,102 @@ func (e *slowQueryRetriever) parseLog(ctx context.Context, sctx sessionctx.Conte
 	return data, nil
 }
 
+// slowLogCopBackoffSuffixes are the suffixes of the backoff fields of the cop tasks, in the order of the text format.
+var slowLogCopBackoffSuffixes = []string{"total_times", "total_time", "max_time", "max_addr", "avg_time", "p90_time"}
+
+// parseJSONLog parses a slow log entry in json format, it returns nil if the entry is invalid or filtered.
+func (e *slowQueryRetriever) parseJSONLog(sctx sessionctx.Context, tz *time.Location, line string, fileLine int) []types.Datum {
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+	var item map[string]interface{}
+	if err := decoder.Decode(&item); err != nil {
+		err = fmt.Errorf("Parse slow log at line %v, error is %v", fileLine, err)
+		sctx.GetSessionVars().StmtCtx.AppendWarning(err)
+		return nil
+	}
+	values := make(map[string]string, len(item))
+	for k, v := range item {
+		switch x := v.(type) {
+		case string:
+			values[k] = x
+		case json.Number:
+			values[k] = x.String()
+		case bool:
+			values[k] = strconv.FormatBool(x)
+		}
+	}
+
+	row := make([]types.Datum, len(e.outputCols))
+	if !e.setColumnValue(sctx, row, tz, variable.SlowLogTimeStr, values[variable.SlowLogTimeStr], e.checker, fileLine) {
+		return nil
+	}
+	delete(values, variable.SlowLogTimeStr)
+	user := ""
+	if value, ok := values[variable.SlowLogUserAndHostStr]; ok {
+		delete(values, variable.SlowLogUserAndHostStr)
+		if fields := strings.SplitN(value, "@", 2); len(fields) == 2 {
+			user = parseUserOrHostValue(fields[0])
+			if !e.setColumnValue(sctx, row, tz, variable.SlowLogUserStr, user, e.checker, fileLine) ||
+				!e.setColumnValue(sctx, row, tz, variable.SlowLogHostStr, parseUserOrHostValue(fields[1]), e.checker, fileLine) {
+				return nil
+			}
+		}
+	}
+	if e.checker != nil && !e.checker.hasPrivilege(user) {
+		return nil
+	}
+
+	// The backoff fields of a backoff type are in a line of the text format, which is the value of SlowLogBackoffDetail.
+	backoffTypes := make(map[string]struct{})
+	fields := make([]string, 0, len(values))
+	for k := range values {
+		if !strings.HasPrefix(k, variable.SlowLogCopBackoffPrefix) {
+			fields = append(fields, k)
+			continue
+		}
+		for _, suffix := range slowLogCopBackoffSuffixes {
+			if strings.HasSuffix(k, "_"+suffix) {
+				backoffTypes[k[len(variable.SlowLogCopBackoffPrefix):len(k)-len(suffix)-1]] = struct{}{}
+				break
+			}
+		}
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if !e.setColumnValue(sctx, row, tz, field, values[field], e.checker, fileLine) {
+			return nil
+		}
+	}
+	backoffs := make([]string, 0, len(backoffTypes))
+	for backoff := range backoffTypes {
+		backoffs = append(backoffs, backoff)
+	}
+	sort.Strings(backoffs)
+	for _, backoff := range backoffs {
+		backoffFields := make([]string, 0, len(slowLogCopBackoffSuffixes))
+		for _, suffix := range slowLogCopBackoffSuffixes {
+			key := variable.SlowLogCopBackoffPrefix + backoff + "_" + suffix
+			if value, ok := values[key]; ok {
+				backoffFields = append(backoffFields, key+variable.SlowLogSpaceMarkStr+value)
+			}
+		}
+		_ = e.setColumnValue(sctx, row, tz, variable.SlowLogBackoffDetail, strings.Join(backoffFields, " "), e.checker, fileLine)
+	}
+	e.setDefaultValue(row)
+	return row
+}
+
+// parseJSONLogTime parses the time of a slow log entry in json format, which is its first field.
+func parseJSONLogTime(line string) (time.Time, error) {
+	value := line[len(variable.SlowLogJSONStartPrefixStr):]
+	if len(value) > 0 && value[0] == '"' {
+		if end := strings.IndexByte(value[1:], '"'); end >= 0 {
+			return ParseTime(value[1 : end+1])
+		}
+	}
+	return time.Time{}, errors.Errorf("invalid slow log entry %v", line)
+}
+
 func (e *slowQueryRetriever) setColumnValue(sctx sessionctx.Context, row []types.Datum, tz *time.Location, field, value string, checker *slowLogChecker, lineNum int) bool {
 	factory := e.columnValueFactoryMap[field]
 	if factory == nil {
@@ -690,7 +799,7 @@ func getColumnValueFactoryByName(sctx sessionctx.Context, colName string, column
 			row[columnIdx] = types.NewStringDatum(plan)
 			return true, nil
 		}, nil
-	case variable.SlowLogConnIDStr, variable.SlowLogExecRetryCount, variable.SlowLogPreprocSubQueriesStr,
+	case variable.SlowLogConnIDStr, variable.SlowLogTraceIDStr, variable.SlowLogExecRetryCount, variable.SlowLogPreprocSubQueriesStr,
 		execdetails.WriteKeysStr, execdetails.WriteSizeStr, execdetails.PrewriteRegionStr, execdetails.TxnRetryStr,
 		execdetails.RequestCountStr, execdetails.TotalKeysStr, execdetails.ProcessKeysStr,
 		execdetails.RocksdbDeleteSkippedCountStr, execdetails.RocksdbKeySkippedCountStr,
@@ -712,7 +821,8 @@ func getColumnValueFactoryByName(sctx sessionctx.Context, colName string, column
 		execdetails.CopTimeStr, execdetails.ProcessTimeStr, execdetails.WaitTimeStr, execdetails.BackoffTimeStr,
 		execdetails.LockKeysTimeStr, variable.SlowLogCopProcAvg, variable.SlowLogCopProcP90, variable.SlowLogCopProcMax,
 		variable.SlowLogCopWaitAvg, variable.SlowLogCopWaitP90, variable.SlowLogCopWaitMax, variable.SlowLogKVTotal,
-		variable.SlowLogPDTotal, variable.SlowLogBackoffTotal, variable.SlowLogWriteSQLRespTotal:
+		variable.SlowLogPDTotal, variable.SlowLogBackoffTotal, variable.SlowLogWriteSQLRespTotal, variable.SlowLogClientStallTotal,
+		variable.SlowLogTiDBExecTime:
 		return func(row []types.Datum, value string, tz *time.Location, checker *slowLogChecker) (valid bool, err error) {
 			v, err := strconv.ParseFloat(value, 64)
 			if err != nil {
@@ -925,6 +1035,9 @@ func (e *slowQueryRetriever) getFileStartTime(ctx context.Context, file *os.File
 		if strings.HasPrefix(line, variable.SlowLogStartPrefixStr) {
 			return ParseTime(line[len(variable.SlowLogStartPrefixStr):])
 		}
+		if strings.HasPrefix(line, variable.SlowLogJSONStartPrefixStr) {
+			return parseJSONLogTime(line)
+		}
 		maxNum -= 1
 		if maxNum <= 0 {
 			break
@@ -1006,6 +1119,9 @@ func (e *slowQueryRetriever) getFileEndTime(ctx context.Context, file *os.File)
 			if strings.HasPrefix(lines[i], variable.SlowLogStartPrefixStr) {
 				return ParseTime(lines[i][len(variable.SlowLogStartPrefixStr):])
 			}
+			if strings.HasPrefix(lines[i], variable.SlowLogJSONStartPrefixStr) {
+				return parseJSONLogTime(lines[i])
+			}
 		}
 		tried += len(lines)
 		if tried >= maxLineNum {
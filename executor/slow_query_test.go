@@ -26,6 +26,7 @@ import (
 
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/parser/auth"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/terror"
 	plannercore "github.com/pingcap/tidb/planner/core"
@@ -117,6 +118,7 @@ func TestParseSlowLogFile(t *testing.T) {
 		`# Time: 2019-04-28T15:24:04.309074+08:00
 # Txn_start_ts: 405888132465033227
 # User@Host: root[root] @ localhost [127.0.0.1]
+# Trace_id: 1103806595072
 # Exec_retry_time: 0.12 Exec_retry_count: 57
 # Query_time: 0.216905
 # Cop_time: 0.38 Process_time: 0.021 Request_count: 1 Total_keys: 637 Processed_keys: 436
@@ -157,9 +159,9 @@ select * from t;`
 		recordString += str
 	}
 	expectRecordString := `2019-04-28 15:24:04.309074,` +
-		`405888132465033227,root,localhost,0,57,0.12,0.216905,` +
+		`405888132465033227,root,localhost,0,1103806595072,57,0.12,0.216905,` +
 		`0,0,0,0,0,0,0,0,0,0,0,0,,0,0,0,0,0,0,0.38,0.021,0,0,0,1,637,0,10,10,10,10,100,,,1,42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772,t1:1,t2:2,` +
-		`0.1,0.2,0.03,127.0.0.1:20160,0.05,0.6,0.8,0.0.0.0:20160,70724,65536,0,0,0,0,0,` +
+		`0.1,0.2,0.03,127.0.0.1:20160,0.05,0.6,0.8,0.0.0.0:20160,70724,65536,0,0,0,0,0,0,0,` +
 		`Cop_backoff_regionMiss_total_times: 200 Cop_backoff_regionMiss_total_time: 0.2 Cop_backoff_regionMiss_max_time: 0.2 Cop_backoff_regionMiss_max_addr: 127.0.0.1 Cop_backoff_regionMiss_avg_time: 0.2 Cop_backoff_regionMiss_p90_time: 0.2 Cop_backoff_rpcPD_total_times: 200 Cop_backoff_rpcPD_total_time: 0.2 Cop_backoff_rpcPD_max_time: 0.2 Cop_backoff_rpcPD_max_addr: 127.0.0.1 Cop_backoff_rpcPD_avg_time: 0.2 Cop_backoff_rpcPD_p90_time: 0.2 Cop_backoff_rpcTiKV_total_times: 200 Cop_backoff_rpcTiKV_total_time: 0.2 Cop_backoff_rpcTiKV_max_time: 0.2 Cop_backoff_rpcTiKV_max_addr: 127.0.0.1 Cop_backoff_rpcTiKV_avg_time: 0.2 Cop_backoff_rpcTiKV_p90_time: 0.2,` +
 		`0,0,1,0,1,1,,60e9378c746d9a2be1c791047e008967cf252eb6de9167ad3aa6098fa2d523f4,` +
 		`update t set i = 1;,select * from t;`
@@ -180,9 +182,9 @@ select * from t;`
 		recordString += str
 	}
 	expectRecordString = `2019-04-28 15:24:04.309074,` +
-		`405888132465033227,root,localhost,0,57,0.12,0.216905,` +
+		`405888132465033227,root,localhost,0,1103806595072,57,0.12,0.216905,` +
 		`0,0,0,0,0,0,0,0,0,0,0,0,,0,0,0,0,0,0,0.38,0.021,0,0,0,1,637,0,10,10,10,10,100,,,1,42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772,t1:1,t2:2,` +
-		`0.1,0.2,0.03,127.0.0.1:20160,0.05,0.6,0.8,0.0.0.0:20160,70724,65536,0,0,0,0,0,` +
+		`0.1,0.2,0.03,127.0.0.1:20160,0.05,0.6,0.8,0.0.0.0:20160,70724,65536,0,0,0,0,0,0,0,` +
 		`Cop_backoff_regionMiss_total_times: 200 Cop_backoff_regionMiss_total_time: 0.2 Cop_backoff_regionMiss_max_time: 0.2 Cop_backoff_regionMiss_max_addr: 127.0.0.1 Cop_backoff_regionMiss_avg_time: 0.2 Cop_backoff_regionMiss_p90_time: 0.2 Cop_backoff_rpcPD_total_times: 200 Cop_backoff_rpcPD_total_time: 0.2 Cop_backoff_rpcPD_max_time: 0.2 Cop_backoff_rpcPD_max_addr: 127.0.0.1 Cop_backoff_rpcPD_avg_time: 0.2 Cop_backoff_rpcPD_p90_time: 0.2 Cop_backoff_rpcTiKV_total_times: 200 Cop_backoff_rpcTiKV_total_time: 0.2 Cop_backoff_rpcTiKV_max_time: 0.2 Cop_backoff_rpcTiKV_max_addr: 127.0.0.1 Cop_backoff_rpcTiKV_avg_time: 0.2 Cop_backoff_rpcTiKV_p90_time: 0.2,` +
 		`0,0,1,0,1,1,,60e9378c746d9a2be1c791047e008967cf252eb6de9167ad3aa6098fa2d523f4,` +
 		`update t set i = 1;,select * from t;`
@@ -238,6 +240,64 @@ select * from t;
 	require.Equal(t, warnings[0].Err.Error(), "Parse slow log at line 2, failed field is Succ, failed value is abc, error is strconv.ParseBool: parsing \"abc\": invalid syntax")
 }
 
+func TestParseSlowLogJSON(t *testing.T) {
+	jsonEntry := `{"Time":"2019-04-28T15:24:04.309074+08:00","Txn_start_ts":405888132465033227,` +
+		`"User@Host":"root[root] @ localhost [127.0.0.1]","Trace_id":1103806595072,"Exec_retry_time":0.12,"Exec_retry_count":57,"Query_time":0.216905,` +
+		`"Cop_time":0.38,"Process_time":0.021,"Request_count":1,"Total_keys":637,"Processed_keys":436,` +
+		`"Is_internal":true,"Digest":"42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772","Stats":"t1:1,t2:2",` +
+		`"Cop_proc_avg":0.1,"Cop_proc_p90":0.2,"Cop_proc_max":0.03,"Cop_proc_addr":"127.0.0.1:20160",` +
+		`"Cop_wait_avg":0.05,"Cop_wait_p90":0.6,"Cop_wait_max":0.8,"Cop_wait_addr":"0.0.0.0:20160",` +
+		`"Cop_backoff_rpcPD_total_times":200,"Cop_backoff_rpcPD_total_time":0.2,"Cop_backoff_rpcPD_max_time":0.2,` +
+		`"Cop_backoff_rpcPD_max_addr":"127.0.0.1","Cop_backoff_rpcPD_avg_time":0.2,"Cop_backoff_rpcPD_p90_time":0.2,` +
+		`"Cop_backoff_regionMiss_total_times":200,"Cop_backoff_regionMiss_total_time":0.2,` +
+		`"Mem_max":70724,"Disk_max":65536,"Plan_from_cache":true,"Succ":false,` +
+		`"Plan_digest":"60e9378c746d9a2be1c791047e008967cf252eb6de9167ad3aa6098fa2d523f4",` +
+		`"Prev_stmt":"update t set i = 1;","Query":"select * from t;"}`
+	// The text entry after the json entry is parsed as well.
+	slowLogStr := jsonEntry + `
+# Time: 2019-04-28T15:24:05.309074+08:00
+# Query_time: 1
+select 1;`
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	ctx := mock.NewContext()
+	ctx.GetSessionVars().TimeZone = loc
+	for _, logNum := range []int{1, 64} {
+		reader := bufio.NewReader(bytes.NewBufferString(slowLogStr))
+		rows, err := parseSlowLog(ctx, reader, logNum)
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		recordString := ""
+		for i, value := range rows[0] {
+			str, err := value.ToString()
+			require.NoError(t, err)
+			if i > 0 {
+				recordString += ","
+			}
+			recordString += str
+		}
+		expectRecordString := `2019-04-28 15:24:04.309074,` +
+			`405888132465033227,root,localhost,0,1103806595072,57,0.12,0.216905,` +
+			`0,0,0,0,0,0,0,0,0,0,0,0,,0,0,0,0,0,0,0.38,0.021,0,0,0,1,637,0,0,0,0,0,0,,,1,42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772,t1:1,t2:2,` +
+			`0.1,0.2,0.03,127.0.0.1:20160,0.05,0.6,0.8,0.0.0.0:20160,70724,65536,0,0,0,0,0,0,0,` +
+			`Cop_backoff_regionMiss_total_times: 200 Cop_backoff_regionMiss_total_time: 0.2 Cop_backoff_rpcPD_total_times: 200 Cop_backoff_rpcPD_total_time: 0.2 Cop_backoff_rpcPD_max_time: 0.2 Cop_backoff_rpcPD_max_addr: 127.0.0.1 Cop_backoff_rpcPD_avg_time: 0.2 Cop_backoff_rpcPD_p90_time: 0.2,` +
+			`0,0,0,0,1,0,,60e9378c746d9a2be1c791047e008967cf252eb6de9167ad3aa6098fa2d523f4,` +
+			`update t set i = 1;,select * from t;`
+		require.Equal(t, expectRecordString, recordString)
+		require.Equal(t, "select 1;", rows[1][len(rows[1])-1].GetString())
+	}
+
+	// The entries of the users without privilege are filtered.
+	retriever, err := newSlowQueryRetriever()
+	require.NoError(t, err)
+	retriever.checker = &slowLogChecker{hasProcessPriv: false, user: &auth.UserIdentity{Username: "other"}}
+	require.Nil(t, retriever.parseJSONLog(ctx, loc, jsonEntry, 1))
+
+	tm, err := parseJSONLogTime(jsonEntry)
+	require.NoError(t, err)
+	require.Equal(t, "2019-04-28T15:24:04.309074+08:00", tm.In(loc).Format(logutil.SlowLogTimeFormat))
+}
+
 // It changes variable.MaxOfMaxAllowedPacket, so must be stayed in SerialSuite.
 func TestParseSlowLogFileSerial(t *testing.T) {
 	loc, err := time.LoadLocation("Asia/Shanghai")
@@ -651,6 +711,43 @@ select 9;`
 	}
 }
 
+func TestBatchLogForReversedScanJSON(t *testing.T) {
+	fileName := "tidb-slow-json.log"
+	logs := []string{
+		`{"Time":"2020-02-15T18:00:01.000000+08:00","Query":"select 1;"}`,
+		`{"Time":"2020-02-15T19:00:05.000000+08:00","Query":"select 2;"}`,
+		`{"Time":"2020-02-15T20:00:05.000000+08:00","Query":"select 3;"}`,
+	}
+	prepareLogs(t, []string{strings.Join(logs, "\n") + "\n"}, []string{fileName})
+	defer removeFiles([]string{fileName})
+
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	sctx := mock.NewContext()
+	sctx.GetSessionVars().TimeZone = loc
+	sctx.GetSessionVars().SlowQueryFile = fileName
+	startTime, err := ParseTime("2020-02-15T18:00:00.000000+08:00")
+	require.NoError(t, err)
+	endTime, err := ParseTime("2020-02-15T21:00:00.000000+08:00")
+	require.NoError(t, err)
+	retriever, err := newSlowQueryRetriever()
+	require.NoError(t, err)
+	retriever.extractor = &plannercore.SlowQueryExtractor{
+		Enable:     true,
+		Desc:       true,
+		TimeRanges: []*plannercore.TimeRange{{StartTime: startTime, EndTime: endTime}},
+	}
+	require.NoError(t, retriever.initialize(context.Background(), sctx))
+	require.Len(t, retriever.files, 1)
+	require.Equal(t, "2020-02-15T18:00:01+08:00", retriever.files[0].start.In(loc).Format(time.RFC3339))
+	require.Equal(t, "2020-02-15T20:00:05+08:00", retriever.files[0].end.In(loc).Format(time.RFC3339))
+	reader := bufio.NewReader(retriever.files[0].file)
+	rows, err := retriever.getBatchLogForReversedScan(context.Background(), reader, &offset{}, 3)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{logs[2], logs[1], logs[0]}}, rows)
+	require.NoError(t, retriever.close())
+}
+
 func prepareLogs(t *testing.T, logData []string, fileNames []string) {
 	writeFile := func(file string, data string) {
 		f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/testkit"
 	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/stretchr/testify/require"
 )
 
@@ -94,6 +95,25 @@ func testSortInDisk(t *testing.T, removeDir bool) {
 	require.Greater(t, tk.Session().GetSessionVars().StmtCtx.DiskTracker.MaxConsumed(), int64(0))
 }
 
+func TestTmpStorageQuota(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustQuery("select @@tidb_tmp_storage_quota").Check(testkit.Rows("-1"))
+	tk.MustExec("select 1")
+	require.Equal(t, int64(-1), tk.Session().GetSessionVars().StmtCtx.DiskTracker.GetBytesLimit())
+
+	// The statement is cancelled once it spills more than the quota.
+	tk.MustExec("set @@tidb_tmp_storage_quota=1024")
+	tk.MustExec("select 1")
+	diskTracker := tk.Session().GetSessionVars().StmtCtx.DiskTracker
+	require.Equal(t, int64(1024), diskTracker.GetBytesLimit())
+	diskTracker.Consume(1000)
+	require.PanicsWithValue(t, memory.PanicStorageExceed+fmt.Sprintf("[conn_id=%d]", tk.Session().GetSessionVars().ConnectionID), func() {
+		diskTracker.Consume(24)
+	})
+}
+
 func TestIssue16696(t *testing.T) {
 	defer config.RestoreFunc()()
 	config.UpdateGlobal(func(conf *config.Config) {
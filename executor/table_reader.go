@@ -108,6 +108,9 @@ type TableReaderExecutor struct {
 	virtualColumnRetFieldTypes []*types.FieldType
 	// batchCop indicates whether use super batch coprocessor request, only works for TiFlash engine.
 	batchCop bool
+	// tikvFallbackDAG reads the regions of TiFlash without available replicas from TiKV, it's nil if the fallback
+	// is disabled or the plans can't be pushed down to TiKV.
+	tikvFallbackDAG *tipb.DAGRequest
 
 	// extraPIDColumnIndex is used for partition reader to add an extra partition ID column.
 	extraPIDColumnIndex offsetOptional
@@ -156,6 +159,12 @@ func (e *TableReaderExecutor) Open(ctx context.Context) error {
 				return err
 			}
 			e.dagPB.RootExecutor = execs[0]
+			if e.tikvFallbackDAG != nil {
+				e.tikvFallbackDAG.Executors, _, err = constructDistExec(e.ctx, e.plans)
+				if err != nil {
+					return err
+				}
+			}
 		} else {
 			e.dagPB.Executors, _, err = constructDistExec(e.ctx, e.plans)
 			if err != nil {
@@ -166,6 +175,9 @@ func (e *TableReaderExecutor) Open(ctx context.Context) error {
 	if e.runtimeStats != nil {
 		collExec := true
 		e.dagPB.CollectExecutionSummaries = &collExec
+		if e.tikvFallbackDAG != nil {
+			e.tikvFallbackDAG.CollectExecutionSummaries = &collExec
+		}
 	}
 	if e.corColInAccess {
 		ts := e.plans[0].(*plannercore.PhysicalTableScan)
@@ -329,6 +341,11 @@ func (e *TableReaderExecutor) buildKVReqSeparately(ctx context.Context, ranges [
 		if err := updateExecutorTableID(ctx, e.dagPB.RootExecutor, pids[i], true); err != nil {
 			return nil, err
 		}
+		if e.tikvFallbackDAG != nil {
+			if err := updateExecutorTableID(ctx, e.tikvFallbackDAG.Executors[0], pids[i], false); err != nil {
+				return nil, err
+			}
+		}
 		var builder distsql.RequestBuilder
 		reqBuilder := builder.SetKeyRanges(kvRange)
 		kvReq, err := reqBuilder.
@@ -342,7 +359,8 @@ func (e *TableReaderExecutor) buildKVReqSeparately(ctx context.Context, ranges [
 			SetFromInfoSchema(e.ctx.GetInfoSchema()).
 			SetMemTracker(e.memTracker).
 			SetStoreType(e.storeType).
-			SetAllowBatchCop(e.batchCop).Build()
+			SetAllowBatchCop(e.allowBatchCop()).
+			SetTiKVFallbackDAGRequest(e.tikvFallbackDAG).Build()
 		if err != nil {
 			return nil, err
 		}
@@ -375,10 +393,20 @@ func (e *TableReaderExecutor) buildKVReq(ctx context.Context, ranges []*ranger.R
 		SetFromInfoSchema(e.ctx.GetInfoSchema()).
 		SetMemTracker(e.memTracker).
 		SetStoreType(e.storeType).
-		SetAllowBatchCop(e.batchCop)
+		SetAllowBatchCop(e.allowBatchCop()).
+		SetTiKVFallbackDAGRequest(e.tikvFallbackDAG)
 	return reqBuilder.Build()
 }
 
+// allowBatchCop returns whether the table is read by the batch coprocessor requests. Besides TiFlash, the stores
+// of TiKV batch the regions if tidb_enable_tikv_batch_cop is on and the order of the rows needn't be kept.
+func (e *TableReaderExecutor) allowBatchCop() bool {
+	if e.storeType == kv.TiKV {
+		return e.ctx.GetSessionVars().EnableTiKVBatchCop && !e.keepOrder
+	}
+	return e.batchCop
+}
+
 func buildVirtualColumnIndex(schema *expression.Schema, columns []*model.ColumnInfo) []int {
 	virtualColumnIndex := make([]int, 0, len(columns))
 	for i, col := range schema.Columns {
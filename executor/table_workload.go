@@ -0,0 +1,167 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/pingcap/tidb/parser/model"
+	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/util/execdetails"
+	"github.com/pingcap/tidb/util/tableworkload"
+)
+
+// tableWorkloadCollector collects the tables read by a statement from its plan and runtime stats.
+type tableWorkloadCollector struct {
+	sctx      sessionctx.Context
+	statsColl *execdetails.RuntimeStatsColl
+	reads     []tableworkload.Read
+}
+
+// recordTableWorkload records the tables read by the statement for INFORMATION_SCHEMA.TABLE_WORKLOAD, and the
+// indexes read by the coprocessor and the batch point get for mysql.schema_index_usage.
+func (a *ExecStmt) recordTableWorkload() {
+	if a.Plan == nil {
+		return
+	}
+	c := &tableWorkloadCollector{sctx: a.Ctx, statsColl: a.Ctx.GetSessionVars().StmtCtx.RuntimeStatsColl}
+	c.collect(a.Plan)
+	if len(c.reads) > 0 {
+		tableworkload.RecordReads(c.reads)
+	}
+}
+
+func (c *tableWorkloadCollector) collect(p plannercore.Plan) {
+	switch x := p.(type) {
+	case *plannercore.PhysicalTableReader:
+		if ts, ok := x.TablePlans[0].(*plannercore.PhysicalTableScan); ok {
+			c.addCopRead(physicalIDOfTableScan(ts), "", x.TablePlans)
+		}
+	case *plannercore.PhysicalIndexReader:
+		if is, ok := x.IndexPlans[0].(*plannercore.PhysicalIndexScan); ok {
+			c.addCopRead(physicalIDOfIndexScan(is), is.Index.Name.O, x.IndexPlans)
+			c.storeIndexUsage(is, x.IndexPlans)
+		}
+	case *plannercore.PhysicalIndexLookUpReader:
+		if is, ok := x.IndexPlans[0].(*plannercore.PhysicalIndexScan); ok {
+			c.addCopRead(physicalIDOfIndexScan(is), is.Index.Name.O, x.IndexPlans, x.TablePlans)
+			c.storeIndexUsage(is, x.IndexPlans)
+		}
+	case *plannercore.PhysicalIndexMergeReader:
+		for _, partialPlans := range x.PartialPlans {
+			switch scan := partialPlans[0].(type) {
+			case *plannercore.PhysicalIndexScan:
+				c.addCopRead(physicalIDOfIndexScan(scan), scan.Index.Name.O, partialPlans)
+				c.storeIndexUsage(scan, partialPlans)
+			case *plannercore.PhysicalTableScan:
+				c.addCopRead(physicalIDOfTableScan(scan), "", partialPlans)
+			}
+		}
+	case *plannercore.PointGetPlan:
+		tableID := x.TblInfo.ID
+		if x.PartitionInfo != nil {
+			tableID = x.PartitionInfo.ID
+		}
+		c.addPointRead(x.ID(), tableID, x.IndexInfo)
+	case *plannercore.BatchPointGetPlan:
+		c.addPointRead(x.ID(), x.TblInfo.ID, x.IndexInfo)
+		// Unlike the point get, the batch point get executor doesn't store the index usage itself.
+		if x.IndexInfo != nil {
+			c.sctx.StoreIndexUsage(x.TblInfo.ID, x.IndexInfo.ID, c.actRowsOfRoot(x.ID()))
+		}
+	case *plannercore.PhysicalCTE:
+		if x.SeedPlan != nil {
+			c.collect(x.SeedPlan)
+		}
+		if x.RecurPlan != nil {
+			c.collect(x.RecurPlan)
+		}
+	case plannercore.PhysicalPlan:
+		for _, child := range x.Children() {
+			c.collect(child)
+		}
+	case *plannercore.Insert:
+		if x.SelectPlan != nil {
+			c.collect(x.SelectPlan)
+		}
+	case *plannercore.Update:
+		if x.SelectPlan != nil {
+			c.collect(x.SelectPlan)
+		}
+	case *plannercore.Delete:
+		if x.SelectPlan != nil {
+			c.collect(x.SelectPlan)
+		}
+	}
+}
+
+// addCopRead adds a read by the coprocessor, whose cost is taken from the root of each pushed down plan.
+func (c *tableWorkloadCollector) addCopRead(tableID int64, indexName string, copPlans ...[]plannercore.PhysicalPlan) {
+	read := tableworkload.Read{TableID: tableID, IndexName: indexName}
+	if c.statsColl != nil {
+		for _, plans := range copPlans {
+			rootID := plans[len(plans)-1].ID()
+			if !c.statsColl.ExistsCopStats(rootID) {
+				continue
+			}
+			copStats := c.statsColl.GetCopStats(rootID)
+			read.ScanKeys += copStats.GetProcessedKeys()
+			read.CopTime += copStats.GetProcessTime()
+		}
+	}
+	c.reads = append(c.reads, read)
+}
+
+func (c *tableWorkloadCollector) addPointRead(planID int, tableID int64, index *model.IndexInfo) {
+	read := tableworkload.Read{TableID: tableID}
+	if index != nil {
+		read.IndexName = index.Name.O
+	}
+	read.ScanKeys = c.actRowsOfRoot(planID)
+	c.reads = append(c.reads, read)
+}
+
+// storeIndexUsage stores the usage of the index read by the coprocessor, the selected rows are the rows
+// returned by the root of the pushed down index plan.
+func (c *tableWorkloadCollector) storeIndexUsage(is *plannercore.PhysicalIndexScan, indexPlans []plannercore.PhysicalPlan) {
+	rows := int64(0)
+	if c.statsColl != nil {
+		rootID := indexPlans[len(indexPlans)-1].ID()
+		if c.statsColl.ExistsCopStats(rootID) {
+			rows = c.statsColl.GetCopStats(rootID).GetActRows()
+		}
+	}
+	c.sctx.StoreIndexUsage(is.Table.ID, is.Index.ID, rows)
+}
+
+func (c *tableWorkloadCollector) actRowsOfRoot(planID int) int64 {
+	if c.statsColl != nil && c.statsColl.ExistsRootStats(planID) {
+		return c.statsColl.GetRootStats(planID).GetActRows()
+	}
+	return 0
+}
+
+func physicalIDOfTableScan(ts *plannercore.PhysicalTableScan) int64 {
+	if isPartition, physicalID := ts.IsPartition(); isPartition {
+		return physicalID
+	}
+	return ts.Table.ID
+}
+
+func physicalIDOfIndexScan(is *plannercore.PhysicalIndexScan) int64 {
+	if isPartition, physicalID := is.IsPartition(); isPartition {
+		return physicalID
+	}
+	return is.Table.ID
+}
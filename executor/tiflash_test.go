@@ -1071,3 +1071,26 @@ func (s *tiflashTestSuite) TestForbidTiflashDuringStaleRead(c *C) {
 	c.Assert(strings.Contains(res, "tiflash"), IsFalse)
 	c.Assert(strings.Contains(res, "tikv"), IsTrue)
 }
+
+func (s *tiflashTestSuite) TestKeepOrderTiFlashScanNotBatchCop(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int not null primary key, b int not null)")
+	tk.MustExec("alter table t set tiflash replica 1")
+	tb := testGetTableByName(c, tk.Se, "test", "t")
+	err := domain.GetDomain(tk.Se).DDL().UpdateTableReplicaInfo(tk.Se, tb.Meta().ID, true)
+	c.Assert(err, IsNil)
+	tk.MustExec("insert into t values(1,0), (2,0), (3,0), (4,0)")
+	tk.MustExec("set @@session.tidb_isolation_read_engines=\"tiflash\"")
+	tk.MustExec("set @@session.tidb_allow_mpp=0")
+	tk.MustExec("set @@session.tidb_allow_batch_cop=2")
+
+	// TiFlash doesn't keep the order across the regions of a batch cop request, so the keep order scans are still
+	// sent by the cop requests.
+	res := fmt.Sprint(tk.MustQuery("explain select * from t order by a limit 3").Rows())
+	c.Assert(res, Matches, ".*cop\\[tiflash\\].*keep order:true.*")
+	c.Assert(strings.Contains(res, "batchCop"), IsFalse)
+	tk.MustQuery("select * from t order by a limit 3").Check(testkit.Rows("1 0", "2 0", "3 0"))
+	tk.MustQuery("select * from t order by a desc limit 3").Check(testkit.Rows("4 0", "3 0", "2 0"))
+}
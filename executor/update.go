@@ -60,6 +60,12 @@ type UpdateExec struct {
 	virtualAssignmentsOffset  int
 	drained                   bool
 	memTracker                *memory.Tracker
+	// returning is not nil if the UPDATE has a RETURNING clause.
+	returning *dmlReturning
+	// chk and memUsageOfChk are the chunk of the child being updated, they are kept across the calls
+	// of Next when the results of RETURNING are returned chunk by chunk.
+	chk           *chunk.Chunk
+	memUsageOfChk int64
 
 	stats *updateRuntimeStats
 
@@ -192,6 +198,11 @@ func (e *UpdateExec) exec(ctx context.Context, schema *expression.Schema, row, n
 		changed, err1 := updateRecord(ctx, e.ctx, handle, oldData, newTableData, flags, tbl, false, e.memTracker)
 		if err1 == nil {
 			e.updatedRowKeys[content.Start].Set(handle, changed)
+			if e.returning != nil {
+				if err := e.returning.appendDatums(newData, row); err != nil {
+					return err
+				}
+			}
 			continue
 		}
 
@@ -223,17 +234,18 @@ func (e *UpdateExec) Next(ctx context.Context, req *chunk.Chunk) error {
 		if e.collectRuntimeStatsEnabled() {
 			ctx = context.WithValue(ctx, autoid.AllocatorRuntimeStatsCtxKey, e.stats.AllocatorRuntimeStats)
 		}
-		numRows, err := e.updateRows(ctx)
+		numRows, err := e.updateRows(ctx, req)
 		if err != nil {
 			return err
 		}
-		e.drained = true
 		e.ctx.GetSessionVars().StmtCtx.AddRecordRows(uint64(numRows))
 	}
 	return nil
 }
 
-func (e *UpdateExec) updateRows(ctx context.Context) (int, error) {
+// updateRows updates the rows of the child until it's drained. If the UPDATE has a RETURNING clause,
+// it returns once the results of a chunk of the child are appended to req.
+func (e *UpdateExec) updateRows(ctx context.Context, req *chunk.Chunk) (int, error) {
 	fields := retTypes(e.children[0])
 	colsInfo := make([]*table.Column, len(fields))
 	for _, content := range e.tblColPosInfos {
@@ -243,28 +255,35 @@ func (e *UpdateExec) updateRows(ctx context.Context) (int, error) {
 		}
 	}
 	globalRowIdx := 0
-	chk := newFirstChunk(e.children[0])
-	if !e.allAssignmentsAreConstant {
-		e.evalBuffer = chunk.MutRowFromTypes(fields)
+	if e.chk == nil {
+		e.chk = newFirstChunk(e.children[0])
+		if !e.allAssignmentsAreConstant {
+			e.evalBuffer = chunk.MutRowFromTypes(fields)
+		}
 	}
 	composeFunc := e.fastComposeNewRow
 	if !e.allAssignmentsAreConstant {
 		composeFunc = e.composeNewRow
 	}
-	memUsageOfChk := int64(0)
+	if e.returning != nil {
+		e.returning.output = req
+	}
 	totalNumRows := 0
 	for {
-		e.memTracker.Consume(-memUsageOfChk)
+		chk := e.chk
+		e.memTracker.Consume(-e.memUsageOfChk)
 		err := Next(ctx, e.children[0], chk)
 		if err != nil {
 			return 0, err
 		}
 
 		if chk.NumRows() == 0 {
+			e.memUsageOfChk = 0
+			e.drained = true
 			break
 		}
-		memUsageOfChk = chk.MemoryUsage()
-		e.memTracker.Consume(memUsageOfChk)
+		e.memUsageOfChk = chk.MemoryUsage()
+		e.memTracker.Consume(e.memUsageOfChk)
 		if e.collectRuntimeStatsEnabled() {
 			txn, err := e.ctx.Txn(true)
 			if err == nil && txn.GetSnapshot() != nil {
@@ -314,7 +333,10 @@ func (e *UpdateExec) updateRows(ctx context.Context) (int, error) {
 			}
 		}
 		totalNumRows += chk.NumRows()
-		chk = chunk.Renew(chk, e.maxChunkSize)
+		e.chk = chunk.Renew(chk, e.maxChunkSize)
+		if req.NumRows() > 0 {
+			break
+		}
 	}
 	return totalNumRows, nil
 }
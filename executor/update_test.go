@@ -506,3 +506,51 @@ func TestIssue23553(t *testing.T) {
 	tk.MustExec(`insert into tt values('1',0),('1',0),('1',0)`)
 	tk.MustExec(`update tt a inner join (select m0 from tt where status!=1 group by m0 having count(*)>1) b on a.m0=b.m0 set a.status=1`)
 }
+
+func TestUpdateReturning(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(id int primary key, v int, g int as (v + 1))")
+	tk.MustExec("insert into t(id, v) values(1, 10), (2, 20), (3, 30)")
+
+	tk.MustQuery("update t set v = v + 1 where id = 1 returning *").Check(testkit.Rows("1 11 12"))
+	tk.MustQuery("update t set v = 0 where id > 1 order by id desc limit 1 returning id, v, g").Check(testkit.Rows("3 0 1"))
+	tk.MustQuery("update t set v = v where id = 2 returning v").Check(testkit.Rows("20"))
+	tk.MustQuery("update t set v = 1 where id > 10 returning id").Check(testkit.Rows())
+
+	tk.MustExec("begin pessimistic")
+	tk.MustQuery("update t set v = -v returning id, v").Sort().Check(testkit.Rows("1 -11", "2 -20", "3 0"))
+	tk.MustExec("commit")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 -11 -10", "2 -20 -19", "3 0 1"))
+
+	// The unqualified and the NEW columns are the updated values, the OLD columns are the values before the update.
+	tk.MustQuery("update t set v = v + 1 where id = 1 returning old.v, new.v, v, t.v, old.g, new.g").Check(testkit.Rows("-11 -10 -10 -10 -10 -9"))
+	tk.MustQuery("update t set v = 5 where id = 3 returning old.*, new.*").Check(testkit.Rows("3 0 1 3 5 6"))
+
+	// The results are returned chunk by chunk.
+	tk.MustExec("drop table if exists t1")
+	tk.MustExec("create table t1(a int)")
+	tk.MustExec("set @@tidb_max_chunk_size = 32")
+	tk.MustExec("insert into t1 values (1)")
+	for i := 0; i < 6; i++ {
+		tk.MustExec("insert into t1 select * from t1")
+	}
+	require.Len(t, tk.MustQuery("update t1 set a = a + 1 returning old.a, new.a").Rows(), 64)
+	tk.MustQuery("select a, count(*) from t1 group by a").Check(testkit.Rows("2 64"))
+	tk.MustExec("begin pessimistic")
+	require.Len(t, tk.MustQuery("update t1 set a = a + 1 returning a").Rows(), 64)
+	tk.MustExec("commit")
+	tk.MustQuery("select a, count(*) from t1 group by a").Check(testkit.Rows("3 64"))
+
+	tk.MustGetErrCode("update t t1, t t2 set t1.v = 1 returning t1.id", errno.ErrParse)
+	tk.MustGetErrCode("update t set v = 1 returning old.b", errno.ErrBadField)
+	tk.MustGetErrCode("update t set v = old.v + 1 returning v", errno.ErrBadField)
+	tk.MustExec("set @@tidb_low_resolution_tso = 1")
+	tk.MustGetErrCode("update t1 set a = 1 returning a", errno.ErrWriteInHistoryRead)
+	tk.MustExec("set @@tidb_low_resolution_tso = 0")
+	tk.MustGetErrCode("update t set v = 1 returning (select a from t1 where t1.a = old.v)", errno.ErrNotSupportedYet)
+}
@@ -16,7 +16,9 @@ package expression
 
 import (
 	"fmt"
+	"sync/atomic"
 
+	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/sessionctx"
@@ -69,9 +71,20 @@ type Constant struct {
 	ParamMarker *ParamMarker
 	hashcode    []byte
 
+	// deferredFold caches the value of DeferredExpr for the current execution, see getDeferredDatum.
+	deferredFold atomic.Value
+	// deferredStable is 1 if DeferredExpr is stable during an execution, 2 if not, 0 if unknown.
+	deferredStable int32
+
 	collationInfo
 }
 
+// deferredFoldResult is the value of a deferred expression folded for one execution of a statement.
+type deferredFoldResult struct {
+	taskID uint64
+	val    types.Datum
+}
+
 // ParamMarker indicates param provided by COM_STMT_EXECUTE.
 type ParamMarker struct {
 	ctx   sessionctx.Context
@@ -121,7 +134,7 @@ func (c *Constant) GetType() *types.FieldType {
 
 // VecEvalInt evaluates this expression in a vectorized manner.
 func (c *Constant) VecEvalInt(ctx sessionctx.Context, input *chunk.Chunk, result *chunk.Column) error {
-	if c.DeferredExpr == nil {
+	if c.DeferredExpr == nil || c.isDeferredExprStable() {
 		return genVecFromConstExpr(ctx, c, types.ETInt, input, result)
 	}
 	return c.DeferredExpr.VecEvalInt(ctx, input, result)
@@ -129,7 +142,7 @@ func (c *Constant) VecEvalInt(ctx sessionctx.Context, input *chunk.Chunk, result
 
 // VecEvalReal evaluates this expression in a vectorized manner.
 func (c *Constant) VecEvalReal(ctx sessionctx.Context, input *chunk.Chunk, result *chunk.Column) error {
-	if c.DeferredExpr == nil {
+	if c.DeferredExpr == nil || c.isDeferredExprStable() {
 		return genVecFromConstExpr(ctx, c, types.ETReal, input, result)
 	}
 	return c.DeferredExpr.VecEvalReal(ctx, input, result)
@@ -137,7 +150,7 @@ func (c *Constant) VecEvalReal(ctx sessionctx.Context, input *chunk.Chunk, resul
 
 // VecEvalString evaluates this expression in a vectorized manner.
 func (c *Constant) VecEvalString(ctx sessionctx.Context, input *chunk.Chunk, result *chunk.Column) error {
-	if c.DeferredExpr == nil {
+	if c.DeferredExpr == nil || c.isDeferredExprStable() {
 		return genVecFromConstExpr(ctx, c, types.ETString, input, result)
 	}
 	return c.DeferredExpr.VecEvalString(ctx, input, result)
@@ -145,7 +158,7 @@ func (c *Constant) VecEvalString(ctx sessionctx.Context, input *chunk.Chunk, res
 
 // VecEvalDecimal evaluates this expression in a vectorized manner.
 func (c *Constant) VecEvalDecimal(ctx sessionctx.Context, input *chunk.Chunk, result *chunk.Column) error {
-	if c.DeferredExpr == nil {
+	if c.DeferredExpr == nil || c.isDeferredExprStable() {
 		return genVecFromConstExpr(ctx, c, types.ETDecimal, input, result)
 	}
 	return c.DeferredExpr.VecEvalDecimal(ctx, input, result)
@@ -153,7 +166,7 @@ func (c *Constant) VecEvalDecimal(ctx sessionctx.Context, input *chunk.Chunk, re
 
 // VecEvalTime evaluates this expression in a vectorized manner.
 func (c *Constant) VecEvalTime(ctx sessionctx.Context, input *chunk.Chunk, result *chunk.Column) error {
-	if c.DeferredExpr == nil {
+	if c.DeferredExpr == nil || c.isDeferredExprStable() {
 		return genVecFromConstExpr(ctx, c, types.ETTimestamp, input, result)
 	}
 	return c.DeferredExpr.VecEvalTime(ctx, input, result)
@@ -161,7 +174,7 @@ func (c *Constant) VecEvalTime(ctx sessionctx.Context, input *chunk.Chunk, resul
 
 // VecEvalDuration evaluates this expression in a vectorized manner.
 func (c *Constant) VecEvalDuration(ctx sessionctx.Context, input *chunk.Chunk, result *chunk.Column) error {
-	if c.DeferredExpr == nil {
+	if c.DeferredExpr == nil || c.isDeferredExprStable() {
 		return genVecFromConstExpr(ctx, c, types.ETDuration, input, result)
 	}
 	return c.DeferredExpr.VecEvalDuration(ctx, input, result)
@@ -169,7 +182,7 @@ func (c *Constant) VecEvalDuration(ctx sessionctx.Context, input *chunk.Chunk, r
 
 // VecEvalJSON evaluates this expression in a vectorized manner.
 func (c *Constant) VecEvalJSON(ctx sessionctx.Context, input *chunk.Chunk, result *chunk.Column) error {
-	if c.DeferredExpr == nil {
+	if c.DeferredExpr == nil || c.isDeferredExprStable() {
 		return genVecFromConstExpr(ctx, c, types.ETJson, input, result)
 	}
 	return c.DeferredExpr.VecEvalJSON(ctx, input, result)
@@ -179,12 +192,71 @@ func (c *Constant) getLazyDatum(row chunk.Row) (dt types.Datum, isLazy bool, err
 	if c.ParamMarker != nil {
 		return c.ParamMarker.GetUserVar(), true, nil
 	} else if c.DeferredExpr != nil {
-		dt, err = c.DeferredExpr.Eval(row)
+		dt, err = c.getDeferredDatum(row)
 		return dt, true, err
 	}
 	return types.Datum{}, false, nil
 }
 
+// getDeferredDatum evaluates DeferredExpr. The deferred expressions of the cached plans, e.g. `? + 1` or `now()`,
+// don't depend on the rows, so they are folded once per execution instead of once per row. The expressions
+// that may change during an execution, e.g. `random_bytes(?)`, are still evaluated for each row.
+func (c *Constant) getDeferredDatum(row chunk.Row) (types.Datum, error) {
+	sf, ok := c.DeferredExpr.(*ScalarFunction)
+	if !ok || !c.isDeferredExprStable() {
+		return c.DeferredExpr.Eval(row)
+	}
+	// The statement contexts are reused by the session, but each execution allocates a new task ID.
+	taskID := sf.GetCtx().GetSessionVars().StmtCtx.TaskID
+	if taskID == 0 {
+		return c.DeferredExpr.Eval(row)
+	}
+	if folded, ok := c.deferredFold.Load().(*deferredFoldResult); ok && folded.taskID == taskID {
+		return folded.val, nil
+	}
+	dt, err := c.DeferredExpr.Eval(row)
+	if err != nil {
+		return dt, err
+	}
+	c.deferredFold.Store(&deferredFoldResult{taskID: taskID, val: dt})
+	return dt, nil
+}
+
+func (c *Constant) isDeferredExprStable() bool {
+	switch atomic.LoadInt32(&c.deferredStable) {
+	case 1:
+		return true
+	case 2:
+		return false
+	}
+	stable := isStableInExecution(c.DeferredExpr)
+	if stable {
+		atomic.StoreInt32(&c.deferredStable, 1)
+	} else {
+		atomic.StoreInt32(&c.deferredStable, 2)
+	}
+	return stable
+}
+
+// isStableInExecution checks whether expr evaluates to the same value during one execution of a statement.
+func isStableInExecution(expr Expression) bool {
+	switch x := expr.(type) {
+	case *Constant:
+		return x.DeferredExpr == nil || isStableInExecution(x.DeferredExpr)
+	case *ScalarFunction:
+		if _, ok := unFoldableFunctions[x.FuncName.L]; ok || x.FuncName.L == ast.RandomBytes {
+			return false
+		}
+		for _, arg := range x.GetArgs() {
+			if !isStableInExecution(arg) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
 // Eval implements Expression interface.
 func (c *Constant) Eval(row chunk.Row) (types.Datum, error) {
 	if dt, lazy, err := c.getLazyDatum(row); lazy {
@@ -414,7 +486,7 @@ func (c *Constant) resolveIndicesByVirtualExpr(_ *Schema) bool {
 
 // Vectorized returns if this expression supports vectorized evaluation.
 func (c *Constant) Vectorized() bool {
-	if c.DeferredExpr != nil {
+	if c.DeferredExpr != nil && !c.isDeferredExprStable() {
 		return c.DeferredExpr.Vectorized()
 	}
 	return true
@@ -423,6 +423,64 @@ func TestDeferredExprNotNull(t *testing.T) {
 	require.Equal(t, cst.DeferredExpr, cln.DeferredExpr)
 }
 
+func TestDeferredExprFoldedPerExecution(t *testing.T) {
+	ctx := mock.NewContext()
+	ctx.GetSessionVars().PreparedParams = []types.Datum{types.NewIntDatum(1)}
+	param := &Constant{ParamMarker: &ParamMarker{ctx: ctx, order: 0}, RetType: newIntFieldType()}
+	plus, err := NewFunctionBase(ctx, ast.Plus, newIntFieldType(), param, NewOne())
+	require.NoError(t, err)
+	cst := &Constant{DeferredExpr: plus, RetType: newIntFieldType()}
+	require.True(t, cst.Vectorized())
+
+	ctx.GetSessionVars().StmtCtx.TaskID = 1
+	v, _, err := cst.EvalInt(ctx, chunk.Row{})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), v)
+	// The folded value is reused during the execution.
+	ctx.GetSessionVars().PreparedParams[0] = types.NewIntDatum(10)
+	v, _, err = cst.EvalInt(ctx, chunk.Row{})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), v)
+	// The next execution folds the expression again.
+	ctx.GetSessionVars().StmtCtx.TaskID = 2
+	input := chunk.New([]*types.FieldType{newIntFieldType()}, 3, 3)
+	for i := 0; i < 3; i++ {
+		input.AppendInt64(0, int64(i))
+	}
+	col := chunk.NewColumn(newIntFieldType(), 3)
+	require.NoError(t, cst.VecEvalInt(ctx, input, col))
+	require.Equal(t, []int64{11, 11, 11}, col.Int64s())
+
+	// The non-deterministic expressions are evaluated for each row.
+	randomBytes, err := NewFunctionBase(ctx, ast.RandomBytes, newBinaryLiteralFieldType(), param)
+	require.NoError(t, err)
+	cst = &Constant{DeferredExpr: randomBytes, RetType: newBinaryLiteralFieldType()}
+	require.False(t, cst.isDeferredExprStable())
+	require.True(t, isStableInExecution(plus))
+}
+
+func BenchmarkDeferredExprEval(b *testing.B) {
+	ctx := mock.NewContext()
+	ctx.GetSessionVars().PreparedParams = []types.Datum{types.NewIntDatum(1)}
+	ctx.GetSessionVars().StmtCtx.TaskID = 1
+	param := &Constant{ParamMarker: &ParamMarker{ctx: ctx, order: 0}, RetType: newIntFieldType()}
+	mul, err := NewFunctionBase(ctx, ast.Mul, newIntFieldType(), param, NewOne())
+	if err != nil {
+		b.Fatal(err)
+	}
+	plus, err := NewFunctionBase(ctx, ast.Plus, newIntFieldType(), mul, NewOne())
+	if err != nil {
+		b.Fatal(err)
+	}
+	cst := &Constant{DeferredExpr: plus, RetType: newIntFieldType()}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := cst.EvalInt(ctx, chunk.Row{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestVectorizedConstant(t *testing.T) {
 	// fixed-length type with/without Sel
 	for _, cst := range []*Constant{
@@ -0,0 +1,224 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/collate"
+)
+
+// fusedCmp is a comparison between a column and a constant, which is normalized to `column op constant`.
+type fusedCmp struct {
+	colIdx   int
+	op       opcode.Op
+	isString bool
+	collator collate.Collator
+	con      *Constant
+}
+
+// FusedFilter evaluates a conjunction of comparisons between columns and constants, such as
+// `a > ? and a < ? and b = 'x'`, by specialized loops over the chunk columns. It avoids the
+// temporary columns and the per-function dispatch of the generic vectorized evaluation.
+type FusedFilter struct {
+	cmps []fusedCmp
+}
+
+// FuseFilters builds a FusedFilter for the filters. It returns nil if any of the filters is not
+// a comparison between an integer or string column and a constant.
+func FuseFilters(filters []Expression) *FusedFilter {
+	if len(filters) == 0 {
+		return nil
+	}
+	cmps := make([]fusedCmp, 0, len(filters))
+	for _, filter := range filters {
+		cmp, ok := fuseComparison(filter)
+		if !ok {
+			return nil
+		}
+		cmps = append(cmps, cmp)
+	}
+	return &FusedFilter{cmps: cmps}
+}
+
+func fuseComparison(expr Expression) (fusedCmp, bool) {
+	sf, ok := expr.(*ScalarFunction)
+	if !ok {
+		return fusedCmp{}, false
+	}
+	var cmp fusedCmp
+	switch sf.Function.(type) {
+	case *builtinLTIntSig, *builtinLTStringSig:
+		cmp.op = opcode.LT
+	case *builtinLEIntSig, *builtinLEStringSig:
+		cmp.op = opcode.LE
+	case *builtinGTIntSig, *builtinGTStringSig:
+		cmp.op = opcode.GT
+	case *builtinGEIntSig, *builtinGEStringSig:
+		cmp.op = opcode.GE
+	case *builtinEQIntSig, *builtinEQStringSig:
+		cmp.op = opcode.EQ
+	case *builtinNEIntSig, *builtinNEStringSig:
+		cmp.op = opcode.NE
+	default:
+		return fusedCmp{}, false
+	}
+	switch sf.Function.(type) {
+	case *builtinLTStringSig, *builtinLEStringSig, *builtinGTStringSig, *builtinGEStringSig, *builtinEQStringSig, *builtinNEStringSig:
+		cmp.isString = true
+		_, coll := sf.Function.CharsetAndCollation()
+		cmp.collator = collate.GetCollator(coll)
+	}
+	args := sf.GetArgs()
+	col, isCol := args[0].(*Column)
+	con, isCon := args[1].(*Constant)
+	if !isCol || !isCon {
+		col, isCol = args[1].(*Column)
+		con, isCon = args[0].(*Constant)
+		if !isCol || !isCon {
+			return fusedCmp{}, false
+		}
+		cmp.op = symmetricOp[cmp.op]
+	}
+	tp := col.GetType()
+	if tp.Hybrid() {
+		return fusedCmp{}, false
+	}
+	if cmp.isString {
+		if tp.EvalType() != types.ETString || con.GetType().EvalType() != types.ETString {
+			return fusedCmp{}, false
+		}
+	} else {
+		// The unsigned integers need the special comparison in CompareInt.
+		if tp.EvalType() != types.ETInt || mysql.HasUnsignedFlag(tp.Flag) || mysql.HasUnsignedFlag(con.GetType().Flag) {
+			return fusedCmp{}, false
+		}
+	}
+	cmp.colIdx = col.Index
+	cmp.con = con
+	return cmp, true
+}
+
+// Filter evaluates the filter on the rows of input, selected[i] is true if the i-th physical row
+// satisfies all the comparisons. The rows that are not in input.Sel() are not selected.
+func (f *FusedFilter) Filter(ctx sessionctx.Context, input *chunk.Chunk, selected []bool) ([]bool, error) {
+	sel := input.Sel()
+	if sel != nil {
+		defer input.SetSel(sel)
+		input.SetSel(nil)
+	}
+	n := input.NumRows()
+	selected = selected[:0]
+	for i := 0; i < n; i++ {
+		selected = append(selected, sel == nil)
+	}
+	if sel != nil {
+		for _, i := range sel {
+			selected[i] = true
+		}
+	}
+	for i := range f.cmps {
+		cmp := &f.cmps[i]
+		col := input.Column(cmp.colIdx)
+		// The constant may be a parameter of a cached plan, so it's evaluated for every chunk.
+		if cmp.isString {
+			v, isNull, err := cmp.con.EvalString(ctx, chunk.Row{})
+			if err != nil {
+				return nil, err
+			}
+			if isNull {
+				return clearSelected(selected), nil
+			}
+			filterString(col, cmp.op, v, cmp.collator, selected)
+		} else {
+			v, isNull, err := cmp.con.EvalInt(ctx, chunk.Row{})
+			if err != nil {
+				return nil, err
+			}
+			if isNull {
+				return clearSelected(selected), nil
+			}
+			filterInt(col, cmp.op, v, selected)
+		}
+	}
+	return selected, nil
+}
+
+func clearSelected(selected []bool) []bool {
+	for i := range selected {
+		selected[i] = false
+	}
+	return selected
+}
+
+func filterInt(col *chunk.Column, op opcode.Op, v int64, selected []bool) {
+	i64s := col.Int64s()
+	switch op {
+	case opcode.LT:
+		for i := range selected {
+			selected[i] = selected[i] && !col.IsNull(i) && i64s[i] < v
+		}
+	case opcode.LE:
+		for i := range selected {
+			selected[i] = selected[i] && !col.IsNull(i) && i64s[i] <= v
+		}
+	case opcode.GT:
+		for i := range selected {
+			selected[i] = selected[i] && !col.IsNull(i) && i64s[i] > v
+		}
+	case opcode.GE:
+		for i := range selected {
+			selected[i] = selected[i] && !col.IsNull(i) && i64s[i] >= v
+		}
+	case opcode.EQ:
+		for i := range selected {
+			selected[i] = selected[i] && !col.IsNull(i) && i64s[i] == v
+		}
+	case opcode.NE:
+		for i := range selected {
+			selected[i] = selected[i] && !col.IsNull(i) && i64s[i] != v
+		}
+	}
+}
+
+func filterString(col *chunk.Column, op opcode.Op, v string, collator collate.Collator, selected []bool) {
+	for i := range selected {
+		if !selected[i] {
+			continue
+		}
+		if col.IsNull(i) {
+			selected[i] = false
+			continue
+		}
+		res := collator.Compare(col.GetString(i), v)
+		switch op {
+		case opcode.LT:
+			selected[i] = res < 0
+		case opcode.LE:
+			selected[i] = res <= 0
+		case opcode.GT:
+			selected[i] = res > 0
+		case opcode.GE:
+			selected[i] = res >= 0
+		case opcode.EQ:
+			selected[i] = res == 0
+		case opcode.NE:
+			selected[i] = res != 0
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func fusedFilterTestColumns() (*Column, *Column) {
+	intCol := &Column{Index: 0, UniqueID: 1, RetType: types.NewFieldType(mysql.TypeLonglong)}
+	strCol := &Column{Index: 1, UniqueID: 2, RetType: types.NewFieldTypeWithCollation(mysql.TypeVarchar, "utf8mb4_bin", 255)}
+	return intCol, strCol
+}
+
+func fusedFilterTestChunk(n int) *chunk.Chunk {
+	intCol, strCol := fusedFilterTestColumns()
+	chk := chunk.NewChunkWithCapacity([]*types.FieldType{intCol.RetType, strCol.RetType}, n)
+	for i := 0; i < n; i++ {
+		if i%17 == 0 {
+			chk.AppendNull(0)
+		} else {
+			chk.AppendInt64(0, rand.Int63n(100)-50)
+		}
+		if i%13 == 0 {
+			chk.AppendNull(1)
+		} else {
+			chk.AppendString(1, fmt.Sprintf("s%02d", rand.Intn(20)))
+		}
+	}
+	return chk
+}
+
+func TestFuseFilters(t *testing.T) {
+	intCol, strCol := fusedFilterTestColumns()
+	unsignedCol := &Column{Index: 0, UniqueID: 3, RetType: types.NewFieldType(mysql.TypeLonglong)}
+	unsignedCol.RetType.Flag |= mysql.UnsignedFlag
+	realCol := &Column{Index: 0, UniqueID: 4, RetType: types.NewFieldType(mysql.TypeDouble)}
+
+	require.NotNil(t, FuseFilters([]Expression{
+		newFunctionWithType(ast.GT, types.NewFieldType(mysql.TypeLonglong), intCol, newLonglong(1)),
+		newFunctionWithType(ast.EQ, types.NewFieldType(mysql.TypeLonglong), strCol, newString("a", "utf8mb4_bin")),
+		newFunctionWithType(ast.LT, types.NewFieldType(mysql.TypeLonglong), newLonglong(1), intCol),
+	}))
+	require.Nil(t, FuseFilters(nil))
+	require.Nil(t, FuseFilters([]Expression{newFunctionWithType(ast.GT, types.NewFieldType(mysql.TypeLonglong), intCol, intCol)}))
+	require.Nil(t, FuseFilters([]Expression{newFunctionWithType(ast.GT, types.NewFieldType(mysql.TypeLonglong), unsignedCol, newLonglong(1))}))
+	require.Nil(t, FuseFilters([]Expression{newFunctionWithType(ast.GT, types.NewFieldType(mysql.TypeLonglong), realCol, newLonglong(1))}))
+	require.Nil(t, FuseFilters([]Expression{
+		newFunctionWithType(ast.GT, types.NewFieldType(mysql.TypeLonglong), intCol, newLonglong(1)),
+		newFunctionWithType(ast.LogicOr, types.NewFieldType(mysql.TypeLonglong), intCol, newLonglong(1)),
+	}))
+}
+
+func TestFusedFilterResult(t *testing.T) {
+	ctx := mock.NewContext()
+	intCol, strCol := fusedFilterTestColumns()
+	ops := []string{ast.LT, ast.LE, ast.GT, ast.GE, ast.EQ, ast.NE}
+	chk := fusedFilterTestChunk(1024)
+	sel := make([]int, 0, chk.NumRows())
+	for i := 0; i < chk.NumRows(); i += 3 {
+		sel = append(sel, i)
+	}
+	for _, intOp := range ops {
+		for _, strOp := range ops {
+			filters := []Expression{
+				newFunctionWithType(intOp, types.NewFieldType(mysql.TypeLonglong), intCol, newLonglong(rand.Int63n(100)-50)),
+				newFunctionWithType(strOp, types.NewFieldType(mysql.TypeLonglong), newString(fmt.Sprintf("s%02d", rand.Intn(20)), "utf8mb4_bin"), strCol),
+			}
+			fused := FuseFilters(filters)
+			require.NotNil(t, fused)
+			for _, s := range [][]int{nil, sel} {
+				chk.SetSel(s)
+				expected, err := VectorizedFilter(ctx, filters, chunk.NewIterator4Chunk(chk), nil)
+				require.NoError(t, err)
+				selected, err := fused.Filter(ctx, chk, nil)
+				require.NoError(t, err)
+				require.Equal(t, expected, selected, "%s %s", filters[0], filters[1])
+				require.Equal(t, s, chk.Sel())
+			}
+			chk.SetSel(nil)
+		}
+	}
+
+	// The comparison with NULL selects nothing.
+	nullCon := &Constant{Value: types.NewDatum(nil), RetType: types.NewFieldType(mysql.TypeLonglong)}
+	fused := FuseFilters([]Expression{newFunctionWithType(ast.NE, types.NewFieldType(mysql.TypeLonglong), intCol, nullCon)})
+	require.NotNil(t, fused)
+	selected, err := fused.Filter(ctx, chk, nil)
+	require.NoError(t, err)
+	require.Len(t, selected, chk.NumRows())
+	require.NotContains(t, selected, true)
+}
+
+func BenchmarkFusedFilter(b *testing.B) {
+	ctx := mock.NewContext()
+	intCol, strCol := fusedFilterTestColumns()
+	filters := []Expression{
+		newFunctionWithType(ast.GT, types.NewFieldType(mysql.TypeLonglong), intCol, newLonglong(-20)),
+		newFunctionWithType(ast.LT, types.NewFieldType(mysql.TypeLonglong), intCol, newLonglong(20)),
+		newFunctionWithType(ast.EQ, types.NewFieldType(mysql.TypeLonglong), strCol, newString("s01", "utf8mb4_bin")),
+	}
+	chk := fusedFilterTestChunk(1024)
+	selected := make([]bool, 0, chk.NumRows())
+
+	b.Run("vectorized", func(b *testing.B) {
+		it := chunk.NewIterator4Chunk(chk)
+		var err error
+		for i := 0; i < b.N; i++ {
+			selected, err = VectorizedFilter(ctx, filters, it, selected)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("fused", func(b *testing.B) {
+		fused := FuseFilters(filters)
+		var err error
+		for i := 0; i < b.N; i++ {
+			selected, err = fused.Filter(ctx, chk, selected)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
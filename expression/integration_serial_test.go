@@ -104,7 +104,22 @@ func TestIssue31174(t *testing.T) {
 	// The 'like' condition can not be used to construct the range.
 	tk.HasPlan("select * from t where a like '`%';", "TableFullScan")
 	tk.MustQuery("select * from t where a like '`%';").Check(testkit.Rows("`?"))
+	// The range of the 'like' condition is built on the sort keys with the fix 738.
+	tk.MustExec("set @@tidb_opt_fix_control = '738:ON'")
+	tk.HasPlan("select * from t where a like '`%';", "TableRangeScan")
+	tk.MustQuery("select * from t where a like '`%';").Check(testkit.Rows("`?"))
 
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a varchar(10) collate utf8mb4_general_ci, key(a(2)));")
+	tk.MustExec("insert into t values('abc'), ('ABD'), ('aB'), ('ab '), ('AB_'), ('`b'), ('b');")
+	tk.HasPlan("select * from t use index(a) where a like 'ab%';", "IndexRangeScan")
+	tk.MustQuery("select * from t use index(a) where a like 'ab%';").Sort().Check(testkit.Rows("ABD", "AB_", "aB", "ab ", "abc"))
+	tk.MustQuery("select * from t use index(a) where a like 'ab_';").Sort().Check(testkit.Rows("ABD", "AB_", "ab ", "abc"))
+	tk.MustQuery("select * from t use index(a) where a like 'ab';").Check(testkit.Rows("aB"))
+	tk.MustQuery("select * from t use index(a) where a like 'ab\\_';").Check(testkit.Rows("AB_"))
+	tk.MustQuery("select * from t use index(a) where a like '`%' or a > 'abc';").Sort().Check(testkit.Rows("ABD", "AB_", "`b", "b"))
+
+	tk.MustExec("set @@tidb_opt_fix_control = ''")
 	tk.MustExec("drop table if exists t")
 	tk.MustExec("create table t(a char(4) collate binary primary key /*T![clustered_index] clustered */);")
 	tk.MustExec("insert into t values('`?');")
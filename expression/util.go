@@ -882,6 +882,12 @@ func IsMutableEffectsExpr(expr Expression) bool {
 	return false
 }
 
+// IsMutableEffectsFunction checks if the function of the name is mutable or has side effects.
+func IsMutableEffectsFunction(name string) bool {
+	_, ok := mutableEffectsFunctions[strings.ToLower(name)]
+	return ok
+}
+
 // RemoveDupExprs removes identical exprs. Not that if expr contains functions which
 // are mutable or have side effects, we cannot remove it even if it has duplicates;
 // if the plan is going to be cached, we cannot remove expressions containing `?` neither.
@@ -44,6 +44,18 @@ const (
 	ClusterTableTiDBTrx = "CLUSTER_TIDB_TRX"
 	// ClusterTableDeadlocks is the string constant of cluster dead lock table.
 	ClusterTableDeadlocks = "CLUSTER_DEADLOCKS"
+	// ClusterTablePlanCache is the string constant of cluster prepared plan cache table.
+	ClusterTablePlanCache = "CLUSTER_PLAN_CACHE"
+	// ClusterTableBindingCache is the string constant of cluster global binding cache table.
+	ClusterTableBindingCache = "CLUSTER_BINDING_CACHE"
+	// ClusterTableTableWorkload is the string constant of cluster table workload table.
+	ClusterTableTableWorkload = "CLUSTER_TABLE_WORKLOAD"
+	// ClusterTableIndexUsage is the string constant of cluster index usage table.
+	ClusterTableIndexUsage = "CLUSTER_INDEX_USAGE"
+	// ClusterTableDDLBlockers is the string constant of cluster DDL blockers table.
+	ClusterTableDDLBlockers = "CLUSTER_DDL_BLOCKERS"
+	// ClusterTablePlanHistory is the string constant of cluster plan history table.
+	ClusterTablePlanHistory = "CLUSTER_PLAN_HISTORY"
 )
 
 // memTableToClusterTables means add memory table to cluster table.
@@ -55,6 +67,12 @@ var memTableToClusterTables = map[string]string{
 	TableStatementsSummaryEvicted: ClusterTableStatementsSummaryEvicted,
 	TableTiDBTrx:                  ClusterTableTiDBTrx,
 	TableDeadlocks:                ClusterTableDeadlocks,
+	TablePlanCache:                ClusterTablePlanCache,
+	TableBindingCache:             ClusterTableBindingCache,
+	TableTableWorkload:            ClusterTableTableWorkload,
+	TableIndexUsage:               ClusterTableIndexUsage,
+	TableDDLBlockers:              ClusterTableDDLBlockers,
+	TablePlanHistory:              ClusterTablePlanHistory,
 }
 
 func init() {
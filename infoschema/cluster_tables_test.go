@@ -34,6 +34,7 @@ import (
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/parser/auth"
 	"github.com/pingcap/tidb/parser/mysql"
+	plannercore "github.com/pingcap/tidb/planner/core"
 	"github.com/pingcap/tidb/server"
 	"github.com/pingcap/tidb/store/helper"
 	"github.com/pingcap/tidb/store/mockstore/mockstorage"
@@ -42,6 +43,7 @@ import (
 	"github.com/pingcap/tidb/util/pdapi"
 	"github.com/pingcap/tidb/util/resourcegrouptag"
 	"github.com/pingcap/tidb/util/set"
+	"github.com/pingcap/tidb/util/tableworkload"
 	"github.com/pingcap/tidb/util/testutil"
 	"github.com/pingcap/tipb/go-tipb"
 	"github.com/stretchr/testify/require"
@@ -601,6 +603,128 @@ func TestStmtSummaryResultRows(t *testing.T) {
 		Check(testkit.Rows("10 30 20"))
 }
 
+func TestPlanCacheAndBindingCacheTable(t *testing.T) {
+	var clean func()
+	s := new(clusterTablesSuite)
+	s.store, s.dom, clean = testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+	s.rpcserver, s.listenAddr = s.setUpRPCService(t, "127.0.0.1:0")
+	s.httpServer, s.mockAddr = s.setUpMockPDHTTPServer()
+	defer s.httpServer.Close()
+	defer s.rpcserver.Stop()
+
+	tk := s.newTestKitWithRoot(t)
+	tk.MustExec("create table t(a int, key(a))")
+	tk.MustExec("create global binding for select * from t where a = 1 using select * from t use index(a) where a = 1")
+	tk.MustExec("select * from t where a = 2")
+	tk.MustQuery("select instance != '', original_sql, hit_count from information_schema.cluster_binding_cache").Check(
+		testkit.Rows("1 select * from `test` . `t` where `a` = ? 1"))
+
+	user := testkit.NewTestKit(t, s.store)
+	tk.MustExec("create user 'plan_cache_user'@'localhost'")
+	require.True(t, user.Session().Auth(&auth.UserIdentity{Username: "plan_cache_user", Hostname: "localhost"}, nil, nil))
+	err := user.QueryToErr("select * from information_schema.binding_cache")
+	require.EqualError(t, err, "[planner:1227]Access denied; you need (at least one of) the PROCESS privilege(s) for this operation")
+
+	orgEnable := plannercore.PreparedPlanCacheEnabled()
+	defer plannercore.SetPreparedPlanCache(orgEnable)
+	plannercore.SetPreparedPlanCache(true)
+	tk = s.newTestKitWithRoot(t)
+	tk.MustExec("prepare stmt from 'select * from t where a = ?'")
+	tk.MustExec("set @a = 1")
+	tk.MustExec("execute stmt using @a")
+	tk.MustExec("execute stmt using @a")
+	tk.MustQuery("select instance != '', digest_text, hit_count from information_schema.cluster_plan_cache where connection_id = connection_id()").Check(
+		testkit.Rows("1 select * from `t` where `a` = ? 1"))
+	// The users without the PROCESS privilege can only see the plans cached by themselves.
+	connID := tk.Session().GetSessionVars().ConnectionID
+	user.MustQuery(fmt.Sprintf("select count(*) from information_schema.plan_cache where connection_id = %d", connID)).Check(testkit.Rows("0"))
+}
+
+func TestTableWorkloadTable(t *testing.T) {
+	var clean func()
+	s := new(clusterTablesSuite)
+	s.store, s.dom, clean = testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+	s.rpcserver, s.listenAddr = s.setUpRPCService(t, "127.0.0.1:0")
+	s.httpServer, s.mockAddr = s.setUpMockPDHTTPServer()
+	defer s.httpServer.Close()
+	defer s.rpcserver.Stop()
+	tableworkload.Reset()
+	defer tableworkload.Reset()
+
+	tk := s.newTestKitWithRoot(t)
+	tk.MustExec("create table t(a int, b int, key(a), key(b))")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3)")
+	tk.MustExec("update t set b = 4 where b = 3")
+	tk.MustQuery("select * from t where a = 1")
+	tk.MustQuery("select a from t use index(a) where a > 1")
+	tk.MustQuery("select count(*) from t ignore index(a, b)")
+	tk.MustQuery("select instance != '', table_name, read_count, cop_time > 0, write_count, write_rows, hot_index, hot_index_read_count, " +
+		"index_usage, last_read_time is not null from information_schema.cluster_table_workload where table_schema = 'test'").Check(
+		testkit.Rows(`1 t 4 1 2 4 a 2 {"a": 2, "b": 1} 1`))
+
+	// The partitions are counted separately.
+	tk.MustExec("create table p(a int) partition by hash(a) partitions 2")
+	tk.MustExec("insert into p values (1), (3)")
+	tk.MustQuery("select partition_name, read_count, write_rows from information_schema.table_workload where table_name = 'p'").Check(
+		testkit.Rows("p1 0 2"))
+
+	// The dropped tables are not shown.
+	tk.MustExec("drop table t")
+	tk.MustQuery("select count(*) from information_schema.table_workload where table_name = 't'").Check(testkit.Rows("0"))
+
+	// The tables without privileges are not shown.
+	tk.MustExec("create user 'table_workload_user'@'localhost'")
+	user := testkit.NewTestKit(t, s.store)
+	require.True(t, user.Session().Auth(&auth.UserIdentity{Username: "table_workload_user", Hostname: "localhost"}, nil, nil))
+	user.MustQuery("select count(*) from information_schema.table_workload where table_name = 'p'").Check(testkit.Rows("0"))
+}
+
+func TestIndexUsageTable(t *testing.T) {
+	var clean func()
+	s := new(clusterTablesSuite)
+	s.store, s.dom, clean = testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+	s.rpcserver, s.listenAddr = s.setUpRPCService(t, "127.0.0.1:0")
+	s.httpServer, s.mockAddr = s.setUpMockPDHTTPServer()
+	defer s.httpServer.Close()
+	defer s.rpcserver.Stop()
+	tableworkload.Reset()
+	defer tableworkload.Reset()
+
+	tk := s.newTestKitWithRoot(t)
+	tk.MustExec("create table t(a int, b int, c int, key(a), key(b), key(c))")
+	tk.MustExec("insert into t values (1, 1, 1), (2, 2, 2)")
+	tk.MustQuery("select a from t use index(a) where a > 1")
+	tk.MustQuery("select a from t use index(a) where a < 1")
+	tk.MustQuery("select instance != '', index_name, query_count, last_used_at is not null from information_schema.cluster_index_usage " +
+		"where table_schema = 'test' and table_name = 't' order by index_name").Check(
+		testkit.Rows("1 a 2 1", "1 b 0 0", "1 c 0 0"))
+
+	// The last used time persisted before the restarts is used if there are no later usages.
+	tk.MustExec("insert into mysql.schema_index_usage values (" +
+		"(select tidb_table_id from information_schema.tables where table_schema = 'test' and table_name = 't'), " +
+		"(select index_id from information_schema.tidb_indexes where table_schema = 'test' and table_name = 't' and key_name = 'b'), " +
+		"5, 10, '2022-01-02 03:04:05')")
+	tk.MustQuery("select index_name, query_count, last_used_at from information_schema.index_usage " +
+		"where table_schema = 'test' and table_name = 't' and index_name = 'b'").Check(
+		testkit.Rows("b 0 2022-01-02 03:04:05.000000"))
+
+	// The indexes of the partitions are summed up.
+	tk.MustExec("set @@tidb_partition_prune_mode = 'static'")
+	tk.MustExec("create table p(a int, key(a)) partition by hash(a) partitions 2")
+	tk.MustExec("insert into p values (1), (2)")
+	tk.MustQuery("select a from p use index(a) where a > 0")
+	tk.MustQuery("select query_count from information_schema.index_usage where table_name = 'p'").Check(testkit.Rows("2"))
+
+	// The tables without privileges are not shown.
+	tk.MustExec("create user 'index_usage_user'@'localhost'")
+	user := testkit.NewTestKit(t, s.store)
+	require.True(t, user.Session().Auth(&auth.UserIdentity{Username: "index_usage_user", Hostname: "localhost"}, nil, nil))
+	user.MustQuery("select count(*) from information_schema.index_usage where table_name = 't'").Check(testkit.Rows("0"))
+}
+
 func (s *clusterTablesSuite) setUpRPCService(t *testing.T, addr string) (*grpc.Server, string) {
 	lis, err := net.Listen("tcp", addr)
 	require.NoError(t, err)
@@ -0,0 +1,101 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infoschema
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pingcap/tidb/types"
+)
+
+// MemTableMockProvider provides the synthetic rows of the memory tables, so the tests can simulate the states
+// of the cluster, like the stores of TiKV or the replicas of TiFlash. It must not be used in production.
+type MemTableMockProvider interface {
+	// MockRows returns the rows of the table with the values of its columns in order, the columns missing
+	// at the end of a row are NULL. ok is false if the table is not mocked.
+	MockRows(tableName string) (rows [][]types.Datum, ok bool)
+}
+
+var memTableMockProviders struct {
+	sync.RWMutex
+	// providers holds the pointers to the providers, so a provider is removed even if it's not comparable.
+	providers []*MemTableMockProvider
+}
+
+// SetMemTableMockProvider makes the memory tables mocked by the provider return its rows instead of the real
+// ones. The latest provider wins if several ones mock the same table. The returned function removes the provider.
+func SetMemTableMockProvider(provider MemTableMockProvider) (restore func()) {
+	memTableMockProviders.Lock()
+	defer memTableMockProviders.Unlock()
+	entry := &provider
+	memTableMockProviders.providers = append(memTableMockProviders.providers, entry)
+	return func() {
+		memTableMockProviders.Lock()
+		defer memTableMockProviders.Unlock()
+		providers := memTableMockProviders.providers
+		for i := len(providers) - 1; i >= 0; i-- {
+			if providers[i] == entry {
+				memTableMockProviders.providers = append(providers[:i:i], providers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// GetMemTableMockRows returns the mocked rows of the memory table, ok is false if the table is not mocked.
+func GetMemTableMockRows(tableName string) (rows [][]types.Datum, ok bool) {
+	memTableMockProviders.RLock()
+	defer memTableMockProviders.RUnlock()
+	for i := len(memTableMockProviders.providers) - 1; i >= 0; i-- {
+		if rows, ok = (*memTableMockProviders.providers[i]).MockRows(tableName); ok {
+			return rows, true
+		}
+	}
+	return nil, false
+}
+
+// MemTableMock is a MemTableMockProvider returning the fixed rows of the tables.
+type MemTableMock struct {
+	mu   sync.RWMutex
+	rows map[string][][]types.Datum
+}
+
+// NewMemTableMock creates a MemTableMock mocking no tables.
+func NewMemTableMock() *MemTableMock {
+	return &MemTableMock{rows: make(map[string][][]types.Datum)}
+}
+
+// SetRows mocks the table with the rows, every row has the values of the columns of the table in order.
+func (m *MemTableMock) SetRows(tableName string, rows ...[]interface{}) {
+	datums := make([][]types.Datum, 0, len(rows))
+	for _, row := range rows {
+		datums = append(datums, types.MakeDatums(row...))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rows[strings.ToUpper(tableName)] = datums
+}
+
+// MockRows implements the MemTableMockProvider interface.
+func (m *MemTableMock) MockRows(tableName string) ([][]types.Datum, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	rows, ok := m.rows[strings.ToUpper(tableName)]
+	if !ok {
+		return nil, false
+	}
+	return append([][]types.Datum(nil), rows...), true
+}
@@ -178,6 +178,18 @@ const (
 	TableAttributes = "ATTRIBUTES"
 	// TablePlacementPolicies is the string constant of placement policies table.
 	TablePlacementPolicies = "PLACEMENT_POLICIES"
+	// TablePlanCache is the string constant of prepared plan cache table.
+	TablePlanCache = "PLAN_CACHE"
+	// TableBindingCache is the string constant of global binding cache table.
+	TableBindingCache = "BINDING_CACHE"
+	// TableTableWorkload is the string constant of table workload table.
+	TableTableWorkload = "TABLE_WORKLOAD"
+	// TableIndexUsage is the string constant of index usage table.
+	TableIndexUsage = "INDEX_USAGE"
+	// TableDDLBlockers is the string constant of DDL blockers table.
+	TableDDLBlockers = "DDL_BLOCKERS"
+	// TablePlanHistory is the string constant of plan history table.
+	TablePlanHistory = "PLAN_HISTORY"
 )
 
 const (
@@ -276,6 +288,18 @@ var tableIDMap = map[string]int64{
 	TableAttributes:                      autoid.InformationSchemaDBID + 77,
 	TableTiDBHotRegionsHistory:           autoid.InformationSchemaDBID + 78,
 	TablePlacementPolicies:               autoid.InformationSchemaDBID + 79,
+	TablePlanCache:                       autoid.InformationSchemaDBID + 80,
+	ClusterTablePlanCache:                autoid.InformationSchemaDBID + 81,
+	TableBindingCache:                    autoid.InformationSchemaDBID + 82,
+	ClusterTableBindingCache:             autoid.InformationSchemaDBID + 83,
+	TableTableWorkload:                   autoid.InformationSchemaDBID + 84,
+	ClusterTableTableWorkload:            autoid.InformationSchemaDBID + 85,
+	TableIndexUsage:                      autoid.InformationSchemaDBID + 86,
+	ClusterTableIndexUsage:               autoid.InformationSchemaDBID + 87,
+	TableDDLBlockers:                     autoid.InformationSchemaDBID + 88,
+	ClusterTableDDLBlockers:              autoid.InformationSchemaDBID + 89,
+	TablePlanHistory:                     autoid.InformationSchemaDBID + 90,
+	ClusterTablePlanHistory:              autoid.InformationSchemaDBID + 91,
 }
 
 type columnInfo struct {
@@ -807,6 +831,7 @@ var slowQueryCols = []columnInfo{
 	{name: variable.SlowLogUserStr, tp: mysql.TypeVarchar, size: 64},
 	{name: variable.SlowLogHostStr, tp: mysql.TypeVarchar, size: 64},
 	{name: variable.SlowLogConnIDStr, tp: mysql.TypeLonglong, size: 20, flag: mysql.UnsignedFlag},
+	{name: variable.SlowLogTraceIDStr, tp: mysql.TypeLonglong, size: 20, flag: mysql.UnsignedFlag},
 	{name: variable.SlowLogExecRetryCount, tp: mysql.TypeLonglong, size: 20, flag: mysql.UnsignedFlag},
 	{name: variable.SlowLogExecRetryTime, tp: mysql.TypeDouble, size: 22},
 	{name: variable.SlowLogQueryTimeStr, tp: mysql.TypeDouble, size: 22},
@@ -861,6 +886,8 @@ var slowQueryCols = []columnInfo{
 	{name: variable.SlowLogPDTotal, tp: mysql.TypeDouble, size: 22},
 	{name: variable.SlowLogBackoffTotal, tp: mysql.TypeDouble, size: 22},
 	{name: variable.SlowLogWriteSQLRespTotal, tp: mysql.TypeDouble, size: 22},
+	{name: variable.SlowLogClientStallTotal, tp: mysql.TypeDouble, size: 22},
+	{name: variable.SlowLogTiDBExecTime, tp: mysql.TypeDouble, size: 22},
 	{name: variable.SlowLogResultRows, tp: mysql.TypeLonglong, size: 22},
 	{name: variable.SlowLogBackoffDetail, tp: mysql.TypeVarchar, size: 4096},
 	{name: variable.SlowLogPrepared, tp: mysql.TypeTiny, size: 1},
@@ -1259,6 +1286,10 @@ var tableStatementsSummaryCols = []columnInfo{
 	{name: stmtsummary.AvgPdTimeStr, tp: mysql.TypeLonglong, size: 22, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Average time of PD used"},
 	{name: stmtsummary.AvgBackoffTotalTimeStr, tp: mysql.TypeLonglong, size: 22, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Average time of Backoff used"},
 	{name: stmtsummary.AvgWriteSQLRespTimeStr, tp: mysql.TypeLonglong, size: 22, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Average time of write sql resp used"},
+	{name: stmtsummary.AvgClientStallTimeStr, tp: mysql.TypeLonglong, size: 22, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Average time blocked in writing the response, waiting for the client to read it"},
+	{name: stmtsummary.MaxClientStallTimeStr, tp: mysql.TypeLonglong, size: 22, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Max time blocked in writing the response, waiting for the client to read it"},
+	{name: stmtsummary.AvgTiDBExecTimeStr, tp: mysql.TypeLonglong, size: 22, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Average time of TiDB executing, excluding the time waiting for TiKV, PD, backoff and writing the response"},
+	{name: stmtsummary.MaxTiDBExecTimeStr, tp: mysql.TypeLonglong, size: 22, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Max time of TiDB executing, excluding the time waiting for TiKV, PD, backoff and writing the response"},
 	{name: stmtsummary.MaxResultRowsStr, tp: mysql.TypeLonglong, size: 22, flag: mysql.NotNullFlag, comment: "Max count of sql result rows"},
 	{name: stmtsummary.MinResultRowsStr, tp: mysql.TypeLonglong, size: 22, flag: mysql.NotNullFlag, comment: "Min count of sql result rows"},
 	{name: stmtsummary.AvgResultRowsStr, tp: mysql.TypeLonglong, size: 22, flag: mysql.NotNullFlag, comment: "Average count of sql result rows"},
@@ -1464,6 +1495,89 @@ var tablePlacementPoliciesCols = []columnInfo{
 	{name: "LEARNERS", tp: mysql.TypeLonglong, size: 64},
 }
 
+var tablePlanCacheCols = []columnInfo{
+	{name: "CONNECTION_ID", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "The session which caches the plan"},
+	{name: "SCHEMA_NAME", tp: mysql.TypeVarchar, size: 64, comment: "The current schema when the plan is cached"},
+	{name: "DIGEST", tp: mysql.TypeVarchar, size: 64, comment: "Digest of the prepared statement"},
+	{name: "DIGEST_TEXT", tp: mysql.TypeBlob, size: types.UnspecifiedLength, comment: "The normalized prepared statement"},
+	{name: "PLAN_DIGEST", tp: mysql.TypeVarchar, size: 64, comment: "Digest of the cached plan"},
+	{name: "MEM_SIZE", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag, comment: "Estimated memory size of the cached plan in bytes"},
+	{name: "HIT_COUNT", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag, comment: "How many times the cached plan is used"},
+	{name: "CREATE_TIME", tp: mysql.TypeTimestamp, decimal: 6, size: 26, comment: "When the plan is cached"},
+	{name: "LAST_HIT_TIME", tp: mysql.TypeTimestamp, decimal: 6, size: 26, comment: "When the cached plan is used last time"},
+	{name: "EVICT_TIME", tp: mysql.TypeTimestamp, decimal: 6, size: 26, comment: "When the plan is evicted, NULL if it's still cached"},
+	{name: "EVICT_REASON", tp: mysql.TypeVarchar, size: 64, comment: "Why the plan is evicted"},
+}
+
+var tableBindingCacheCols = []columnInfo{
+	{name: "ORIGINAL_SQL", tp: mysql.TypeBlob, size: types.UnspecifiedLength, flag: mysql.NotNullFlag},
+	{name: "BIND_SQL", tp: mysql.TypeBlob, size: types.UnspecifiedLength, flag: mysql.NotNullFlag},
+	{name: "DEFAULT_DB", tp: mysql.TypeVarchar, size: 64},
+	{name: "STATUS", tp: mysql.TypeVarchar, size: 64},
+	{name: "SOURCE", tp: mysql.TypeVarchar, size: 10},
+	{name: "DIGEST", tp: mysql.TypeVarchar, size: 64, comment: "Digest of the original SQL"},
+	{name: "MEM_SIZE", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag, comment: "Estimated memory size of the binding in bytes"},
+	{name: "HIT_COUNT", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag, comment: "How many times the binding is used to build the plan"},
+	{name: "LAST_HIT_TIME", tp: mysql.TypeTimestamp, decimal: 6, size: 26, comment: "When the binding is used last time"},
+}
+
+var tableTableWorkloadCols = []columnInfo{
+	{name: "TABLE_SCHEMA", tp: mysql.TypeVarchar, size: 64},
+	{name: "TABLE_NAME", tp: mysql.TypeVarchar, size: 64},
+	{name: "PARTITION_NAME", tp: mysql.TypeVarchar, size: 64},
+	{name: "TABLE_ID", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag, comment: "The physical table ID, which is the partition ID for the partition"},
+	{name: "READ_COUNT", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag, comment: "How many times the table is read by the statements"},
+	{name: "SCAN_KEYS", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag, comment: "Total number of the keys scanned when reading the table"},
+	{name: "COP_TIME", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag, comment: "Total processing time of the coprocessor tasks reading the table in nanoseconds"},
+	{name: "WRITE_COUNT", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag, comment: "How many committed transactions write the table"},
+	{name: "WRITE_ROWS", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag, comment: "Total number of the rows written by the committed transactions"},
+	{name: "HOT_INDEX", tp: mysql.TypeVarchar, size: 64, comment: "The most read index of the table"},
+	{name: "HOT_INDEX_READ_COUNT", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag, comment: "How many times the most read index is read"},
+	{name: "INDEX_USAGE", tp: mysql.TypeJSON, size: 51, comment: "How many times each index is read, the unused indexes are missing"},
+	{name: "LAST_READ_TIME", tp: mysql.TypeTimestamp, decimal: 6, size: 26, comment: "When the table is read last time"},
+	{name: "LAST_WRITE_TIME", tp: mysql.TypeTimestamp, decimal: 6, size: 26, comment: "When the table is written last time"},
+}
+
+var tableIndexUsageCols = []columnInfo{
+	{name: "TABLE_SCHEMA", tp: mysql.TypeVarchar, size: 64},
+	{name: "TABLE_NAME", tp: mysql.TypeVarchar, size: 64},
+	{name: "INDEX_NAME", tp: mysql.TypeVarchar, size: 64},
+	{name: "INDEX_ID", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag},
+	{name: "QUERY_COUNT", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag, comment: "How many times the index is read by the statements since the instance starts"},
+	{name: "LAST_USED_AT", tp: mysql.TypeTimestamp, decimal: 6, size: 26, comment: "When the index is used last time, including the usage persisted before the restarts"},
+}
+
+var tableDDLBlockersCols = []columnInfo{
+	{name: "JOB_ID", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag},
+	{name: "DB_NAME", tp: mysql.TypeVarchar, size: 64},
+	{name: "TABLE_NAME", tp: mysql.TypeVarchar, size: 64},
+	{name: "JOB_TYPE", tp: mysql.TypeVarchar, size: 64},
+	{name: "SCHEMA_STATE", tp: mysql.TypeVarchar, size: 64},
+	{name: "QUERY", tp: mysql.TypeLongBlob, size: types.UnspecifiedLength, comment: "The statement of the DDL job"},
+	{name: "SESSION_ID", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "The connection ID of the blocking session"},
+	{name: "TXN_START", tp: mysql.TypeTimestamp, decimal: 6, size: 26, comment: "When the transaction of the blocking session is started"},
+	{name: "CURRENT_SQL", tp: mysql.TypeLongBlob, size: types.UnspecifiedLength, comment: "The statement the blocking session is running, NULL if it's idle"},
+}
+
+var tablePlanHistoryCols = []columnInfo{
+	{name: "SCHEMA_NAME", tp: mysql.TypeVarchar, size: 64, comment: "Current schema"},
+	{name: "DIGEST", tp: mysql.TypeVarchar, size: 64, flag: mysql.NotNullFlag},
+	{name: "DIGEST_TEXT", tp: mysql.TypeBlob, size: types.UnspecifiedLength, flag: mysql.NotNullFlag, comment: "Normalized statement"},
+	{name: "PLAN_DIGEST", tp: mysql.TypeVarchar, size: 64, comment: "Digest of the plan"},
+	{name: "PLAN", tp: mysql.TypeBlob, size: types.UnspecifiedLength, comment: "The plan when it's seen first"},
+	{name: "FIRST_SEEN", tp: mysql.TypeTimestamp, size: 26, comment: "The first time the plan is used by the statement"},
+	{name: "LAST_SEEN", tp: mysql.TypeTimestamp, size: 26, comment: "The last time the plan is used by the statement"},
+	{name: "EXEC_COUNT", tp: mysql.TypeLonglong, size: 20, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Count of executions with the plan"},
+	{name: "SUM_ERRORS", tp: mysql.TypeLong, size: 11, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Sum of errors"},
+	{name: "SUM_LATENCY", tp: mysql.TypeLonglong, size: 20, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Sum latency of the executions"},
+	{name: "MAX_LATENCY", tp: mysql.TypeLonglong, size: 20, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Max latency of the executions"},
+	{name: "MIN_LATENCY", tp: mysql.TypeLonglong, size: 20, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Min latency of the executions"},
+	{name: "AVG_LATENCY", tp: mysql.TypeLonglong, size: 20, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Average latency of the executions"},
+	{name: "AVG_PROCESSED_KEYS", tp: mysql.TypeDouble, size: 22, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Average number of processed keys"},
+	{name: "AVG_RESULT_ROWS", tp: mysql.TypeDouble, size: 22, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Average number of the result rows"},
+	{name: "AVG_MEM", tp: mysql.TypeLonglong, size: 20, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Average memory(byte) used"},
+}
+
 // GetShardingInfo returns a nil or description string for the sharding information of given TableInfo.
 // The returned description string may be:
 //  - "NOT_SHARDED": for tables that SHARD_ROW_ID_BITS is not specified.
@@ -1834,6 +1948,12 @@ var tableNameToColumns = map[string][]columnInfo{
 	TableDataLockWaits:                      tableDataLockWaitsCols,
 	TableAttributes:                         tableAttributesCols,
 	TablePlacementPolicies:                  tablePlacementPoliciesCols,
+	TablePlanCache:                          tablePlanCacheCols,
+	TableBindingCache:                       tableBindingCacheCols,
+	TableTableWorkload:                      tableTableWorkloadCols,
+	TableIndexUsage:                         tableIndexUsageCols,
+	TableDDLBlockers:                        tableDDLBlockersCols,
+	TablePlanHistory:                        tablePlanHistoryCols,
 }
 
 func createInfoSchemaTable(_ autoid.Allocators, meta *model.TableInfo) (table.Table, error) {
@@ -25,6 +25,7 @@ import (
 
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/errno"
 	"github.com/pingcap/tidb/infoschema"
@@ -558,13 +559,13 @@ func TestSlowQuery(t *testing.T) {
 	tk.MustExec(fmt.Sprintf("set @@tidb_slow_query_file='%v'", slowLogFileName))
 	tk.MustExec("set time_zone = '+08:00';")
 	re := tk.MustQuery("select * from information_schema.slow_query")
-	re.Check(testutil.RowsWithSep("|", "2019-02-12 19:33:56.571953|406315658548871171|root|localhost|6|57|0.12|4.895492|0.4|0.2|0.000000003|2|0.000000002|0.00000001|0.000000003|0.19|0.21|0.01|0|0.18|[txnLock]|0.03|0|15|480|1|8|0.3824278|0.161|0.101|0.092|1.71|1|100001|100000|100|10|10|10|100|test||0|42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772|t1:1,t2:2|0.1|0.2|0.03|127.0.0.1:20160|0.05|0.6|0.8|0.0.0.0:20160|70724|65536|0|0|0|0|10||0|1|0|0|1|0|abcd|60e9378c746d9a2be1c791047e008967cf252eb6de9167ad3aa6098fa2d523f4|update t set i = 2;|select * from t_slim;",
-		"2021-09-08|14:39:54.506967|427578666238083075|root|172.16.0.0|40507|0|0|25.571605962|0.002923536|0.006800973|0.002100764|0|0|0|0.000015801|25.542014572|0|0.002294647|0.000605473|12.483|[tikvRPC regionMiss tikvRPC regionMiss regionMiss]|0|0|624|172064|60|0|0|0|0|0|0|0|0|0|0|0|0|0|0|rtdb||0|124acb3a0bec903176baca5f9da00b4e7512a41c93b417923f26502edeb324cc||0|0|0||0|0|0||856544|0|86.635049185|0.015486658|100.054|0|0||0|1|0|0|0|0||||INSERT INTO ...;",
+	re.Check(testutil.RowsWithSep("|", "2019-02-12 19:33:56.571953|406315658548871171|root|localhost|6|0|57|0.12|4.895492|0.4|0.2|0.000000003|2|0.000000002|0.00000001|0.000000003|0.19|0.21|0.01|0|0.18|[txnLock]|0.03|0|15|480|1|8|0.3824278|0.161|0.101|0.092|1.71|1|100001|100000|100|10|10|10|100|test||0|42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772|t1:1,t2:2|0.1|0.2|0.03|127.0.0.1:20160|0.05|0.6|0.8|0.0.0.0:20160|70724|65536|0|0|0|0|0|0|10||0|1|0|0|1|0|abcd|60e9378c746d9a2be1c791047e008967cf252eb6de9167ad3aa6098fa2d523f4|update t set i = 2;|select * from t_slim;",
+		"2021-09-08|14:39:54.506967|427578666238083075|root|172.16.0.0|40507|0|0|0|25.571605962|0.002923536|0.006800973|0.002100764|0|0|0|0.000015801|25.542014572|0|0.002294647|0.000605473|12.483|[tikvRPC regionMiss tikvRPC regionMiss regionMiss]|0|0|624|172064|60|0|0|0|0|0|0|0|0|0|0|0|0|0|0|rtdb||0|124acb3a0bec903176baca5f9da00b4e7512a41c93b417923f26502edeb324cc||0|0|0||0|0|0||856544|0|86.635049185|0.015486658|100.054|0|0|0|0||0|1|0|0|0|0||||INSERT INTO ...;",
 	))
 	tk.MustExec("set time_zone = '+00:00';")
 	re = tk.MustQuery("select * from information_schema.slow_query")
-	re.Check(testutil.RowsWithSep("|", "2019-02-12 11:33:56.571953|406315658548871171|root|localhost|6|57|0.12|4.895492|0.4|0.2|0.000000003|2|0.000000002|0.00000001|0.000000003|0.19|0.21|0.01|0|0.18|[txnLock]|0.03|0|15|480|1|8|0.3824278|0.161|0.101|0.092|1.71|1|100001|100000|100|10|10|10|100|test||0|42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772|t1:1,t2:2|0.1|0.2|0.03|127.0.0.1:20160|0.05|0.6|0.8|0.0.0.0:20160|70724|65536|0|0|0|0|10||0|1|0|0|1|0|abcd|60e9378c746d9a2be1c791047e008967cf252eb6de9167ad3aa6098fa2d523f4|update t set i = 2;|select * from t_slim;",
-		"2021-09-08|06:39:54.506967|427578666238083075|root|172.16.0.0|40507|0|0|25.571605962|0.002923536|0.006800973|0.002100764|0|0|0|0.000015801|25.542014572|0|0.002294647|0.000605473|12.483|[tikvRPC regionMiss tikvRPC regionMiss regionMiss]|0|0|624|172064|60|0|0|0|0|0|0|0|0|0|0|0|0|0|0|rtdb||0|124acb3a0bec903176baca5f9da00b4e7512a41c93b417923f26502edeb324cc||0|0|0||0|0|0||856544|0|86.635049185|0.015486658|100.054|0|0||0|1|0|0|0|0||||INSERT INTO ...;",
+	re.Check(testutil.RowsWithSep("|", "2019-02-12 11:33:56.571953|406315658548871171|root|localhost|6|0|57|0.12|4.895492|0.4|0.2|0.000000003|2|0.000000002|0.00000001|0.000000003|0.19|0.21|0.01|0|0.18|[txnLock]|0.03|0|15|480|1|8|0.3824278|0.161|0.101|0.092|1.71|1|100001|100000|100|10|10|10|100|test||0|42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772|t1:1,t2:2|0.1|0.2|0.03|127.0.0.1:20160|0.05|0.6|0.8|0.0.0.0:20160|70724|65536|0|0|0|0|0|0|10||0|1|0|0|1|0|abcd|60e9378c746d9a2be1c791047e008967cf252eb6de9167ad3aa6098fa2d523f4|update t set i = 2;|select * from t_slim;",
+		"2021-09-08|06:39:54.506967|427578666238083075|root|172.16.0.0|40507|0|0|0|25.571605962|0.002923536|0.006800973|0.002100764|0|0|0|0.000015801|25.542014572|0|0.002294647|0.000605473|12.483|[tikvRPC regionMiss tikvRPC regionMiss regionMiss]|0|0|624|172064|60|0|0|0|0|0|0|0|0|0|0|0|0|0|0|rtdb||0|124acb3a0bec903176baca5f9da00b4e7512a41c93b417923f26502edeb324cc||0|0|0||0|0|0||856544|0|86.635049185|0.015486658|100.054|0|0|0|0||0|1|0|0|0|0||||INSERT INTO ...;",
 	))
 
 	// Test for long query.
@@ -1388,6 +1389,100 @@ func TestTiDBTrx(t *testing.T) {
 		"[null,null,\"update `test_tidb_trx` set `i` = `i` + ?\"]"))
 }
 
+func TestPlanHistory(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk := newTestKitWithRoot(t, store)
+	tk.MustExec("create table t(a int, b int)")
+	tk.MustExec("insert into t values (1, 1), (2, 2)")
+	// Clear all statements.
+	tk.MustExec("set global tidb_enable_stmt_summary = 0")
+	tk.MustExec("set global tidb_enable_stmt_summary = 1")
+
+	tk.MustQuery("select b from t where a = 1").Check(testkit.Rows("1"))
+	tk.MustQuery("select b from t where a = 2").Check(testkit.Rows("2"))
+	tk.MustExec("alter table t add index idx(a)")
+	tk.MustQuery("select b from t where a = 1").Check(testkit.Rows("1"))
+
+	// The plan changes after the index is added.
+	sql := "select exec_count, plan like '%IndexLookUp%', first_seen <= last_seen from information_schema.plan_history " +
+		"where digest_text = 'select `b` from `t` where `a` = ?' order by first_seen, exec_count desc"
+	tk.MustQuery(sql).Check(testkit.Rows("2 0 1", "1 1 1"))
+	rows := tk.MustQuery("select distinct plan_digest from information_schema.plan_history where digest_text = 'select `b` from `t` where `a` = ?'").Rows()
+	require.Len(t, rows, 2)
+
+	tk.MustExec("set global tidb_stmt_summary_plan_history_size = 1")
+	defer tk.MustExec("set global tidb_stmt_summary_plan_history_size = default")
+	tk.MustQuery("select b from t where a = 2").Check(testkit.Rows("2"))
+	tk.MustQuery(sql).Check(testkit.Rows("2 1 1"))
+
+	// The plan history requires the PROCESS privilege.
+	tk.MustExec("create user 'plan_history'@'%'")
+	userTk := testkit.NewTestKit(t, store)
+	require.True(t, userTk.Session().Auth(&auth.UserIdentity{Username: "plan_history", Hostname: "%"}, nil, nil))
+	err := userTk.QueryToErr("select * from information_schema.plan_history")
+	require.EqualError(t, err, "[planner:1227]Access denied; you need (at least one of) the PROCESS privilege(s) for this operation")
+}
+
+type ddlBlockersCallback struct {
+	ddl.BaseCallback
+	onJobUpdated func(job *model.Job)
+}
+
+func (c *ddlBlockersCallback) OnJobUpdated(job *model.Job) {
+	c.onJobUpdated(job)
+}
+
+func TestDDLBlockers(t *testing.T) {
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+
+	tk := newTestKitWithRoot(t, store)
+	tk.MustExec("create table t1 (a int)")
+	tk.MustExec("create table t2 (a int)")
+	tk.MustExec("insert into t1 values (1)")
+
+	// The transaction reading t1 blocks the DDL on t1, but the one only writing t2 doesn't.
+	tk1 := newTestKitWithRoot(t, store)
+	tk1.RefreshConnectionID()
+	tk1.MustExec("begin")
+	tk1.MustQuery("select * from t1").Check(testkit.Rows("1"))
+	tk2 := newTestKitWithRoot(t, store)
+	tk2.RefreshConnectionID()
+	tk2.MustExec("begin")
+	tk2.MustExec("insert into t2 values (1)")
+
+	sm := &mockSessionManager{make(map[uint64]*util.ProcessInfo, 2), nil}
+	for _, se := range []session.Session{tk1.Session(), tk2.Session()} {
+		sm.processInfoMap[se.GetSessionVars().ConnectionID] = se.ShowProcess()
+		sm.txnInfo = append(sm.txnInfo, se.TxnInfo())
+	}
+	tkQuery := newTestKitWithRoot(t, store)
+	tkQuery.Session().SetSessionManager(sm)
+	tkQuery.MustQuery("select * from information_schema.ddl_blockers").Check(testkit.Rows())
+
+	// The job is read from the queue, which is updated after it starts running.
+	var result [][]interface{}
+	callback := &ddlBlockersCallback{}
+	callback.onJobUpdated = func(job *model.Job) {
+		if len(result) == 0 {
+			result = tkQuery.MustQuery("select db_name, table_name, job_type, query, session_id, current_sql from information_schema.ddl_blockers").Rows()
+		}
+	}
+	originalHook := dom.DDL().GetHook()
+	dom.DDL().SetHook(callback)
+	defer dom.DDL().SetHook(originalHook)
+	tk.MustExec("alter table t1 add column b int")
+	require.Equal(t, testkit.RowsWithSep("|", fmt.Sprintf("test|t1|add column|alter table t1 add column b int|%d|select * from t1",
+		tk1.Session().GetSessionVars().ConnectionID)), result)
+
+	// The finished jobs aren't blocked.
+	tkQuery.MustQuery("select * from information_schema.ddl_blockers").Check(testkit.Rows())
+	tk1.MustExec("rollback")
+	tk2.MustExec("rollback")
+}
+
 func TestInfoSchemaDeadlockPrivilege(t *testing.T) {
 	store, clean := testkit.CreateMockStore(t)
 	defer clean()
@@ -1443,3 +1538,33 @@ func TestReferentialConstraints(t *testing.T) {
 
 	tk.MustQuery(`SELECT * FROM information_schema.referential_constraints WHERE table_name='t2'`).Check(testkit.Rows("def referconstraints fk_to_t1 def referconstraints PRIMARY NONE NO ACTION NO ACTION t2 t1"))
 }
+
+func TestMemTableMock(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+
+	tk.MustQuery("select * from information_schema.tiflash_replica").Check(testkit.Rows())
+	restoreReplica := testkit.MockMemTable(infoschema.TableTiFlashReplica,
+		[]interface{}{"test", "t", 100, 2, "", 0, 0.5},
+		[]interface{}{"test", "t2", 101, 1, "", 1, 1.0},
+	)
+	tk.MustQuery("select table_name, available, progress from information_schema.tiflash_replica where table_id = 100").
+		Check(testkit.Rows("t 0 0.5"))
+	tk.MustQuery("select count(*) from information_schema.tiflash_replica").Check(testkit.Rows("2"))
+
+	// The latest mock wins, and the other tables are not affected.
+	mock := infoschema.NewMemTableMock()
+	mock.SetRows(infoschema.TableTiFlashReplica)
+	mock.SetRows("tikv_store_status", []interface{}{1, "127.0.0.1:20160", 1, "Offline"})
+	restoreStore := infoschema.SetMemTableMockProvider(mock)
+	tk.MustQuery("select count(*) from information_schema.tiflash_replica").Check(testkit.Rows("0"))
+	tk.MustQuery("select store_id, address, store_state_name, version from information_schema.tikv_store_status").
+		Check(testkit.Rows("1 127.0.0.1:20160 Offline <nil>"))
+	tk.MustQuery("select count(*) from information_schema.schemata where schema_name = 'test'").Check(testkit.Rows("1"))
+
+	restoreStore()
+	tk.MustQuery("select count(*) from information_schema.tiflash_replica").Check(testkit.Rows("2"))
+	restoreReplica()
+	tk.MustQuery("select * from information_schema.tiflash_replica").Check(testkit.Rows())
+}
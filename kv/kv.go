@@ -346,11 +346,15 @@ type Request struct {
 	BatchCop bool
 	// TaskID is an unique ID for an execution of a statement
 	TaskID uint64
+	// CopTaskSeqAllocator allocates the sequence numbers of the cop tasks, which are added to TaskID in the requests
+	// so the cop tasks of a statement can be told apart in the TiKV logs. It's nil if the sequence numbers are not traced.
+	CopTaskSeqAllocator func() uint64
 	// TiDBServerID is the specified TiDB serverID to execute request. `0` means all TiDB instances.
 	TiDBServerID uint64
 	// ReadReplicaScope is the scope of the read replica.
 	ReadReplicaScope string
-	// IsStaleness indicates whether the request read staleness data
+	// IsStaleness indicates whether the request read staleness data, the TiFlash replicas serve the batch cop
+	// requests of it without the read index.
 	IsStaleness bool
 	// MatchStoreLabels indicates the labels the store should be matched
 	MatchStoreLabels []*metapb.StoreLabel
@@ -358,6 +362,43 @@ type Request struct {
 	ResourceGroupTagger tikvrpc.ResourceGroupTagger
 	// Paging indicates whether the request is a paging request.
 	Paging bool
+	// PreferredZone is the zone whose TiFlash stores are preferred by the batch cop tasks.
+	PreferredZone string
+	// TiFlashReplicaReadNodes is the addresses of the TiFlash stores the batch cop tasks are only sent to,
+	// all the stores are used if it's empty.
+	TiFlashReplicaReadNodes []string
+	// BatchCopRespChanSize is the number of the batch cop responses that can be queued before being consumed.
+	BatchCopRespChanSize int
+	// BatchCopRespMaxBytes limits the bytes of the queued batch cop responses, 0 means no limit.
+	BatchCopRespMaxBytes int64
+	// BatchCopRespSpill indicates the batch cop responses are spilled to the disk once the memory quota is exceeded.
+	// It doesn't work if keep order.
+	BatchCopRespSpill bool
+	// MaxRegionsPerBatchCopTask limits the number of the regions in a batch cop task, 0 means no limit.
+	// The tasks of a store are sent with the concurrency of the request at most.
+	MaxRegionsPerBatchCopTask int
+	// BatchCopConcurrency limits the number of the running batch cop tasks, 0 means no limit.
+	BatchCopConcurrency int
+	// BatchCopStreamStallThreshold is how long the batch cop streams wait for the next response before they're
+	// regarded as stalled, 0 means no detection.
+	BatchCopStreamStallThreshold time.Duration
+	// BatchCopStreamStallReconnect indicates whether the stalled batch cop streams returning no data yet are
+	// cancelled and their tasks are sent again.
+	BatchCopStreamStallReconnect bool
+	// BatchCopTaskCache indicates whether the layouts of the batch cop tasks are cached and reused.
+	BatchCopTaskCache bool
+	// AllowFallbackToTiKV indicates the statement is executed again on TiKV once the TiFlash stores time out,
+	// so the batch cop tasks fail fast instead of being sent to the stores that keep timing out.
+	AllowFallbackToTiKV bool
+	// TiKVFallbackData is the DAG request of TiKV reading the same data as a TiFlash request, the regions without
+	// available TiFlash peers are read from TiKV by it instead of failing the request. Nil disables the fallback.
+	TiKVFallbackData []byte
+	// MaxExecutionDeadline is the deadline derived from max_execution_time of the statement,
+	// the RPC timeouts are capped by it. Zero means there is no deadline.
+	MaxExecutionDeadline time.Time
+	// Timeout is the max time to handle the request since it's sent. The streams of the batch cop requests are
+	// cancelled by the gRPC deadline once the time is up, so the stores stop the tasks. Zero means no timeout.
+	Timeout time.Duration
 }
 
 const (
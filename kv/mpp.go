@@ -81,6 +81,7 @@ type MPPClient interface {
 	ConstructMPPTasks(context.Context, *MPPBuildTasksRequest, map[string]time.Time, time.Duration) ([]MPPTaskMeta, error)
 
 	// DispatchMPPTasks dispatches ALL mpp requests at once, and returns an iterator that transfers the data.
+	// The regions of each request are validated by their epochs before it's dispatched.
 	DispatchMPPTasks(ctx context.Context, vars interface{}, reqs []*MPPDispatchRequest, needTriggerFallback bool, startTs uint64) Response
 }
 
@@ -89,4 +90,9 @@ type MPPClient interface {
 type MPPBuildTasksRequest struct {
 	KeyRanges []KeyRange
 	StartTS   uint64
+	// PreferredZone is the zone whose TiFlash stores are preferred by the MPP tasks.
+	PreferredZone string
+	// TiFlashReplicaReadNodes is the addresses of the TiFlash stores the MPP tasks are only sent to,
+	// all the stores are used if it's empty.
+	TiFlashReplicaReadNodes []string
 }
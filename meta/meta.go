@@ -561,6 +561,17 @@ func (m *Meta) ListDatabases() ([]*model.DBInfo, error) {
 	return dbs, nil
 }
 
+// DBKeyRanges returns the key ranges of the meta data of the database, they are the field of the database in the
+// databases and the fields of its tables, sequences and their auto IDs.
+func (m *Meta) DBKeyRanges(dbID int64) []kv.KeyRange {
+	dbKey := m.dbKey(dbID)
+	dbField := m.txn.EncodeHashDataKey(mDBs, dbKey)
+	return []kv.KeyRange{
+		{StartKey: dbField, EndKey: dbField.Next()},
+		m.txn.EncodeHashKeyRange(dbKey),
+	}
+}
+
 // GetDatabase gets the database value with ID.
 func (m *Meta) GetDatabase(dbID int64) (*model.DBInfo, error) {
 	dbKey := m.dbKey(dbID)
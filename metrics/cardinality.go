@@ -0,0 +1,232 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pingcap/tidb/util/fastrand"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	tikvmetrics "github.com/tikv/client-go/v2/metrics"
+)
+
+// LblOther is the label value that aggregates all the values beyond the label value limit.
+const LblOther = "other"
+
+var (
+	// labelValueLimit is the max number of distinct values of a limited label, 0 means no limit.
+	labelValueLimit int64
+	// histogramSampleRate is the bits of the float64 rate of sampled histogram observations, 0 means disabled.
+	histogramSampleRate uint64
+)
+
+// limitedLabels are the labels whose values are limited in all the metrics.
+var limitedLabels = map[string]struct{}{
+	LblStore:                 {},
+	LblAddress:               {},
+	tikvmetrics.LblFromStore: {},
+	tikvmetrics.LblToStore:   {},
+	LblDb:                    {},
+	LblSQLType:               {},
+}
+
+// limitedTypeLabels are the metrics whose `type` labels are limited, the backoff types and the statement types
+// can be too many.
+var limitedTypeLabels = map[string]struct{}{
+	"tidb_tikvclient_backoff_seconds":  {},
+	"tidb_executor_statement_total":    {},
+	"tidb_executor_statement_db_total": {},
+}
+
+// SetLabelValueLimit sets the max number of distinct values of each limited label.
+// It only affects the values that have not been seen yet.
+func SetLabelValueLimit(limit int64) {
+	atomic.StoreInt64(&labelValueLimit, limit)
+}
+
+// SetHistogramSampleRate sets the rate of observations that are recorded by ObserveSampled.
+func SetHistogramSampleRate(rate float64) {
+	atomic.StoreUint64(&histogramSampleRate, math.Float64bits(rate))
+}
+
+// LabelLimiter limits the number of distinct values of a label, so that labels like store addresses
+// can't blow up the metric series in large clusters. Values beyond the limit are reported as LblOther.
+type LabelLimiter struct {
+	mu   sync.RWMutex
+	seen map[string]struct{}
+}
+
+// NewLabelLimiter creates a LabelLimiter.
+func NewLabelLimiter() *LabelLimiter {
+	return &LabelLimiter{seen: make(map[string]struct{})}
+}
+
+// Value returns the label value that should be reported for v.
+func (l *LabelLimiter) Value(v string) string {
+	limit := atomic.LoadInt64(&labelValueLimit)
+	if limit <= 0 {
+		return v
+	}
+	l.mu.RLock()
+	_, ok := l.seen[v]
+	l.mu.RUnlock()
+	if ok {
+		return v
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.seen[v]; ok {
+		return v
+	}
+	if int64(len(l.seen)) >= limit {
+		return LblOther
+	}
+	l.seen[v] = struct{}{}
+	return v
+}
+
+// limitedGatherer aggregates the series whose limited label values are beyond the limit into the LblOther series,
+// it works for the metrics of client-go as well, which can't be limited when they are observed.
+type limitedGatherer struct {
+	prometheus.Gatherer
+	mu sync.Mutex
+	// limiters is indexed by the metric name and the label name.
+	limiters map[string]map[string]*LabelLimiter
+}
+
+// NewLimitedGatherer wraps the gatherer to limit the label values of the gathered metrics.
+func NewLimitedGatherer(g prometheus.Gatherer) prometheus.Gatherer {
+	return &limitedGatherer{Gatherer: g, limiters: make(map[string]map[string]*LabelLimiter)}
+}
+
+var defaultGatherer = NewLimitedGatherer(prometheus.DefaultGatherer)
+
+// Gatherer returns the gatherer of the TiDB metrics, whose label values are limited.
+func Gatherer() prometheus.Gatherer {
+	return defaultGatherer
+}
+
+// Gather implements the prometheus.Gatherer interface.
+func (g *limitedGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	if atomic.LoadInt64(&labelValueLimit) <= 0 {
+		return mfs, err
+	}
+	for _, mf := range mfs {
+		g.limitMetricFamily(mf)
+	}
+	return mfs, err
+}
+
+func (g *limitedGatherer) limiter(name, label string) *LabelLimiter {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	limiters, ok := g.limiters[name]
+	if !ok {
+		limiters = make(map[string]*LabelLimiter)
+		g.limiters[name] = limiters
+	}
+	l, ok := limiters[label]
+	if !ok {
+		l = NewLabelLimiter()
+		limiters[label] = l
+	}
+	return l
+}
+
+func (g *limitedGatherer) limitMetricFamily(mf *dto.MetricFamily) {
+	name := mf.GetName()
+	_, limitType := limitedTypeLabels[name]
+	series := make(map[string]*dto.Metric, len(mf.Metric))
+	metrics := mf.Metric[:0]
+	var key strings.Builder
+	for _, m := range mf.Metric {
+		key.Reset()
+		for i, lp := range m.Label {
+			label := lp.GetName()
+			if _, ok := limitedLabels[label]; ok || (limitType && label == LblType) {
+				if v := g.limiter(name, label).Value(lp.GetValue()); v != lp.GetValue() {
+					// The label pairs are shared by the metric and all the gathered results, so they are copied.
+					labels := make([]*dto.LabelPair, len(m.Label))
+					copy(labels, m.Label)
+					lp = &dto.LabelPair{Name: lp.Name, Value: &v}
+					labels[i] = lp
+					m.Label = labels
+				}
+			}
+			key.WriteString(label)
+			key.WriteByte('=')
+			key.WriteString(lp.GetValue())
+			key.WriteByte(0xff)
+		}
+		if prev, ok := series[key.String()]; ok {
+			mergeMetric(prev, m)
+			continue
+		}
+		series[key.String()] = m
+		metrics = append(metrics, m)
+	}
+	mf.Metric = metrics
+}
+
+// mergeMetric adds the values of from to to, they must be of the same metric.
+func mergeMetric(to, from *dto.Metric) {
+	add := func(a, b *float64) *float64 {
+		v := *a + *b
+		return &v
+	}
+	switch {
+	case to.Counter != nil && from.Counter != nil:
+		to.Counter.Value = add(to.Counter.Value, from.Counter.Value)
+	case to.Gauge != nil && from.Gauge != nil:
+		to.Gauge.Value = add(to.Gauge.Value, from.Gauge.Value)
+	case to.Untyped != nil && from.Untyped != nil:
+		to.Untyped.Value = add(to.Untyped.Value, from.Untyped.Value)
+	case to.Histogram != nil && from.Histogram != nil:
+		cnt := to.Histogram.GetSampleCount() + from.Histogram.GetSampleCount()
+		to.Histogram.SampleCount = &cnt
+		to.Histogram.SampleSum = add(to.Histogram.SampleSum, from.Histogram.SampleSum)
+		// The histograms of a metric have the same buckets.
+		for i, b := range to.Histogram.Bucket {
+			if i < len(from.Histogram.Bucket) {
+				cnt := b.GetCumulativeCount() + from.Histogram.Bucket[i].GetCumulativeCount()
+				b.CumulativeCount = &cnt
+			}
+		}
+	case to.Summary != nil && from.Summary != nil:
+		cnt := to.Summary.GetSampleCount() + from.Summary.GetSampleCount()
+		to.Summary.SampleCount = &cnt
+		to.Summary.SampleSum = add(to.Summary.SampleSum, from.Summary.SampleSum)
+		// The quantiles can't be merged.
+		to.Summary.Quantile = nil
+	}
+}
+
+// ObserveSampled records v by the observer with the probability of the histogram sample rate. It's only used by
+// the histograms with extra label dimensions that are too expensive to be observed by every statement, the values
+// must be fully recorded by the base histograms without these dimensions. The observer is only got if v is sampled.
+func ObserveSampled(observer func() prometheus.Observer, v float64) {
+	rate := math.Float64frombits(atomic.LoadUint64(&histogramSampleRate))
+	if rate <= 0 {
+		return
+	}
+	if rate >= 1 || float64(fastrand.Uint32())/float64(math.MaxUint32) < rate {
+		observer().Observe(v)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+type countObserver struct {
+	cnt int
+}
+
+func (o *countObserver) Observe(float64) {
+	o.cnt++
+}
+
+func TestLabelLimiter(t *testing.T) {
+	defer SetLabelValueLimit(0)
+
+	l := NewLabelLimiter()
+	// No limit by default.
+	require.Equal(t, "db1", l.Value("db1"))
+
+	SetLabelValueLimit(2)
+	require.Equal(t, "db1", l.Value("db1"))
+	require.Equal(t, "db2", l.Value("db2"))
+	require.Equal(t, LblOther, l.Value("db3"))
+	// Values that have been seen are still reported.
+	require.Equal(t, "db1", l.Value("db1"))
+	require.Equal(t, "db2", l.Value("db2"))
+
+	SetLabelValueLimit(0)
+	require.Equal(t, "db3", l.Value("db3"))
+}
+
+func TestObserveSampled(t *testing.T) {
+	defer SetHistogramSampleRate(0)
+
+	o := &countObserver{}
+	observer := func() prometheus.Observer {
+		return o
+	}
+	SetHistogramSampleRate(1)
+	for i := 0; i < 100; i++ {
+		ObserveSampled(observer, 1)
+	}
+	require.Equal(t, 100, o.cnt)
+
+	o.cnt = 0
+	SetHistogramSampleRate(0)
+	for i := 0; i < 100; i++ {
+		ObserveSampled(func() prometheus.Observer {
+			require.FailNow(t, "the observer shouldn't be got if the value is not sampled")
+			return o
+		}, 1)
+	}
+	require.Equal(t, 0, o.cnt)
+
+	SetHistogramSampleRate(0.5)
+	for i := 0; i < 10000; i++ {
+		ObserveSampled(observer, 1)
+	}
+	require.Greater(t, o.cnt, 4000)
+	require.Less(t, o.cnt, 6000)
+}
+
+func TestLimitedGatherer(t *testing.T) {
+	defer SetLabelValueLimit(0)
+
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_counter"}, []string{LblStore, LblType})
+	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "tidb_tikvclient_backoff_seconds", Buckets: []float64{1, 2}}, []string{LblType})
+	reg.MustRegister(counter, histogram)
+	g := NewLimitedGatherer(reg)
+	for _, store := range []string{"s1", "s2", "s3", "s4"} {
+		counter.WithLabelValues(store, "get").Add(1)
+		counter.WithLabelValues(store, "put").Add(2)
+	}
+	for i, tp := range []string{"b1", "b2", "b3"} {
+		histogram.WithLabelValues(tp).Observe(float64(i))
+	}
+
+	series := func() map[string]string {
+		mfs, err := g.Gather()
+		require.NoError(t, err)
+		result := make(map[string]string)
+		for _, mf := range mfs {
+			for _, m := range mf.Metric {
+				key := mf.GetName()
+				for _, lp := range m.Label {
+					key += "," + lp.GetValue()
+				}
+				if m.Histogram != nil {
+					result[key] = fmt.Sprintf("%d %v %d %d", m.Histogram.GetSampleCount(), m.Histogram.GetSampleSum(),
+						m.Histogram.Bucket[0].GetCumulativeCount(), m.Histogram.Bucket[1].GetCumulativeCount())
+				} else {
+					result[key] = fmt.Sprintf("%v", m.Counter.GetValue())
+				}
+			}
+		}
+		return result
+	}
+	// No limit by default.
+	require.Len(t, series(), 11)
+
+	SetLabelValueLimit(2)
+	require.Equal(t, map[string]string{
+		"test_counter,s1,get":                   "1",
+		"test_counter,s1,put":                   "2",
+		"test_counter,s2,get":                   "1",
+		"test_counter,s2,put":                   "2",
+		"test_counter,other,get":                "2",
+		"test_counter,other,put":                "4",
+		"tidb_tikvclient_backoff_seconds,b1":    "1 0 1 1",
+		"tidb_tikvclient_backoff_seconds,b2":    "1 1 1 1",
+		"tidb_tikvclient_backoff_seconds,other": "1 2 0 1",
+	}, series())
+
+	// The limited values are stable.
+	counter.WithLabelValues("s0", "get").Add(1)
+	require.Equal(t, "3", series()["test_counter,other,get"])
+	require.Equal(t, "1", series()["test_counter,s1,get"])
+}
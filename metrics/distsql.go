@@ -61,4 +61,46 @@ var (
 			Help:      "coprocessor cache hit, evict and miss number",
 			Buckets:   prometheus.ExponentialBuckets(1, 2, 16),
 		}, []string{LblType})
+
+	DistSQLBatchCopBalanceSkewHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "distsql",
+			Name:      "batch_cop_balance_skew",
+			Help:      "Bucketed histogram of the ratio of the most regions of a store to the average regions of the stores after the batch cop tasks are balanced.",
+			Buckets:   prometheus.ExponentialBuckets(1, 1.25, 16), // 1 ~ 28.4
+		})
+
+	DistSQLBatchCopRetryCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "distsql",
+			Name:      "batch_cop_retry_total",
+			Help:      "Counter of the batch cop tasks built or sent again by reason.",
+		}, []string{LblType})
+
+	DistSQLBatchCopStreamReconnectCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "distsql",
+			Name:      "batch_cop_stream_reconnect_total",
+			Help:      "Counter of the streams of the batch cop tasks established again after the previous ones break.",
+		})
+
+	DistSQLBatchCopStreamRecvGapHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "distsql",
+			Name:      "batch_cop_stream_recv_gap_seconds",
+			Help:      "Bucketed histogram of the time waiting for the next response of the batch cop streams.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 20), // 0.5ms ~ 262s
+		})
+
+	DistSQLBatchCopStreamStallCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "distsql",
+			Name:      "batch_cop_stream_stall_total",
+			Help:      "Counter of the streams of the batch cop tasks waiting for the next response longer than the stall threshold.",
+		})
 )
@@ -46,4 +46,23 @@ var (
 			Name:      "statement_db_total",
 			Help:      "Counter of StmtNode by Database.",
 		}, []string{LblDb, LblType})
+
+	// AdmissionQueueGauge records the number of the statements queued and running in the admission queue.
+	AdmissionQueueGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "executor",
+			Name:      "admission_queue_statements",
+			Help:      "The number of the statements queued or running in the admission queue.",
+		}, []string{LblType, LblState})
+
+	// AdmissionQueueWaitHistogram records the duration the statements wait in the admission queue.
+	AdmissionQueueWaitHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "executor",
+			Name:      "admission_queue_wait_duration_seconds",
+			Help:      "Bucketed histogram of the duration the statements wait in the admission queue.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 20), // 1ms ~ 524s
+		}, []string{LblType})
 )
@@ -66,6 +66,8 @@ func RetLabel(err error) string {
 
 // RegisterMetrics registers the metrics which are ONLY used in TiDB server.
 func RegisterMetrics() {
+	prometheus.MustRegister(AdmissionQueueGauge)
+	prometheus.MustRegister(AdmissionQueueWaitHistogram)
 	prometheus.MustRegister(AutoAnalyzeCounter)
 	prometheus.MustRegister(AutoAnalyzeHistogram)
 	prometheus.MustRegister(AutoIDHistogram)
@@ -83,6 +85,11 @@ func RegisterMetrics() {
 	prometheus.MustRegister(BackfillProgressGauge)
 	prometheus.MustRegister(DDLWorkerHistogram)
 	prometheus.MustRegister(DeploySyncerHistogram)
+	prometheus.MustRegister(DistSQLBatchCopBalanceSkewHistogram)
+	prometheus.MustRegister(DistSQLBatchCopRetryCounter)
+	prometheus.MustRegister(DistSQLBatchCopStreamReconnectCounter)
+	prometheus.MustRegister(DistSQLBatchCopStreamRecvGapHistogram)
+	prometheus.MustRegister(DistSQLBatchCopStreamStallCounter)
 	prometheus.MustRegister(DistSQLPartialCountHistogram)
 	prometheus.MustRegister(DistSQLCoprCacheHistogram)
 	prometheus.MustRegister(DistSQLQueryHistogram)
@@ -111,9 +118,11 @@ func RegisterMetrics() {
 	prometheus.MustRegister(OwnerHandleSyncerHistogram)
 	prometheus.MustRegister(PanicCounter)
 	prometheus.MustRegister(PlanCacheCounter)
+	prometheus.MustRegister(PlanCacheEvictCounter)
 	prometheus.MustRegister(PseudoEstimation)
 	prometheus.MustRegister(PacketIOCounter)
 	prometheus.MustRegister(QueryDurationHistogram)
+	prometheus.MustRegister(QueryDurationByDBHistogram)
 	prometheus.MustRegister(QueryTotalCounter)
 	prometheus.MustRegister(SchemaLeaseErrorCounter)
 	prometheus.MustRegister(ServerEventCounter)
@@ -150,6 +159,7 @@ func RegisterMetrics() {
 	prometheus.MustRegister(MaxProcs)
 	prometheus.MustRegister(GOGC)
 	prometheus.MustRegister(ConnIdleDurationHistogram)
+	prometheus.MustRegister(ClientStallDurationHistogram)
 	prometheus.MustRegister(ServerInfo)
 	prometheus.MustRegister(TokenGauge)
 	prometheus.MustRegister(ConfigStatus)
@@ -44,6 +44,16 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 29), // 0.5ms ~ 1.5days
 		}, []string{LblSQLType})
 
+	// QueryDurationByDBHistogram is sampled by the histogram sample rate, see ObserveSampled. It's disabled by default.
+	QueryDurationByDBHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "handle_query_duration_by_db_seconds",
+			Help:      "Bucketed histogram of processing time (s) of the sampled handled queries by database.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 29), // 0.5ms ~ 1.5days
+		}, []string{LblDb, LblSQLType})
+
 	QueryTotalCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: "tidb",
@@ -128,6 +138,14 @@ var (
 			Help:      "Counter of query using plan cache.",
 		}, []string{LblType})
 
+	PlanCacheEvictCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "plan_cache_evict_total",
+			Help:      "Counter of the plans evicted from the plan cache by reason.",
+		}, []string{LblType})
+
 	ReadFromTableCacheCounter = prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Namespace: "tidb",
@@ -205,6 +223,15 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 29), // 0.5ms ~ 1.5days
 		}, []string{LblInTxn})
 
+	ClientStallDurationHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "client_stall_duration_seconds",
+			Help:      "Bucketed histogram of the time blocked in writing the query results to the client (s).",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 24), // 0.5ms ~ 1.2h
+		})
+
 	ServerInfo = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "tidb",
@@ -1018,13 +1018,36 @@ type CreateTableStmt struct {
 	Partition      *PartitionOptions
 	OnDuplicate    OnDuplicateKeyHandlingType
 	Select         ResultSetNode
+	// Foreign is set for CREATE FOREIGN TABLE, whose data is read from a foreign server.
+	Foreign *ForeignTableClause
+}
+
+// ForeignTableClause is the `SERVER server_name [OPTIONS (...)]` clause of CREATE FOREIGN TABLE.
+type ForeignTableClause struct {
+	Server  string
+	Options []*ServerOption
+}
+
+// Restore implements Node interface.
+func (n *ForeignTableClause) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("SERVER ")
+	ctx.WriteName(n.Server)
+	if len(n.Options) > 0 {
+		ctx.WritePlain(" ")
+		return restoreServerOptions(ctx, n.Options)
+	}
+	return nil
 }
 
 // Restore implements Node interface.
 func (n *CreateTableStmt) Restore(ctx *format.RestoreCtx) error {
 	switch n.TemporaryKeyword {
 	case TemporaryNone:
-		ctx.WriteKeyWord("CREATE TABLE ")
+		if n.Foreign != nil {
+			ctx.WriteKeyWord("CREATE FOREIGN TABLE ")
+		} else {
+			ctx.WriteKeyWord("CREATE TABLE ")
+		}
 	case TemporaryGlobal:
 		ctx.WriteKeyWord("CREATE GLOBAL TEMPORARY TABLE ")
 	case TemporaryLocal:
@@ -1104,6 +1127,13 @@ func (n *CreateTableStmt) Restore(ctx *format.RestoreCtx) error {
 		}
 	}
 
+	if n.Foreign != nil {
+		ctx.WritePlain(" ")
+		if err := n.Foreign.Restore(ctx); err != nil {
+			return errors.Annotate(err, "An error occurred while splicing CreateTableStmt Foreign")
+		}
+	}
+
 	return nil
 }
 
@@ -2045,6 +2075,8 @@ const (
 	TableOptionTableCheckSum
 	TableOptionUnion
 	TableOptionEncryption
+	TableOptionTTL
+	TableOptionTTLEnable
 	TableOptionPlacementPolicy = TableOptionType(PlacementOptionPolicy)
 	TableOptionStatsBuckets    = TableOptionType(StatsOptionBuckets)
 	TableOptionStatsTopN       = TableOptionType(StatsOptionTopN)
@@ -2097,6 +2129,9 @@ type TableOption struct {
 	BoolValue  bool
 	Value      ValueExpr
 	TableNames []*TableName
+	// ColumnName and TimeUnitValue are the column and the interval unit of TableOptionTTL.
+	ColumnName    *ColumnName
+	TimeUnitValue *TimeUnitExpr
 }
 
 func (n *TableOption) Restore(ctx *format.RestoreCtx) error {
@@ -2323,6 +2358,27 @@ func (n *TableOption) Restore(ctx *format.RestoreCtx) error {
 		ctx.WriteKeyWord("ENCRYPTION ")
 		ctx.WritePlain("= ")
 		ctx.WriteString(n.StrValue)
+	case TableOptionTTL:
+		_ = ctx.WriteWithSpecialComments(tidb.FeatureIDTTL, func() error {
+			ctx.WriteKeyWord("TTL ")
+			ctx.WritePlain("= ")
+			ctx.WriteName(n.ColumnName.Name.O)
+			ctx.WritePlain(" + ")
+			ctx.WriteKeyWord("INTERVAL ")
+			ctx.WritePlainf("%d ", n.UintValue)
+			return n.TimeUnitValue.Restore(ctx)
+		})
+	case TableOptionTTLEnable:
+		_ = ctx.WriteWithSpecialComments(tidb.FeatureIDTTL, func() error {
+			ctx.WriteKeyWord("TTL_ENABLE ")
+			ctx.WritePlain("= ")
+			if n.BoolValue {
+				ctx.WriteString("ON")
+			} else {
+				ctx.WriteString("OFF")
+			}
+			return nil
+		})
 	case TableOptionPlacementPolicy:
 		placementOpt := PlacementOption{
 			Tp:        PlacementOptionPolicy,
@@ -2560,6 +2616,7 @@ const (
 	AlterTableCache
 	AlterTableNoCache
 	AlterTableStatsOptions
+	AlterTableRemoveTTL
 )
 
 // LockType is the type for AlterTableSpec.
@@ -3097,6 +3154,11 @@ func (n *AlterTableSpec) Restore(ctx *format.RestoreCtx) error {
 		ctx.WriteKeyWord("DISABLE KEYS")
 	case AlterTableRemovePartitioning:
 		ctx.WriteKeyWord("REMOVE PARTITIONING")
+	case AlterTableRemoveTTL:
+		_ = ctx.WriteWithSpecialComments(tidb.FeatureIDTTL, func() error {
+			ctx.WriteKeyWord("REMOVE TTL")
+			return nil
+		})
 	case AlterTableWithValidation:
 		ctx.WriteKeyWord("WITH VALIDATION")
 	case AlterTableWithoutValidation:
@@ -3888,6 +3950,38 @@ func (n *RecoverTableStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// FlashBackClusterStmt is a statement to flashback the data and the schemas of the cluster to a timestamp.
+type FlashBackClusterStmt struct {
+	ddlNode
+
+	FlashbackTS ExprNode
+}
+
+// Restore implements Node interface.
+func (n *FlashBackClusterStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("FLASHBACK CLUSTER TO TIMESTAMP ")
+	if err := n.FlashbackTS.Restore(ctx); err != nil {
+		return errors.Annotate(err, "An error occurred while splicing FlashBackClusterStmt FlashbackTS")
+	}
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *FlashBackClusterStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+
+	n = newNode.(*FlashBackClusterStmt)
+	node, ok := n.FlashbackTS.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.FlashbackTS = node.(ExprNode)
+	return v.Leave(n)
+}
+
 // FlashBackTableStmt is a statement to restore a dropped/truncate table.
 type FlashBackTableStmt struct {
 	ddlNode
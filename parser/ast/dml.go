@@ -439,6 +439,51 @@ func (n *TableName) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// ExternalTable is the table function which reads the rows from an external file.
+// e.g. `EXTERNAL('s3://bucket/x.parquet', FORMAT => 'parquet')`
+type ExternalTable struct {
+	node
+
+	// URL is the location of the file, such as `s3://bucket/x.csv` or `local:///tmp/x.csv`.
+	URL string
+	// Options are the named arguments of the table function.
+	Options []*ExternalTableOption
+}
+
+// ExternalTableOption is a named argument of the EXTERNAL table function, such as `FORMAT => 'csv'`.
+type ExternalTableOption struct {
+	// Name is the upper-case name of the argument.
+	Name  string
+	Value string
+}
+
+func (*ExternalTable) resultSet() {}
+
+// Restore implements Node interface.
+func (n *ExternalTable) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("EXTERNAL")
+	ctx.WritePlain("(")
+	ctx.WriteString(n.URL)
+	for _, option := range n.Options {
+		ctx.WritePlain(", ")
+		ctx.WriteKeyWord(option.Name)
+		ctx.WritePlain(" => ")
+		ctx.WriteString(option.Value)
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *ExternalTable) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*ExternalTable)
+	return v.Leave(n)
+}
+
 // DeleteTableList is the tablelist used in delete statement multi-table mode.
 type DeleteTableList struct {
 	node
@@ -2198,6 +2243,8 @@ type DeleteStmt struct {
 	// TableHints represents the table level Optimizer Hint for join type.
 	TableHints []*TableOptimizerHint
 	With       *WithClause
+	// Returning is the fields of the deleted rows that are returned to the client.
+	Returning *FieldList
 }
 
 // Restore implements Node interface.
@@ -2291,6 +2338,13 @@ func (n *DeleteStmt) Restore(ctx *format.RestoreCtx) error {
 		}
 	}
 
+	if n.Returning != nil {
+		ctx.WriteKeyWord(" RETURNING ")
+		if err := n.Returning.Restore(ctx); err != nil {
+			return errors.Annotate(err, "An error occurred while restore DeleteStmt.Returning")
+		}
+	}
+
 	return nil
 }
 
@@ -2344,6 +2398,13 @@ func (n *DeleteStmt) Accept(v Visitor) (Node, bool) {
 		}
 		n.Limit = node.(*Limit)
 	}
+	if n.Returning != nil {
+		node, ok = n.Returning.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Returning = node.(*FieldList)
+	}
 	return v.Leave(n)
 }
 
@@ -2362,6 +2423,8 @@ type UpdateStmt struct {
 	MultipleTable bool
 	TableHints    []*TableOptimizerHint
 	With          *WithClause
+	// Returning is the fields of the updated rows that are returned to the client.
+	Returning *FieldList
 }
 
 // Restore implements Node interface.
@@ -2444,6 +2507,13 @@ func (n *UpdateStmt) Restore(ctx *format.RestoreCtx) error {
 		}
 	}
 
+	if n.Returning != nil {
+		ctx.WriteKeyWord(" RETURNING ")
+		if err := n.Returning.Restore(ctx); err != nil {
+			return errors.Annotate(err, "An error occur while restore UpdateStmt.Returning")
+		}
+	}
+
 	return nil
 }
 
@@ -2494,6 +2564,13 @@ func (n *UpdateStmt) Accept(v Visitor) (Node, bool) {
 		}
 		n.Limit = node.(*Limit)
 	}
+	if n.Returning != nil {
+		node, ok = n.Returning.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Returning = node.(*FieldList)
+	}
 	return v.Leave(n)
 }
 
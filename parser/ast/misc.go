@@ -1585,6 +1585,119 @@ func (n *DropUserStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// ServerOption is an option of a foreign server or a foreign table, such as `HOST 'localhost'` or `PORT 3306`.
+type ServerOption struct {
+	// Name is the upper-case name of the option.
+	Name string
+	// Value is the value of the option, the numeric value of PORT is formatted in decimal.
+	Value string
+}
+
+// Restore implements Node interface.
+func (n *ServerOption) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord(n.Name)
+	ctx.WritePlain(" ")
+	if n.Name == "PORT" {
+		ctx.WritePlain(n.Value)
+	} else {
+		ctx.WriteString(n.Value)
+	}
+	return nil
+}
+
+func restoreServerOptions(ctx *format.RestoreCtx, options []*ServerOption) error {
+	ctx.WriteKeyWord("OPTIONS ")
+	ctx.WritePlain("(")
+	for i, option := range options {
+		if i != 0 {
+			ctx.WritePlain(", ")
+		}
+		if err := option.Restore(ctx); err != nil {
+			return errors.Annotatef(err, "An error occurred while restore ServerOption[%d]", i)
+		}
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
+// CreateServerStmt creates a foreign server which can be referenced by foreign tables.
+// See https://dev.mysql.com/doc/refman/8.0/en/create-server.html
+type CreateServerStmt struct {
+	stmtNode
+
+	IfNotExists bool
+	Name        string
+	Wrapper     string
+	Options     []*ServerOption
+}
+
+// Restore implements Node interface.
+func (n *CreateServerStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("CREATE SERVER ")
+	if n.IfNotExists {
+		ctx.WriteKeyWord("IF NOT EXISTS ")
+	}
+	ctx.WriteName(n.Name)
+	ctx.WriteKeyWord(" FOREIGN DATA WRAPPER ")
+	ctx.WriteName(n.Wrapper)
+	ctx.WritePlain(" ")
+	return restoreServerOptions(ctx, n.Options)
+}
+
+// SecureText implements SensitiveStatement interface.
+func (n *CreateServerStmt) SecureText() string {
+	redacted := *n
+	redacted.Options = make([]*ServerOption, 0, len(n.Options))
+	for _, option := range n.Options {
+		if option.Name == "PASSWORD" {
+			option = &ServerOption{Name: option.Name, Value: "xxxxxx"}
+		}
+		redacted.Options = append(redacted.Options, option)
+	}
+	var sb strings.Builder
+	_ = redacted.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb))
+	return sb.String()
+}
+
+// Accept implements Node Accept interface.
+func (n *CreateServerStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CreateServerStmt)
+	return v.Leave(n)
+}
+
+// DropServerStmt drops a foreign server.
+// See https://dev.mysql.com/doc/refman/8.0/en/drop-server.html
+type DropServerStmt struct {
+	stmtNode
+
+	IfExists bool
+	Name     string
+}
+
+// Restore implements Node interface.
+func (n *DropServerStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DROP SERVER ")
+	if n.IfExists {
+		ctx.WriteKeyWord("IF EXISTS ")
+	}
+	ctx.WriteName(n.Name)
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *DropServerStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*DropServerStmt)
+	return v.Leave(n)
+}
+
 // CreateBindingStmt creates sql binding hint.
 type CreateBindingStmt struct {
 	stmtNode
@@ -1858,6 +1971,17 @@ const (
 	AdminResetTelemetryID
 	AdminReloadStatistics
 	AdminFlushPlanCache
+	AdminReclaimTable
+	AdminShowClusterSettings
+	AdminSetClusterSetting
+	AdminShowSafePoints
+	AdminSetSafePoint
+	AdminExpireSafePoint
+	AdminRepairIndex
+	AdminShowRedundantIndexes
+	AdminDecodeKey
+	AdminDecodePlan
+	AdminShowMVCCByKey
 )
 
 // HandleRange represents a range where handle value >= Begin and < End.
@@ -1945,6 +2069,17 @@ type AdminStmt struct {
 	Plugins        []string
 	Where          ExprNode
 	StatementScope StatementScope
+	// Compact indicates whether to compact the key ranges of the tables after reclaiming.
+	Compact bool
+	// SettingName and SettingValue are the cluster setting to set by ADMIN SET CLUSTER SETTING.
+	SettingName  string
+	SettingValue ExprNode
+	// ServiceID, SafePoint and SafePointTTL are the service safe point to set by ADMIN SET SAFEPOINT.
+	ServiceID    string
+	SafePoint    uint64
+	SafePointTTL uint64
+	// EncodedStr is the hex key or the encoded plan of ADMIN DECODE KEY/PLAN and ADMIN SHOW MVCC KEY.
+	EncodedStr string
 }
 
 // Restore implements Node interface.
@@ -2013,6 +2148,12 @@ func (n *AdminStmt) Restore(ctx *format.RestoreCtx) error {
 			return err
 		}
 		ctx.WritePlainf(" %s", n.Index)
+	case AdminRepairIndex:
+		ctx.WriteKeyWord("REPAIR INDEX ")
+		if err := restoreTables(); err != nil {
+			return err
+		}
+		ctx.WritePlainf(" %s", n.Index)
 	case AdminCheckIndexRange:
 		ctx.WriteKeyWord("CHECK INDEX ")
 		if err := restoreTables(); err != nil {
@@ -2082,6 +2223,45 @@ func (n *AdminStmt) Restore(ctx *format.RestoreCtx) error {
 		ctx.WriteKeyWord("RESET TELEMETRY_ID")
 	case AdminReloadStatistics:
 		ctx.WriteKeyWord("RELOAD STATS_EXTENDED")
+	case AdminShowClusterSettings:
+		ctx.WriteKeyWord("SHOW CLUSTER SETTINGS")
+	case AdminSetClusterSetting:
+		ctx.WriteKeyWord("SET CLUSTER SETTING ")
+		ctx.WriteName(n.SettingName)
+		ctx.WritePlain(" = ")
+		if err := n.SettingValue.Restore(ctx); err != nil {
+			return errors.Annotate(err, "An error occurred while restore AdminStmt.SettingValue")
+		}
+	case AdminShowSafePoints:
+		ctx.WriteKeyWord("SHOW SAFEPOINTS")
+	case AdminShowRedundantIndexes:
+		ctx.WriteKeyWord("SHOW REDUNDANT INDEXES")
+	case AdminDecodeKey:
+		ctx.WriteKeyWord("DECODE KEY ")
+		ctx.WriteString(n.EncodedStr)
+	case AdminDecodePlan:
+		ctx.WriteKeyWord("DECODE PLAN ")
+		ctx.WriteString(n.EncodedStr)
+	case AdminShowMVCCByKey:
+		ctx.WriteKeyWord("SHOW MVCC KEY ")
+		ctx.WriteString(n.EncodedStr)
+	case AdminSetSafePoint:
+		ctx.WriteKeyWord("SET SAFEPOINT ")
+		ctx.WriteString(n.ServiceID)
+		ctx.WritePlainf(" = %d", n.SafePoint)
+		ctx.WriteKeyWord(" TTL ")
+		ctx.WritePlainf("%d", n.SafePointTTL)
+	case AdminExpireSafePoint:
+		ctx.WriteKeyWord("EXPIRE SAFEPOINT ")
+		ctx.WriteString(n.ServiceID)
+	case AdminReclaimTable:
+		ctx.WriteKeyWord("RECLAIM TABLE ")
+		if err := restoreTables(); err != nil {
+			return err
+		}
+		if n.Compact {
+			ctx.WriteKeyWord(" COMPACT")
+		}
 	case AdminFlushPlanCache:
 		if n.StatementScope == StatementScopeSession {
 			ctx.WriteKeyWord("FLUSH SESSION PLAN_CACHE")
@@ -2120,6 +2300,14 @@ func (n *AdminStmt) Accept(v Visitor) (Node, bool) {
 		n.Where = node.(ExprNode)
 	}
 
+	if n.SettingValue != nil {
+		node, ok := n.SettingValue.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.SettingValue = node.(ExprNode)
+	}
+
 	return v.Leave(n)
 }
 
@@ -328,3 +328,13 @@ func TestBRIESecureText(t *testing.T) {
 
 	}
 }
+
+func TestCreateServerSecureText(t *testing.T) {
+	p := parser.New()
+	node, err := p.ParseOneStmt("create server s foreign data wrapper mysql options (host 'h', user 'u', password 'secret', port 3306)", "", "")
+	require.NoError(t, err)
+	n, ok := node.(ast.SensitiveStmtNode)
+	require.True(t, ok)
+	require.Equal(t, "CREATE SERVER `s` FOREIGN DATA WRAPPER `mysql` OPTIONS (HOST 'h', USER 'u', PASSWORD 'xxxxxx', PORT 3306)", n.SecureText())
+	require.Equal(t, "secret", node.(*ast.CreateServerStmt).Options[2].Value)
+}
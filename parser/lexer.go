@@ -46,6 +46,9 @@ type Scanner struct {
 	warns        []error
 	stmtStartPos int
 
+	// errSpans are the ranges of the text the errors are found at, errSpans[i] is the range of errs[i].
+	errSpans []errSpan
+
 	// inBangComment is true if we are inside a `/*! ... */` block.
 	// It is used to ignore a stray `*/` when scanning.
 	inBangComment bool
@@ -94,12 +97,23 @@ func (s *Scanner) reset(sql string) {
 	s.r = reader{s: sql, p: Pos{Line: 1}, l: len(sql)}
 	s.buf.Reset()
 	s.errs = s.errs[:0]
+	s.errSpans = s.errSpans[:0]
 	s.warns = s.warns[:0]
 	s.stmtStartPos = 0
 	s.inBangComment = false
 	s.lastKeyword = 0
 }
 
+// resetRange resets the sql string to be scanned from start to end, the positions are still the ones in the
+// whole sql string.
+func (s *Scanner) resetRange(sql string, start Pos, end int) {
+	s.reset(sql)
+	s.r.p = start
+	s.r.l = end
+	s.stmtStartPos = start.Offset
+	s.lastScanOffset = start.Offset
+}
+
 func (s *Scanner) stmtText() string {
 	endPos := s.r.pos().Offset
 	if s.r.s[endPos-1] == '\n' {
@@ -136,7 +150,13 @@ func (s *Scanner) AppendError(err error) {
 	if err == nil {
 		return
 	}
+	s.appendErrorAt(err, s.lastScanOffset)
+}
+
+// appendErrorAt sets error into scanner, the error is found at the text from start to the current position.
+func (s *Scanner) appendErrorAt(err error, start int) {
 	s.errs = append(s.errs, err)
+	s.errSpans = append(s.errSpans, errSpan{start: start, end: s.r.p.Offset})
 }
 
 // AppendWarn sets warning into scanner.
@@ -526,7 +546,7 @@ func startWithSlash(s *Scanner) (tok int, pos Pos, lit string) {
 			}
 		}
 		// unclosed comment or other errors.
-		s.errs = append(s.errs, ParseErrorWith(s.r.data(&pos), s.r.p.Line))
+		s.appendErrorAt(ParseErrorWith(s.r.data(&pos), s.r.p.Line), pos.Offset)
 		return
 	}
 }
@@ -948,6 +968,7 @@ func (s *Scanner) lastErrorAsWarn() {
 	}
 	s.warns = append(s.warns, s.errs[len(s.errs)-1])
 	s.errs = s.errs[:len(s.errs)-1]
+	s.errSpans = s.errSpans[:len(s.errSpans)-1]
 }
 
 type reader struct {
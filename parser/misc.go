@@ -13,6 +13,8 @@
 
 package parser
 
+import "strings"
+
 func isLetter(ch byte) bool {
 	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
 }
@@ -103,6 +105,7 @@ func init() {
 	initTokenString("&^", andnot)
 	initTokenString(":=", assignmentEq)
 	initTokenString("<=>", nulleq)
+	initTokenString("=>", rightArrow)
 	initTokenString(">=", ge)
 	initTokenString("<=", le)
 	initTokenString("!=", neq)
@@ -132,6 +135,11 @@ func isInTokenMap(target string) bool {
 	return ok
 }
 
+// IsKeyword returns whether the identifier is a keyword, which should be quoted to be used as an identifier.
+func IsKeyword(ident string) bool {
+	return isInTokenMap(strings.ToUpper(ident))
+}
+
 // tokenMap is a map of known identifiers to the parser token ID.
 // Please try to keep the map in alphabetical order.
 var tokenMap = map[string]int{
@@ -216,6 +224,7 @@ var tokenMap = map[string]int{
 	"CLEANUP":                  cleanup,
 	"CLIENT":                   client,
 	"CLIENT_ERRORS_SUMMARY":    clientErrorsSummary,
+	"CLUSTER":                  cluster,
 	"CLUSTERED":                clustered,
 	"CMSKETCH":                 cmSketch,
 	"COALESCE":                 coalesce,
@@ -276,6 +285,7 @@ var tokenMap = map[string]int{
 	"DEALLOCATE":               deallocate,
 	"DEC":                      decimalType,
 	"DECIMAL":                  decimalType,
+	"DECODE":                   decode,
 	"DEFAULT":                  defaultKwd,
 	"DEFINER":                  definer,
 	"DELAY_KEY_WRITE":          delayKeyWrite,
@@ -328,6 +338,7 @@ var tokenMap = map[string]int{
 	"EXPLAIN":                  explain,
 	"EXPR_PUSHDOWN_BLACKLIST":  exprPushdownBlacklist,
 	"EXTENDED":                 extended,
+	"EXTERNAL":                 external,
 	"EXTRACT":                  extract,
 	"FALSE":                    falseKwd,
 	"FAULTS":                   faultsSym,
@@ -474,6 +485,7 @@ var tokenMap = map[string]int{
 	"MODE":                     mode,
 	"MODIFY":                   modify,
 	"MONTH":                    month,
+	"MVCC":                     mvcc,
 	"NAMES":                    names,
 	"NATIONAL":                 national,
 	"NATURAL":                  natural,
@@ -514,6 +526,7 @@ var tokenMap = map[string]int{
 	"OPTION":                   option,
 	"OPTIONAL":                 optional,
 	"OPTIONALLY":               optionally,
+	"OPTIONS":                  options,
 	"OR":                       or,
 	"ORDER":                    order,
 	"OUTER":                    outer,
@@ -563,6 +576,7 @@ var tokenMap = map[string]int{
 	"REAL":                     realType,
 	"REBUILD":                  rebuild,
 	"RECENT":                   recent,
+	"RECLAIM":                  reclaim,
 	"RECOVER":                  recover,
 	"RECURSIVE":                recursive,
 	"REDUNDANT":                redundant,
@@ -591,6 +605,7 @@ var tokenMap = map[string]int{
 	"RESTORE":                  restore,
 	"RESTORES":                 restores,
 	"RESTRICT":                 restrict,
+	"RETURNING":                returning,
 	"REVERSE":                  reverse,
 	"REVOKE":                   revoke,
 	"RIGHT":                    right,
@@ -606,6 +621,8 @@ var tokenMap = map[string]int{
 	"RESUME":                   resume,
 	"RUNNING":                  running,
 	"S3":                       s3,
+	"SAFEPOINT":                safePoint,
+	"SAFEPOINTS":               safePoints,
 	"SAMPLES":                  samples,
 	"SAMPLERATE":               sampleRate,
 	"SAN":                      san,
@@ -624,8 +641,11 @@ var tokenMap = map[string]int{
 	"SEQUENCE":                 sequence,
 	"SERIAL":                   serial,
 	"SERIALIZABLE":             serializable,
+	"SERVER":                   server,
 	"SESSION":                  session,
 	"SET":                      set,
+	"SETTING":                  setting,
+	"SETTINGS":                 settings,
 	"SETVAL":                   setval,
 	"SHARD_ROW_ID_BITS":        shardRowIDBits,
 	"SHARE":                    share,
@@ -744,6 +764,8 @@ var tokenMap = map[string]int{
 	"TRIM":                     trim,
 	"TRUE":                     trueKwd,
 	"TRUNCATE":                 truncate,
+	"TTL":                      ttl,
+	"TTL_ENABLE":               ttlEnable,
 	"TYPE":                     tp,
 	"UNBOUNDED":                unbounded,
 	"UNCOMMITTED":              uncommitted,
@@ -788,6 +810,7 @@ var tokenMap = map[string]int{
 	"WIDTH":                    width,
 	"WITH":                     with,
 	"WITHOUT":                  without,
+	"WRAPPER":                  wrapper,
 	"WRITE":                    write,
 	"X509":                     x509,
 	"XOR":                      xor,
@@ -95,6 +95,9 @@ const (
 	ActionAlterTableStatsOptions        ActionType = 58
 	ActionAlterNoCacheTable             ActionType = 59
 	ActionCreateTables                  ActionType = 60
+	ActionAlterTTLInfo                  ActionType = 61
+	ActionAlterTTLRemove                ActionType = 62
+	ActionFlashbackCluster              ActionType = 63
 )
 
 var actionMap = map[ActionType]string{
@@ -157,6 +160,9 @@ var actionMap = map[ActionType]string{
 	ActionAlterCacheTable:               "alter table cache",
 	ActionAlterNoCacheTable:             "alter table nocache",
 	ActionAlterTableStatsOptions:        "alter table statistics options",
+	ActionAlterTTLInfo:                  "alter table ttl info",
+	ActionAlterTTLRemove:                "alter table no_ttl",
+	ActionFlashbackCluster:              "flashback cluster",
 
 	// `ActionAlterTableAlterPartition` is removed and will never be used.
 	// Just left a tombstone here for compatibility.
@@ -353,7 +353,47 @@ type TableInfo struct {
 
 	// StatsOptions is used when do analyze/auto-analyze for each table
 	StatsOptions *StatsOptions `json:"stats_options"`
+
+	// TTLInfo means the rows of the table expire after a period of time.
+	TTLInfo *TTLInfo `json:"ttl_info"`
+
+	// Foreign means the table is a read-only foreign table whose rows are read from a foreign server.
+	Foreign *ForeignTableInfo `json:"foreign"`
 }
+
+// ForeignTableInfo records the remote table of a foreign table.
+type ForeignTableInfo struct {
+	// Server is the name of the foreign server created by CREATE SERVER.
+	Server CIStr `json:"server"`
+	// RemoteDB is the database of the remote table, it's the DATABASE of the server if it's empty.
+	RemoteDB string `json:"remote_db"`
+	// RemoteTable is the name of the remote table, it's the name of the foreign table if it's empty.
+	RemoteTable string `json:"remote_table"`
+}
+
+// Clone clones ForeignTableInfo.
+func (f *ForeignTableInfo) Clone() *ForeignTableInfo {
+	cloned := *f
+	return &cloned
+}
+
+// TTLInfo records the TTL config of a table. A row expires when `ColumnName + INTERVAL IntervalExprStr
+// IntervalTimeUnit` is earlier than the current time.
+type TTLInfo struct {
+	ColumnName CIStr `json:"column"`
+	// IntervalExprStr is the number of the time units, it's an unsigned integer literal.
+	IntervalExprStr string `json:"interval_expr"`
+	// IntervalTimeUnit is the time unit of the interval, e.g. "DAY".
+	IntervalTimeUnit string `json:"interval_time_unit"`
+	Enable           bool   `json:"enable"`
+}
+
+// Clone clones TTLInfo.
+func (t *TTLInfo) Clone() *TTLInfo {
+	cloned := *t
+	return &cloned
+}
+
 type TableCacheStatusType int
 
 const (
@@ -543,6 +583,14 @@ func (t *TableInfo) Clone() *TableInfo {
 		nt.ForeignKeys[i] = t.ForeignKeys[i].Clone()
 	}
 
+	if t.TTLInfo != nil {
+		nt.TTLInfo = t.TTLInfo.Clone()
+	}
+
+	if t.Foreign != nil {
+		nt.Foreign = t.Foreign.Clone()
+	}
+
 	return &nt
 }
 
@@ -679,6 +727,11 @@ func (t *TableInfo) IsSequence() bool {
 	return t.Sequence != nil
 }
 
+// IsForeignTable checks if TableInfo is a foreign table.
+func (t *TableInfo) IsForeignTable() bool {
+	return t.Foreign != nil
+}
+
 // IsBaseTable checks to see the table is neither a view or a sequence.
 func (t *TableInfo) IsBaseTable() bool {
 	return t.Sequence == nil && t.View == nil
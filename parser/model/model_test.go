@@ -304,6 +304,9 @@ func TestString(t *testing.T) {
 		{ActionAlterTablePlacement, "alter table placement"},
 		{ActionAlterTablePartitionPlacement, "alter table partition placement"},
 		{ActionAlterNoCacheTable, "alter table nocache"},
+		{ActionAlterTTLInfo, "alter table ttl info"},
+		{ActionAlterTTLRemove, "alter table no_ttl"},
+		{ActionFlashbackCluster, "flashback cluster"},
 	}
 
 	for _, v := range acts {
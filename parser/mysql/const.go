@@ -155,6 +155,11 @@ const (
 	ClientPluginAuth
 	ClientConnectAtts
 	ClientPluginAuthLenencClientData
+	ClientCanHandleExpiredPasswords
+	ClientSessionTrack
+	ClientDeprecateEOF
+	ClientOptionalResultsetMetadata
+	ClientZstdCompressionAlgorithm
 )
 
 // Cache type information.
@@ -193,6 +198,10 @@ const (
 	RoleEdgeTable = "role_edges"
 	// DefaultRoleTable is the table contain default active role info
 	DefaultRoleTable = "default_roles"
+	// ServersTable is the table contains the foreign servers.
+	ServersTable = "servers"
+	// ClusterSettingHistoryTable is the table contains the change history of the cluster settings.
+	ClusterSettingHistoryTable = "cluster_setting_history"
 )
 
 // MySQL type maximum length.
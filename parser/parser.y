@@ -26,6 +26,7 @@
 package parser
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/pingcap/tidb/parser/mysql"
@@ -393,6 +394,7 @@ import (
 	expansion             "EXPANSION"
 	expire                "EXPIRE"
 	extended              "EXTENDED"
+	external              "EXTERNAL"
 	faultsSym             "FAULTS"
 	fields                "FIELDS"
 	file                  "FILE"
@@ -483,6 +485,7 @@ import (
 	only                  "ONLY"
 	open                  "OPEN"
 	optional              "OPTIONAL"
+	options               "OPTIONS"
 	packKeys              "PACK_KEYS"
 	pageSym               "PAGE"
 	parser                "PARSER"
@@ -547,6 +550,7 @@ import (
 	sequence              "SEQUENCE"
 	serial                "SERIAL"
 	serializable          "SERIALIZABLE"
+	server                "SERVER"
 	session               "SESSION"
 	setval                "SETVAL"
 	shardRowIDBits        "SHARD_ROW_ID_BITS"
@@ -604,6 +608,8 @@ import (
 	transaction           "TRANSACTION"
 	triggers              "TRIGGERS"
 	truncate              "TRUNCATE"
+	ttl                   "TTL"
+	ttlEnable             "TTL_ENABLE"
 	unbounded             "UNBOUNDED"
 	uncommitted           "UNCOMMITTED"
 	undefined             "UNDEFINED"
@@ -619,6 +625,7 @@ import (
 	week                  "WEEK"
 	weightString          "WEIGHT_STRING"
 	without               "WITHOUT"
+	wrapper               "WRAPPER"
 	x509                  "X509"
 	yearType              "YEAR"
 	wait                  "WAIT"
@@ -714,22 +721,31 @@ import (
 	builtins                   "BUILTINS"
 	cancel                     "CANCEL"
 	cardinality                "CARDINALITY"
+	cluster                    "CLUSTER"
 	cmSketch                   "CMSKETCH"
 	columnStatsUsage           "COLUMN_STATS_USAGE"
 	correlation                "CORRELATION"
 	ddl                        "DDL"
+	decode                     "DECODE"
 	dependency                 "DEPENDENCY"
 	depth                      "DEPTH"
 	drainer                    "DRAINER"
 	jobs                       "JOBS"
 	job                        "JOB"
+	mvcc                       "MVCC"
 	nodeID                     "NODE_ID"
 	nodeState                  "NODE_STATE"
 	optimistic                 "OPTIMISTIC"
 	pessimistic                "PESSIMISTIC"
 	pump                       "PUMP"
+	reclaim                    "RECLAIM"
+	returning                  "RETURNING"
+	safePoint                  "SAFEPOINT"
+	safePoints                 "SAFEPOINTS"
 	samples                    "SAMPLES"
 	sampleRate                 "SAMPLERATE"
+	setting                    "SETTING"
+	settings                   "SETTINGS"
 	statistics                 "STATISTICS"
 	stats                      "STATS"
 	statsMeta                  "STATS_META"
@@ -804,6 +820,7 @@ import (
 	neqSynonym   "<>"
 	nulleq       "<=>"
 	paramMarker  "?"
+	rightArrow   "=>"
 	rsh          ">>"
 
 %token not2
@@ -862,6 +879,7 @@ import (
 	CreateBindingStmt          "CREATE BINDING  statement"
 	CreatePolicyStmt           "CREATE PLACEMENT POLICY statement"
 	CreateSequenceStmt         "CREATE SEQUENCE statement"
+	CreateServerStmt           "CREATE SERVER statement"
 	CreateStatisticsStmt       "CREATE STATISTICS statement"
 	DoStmt                     "Do statement"
 	DropDatabaseStmt           "DROP DATABASE statement"
@@ -871,6 +889,7 @@ import (
 	DropStatsStmt              "DROP STATS statement"
 	DropTableStmt              "DROP TABLE statement"
 	DropSequenceStmt           "DROP SEQUENCE statement"
+	DropServerStmt             "DROP SERVER statement"
 	DropUserStmt               "DROP USER"
 	DropRoleStmt               "DROP ROLE"
 	DropViewStmt               "DROP VIEW statement"
@@ -886,6 +905,7 @@ import (
 	ExplainableStmt            "explainable statement"
 	FlushStmt                  "Flush statement"
 	FlashbackTableStmt         "Flashback table statement"
+	FlashbackClusterStmt       "Flashback cluster statement"
 	GrantStmt                  "Grant statement"
 	GrantProxyStmt             "Grant proxy statement"
 	GrantRoleStmt              "Grant role statement"
@@ -1000,6 +1020,8 @@ import (
 	EscapedTableRef                        "escaped table reference"
 	ExpressionList                         "expression list"
 	ExtendedPriv                           "Extended privileges like LOAD FROM S3 or dynamic privileges"
+	ExternalTableOption                    "named argument of the EXTERNAL table function"
+	ExternalTableOptionList                "named argument list of the EXTERNAL table function"
 	MaxValueOrExpressionList               "maxvalue or expression list"
 	ExpressionListOpt                      "expression list opt"
 	FetchFirstOpt                          "Fetch First/Next Option"
@@ -1119,6 +1141,7 @@ import (
 	ReorganizePartitionRuleOpt             "optional reorganize partition partition list and definitions"
 	RequireList                            "require list"
 	RequireListElement                     "require list element"
+	ReturningOptional                      "optional RETURNING clause"
 	Rolename                               "Rolename"
 	RolenameComposed                       "Rolename that composed with more than 1 symbol"
 	RolenameList                           "RolenameList"
@@ -1145,6 +1168,9 @@ import (
 	SelectStmtIntoOption                   "SELECT statement into clause"
 	SequenceOption                         "Create sequence option"
 	SequenceOptionList                     "Create sequence option list"
+	ServerOption                           "Foreign server option"
+	ServerOptionList                       "Foreign server option list"
+	ServerOptionListOpt                    "Optional foreign server option list"
 	SetRoleOpt                             "Set role options"
 	SetDefaultRoleOpt                      "Set default role options"
 	SetOpr                                 "Set operator contain UNION, EXCEPT and INTERSECT"
@@ -1392,6 +1418,7 @@ import (
 	StringNameOrBRIEOptionKeyword   "string literal or identifier or keyword used for BRIE options"
 	Symbol                          "Constraint Symbol"
 
+%precedence returning
 %precedence empty
 %precedence as
 %precedence placement
@@ -1619,6 +1646,12 @@ AlterTablePartitionOpt:
 			Tp: ast.AlterTableRemovePartitioning,
 		}
 	}
+|	"REMOVE" "TTL"
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp: ast.AlterTableRemoveTTL,
+		}
+	}
 |	"REORGANIZE" "PARTITION" NoWriteToBinLogAliasOpt ReorganizePartitionRuleOpt
 	{
 		ret := $4.(*ast.AlterTableSpec)
@@ -2490,6 +2523,21 @@ FlashbackToNewName:
 		$$ = $2
 	}
 
+/*******************************************************************
+ *
+ *  Flashback Cluster Statement
+ *
+ *  Example:
+ *      FLASHBACK CLUSTER TO TIMESTAMP '2022-05-26 19:01:00'
+ *******************************************************************/
+FlashbackClusterStmt:
+	"FLASHBACK" "CLUSTER" "TO" "TIMESTAMP" stringLit
+	{
+		$$ = &ast.FlashBackClusterStmt{
+			FlashbackTS: ast.NewValueExpr($5, "", ""),
+		}
+	}
+
 /*******************************************************************
  *
  *  Split index region statement
@@ -3749,6 +3797,17 @@ CreateTableStmt:
 		}
 		$$ = tmp
 	}
+|	"CREATE" "FOREIGN" "TABLE" IfNotExists TableName TableElementListOpt "SERVER" Identifier ServerOptionListOpt
+	{
+		stmt := $6.(*ast.CreateTableStmt)
+		stmt.Table = $5.(*ast.TableName)
+		stmt.IfNotExists = $4.(bool)
+		stmt.Foreign = &ast.ForeignTableClause{
+			Server:  $8,
+			Options: $9.([]*ast.ServerOption),
+		}
+		$$ = stmt
+	}
 
 OnCommitOpt:
 	{
@@ -4309,7 +4368,7 @@ DoStmt:
  *
  *******************************************************************/
 DeleteWithoutUsingStmt:
-	"DELETE" TableOptimizerHintsOpt PriorityOpt QuickOptional IgnoreOptional "FROM" TableName PartitionNameListOpt TableAsNameOpt IndexHintListOpt WhereClauseOptional OrderByOptional LimitClause
+	"DELETE" TableOptimizerHintsOpt PriorityOpt QuickOptional IgnoreOptional "FROM" TableName PartitionNameListOpt TableAsNameOpt IndexHintListOpt WhereClauseOptional OrderByOptional LimitClause ReturningOptional
 	{
 		// Single Table
 		tn := $7.(*ast.TableName)
@@ -4334,6 +4393,9 @@ DeleteWithoutUsingStmt:
 		if $13 != nil {
 			x.Limit = $13.(*ast.Limit)
 		}
+		if $14 != nil {
+			x.Returning = $14.(*ast.FieldList)
+		}
 
 		$$ = x
 	}
@@ -4395,6 +4457,15 @@ DeleteFromStmt:
 		$$ = d
 	}
 
+ReturningOptional:
+	{
+		$$ = nil
+	}
+|	"RETURNING" SelectStmtFieldList
+	{
+		$$ = $2
+	}
+
 DatabaseSym:
 	"DATABASE"
 
@@ -4490,6 +4561,67 @@ DropRoleStmt:
 		$$ = &ast.DropUserStmt{IsDropRole: true, IfExists: true, UserList: tmp}
 	}
 
+/*******************************************************************
+ *
+ *  Create Server Statement
+ *
+ *  Example:
+ *      CREATE SERVER s FOREIGN DATA WRAPPER mysql OPTIONS (HOST '127.0.0.1', PORT 3306, USER 'root', DATABASE 'test')
+ *******************************************************************/
+CreateServerStmt:
+	"CREATE" "SERVER" IfNotExists Identifier "FOREIGN" "DATA" "WRAPPER" Identifier "OPTIONS" '(' ServerOptionList ')'
+	{
+		$$ = &ast.CreateServerStmt{
+			IfNotExists: $3.(bool),
+			Name:        $4,
+			Wrapper:     $8,
+			Options:     $11.([]*ast.ServerOption),
+		}
+	}
+
+ServerOptionListOpt:
+	{
+		$$ = []*ast.ServerOption{}
+	}
+|	"OPTIONS" '(' ServerOptionList ')'
+	{
+		$$ = $3
+	}
+
+ServerOptionList:
+	ServerOption
+	{
+		$$ = []*ast.ServerOption{$1.(*ast.ServerOption)}
+	}
+|	ServerOptionList ',' ServerOption
+	{
+		$$ = append($1.([]*ast.ServerOption), $3.(*ast.ServerOption))
+	}
+
+ServerOption:
+	Identifier stringLit
+	{
+		$$ = &ast.ServerOption{Name: strings.ToUpper($1), Value: $2}
+	}
+|	Identifier LengthNum
+	{
+		$$ = &ast.ServerOption{Name: strings.ToUpper($1), Value: strconv.FormatUint($2.(uint64), 10)}
+	}
+|	"DATABASE" stringLit
+	{
+		$$ = &ast.ServerOption{Name: "DATABASE", Value: $2}
+	}
+|	"TABLE" stringLit
+	{
+		$$ = &ast.ServerOption{Name: "TABLE", Value: $2}
+	}
+
+DropServerStmt:
+	"DROP" "SERVER" IfExists Identifier
+	{
+		$$ = &ast.DropServerStmt{IfExists: $3.(bool), Name: $4}
+	}
+
 DropStatsStmt:
 	"DROP" "STATS" TableName
 	{
@@ -5872,6 +6004,7 @@ UnReservedKeyword:
 |	"ONLY"
 |	"SERIAL"
 |	"SERIALIZABLE"
+|	"SERVER"
 |	"LEVEL"
 |	"VARIABLES"
 |	"SQL_CACHE"
@@ -5940,6 +6073,7 @@ UnReservedKeyword:
 |	"X509"
 |	"NEVER"
 |	"EXPIRE"
+|	"EXTERNAL"
 |	"ACCOUNT"
 |	"INCREMENTAL"
 |	"CPU"
@@ -5973,6 +6107,7 @@ UnReservedKeyword:
 |	"SECONDARY_UNLOAD"
 |	"VALIDATION"
 |	"WITHOUT"
+|	"WRAPPER"
 |	"RTREE"
 |	"EXCHANGE"
 |	"COLUMN_FORMAT"
@@ -5981,6 +6116,8 @@ UnReservedKeyword:
 |	"IMPORTS"
 |	"DISCARD"
 |	"TABLE_CHECKSUM"
+|	"TTL"
+|	"TTL_ENABLE"
 |	"UNICODE"
 |	"AUTO_RANDOM"
 |	"AUTO_RANDOM_BASE"
@@ -6056,6 +6193,7 @@ UnReservedKeyword:
 |	"RESUME"
 |	"OFF"
 |	"OPTIONAL"
+|	"OPTIONS"
 |	"REQUIRED"
 |	"PURGE"
 |	"SKIP"
@@ -6070,20 +6208,29 @@ TiDBKeyword:
 |	"BUILTINS"
 |	"CANCEL"
 |	"CARDINALITY"
+|	"CLUSTER"
 |	"CMSKETCH"
 |	"COLUMN_STATS_USAGE"
 |	"CORRELATION"
 |	"DDL"
+|	"DECODE"
 |	"DEPENDENCY"
 |	"DEPTH"
 |	"DRAINER"
 |	"JOBS"
 |	"JOB"
+|	"MVCC"
 |	"NODE_ID"
 |	"NODE_STATE"
 |	"PUMP"
+|	"RECLAIM"
+|	"RETURNING"
+|	"SAFEPOINT"
+|	"SAFEPOINTS"
 |	"SAMPLES"
 |	"SAMPLERATE"
+|	"SETTING"
+|	"SETTINGS"
 |	"STATISTICS"
 |	"STATS"
 |	"STATS_META"
@@ -6941,6 +7088,7 @@ FunctionNameConflict:
 |	"DATE"
 |	"DATABASE"
 |	"DAY"
+|	"DECODE"
 |	"HOUR"
 |	"IF"
 |	"INTERVAL"
@@ -8733,6 +8881,27 @@ TableFactor:
 		j.ExplicitParens = true
 		$$ = $2
 	}
+|	"EXTERNAL" '(' stringLit ExternalTableOptionList ')' TableAsNameOpt
+	{
+		ext := &ast.ExternalTable{URL: $3, Options: $4.([]*ast.ExternalTableOption)}
+		$$ = &ast.TableSource{Source: ext, AsName: $6.(model.CIStr)}
+	}
+
+ExternalTableOptionList:
+	/* empty */
+	{
+		$$ = []*ast.ExternalTableOption{}
+	}
+|	ExternalTableOptionList ',' ExternalTableOption
+	{
+		$$ = append($1.([]*ast.ExternalTableOption), $3.(*ast.ExternalTableOption))
+	}
+
+ExternalTableOption:
+	Identifier "=>" stringLit
+	{
+		$$ = &ast.ExternalTableOption{Name: strings.ToUpper($1), Value: $3}
+	}
 
 PartitionNameListOpt:
 	/* empty */
@@ -10034,6 +10203,14 @@ AdminStmt:
 			Index:  string($5),
 		}
 	}
+|	"ADMIN" "REPAIR" "INDEX" TableName Identifier
+	{
+		$$ = &ast.AdminStmt{
+			Tp:     ast.AdminRepairIndex,
+			Tables: []*ast.TableName{$4.(*ast.TableName)},
+			Index:  string($5),
+		}
+	}
 |	"ADMIN" "CHECK" "INDEX" TableName Identifier HandleRangeList
 	{
 		$$ = &ast.AdminStmt{
@@ -10050,6 +10227,21 @@ AdminStmt:
 			Tables: $4.([]*ast.TableName),
 		}
 	}
+|	"ADMIN" "RECLAIM" "TABLE" TableNameList
+	{
+		$$ = &ast.AdminStmt{
+			Tp:     ast.AdminReclaimTable,
+			Tables: $4.([]*ast.TableName),
+		}
+	}
+|	"ADMIN" "RECLAIM" "TABLE" TableNameList "COMPACT"
+	{
+		$$ = &ast.AdminStmt{
+			Tp:      ast.AdminReclaimTable,
+			Tables:  $4.([]*ast.TableName),
+			Compact: true,
+		}
+	}
 |	"ADMIN" "CANCEL" "DDL" "JOBS" NumList
 	{
 		$$ = &ast.AdminStmt{
@@ -10158,6 +10350,69 @@ AdminStmt:
 			Tp: ast.AdminResetTelemetryID,
 		}
 	}
+|	"ADMIN" "SHOW" "CLUSTER" "SETTINGS"
+	{
+		$$ = &ast.AdminStmt{
+			Tp: ast.AdminShowClusterSettings,
+		}
+	}
+|	"ADMIN" "SET" "CLUSTER" "SETTING" Identifier EqOrAssignmentEq SignedLiteral
+	{
+		$$ = &ast.AdminStmt{
+			Tp:           ast.AdminSetClusterSetting,
+			SettingName:  strings.ToLower($5),
+			SettingValue: $7.(ast.ExprNode),
+		}
+	}
+|	"ADMIN" "SHOW" "SAFEPOINTS"
+	{
+		$$ = &ast.AdminStmt{
+			Tp: ast.AdminShowSafePoints,
+		}
+	}
+|	"ADMIN" "SET" "SAFEPOINT" stringLit EqOrAssignmentEq LengthNum "TTL" LengthNum
+	{
+		$$ = &ast.AdminStmt{
+			Tp:           ast.AdminSetSafePoint,
+			ServiceID:    $4,
+			SafePoint:    $6.(uint64),
+			SafePointTTL: $8.(uint64),
+		}
+	}
+|	"ADMIN" "EXPIRE" "SAFEPOINT" stringLit
+	{
+		$$ = &ast.AdminStmt{
+			Tp:        ast.AdminExpireSafePoint,
+			ServiceID: $4,
+		}
+	}
+|	"ADMIN" "SHOW" "REDUNDANT" "INDEXES"
+	{
+		$$ = &ast.AdminStmt{
+			Tp: ast.AdminShowRedundantIndexes,
+		}
+	}
+|	"ADMIN" "DECODE" "KEY" stringLit
+	{
+		$$ = &ast.AdminStmt{
+			Tp:         ast.AdminDecodeKey,
+			EncodedStr: $4,
+		}
+	}
+|	"ADMIN" "DECODE" "PLAN" stringLit
+	{
+		$$ = &ast.AdminStmt{
+			Tp:         ast.AdminDecodePlan,
+			EncodedStr: $4,
+		}
+	}
+|	"ADMIN" "SHOW" "MVCC" "KEY" stringLit
+	{
+		$$ = &ast.AdminStmt{
+			Tp:         ast.AdminShowMVCCByKey,
+			EncodedStr: $5,
+		}
+	}
 |	"ADMIN" "FLUSH" StatementScope "PLAN_CACHE"
 	{
 		$$ = &ast.AdminStmt{
@@ -10949,6 +11204,7 @@ Statement:
 |	CreateBindingStmt
 |	CreatePolicyStmt
 |	CreateSequenceStmt
+|	CreateServerStmt
 |	CreateStatisticsStmt
 |	DoStmt
 |	DropDatabaseStmt
@@ -10959,12 +11215,14 @@ Statement:
 |	DropSequenceStmt
 |	DropViewStmt
 |	DropUserStmt
+|	DropServerStmt
 |	DropRoleStmt
 |	DropStatisticsStmt
 |	DropStatsStmt
 |	DropBindingStmt
 |	FlushStmt
 |	FlashbackTableStmt
+|	FlashbackClusterStmt
 |	GrantStmt
 |	GrantProxyStmt
 |	GrantRoleStmt
@@ -11195,6 +11453,24 @@ TableOption:
 	{
 		$$ = &ast.TableOption{Tp: ast.TableOptionTableCheckSum, UintValue: $3.(uint64)}
 	}
+|	"TTL" EqOpt Identifier '+' "INTERVAL" LengthNum TimeUnit
+	{
+		$$ = &ast.TableOption{
+			Tp:            ast.TableOptionTTL,
+			ColumnName:    &ast.ColumnName{Name: model.NewCIStr($3)},
+			UintValue:     $6.(uint64),
+			TimeUnitValue: &ast.TimeUnitExpr{Unit: $7.(ast.TimeUnitType)},
+		}
+	}
+|	"TTL_ENABLE" EqOpt stringLit
+	{
+		onOrOff := strings.ToUpper($3)
+		if onOrOff != "ON" && onOrOff != "OFF" {
+			yylex.AppendError(yylex.Errorf("The value of TTL_ENABLE must be one of ['ON'|'OFF']."))
+			return 1
+		}
+		$$ = &ast.TableOption{Tp: ast.TableOptionTTLEnable, BoolValue: onOrOff == "ON"}
+	}
 |	"PASSWORD" EqOpt stringLit
 	{
 		$$ = &ast.TableOption{Tp: ast.TableOptionPassword, StrValue: $3}
@@ -12113,7 +12389,7 @@ UpdateStmt:
 	}
 
 UpdateStmtNoWith:
-	"UPDATE" TableOptimizerHintsOpt PriorityOpt IgnoreOptional TableRef "SET" AssignmentList WhereClauseOptional OrderByOptional LimitClause
+	"UPDATE" TableOptimizerHintsOpt PriorityOpt IgnoreOptional TableRef "SET" AssignmentList WhereClauseOptional OrderByOptional LimitClause ReturningOptional
 	{
 		var refs *ast.Join
 		if x, ok := $5.(*ast.Join); ok {
@@ -12139,6 +12415,9 @@ UpdateStmtNoWith:
 		if $10 != nil {
 			st.Limit = $10.(*ast.Limit)
 		}
+		if $11 != nil {
+			st.Returning = $11.(*ast.FieldList)
+		}
 		$$ = st
 	}
 |	"UPDATE" TableOptimizerHintsOpt PriorityOpt IgnoreOptional TableRefs "SET" AssignmentList WhereClauseOptional
@@ -730,6 +730,28 @@ func TestDMLStmt(t *testing.T) {
 		{"DELETE FROM x.y z WHERE z.a > 0", true, "DELETE FROM `x`.`y` AS `z` WHERE `z`.`a`>0"},
 		{"DELETE FROM t1 AS w WHERE a > 0", true, "DELETE FROM `t1` AS `w` WHERE `a`>0"},
 		{"DELETE from t1 partition (p0,p1)", true, "DELETE FROM `t1` PARTITION(`p0`, `p1`)"},
+		// for foreign server and foreign table
+		{"CREATE SERVER s FOREIGN DATA WRAPPER mysql OPTIONS (HOST '127.0.0.1', PORT 3306, USER 'root', password 'pw', DATABASE 'test')", true, "CREATE SERVER `s` FOREIGN DATA WRAPPER `mysql` OPTIONS (HOST '127.0.0.1', PORT 3306, USER 'root', PASSWORD 'pw', DATABASE 'test')"},
+		{"create server if not exists s foreign data wrapper mysql options (socket '/tmp/mysql.sock')", true, "CREATE SERVER IF NOT EXISTS `s` FOREIGN DATA WRAPPER `mysql` OPTIONS (SOCKET '/tmp/mysql.sock')"},
+		{"CREATE SERVER s FOREIGN DATA WRAPPER mysql", false, ""},
+		{"CREATE SERVER s FOREIGN DATA WRAPPER mysql OPTIONS ()", false, ""},
+		{"DROP SERVER s", true, "DROP SERVER `s`"},
+		{"DROP SERVER IF EXISTS s", true, "DROP SERVER IF EXISTS `s`"},
+		{"CREATE FOREIGN TABLE t (a int, b varchar(10)) SERVER s", true, "CREATE FOREIGN TABLE `t` (`a` INT,`b` VARCHAR(10)) SERVER `s`"},
+		{"CREATE FOREIGN TABLE IF NOT EXISTS db.t (a int) SERVER s OPTIONS (DATABASE 'remote', TABLE 'rt')", true, "CREATE FOREIGN TABLE IF NOT EXISTS `db`.`t` (`a` INT) SERVER `s` OPTIONS (DATABASE 'remote', TABLE 'rt')"},
+		{"CREATE FOREIGN TABLE t (a int)", false, ""},
+		{"create table server (server int)", true, "CREATE TABLE `server` (`server` INT)"},
+		{"select * from external('s3://bucket/x.parquet', format => 'parquet')", true, "SELECT * FROM EXTERNAL('s3://bucket/x.parquet', FORMAT => 'parquet')"},
+		{"select e.a from external('s3://bucket/x.csv', header=>'false', separator => '|') as e join t on e.a = t.a", true, "SELECT `e`.`a` FROM EXTERNAL('s3://bucket/x.csv', HEADER => 'false', SEPARATOR => '|') AS `e` JOIN `t` ON `e`.`a`=`t`.`a`"},
+		{"select * from external('/tmp/x.csv') e", true, "SELECT * FROM EXTERNAL('/tmp/x.csv') AS `e`"},
+		{"select external from external", true, "SELECT `external` FROM `external`"},
+		{"select * from external('/tmp/x.csv', format 'csv')", false, ""},
+		{"select * from external(format => 'csv')", false, ""},
+		{"DELETE FROM t1 WHERE a > 0 RETURNING *", true, "DELETE FROM `t1` WHERE `a`>0 RETURNING *"},
+		{"DELETE FROM t1 ORDER BY a LIMIT 2 RETURNING a, b + 1 AS c", true, "DELETE FROM `t1` ORDER BY `a` LIMIT 2 RETURNING `a`, `b`+1 AS `c`"},
+		{"DELETE FROM t1 AS w RETURNING w.a", true, "DELETE FROM `t1` AS `w` RETURNING `w`.`a`"},
+		{"DELETE FROM t1 RETURNING", false, ""},
+		{"DELETE t1 FROM t1, t2 RETURNING t1.a", false, ""},
 
 		// multi table syntax: before from
 		{"delete low_priority t1, t2 from t1, t2", true, "DELETE LOW_PRIORITY `t1`,`t2` FROM (`t1`) JOIN `t2`"},
@@ -794,6 +816,7 @@ func TestDMLStmt(t *testing.T) {
 		{"admin cancel ddl jobs 1, 2", true, "ADMIN CANCEL DDL JOBS 1, 2"},
 		{"admin recover index t1 idx_a", true, "ADMIN RECOVER INDEX `t1` idx_a"},
 		{"admin cleanup index t1 idx_a", true, "ADMIN CLEANUP INDEX `t1` idx_a"},
+		{"admin repair index t1 idx_a", true, "ADMIN REPAIR INDEX `t1` idx_a"},
 		{"admin show slow top 3", true, "ADMIN SHOW SLOW TOP 3"},
 		{"admin show slow top internal 7", true, "ADMIN SHOW SLOW TOP INTERNAL 7"},
 		{"admin show slow top all 9", true, "ADMIN SHOW SLOW TOP ALL 9"},
@@ -807,6 +830,27 @@ func TestDMLStmt(t *testing.T) {
 		{"admin reload bindings", true, "ADMIN RELOAD BINDINGS"},
 		{"admin show telemetry", true, "ADMIN SHOW TELEMETRY"},
 		{"admin reset telemetry_id", true, "ADMIN RESET TELEMETRY_ID"},
+		{"admin reclaim table t1", true, "ADMIN RECLAIM TABLE `t1`"},
+		{"admin reclaim table test.t1, t2 compact", true, "ADMIN RECLAIM TABLE `test`.`t1`, `t2` COMPACT"},
+		{"admin reclaim table", false, ""},
+		{"admin show cluster settings", true, "ADMIN SHOW CLUSTER SETTINGS"},
+		{"admin set cluster setting tikv_gc_life_time = '20m'", true, "ADMIN SET CLUSTER SETTING `tikv_gc_life_time` = _UTF8MB4'20m'"},
+		{"admin set cluster setting TiKV_GC_Concurrency := 4", true, "ADMIN SET CLUSTER SETTING `tikv_gc_concurrency` = 4"},
+		{"admin set cluster setting tikv_gc_enable = false", true, "ADMIN SET CLUSTER SETTING `tikv_gc_enable` = FALSE"},
+		{"admin set cluster setting tikv_gc_life_time", false, ""},
+		{"admin set cluster setting tikv_gc_life_time = now()", false, ""},
+		{"admin show safepoints", true, "ADMIN SHOW SAFEPOINTS"},
+		{"admin show redundant indexes", true, "ADMIN SHOW REDUNDANT INDEXES"},
+		{"admin decode key '7480000000000000FF3E5F720000000000FA'", true, "ADMIN DECODE KEY '7480000000000000FF3E5F720000000000FA'"},
+		{"admin decode plan 'WgAwJzAJMjdfMgkwCTAJTi9BCTAJdGltZToxMC4zMcK1cywgbG9vcHM6MQkwIEJ5dGVzCU4vQQo='", true, "ADMIN DECODE PLAN 'WgAwJzAJMjdfMgkwCTAJTi9BCTAJdGltZToxMC4zMcK1cywgbG9vcHM6MQkwIEJ5dGVzCU4vQQo='"},
+		{"admin decode key", false, ""},
+		{"admin show mvcc key '7480000000000000FF3E5F720000000000FA'", true, "ADMIN SHOW MVCC KEY '7480000000000000FF3E5F720000000000FA'"},
+		{"admin show mvcc key 1", false, ""},
+		{"admin set safepoint 'ticdc' = 430000000000000000 ttl 86400", true, "ADMIN SET SAFEPOINT 'ticdc' = 430000000000000000 TTL 86400"},
+		{"admin set safepoint 'br' := 4300 ttl 60", true, "ADMIN SET SAFEPOINT 'br' = 4300 TTL 60"},
+		{"admin set safepoint 'br' = 4300", false, ""},
+		{"admin expire safepoint 'ticdc'", true, "ADMIN EXPIRE SAFEPOINT 'ticdc'"},
+		{"admin expire safepoint", false, ""},
 		// This case would be removed once TiDB PR to remove ADMIN RELOAD STATISTICS is merged.
 		{"admin reload statistics", true, "ADMIN RELOAD STATS_EXTENDED"},
 		{"admin reload stats_extended", true, "ADMIN RELOAD STATS_EXTENDED"},
@@ -828,6 +872,9 @@ func TestDMLStmt(t *testing.T) {
 		{"UPDATE LOW_PRIORITY IGNORE t SET id = id + 1 ORDER BY id DESC;", true, "UPDATE LOW_PRIORITY IGNORE `t` SET `id`=`id`+1 ORDER BY `id` DESC"},
 		{"UPDATE t SET id = id + 1 ORDER BY id DESC;", true, "UPDATE `t` SET `id`=`id`+1 ORDER BY `id` DESC"},
 		{"UPDATE t SET id = id + 1 ORDER BY id DESC limit 3 ;", true, "UPDATE `t` SET `id`=`id`+1 ORDER BY `id` DESC LIMIT 3"},
+		{"UPDATE t SET id = id + 1 WHERE id > 1 RETURNING id, c", true, "UPDATE `t` SET `id`=`id`+1 WHERE `id`>1 RETURNING `id`, `c`"},
+		{"UPDATE t SET id = id + 1 LIMIT 3 RETURNING *", true, "UPDATE `t` SET `id`=`id`+1 LIMIT 3 RETURNING *"},
+		{"UPDATE t1, t2 SET t1.a = 1 RETURNING t1.a", false, ""},
 		{"UPDATE t SET id = id + 1, name = 'jojo';", true, "UPDATE `t` SET `id`=`id`+1, `name`=_UTF8MB4'jojo'"},
 		{"UPDATE items,month SET items.price=month.price WHERE items.id=month.id;", true, "UPDATE (`items`) JOIN `month` SET `items`.`price`=`month`.`price` WHERE `items`.`id`=`month`.`id`"},
 		{"UPDATE user T0 LEFT OUTER JOIN user_profile T1 ON T1.id = T0.profile_id SET T0.profile_id = 1 WHERE T0.profile_id IN (1);", true, "UPDATE `user` AS `T0` LEFT JOIN `user_profile` AS `T1` ON `T1`.`id`=`T0`.`profile_id` SET `T0`.`profile_id`=1 WHERE `T0`.`profile_id` IN (1)"},
@@ -2361,6 +2408,15 @@ func TestDDL(t *testing.T) {
 		{"create table t (c int) checksum 1", true, "CREATE TABLE `t` (`c` INT) CHECKSUM = 1"},
 		{"create table t (c int) table_checksum = 0", true, "CREATE TABLE `t` (`c` INT) TABLE_CHECKSUM = 0"},
 		{"create table t (c int) table_checksum 1", true, "CREATE TABLE `t` (`c` INT) TABLE_CHECKSUM = 1"},
+		{"create table t (c datetime) ttl = c + interval 1 day", true, "CREATE TABLE `t` (`c` DATETIME) TTL = `c` + INTERVAL 1 DAY"},
+		{"create table t (c datetime) ttl c + interval 3 month ttl_enable = 'off'", true, "CREATE TABLE `t` (`c` DATETIME) TTL = `c` + INTERVAL 3 MONTH TTL_ENABLE = 'OFF'"},
+		{"create table t (c datetime) ttl = c + interval 1.5 day", false, ""},
+		{"create table t (c datetime) ttl = c - interval 1 day", false, ""},
+		{"create table t (c datetime) ttl = c + interval 1 day ttl_enable = 'yes'", false, ""},
+		{"alter table t ttl = c + interval 1 hour", true, "ALTER TABLE `t` TTL = `c` + INTERVAL 1 HOUR"},
+		{"alter table t ttl_enable = 'ON'", true, "ALTER TABLE `t` TTL_ENABLE = 'ON'"},
+		{"alter table t remove ttl", true, "ALTER TABLE `t` REMOVE TTL"},
+		{"create table ttl (ttl int, ttl_enable int)", true, "CREATE TABLE `ttl` (`ttl` INT,`ttl_enable` INT)"},
 		{"create table t (c int) compression = 'NONE'", true, "CREATE TABLE `t` (`c` INT) COMPRESSION = 'NONE'"},
 		{"create table t (c int) compression 'lz4'", true, "CREATE TABLE `t` (`c` INT) COMPRESSION = 'lz4'"},
 		{"create table t (c int) connection = 'abc'", true, "CREATE TABLE `t` (`c` INT) CONNECTION = 'abc'"},
@@ -3199,6 +3255,11 @@ func TestDDL(t *testing.T) {
 		{"flashback table t", true, "FLASHBACK TABLE `t`"},
 		{"flashback table t TO t1", true, "FLASHBACK TABLE `t` TO `t1`"},
 
+		// for flashback cluster.
+		{"flashback cluster to timestamp '2022-05-26 19:01:00'", true, "FLASHBACK CLUSTER TO TIMESTAMP '2022-05-26 19:01:00'"},
+		{"flashback cluster to timestamp now()", false, ""},
+		{"flashback cluster timestamp '2022-05-26 19:01:00'", false, ""},
+
 		// for remove partitioning
 		{"alter table t remove partitioning", true, "ALTER TABLE `t` REMOVE PARTITIONING"},
 		{"alter table db.ident remove partitioning", true, "ALTER TABLE `db`.`ident` REMOVE PARTITIONING"},
@@ -4408,6 +4469,59 @@ func TestParserErrMsg(t *testing.T) {
 	RunErrMsgTest(t, funcCallMsgCases)
 }
 
+func TestParseSQLWithRecovery(t *testing.T) {
+	type testErrRange struct {
+		text   string
+		line   int
+		column int
+		msg    string
+	}
+	cases := []struct {
+		src   string
+		stmts []string
+		errs  []testErrRange
+	}{
+		{"select 1; select 2", []string{"select 1;", "select 2"}, nil},
+		{"select * from t where a = = 1 and b = = 2", nil, []testErrRange{
+			{"=", 1, 27, "line 1 column 27 near \"= 1 and b = = 2\" "},
+			{"=", 1, 39, "line 1 column 39 near \"= 2\" "},
+		}},
+		{"selec * from t; select 1;\nselect * fromm t where; select 2", []string{"select 1;", "select 2"}, []testErrRange{
+			{"selec * from t", 1, 1, "line 1 column 5 near \"selec * from t; select 1;\nselect * fromm t where; select 2\" "},
+			{"fromm t", 2, 10, "line 2 column 15 near \"fromm t where; select 2\" "},
+			{";", 2, 23, "line 2 column 24 near \"; select 2\" "},
+		}},
+		{"create table t (a int,, b int) comment 'x' 'y'", nil, []testErrRange{
+			{",", 1, 23, "line 1 column 23 near \", b int) comment 'x' 'y'\" "},
+			{"'y'", 1, 44, "line 1 column 46 near \"'y'\" "},
+		}},
+		{"create table t(f_year year(5)); select 'abc", nil, []testErrRange{
+			{");", 1, 30, "[parser:1818]Supports only YEAR or YEAR(4) column"},
+			{"'abc", 1, 40, "line 1 column 43 near \"'abc\" "},
+		}},
+		{"select 1; select 1/* abc", []string{"select 1;"}, []testErrRange{
+			{"/* abc", 1, 19, "near '/* abc' at line 1"},
+		}},
+	}
+
+	p := parser.New()
+	for _, c := range cases {
+		stmts, _, errs, err := p.ParseSQLWithRecovery(c.src)
+		require.NoError(t, err)
+		require.Len(t, stmts, len(c.stmts), c.src)
+		for i, stmt := range stmts {
+			require.Equal(t, c.stmts[i], stmt.Text())
+		}
+		require.Len(t, errs, len(c.errs), c.src)
+		for i, e := range errs {
+			require.Equal(t, c.errs[i].text, c.src[e.Start:e.End])
+			require.Equal(t, c.errs[i].line, e.Line)
+			require.Equal(t, c.errs[i].column, e.Column)
+			require.EqualError(t, e, c.errs[i].msg)
+		}
+	}
+}
+
 type subqueryChecker struct {
 	text string
 	t    *testing.T
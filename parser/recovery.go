@@ -0,0 +1,180 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"strings"
+
+	"github.com/pingcap/tidb/parser/ast"
+)
+
+// maxSyntaxErrorRecoveries is the most times a statement is parsed again after skipping the text of a syntax error.
+const maxSyntaxErrorRecoveries = 10
+
+// errSpan is the range of the text an error is found at, end is exclusive.
+type errSpan struct {
+	start int
+	end   int
+}
+
+// SyntaxError is an error found by ParseSQLWithRecovery, with the range of the text it's found at.
+type SyntaxError struct {
+	// Start and End are the byte offsets of the text in the SQL, End is exclusive. They are equal if the error is
+	// found at the end of the SQL.
+	Start int
+	End   int
+	// Line and Column are the position of Start, both of them are 1-based and Column counts the bytes.
+	Line   int
+	Column int
+	Err    error
+}
+
+// Error implements the error interface.
+func (e *SyntaxError) Error() string {
+	return e.Err.Error()
+}
+
+// Cause returns the underlying error.
+func (e *SyntaxError) Cause() error {
+	return e.Err
+}
+
+// stmtRange is the range of a statement in the SQL, including the ';' ending the statement.
+type stmtRange struct {
+	start Pos
+	end   int
+}
+
+// ParseSQLWithRecovery parses a query string like ParseSQL, but it doesn't stop at the first error. The statements
+// are parsed one by one, and after a syntax error the statement is parsed again with the text of the error skipped,
+// so all the syntax errors are reported with their ranges in the query. Only the statements without errors are
+// returned. The err is only returned if the params can't be applied.
+func (parser *Parser) ParseSQLWithRecovery(sql string, params ...ParseParam) (stmts []ast.StmtNode, warns []error, syntaxErrs []*SyntaxError, err error) {
+	for _, r := range parser.splitStmts(sql) {
+		src := sql
+		seen := make(map[errSpan]struct{})
+		var stmtErrs []*SyntaxError
+		for i := 0; ; i++ {
+			accepted, err := parser.parseStmtRange(src, r, params)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			w, errs := parser.lexer.Errors()
+			if i == 0 {
+				warns = append(warns, w...)
+			}
+			if len(errs) == 0 {
+				if len(stmtErrs) == 0 {
+					for _, stmt := range parser.result {
+						ast.SetFlag(stmt)
+						stmts = append(stmts, stmt)
+					}
+				}
+				break
+			}
+			for j, e := range errs {
+				span := parser.lexer.errSpans[j]
+				if _, ok := seen[span]; ok {
+					continue
+				}
+				seen[span] = struct{}{}
+				// The errors following the skipped text of the last one are mostly caused by it, so they are
+				// merged into one.
+				if n := len(stmtErrs); n > 0 && stmtErrs[n-1].End <= span.start &&
+					strings.TrimSpace(src[stmtErrs[n-1].End:span.start]) == "" {
+					if span.end > stmtErrs[n-1].End {
+						stmtErrs[n-1].End = span.end
+					}
+					continue
+				}
+				line, column := lineColumn(sql, span.start)
+				stmtErrs = append(stmtErrs, &SyntaxError{Start: span.start, End: span.end, Line: line, Column: column, Err: e})
+			}
+			// The statement is parsed to the end if it's accepted, the errors are not syntax errors.
+			if accepted || i+1 >= maxSyntaxErrorRecoveries {
+				break
+			}
+			span := parser.lexer.errSpans[len(errs)-1]
+			if span.end <= span.start {
+				break
+			}
+			src = src[:span.start] + blankText(src[span.start:span.end]) + src[span.end:]
+		}
+		syntaxErrs = append(syntaxErrs, stmtErrs...)
+	}
+	return stmts, warns, syntaxErrs, nil
+}
+
+// splitStmts splits the SQL into the statements by the ';' tokens, the empty statements are skipped.
+func (parser *Parser) splitStmts(sql string) []stmtRange {
+	s := &parser.lexer
+	s.reset(sql)
+	var ranges []stmtRange
+	start := s.r.pos()
+	empty := true
+	for {
+		tok, pos, _ := s.scan()
+		switch tok {
+		case ';':
+			if !empty {
+				ranges = append(ranges, stmtRange{start: start, end: pos.Offset + 1})
+			}
+			start = s.r.pos()
+			empty = true
+		case 0:
+			// The scanner also stops at the unclosed comment, whose error is reported by parsing the statement.
+			if !empty || len(s.errs) > 0 {
+				ranges = append(ranges, stmtRange{start: start, end: len(sql)})
+			}
+			return ranges
+		default:
+			if empty {
+				start = pos
+			}
+			empty = false
+		}
+	}
+}
+
+// parseStmtRange parses the statement in the range of src, it returns whether the statement is parsed to the end.
+func (parser *Parser) parseStmtRange(src string, r stmtRange, params []ParseParam) (bool, error) {
+	resetParams(parser)
+	parser.lexer.resetRange(src, r.start, r.end)
+	for _, p := range params {
+		if err := p.ApplyOn(parser); err != nil {
+			return false, err
+		}
+	}
+	parser.src = src
+	parser.result = parser.result[:0]
+	return yyParse(&parser.lexer, parser) == 0, nil
+}
+
+// blankText replaces the text with spaces, the new lines are kept so the positions after it are not changed.
+func blankText(text string) string {
+	b := []byte(text)
+	for i := range b {
+		if b[i] != '\n' {
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
+
+func lineColumn(sql string, offset int) (line, column int) {
+	line = strings.Count(sql[:offset], "\n") + 1
+	column = offset - strings.LastIndexByte(sql[:offset], '\n')
+	return line, column
+}
@@ -183,6 +183,10 @@ type PlanCacheValue struct {
 	TblInfo2UnionScan map[*model.TableInfo]bool
 	UserVarTypes      FieldSlice
 	BindSQL           string
+
+	item *planCacheItem
+	// deviatedExecutions is the number of the consecutive executions whose rows deviate from the estimates.
+	deviatedExecutions int
 }
 
 // NewPlanCacheValue creates a SQLCacheValue.
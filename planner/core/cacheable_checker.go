@@ -126,6 +126,10 @@ func (checker *cacheableChecker) Enter(in ast.Node) (out ast.Node, skipChildren
 			checker.cacheable = false
 			return in, true
 		}
+	case *ast.ExternalTable:
+		// The columns are inferred from the file when the plan is built.
+		checker.cacheable = false
+		return in, true
 	case *ast.TableName:
 		if checker.schema != nil {
 			if checker.isPartitionTable(node) {
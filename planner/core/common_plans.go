@@ -105,6 +105,14 @@ type CleanupIndex struct {
 	IndexName string
 }
 
+// RepairIndex is used to rebuild the index entries inconsistent with the row data.
+type RepairIndex struct {
+	baseSchemaProducer
+
+	Table     *ast.TableName
+	IndexName string
+}
+
 // CheckIndexRange is used for checking index data, output the index values that handle within begin and end.
 type CheckIndexRange struct {
 	baseSchemaProducer
@@ -122,6 +130,14 @@ type ChecksumTable struct {
 	Tables []*ast.TableName
 }
 
+// ReclaimTable is used for merging the empty regions of tables, built from the `admin reclaim table` statement.
+type ReclaimTable struct {
+	baseSchemaProducer
+
+	Tables  []*ast.TableName
+	Compact bool
+}
+
 // CancelDDLJobs represents a cancel DDL jobs plan.
 type CancelDDLJobs struct {
 	baseSchemaProducer
@@ -166,6 +182,64 @@ type AdminResetTelemetryID struct {
 	baseSchemaProducer
 }
 
+// AdminShowClusterSettings displays the cluster settings stored in mysql.tidb.
+type AdminShowClusterSettings struct {
+	baseSchemaProducer
+}
+
+// AdminSetClusterSetting changes a cluster setting stored in mysql.tidb.
+type AdminSetClusterSetting struct {
+	baseSchemaProducer
+	Name  string
+	Value string
+}
+
+// AdminShowSafePoints displays the service safe points registered to PD.
+type AdminShowSafePoints struct {
+	baseSchemaProducer
+}
+
+// AdminShowRedundantIndexes displays the indexes that are never used or covered by other indexes.
+type AdminShowRedundantIndexes struct {
+	baseSchemaProducer
+}
+
+// AdminDecodeKey decodes a hex record or index key into its table, handle and index values.
+type AdminDecodeKey struct {
+	baseSchemaProducer
+
+	Key string
+}
+
+// AdminDecodePlan decodes an encoded plan, or the plan of a plan digest in the statements summary.
+type AdminDecodePlan struct {
+	baseSchemaProducer
+
+	Plan string
+}
+
+// AdminShowMVCCByKey displays the MVCC versions of a hex key in TiKV.
+type AdminShowMVCCByKey struct {
+	baseSchemaProducer
+
+	Key string
+}
+
+// AdminSetSafePoint registers or renews a service safe point in PD.
+type AdminSetSafePoint struct {
+	baseSchemaProducer
+	ServiceID string
+	SafePoint uint64
+	// TTL is in seconds.
+	TTL int64
+}
+
+// AdminExpireSafePoint removes a service safe point from PD.
+type AdminExpireSafePoint struct {
+	baseSchemaProducer
+	ServiceID string
+}
+
 // Change represents a change plan.
 type Change struct {
 	baseSchemaProducer
@@ -194,6 +268,8 @@ type Execute struct {
 	Stmt             ast.StmtNode
 	StmtType         string
 	Plan             Plan
+	// CacheHit is set if the plan is got from the prepared plan cache.
+	CacheHit *PlanCacheHit
 }
 
 // Check if result of GetVar expr is BinaryLiteral
@@ -301,7 +377,7 @@ func (e *Execute) OptimizePreparedPlan(ctx context.Context, sctx sessionctx.Cont
 	// And update lastUpdateTime to the newest one.
 	expiredTimeStamp4PC := domain.GetDomain(sctx).ExpiredTimeStamp4PC()
 	if prepared.UseCache && expiredTimeStamp4PC.Compare(vars.LastUpdateTime4PC) > 0 {
-		sctx.PreparedPlanCache().DeleteAll()
+		DeleteAllPlanCache(sctx.PreparedPlanCache(), PlanCacheEvictFlush)
 		prepared.CachedPlan = nil
 		vars.LastUpdateTime4PC = expiredTimeStamp4PC
 	}
@@ -480,7 +556,7 @@ func (e *Execute) getPhysicalPlan(ctx context.Context, sctx sessionctx.Context,
 					// When BindSQL does not match, it means that we have added a new binding,
 					// and the original cached plan will be invalid,
 					// so the original cached plan can be cleared directly
-					sctx.PreparedPlanCache().Delete(cacheKey)
+					DeletePlanCache(sctx.PreparedPlanCache(), cacheKey, PlanCacheEvictBindingChanged)
 					break
 				}
 				if !cachedVal.UserVarTypes.Equal(tps) {
@@ -492,7 +568,7 @@ func (e *Execute) getPhysicalPlan(ctx context.Context, sctx sessionctx.Context,
 						planValid = false
 						// TODO we can inject UnionScan into cached plan to avoid invalidating it, though
 						// rebuilding the filters in UnionScan is pretty trivial.
-						sctx.PreparedPlanCache().Delete(cacheKey)
+						DeletePlanCache(sctx.PreparedPlanCache(), cacheKey, PlanCacheEvictDirtyTable)
 						break
 					}
 				}
@@ -519,8 +595,10 @@ func (e *Execute) getPhysicalPlan(ctx context.Context, sctx sessionctx.Context,
 					} else {
 						planCacheCounter.Inc()
 					}
+					cachedVal.recordHit()
 					e.names = cachedVal.OutPutNames
 					e.Plan = cachedVal.Plan
+					e.CacheHit = &PlanCacheHit{Key: cacheKey, Value: cachedVal}
 					stmtCtx.SetPlanDigest(preparedStmt.NormalizedPlan, preparedStmt.PlanDigest)
 					return nil
 				}
@@ -555,11 +633,14 @@ REBUILD:
 		cached := NewPlanCacheValue(p, names, stmtCtx.TblInfo2UnionScan, tps, sessVars.StmtCtx.BindSQL)
 		preparedStmt.NormalizedPlan, preparedStmt.PlanDigest = NormalizePlan(p)
 		stmtCtx.SetPlanDigest(preparedStmt.NormalizedPlan, preparedStmt.PlanDigest)
+		cached.item = newPlanCacheItem(sessVars, preparedStmt, cached)
+		registerPlanCacheValue(sctx.PreparedPlanCache(), cached)
 		if cacheVals, exists := sctx.PreparedPlanCache().Get(cacheKey); exists {
 			hitVal := false
 			for i, cacheVal := range cacheVals.([]*PlanCacheValue) {
 				if cacheVal.UserVarTypes.Equal(tps) {
 					hitVal = true
+					evictPlanCacheValues(sctx.PreparedPlanCache(), []*PlanCacheValue{cacheVal}, PlanCacheEvictReplaced)
 					cacheVals.([]*PlanCacheValue)[i] = cached
 					break
 				}
@@ -1011,9 +1092,62 @@ type Update struct {
 	// e.g. update t partition(p0) set a = 1;
 	PartitionedTable []table.PartitionedTable
 
+	// Returning is the RETURNING clause evaluated on the new values of the updated rows.
+	Returning []expression.Expression
+
 	tblID2Table map[int64]table.Table
 }
 
+// IsCommutative checks whether the update only adds integer constants to the non-index integer columns of the
+// rows got by their handles or unique keys, such as `UPDATE t SET c = c + 1 WHERE id = 1`. The result of such an
+// update doesn't depend on the values it reads, so running it again on a newer snapshot is the same as running it
+// after the transactions committed in between.
+func (p *Update) IsCommutative() bool {
+	if len(p.Returning) > 0 || len(p.TblColPosInfos) != 1 || p.VirtualAssignmentsOffset == 0 {
+		return false
+	}
+	var tblInfo *model.TableInfo
+	switch x := p.SelectPlan.(type) {
+	case *PointGetPlan:
+		tblInfo = x.TblInfo
+	case *BatchPointGetPlan:
+		tblInfo = x.TblInfo
+	default:
+		return false
+	}
+	for _, assign := range p.OrderedList[:p.VirtualAssignmentsOffset] {
+		colInfo := model.FindColumnInfoByID(tblInfo.Columns, assign.Col.ID)
+		if colInfo == nil || !mysql.IsIntegerType(colInfo.Tp) || colInfo.IsGenerated() ||
+			(tblInfo.PKIsHandle && mysql.HasPriKeyFlag(colInfo.Flag)) || tblInfo.ColumnIsInIndex(colInfo) {
+			return false
+		}
+		if !isIncrementOf(assign.Expr, assign.Col) {
+			return false
+		}
+	}
+	return true
+}
+
+// isIncrementOf checks whether expr is `col + constant` or `constant + col` of integers, which may be cast to the type of col.
+func isIncrementOf(expr expression.Expression, col *expression.Column) bool {
+	sf, ok := expr.(*expression.ScalarFunction)
+	if ok && sf.FuncName.L == ast.Cast {
+		sf, ok = sf.GetArgs()[0].(*expression.ScalarFunction)
+	}
+	if !ok || sf.FuncName.L != ast.Plus {
+		return false
+	}
+	args := sf.GetArgs()
+	for i := range args {
+		c, isCol := args[i].(*expression.Column)
+		con, isCon := args[1-i].(*expression.Constant)
+		if isCol && isCon && c.Equal(nil, col) && con.GetType().EvalType() == types.ETInt {
+			return true
+		}
+	}
+	return false
+}
+
 // Delete represents a delete plan.
 type Delete struct {
 	baseSchemaProducer
@@ -1023,6 +1157,9 @@ type Delete struct {
 	SelectPlan PhysicalPlan
 
 	TblColPosInfos TblColPosInfoSlice
+
+	// Returning is the RETURNING clause evaluated on the deleted rows.
+	Returning []expression.Expression
 }
 
 // AnalyzeInfo is used to store the database name, table name and partition name of analyze task.
@@ -1184,6 +1321,24 @@ func GetExplainRowsForPlan(plan Plan) (rows [][]string) {
 	return explain.Rows
 }
 
+// GetExplainAnalyzeRowsForPlan gets the explain rows of the plan with the runtime stats collected so far, like
+// `explain for connection` does.
+func GetExplainAnalyzeRowsForPlan(plan interface{}, runtimeStatsColl *execdetails.RuntimeStatsColl) (rows [][]string) {
+	p, ok := plan.(Plan)
+	if !ok || p == nil {
+		return rows
+	}
+	explain := &Explain{
+		TargetPlan:       p,
+		Format:           types.ExplainFormatROW,
+		RuntimeStatsColl: runtimeStatsColl,
+	}
+	if err := explain.RenderResult(); err != nil {
+		return rows
+	}
+	return explain.Rows
+}
+
 // prepareSchema prepares explain's result schema.
 func (e *Explain) prepareSchema() error {
 	var fieldNames []string
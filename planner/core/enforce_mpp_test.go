@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/testkit"
 	"github.com/pingcap/tidb/testkit/testdata"
+	"github.com/pingcap/tidb/util/clustercap"
 	"github.com/pingcap/tidb/util/collate"
 	"github.com/stretchr/testify/require"
 )
@@ -346,3 +347,48 @@ func TestEnforceMPPWarning4(t *testing.T) {
 		require.Equal(t, output[i].Warn, testdata.ConvertSQLWarnToStrings(tk.Session().GetSessionVars().StmtCtx.GetWarnings()))
 	}
 }
+
+// Test enforce mpp warning when some TiFlash nodes are not upgraded yet
+func TestEnforceMPPWarningBeforeUpgrade(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	defer clustercap.Reset()
+	tk := testkit.NewTestKit(t, store)
+
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("CREATE TABLE t(a int, b int)")
+
+	// Create virtual tiflash replica info.
+	dom := domain.GetDomain(tk.Session())
+	is := dom.InfoSchema()
+	db, exists := is.SchemaByName(model.NewCIStr("test"))
+	require.True(t, exists)
+	for _, tblInfo := range db.Tables {
+		if tblInfo.Name.L == "t" {
+			tblInfo.TiFlashReplica = &model.TiFlashReplicaInfo{
+				Count:     1,
+				Available: true,
+			}
+		}
+	}
+
+	tk.MustExec("set @@tidb_allow_mpp=1; set @@tidb_enforce_mpp=1;")
+	tk.MustExec("set @@tidb_isolation_read_engines = 'tiflash'")
+	clustercap.Update(map[clustercap.Component][]string{clustercap.TiFlash: {"v4.0.9", "v5.4.0"}})
+	rows := tk.MustQuery("explain format = 'brief' select count(*) from t group by b").Rows()
+	for _, row := range rows {
+		require.NotContains(t, row[0], "ExchangeSender")
+	}
+	warnings := tk.MustQuery("show warnings").Rows()
+	require.NotEmpty(t, warnings)
+	for _, warning := range warnings {
+		require.Equal(t, "MPP mode may be blocked because MPP execution requires all the tiflash nodes to be v5.0.0 "+
+			"or later, but some of them are v4.0.9.", warning[2])
+	}
+
+	clustercap.Update(map[clustercap.Component][]string{clustercap.TiFlash: {"v5.4.0"}})
+	rows = tk.MustQuery("explain format = 'brief' select count(*) from t group by b").Rows()
+	require.Contains(t, rows[1][0], "ExchangeSender")
+	tk.MustQuery("show warnings").Check(testkit.Rows())
+}
@@ -2241,11 +2241,45 @@ func MatchItems(p *property.PhysicalProperty, items []*util.ByItems) bool {
 
 func (lt *LogicalTopN) exhaustPhysicalPlans(prop *property.PhysicalProperty) ([]PhysicalPlan, bool, error) {
 	if MatchItems(prop, lt.ByItems) {
+		if lt.limitHints.preferIndexOrder && lt.canReadInIndexOrder() {
+			return lt.getPhysLimits(prop), true, nil
+		}
 		return append(lt.getPhysTopN(prop), lt.getPhysLimits(prop)...), true, nil
 	}
 	return nil, true, nil
 }
 
+// canReadInIndexOrder checks whether the child of the TopN is a DataSource, maybe under some Selections, and it has an
+// access path on TiKV keeping the order of the TopN, so the TopN can be replaced by a Limit on the ordered scan.
+func (lt *LogicalTopN) canReadInIndexOrder() bool {
+	prop, ok := GetPropByOrderByItems(lt.ByItems)
+	if !ok {
+		return false
+	}
+	child := lt.children[0]
+	for {
+		sel, ok := child.(*LogicalSelection)
+		if !ok {
+			break
+		}
+		child = sel.children[0]
+	}
+	ds, ok := child.(*DataSource)
+	// The scans on the partition table can't keep order.
+	if !ok || ds.tableInfo.GetPartitionInfo() != nil {
+		return false
+	}
+	for _, path := range ds.possibleAccessPaths {
+		if path.StoreType == kv.TiFlash || path.PartialIndexPaths != nil || len(path.Ranges) == 0 {
+			continue
+		}
+		if ds.isMatchProp(path, prop) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetHashJoin is public for cascades planner.
 func (la *LogicalApply) GetHashJoin(prop *property.PhysicalProperty) *PhysicalHashJoin {
 	return la.LogicalJoin.getHashJoin(prop, 1, false)
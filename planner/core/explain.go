@@ -691,19 +691,23 @@ func (p *PhysicalHashJoin) explainInfo(normalized bool) string {
 
 	buffer := new(strings.Builder)
 
-	if len(p.EqualConditions) == 0 {
+	equalConditions := p.EqualConditions
+	if len(p.NAEqualConditions) > 0 {
+		equalConditions = p.NAEqualConditions
+		buffer.WriteString("Null-aware ")
+	} else if len(p.EqualConditions) == 0 {
 		buffer.WriteString("CARTESIAN ")
 	}
 
 	buffer.WriteString(p.JoinType.String())
 
-	if len(p.EqualConditions) > 0 {
+	if len(equalConditions) > 0 {
 		if normalized {
 			buffer.WriteString(", equal:")
-			buffer.Write(expression.SortedExplainNormalizedScalarFuncList(p.EqualConditions))
+			buffer.Write(expression.SortedExplainNormalizedScalarFuncList(equalConditions))
 		} else {
 			buffer.WriteString(", equal:[")
-			for i, EqualConditions := range p.EqualConditions {
+			for i, EqualConditions := range equalConditions {
 				if i != 0 {
 					buffer.WriteString(" ")
 				}
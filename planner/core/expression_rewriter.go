@@ -27,8 +27,10 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/expression/aggregation"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/charset"
+	"github.com/pingcap/tidb/parser/format"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
@@ -999,19 +1001,29 @@ func (er *expressionRewriter) handleScalarSubquery(ctx context.Context, v *ast.S
 		}
 		return v, true
 	}
-	// We don't want nth_plan hint to affect separately executed subqueries here, so disable nth_plan temporarily.
-	NthPlanBackup := er.sctx.GetSessionVars().StmtCtx.StmtHints.ForceNthPlan
-	er.sctx.GetSessionVars().StmtCtx.StmtHints.ForceNthPlan = -1
-	physicalPlan, _, err := DoOptimize(ctx, er.sctx, er.b.optFlag, np)
-	er.sctx.GetSessionVars().StmtCtx.StmtHints.ForceNthPlan = NthPlanBackup
-	if err != nil {
-		er.err = err
-		return v, true
-	}
-	row, err := EvalSubqueryFirstRow(ctx, physicalPlan, er.b.is, er.b.ctx)
-	if err != nil {
-		er.err = err
-		return v, true
+	digest, cacheable := er.scalarSubqueryDigest(v)
+	row, ok := er.b.scalarSubqueryResults[digest]
+	if !cacheable || !ok {
+		// We don't want nth_plan hint to affect separately executed subqueries here, so disable nth_plan temporarily.
+		NthPlanBackup := er.sctx.GetSessionVars().StmtCtx.StmtHints.ForceNthPlan
+		er.sctx.GetSessionVars().StmtCtx.StmtHints.ForceNthPlan = -1
+		physicalPlan, _, err := DoOptimize(ctx, er.sctx, er.b.optFlag, np)
+		er.sctx.GetSessionVars().StmtCtx.StmtHints.ForceNthPlan = NthPlanBackup
+		if err != nil {
+			er.err = err
+			return v, true
+		}
+		row, err = EvalSubqueryFirstRow(ctx, physicalPlan, er.b.is, er.b.ctx)
+		if err != nil {
+			er.err = err
+			return v, true
+		}
+		if cacheable {
+			if er.b.scalarSubqueryResults == nil {
+				er.b.scalarSubqueryResults = make(map[string][]types.Datum)
+			}
+			er.b.scalarSubqueryResults[digest] = row
+		}
 	}
 	if np.Schema().Len() > 1 {
 		newCols := make([]expression.Expression, 0, np.Schema().Len())
@@ -1039,6 +1051,53 @@ func (er *expressionRewriter) handleScalarSubquery(ctx context.Context, v *ast.S
 	return v, true
 }
 
+// scalarSubqueryDigest returns the digest of the uncorrelated scalar subquery, and whether its result
+// can be shared by the identical subqueries in the same statement.
+func (er *expressionRewriter) scalarSubqueryDigest(v *ast.SubqueryExpr) (string, bool) {
+	// The same text may refer to different tables in the scope of CTEs or in the definitions of views.
+	if len(er.b.outerCTEs) > 0 || len(er.b.buildingViewStack) > 0 {
+		return "", false
+	}
+	checker := &scalarSubqueryCacheChecker{cacheable: true}
+	v.Query.Accept(checker)
+	if !checker.cacheable {
+		return "", false
+	}
+	var sb strings.Builder
+	if err := v.Query.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+		return "", false
+	}
+	return parser.DigestNormalized(sb.String()).String(), true
+}
+
+// scalarSubqueryCacheChecker checks whether the result of a subquery depends only on its text, it's not
+// the case if the subquery contains mutable functions, user variables, parameters or CTEs.
+type scalarSubqueryCacheChecker struct {
+	cacheable bool
+}
+
+// Enter implements Visitor interface.
+func (c *scalarSubqueryCacheChecker) Enter(in ast.Node) (ast.Node, bool) {
+	switch x := in.(type) {
+	case *ast.FuncCallExpr:
+		if expression.IsMutableEffectsFunction(x.FnName.L) {
+			c.cacheable = false
+		}
+	case *ast.VariableExpr:
+		if !x.IsSystem {
+			c.cacheable = false
+		}
+	case ast.ParamMarkerExpr, *ast.WithClause:
+		c.cacheable = false
+	}
+	return in, !c.cacheable
+}
+
+// Leave implements Visitor interface.
+func (c *scalarSubqueryCacheChecker) Leave(in ast.Node) (ast.Node, bool) {
+	return in, true
+}
+
 // Leave implements Visitor interface.
 func (er *expressionRewriter) Leave(originInNode ast.Node) (retNode ast.Node, ok bool) {
 	if er.err != nil {
@@ -15,13 +15,17 @@
 package core_test
 
 import (
+	"context"
 	"testing"
 
+	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
 	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/testkit"
 	"github.com/pingcap/tidb/testkit/testdata"
+	"github.com/pingcap/tidb/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -421,3 +425,45 @@ func TestMultiColInExpression(t *testing.T) {
 		tk.MustQuery(tt).Sort().Check(testkit.Rows(output[i].Res...))
 	}
 }
+
+func TestScalarSubqueryCache(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int, b int)")
+	tk.MustExec("insert into t values(1, 1), (2, 2), (3, 3)")
+
+	evalCount := 0
+	evalSubqueryFirstRow := plannercore.EvalSubqueryFirstRow
+	plannercore.EvalSubqueryFirstRow = func(ctx context.Context, p plannercore.PhysicalPlan, is infoschema.InfoSchema, sctx sessionctx.Context) ([]types.Datum, error) {
+		evalCount++
+		return evalSubqueryFirstRow(ctx, p, is, sctx)
+	}
+	defer func() {
+		plannercore.EvalSubqueryFirstRow = evalSubqueryFirstRow
+	}()
+
+	tk.MustQuery("select a from t where a > (select min(a) from t) and b < (select max(b) from t) and a + (select min(a) from t) > (select max(b) from t)").Check(testkit.Rows())
+	require.Equal(t, 2, evalCount)
+
+	evalCount = 0
+	tk.MustQuery("select (select max(a) from t where b < 3), (select max(a) from t where b < 2), (select max(a) from t where b < 3)").Check(testkit.Rows("2 1 2"))
+	require.Equal(t, 2, evalCount)
+
+	// The subqueries with mutable functions or user variables are evaluated separately.
+	evalCount = 0
+	tk.MustQuery("select (select count(*) from t where a < rand() * 10) is not null, (select count(*) from t where a < rand() * 10) is not null").Check(testkit.Rows("1 1"))
+	require.Equal(t, 2, evalCount)
+	evalCount = 0
+	tk.MustExec("set @v = 1")
+	tk.MustQuery("select (select max(a) from t where b > @v), (select max(a) from t where b > @v)").Check(testkit.Rows("3 3"))
+	require.Equal(t, 2, evalCount)
+
+	// The cache doesn't live across statements.
+	evalCount = 0
+	tk.MustExec("insert into t values(4, 4)")
+	tk.MustQuery("select (select max(a) from t), (select max(a) from t)").Check(testkit.Rows("4 4"))
+	require.Equal(t, 1, evalCount)
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/pingcap/tidb/types"
 	tidbutil "github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/clustercap"
 	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/ranger"
@@ -326,6 +327,19 @@ func (op *physicalOptimizeOp) appendCandidate(lp LogicalPlan, pp PhysicalPlan, p
 	op.tracer.State[name] = pps
 }
 
+// appendCrossoverSelectivity records the crossover selectivity of the double read candidate, see
+// tracing.PlanTrace.CrossoverSelectivity.
+func (op *physicalOptimizeOp) appendCrossoverSelectivity(lp LogicalPlan, pp PhysicalPlan, selectivity float64) {
+	if op == nil || op.tracer == nil || pp == nil {
+		return
+	}
+	name := tracing.CodecPlanName(lp.TP(), lp.ID())
+	key := tracing.CodecPlanName(pp.TP(), pp.ID())
+	if trace, ok := op.tracer.State[name][key]; ok {
+		trace.CrossoverSelectivity = selectivity
+	}
+}
+
 // findBestTask implements LogicalPlan interface.
 func (p *baseLogicalPlan) findBestTask(prop *property.PhysicalProperty, planCounter *PlanCounterTp, opt *physicalOptimizeOp) (bestTask task, cntPlan int64, err error) {
 	// If p is an inner plan in an IndexJoin, the IndexJoin will generate an inner plan by itself,
@@ -810,6 +824,9 @@ func (ds *DataSource) findBestTask(prop *property.PhysicalProperty, planCounter
 
 	opt.buildPhysicalOptimizeTraceInfo(ds)
 	cntPlan = 0
+	// The cost of the table scan and the double read candidates are used to trace the crossover selectivity.
+	tblCost := float64(-1)
+	var doubleReads []doubleReadCandidate
 	for _, candidate := range candidates {
 		path := candidate.path
 		if path.PartialIndexPaths != nil {
@@ -940,6 +957,9 @@ func (ds *DataSource) findBestTask(prop *property.PhysicalProperty, planCounter
 				planCounter.Dec(1)
 			}
 			appendCandidate(ds, tblTask, prop, opt)
+			if !tblTask.invalid() && path.StoreType == kv.TiKV && (tblCost < 0 || tblTask.cost() < tblCost) {
+				tblCost = tblTask.cost()
+			}
 			if tblTask.cost() < t.cost() || planCounter.Empty() {
 				t = tblTask
 			}
@@ -961,6 +981,9 @@ func (ds *DataSource) findBestTask(prop *property.PhysicalProperty, planCounter
 			planCounter.Dec(1)
 		}
 		appendCandidate(ds, idxTask, prop, opt)
+		if !idxTask.invalid() && !path.IsSingleScan {
+			doubleReads = append(doubleReads, doubleReadCandidate{path: path, task: idxTask})
+		}
 		if idxTask.cost() < t.cost() || planCounter.Empty() {
 			t = idxTask
 		}
@@ -968,10 +991,33 @@ func (ds *DataSource) findBestTask(prop *property.PhysicalProperty, planCounter
 			return t, cntPlan, nil
 		}
 	}
+	ds.traceCrossoverSelectivity(tblCost, doubleReads, opt)
 
 	return
 }
 
+type doubleReadCandidate struct {
+	path *util.AccessPath
+	task task
+}
+
+// traceCrossoverSelectivity records the crossover selectivity of each double read candidate in the optimizer trace.
+// The cost of the double read is considered linear with the selectivity of the index ranges, so the crossover is
+// where it reaches the cost of the table scan.
+func (ds *DataSource) traceCrossoverSelectivity(tblCost float64, doubleReads []doubleReadCandidate, opt *physicalOptimizeOp) {
+	if opt == nil || opt.tracer == nil || tblCost < 0 || ds.tableStats.RowCount <= 0 {
+		return
+	}
+	for _, c := range doubleReads {
+		idxCost := c.task.cost()
+		if idxCost <= 0 {
+			continue
+		}
+		selectivity := c.path.CountAfterAccess / ds.tableStats.RowCount
+		opt.appendCrossoverSelectivity(ds, c.task.plan(), math.Max(0, math.Min(1, selectivity*tblCost/idxCost)))
+	}
+}
+
 func (ds *DataSource) canConvertToPointGetForPlanCache(path *util.AccessPath) bool {
 	// PointGet might contain some over-optimized assumptions, like `a>=1 and a<=1` --> `a=1`, but
 	// these assumptions may be broken after parameters change.
@@ -1280,6 +1326,25 @@ func (ds *DataSource) addSelection4PlanCache(task *rootTask, stats *property.Sta
 	task.p = sel
 }
 
+// getDoubleReadStats returns the stats used to estimate the lookups of the double read on the index path, it returns
+// nil if the table is not analyzed. The correlation of the index is only collected by the version 2 stats, otherwise
+// the one of the first column of the index is used.
+func (ds *DataSource) getDoubleReadStats(path *util.AccessPath) *doubleReadStats {
+	if ds.statisticTable.Pseudo || path.Index == nil {
+		return nil
+	}
+	var corr float64
+	if idx, ok := ds.statisticTable.Indices[path.Index.ID]; ok && idx.StatsVer >= statistics.Version2 {
+		corr = math.Abs(idx.Correlation)
+	}
+	if len(path.IdxCols) > 0 {
+		if col, ok := ds.statisticTable.Columns[path.IdxCols[0].ID]; ok {
+			corr = math.Max(corr, math.Abs(col.Correlation))
+		}
+	}
+	return &doubleReadStats{handleCorr: corr, tableRows: float64(ds.statisticTable.Count)}
+}
+
 // convertToIndexScan converts the DataSource to index scan with idx.
 func (ds *DataSource) convertToIndexScan(prop *property.PhysicalProperty,
 	candidate *candidatePath, opt *physicalOptimizeOp) (task task, err error) {
@@ -1325,6 +1390,7 @@ func (ds *DataSource) convertToIndexScan(prop *property.PhysicalProperty,
 		// the other hand, it may be hard to identify `StatsVersion` of `ts` in `(*copTask).finishIndexPlan`.
 		ts.stats = &property.StatsInfo{StatsVersion: ds.tableStats.StatsVersion}
 		cop.tablePlan = ts
+		cop.lookupStats = ds.getDoubleReadStats(path)
 	}
 	cop.cst = cost
 	task = cop
@@ -1804,6 +1870,10 @@ func (ds *DataSource) convertToTableScan(prop *property.PhysicalProperty, candid
 		if ts.KeepOrder {
 			return &mppTask{}, nil
 		}
+		if err := clustercap.Check(clustercap.FeatureMPP); err != nil {
+			ds.SCtx().GetSessionVars().RaiseWarningWhenMPPEnforced("MPP mode may be blocked because " + err.Error() + ".")
+			return &mppTask{}, nil
+		}
 		if prop.MPPPartitionTp != property.AnyType || ts.isPartition {
 			// If ts is a single partition, then this partition table is in static-only prune, then we should not choose mpp execution.
 			ds.SCtx().GetSessionVars().RaiseWarningWhenMPPEnforced("MPP mode may be blocked because table `" + ds.tableInfo.Name.O + "`is a partition table which is not supported when `@@tidb_partition_prune_mode=static`.")
@@ -265,3 +265,20 @@ func TestHintCannotFitProperty(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, 1, mockPhysicalPlan.planType)
 }
+
+func TestDoubleReadLookupSeeks(t *testing.T) {
+	// 100 rows of 1 MB in about 2 regions.
+	clustered := &doubleReadStats{handleCorr: 1, tableRows: 100}
+	scattered := &doubleReadStats{handleCorr: 0, tableRows: 100}
+	require.Equal(t, 0.0, clustered.lookupSeeks(0, 1<<20, 10))
+	// The clustered handles of a batch are in the adjacent regions, so each batch seeks at most twice.
+	require.Equal(t, 4.0, clustered.lookupSeeks(20, 1<<20, 10))
+	// The scattered handles seek for every row.
+	seeks := scattered.lookupSeeks(20, 1<<20, 10)
+	require.Greater(t, seeks, 20.0)
+	require.Less(t, seeks, 24.0)
+	// The batches of the scattered handles are split by more regions in the larger tables.
+	large := &doubleReadStats{handleCorr: 0, tableRows: 100000}
+	require.Greater(t, large.lookupSeeks(20, 1<<20, 10), seeks)
+	require.Less(t, clustered.lookupSeeks(20, 1<<20, 10), (&doubleReadStats{handleCorr: 0.5, tableRows: 100}).lookupSeeks(20, 1<<20, 10))
+}
@@ -346,7 +346,9 @@ func (e *mppTaskGenerator) constructMPPTasksImpl(ctx context.Context, ts *Physic
 
 func (e *mppTaskGenerator) constructMPPTasksForSinglePartitionTable(ctx context.Context, kvRanges []kv.KeyRange, tableID int64) ([]*kv.MPPTask, error) {
 	req := &kv.MPPBuildTasksRequest{
-		KeyRanges: kvRanges,
+		KeyRanges:               kvRanges,
+		PreferredZone:           e.ctx.GetSessionVars().GetTiFlashPreferredZone(),
+		TiFlashReplicaReadNodes: e.ctx.GetSessionVars().GetTiFlashReplicaReadNodes(),
 	}
 	ttl, err := time.ParseDuration(e.ctx.GetSessionVars().MPPStoreFailTTL)
 	if err != nil {
@@ -1136,3 +1136,74 @@ func TestIssue27532(t *testing.T) {
 	tk.MustQuery(`select * from t2`).Sort().Check(testkit.Rows("1 1 1 1", "2 2 2 2", "3 3 3 3", "4 4 4 4"))
 	tk.MustExec(`drop table t2`)
 }
+
+func TestPartitionWiseJoin(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("set @@tidb_partition_prune_mode = 'static'")
+	tk.MustExec("create table th1 (a int, b int) partition by hash(a) partitions 4")
+	tk.MustExec("create table th2 (a int, b int) partition by hash(a) partitions 4")
+	tk.MustExec("create table th3 (a int, b int) partition by hash(a) partitions 3")
+	tk.MustExec("create table tr1 (a int, b int) partition by range(a) (partition p0 values less than (3), partition p1 values less than (6), partition p2 values less than maxvalue)")
+	tk.MustExec("create table tr2 (a int, b int) partition by range columns(a) (partition p0 values less than (3), partition p1 values less than (6), partition p2 values less than maxvalue)")
+	tk.MustExec("create table tr3 (a int, b int) partition by range(a) (partition p0 values less than (4), partition p1 values less than (6), partition p2 values less than maxvalue)")
+	for _, tbl := range []string{"th1", "th2", "th3", "tr1", "tr2", "tr3"} {
+		tk.MustExec("insert into " + tbl + " values (null, null), (0, 0), (1, 1), (2, 2), (3, 3), (5, 5), (6, 6), (7, null)")
+	}
+	tk.MustExec("delete from th2 where a in (1, 5)")
+	tk.MustExec("delete from tr1 where a in (3, 6)")
+
+	countJoins := func(sql string) (unions, joins int) {
+		for _, row := range tk.MustQuery("explain format = 'brief' " + sql).Rows() {
+			id := row[0].(string)
+			if strings.Contains(id, "Union") && !strings.Contains(id, "PartitionUnion") {
+				unions++
+			}
+			if strings.Contains(id, "Join") {
+				joins++
+			}
+		}
+		return
+	}
+	for _, tt := range []struct {
+		sql   string
+		joins int
+	}{
+		{"select * from th1 join th2 on th1.a = th2.a", 4},
+		{"select * from th1 join th2 on th1.a = th2.a and th1.b > th2.b - 1 where th1.a in (1, 2)", 2},
+		{"select * from th1 left join th2 on th1.a = th2.a and th2.a in (1, 2) where th1.a in (1, 2, 3)", 3},
+		{"select * from th1 right join th2 on th1.a = th2.a and th1.a in (1, 2)", 4},
+		{"select * from th1 where exists (select 1 from th2 where th1.a = th2.a)", 4},
+		{"select * from th1 where not exists (select 1 from th2 where th1.a = th2.a)", 4},
+		{"select th1.a, th1.a in (select a from th2) from th1", 0},
+		{"select * from tr1 join tr1 t on tr1.a = t.a", 3},
+		{"select * from tr1 left join tr2 on tr1.a = tr2.a", 0},
+		{"select * from tr2 t1 left join tr2 t2 on t1.a = t2.a where t1.a > 4", 2},
+		{"select * from th1 join th3 on th1.a = th3.a", 0},
+		{"select * from tr1 join tr3 on tr1.a = tr3.a", 0},
+		{"select * from th1 join th2 on th1.b = th2.a", 0},
+		{"select * from th1 join th2 on th1.a = th2.b", 0},
+		{"select * from th1 join tr1 on th1.a = tr1.a", 0},
+	} {
+		tk.MustExec("set @@tidb_enable_partition_wise_join = 0")
+		expected := tk.MustQuery(tt.sql).Sort().Rows()
+		tk.MustExec("set @@tidb_enable_partition_wise_join = 1")
+		tk.MustQuery(tt.sql).Sort().Check(expected)
+		unions, joins := countJoins(tt.sql)
+		if tt.joins == 0 {
+			require.Equal(t, 0, unions, tt.sql)
+		} else {
+			require.Equal(t, 1, unions, tt.sql)
+			require.Equal(t, tt.joins, joins, tt.sql)
+		}
+	}
+
+	tk.MustExec("set @@tidb_enable_partition_wise_join = 1")
+	tk.MustQuery("select * from th1 join th2 on th1.a = th2.a where th1.a = 2").Check(testkit.Rows("2 2 2 2"))
+	tk.MustQuery("select * from th1 join th2 on th1.a = th2.a and th2.a = 1").Check(testkit.Rows())
+	tk.MustExec("set @@tidb_partition_prune_mode = 'dynamic'")
+	unions, _ := countJoins("select * from th1 join th2 on th1.a = th2.a")
+	require.Equal(t, 0, unions)
+}
@@ -55,7 +55,9 @@ import (
 	util2 "github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/collate"
+	"github.com/pingcap/tidb/util/extfile"
 	"github.com/pingcap/tidb/util/plancodec"
+	"github.com/pingcap/tidb/util/sem"
 	"github.com/pingcap/tidb/util/set"
 )
 
@@ -339,6 +341,10 @@ func (b *PlanBuilder) buildResultSetNode(ctx context.Context, node ast.ResultSet
 		case *ast.TableName:
 			p, err = b.buildDataSource(ctx, v, &x.AsName)
 			isTableName = true
+		case *ast.ExternalTable:
+			// The table function is not a select block either.
+			p, err = b.buildExternalTable(ctx, v, x.AsName)
+			isTableName = true
 		default:
 			err = ErrUnsupportedType.GenWithStackByArgs(v)
 		}
@@ -2664,65 +2670,129 @@ func buildFuncDependCol(p LogicalPlan, cond ast.ExprNode) (*types.FieldName, *ty
 	return p.OutputNames()[lIdx], p.OutputNames()[rIdx], nil
 }
 
-func buildWhereFuncDepend(p LogicalPlan, where ast.ExprNode) (map[*types.FieldName]*types.FieldName, error) {
+// funcDepends maps a column to the columns which it's functionally dependent on.
+type funcDepends map[*types.FieldName][]*types.FieldName
+
+func (d funcDepends) add(col, determinant *types.FieldName) {
+	d[col] = append(d[col], determinant)
+}
+
+func buildWhereFuncDepend(p LogicalPlan, where ast.ExprNode, colDepends funcDepends) error {
 	whereConditions := splitWhere(where)
-	colDependMap := make(map[*types.FieldName]*types.FieldName, 2*len(whereConditions))
 	for _, cond := range whereConditions {
 		lCol, rCol, err := buildFuncDependCol(p, cond)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if lCol == nil || rCol == nil {
 			continue
 		}
-		colDependMap[lCol] = rCol
-		colDependMap[rCol] = lCol
+		colDepends.add(lCol, rCol)
+		colDepends.add(rCol, lCol)
 	}
-	return colDependMap, nil
+	return nil
 }
 
-func buildJoinFuncDepend(p LogicalPlan, from ast.ResultSetNode) (map[*types.FieldName]*types.FieldName, error) {
-	switch x := from.(type) {
-	case *ast.Join:
-		if x.On == nil {
-			return nil, nil
+func buildJoinFuncDepend(p LogicalPlan, from ast.ResultSetNode, colDepends funcDepends) error {
+	x, ok := from.(*ast.Join)
+	if !ok {
+		return nil
+	}
+	// The ON conditions of the nested joins hold in the joined rows too, because the NULL-complemented
+	// rows of an outer join are NULL on all the columns of its inner side.
+	if err := buildJoinFuncDepend(p, x.Left, colDepends); err != nil {
+		return err
+	}
+	if x.Right != nil {
+		if err := buildJoinFuncDepend(p, x.Right, colDepends); err != nil {
+			return err
 		}
-		onConditions := splitWhere(x.On.Expr)
-		colDependMap := make(map[*types.FieldName]*types.FieldName, len(onConditions))
-		for _, cond := range onConditions {
-			lCol, rCol, err := buildFuncDependCol(p, cond)
-			if err != nil {
-				return nil, err
-			}
-			if lCol == nil || rCol == nil {
+	}
+	if x.On == nil {
+		return nil
+	}
+	onConditions := splitWhere(x.On.Expr)
+	for _, cond := range onConditions {
+		lCol, rCol, err := buildFuncDependCol(p, cond)
+		// The columns of USING are coalesced, so a column of the ON conditions may be not in the output.
+		if ErrUnknownColumn.Equal(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if lCol == nil || rCol == nil {
+			continue
+		}
+		lTbl := tblInfoFromCol(x.Left, lCol)
+		if lTbl == nil {
+			lCol, rCol = rCol, lCol
+		}
+		switch x.Tp {
+		case ast.CrossJoin:
+			colDepends.add(lCol, rCol)
+			colDepends.add(rCol, lCol)
+		case ast.LeftJoin:
+			colDepends.add(rCol, lCol)
+		case ast.RightJoin:
+			colDepends.add(lCol, rCol)
+		}
+	}
+	return nil
+}
+
+// buildFuncDependClosure returns the columns which are functionally dependent on the GROUP BY columns and the
+// single value columns, including themselves. Besides the equal conditions in colDepends, all the columns of a
+// table are functionally dependent on its primary key or unique key whose columns are not null. The dependencies
+// are transitive, so the closure is expanded until no more columns can be added.
+func buildFuncDependClosure(p LogicalPlan, from ast.ResultSetNode, gbyOrSingleValueColNames map[*types.FieldName]struct{}, colDepends funcDepends) map[*types.FieldName]struct{} {
+	closure := make(map[*types.FieldName]struct{}, len(p.OutputNames()))
+	for name := range gbyOrSingleValueColNames {
+		closure[name] = struct{}{}
+	}
+	tblNames := make(map[*model.TableInfo][]*types.FieldName)
+	for _, name := range p.OutputNames() {
+		if tblInfo := tblInfoFromCol(from, name); tblInfo != nil {
+			tblNames[tblInfo] = append(tblNames[tblInfo], name)
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for col, determinants := range colDepends {
+			if _, ok := closure[col]; ok {
 				continue
 			}
-			lTbl := tblInfoFromCol(x.Left, lCol)
-			if lTbl == nil {
-				lCol, rCol = rCol, lCol
+			for _, determinant := range determinants {
+				if _, ok := closure[determinant]; ok {
+					closure[col] = struct{}{}
+					changed = true
+					break
+				}
 			}
-			switch x.Tp {
-			case ast.CrossJoin:
-				colDependMap[lCol] = rCol
-				colDependMap[rCol] = lCol
-			case ast.LeftJoin:
-				colDependMap[rCol] = lCol
-			case ast.RightJoin:
-				colDependMap[lCol] = rCol
+		}
+		for tblInfo, names := range tblNames {
+			if !checkColFuncDepend(p, names[0], tblInfo, closure) {
+				continue
 			}
+			for _, name := range names {
+				if _, ok := closure[name]; !ok {
+					closure[name] = struct{}{}
+					changed = true
+				}
+			}
+			delete(tblNames, tblInfo)
 		}
-		return colDependMap, nil
-	default:
-		return nil, nil
 	}
+	return closure
 }
 
+// checkColFuncDepend checks whether the columns of the table, which the column name belongs to, are functionally
+// dependent on the columns in closure by the primary key or a unique key whose columns are not null.
 func checkColFuncDepend(
 	p LogicalPlan,
 	name *types.FieldName,
 	tblInfo *model.TableInfo,
-	gbyOrSingleValueColNames map[*types.FieldName]struct{},
-	whereDependNames, joinDependNames map[*types.FieldName]*types.FieldName,
+	closure map[*types.FieldName]struct{},
 ) bool {
 	for _, index := range tblInfo.Indices {
 		if !index.Unique {
@@ -2745,20 +2815,9 @@ func checkColFuncDepend(
 				funcDepend = false
 				break
 			}
-			iName := p.OutputNames()[iIdx]
-			if _, ok := gbyOrSingleValueColNames[iName]; ok {
+			if _, ok := closure[p.OutputNames()[iIdx]]; ok {
 				continue
 			}
-			if wCol, ok := whereDependNames[iName]; ok {
-				if _, ok = gbyOrSingleValueColNames[wCol]; ok {
-					continue
-				}
-			}
-			if jCol, ok := joinDependNames[iName]; ok {
-				if _, ok = gbyOrSingleValueColNames[jCol]; ok {
-					continue
-				}
-			}
 			funcDepend = false
 			break
 		}
@@ -2805,20 +2864,9 @@ func checkColFuncDepend(
 			primaryFuncDepend = false
 			break
 		}
-		pCol := p.OutputNames()[pIdx]
-		if _, ok := gbyOrSingleValueColNames[pCol]; ok {
+		if _, ok := closure[p.OutputNames()[pIdx]]; ok {
 			continue
 		}
-		if wCol, ok := whereDependNames[pCol]; ok {
-			if _, ok = gbyOrSingleValueColNames[wCol]; ok {
-				continue
-			}
-		}
-		if jCol, ok := joinDependNames[pCol]; ok {
-			if _, ok = gbyOrSingleValueColNames[jCol]; ok {
-				continue
-			}
-		}
 		primaryFuncDepend = false
 		break
 	}
@@ -2945,25 +2993,19 @@ func (b *PlanBuilder) checkOnlyFullGroupByWithGroupClause(p LogicalPlan, sel *as
 		return nil
 	}
 
-	whereDepends, err := buildWhereFuncDepend(p, sel.Where)
-	if err != nil {
+	colDepends := make(funcDepends)
+	if err := buildWhereFuncDepend(p, sel.Where, colDepends); err != nil {
 		return err
 	}
-	joinDepends, err := buildJoinFuncDepend(p, sel.From.TableRefs)
-	if err != nil {
+	if err := buildJoinFuncDepend(p, sel.From.TableRefs, colDepends); err != nil {
 		return err
 	}
-	tblMap := make(map[*model.TableInfo]struct{}, len(notInGbyOrSingleValueColNames))
+	closure := buildFuncDependClosure(p, sel.From.TableRefs, gbyOrSingleValueColNames, colDepends)
 	for name, errExprLoc := range notInGbyOrSingleValueColNames {
-		tblInfo := tblInfoFromCol(sel.From.TableRefs, name)
-		if tblInfo == nil {
-			continue
-		}
-		if _, ok := tblMap[tblInfo]; ok {
+		if _, ok := closure[name]; ok {
 			continue
 		}
-		if checkColFuncDepend(p, name, tblInfo, gbyOrSingleValueColNames, whereDepends, joinDepends) {
-			tblMap[tblInfo] = struct{}{}
+		if tblInfoFromCol(sel.From.TableRefs, name) == nil {
 			continue
 		}
 		switch errExprLoc.Loc {
@@ -3007,34 +3049,24 @@ func (b *PlanBuilder) checkOnlyFullGroupByWithOutGroupClause(p LogicalPlan, sel
 	}
 	singleValueColNames := make(map[*types.FieldName]struct{}, len(sel.Fields.Fields))
 	extractSingeValueColNamesFromWhere(p, sel.Where, singleValueColNames)
-	whereDepends, err := buildWhereFuncDepend(p, sel.Where)
-	if err != nil {
+	colDepends := make(funcDepends)
+	if err := buildWhereFuncDepend(p, sel.Where, colDepends); err != nil {
 		return err
 	}
-
-	joinDepends, err := buildJoinFuncDepend(p, sel.From.TableRefs)
-	if err != nil {
+	if err := buildJoinFuncDepend(p, sel.From.TableRefs, colDepends); err != nil {
 		return err
 	}
-	tblMap := make(map[*model.TableInfo]struct{}, len(resolver.nonAggCols))
+	closure := buildFuncDependClosure(p, sel.From.TableRefs, singleValueColNames, colDepends)
 	for i, colName := range resolver.nonAggCols {
 		idx, err := expression.FindFieldName(p.OutputNames(), colName)
 		if err != nil || idx < 0 {
 			return ErrMixOfGroupFuncAndFields.GenWithStackByArgs(resolver.nonAggColIdxs[i]+1, colName.Name.O)
 		}
 		fieldName := p.OutputNames()[idx]
-		if _, ok := singleValueColNames[fieldName]; ok {
-			continue
-		}
-		tblInfo := tblInfoFromCol(sel.From.TableRefs, fieldName)
-		if tblInfo == nil {
+		if _, ok := closure[fieldName]; ok {
 			continue
 		}
-		if _, ok := tblMap[tblInfo]; ok {
-			continue
-		}
-		if checkColFuncDepend(p, fieldName, tblInfo, singleValueColNames, whereDepends, joinDepends) {
-			tblMap[tblInfo] = struct{}{}
+		if tblInfoFromCol(sel.From.TableRefs, fieldName) == nil {
 			continue
 		}
 		return ErrMixOfGroupFuncAndFields.GenWithStackByArgs(resolver.nonAggColIdxs[i]+1, colName.Name.O)
@@ -3967,6 +3999,13 @@ func (b *PlanBuilder) buildDataSource(ctx context.Context, tn *ast.TableName, as
 		return b.buildMemTable(ctx, dbName, tableInfo)
 	}
 
+	if tableInfo.IsForeignTable() {
+		if tn.TableSample != nil {
+			return nil, expression.ErrInvalidTableSample.GenWithStackByArgs("Unsupported TABLESAMPLE in foreign tables")
+		}
+		return b.buildMemTable(ctx, dbName, tableInfo)
+	}
+
 	if tableInfo.IsView() {
 		if tn.TableSample != nil {
 			return nil, expression.ErrInvalidTableSample.GenWithStackByArgs("Unsupported TABLESAMPLE in views")
@@ -3986,6 +4025,9 @@ func (b *PlanBuilder) buildDataSource(ctx context.Context, tn *ast.TableName, as
 		// Use the new partition implementation, clean up the code here when it's full implemented.
 		if !b.ctx.GetSessionVars().UseDynamicPartitionPrune() {
 			b.optFlag = b.optFlag | flagPartitionProcessor
+			if b.ctx.GetSessionVars().EnablePartitionWiseJoin {
+				b.optFlag = b.optFlag | flagPartitionWiseJoin
+			}
 		}
 
 		pt := tbl.(table.PartitionedTable)
@@ -4218,9 +4260,56 @@ func (b *PlanBuilder) buildDataSource(ctx context.Context, tn *ast.TableName, as
 	}
 	sessionVars.StmtCtx.TblInfo2UnionScan[tableInfo] = dirty
 
+	// Only the reads hide the expired rows, UPDATE and DELETE, including their subqueries, and the internal SQLs
+	// still see them, so the expired rows can be deleted by the users.
+	// TODO: delete the expired rows in the background, there is no TTL job yet, so they're kept until deleted by hand.
+	if ttl := tableInfo.TTLInfo; ttl != nil && ttl.Enable && !sessionVars.InRestrictedSQL && !b.inUpdateStmt && !b.inDeleteStmt {
+		conds, err := b.buildTTLConditions(ds, ttl)
+		if err != nil {
+			return nil, err
+		}
+		sel := LogicalSelection{Conditions: conds}.Init(b.ctx, b.getSelectOffset())
+		sel.SetChildren(result)
+		result = sel
+	}
+
 	return result, nil
 }
 
+// buildTTLConditions builds the implicit predicate `ttl_column >= NOW() - INTERVAL expr unit` for a TTL table,
+// which hides the expired rows before they are deleted. The predicate can be pushed down to the coprocessor.
+func (b *PlanBuilder) buildTTLConditions(ds *DataSource, ttl *model.TTLInfo) ([]expression.Expression, error) {
+	var ttlCol *expression.Column
+	for i, col := range ds.Columns {
+		if col.Name.L == ttl.ColumnName.L {
+			ttlCol = ds.TblCols[i]
+			break
+		}
+	}
+	if ttlCol == nil {
+		return nil, ErrUnknownColumn.GenWithStackByArgs(ttl.ColumnName.O, "TTL")
+	}
+	interval, err := strconv.ParseUint(ttl.IntervalExprStr, 10, 64)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	now, err := expression.NewFunction(b.ctx, ast.Now, types.NewFieldType(mysql.TypeDatetime))
+	if err != nil {
+		return nil, err
+	}
+	intervalConst := &expression.Constant{Value: types.NewUintDatum(interval), RetType: types.NewFieldType(mysql.TypeLonglong)}
+	unitConst := &expression.Constant{Value: types.NewStringDatum(ttl.IntervalTimeUnit), RetType: types.NewFieldType(mysql.TypeVarchar)}
+	expireTime, err := expression.NewFunction(b.ctx, ast.DateSub, types.NewFieldType(mysql.TypeUnspecified), now, intervalConst, unitConst)
+	if err != nil {
+		return nil, err
+	}
+	cond, err := expression.NewFunction(b.ctx, ast.GE, types.NewFieldType(mysql.TypeTiny), ttlCol, expireTime)
+	if err != nil {
+		return nil, err
+	}
+	return []expression.Expression{cond}, nil
+}
+
 func (b *PlanBuilder) timeRangeForSummaryTable() QueryTimeRange {
 	const defaultSummaryDuration = 30 * time.Minute
 	hints := b.TableHints()
@@ -4301,6 +4390,10 @@ func (b *PlanBuilder) buildMemTable(_ context.Context, dbName model.CIStr, table
 	}
 
 	// Some memory tables can receive some predicates
+	if tableInfo.IsForeignTable() {
+		p.Extractor = &ForeignTableExtractor{}
+		return p, nil
+	}
 	switch dbName.L {
 	case util2.MetricSchemaName.L:
 		p.Extractor = newMetricTableExtractor()
@@ -4335,11 +4428,78 @@ func (b *PlanBuilder) buildMemTable(_ context.Context, dbName model.CIStr, table
 			p.Extractor = &TikvRegionPeersExtractor{}
 		case infoschema.TableColumns:
 			p.Extractor = &ColumnsTableExtractor{}
+		case infoschema.TableTables:
+			p.Extractor = &TablesTableExtractor{}
 		}
 	}
 	return p, nil
 }
 
+// buildExternalTable builds the EXTERNAL table function, the columns are inferred from the first row of the file.
+func (b *PlanBuilder) buildExternalTable(ctx context.Context, ext *ast.ExternalTable, asName model.CIStr) (LogicalPlan, error) {
+	if sem.IsEnabled() {
+		return nil, ErrNotSupportedWithSem.GenWithStackByArgs("EXTERNAL")
+	}
+	// The file is read to infer the columns when the plan is built, so the privilege can't wait for the visitInfo check.
+	if pm := privilege.GetPrivilegeManager(b.ctx); pm != nil {
+		if !pm.RequestVerification(b.ctx.GetSessionVars().ActiveRoles, "", "", "", mysql.FilePriv) {
+			return nil, ErrSpecificAccessDenied.GenWithStackByArgs("FILE")
+		}
+	}
+	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.FilePriv, "", "", "", ErrSpecificAccessDenied.GenWithStackByArgs("FILE"))
+
+	src := extfile.NewSource(ext.URL)
+	for _, option := range ext.Options {
+		switch option.Name {
+		case "FORMAT":
+			switch format := strings.ToLower(option.Value); format {
+			case extfile.FormatCSV, extfile.FormatParquet:
+				src.Format = format
+			default:
+				return nil, ErrWrongArguments.GenWithStackByArgs("EXTERNAL")
+			}
+		case "HEADER":
+			header, err := strconv.ParseBool(option.Value)
+			if err != nil {
+				return nil, ErrWrongArguments.GenWithStackByArgs("EXTERNAL")
+			}
+			src.Header = header
+		case "SEPARATOR":
+			separator := []rune(option.Value)
+			if len(separator) != 1 || separator[0] == '"' || separator[0] == '\r' || separator[0] == '\n' {
+				return nil, ErrWrongArguments.GenWithStackByArgs("EXTERNAL")
+			}
+			src.Separator = separator[0]
+		default:
+			return nil, ErrWrongArguments.GenWithStackByArgs("EXTERNAL")
+		}
+	}
+	columns, err := src.Columns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if asName.L == "" {
+		asName = model.NewCIStr("external")
+	}
+	tableInfo := &model.TableInfo{Name: asName, Columns: make([]*model.ColumnInfo, 0, len(columns))}
+	for i, col := range columns {
+		tableInfo.Columns = append(tableInfo.Columns, &model.ColumnInfo{
+			ID:        int64(i + 1),
+			Name:      model.NewCIStr(col.Name),
+			Offset:    i,
+			FieldType: *col.Type,
+			State:     model.StatePublic,
+		})
+	}
+	p, err := b.buildMemTable(ctx, model.CIStr{}, tableInfo)
+	if err != nil {
+		return nil, err
+	}
+	p.(*LogicalMemTable).Extractor = &ExternalTableExtractor{Source: src}
+	return p, nil
+}
+
 // checkRecursiveView checks whether this view is recursively defined.
 func (b *PlanBuilder) checkRecursiveView(dbName model.CIStr, tableName model.CIStr) (func(), error) {
 	viewFullName := dbName.L + "." + tableName.L
@@ -4725,6 +4885,9 @@ func (b *PlanBuilder) buildUpdate(ctx context.Context, update *ast.UpdateStmt) (
 		if err != nil {
 			return nil, err
 		}
+		if li, ok := p.(*LogicalLimit); ok && update.Order != nil && update.TableRefs.TableRefs.Right == nil {
+			li.limitHints.preferIndexOrder = true
+		}
 	}
 
 	// Add project to freeze the order of output columns.
@@ -4736,6 +4899,24 @@ func (b *PlanBuilder) buildUpdate(ctx context.Context, update *ast.UpdateStmt) (
 	proj.SetChildren(p)
 	p = proj
 
+	var (
+		returning       []expression.Expression
+		returningSchema *expression.Schema
+		returningNames  types.NameSlice
+		returningOld    map[int64]*expression.Column
+	)
+	if update.Returning != nil {
+		if update.MultipleTable {
+			return nil, ErrNotSupportedYet.GenWithStackByArgs("RETURNING in multiple-table UPDATE")
+		}
+		var evalPlan LogicalPlan
+		evalPlan, returningOld = b.buildReturningOldNew(p)
+		returning, returningSchema, returningNames, err = b.buildReturning(ctx, p, evalPlan, update.Returning)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	utlr := &updatableTableListResolver{}
 	update.Accept(utlr)
 	orderedList, np, allAssignmentsAreConstant, err := b.buildUpdateLists(ctx, utlr.updatableTableList, update.List, p)
@@ -4771,9 +4952,119 @@ func (b *PlanBuilder) buildUpdate(ctx context.Context, update *ast.UpdateStmt) (
 	updt.TblColPosInfos, err = buildColumns2Handle(updt.OutputNames(), tblID2Handle, tblID2table, true)
 	updt.PartitionedTable = b.partitionedTable
 	updt.tblID2Table = tblID2table
+	if err == nil && returning != nil {
+		// The RETURNING clause is evaluated on the new values of the row followed by the old values.
+		selCols := updt.SelectPlan.Schema().Columns
+		evalCols := make([]*expression.Column, 0, 2*len(selCols))
+		evalCols = append(evalCols, selCols...)
+		for _, col := range selCols {
+			// The columns that are not from p have no old values, they are kept as placeholders.
+			if oldCol, ok := returningOld[col.UniqueID]; ok {
+				col = oldCol
+			}
+			evalCols = append(evalCols, col)
+		}
+		updt.Returning, err = resolveIndicesForReturning(returning, expression.NewSchema(evalCols...))
+		updt.SetSchema(returningSchema)
+		updt.names = returningNames
+	}
 	return updt, err
 }
 
+// The qualifiers of the new and old values of the updated rows in the RETURNING clause of UPDATE.
+var (
+	returningNewQualifier = model.NewCIStr("new")
+	returningOldQualifier = model.NewCIStr("old")
+)
+
+// buildReturningOldNew builds the plan that the RETURNING clause of UPDATE is rewritten on. Besides the columns of p,
+// which are the new values, its schema contains the new values qualified by NEW and the old values qualified by OLD.
+// The old values are new columns, which are returned by the unique IDs of the corresponding columns of p.
+func (b *PlanBuilder) buildReturningOldNew(p LogicalPlan) (LogicalPlan, map[int64]*expression.Column) {
+	cols := p.Schema().Columns
+	evalCols := make([]*expression.Column, 0, 3*len(cols))
+	evalNames := make(types.NameSlice, 0, 3*len(cols))
+	evalCols = append(evalCols, cols...)
+	evalNames = append(evalNames, p.OutputNames()...)
+	oldCols := make(map[int64]*expression.Column, len(cols))
+	for _, qualifier := range []model.CIStr{returningNewQualifier, returningOldQualifier} {
+		for i, col := range cols {
+			name := *p.OutputNames()[i]
+			name.DBName, name.TblName = model.CIStr{}, qualifier
+			// The qualified names are redundant so that an unqualified column is resolved to the column of p.
+			name.Redundant = true
+			if qualifier == returningOldQualifier {
+				oldCol := col.Clone().(*expression.Column)
+				oldCol.UniqueID = b.ctx.GetSessionVars().AllocPlanColumnID()
+				oldCols[col.UniqueID] = oldCol
+				col = oldCol
+			}
+			evalCols = append(evalCols, col)
+			evalNames = append(evalNames, &name)
+		}
+	}
+	dual := LogicalTableDual{RowCount: 1}.Init(b.ctx, b.getSelectOffset())
+	dual.SetSchema(expression.NewSchema(evalCols...))
+	dual.names = evalNames
+	return dual, oldCols
+}
+
+// buildReturning builds the RETURNING clause of a single-table UPDATE or DELETE on the output of p. The fields are
+// rewritten on evalPlan, which may contain more columns than p, see buildReturningOldNew.
+func (b *PlanBuilder) buildReturning(ctx context.Context, p, evalPlan LogicalPlan, returning *ast.FieldList) ([]expression.Expression, *expression.Schema, types.NameSlice, error) {
+	fields := make([]*ast.SelectField, 0, len(returning.Fields))
+	for i, field := range returning.Fields {
+		if field.WildCard == nil {
+			fields = append(fields, field)
+			continue
+		}
+		if field.WildCard.Table.L == "" && i > 0 {
+			return nil, nil, nil, ErrInvalidWildCard
+		}
+		// An unqualified wildcard is only unfolded to the columns of p.
+		list := unfoldWildStar(field, p.OutputNames(), p.Schema().Columns)
+		if field.WildCard.Table.L != "" {
+			list = unfoldWildStar(field, evalPlan.OutputNames(), evalPlan.Schema().Columns)
+		}
+		if len(list) == 0 {
+			return nil, nil, nil, ErrBadTable.GenWithStackByArgs(field.WildCard.Table)
+		}
+		fields = append(fields, list...)
+	}
+	exprs := make([]expression.Expression, 0, len(fields))
+	schema := expression.NewSchema(make([]*expression.Column, 0, len(fields))...)
+	names := make(types.NameSlice, 0, len(fields))
+	for _, field := range fields {
+		expr, np, err := b.rewrite(ctx, field.Expr, evalPlan, nil, true)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if np != evalPlan {
+			return nil, nil, nil, ErrNotSupportedYet.GenWithStackByArgs("subquery in RETURNING")
+		}
+		col, name, err := b.buildProjectionField(ctx, evalPlan, field, expr)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		exprs = append(exprs, expr)
+		schema.Append(col)
+		names = append(names, name)
+	}
+	return exprs, schema, names, nil
+}
+
+func resolveIndicesForReturning(exprs []expression.Expression, schema *expression.Schema) ([]expression.Expression, error) {
+	resolved := make([]expression.Expression, 0, len(exprs))
+	for _, expr := range exprs {
+		newExpr, err := expr.ResolveIndices(schema)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, newExpr)
+	}
+	return resolved, nil
+}
+
 type tblUpdateInfo struct {
 	name                string
 	pkUpdated           bool
@@ -4859,7 +5150,7 @@ func (b *PlanBuilder) buildUpdateLists(ctx context.Context, tableList []*ast.Tab
 		foundListItem := false
 		for _, tl := range tableList {
 			if (tl.Schema.L == "" || tl.Schema.L == name.DBName.L) && (tl.Name.L == name.TblName.L) {
-				if isCTE(tl) || tl.TableInfo.IsView() || tl.TableInfo.IsSequence() {
+				if isCTE(tl) || tl.TableInfo.IsView() || tl.TableInfo.IsSequence() || tl.TableInfo.IsForeignTable() {
 					return nil, nil, false, ErrNonUpdatableTable.GenWithStackByArgs(name.TblName.O, "UPDATE")
 				}
 				foundListItem = true
@@ -4882,7 +5173,7 @@ func (b *PlanBuilder) buildUpdateLists(ctx context.Context, tableList []*ast.Tab
 	// And, fill virtualAssignments here; that's for generated columns.
 	virtualAssignments := make([]*ast.Assignment, 0)
 	for _, tn := range tableList {
-		if isCTE(tn) || tn.TableInfo.IsView() || tn.TableInfo.IsSequence() {
+		if isCTE(tn) || tn.TableInfo.IsView() || tn.TableInfo.IsSequence() || tn.TableInfo.IsForeignTable() {
 			continue
 		}
 
@@ -5089,10 +5380,13 @@ func (b *PlanBuilder) buildDelete(ctx context.Context, ds *ast.DeleteStmt) (Plan
 		if err != nil {
 			return nil, err
 		}
+		if li, ok := p.(*LogicalLimit); ok && ds.Order != nil && !ds.IsMultiTable {
+			li.limitHints.preferIndexOrder = true
+		}
 	}
 
 	// If the delete is non-qualified it does not require Select Priv
-	if ds.Where == nil && ds.Order == nil {
+	if ds.Where == nil && ds.Order == nil && ds.Returning == nil {
 		b.popVisitInfo()
 	}
 	var authErr error
@@ -5104,6 +5398,21 @@ func (b *PlanBuilder) buildDelete(ctx context.Context, ds *ast.DeleteStmt) (Plan
 	proj.names = p.OutputNames()[:oldLen]
 	p = proj
 
+	var (
+		returning       []expression.Expression
+		returningSchema *expression.Schema
+		returningNames  types.NameSlice
+	)
+	if ds.Returning != nil {
+		if ds.IsMultiTable {
+			return nil, ErrNotSupportedYet.GenWithStackByArgs("RETURNING in multiple-table DELETE")
+		}
+		returning, returningSchema, returningNames, err = b.buildReturning(ctx, p, p, ds.Returning)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	handleColsMap := b.handleHelper.tailMap()
 	for _, cols := range handleColsMap {
 		for _, col := range cols {
@@ -5172,6 +5481,9 @@ func (b *PlanBuilder) buildDelete(ctx context.Context, ds *ast.DeleteStmt) (Plan
 			if tn.TableInfo.IsSequence() {
 				return nil, errors.Errorf("delete sequence %s is not supported now.", tn.Name.O)
 			}
+			if tn.TableInfo.IsForeignTable() {
+				return nil, ErrNonUpdatableTable.GenWithStackByArgs(tn.Name.O, "DELETE")
+			}
 			if sessionVars.User != nil {
 				authErr = ErrTableaccessDenied.FastGenByArgs("DELETE", sessionVars.User.AuthUsername, sessionVars.User.AuthHostname, tb.Name.L)
 			}
@@ -5191,6 +5503,9 @@ func (b *PlanBuilder) buildDelete(ctx context.Context, ds *ast.DeleteStmt) (Plan
 			if v.TableInfo.IsSequence() {
 				return nil, errors.Errorf("delete sequence %s is not supported now.", v.Name.O)
 			}
+			if v.TableInfo.IsForeignTable() {
+				return nil, ErrNonUpdatableTable.GenWithStackByArgs(v.Name.O, "DELETE")
+			}
 			dbName := v.Schema.L
 			if dbName == "" {
 				dbName = b.ctx.GetSessionVars().CurrentDB
@@ -5217,6 +5532,11 @@ func (b *PlanBuilder) buildDelete(ctx context.Context, ds *ast.DeleteStmt) (Plan
 		tblID2table[id], _ = b.is.TableByID(id)
 	}
 	del.TblColPosInfos, err = buildColumns2Handle(del.names, tblID2Handle, tblID2table, false)
+	if err == nil && returning != nil {
+		del.Returning, err = resolveIndicesForReturning(returning, del.SelectPlan.Schema())
+		del.SetSchema(returningSchema)
+		del.names = returningNames
+	}
 	return del, err
 }
 
@@ -36,6 +36,7 @@ import (
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/extfile"
 	"github.com/pingcap/tidb/util/rowcodec"
 	"github.com/pingcap/tidb/util/set"
 	"github.com/pingcap/tidb/util/stringutil"
@@ -1549,3 +1550,291 @@ func (e *ColumnsTableExtractor) explainInfo(p *PhysicalMemTable) string {
 	}
 	return s
 }
+
+// TablesTableExtractor is used to extract the names of the schemas and the tables from the predicates of the
+// tables table, so the retriever only builds the rows of the tables that may be selected. Unlike
+// ColumnsTableExtractor, the predicates are not removed, they are still evaluated on the rows, because the
+// names are compared in lower case and the LIKE patterns are only used by their literal prefixes here.
+type TablesTableExtractor struct {
+	extractHelper
+
+	// SkipRequest means the where clause always false, we don't need to request any component
+	SkipRequest bool
+
+	TableSchema set.StringSet
+
+	TableName set.StringSet
+
+	// TableSchemaPrefixes are the literal prefixes of the LIKE patterns of table_schema.
+	TableSchemaPrefixes []string
+
+	// TableNamePrefixes are the literal prefixes of the LIKE patterns of table_name.
+	TableNamePrefixes []string
+}
+
+// Extract implements the MemTablePredicateExtractor Extract interface
+func (e *TablesTableExtractor) Extract(_ sessionctx.Context,
+	schema *expression.Schema,
+	names []*types.FieldName,
+	predicates []expression.Expression,
+) (remained []expression.Expression) {
+	_, tableSchemaSkipRequest, tableSchema := e.extractCol(schema, names, predicates, "table_schema", true)
+	_, tableNameSkipRequest, tableName := e.extractCol(schema, names, predicates, "table_name", true)
+	e.SkipRequest = tableSchemaSkipRequest || tableNameSkipRequest
+	if e.SkipRequest {
+		return predicates
+	}
+	e.TableSchema = tableSchema
+	e.TableName = tableName
+	e.TableSchemaPrefixes = e.extractLikePrefixes(schema, names, predicates, "table_schema")
+	e.TableNamePrefixes = e.extractLikePrefixes(schema, names, predicates, "table_name")
+	return predicates
+}
+
+// extractLikePrefixes returns the lower case literal prefixes of the `col LIKE 'pattern'` predicates of the column,
+// the patterns starting with a wildcard are ignored.
+func (e *TablesTableExtractor) extractLikePrefixes(
+	schema *expression.Schema,
+	names []*types.FieldName,
+	predicates []expression.Expression,
+	extractColName string,
+) []string {
+	extractCols := e.findColumn(schema, names, extractColName)
+	if len(extractCols) == 0 {
+		return nil
+	}
+	var prefixes []string
+	for _, expr := range predicates {
+		fn, ok := expr.(*expression.ScalarFunction)
+		if !ok || fn.FuncName.L != ast.Like {
+			continue
+		}
+		args := fn.GetArgs()
+		// The pattern must be the right side, and the escape must be the default one.
+		if _, ok := args[0].(*expression.Column); !ok {
+			continue
+		}
+		if escape, ok := args[2].(*expression.Constant); !ok || escape.Value.GetInt64() != int64('\\') {
+			continue
+		}
+		colName, datums := e.extractColBinaryOpConsExpr(extractCols, fn)
+		if colName != extractColName || datums[0].IsNull() {
+			continue
+		}
+		patChars, patTypes := stringutil.CompilePattern(datums[0].GetString(), '\\')
+		var prefix []rune
+		for i := 0; i < len(patTypes) && patTypes[i] == stringutil.PatMatch; i++ {
+			prefix = append(prefix, patChars[i])
+		}
+		if len(prefix) > 0 {
+			prefixes = append(prefixes, strings.ToLower(string(prefix)))
+		}
+	}
+	return prefixes
+}
+
+func (e *TablesTableExtractor) explainInfo(p *PhysicalMemTable) string {
+	if e.SkipRequest {
+		return "skip_request:true"
+	}
+	r := new(bytes.Buffer)
+	if len(e.TableSchema) > 0 {
+		r.WriteString(fmt.Sprintf("table_schema:[%s], ", extractStringFromStringSet(e.TableSchema)))
+	}
+	if len(e.TableName) > 0 {
+		r.WriteString(fmt.Sprintf("table_name:[%s], ", extractStringFromStringSet(e.TableName)))
+	}
+	if len(e.TableSchemaPrefixes) > 0 {
+		r.WriteString(fmt.Sprintf("table_schema_prefix:[%s], ", extractStringFromStringSlice(e.TableSchemaPrefixes)))
+	}
+	if len(e.TableNamePrefixes) > 0 {
+		r.WriteString(fmt.Sprintf("table_name_prefix:[%s], ", extractStringFromStringSlice(e.TableNamePrefixes)))
+	}
+	// remove the last ", " in the message info
+	s := r.String()
+	if len(s) > 2 {
+		return s[:len(s)-2]
+	}
+	return s
+}
+
+// ForeignTableExtractor is used to extract the simple predicates which can be evaluated by
+// the foreign server of a foreign table. The extracted predicates are still evaluated by TiDB
+// on the retrieved rows, so the foreign server is only used to reduce the transferred rows.
+// Only comparisons between integer or decimal columns and constants, and `IS NULL` are pushed,
+// because the result of them doesn't depend on the collations of the foreign server.
+type ForeignTableExtractor struct {
+	// Conditions are the SQL text of the pushed predicates, they are combined by `AND`.
+	Conditions []string
+}
+
+// Extract implements the MemTablePredicateExtractor Extract interface
+func (e *ForeignTableExtractor) Extract(
+	_ sessionctx.Context,
+	schema *expression.Schema,
+	names []*types.FieldName,
+	predicates []expression.Expression,
+) []expression.Expression {
+	for _, expr := range predicates {
+		if cond, ok := foreignTableCondition(schema, names, expr); ok {
+			e.Conditions = append(e.Conditions, cond)
+		}
+	}
+	return predicates
+}
+
+func (e *ForeignTableExtractor) explainInfo(_ *PhysicalMemTable) string {
+	if len(e.Conditions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("remote_filter:[%s]", strings.Join(e.Conditions, ", "))
+}
+
+var foreignTableCompareOps = map[string]string{
+	ast.EQ: "=",
+	ast.NE: "!=",
+	ast.LT: "<",
+	ast.LE: "<=",
+	ast.GT: ">",
+	ast.GE: ">=",
+}
+
+var foreignTableReverseOps = map[string]string{
+	ast.EQ: ast.EQ,
+	ast.NE: ast.NE,
+	ast.LT: ast.GT,
+	ast.LE: ast.GE,
+	ast.GT: ast.LT,
+	ast.GE: ast.LE,
+}
+
+// foreignTableCondition returns the SQL text of expr if it can be evaluated by the foreign server.
+func foreignTableCondition(schema *expression.Schema, names []*types.FieldName, expr expression.Expression) (string, bool) {
+	fn, ok := expr.(*expression.ScalarFunction)
+	if !ok {
+		return "", false
+	}
+	args := fn.GetArgs()
+	switch fn.FuncName.L {
+	case ast.EQ, ast.NE, ast.LT, ast.LE, ast.GT, ast.GE:
+		funcName := fn.FuncName.L
+		col, ok := args[0].(*expression.Column)
+		con, isCon := args[1].(*expression.Constant)
+		if !ok || !isCon {
+			col, ok = args[1].(*expression.Column)
+			con, isCon = args[0].(*expression.Constant)
+			funcName = foreignTableReverseOps[funcName]
+		}
+		if !ok || !isCon {
+			return "", false
+		}
+		name, ok := foreignTableColumn(schema, names, col)
+		if !ok {
+			return "", false
+		}
+		value, ok := foreignTableConstant(con)
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("%s %s %s", name, foreignTableCompareOps[funcName], value), true
+	case ast.IsNull:
+		col, ok := args[0].(*expression.Column)
+		if !ok {
+			return "", false
+		}
+		name, ok := foreignTableColumn(schema, names, col)
+		if !ok {
+			return "", false
+		}
+		return name + " IS NULL", true
+	case ast.In:
+		col, ok := args[0].(*expression.Column)
+		if !ok {
+			return "", false
+		}
+		name, ok := foreignTableColumn(schema, names, col)
+		if !ok {
+			return "", false
+		}
+		values := make([]string, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			con, ok := arg.(*expression.Constant)
+			if !ok {
+				return "", false
+			}
+			value, ok := foreignTableConstant(con)
+			if !ok {
+				return "", false
+			}
+			values = append(values, value)
+		}
+		return fmt.Sprintf("%s IN (%s)", name, strings.Join(values, ", ")), true
+	}
+	return "", false
+}
+
+func foreignTableColumn(schema *expression.Schema, names []*types.FieldName, col *expression.Column) (string, bool) {
+	idx := schema.ColumnIndex(col)
+	if idx < 0 {
+		return "", false
+	}
+	switch col.RetType.EvalType() {
+	case types.ETInt, types.ETDecimal:
+	default:
+		return "", false
+	}
+	return "`" + strings.ReplaceAll(names[idx].ColName.O, "`", "``") + "`", true
+}
+
+func foreignTableConstant(con *expression.Constant) (string, bool) {
+	// The constants of cached plans may change in different executions.
+	if con.DeferredExpr != nil || con.ParamMarker != nil {
+		return "", false
+	}
+	switch con.Value.Kind() {
+	case types.KindInt64:
+		return strconv.FormatInt(con.Value.GetInt64(), 10), true
+	case types.KindUint64:
+		return strconv.FormatUint(con.Value.GetUint64(), 10), true
+	case types.KindMysqlDecimal:
+		return con.Value.GetMysqlDecimal().String(), true
+	}
+	return "", false
+}
+
+// ExternalTableExtractor is used to extract the predicates of the EXTERNAL table function. The
+// extracted predicates are evaluated on the decoded rows of the file, so the rows filtered out
+// are never materialized.
+type ExternalTableExtractor struct {
+	// Source is the file read by the table function.
+	Source *extfile.Source
+	// Filters are the extracted predicates.
+	Filters []expression.Expression
+}
+
+// Extract implements the MemTablePredicateExtractor Extract interface
+func (e *ExternalTableExtractor) Extract(
+	_ sessionctx.Context,
+	_ *expression.Schema,
+	_ []*types.FieldName,
+	predicates []expression.Expression,
+) []expression.Expression {
+	remained := make([]expression.Expression, 0, len(predicates))
+	for _, expr := range predicates {
+		if expression.IsMutableEffectsExpr(expr) || len(expression.ExtractCorColumns(expr)) > 0 {
+			remained = append(remained, expr)
+			continue
+		}
+		e.Filters = append(e.Filters, expr)
+	}
+	return remained
+}
+
+func (e *ExternalTableExtractor) explainInfo(_ *PhysicalMemTable) string {
+	r := new(bytes.Buffer)
+	r.WriteString(fmt.Sprintf("file:%s, format:%s", e.Source.Redacted(), e.Source.Format))
+	if len(e.Filters) > 0 {
+		r.WriteString(fmt.Sprintf(", filters:[%s]", expression.SortedExplainExpressionList(e.Filters)))
+	}
+	return r.String()
+}
@@ -1654,6 +1654,63 @@ func TestColumns(t *testing.T) {
 	}
 }
 
+func TestTablesTableExtractor(t *testing.T) {
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+
+	se, err := session.CreateSession4Test(store)
+	require.NoError(t, err)
+
+	var cases = []struct {
+		sql               string
+		tableSchema       set.StringSet
+		tableName         set.StringSet
+		tableSchemaPrefix []string
+		tableNamePrefix   []string
+		skipRequest       bool
+	}{
+		{
+			sql:         `select * from information_schema.TABLES where table_schema='TEST';`,
+			tableSchema: set.NewStringSet("test"),
+		},
+		{
+			sql:         "select * from information_schema.TABLES where table_name in ('TEST','t') and table_schema='a'",
+			tableSchema: set.NewStringSet("a"),
+			tableName:   set.NewStringSet("test", "t"),
+		},
+		{
+			sql:               `select * from information_schema.TABLES where table_name like 'T\_a%' and table_schema like 'Db%';`,
+			tableSchemaPrefix: []string{"db"},
+			tableNamePrefix:   []string{"t_a"},
+		},
+		{
+			sql:             `select * from information_schema.TABLES where table_name like 't_a%' and table_name like '%b';`,
+			tableNamePrefix: []string{"t"},
+		},
+		{
+			sql: `select * from information_schema.TABLES where table_name like 'a|%' escape '|';`,
+		},
+	}
+	parser := parser.New()
+	for _, ca := range cases {
+		logicalMemTable := getLogicalMemTable(t, dom, se, parser, ca.sql)
+		require.NotNil(t, logicalMemTable.Extractor)
+
+		tablesTableExtractor := logicalMemTable.Extractor.(*plannercore.TablesTableExtractor)
+		require.Equal(t, ca.skipRequest, tablesTableExtractor.SkipRequest, "SQL: %v", ca.sql)
+		require.Equal(t, ca.tableSchema.Count(), tablesTableExtractor.TableSchema.Count(), "SQL: %v", ca.sql)
+		if ca.tableSchema.Count() > 0 {
+			require.EqualValues(t, ca.tableSchema, tablesTableExtractor.TableSchema, "SQL: %v", ca.sql)
+		}
+		require.Equal(t, ca.tableName.Count(), tablesTableExtractor.TableName.Count(), "SQL: %v", ca.sql)
+		if ca.tableName.Count() > 0 {
+			require.EqualValues(t, ca.tableName, tablesTableExtractor.TableName, "SQL: %v", ca.sql)
+		}
+		require.Equal(t, ca.tableSchemaPrefix, tablesTableExtractor.TableSchemaPrefixes, "SQL: %v", ca.sql)
+		require.Equal(t, ca.tableNamePrefix, tablesTableExtractor.TableNamePrefixes, "SQL: %v", ca.sql)
+	}
+}
+
 func TestPredicateQuery(t *testing.T) {
 	store, clean := testkit.CreateMockStore(t)
 	defer clean()
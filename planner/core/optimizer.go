@@ -62,6 +62,7 @@ const (
 	flagPredicatePushDown
 	flagEliminateOuterJoin
 	flagPartitionProcessor
+	flagPartitionWiseJoin
 	flagCollectPredicateColumnsPoint
 	flagPushDownAgg
 	flagPushDownTopN
@@ -82,6 +83,7 @@ var optRuleList = []logicalOptRule{
 	&ppdSolver{},
 	&outerJoinEliminator{},
 	&partitionProcessor{},
+	&partitionWiseJoinSolver{},
 	&collectPredicateColumnsPoint{},
 	&aggregationPushDownSolver{},
 	&pushDownTopNOptimizer{},
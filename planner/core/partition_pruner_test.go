@@ -163,6 +163,68 @@ func (s *testPartitionPruneSuit) TestRangeColumnPartitionPruningForIn(c *C) {
 		"    └─TableFullScan 10000.00 cop[tikv] table:t3, partition:p2 keep order:false, stats:pseudo"))
 }
 
+func (s *testPartitionPruneSuit) TestRangeColumnPartitionPruningForMonotoneFn(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("drop database if exists test_range_col_fn")
+	tk.MustExec("create database test_range_col_fn")
+	tk.MustExec("use test_range_col_fn")
+	tk.MustExec("set @@session.tidb_partition_prune_mode='dynamic'")
+	tk.MustExec(`create table t (id int, dt date) partition by range columns(dt) (
+		partition p0 values less than ("2020-01-01"),
+		partition p1 values less than ("2021-01-01"),
+		partition p2 values less than ("2022-01-01"),
+		partition p3 values less than (maxvalue))`)
+	tk.MustExec(`insert into t values (1, "2019-06-01"), (2, "2020-01-01"), (3, "2020-12-31"), (4, "2021-06-01"), (5, "2022-01-01"), (6, null)`)
+	tk.MustExec(`create table t2 (a int) partition by range columns(a) (
+		partition p0 values less than (0),
+		partition p1 values less than (10),
+		partition p2 values less than (20))`)
+	tk.MustExec(`insert into t2 values (-1), (1), (11), (null)`)
+
+	// The used partitions are checked against the result of the query. The partition whose upper
+	// bound matches the condition is kept for the non-strict monotone functions like year.
+	for _, tt := range []struct {
+		sql        string
+		partitions string
+		rows       []string
+	}{
+		{"select id from t where year(dt) < 2020", "p0", []string{"1"}},
+		{"select id from t where year(dt) <= 2020", "p0,p1", []string{"1", "2", "3"}},
+		{"select id from t where year(dt) = 2021", "p1,p2", []string{"4"}},
+		{"select id from t where year(dt) > 2020", "p1,p2,p3", []string{"4", "5"}},
+		{"select id from t where year(dt) >= 2022", "p2,p3", []string{"5"}},
+		{"select id from t where 2021 < year(dt)", "p2,p3", []string{"5"}},
+		{"select id from t where to_days(dt) < to_days('2020-01-01')", "p0", []string{"1"}},
+		{"select id from t where to_days(dt) >= to_days('2020-12-31')", "p1,p2,p3", []string{"3", "4", "5"}},
+		{"select id from t where to_days(dt) = to_days('2021-06-01')", "p2", []string{"4"}},
+		{"select id from t where year(dt) in (2019, 2022)", "p0,p2,p3", []string{"1", "5"}},
+		{"select id from t where year(dt) in (2020, null)", "p0,p1", []string{"2", "3"}},
+		{"select id from t where year(dt) in (2030)", "p3", nil},
+		{"select a from t2 where a + 1 < 1", "p0", []string{"-1"}},
+		{"select a from t2 where a - 5 >= 6", "p2", []string{"11"}},
+		{"select a from t2 where a + 1 >= 10", "p1,p2", []string{"11"}},
+		{"select a from t2 where a + 1 in (2, 12)", "p1,p2", []string{"1", "11"}},
+	} {
+		rows := tk.MustQuery("explain format='brief' " + tt.sql).Rows()
+		c.Assert(fmt.Sprint(rows), Matches, ".*partition:"+tt.partitions+" .*", Commentf("sql: %s", tt.sql))
+		tk.MustQuery(tt.sql + " order by 1").Check(testkit.Rows(tt.rows...))
+	}
+
+	// The string columns and the non-monotone functions are not pruned.
+	tk.MustExec(`create table t3 (a varchar(10)) partition by range columns(a) (
+		partition p0 values less than ("10"),
+		partition p1 values less than ("20"))`)
+	c.Assert(fmt.Sprint(tk.MustQuery("explain format='brief' select * from t3 where a + 1 < 5").Rows()), Matches, ".*partition:all.*")
+	c.Assert(fmt.Sprint(tk.MustQuery("explain format='brief' select * from t where month(dt) < 5").Rows()), Matches, ".*partition:all.*")
+
+	// The pruning is turned off by the fix control.
+	tk.MustExec("set @@tidb_opt_fix_control = '756:OFF'")
+	c.Assert(fmt.Sprint(tk.MustQuery("explain format='brief' select id from t where year(dt) < 2020").Rows()), Matches, ".*partition:all.*")
+	tk.MustQuery("select id from t where year(dt) < 2020").Check(testkit.Rows("1"))
+	tk.MustExec("set @@tidb_opt_fix_control = '756:ON'")
+	c.Assert(fmt.Sprint(tk.MustQuery("explain format='brief' select id from t where year(dt) < 2020").Rows()), Matches, ".*partition:p0 .*")
+}
+
 func (s *testPartitionPruneSuit) TestListPartitionPruner(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("drop database if exists test_partition;")
@@ -118,3 +118,40 @@ func getList(otrace *tracing.PhysicalOptimizeTracer) (ll []string, pl []string)
 	sort.Strings(pl)
 	return ll, pl
 }
+
+func TestTraceCrossoverSelectivity(t *testing.T) {
+	p := parser.New()
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	ctx := tk.Session().(sessionctx.Context)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int primary key, b int, c int, index ib(b))")
+	stmt, err := p.ParseOneStmt("select * from t where b < 10", "", "")
+	require.NoError(t, err)
+	err = core.Preprocess(ctx, stmt, core.WithPreprocessorReturn(&core.PreprocessorReturn{InfoSchema: dom.InfoSchema()}))
+	require.NoError(t, err)
+	sctx := core.MockContext()
+	sctx.GetSessionVars().StmtCtx.EnableOptimizeTrace = true
+	builder, _ := core.NewPlanBuilder().Init(sctx, dom.InfoSchema(), &hint.BlockHintProcessor{})
+	domain.GetDomain(sctx).MockInfoCacheAndLoadInfoSchema(dom.InfoSchema())
+	plan, err := builder.Build(context.TODO(), stmt)
+	require.NoError(t, err)
+	_, _, err = core.DoOptimize(context.TODO(), sctx, builder.GetOptFlag(), plan.(core.LogicalPlan))
+	require.NoError(t, err)
+	otrace := sctx.GetSessionVars().StmtCtx.OptimizeTracer.Physical
+	require.NotNil(t, otrace)
+	// The crossover selectivity is only traced for the double read candidate.
+	var traced []string
+	for _, candidates := range otrace.State {
+		for name, c := range candidates {
+			if c.CrossoverSelectivity > 0 {
+				require.LessOrEqual(t, c.CrossoverSelectivity, 1.0)
+				traced = append(traced, name)
+			}
+		}
+	}
+	require.Len(t, traced, 1)
+	require.True(t, strings.HasPrefix(traced[0], "IndexLookUp"), traced[0])
+}
@@ -765,6 +765,10 @@ type PhysicalHashJoin struct {
 
 	Concurrency     uint
 	EqualConditions []*expression.ScalarFunction
+	// NAEqualConditions stores the equal conditions of `NOT IN` on nullable columns for the null-aware anti semi join.
+	// Their arguments are the join keys, but they can't be regarded as EqualConditions since they may be evaluated
+	// to NULL, so they are resolved against the merged schema of the children and evaluated by the joiner as well.
+	NAEqualConditions []*expression.ScalarFunction
 
 	// use the outer table to build a hash table when the outer table is smaller.
 	UseOuterToBuild bool
@@ -788,15 +792,21 @@ func (p *PhysicalHashJoin) Clone() (PhysicalPlan, error) {
 	for _, c := range p.EqualConditions {
 		cloned.EqualConditions = append(cloned.EqualConditions, c.Clone().(*expression.ScalarFunction))
 	}
+	for _, c := range p.NAEqualConditions {
+		cloned.NAEqualConditions = append(cloned.NAEqualConditions, c.Clone().(*expression.ScalarFunction))
+	}
 	return cloned, nil
 }
 
 // ExtractCorrelatedCols implements PhysicalPlan interface.
 func (p *PhysicalHashJoin) ExtractCorrelatedCols() []*expression.CorrelatedColumn {
-	corCols := make([]*expression.CorrelatedColumn, 0, len(p.EqualConditions)+len(p.LeftConditions)+len(p.RightConditions)+len(p.OtherConditions))
+	corCols := make([]*expression.CorrelatedColumn, 0, len(p.EqualConditions)+len(p.NAEqualConditions)+len(p.LeftConditions)+len(p.RightConditions)+len(p.OtherConditions))
 	for _, fun := range p.EqualConditions {
 		corCols = append(corCols, expression.ExtractCorColumns(fun)...)
 	}
+	for _, fun := range p.NAEqualConditions {
+		corCols = append(corCols, expression.ExtractCorColumns(fun)...)
+	}
 	for _, fun := range p.LeftConditions {
 		corCols = append(corCols, expression.ExtractCorColumns(fun)...)
 	}
@@ -812,10 +822,20 @@ func (p *PhysicalHashJoin) ExtractCorrelatedCols() []*expression.CorrelatedColum
 // NewPhysicalHashJoin creates a new PhysicalHashJoin from LogicalJoin.
 func NewPhysicalHashJoin(p *LogicalJoin, innerIdx int, useOuterToBuild bool, newStats *property.StatsInfo, prop ...*property.PhysicalProperty) *PhysicalHashJoin {
 	leftJoinKeys, rightJoinKeys, isNullEQ, _ := p.GetJoinKeys()
+	otherConds := p.OtherConditions
+	var naEQConds []*expression.ScalarFunction
+	if len(leftJoinKeys) == 0 {
+		naEQConds, otherConds = p.extractNAEQConditions()
+		for _, cond := range naEQConds {
+			leftJoinKeys = append(leftJoinKeys, cond.GetArgs()[0].(*expression.Column))
+			rightJoinKeys = append(rightJoinKeys, cond.GetArgs()[1].(*expression.Column))
+			isNullEQ = append(isNullEQ, false)
+		}
+	}
 	baseJoin := basePhysicalJoin{
 		LeftConditions:  p.LeftConditions,
 		RightConditions: p.RightConditions,
-		OtherConditions: p.OtherConditions,
+		OtherConditions: otherConds,
 		LeftJoinKeys:    leftJoinKeys,
 		RightJoinKeys:   rightJoinKeys,
 		IsNullEQ:        isNullEQ,
@@ -824,14 +844,50 @@ func NewPhysicalHashJoin(p *LogicalJoin, innerIdx int, useOuterToBuild bool, new
 		InnerChildIdx:   innerIdx,
 	}
 	hashJoin := PhysicalHashJoin{
-		basePhysicalJoin: baseJoin,
-		EqualConditions:  p.EqualConditions,
-		Concurrency:      uint(p.ctx.GetSessionVars().HashJoinConcurrency()),
-		UseOuterToBuild:  useOuterToBuild,
+		basePhysicalJoin:  baseJoin,
+		EqualConditions:   p.EqualConditions,
+		NAEqualConditions: naEQConds,
+		Concurrency:       uint(p.ctx.GetSessionVars().HashJoinConcurrency()),
+		UseOuterToBuild:   useOuterToBuild,
 	}.Init(p.ctx, newStats, p.blockOffset, prop...)
 	return hashJoin
 }
 
+// extractNAEQConditions extracts the equal conditions of `NOT IN` whose arguments are columns from both sides,
+// e.g. `t.a = s.a` of `t.a not in (select s.a from s)`. They are kept in OtherConditions by the logical plan,
+// because `NULL NOT IN (...)` and `... NOT IN (NULL)` are not FALSE, and they are returned with the left column
+// as the first argument. The other conditions are returned as otherConds.
+func (p *LogicalJoin) extractNAEQConditions() (naEQConds []*expression.ScalarFunction, otherConds []expression.Expression) {
+	if (p.JoinType != AntiSemiJoin && p.JoinType != AntiLeftOuterSemiJoin) || !p.ctx.GetSessionVars().EnableNullAwareAntiJoin {
+		return nil, p.OtherConditions
+	}
+	lSchema, rSchema := p.children[0].Schema(), p.children[1].Schema()
+	otherConds = make([]expression.Expression, 0, len(p.OtherConditions))
+	for _, cond := range p.OtherConditions {
+		sf, ok := cond.(*expression.ScalarFunction)
+		if !ok || !expression.IsEQCondFromIn(sf) {
+			otherConds = append(otherConds, cond)
+			continue
+		}
+		lCol, lOk := sf.GetArgs()[0].(*expression.Column)
+		rCol, rOk := sf.GetArgs()[1].(*expression.Column)
+		if lOk && rOk && lSchema.Contains(rCol) && rSchema.Contains(lCol) {
+			lCol, rCol = rCol, lCol
+		}
+		if !lOk || !rOk || !lSchema.Contains(lCol) || !rSchema.Contains(rCol) ||
+			lCol.GetType().EvalType() != rCol.GetType().EvalType() {
+			otherConds = append(otherConds, cond)
+			continue
+		}
+		newCond := expression.NewFunctionInternal(p.ctx, sf.FuncName.L, sf.GetType(), lCol, rCol).(*expression.ScalarFunction)
+		naEQConds = append(naEQConds, newCond)
+	}
+	if len(naEQConds) == 0 {
+		return nil, p.OtherConditions
+	}
+	return naEQConds, otherConds
+}
+
 // PhysicalIndexJoin represents the plan of index look up join.
 type PhysicalIndexJoin struct {
 	basePhysicalJoin
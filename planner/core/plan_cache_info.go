@@ -0,0 +1,350 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"math"
+	"reflect"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/execdetails"
+	"github.com/pingcap/tidb/util/kvcache"
+	atomic2 "go.uber.org/atomic"
+)
+
+// The reasons why the plans are evicted from the prepared plan cache.
+const (
+	PlanCacheEvictCapacity       = "capacity"
+	PlanCacheEvictMemory         = "memory"
+	PlanCacheEvictBindingChanged = "binding changed"
+	PlanCacheEvictDirtyTable     = "dirty table"
+	PlanCacheEvictFlush          = "flush"
+	PlanCacheEvictDeallocate     = "deallocate"
+	PlanCacheEvictReplaced       = "replaced"
+	PlanCacheEvictRowDeviation   = "row count deviation"
+)
+
+// planCacheMinDeviatedRows is the least actual rows of an operator to be regarded as deviated from the estimate,
+// the plans reading fewer rows can hardly regress.
+const planCacheMinDeviatedRows = 100
+
+// maxEvictedPlanCacheItems is the number of the recently evicted plans kept for INFORMATION_SCHEMA.PLAN_CACHE.
+const maxEvictedPlanCacheItems = 100
+
+// PlanCacheItem is the information of a plan in the prepared plan cache.
+type PlanCacheItem struct {
+	ConnID      uint64
+	SchemaName  string
+	Digest      string
+	DigestText  string
+	PlanDigest  string
+	MemSize     int64
+	HitCount    int64
+	CreateTime  time.Time
+	LastHitTime time.Time
+	// EvictTime and EvictReason are only set for the evicted plans.
+	EvictTime   time.Time
+	EvictReason string
+}
+
+type planCacheItem struct {
+	info        PlanCacheItem
+	hitCount    atomic2.Int64
+	lastHitTime atomic2.Int64
+}
+
+func (item *planCacheItem) snapshot() PlanCacheItem {
+	info := item.info
+	info.HitCount = item.hitCount.Load()
+	if lastHit := item.lastHitTime.Load(); lastHit > 0 {
+		info.LastHitTime = time.Unix(0, lastHit)
+	}
+	return info
+}
+
+// planCacheItems tracks the plans of all the prepared plan caches, because the caches themselves
+// are not thread-safe and can't be read by other sessions.
+var planCacheItems = struct {
+	sync.Mutex
+	caches  map[*kvcache.SimpleLRUCache]map[*planCacheItem]struct{}
+	evicted []PlanCacheItem
+	next    int
+}{
+	caches: make(map[*kvcache.SimpleLRUCache]map[*planCacheItem]struct{}),
+}
+
+// NewPreparedPlanCache creates a prepared plan cache of a session, whose evictions are recorded.
+func NewPreparedPlanCache() *kvcache.SimpleLRUCache {
+	cache := kvcache.NewSimpleLRUCache(PreparedPlanCacheCapacity, PreparedPlanCacheMemoryGuardRatio, PreparedPlanCacheMaxMemory.Load())
+	cache.SetOnEvict(func(_ kvcache.Key, value kvcache.Value) {
+		// The evicted element is still counted in the size when onEvict is called.
+		reason := PlanCacheEvictMemory
+		if uint(cache.Size()) > cache.Capacity() {
+			reason = PlanCacheEvictCapacity
+		}
+		evictPlanCacheValues(cache, value.([]*PlanCacheValue), reason)
+	})
+	return cache
+}
+
+// DeletePlanCache deletes the plans of the key from the prepared plan cache for the reason.
+func DeletePlanCache(cache *kvcache.SimpleLRUCache, key kvcache.Key, reason string) {
+	if value, ok := cache.Get(key); ok {
+		evictPlanCacheValues(cache, value.([]*PlanCacheValue), reason)
+	}
+	cache.Delete(key)
+}
+
+// DeleteAllPlanCache deletes all the plans from the prepared plan cache for the reason.
+func DeleteAllPlanCache(cache *kvcache.SimpleLRUCache, reason string) {
+	for _, value := range cache.Values() {
+		evictPlanCacheValues(cache, value.([]*PlanCacheValue), reason)
+	}
+	cache.DeleteAll()
+}
+
+// ReleasePlanCache stops tracking the plans of the prepared plan cache when the session is closed.
+func ReleasePlanCache(cache *kvcache.SimpleLRUCache) {
+	if cache == nil {
+		return
+	}
+	planCacheItems.Lock()
+	delete(planCacheItems.caches, cache)
+	planCacheItems.Unlock()
+}
+
+// PlanCacheItems returns the plans in the prepared plan caches of this instance, followed by
+// the recently evicted ones.
+func PlanCacheItems() []PlanCacheItem {
+	planCacheItems.Lock()
+	defer planCacheItems.Unlock()
+	items := make([]PlanCacheItem, 0, len(planCacheItems.evicted))
+	for _, cacheItems := range planCacheItems.caches {
+		for item := range cacheItems {
+			items = append(items, item.snapshot())
+		}
+	}
+	items = append(items, planCacheItems.evicted[planCacheItems.next:]...)
+	return append(items, planCacheItems.evicted[:planCacheItems.next]...)
+}
+
+func newPlanCacheItem(vars *variable.SessionVars, stmt *CachedPrepareStmt, value *PlanCacheValue) *planCacheItem {
+	item := &planCacheItem{info: PlanCacheItem{
+		ConnID:     vars.ConnectionID,
+		SchemaName: vars.CurrentDB,
+		DigestText: stmt.NormalizedSQL,
+		MemSize:    planCacheValueMemSize(value),
+		CreateTime: time.Now(),
+	}}
+	if stmt.SQLDigest != nil {
+		item.info.Digest = stmt.SQLDigest.String()
+	}
+	if stmt.PlanDigest != nil {
+		item.info.PlanDigest = stmt.PlanDigest.String()
+	}
+	return item
+}
+
+func registerPlanCacheValue(cache *kvcache.SimpleLRUCache, value *PlanCacheValue) {
+	if value.item == nil {
+		return
+	}
+	planCacheItems.Lock()
+	defer planCacheItems.Unlock()
+	cacheItems, ok := planCacheItems.caches[cache]
+	if !ok {
+		cacheItems = make(map[*planCacheItem]struct{})
+		planCacheItems.caches[cache] = cacheItems
+	}
+	cacheItems[value.item] = struct{}{}
+}
+
+func evictPlanCacheValues(cache *kvcache.SimpleLRUCache, values []*PlanCacheValue, reason string) {
+	now := time.Now()
+	planCacheItems.Lock()
+	defer planCacheItems.Unlock()
+	cacheItems := planCacheItems.caches[cache]
+	for _, value := range values {
+		if _, ok := cacheItems[value.item]; !ok {
+			continue
+		}
+		delete(cacheItems, value.item)
+		metrics.PlanCacheEvictCounter.WithLabelValues(reason).Inc()
+		info := value.item.snapshot()
+		info.EvictTime, info.EvictReason = now, reason
+		if len(planCacheItems.evicted) < maxEvictedPlanCacheItems {
+			planCacheItems.evicted = append(planCacheItems.evicted, info)
+			continue
+		}
+		planCacheItems.evicted[planCacheItems.next] = info
+		planCacheItems.next = (planCacheItems.next + 1) % maxEvictedPlanCacheItems
+	}
+}
+
+func (v *PlanCacheValue) recordHit() {
+	if v.item != nil {
+		v.item.hitCount.Inc()
+		v.item.lastHitTime.Store(time.Now().UnixNano())
+	}
+}
+
+// PlanCacheHit is the cached plan used by an EXECUTE statement.
+type PlanCacheHit struct {
+	Key   kvcache.Key
+	Value *PlanCacheValue
+}
+
+// ObservePlanCacheHit checks the actual rows of the cached plan against the estimated ones after the execution.
+// The plan is built for the parameters of the execution caching it, so it may be terrible for other parameters,
+// e.g. an index lookup chosen for a rare value is used for a common one. The plan is evicted, and rebuilt by the
+// next execution, once the rows deviate for tidb_plan_cache_row_deviation_executions consecutive executions.
+func ObservePlanCacheHit(sctx sessionctx.Context, hit *PlanCacheHit) {
+	vars := sctx.GetSessionVars()
+	if vars.PlanCacheRowDeviationRatio <= 0 || vars.StmtCtx.RuntimeStatsColl == nil {
+		return
+	}
+	if !planRowsDeviate(hit.Value.Plan, vars.StmtCtx.RuntimeStatsColl, vars.PlanCacheRowDeviationRatio) {
+		hit.Value.deviatedExecutions = 0
+		return
+	}
+	hit.Value.deviatedExecutions++
+	if hit.Value.deviatedExecutions >= vars.PlanCacheRowDeviationExecutions {
+		deletePlanCacheValue(sctx.PreparedPlanCache(), hit.Key, hit.Value, PlanCacheEvictRowDeviation)
+	}
+}
+
+// planRowsDeviate checks whether any operator of the plan returns more rows than the ratio of the estimated ones.
+// Only the underestimation counts, because the operators may stop early, e.g. under a limit. The inner side of
+// the index joins and the applies is skipped as its rows are estimated for one lookup.
+func planRowsDeviate(p Plan, coll *execdetails.RuntimeStatsColl, ratio float64) bool {
+	switch x := p.(type) {
+	case *Insert:
+		return x.SelectPlan != nil && planRowsDeviate(x.SelectPlan, coll, ratio)
+	case *Update:
+		return planRowsDeviate(x.SelectPlan, coll, ratio)
+	case *Delete:
+		return planRowsDeviate(x.SelectPlan, coll, ratio)
+	}
+	pp, ok := p.(PhysicalPlan)
+	if !ok {
+		return false
+	}
+	if operatorRowsDeviate(pp, coll, ratio) {
+		return true
+	}
+	var pushedDown []PhysicalPlan
+	children := pp.Children()
+	switch x := pp.(type) {
+	case *PhysicalTableReader:
+		pushedDown = x.TablePlans
+	case *PhysicalIndexReader:
+		pushedDown = x.IndexPlans
+	case *PhysicalIndexLookUpReader:
+		pushedDown = append(append(pushedDown, x.IndexPlans...), x.TablePlans...)
+	case *PhysicalIndexMergeReader:
+		for _, partialPlans := range x.PartialPlans {
+			pushedDown = append(pushedDown, partialPlans...)
+		}
+		pushedDown = append(pushedDown, x.TablePlans...)
+	case *PhysicalIndexJoin:
+		children = children[1-x.InnerChildIdx : 2-x.InnerChildIdx]
+	case *PhysicalIndexHashJoin:
+		children = children[1-x.InnerChildIdx : 2-x.InnerChildIdx]
+	case *PhysicalIndexMergeJoin:
+		children = children[1-x.InnerChildIdx : 2-x.InnerChildIdx]
+	case *PhysicalApply:
+		children = children[1-x.InnerChildIdx : 2-x.InnerChildIdx]
+	}
+	// The pushed down plans are flattened, so they're checked without the children.
+	for _, child := range pushedDown {
+		if operatorRowsDeviate(child, coll, ratio) {
+			return true
+		}
+	}
+	for _, child := range children {
+		if planRowsDeviate(child, coll, ratio) {
+			return true
+		}
+	}
+	return false
+}
+
+func operatorRowsDeviate(p PhysicalPlan, coll *execdetails.RuntimeStatsColl, ratio float64) bool {
+	var actRows int64
+	if coll.ExistsCopStats(p.ID()) {
+		actRows = coll.GetCopStats(p.ID()).GetActRows()
+	} else if coll.ExistsRootStats(p.ID()) {
+		actRows = coll.GetRootStats(p.ID()).GetActRows()
+	}
+	return actRows >= planCacheMinDeviatedRows && float64(actRows) > math.Max(p.StatsCount(), 1)*ratio
+}
+
+// deletePlanCacheValue deletes a plan of the key from the prepared plan cache, leaving the plans of the other
+// parameter types.
+func deletePlanCacheValue(cache *kvcache.SimpleLRUCache, key kvcache.Key, value *PlanCacheValue, reason string) {
+	cacheVals, ok := cache.Get(key)
+	if !ok {
+		return
+	}
+	vals := cacheVals.([]*PlanCacheValue)
+	remained := make([]*PlanCacheValue, 0, len(vals))
+	for _, v := range vals {
+		if v != value {
+			remained = append(remained, v)
+		}
+	}
+	if len(remained) == len(vals) {
+		return
+	}
+	evictPlanCacheValues(cache, []*PlanCacheValue{value}, reason)
+	if len(remained) == 0 {
+		cache.Delete(key)
+	} else {
+		cache.Put(key, remained)
+	}
+}
+
+// planCacheValueMemSize estimates the memory size of the cached plan by the sizes of the operators
+// and the output names, the memory referenced by the operators isn't counted.
+func planCacheValueMemSize(v *PlanCacheValue) int64 {
+	size := int64(unsafe.Sizeof(*v)) + planMemSize(v.Plan) + int64(len(v.BindSQL))
+	size += int64(len(v.UserVarTypes)) * int64(unsafe.Sizeof(types.FieldType{}))
+	for _, name := range v.OutPutNames {
+		size += int64(unsafe.Sizeof(*name)) + int64(len(name.ColName.O)+len(name.ColName.L))
+	}
+	return size
+}
+
+func planMemSize(p Plan) int64 {
+	if p == nil {
+		return 0
+	}
+	var size int64
+	if tp := reflect.TypeOf(p); tp.Kind() == reflect.Ptr {
+		size = int64(tp.Elem().Size())
+	}
+	if physicalPlan, ok := p.(PhysicalPlan); ok {
+		for _, child := range physicalPlan.Children() {
+			size += planMemSize(child)
+		}
+	}
+	return size
+}
@@ -0,0 +1,141 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core_test
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/testkit"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanCacheTable(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	orgEnable := core.PreparedPlanCacheEnabled()
+	orgCapacity := core.PreparedPlanCacheCapacity
+	defer func() {
+		core.SetPreparedPlanCache(orgEnable)
+		core.PreparedPlanCacheCapacity = orgCapacity
+	}()
+	core.SetPreparedPlanCache(true)
+	core.PreparedPlanCacheCapacity = 1
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int, b int, key(a))")
+	tk.MustExec("prepare stmt1 from 'select * from t where a = ?'")
+	tk.MustExec("set @a = 1")
+	tk.MustExec("execute stmt1 using @a")
+	tk.MustExec("execute stmt1 using @a")
+	tk.MustExec("execute stmt1 using @a")
+	query := "select schema_name, digest_text, hit_count, last_hit_time is not null, mem_size > 0, evict_reason " +
+		"from information_schema.plan_cache where connection_id = connection_id() order by create_time"
+	tk.MustQuery(query).Check(testkit.Rows("test select * from `t` where `a` = ? 2 1 1 <nil>"))
+
+	// The capacity of the cache is 1, so the plan of stmt1 is evicted.
+	tk.MustExec("prepare stmt2 from 'select * from t where b = ?'")
+	tk.MustExec("execute stmt2 using @a")
+	tk.MustQuery(query).Check(testkit.Rows(
+		"test select * from `t` where `a` = ? 2 1 1 capacity",
+		"test select * from `t` where `b` = ? 0 0 1 <nil>"))
+	tk.MustExec("deallocate prepare stmt2")
+	tk.MustQuery(query + ", evict_time").Check(testkit.Rows(
+		"test select * from `t` where `a` = ? 2 1 1 capacity",
+		"test select * from `t` where `b` = ? 0 0 1 deallocate"))
+
+	tk.MustExec("execute stmt1 using @a")
+	tk.MustExec("admin flush session plan_cache")
+	tk.MustQuery(query + " desc limit 1").Check(testkit.Rows("test select * from `t` where `a` = ? 0 0 1 flush"))
+
+	// The plans of the closed sessions are gone.
+	tk2 := testkit.NewTestKit(t, store)
+	tk2.MustExec("use test")
+	tk2.MustExec("prepare stmt from 'select * from t where a = ?'")
+	tk2.MustExec("set @a = 1")
+	tk2.MustExec("execute stmt using @a")
+	connID := tk2.Session().GetSessionVars().ConnectionID
+	tk.MustQuery("select count(*) from information_schema.plan_cache where evict_reason is null and connection_id = ?", connID).Check(testkit.Rows("1"))
+	tk2.Session().Close()
+	tk.MustQuery("select count(*) from information_schema.plan_cache where evict_reason is null and connection_id = ?", connID).Check(testkit.Rows("0"))
+}
+
+func TestPlanCacheRowDeviation(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	orgEnable := core.PreparedPlanCacheEnabled()
+	defer core.SetPreparedPlanCache(orgEnable)
+	core.SetPreparedPlanCache(true)
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int, b int, key(a))")
+	tk.MustExec("insert into t values (1, 0), (2, 0)")
+	for i := 0; i < 9; i++ {
+		tk.MustExec("insert into t select a, b from t where a = 2")
+	}
+	tk.MustExec("analyze table t")
+	tk.MustExec("set @@tidb_plan_cache_row_deviation_ratio = 10, @@tidb_plan_cache_row_deviation_executions = 2")
+	tk.MustExec("prepare stmt from 'select * from t where a = ?'")
+	tk.MustExec("set @rare = 1, @common = 2")
+	// The plan is built for the rare value, so it reads much more rows than estimated for the common one.
+	tk.MustExec("execute stmt using @rare")
+	tk.MustQuery("execute stmt using @common")
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	// A non-deviated execution starts counting again.
+	tk.MustExec("execute stmt using @rare")
+	tk.MustQuery("execute stmt using @common")
+	query := "select hit_count, evict_reason from information_schema.plan_cache where connection_id = connection_id() order by create_time"
+	tk.MustQuery(query).Check(testkit.Rows("3 <nil>"))
+
+	counter := metrics.PlanCacheEvictCounter.WithLabelValues(core.PlanCacheEvictRowDeviation)
+	pb := &dto.Metric{}
+	require.NoError(t, counter.Write(pb))
+	evicted := pb.GetCounter().GetValue()
+	tk.MustQuery("execute stmt using @common")
+	tk.MustQuery(query).Check(testkit.Rows("4 row count deviation"))
+	require.NoError(t, counter.Write(pb))
+	require.Equal(t, evicted+1, pb.GetCounter().GetValue())
+	tk.MustQuery("execute stmt using @common")
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+
+	// The check is disabled by default.
+	tk.MustExec("set @@tidb_plan_cache_row_deviation_ratio = default")
+	tk.MustExec("prepare stmt from 'select * from t where a = ?'")
+	tk.MustExec("execute stmt using @rare")
+	for i := 0; i < 3; i++ {
+		tk.MustQuery("execute stmt using @common")
+		tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	}
+}
+
+func TestBindingCacheTable(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int, b int, key(a))")
+	tk.MustExec("create global binding for select * from t where a = 1 using select * from t use index(a) where a = 1")
+	tk.MustQuery("select original_sql, default_db, status, hit_count, last_hit_time, mem_size > 0 from information_schema.binding_cache").Check(
+		testkit.Rows("select * from `test` . `t` where `a` = ? test using 0 <nil> 1"))
+	tk.MustExec("select * from t where a = 2")
+	tk.MustExec("select * from t where a = 3")
+	tk.MustQuery("select hit_count, last_hit_time is not null from information_schema.binding_cache").Check(testkit.Rows("2 1"))
+	tk.MustExec("drop global binding for select * from t where a = 1")
+	tk.MustQuery("select count(*) from information_schema.binding_cache where status = 'using'").Check(testkit.Rows("0"))
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/errno"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/expression/aggregation"
 	"github.com/pingcap/tidb/parser/ast"
@@ -849,3 +850,137 @@ func TestBuildFinalModeAggregation(t *testing.T) {
 	checkResult(ctx, mixedAggFuncs, emptyGroupByItems)
 	checkResult(ctx, mixedAggFuncs, groupByItems)
 }
+
+func TestTTLScanFilter(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(id int primary key, created_at datetime, key(created_at)) ttl = created_at + interval 1 day")
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `id` int(11) NOT NULL,\n" +
+		"  `created_at` datetime DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`) /*T![clustered_index] CLUSTERED */,\n" +
+		"  KEY `created_at` (`created_at`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin /*T![ttl] TTL=`created_at` + INTERVAL 1 DAY */ /*T![ttl] TTL_ENABLE='ON' */"))
+	tk.MustExec("insert into t values (1, now() - interval 2 day), (2, now()), (3, now() - interval 1 hour)")
+
+	tk.MustQuery("select id from t order by id").Check(testkit.Rows("2", "3"))
+	tk.MustQuery("select id from t where id = 1").Check(testkit.Rows())
+	tk.MustQuery("select id from t where id in (1, 2)").Check(testkit.Rows("2"))
+	tk.MustQuery("select count(*) from t as x where x.id > 0").Check(testkit.Rows("2"))
+	// The filter is pushed down to the coprocessor.
+	rows := tk.MustQuery("explain select id from t where id > 0").Rows()
+	found := false
+	for _, row := range rows {
+		if strings.Contains(row[0].(string), "Selection") {
+			require.Equal(t, "cop[tikv]", row[2])
+			require.Contains(t, row[4], "ge(test.t.created_at")
+			found = true
+		}
+	}
+	require.True(t, found)
+
+	// UPDATE and DELETE see the expired rows.
+	tk.MustExec("update t set created_at = now() where id = 1")
+	require.Equal(t, uint64(1), tk.Session().AffectedRows())
+	tk.MustQuery("select id from t order by id").Check(testkit.Rows("1", "2", "3"))
+	tk.MustExec("update t set created_at = now() - interval 2 day where id = 1")
+	tk.MustQuery("select id from t order by id").Check(testkit.Rows("2", "3"))
+	tk.MustExec("delete from t where created_at < now() - interval 1 day")
+	require.Equal(t, uint64(1), tk.Session().AffectedRows())
+	tk.MustExec("insert into t values (1, now() - interval 2 day)")
+
+	// The expired rows are visible after the TTL is disabled or removed.
+	tk.MustExec("alter table t ttl_enable = 'off'")
+	tk.MustQuery("select id from t order by id").Check(testkit.Rows("1", "2", "3"))
+	tk.MustExec("alter table t ttl_enable = 'on' ttl = created_at + interval 3 day")
+	tk.MustQuery("select id from t order by id").Check(testkit.Rows("1", "2", "3"))
+	tk.MustExec("alter table t remove ttl")
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `id` int(11) NOT NULL,\n" +
+		"  `created_at` datetime DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`) /*T![clustered_index] CLUSTERED */,\n" +
+		"  KEY `created_at` (`created_at`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin"))
+	tk.MustExec("alter table t ttl = created_at + interval 30 minute")
+	tk.MustQuery("select id from t order by id").Check(testkit.Rows("2"))
+
+	// The TTL column is protected.
+	tk.MustGetErrMsg("alter table t drop column created_at", "[ddl:8200]Column 'created_at' is the TTL column, it can't be dropped")
+	tk.MustGetErrMsg("alter table t rename column created_at to c", "[ddl:8200]Column 'created_at' is the TTL column, it can't be renamed")
+	tk.MustGetErrMsg("alter table t modify column created_at int", "[ddl:8200]Column 'created_at' of type int can't be the TTL column, only the DATE, DATETIME and TIMESTAMP columns can")
+	tk.MustExec("alter table t modify column created_at timestamp")
+
+	tk.MustExec("drop table t")
+	tk.MustGetErrCode("create table t(id int, c varchar(10)) ttl = c + interval 1 day", errno.ErrUnsupportedDDLOperation)
+	tk.MustGetErrCode("create table t(id int, c datetime) ttl = d + interval 1 day", errno.ErrBadField)
+	tk.MustGetErrCode("create table t(id int, c datetime) ttl_enable = 'on'", errno.ErrUnsupportedDDLOperation)
+	tk.MustExec("create table t(id int, c datetime)")
+	tk.MustGetErrCode("alter table t ttl_enable = 'off'", errno.ErrUnsupportedDDLOperation)
+	// Removing the TTL of a table without TTL is a no-op.
+	tk.MustExec("alter table t remove ttl")
+}
+
+func TestDMLOrderByLimitReadInIndexOrder(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(id int primary key, ts int, a int, key idx_ts(ts))")
+	for i := 0; i < 50; i++ {
+		vals := make([]string, 0, 200)
+		for j := 0; j < 200; j++ {
+			id := i*200 + j
+			vals = append(vals, fmt.Sprintf("(%d, %d, %d)", id, (id*7919)%10000, id%10))
+		}
+		tk.MustExec("insert into t values " + strings.Join(vals, ","))
+	}
+	tk.MustExec("analyze table t")
+
+	// The query sorts all the rows matching the filter by a TopN.
+	tk.MustQuery("explain format = 'brief' select * from t where ts < 9000 and a = 1 order by ts limit 1000").Check(testkit.Rows(
+		"TopN 900.00 root  test.t.ts, offset:0, count:1000",
+		"└─TableReader 900.00 root  data:Selection",
+		"  └─Selection 900.00 cop[tikv]  eq(test.t.a, 1), lt(test.t.ts, 9000)",
+		"    └─TableFullScan 10000.00 cop[tikv] table:t keep order:false"))
+	// The DELETE and UPDATE read the rows in the order of the index, and stop once the limit is reached.
+	tk.MustQuery("explain format = 'brief' delete from t where ts < 9000 and a = 1 order by ts limit 1000").Check(testkit.Rows(
+		"Delete N/A root  N/A",
+		"└─Limit 900.00 root  offset:0, count:1000",
+		"  └─IndexLookUp 900.00 root  ",
+		"    ├─IndexRangeScan(Build) 9000.00 cop[tikv] table:t, index:idx_ts(ts) range:[-inf,9000), keep order:true",
+		"    └─Selection(Probe) 900.00 cop[tikv]  eq(test.t.a, 1)",
+		"      └─TableRowIDScan 9000.00 cop[tikv] table:t keep order:false"))
+	tk.MustQuery("explain format = 'brief' update t set a = 0 where ts < 9000 and a = 1 order by ts desc limit 1000").Check(testkit.Rows(
+		"Update N/A root  N/A",
+		"└─Limit 900.00 root  offset:0, count:1000",
+		"  └─IndexLookUp 900.00 root  ",
+		"    ├─IndexRangeScan(Build) 9000.00 cop[tikv] table:t, index:idx_ts(ts) range:[-inf,9000), keep order:true, desc",
+		"    └─Selection(Probe) 900.00 cop[tikv]  eq(test.t.a, 1)",
+		"      └─TableRowIDScan 9000.00 cop[tikv] table:t keep order:false"))
+	// The TopN is kept if no index keeps the order.
+	tk.MustQuery("explain format = 'brief' delete from t where a = 1 order by a, ts limit 10").Check(testkit.Rows(
+		"Delete N/A root  N/A",
+		"└─TopN 10.00 root  test.t.a, test.t.ts, offset:0, count:10",
+		"  └─TableReader 10.00 root  data:TopN",
+		"    └─TopN 10.00 cop[tikv]  test.t.a, test.t.ts, offset:0, count:10",
+		"      └─Selection 1000.00 cop[tikv]  eq(test.t.a, 1)",
+		"        └─TableFullScan 10000.00 cop[tikv] table:t keep order:false"))
+
+	tk.MustExec("delete from t where ts < 9000 and a = 1 order by ts limit 10")
+	tk.MustQuery("select ts from t where a = 1 order by ts limit 3").Check(testkit.Rows("109", "119", "129"))
+	tk.MustExec("update t set a = 0 where a = 1 order by ts desc limit 3")
+	tk.MustQuery("select ts from t where a = 0 and id % 10 = 1 order by ts").Check(testkit.Rows("9979", "9989", "9999"))
+
+	// The scans on the partition table can't keep order.
+	tk.MustExec("create table tp(id int, ts int, a int, key idx_ts(ts)) partition by hash(id) partitions 2")
+	tk.MustExec("insert into tp select * from t")
+	for _, mode := range []string{"static", "dynamic"} {
+		tk.MustExec(fmt.Sprintf("set @@tidb_partition_prune_mode = '%s'", mode))
+		rows := tk.MustQuery("explain format = 'brief' delete from tp where a = 1 order by ts limit 10").Rows()
+		require.Contains(t, rows[1][0], "TopN")
+	}
+	tk.MustExec("delete from tp where a = 1 order by ts limit 10")
+	tk.MustQuery("select ts from tp where a = 1 order by ts limit 3").Check(testkit.Rows("209", "219", "229"))
+}
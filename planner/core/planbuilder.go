@@ -43,6 +43,7 @@ import (
 	"github.com/pingcap/tidb/planner/util"
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/clustersetting"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/statistics"
@@ -102,6 +103,9 @@ type tableHintInfo struct {
 
 type limitHintInfo struct {
 	preferLimitToCop bool
+	// preferIndexOrder is set for the ORDER BY ... LIMIT of the single-table DELETE and UPDATE, so the rows are read
+	// and mutated in the order of an index instead of being sorted by a TopN once they're all read.
+	preferIndexOrder bool
 }
 
 type hintTableInfo struct {
@@ -516,6 +520,10 @@ type PlanBuilder struct {
 	isForUpdateRead             bool
 	allocIDForCTEStorage        int
 	buildingRecursivePartForCTE bool
+
+	// scalarSubqueryResults caches the results of the uncorrelated scalar subqueries that are evaluated
+	// when building the plan, keyed by the digest of the subquery, so that the identical ones run once.
+	scalarSubqueryResults map[string][]types.Datum
 }
 
 type handleColHelper struct {
@@ -732,7 +740,7 @@ func (b *PlanBuilder) Build(ctx context.Context, node ast.Node) (Plan, error) {
 		*ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt, *ast.CreateUserStmt, *ast.SetPwdStmt, *ast.AlterInstanceStmt,
 		*ast.GrantStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.RevokeStmt, *ast.KillStmt, *ast.DropStatsStmt,
 		*ast.GrantRoleStmt, *ast.RevokeRoleStmt, *ast.SetRoleStmt, *ast.SetDefaultRoleStmt, *ast.ShutdownStmt,
-		*ast.RenameUserStmt:
+		*ast.RenameUserStmt, *ast.CreateServerStmt, *ast.DropServerStmt:
 		return b.buildSimple(ctx, node.(ast.StmtNode))
 	case ast.DDLNode:
 		return b.buildDDL(ctx, x)
@@ -1310,10 +1318,18 @@ func (b *PlanBuilder) buildAdmin(ctx context.Context, as *ast.AdminStmt) (Plan,
 		p := &CleanupIndex{Table: as.Tables[0], IndexName: as.Index}
 		p.setSchemaAndNames(buildCleanupIndexFields())
 		ret = p
+	case ast.AdminRepairIndex:
+		p := &RepairIndex{Table: as.Tables[0], IndexName: as.Index}
+		p.setSchemaAndNames(buildRepairIndexFields())
+		ret = p
 	case ast.AdminChecksumTable:
 		p := &ChecksumTable{Tables: as.Tables}
 		p.setSchemaAndNames(buildChecksumTableSchema())
 		ret = p
+	case ast.AdminReclaimTable:
+		p := &ReclaimTable{Tables: as.Tables, Compact: as.Compact}
+		p.setSchemaAndNames(buildReclaimTableSchema())
+		ret = p
 	case ast.AdminShowNextRowID:
 		p := &ShowNextRowID{TableName: as.Tables[0]}
 		p.setSchemaAndNames(buildShowNextRowID())
@@ -1383,6 +1399,51 @@ func (b *PlanBuilder) buildAdmin(ctx context.Context, as *ast.AdminStmt) (Plan,
 		ret = p
 	case ast.AdminResetTelemetryID:
 		return &AdminResetTelemetryID{}, nil
+	case ast.AdminShowClusterSettings:
+		p := &AdminShowClusterSettings{}
+		p.setSchemaAndNames(buildShowClusterSettingsSchema())
+		ret = p
+	case ast.AdminSetClusterSetting:
+		value, err := evalAstExpr(b.ctx, as.SettingValue)
+		if err != nil {
+			return nil, err
+		}
+		if value.IsNull() {
+			return nil, clustersetting.ErrWrongValueForClusterSetting.GenWithStackByArgs(as.SettingName, "NULL", "it can't be NULL")
+		}
+		str, err := value.ToString()
+		if err != nil {
+			return nil, err
+		}
+		ret = &AdminSetClusterSetting{Name: as.SettingName, Value: str}
+	case ast.AdminShowSafePoints:
+		p := &AdminShowSafePoints{}
+		p.setSchemaAndNames(buildShowSafePointsSchema())
+		ret = p
+	case ast.AdminShowRedundantIndexes:
+		p := &AdminShowRedundantIndexes{}
+		p.setSchemaAndNames(buildShowRedundantIndexesSchema())
+		ret = p
+	case ast.AdminDecodeKey:
+		p := &AdminDecodeKey{Key: as.EncodedStr}
+		p.setSchemaAndNames(buildDecodeKeySchema())
+		ret = p
+	case ast.AdminDecodePlan:
+		p := &AdminDecodePlan{Plan: as.EncodedStr}
+		p.setSchemaAndNames(buildDecodePlanSchema())
+		ret = p
+	case ast.AdminShowMVCCByKey:
+		p := &AdminShowMVCCByKey{Key: as.EncodedStr}
+		p.setSchemaAndNames(buildShowMVCCByKeySchema())
+		ret = p
+	case ast.AdminSetSafePoint:
+		ttl := int64(math.MaxInt64)
+		if as.SafePointTTL < math.MaxInt64 {
+			ttl = int64(as.SafePointTTL)
+		}
+		ret = &AdminSetSafePoint{ServiceID: as.ServiceID, SafePoint: as.SafePoint, TTL: ttl}
+	case ast.AdminExpireSafePoint:
+		ret = &AdminExpireSafePoint{ServiceID: as.ServiceID}
 	case ast.AdminReloadStatistics:
 		return &Simple{Statement: as}, nil
 	case ast.AdminFlushPlanCache:
@@ -2688,6 +2749,13 @@ func buildCleanupIndexFields() (*expression.Schema, types.NameSlice) {
 	return schema.col2Schema(), schema.names
 }
 
+func buildRepairIndexFields() (*expression.Schema, types.NameSlice) {
+	schema := newColumnsWithNames(2)
+	schema.Append(buildColumnWithName("", "REMOVED_COUNT", mysql.TypeLonglong, 4))
+	schema.Append(buildColumnWithName("", "ADDED_COUNT", mysql.TypeLonglong, 4))
+	return schema.col2Schema(), schema.names
+}
+
 func buildShowDDLJobsFields() (*expression.Schema, types.NameSlice) {
 	schema := newColumnsWithNames(12)
 	schema.Append(buildColumnWithName("", "JOB_ID", mysql.TypeLonglong, 4))
@@ -2783,6 +2851,76 @@ func buildShowTelemetrySchema() (*expression.Schema, types.NameSlice) {
 	schema.Append(buildColumnWithName("", "TRACKING_ID", mysql.TypeVarchar, 64))
 	schema.Append(buildColumnWithName("", "LAST_STATUS", mysql.TypeString, mysql.MaxBlobWidth))
 	schema.Append(buildColumnWithName("", "DATA_PREVIEW", mysql.TypeString, mysql.MaxBlobWidth))
+	schema.Append(buildColumnWithName("", "REPORT_CATEGORIES", mysql.TypeString, mysql.MaxBlobWidth))
+	return schema.col2Schema(), schema.names
+}
+
+func buildShowClusterSettingsSchema() (*expression.Schema, types.NameSlice) {
+	schema := newColumnsWithNames(6)
+	schema.Append(buildColumnWithName("", "Name", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Value", mysql.TypeVarchar, 1024))
+	schema.Append(buildColumnWithName("", "Type", mysql.TypeVarchar, 16))
+	schema.Append(buildColumnWithName("", "Default_value", mysql.TypeVarchar, 1024))
+	schema.Append(buildColumnWithName("", "Read_only", mysql.TypeVarchar, 3))
+	schema.Append(buildColumnWithName("", "Comment", mysql.TypeVarchar, 1024))
+	return schema.col2Schema(), schema.names
+}
+
+func buildShowSafePointsSchema() (*expression.Schema, types.NameSlice) {
+	schema := newColumnsWithNames(4)
+	schema.Append(buildColumnWithName("", "Service_id", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Safe_point", mysql.TypeLonglong, 4))
+	schema.Append(buildColumnWithName("", "Safe_point_time", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Expired_at", mysql.TypeVarchar, 64))
+	return schema.col2Schema(), schema.names
+}
+
+func buildShowRedundantIndexesSchema() (*expression.Schema, types.NameSlice) {
+	schema := newColumnsWithNames(6)
+	schema.Append(buildColumnWithName("", "Db_name", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Table_name", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Index_name", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Reason", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Covering_index", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Last_used_at", mysql.TypeVarchar, 64))
+	return schema.col2Schema(), schema.names
+}
+
+func buildDecodeKeySchema() (*expression.Schema, types.NameSlice) {
+	schema := newColumnsWithNames(10)
+	schema.Append(buildColumnWithName("", "Db_name", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Table_name", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Table_id", mysql.TypeLonglong, 4))
+	schema.Append(buildColumnWithName("", "Partition_name", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Partition_id", mysql.TypeLonglong, 4))
+	schema.Append(buildColumnWithName("", "Handle_type", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Handle_value", mysql.TypeVarchar, 512))
+	schema.Append(buildColumnWithName("", "Index_name", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Index_id", mysql.TypeLonglong, 4))
+	schema.Append(buildColumnWithName("", "Index_values", mysql.TypeVarchar, 512))
+	return schema.col2Schema(), schema.names
+}
+
+func buildDecodePlanSchema() (*expression.Schema, types.NameSlice) {
+	schema := newColumnsWithNames(8)
+	schema.Append(buildColumnWithName("", "id", mysql.TypeString, mysql.MaxBlobWidth))
+	schema.Append(buildColumnWithName("", "task", mysql.TypeString, 64))
+	schema.Append(buildColumnWithName("", "estRows", mysql.TypeString, 64))
+	schema.Append(buildColumnWithName("", "operator info", mysql.TypeString, mysql.MaxBlobWidth))
+	schema.Append(buildColumnWithName("", "actRows", mysql.TypeString, 64))
+	schema.Append(buildColumnWithName("", "execution info", mysql.TypeString, mysql.MaxBlobWidth))
+	schema.Append(buildColumnWithName("", "memory", mysql.TypeString, 64))
+	schema.Append(buildColumnWithName("", "disk", mysql.TypeString, 64))
+	return schema.col2Schema(), schema.names
+}
+
+func buildShowMVCCByKeySchema() (*expression.Schema, types.NameSlice) {
+	schema := newColumnsWithNames(5)
+	schema.Append(buildColumnWithName("", "Kind", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Start_ts", mysql.TypeLonglong, 20))
+	schema.Append(buildColumnWithName("", "Commit_ts", mysql.TypeLonglong, 20))
+	schema.Append(buildColumnWithName("", "Type", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Value", mysql.TypeVarchar, mysql.MaxBlobWidth))
 	return schema.col2Schema(), schema.names
 }
 
@@ -3009,7 +3147,7 @@ func (b *PlanBuilder) buildSimple(ctx context.Context, node ast.StmtNode) (Plan,
 	case *ast.FlushStmt:
 		err := ErrSpecificAccessDenied.GenWithStackByArgs("RELOAD")
 		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.ReloadPriv, "", "", "", err)
-	case *ast.AlterInstanceStmt:
+	case *ast.AlterInstanceStmt, *ast.CreateServerStmt, *ast.DropServerStmt:
 		err := ErrSpecificAccessDenied.GenWithStack("SUPER")
 		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "", err)
 	case *ast.RenameUserStmt:
@@ -3080,7 +3218,7 @@ func (b *PlanBuilder) buildSimple(ctx context.Context, node ast.StmtNode) (Plan,
 	case *ast.BeginStmt:
 		readTS := b.ctx.GetSessionVars().TxnReadTS.PeakTxnReadTS()
 		if raw.AsOf != nil {
-			startTS, err := calculateTsExpr(b.ctx, raw.AsOf)
+			startTS, err := CalculateTsExpr(b.ctx, raw.AsOf)
 			if err != nil {
 				return nil, err
 			}
@@ -3097,8 +3235,8 @@ func (b *PlanBuilder) buildSimple(ctx context.Context, node ast.StmtNode) (Plan,
 	return p, nil
 }
 
-// calculateTsExpr calculates the TsExpr of AsOfClause to get a StartTS.
-func calculateTsExpr(sctx sessionctx.Context, asOfClause *ast.AsOfClause) (uint64, error) {
+// CalculateTsExpr calculates the TsExpr of AsOfClause to get a StartTS.
+func CalculateTsExpr(sctx sessionctx.Context, asOfClause *ast.AsOfClause) (uint64, error) {
 	tsVal, err := evalAstExpr(sctx, asOfClause.TsExpr)
 	if err != nil {
 		return 0, err
@@ -3326,6 +3464,12 @@ func (b *PlanBuilder) buildInsert(ctx context.Context, insert *ast.InsertStmt) (
 		}
 		return nil, err
 	}
+	if tableInfo.IsForeignTable() {
+		if insert.IsReplace {
+			return nil, ErrNonUpdatableTable.GenWithStackByArgs(tableInfo.Name.O, "REPLACE")
+		}
+		return nil, ErrNonUpdatableTable.GenWithStackByArgs(tableInfo.Name.O, "INSERT")
+	}
 	// Build Schema with DBName otherwise ColumnRef with DBName cannot match any Column in Schema.
 	schema, names, err := expression.TableInfo2SchemaAndNames(b.ctx, tn.Schema, tableInfo)
 	if err != nil {
@@ -4353,6 +4497,9 @@ func (b *PlanBuilder) buildDDL(ctx context.Context, node ast.DDLNode) (Plan, err
 	case *ast.RecoverTableStmt, *ast.FlashBackTableStmt:
 		// Recover table command can only be executed by administrator.
 		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "", nil)
+	case *ast.FlashBackClusterStmt:
+		// Flashback cluster command can only be executed by administrator.
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "", nil)
 	case *ast.LockTablesStmt, *ast.UnlockTablesStmt:
 		// TODO: add Lock Table privilege check.
 	case *ast.CleanupTableLockStmt:
@@ -4759,6 +4906,17 @@ func buildChecksumTableSchema() (*expression.Schema, []*types.FieldName) {
 	return schema.col2Schema(), schema.names
 }
 
+func buildReclaimTableSchema() (*expression.Schema, []*types.FieldName) {
+	schema := newColumnsWithNames(6)
+	schema.Append(buildColumnWithName("", "Db_name", mysql.TypeVarchar, 128))
+	schema.Append(buildColumnWithName("", "Table_name", mysql.TypeVarchar, 128))
+	schema.Append(buildColumnWithName("", "Total_regions", mysql.TypeLonglong, 22))
+	schema.Append(buildColumnWithName("", "Empty_regions", mysql.TypeLonglong, 22))
+	schema.Append(buildColumnWithName("", "Merged_regions", mysql.TypeLonglong, 22))
+	schema.Append(buildColumnWithName("", "Compacted_stores", mysql.TypeLonglong, 22))
+	return schema.col2Schema(), schema.names
+}
+
 // adjustOverlongViewColname adjusts the overlong outputNames of a view to
 // `new_exp_$off` where `$off` is the offset of the output column, $off starts from 1.
 // There is still some MySQL compatible problems.
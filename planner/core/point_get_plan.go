@@ -760,6 +760,10 @@ func tryWhereIn2BatchPointGet(ctx sessionctx.Context, selStmt *ast.SelectStmt) *
 	if tbl == nil {
 		return nil
 	}
+	// The expired rows of TTL tables are filtered by the logical plan.
+	if tbl.TTLInfo != nil && tbl.TTLInfo.Enable {
+		return nil
+	}
 	// Skip the optimization with partition selection.
 	if len(tblName.PartitionNames) > 0 {
 		return nil
@@ -856,6 +860,10 @@ func tryPointGetPlan(ctx sessionctx.Context, selStmt *ast.SelectStmt, check bool
 	if tbl == nil {
 		return nil
 	}
+	// The expired rows of TTL tables are filtered by the logical plan.
+	if tbl.TTLInfo != nil && tbl.TTLInfo.Enable {
+		return nil
+	}
 	pi := tbl.GetPartitionInfo()
 
 	for _, col := range tbl.Columns {
@@ -1322,6 +1330,10 @@ func findInPairs(colName string, pairs []nameValuePair) int {
 }
 
 func tryUpdatePointPlan(ctx sessionctx.Context, updateStmt *ast.UpdateStmt) Plan {
+	// The RETURNING clause is evaluated by the general update plan.
+	if updateStmt.Returning != nil {
+		return nil
+	}
 	// avoid using the point_get when assignment_list contains the subquery in the UPDATE.
 	for _, list := range updateStmt.List {
 		if _, ok := list.Expr.(*ast.SubqueryExpr); ok {
@@ -1447,7 +1459,7 @@ func buildOrderedList(ctx sessionctx.Context, plan Plan, list []*ast.Assignment,
 }
 
 func tryDeletePointPlan(ctx sessionctx.Context, delStmt *ast.DeleteStmt) Plan {
-	if delStmt.IsMultiTable {
+	if delStmt.IsMultiTable || delStmt.Returning != nil {
 		return nil
 	}
 	selStmt := &ast.SelectStmt{
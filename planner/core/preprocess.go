@@ -728,9 +728,11 @@ func (p *preprocessor) checkAdminCheckTableGrammar(stmt *ast.AdminStmt) {
 			return
 		}
 		tempTableType := tableInfo.Meta().TempTableType
-		if (stmt.Tp == ast.AdminCheckTable || stmt.Tp == ast.AdminChecksumTable || stmt.Tp == ast.AdminCheckIndex) && tempTableType != model.TempTableNone {
+		if (stmt.Tp == ast.AdminCheckTable || stmt.Tp == ast.AdminChecksumTable || stmt.Tp == ast.AdminCheckIndex || stmt.Tp == ast.AdminReclaimTable) && tempTableType != model.TempTableNone {
 			if stmt.Tp == ast.AdminChecksumTable {
 				p.err = ErrOptOnTemporaryTable.GenWithStackByArgs("admin checksum table")
+			} else if stmt.Tp == ast.AdminReclaimTable {
+				p.err = ErrOptOnTemporaryTable.GenWithStackByArgs("admin reclaim table")
 			} else if stmt.Tp == ast.AdminCheckTable {
 				p.err = ErrOptOnTemporaryTable.GenWithStackByArgs("admin check table")
 			} else {
@@ -1666,7 +1668,7 @@ func (p *preprocessor) handleAsOfAndReadTS(node *ast.AsOfClause) {
 		// for stale read
 		// It means we meet following case:
 		// select statement with as of timestamp
-		ts, p.err = calculateTsExpr(p.ctx, node)
+		ts, p.err = CalculateTsExpr(p.ctx, node)
 		if p.err != nil {
 			return
 		}
@@ -1676,7 +1678,7 @@ func (p *preprocessor) handleAsOfAndReadTS(node *ast.AsOfClause) {
 		}
 		if !p.initedLastSnapshotTS {
 			p.SnapshotTSEvaluator = func(ctx sessionctx.Context) (uint64, error) {
-				return calculateTsExpr(ctx, node)
+				return CalculateTsExpr(ctx, node)
 			}
 			p.LastSnapshotTS = ts
 			p.IsStaleness = true
@@ -95,6 +95,24 @@ func (p *PhysicalHashJoin) ResolveIndices() (err error) {
 		p.RightJoinKeys[i] = rArg.(*expression.Column)
 		p.EqualConditions[i] = expression.NewFunctionInternal(fun.GetCtx(), fun.FuncName.L, fun.GetType(), lArg, rArg).(*expression.ScalarFunction)
 	}
+	// The null-aware equal conditions are evaluated against the joined row, while their arguments are the join keys.
+	for i, fun := range p.NAEqualConditions {
+		lArg, err := fun.GetArgs()[0].ResolveIndices(lSchema)
+		if err != nil {
+			return err
+		}
+		p.LeftJoinKeys[len(p.EqualConditions)+i] = lArg.(*expression.Column)
+		rArg, err := fun.GetArgs()[1].ResolveIndices(rSchema)
+		if err != nil {
+			return err
+		}
+		p.RightJoinKeys[len(p.EqualConditions)+i] = rArg.(*expression.Column)
+		newFun, err := fun.ResolveIndices(expression.MergeSchema(lSchema, rSchema))
+		if err != nil {
+			return err
+		}
+		p.NAEqualConditions[i] = newFun.(*expression.ScalarFunction)
+	}
 	for i, expr := range p.LeftConditions {
 		p.LeftConditions[i], err = expr.ResolveIndices(lSchema)
 		if err != nil {
@@ -364,7 +364,12 @@ func (p *LogicalMemTable) PruneColumns(parentUsedCols []*expression.Column, opt
 		infoschema.TableDeadlocks,
 		infoschema.ClusterTableDeadlocks:
 	default:
-		return nil
+		if extractor, ok := p.Extractor.(*ExternalTableExtractor); ok {
+			// The extracted predicates are evaluated on the columns of the file.
+			parentUsedCols = expression.ExtractColumnsFromExpressions(parentUsedCols, extractor.Filters, nil)
+		} else if !p.TableInfo.IsForeignTable() {
+			return nil
+		}
 	}
 	prunedColumns := make([]*expression.Column, 0)
 	used := expression.GetUsedList(parentUsedCols, p.schema)
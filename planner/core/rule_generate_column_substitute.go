@@ -19,8 +19,10 @@ import (
 
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/planner/util/fixcontrol"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/types"
 )
 
@@ -185,3 +187,54 @@ func (gc *gcSubstituter) substitute(ctx context.Context, lp LogicalPlan, exprToC
 func (*gcSubstituter) name() string {
 	return "generate_column_substitute"
 }
+
+// substituteVirtualColumnsForStats substitutes the expressions of the analyzed virtual generated columns in the
+// conditions with the columns, e.g. `lower(a) = 'x'` is substituted with `_V$_idx_0 = 'x'` if the hidden column of
+// the expression index on `lower(a)` is analyzed, so the conditions can be estimated by the histograms of the columns
+// even if the index is not a possible access path. The conditions are cloned before being substituted, and the coll
+// is cloned if the stats of the columns need to be added to it. It only works with the fix 783 on.
+func (ds *DataSource) substituteVirtualColumnsForStats(conds []expression.Expression, coll *statistics.HistColl) ([]expression.Expression, *statistics.HistColl) {
+	if len(conds) == 0 || ds.statisticTable == nil || ds.statisticTable.Pseudo ||
+		!fixcontrol.GetBoolWithDefault(ds.ctx.GetSessionVars().OptimizerFixControl, fixcontrol.Fix783, false) {
+		return conds, coll
+	}
+	exprToColumn := make(ExprColumnMap)
+	var cols map[int64]*statistics.Column
+	for _, col := range ds.TblCols {
+		if col.VirtualExpr == nil || !col.GetType().Equal(col.VirtualExpr.GetType()) {
+			continue
+		}
+		colStats, ok := ds.statisticTable.Columns[col.ID]
+		if !ok {
+			continue
+		}
+		exprToColumn[col.VirtualExpr] = col
+		if _, ok := coll.Columns[col.UniqueID]; ok {
+			continue
+		}
+		if cols == nil {
+			cols = make(map[int64]*statistics.Column, len(coll.Columns)+1)
+			for id, c := range coll.Columns {
+				cols[id] = c
+			}
+		}
+		cols[col.UniqueID] = colStats
+	}
+	if len(exprToColumn) == 0 {
+		return conds, coll
+	}
+	sc := ds.ctx.GetSessionVars().StmtCtx
+	schema := expression.NewSchema(ds.TblCols...)
+	newConds := make([]expression.Expression, 0, len(conds))
+	for _, cond := range conds {
+		cond = cond.Clone()
+		substituteExpression(cond, sc, ds.ctx, exprToColumn, schema)
+		newConds = append(newConds, cond)
+	}
+	if cols != nil {
+		newColl := *coll
+		newColl.Columns = cols
+		coll = &newColl
+	}
+	return newConds, coll
+}
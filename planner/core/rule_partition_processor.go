@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/planner/util/fixcontrol"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
@@ -1029,6 +1030,9 @@ func partitionRangeForOrExpr(sctx sessionctx.Context, expr1, expr2 expression.Ex
 
 func partitionRangeColumnForInExpr(sctx sessionctx.Context, args []expression.Expression,
 	pruner *rangeColumnsPruner) partitionRangeOR {
+	if fn, ok := args[0].(*expression.ScalarFunction); ok && pruner.isMonotoneFnOfPartCol(sctx, fn) {
+		return partitionRangeColumnForInFnExpr(sctx, fn, args[1:], pruner)
+	}
 	col, ok := args[0].(*expression.Column)
 	if !ok || col.ID != pruner.partCol.ID {
 		return pruner.fullRange()
@@ -1064,6 +1068,33 @@ func partitionRangeColumnForInExpr(sctx sessionctx.Context, args []expression.Ex
 	return result.simplify()
 }
 
+// partitionRangeColumnForInFnExpr distributes 'fn(col) in (const list)' to 'fn(col) = const'
+// and prunes them one by one, the fn should be monotone increasing.
+func partitionRangeColumnForInFnExpr(sctx sessionctx.Context, fn *expression.ScalarFunction, list []expression.Expression,
+	pruner *rangeColumnsPruner) partitionRangeOR {
+	var result partitionRangeOR
+	for _, arg := range list {
+		constExpr, ok := arg.(*expression.Constant)
+		if !ok {
+			return pruner.fullRange()
+		}
+		switch constExpr.Value.Kind() {
+		case types.KindInt64, types.KindUint64:
+		case types.KindNull:
+			// fn(col) = null is never true.
+			continue
+		default:
+			return pruner.fullRange()
+		}
+		start, end, ok := pruner.partitionRangeForMonotoneFn(sctx, ast.EQ, fn, constExpr)
+		if !ok {
+			return pruner.fullRange()
+		}
+		result = append(result, partitionRange{start, end})
+	}
+	return result.simplify()
+}
+
 func partitionRangeForInExpr(sctx sessionctx.Context, args []expression.Expression,
 	pruner *rangePruner) partitionRangeOR {
 	col, ok := args[0].(*expression.Column)
@@ -1562,6 +1593,16 @@ func (p *rangeColumnsPruner) partitionRangeForExpr(sctx sessionctx.Context, expr
 		}
 	}
 	if col == nil || con == nil {
+		// Try 'fn(col) op const' with a monotone increasing fn, like 'to_days(col) < const'.
+		if fn, ok := op.GetArgs()[0].(*expression.ScalarFunction); ok && p.isMonotoneFnOfPartCol(sctx, fn) {
+			if con, ok := op.GetArgs()[1].(*expression.Constant); ok {
+				return p.partitionRangeForMonotoneFn(sctx, op.FuncName.L, fn, con)
+			}
+		} else if fn, ok := op.GetArgs()[1].(*expression.ScalarFunction); ok && p.isMonotoneFnOfPartCol(sctx, fn) {
+			if con, ok := op.GetArgs()[0].(*expression.Constant); ok {
+				return p.partitionRangeForMonotoneFn(sctx, opposite(op.FuncName.L), fn, con)
+			}
+		}
 		return 0, len(p.data), false
 	}
 
@@ -1569,6 +1610,110 @@ func (p *rangeColumnsPruner) partitionRangeForExpr(sctx sessionctx.Context, expr
 	return start, end, true
 }
 
+// isMonotoneFnOfPartCol checks whether fn is a monotone increasing function of the partition column,
+// in the form of fn(col) or fn(col, const...) returning a number. Only the partition columns of the
+// integer and time types are supported, since the order of the strings doesn't match the one of fn.
+// The pruning can be turned off by the fix control 756.
+func (p *rangeColumnsPruner) isMonotoneFnOfPartCol(sctx sessionctx.Context, fn *expression.ScalarFunction) bool {
+	if !fixcontrol.GetBoolWithDefault(sctx.GetSessionVars().OptimizerFixControl, fixcontrol.Fix756, true) {
+		return false
+	}
+	if getMonotoneMode(fn.FuncName.L) == monotoneModeInvalid {
+		return false
+	}
+	if !mysql.IsIntegerType(p.partCol.RetType.Tp) && !types.IsTypeTime(p.partCol.RetType.Tp) {
+		return false
+	}
+	switch fn.GetType().EvalType() {
+	case types.ETInt, types.ETDecimal, types.ETReal:
+	default:
+		return false
+	}
+	args := fn.GetArgs()
+	if len(args) == 0 {
+		return false
+	}
+	if col, ok := args[0].(*expression.Column); !ok || col.ID != p.partCol.ID {
+		return false
+	}
+	for _, arg := range args[1:] {
+		if _, ok := arg.(*expression.Constant); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// partitionRangeForMonotoneFn prunes the partitions by 'fn(col) op con' where fn is monotone increasing.
+// The partition i holds the values in [lessThan[i-1], lessThan[i]), so fn(col) of its rows lies in
+// [fn(lessThan[i-1]), fn(lessThan[i])], and the partitions whose range can't satisfy the condition are pruned.
+func (p *rangeColumnsPruner) partitionRangeForMonotoneFn(sctx sessionctx.Context, op string, fn *expression.ScalarFunction, con *expression.Constant) (int, int, bool) {
+	var err error
+	// satisfy checks 'fn(lessThan[ith]) cmp con', the MAXVALUE bound satisfies any condition.
+	satisfy := func(ith int, cmp string) bool {
+		if err != nil {
+			return false
+		}
+		if ith == len(p.data)-1 && p.maxvalue {
+			return true
+		}
+		bound, ok := p.data[ith].(*expression.Constant)
+		if !ok {
+			err = errors.New("the partition bound is not a constant")
+			return false
+		}
+		var d types.Datum
+		d, err = bound.Value.ConvertTo(sctx.GetSessionVars().StmtCtx, p.partCol.RetType)
+		if err != nil {
+			return false
+		}
+		// Don't modify fn since it's still used by the filter.
+		fnBound := replaceColumnWithConst(fn.Clone().(*expression.ScalarFunction), &expression.Constant{Value: d, RetType: p.partCol.RetType})
+		var expr expression.Expression
+		expr, err = expression.NewFunctionBase(sctx, cmp, types.NewFieldType(mysql.TypeLonglong), fnBound, con)
+		if err != nil {
+			return false
+		}
+		val, isNull, err1 := expr.EvalInt(sctx, chunk.Row{})
+		if err1 == nil && isNull {
+			err1 = errors.New("the partition bound is evaluated to null")
+		}
+		err = err1
+		return err == nil && val > 0
+	}
+
+	// The values are less than the upper bound, so fn(col) < fn(upper bound) if fn is strict monotone.
+	upperOp := ast.GE
+	if getMonotoneMode(fn.FuncName.L) == monotoneModeStrict {
+		upperOp = ast.GT
+	}
+	// The upper bound of the last partition is not a lower bound of any partition.
+	length := len(p.data)
+	start, end := 0, length
+	switch op {
+	case ast.LT, ast.LE:
+		// The partition is kept if fn(lower bound) op con.
+		end = 1 + sort.Search(length-1, func(i int) bool { return !satisfy(i, op) })
+	case ast.GT:
+		// The partition is kept if fn(upper bound) > con.
+		start = sort.Search(length, func(i int) bool { return satisfy(i, ast.GT) })
+	case ast.GE:
+		start = sort.Search(length, func(i int) bool { return satisfy(i, upperOp) })
+	case ast.EQ:
+		start = sort.Search(length, func(i int) bool { return satisfy(i, upperOp) })
+		end = 1 + sort.Search(length-1, func(i int) bool { return satisfy(i, ast.GT) })
+	default:
+		return 0, length, false
+	}
+	if err != nil {
+		return 0, length, false
+	}
+	if start > end {
+		start = end
+	}
+	return start, end, true
+}
+
 func (p *rangeColumnsPruner) pruneUseBinarySearch(sctx sessionctx.Context, op string, data *expression.Constant, f *expression.ScalarFunction) (start int, end int) {
 	var err error
 	var isNull bool
@@ -0,0 +1,268 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/table"
+)
+
+// partitionWiseJoinSolver rewrites the join of two tables partitioned identically on the join key
+// into a union of the joins of the partition pairs.
+//
+// create table t1 (a int, b int) partition by hash(a) partitions 2
+// create table t2 (a int, b int) partition by hash(a) partitions 2
+//
+// select * from t1 join t2 on t1.a = t2.a is equal to
+// select * from (union all
+//      select * from t1 partition (p0) join t2 partition (p0) on t1.a = t2.a
+//      select * from t1 partition (p1) join t2 partition (p1) on t1.a = t2.a)
+//
+// The rows with the same join key are always in the partitions of the same position, so each join only
+// builds the hash table of one partition, and the joins of the partition pairs can run in parallel.
+// It's done after partitionProcessor, because it works on the union of the partitions built by it,
+// which means it only takes effect under the static partition prune mode.
+type partitionWiseJoinSolver struct{}
+
+func (s *partitionWiseJoinSolver) optimize(ctx context.Context, lp LogicalPlan, opt *logicalOptimizeOp) (LogicalPlan, error) {
+	return s.rewrite(lp, opt), nil
+}
+
+func (s *partitionWiseJoinSolver) rewrite(lp LogicalPlan, opt *logicalOptimizeOp) LogicalPlan {
+	children := lp.Children()
+	for i, child := range children {
+		children[i] = s.rewrite(child, opt)
+	}
+	if join, ok := lp.(*LogicalJoin); ok {
+		if p := s.tryToSplitJoin(join, opt); p != nil {
+			return p
+		}
+	}
+	return lp
+}
+
+// partitionedSide is a child of the join which reads the partitions of a partitioned table.
+type partitionedSide struct {
+	pi *model.PartitionInfo
+	// partitions maps the position of a partition definition to the DataSource which reads it.
+	partitions map[int]LogicalPlan
+	// col is the partitioning column.
+	col *expression.Column
+}
+
+// buildPartitionedSide returns nil if the child isn't a partitioned table which is partitioned by a column directly.
+func (s *partitionWiseJoinSolver) buildPartitionedSide(child LogicalPlan) *partitionedSide {
+	var dataSources []*DataSource
+	switch x := child.(type) {
+	case *DataSource:
+		dataSources = append(dataSources, x)
+	case *LogicalPartitionUnionAll:
+		for _, c := range x.Children() {
+			ds, ok := c.(*DataSource)
+			if !ok {
+				return nil
+			}
+			dataSources = append(dataSources, ds)
+		}
+	default:
+		return nil
+	}
+	ds := dataSources[0]
+	pi := ds.tableInfo.GetPartitionInfo()
+	if pi == nil || (pi.Type != model.PartitionTypeHash && pi.Type != model.PartitionTypeRange) || len(pi.Columns) > 1 {
+		return nil
+	}
+	pt, ok := ds.table.(table.PartitionedTable)
+	if !ok {
+		return nil
+	}
+	if len(pi.Columns) == 0 {
+		pe, err := ds.table.(partitionTable).PartitionExpr()
+		if err != nil {
+			return nil
+		}
+		if _, ok := pe.Expr.(*expression.Column); !ok {
+			return nil
+		}
+	}
+	colNames := pt.GetPartitionColumnNames()
+	if len(colNames) != 1 {
+		return nil
+	}
+	side := &partitionedSide{pi: pi, partitions: make(map[int]LogicalPlan, len(dataSources))}
+	for i, col := range ds.Columns {
+		if col.Name.L == colNames[0].L {
+			side.col = ds.schema.Columns[i]
+			break
+		}
+	}
+	if side.col == nil {
+		return nil
+	}
+	pos := make(map[int64]int, len(pi.Definitions))
+	for i, def := range pi.Definitions {
+		pos[def.ID] = i
+	}
+	for _, ds := range dataSources {
+		i, ok := pos[ds.physicalTableID]
+		if !ok || !ds.isPartition {
+			return nil
+		}
+		side.partitions[i] = ds
+	}
+	return side
+}
+
+// isCoPartitioned checks whether the rows with the same value of the partitioning columns are always in the
+// partitions of the same position.
+func isCoPartitioned(l, r *partitionedSide) bool {
+	if l.pi.Type != r.pi.Type || len(l.pi.Columns) != len(r.pi.Columns) || len(l.pi.Definitions) != len(r.pi.Definitions) {
+		return false
+	}
+	lTp, rTp := l.col.GetType(), r.col.GetType()
+	if lTp.Tp != rTp.Tp || mysql.HasUnsignedFlag(lTp.Flag) != mysql.HasUnsignedFlag(rTp.Flag) || lTp.Collate != rTp.Collate {
+		return false
+	}
+	if l.pi.Type == model.PartitionTypeRange {
+		for i := range l.pi.Definitions {
+			lLess, rLess := l.pi.Definitions[i].LessThan, r.pi.Definitions[i].LessThan
+			if len(lLess) != len(rLess) {
+				return false
+			}
+			for j := range lLess {
+				if lLess[j] != rLess[j] {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+func (s *partitionWiseJoinSolver) tryToSplitJoin(join *LogicalJoin, opt *logicalOptimizeOp) LogicalPlan {
+	switch join.JoinType {
+	case InnerJoin, LeftOuterJoin, RightOuterJoin, SemiJoin, AntiSemiJoin, LeftOuterSemiJoin, AntiLeftOuterSemiJoin:
+	default:
+		return nil
+	}
+	l := s.buildPartitionedSide(join.children[0])
+	if l == nil {
+		return nil
+	}
+	r := s.buildPartitionedSide(join.children[1])
+	if r == nil || !isCoPartitioned(l, r) {
+		return nil
+	}
+	onPartitionCols := false
+	for _, eq := range join.EqualConditions {
+		args := eq.GetArgs()
+		if args[0].(*expression.Column).Equal(nil, l.col) && args[1].(*expression.Column).Equal(nil, r.col) {
+			onPartitionCols = true
+			break
+		}
+	}
+	if !onPartitionCols {
+		return nil
+	}
+
+	keepLeft := join.JoinType != InnerJoin && join.JoinType != SemiJoin && join.JoinType != RightOuterJoin
+	keepRight := join.JoinType == RightOuterJoin
+	children := make([]LogicalPlan, 0, len(l.pi.Definitions))
+	for i := range l.pi.Definitions {
+		lChild, lOK := l.partitions[i]
+		rChild, rOK := r.partitions[i]
+		if !lOK && !rOK || !lOK && !keepRight || !rOK && !keepLeft {
+			continue
+		}
+		if !lOK {
+			lChild = s.buildEmptyPartition(join, join.children[0])
+		}
+		if !rOK {
+			rChild = s.buildEmptyPartition(join, join.children[1])
+		}
+		children = append(children, s.buildPartitionJoin(join, lChild, rChild))
+	}
+
+	var p LogicalPlan
+	switch len(children) {
+	case 0:
+		dual := LogicalTableDual{RowCount: 0}.Init(join.ctx, join.blockOffset)
+		dual.SetSchema(join.Schema())
+		dual.SetOutputNames(join.OutputNames())
+		p = dual
+	case 1:
+		p = children[0]
+	default:
+		union := LogicalUnionAll{}.Init(join.ctx, join.blockOffset)
+		union.SetChildren(children...)
+		union.SetSchema(join.Schema().Clone())
+		union.SetOutputNames(join.OutputNames())
+		p = union
+	}
+	appendPartitionWiseJoinTraceStep(join, p, children, opt)
+	return p
+}
+
+func (s *partitionWiseJoinSolver) buildEmptyPartition(join *LogicalJoin, child LogicalPlan) LogicalPlan {
+	dual := LogicalTableDual{RowCount: 0}.Init(join.ctx, join.blockOffset)
+	dual.SetSchema(child.Schema().Clone())
+	dual.SetOutputNames(child.OutputNames())
+	return dual
+}
+
+func (s *partitionWiseJoinSolver) buildPartitionJoin(join *LogicalJoin, lChild, rChild LogicalPlan) LogicalPlan {
+	newJoin := join.Shallow()
+	newJoin.SetChildren(lChild, rChild)
+	newJoin.SetSchema(join.Schema().Clone())
+	newJoin.EqualConditions = make([]*expression.ScalarFunction, 0, len(join.EqualConditions))
+	for _, eq := range join.EqualConditions {
+		newJoin.EqualConditions = append(newJoin.EqualConditions, eq.Clone().(*expression.ScalarFunction))
+	}
+	newJoin.LeftConditions = cloneExprs(join.LeftConditions)
+	newJoin.RightConditions = cloneExprs(join.RightConditions)
+	newJoin.OtherConditions = cloneExprs(join.OtherConditions)
+	return newJoin
+}
+
+func (*partitionWiseJoinSolver) name() string {
+	return "partition_wise_join"
+}
+
+func appendPartitionWiseJoinTraceStep(join *LogicalJoin, p LogicalPlan, children []LogicalPlan, opt *logicalOptimizeOp) {
+	action := func() string {
+		buffer := bytes.NewBufferString(fmt.Sprintf("%v_%v becomes %v_%v", join.TP(), join.ID(), p.TP(), p.ID()))
+		if len(children) > 1 {
+			buffer.WriteString(" with children[")
+			for i, child := range children {
+				if i > 0 {
+					buffer.WriteString(",")
+				}
+				buffer.WriteString(fmt.Sprintf("%v_%v", child.TP(), child.ID()))
+			}
+			buffer.WriteString("]")
+		}
+		return buffer.String()
+	}
+	reason := func() string {
+		return fmt.Sprintf("the children of %v_%v are partitioned identically on the join key", join.TP(), join.ID())
+	}
+	opt.appendStepToCurrent(join.ID(), join.TP(), reason, action)
+}
@@ -247,7 +247,9 @@ func (ds *DataSource) initStats(colGroups [][]*expression.Column) {
 }
 
 func (ds *DataSource) deriveStatsByFilter(conds expression.CNFExprs, filledPaths []*util.AccessPath) *property.StatsInfo {
-	selectivity, nodes, err := ds.tableStats.HistColl.Selectivity(ds.ctx, conds, filledPaths)
+	// The conditions on the expressions of the analyzed virtual columns are estimated by the stats of the columns.
+	exprs, coll := ds.substituteVirtualColumnsForStats(conds, ds.tableStats.HistColl)
+	selectivity, nodes, err := coll.Selectivity(ds.ctx, exprs, filledPaths)
 	if err != nil {
 		logutil.BgLogger().Debug("something wrong happened, use the default selectivity", zap.Error(err))
 		selectivity = SelectionFactor
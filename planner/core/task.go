@@ -29,10 +29,12 @@ import (
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/planner/property"
 	"github.com/pingcap/tidb/planner/util"
+	"github.com/pingcap/tidb/planner/util/fixcontrol"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/clustercap"
 	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/paging"
@@ -94,6 +96,42 @@ type copTask struct {
 	// expectCnt is the expected row count of upper task, 0 for unlimited.
 	// It's used for deciding whether using paging distsql.
 	expectCnt uint64
+
+	// lookupStats is used to estimate the seeks of the table side of the double read, it's nil if the stats of the
+	// table are unknown.
+	lookupStats *doubleReadStats
+}
+
+// estimatedRegionSize is the default size of the regions split by TiKV, it's used to estimate the region count of
+// the tables.
+const estimatedRegionSize = 96 * 1024 * 1024
+
+// doubleReadStats is the stats of the table used to estimate the cost of looking up the rows by the handles read
+// from the index.
+type doubleReadStats struct {
+	// handleCorr is the absolute correlation between the handles and the index values, the handles read from the
+	// index are clustered in a few regions if it's close to 1, or scattered across the table if it's close to 0.
+	handleCorr float64
+	// tableRows is the row count of the whole table.
+	tableRows float64
+}
+
+// lookupSeeks estimates the seeks of looking up the rows by the handles. The handles are sent in the batches of
+// batchSize, and each batch is split into the tasks by the regions it touches. Every task seeks once, and the rows
+// whose handles are not adjacent to the previous ones seek again.
+func (s *doubleReadStats) lookupSeeks(lookupRows, rowSize float64, batchSize int) float64 {
+	if lookupRows <= 0 || s.tableRows <= 0 {
+		return 0
+	}
+	regions := math.Max(1, math.Ceil(s.tableRows*rowSize/estimatedRegionSize))
+	batch := math.Min(math.Max(1, float64(batchSize)), lookupRows)
+	batches := math.Ceil(lookupRows / batch)
+	// The clustered handles of a batch are in the consecutive regions, while the scattered ones are spread over
+	// the regions randomly.
+	clustered := math.Min(regions, math.Ceil(regions*batch/s.tableRows)+1)
+	scattered := regions * (1 - math.Pow(1-1/regions, batch))
+	tasks := batches * (s.handleCorr*clustered + (1-s.handleCorr)*scattered)
+	return tasks + (1-s.handleCorr)*lookupRows
 }
 
 func (t *copTask) invalid() bool {
@@ -182,6 +220,10 @@ func (t *copTask) finishIndexPlan() {
 	}
 	rowSize := t.tblColHists.GetIndexAvgRowSize(t.indexPlan.SCtx(), t.tblCols, p.(*PhysicalIndexScan).Index.Unique)
 	t.cst += cnt * rowSize * sessVars.GetScanFactor(tableInfo)
+	// The random seeks of the lookups dominate the cost if the handles are scattered across the table.
+	if t.lookupStats != nil && fixcontrol.GetBoolWithDefault(sessVars.OptimizerFixControl, fixcontrol.Fix784, false) {
+		t.cst += t.lookupStats.lookupSeeks(cnt, rowSize, sessVars.IndexLookupSize) * sessVars.GetSeekFactor(tableInfo)
+	}
 }
 
 func (t *copTask) getStoreType() kv.StoreType {
@@ -2423,12 +2465,20 @@ func (t *mppTask) enforceExchanger(prop *property.PhysicalProperty) *mppTask {
 }
 
 func (t *mppTask) enforceExchangerImpl(prop *property.PhysicalProperty) *mppTask {
-	if collate.NewCollationEnabled() && !t.p.SCtx().GetSessionVars().HashExchangeWithNewCollation && prop.MPPPartitionTp == property.HashType {
+	if collate.NewCollationEnabled() && prop.MPPPartitionTp == property.HashType {
 		for _, col := range prop.MPPPartitionCols {
-			if types.IsString(col.Col.RetType.Tp) {
+			if !types.IsString(col.Col.RetType.Tp) {
+				continue
+			}
+			if !t.p.SCtx().GetSessionVars().HashExchangeWithNewCollation {
 				t.p.SCtx().GetSessionVars().RaiseWarningWhenMPPEnforced("MPP mode may be blocked because when `new_collation_enabled` is true, HashJoin or HashAgg with string key is not supported now.")
 				return &mppTask{cst: math.MaxFloat64}
 			}
+			// The TiFlash nodes not upgraded yet don't hash the strings by the new collation.
+			if err := clustercap.Check(clustercap.FeatureHashExchangeWithNewCollation); err != nil {
+				t.p.SCtx().GetSessionVars().RaiseWarningWhenMPPEnforced("MPP mode may be blocked because " + err.Error() + ".")
+				return &mppTask{cst: math.MaxFloat64}
+			}
 		}
 	}
 	ctx := t.p.SCtx()
@@ -198,6 +198,9 @@ func Optimize(ctx context.Context, sctx sessionctx.Context, node ast.Node, is in
 			if err := setFoundInBinding(sctx, true, chosenBinding.BindSQL); err != nil {
 				logutil.BgLogger().Warn("set tidb_found_in_binding failed", zap.Error(err))
 			}
+			if scope == metrics.ScopeGlobal {
+				domain.GetDomain(sctx).BindHandle().RecordBindingHit(chosenBinding.BindSQL)
+			}
 			if sessVars.StmtCtx.InVerboseExplain {
 				sessVars.StmtCtx.AppendNote(errors.Errorf("Using the bindSQL: %v", chosenBinding.BindSQL))
 			}
@@ -547,7 +550,7 @@ func handleStmtHints(hints []*ast.TableOptimizerHint) (stmtHints stmtctx.StmtHin
 	}
 	hintOffs := make(map[string]int, len(hints))
 	var forceNthPlan *ast.TableOptimizerHint
-	var memoryQuotaHintCnt, useToJAHintCnt, useCascadesHintCnt, noIndexMergeHintCnt, readReplicaHintCnt, maxExecutionTimeCnt, forceNthPlanCnt int
+	var memoryQuotaHintCnt, useToJAHintCnt, useCascadesHintCnt, noIndexMergeHintCnt, readReplicaHintCnt, maxExecutionTimeCnt, forceNthPlanCnt, queryTypeHintCnt int
 	setVars := make(map[string]string)
 	setVarsOffs := make([]int, 0, len(hints))
 	for i, hint := range hints {
@@ -570,6 +573,9 @@ func handleStmtHints(hints []*ast.TableOptimizerHint) (stmtHints stmtctx.StmtHin
 		case "max_execution_time":
 			hintOffs[hint.HintName.L] = i
 			maxExecutionTimeCnt++
+		case "query_type":
+			hintOffs[hint.HintName.L] = i
+			queryTypeHintCnt++
 		case "nth_plan":
 			forceNthPlanCnt++
 			forceNthPlan = hint
@@ -666,6 +672,16 @@ func handleStmtHints(hints []*ast.TableOptimizerHint) (stmtHints stmtctx.StmtHin
 		stmtHints.HasMaxExecutionTime = true
 		stmtHints.MaxExecutionTime = maxExecutionTime.HintData.(uint64)
 	}
+	// Handle QUERY_TYPE
+	if queryTypeHintCnt != 0 {
+		queryTypeHint := hints[hintOffs["query_type"]]
+		if queryTypeHintCnt > 1 {
+			warn := errors.Errorf("QUERY_TYPE() is defined more than once, only the last definition takes effect: QUERY_TYPE(%v)", queryTypeHint.HintData.(model.CIStr).O)
+			warns = append(warns, warn)
+		}
+		stmtHints.HasQueryTypeHint = true
+		stmtHints.QueryType = queryTypeHint.HintData.(model.CIStr).L
+	}
 	// Handle NTH_PLAN
 	if forceNthPlanCnt != 0 {
 		if forceNthPlanCnt > 1 {
@@ -0,0 +1,86 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fixcontrol controls the behavior changes of the optimizer one by one. Every fix which may change
+// the plans gets a number, and the planner consults tidb_opt_fix_control, like "756:OFF", at the point of the
+// fix, so the users meeting a plan regression can opt out of the fix without downgrading.
+package fixcontrol
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/errors"
+)
+
+const (
+	// Fix756 controls whether the RANGE COLUMNS partitions are pruned by the conditions on the monotone
+	// functions of the partition column, like `year(col) < const`.
+	Fix756 uint64 = 756
+	// Fix738 controls whether the ranges of LIKE on the string columns under non-binary collations are built on
+	// the sort keys of the collations, instead of scanning the whole index. It's off by default.
+	Fix738 uint64 = 738
+	// Fix783 controls whether the version 2 ANALYZE builds the stats of the virtual generated columns, and whether
+	// the expressions of the analyzed virtual columns in the filters are estimated by the stats of the columns.
+	// It's off by default.
+	Fix783 uint64 = 783
+	// Fix784 controls whether the version 2 ANALYZE collects the correlation between the index values and the
+	// handles, and whether the cost of IndexLookUp charges the seeks of looking up the rows by the handles.
+	// It's off by default.
+	Fix784 uint64 = 784
+)
+
+// ParseToMap parses the value of tidb_opt_fix_control, which is a comma separated list of "fix:value",
+// into a map from the fixes to the values. The later value wins if a fix is set more than once.
+func ParseToMap(s string) (map[uint64]string, error) {
+	result := make(map[uint64]string)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		fixAndValue := strings.SplitN(item, ":", 2)
+		if len(fixAndValue) != 2 {
+			return nil, errors.Errorf("invalid fix control %q, it should be in the form of \"fix:value\"", item)
+		}
+		fix, err := strconv.ParseUint(strings.TrimSpace(fixAndValue[0]), 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid fix %q, it should be a number", fixAndValue[0])
+		}
+		result[fix] = strings.TrimSpace(fixAndValue[1])
+	}
+	return result, nil
+}
+
+// GetStr returns the value of the fix, ok is false if the fix is not set.
+func GetStr(fixControlMap map[uint64]string, fix uint64) (value string, ok bool) {
+	value, ok = fixControlMap[fix]
+	return value, ok
+}
+
+// GetBoolWithDefault returns the value of the fix as a bool, the default value is returned if the fix is
+// not set or the value isn't a bool.
+func GetBoolWithDefault(fixControlMap map[uint64]string, fix uint64, defaultVal bool) bool {
+	value, ok := GetStr(fixControlMap, fix)
+	if !ok {
+		return defaultVal
+	}
+	switch strings.ToUpper(value) {
+	case "ON", "TRUE", "1":
+		return true
+	case "OFF", "FALSE", "0":
+		return false
+	}
+	return defaultVal
+}
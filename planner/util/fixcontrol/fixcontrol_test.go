@@ -0,0 +1,51 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixcontrol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseToMap(t *testing.T) {
+	m, err := ParseToMap("")
+	require.NoError(t, err)
+	require.Empty(t, m)
+
+	m, err = ParseToMap(" 756:OFF, 1234 : on ,,756:1")
+	require.NoError(t, err)
+	require.Equal(t, map[uint64]string{756: "1", 1234: "on"}, m)
+
+	for _, s := range []string{"756", "abc:ON", "-1:OFF", ":ON"} {
+		_, err = ParseToMap(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestGetBoolWithDefault(t *testing.T) {
+	m := map[uint64]string{1: "ON", 2: "off", 3: "0", 4: "true", 5: "unknown"}
+	require.True(t, GetBoolWithDefault(m, 1, false))
+	require.False(t, GetBoolWithDefault(m, 2, true))
+	require.False(t, GetBoolWithDefault(m, 3, true))
+	require.True(t, GetBoolWithDefault(m, 4, false))
+	require.True(t, GetBoolWithDefault(m, 5, true))
+	require.False(t, GetBoolWithDefault(m, 6, false))
+	require.True(t, GetBoolWithDefault(nil, 1, true))
+
+	v, ok := GetStr(m, 5)
+	require.True(t, ok)
+	require.Equal(t, "unknown", v)
+}
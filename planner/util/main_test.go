@@ -23,5 +23,8 @@ import (
 
 func TestMain(m *testing.M) {
 	testbridge.SetupForCommonTest()
-	goleak.VerifyTestMain(m)
+	opts := []goleak.Option{
+		goleak.IgnoreTopFunction("go.opencensus.io/stats/view.(*worker).start"),
+	}
+	goleak.VerifyTestMain(m, opts...)
 }
@@ -63,6 +63,57 @@ type UserPrivileges struct {
 	user string
 	host string
 	*Handle
+	verificationCache verificationCache
+}
+
+// verificationCacheCapacity is the max number of the privilege decisions cached by a session.
+const verificationCacheCapacity = 1024
+
+type verificationKey struct {
+	db, table, column string
+	priv              mysql.PrivilegeType
+}
+
+// verificationCache caches the results of MySQLPrivilege.RequestVerification for a session,
+// so the hot statements don't walk the privilege tables for every check. A loaded MySQLPrivilege
+// is never modified, so the decisions stay valid until the privilege watcher loads a new one,
+// or the user or the active roles of the session change.
+type verificationCache struct {
+	sync.Mutex
+	mysqlPriv   *MySQLPrivilege
+	user, host  string
+	activeRoles []*auth.RoleIdentity
+	decisions   map[verificationKey]bool
+}
+
+func (c *verificationCache) requestVerification(mysqlPriv *MySQLPrivilege, activeRoles []*auth.RoleIdentity, user, host, db, table, column string, priv mysql.PrivilegeType) bool {
+	c.Lock()
+	defer c.Unlock()
+	if c.decisions == nil || c.mysqlPriv != mysqlPriv || c.user != user || c.host != host ||
+		!equalRoles(c.activeRoles, activeRoles) || len(c.decisions) >= verificationCacheCapacity {
+		c.mysqlPriv, c.user, c.host = mysqlPriv, user, host
+		c.activeRoles = append(c.activeRoles[:0], activeRoles...)
+		c.decisions = make(map[verificationKey]bool)
+	}
+	key := verificationKey{db: db, table: table, column: column, priv: priv}
+	if decision, ok := c.decisions[key]; ok {
+		return decision
+	}
+	decision := mysqlPriv.RequestVerification(activeRoles, user, host, db, table, column, priv)
+	c.decisions[key] = decision
+	return decision
+}
+
+func equalRoles(a, b []*auth.RoleIdentity) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Username != b[i].Username || a[i].Hostname != b[i].Hostname {
+			return false
+		}
+	}
+	return true
 }
 
 // RequestDynamicVerificationWithUser implements the Manager interface.
@@ -165,7 +216,7 @@ func (p *UserPrivileges) RequestVerification(activeRoles []*auth.RoleIdentity, d
 	}
 
 	mysqlPriv := p.Handle.Get()
-	return mysqlPriv.RequestVerification(activeRoles, p.user, p.host, db, table, column, priv)
+	return p.verificationCache.requestVerification(mysqlPriv, activeRoles, p.user, p.host, db, table, column, priv)
 }
 
 // RequestVerificationWithUser implements the Manager interface.
@@ -78,6 +78,36 @@ func TestCheckDBPrivilege(t *testing.T) {
 	require.True(t, pc.RequestVerification(activeRoles, "test", "", "", mysql.UpdatePriv))
 }
 
+func TestCachedPrivilegeDecisions(t *testing.T) {
+	store, clean := newStore(t)
+	defer clean()
+	rootSe := newSession(t, store, dbName)
+	mustExec(t, rootSe, `CREATE USER 'testcache'@'localhost';`)
+	mustExec(t, rootSe, `CREATE ROLE 'r_cache'@'localhost';`)
+	mustExec(t, rootSe, `CREATE TABLE test.t(a int);`)
+	mustExec(t, rootSe, `GRANT 'r_cache'@'localhost' TO 'testcache'@'localhost';`)
+
+	se := newSession(t, store, dbName)
+	require.True(t, se.Auth(&auth.UserIdentity{Username: "testcache", Hostname: "localhost"}, nil, nil))
+	pc := privilege.GetPrivilegeManager(se)
+	noRoles := make([]*auth.RoleIdentity, 0)
+	require.False(t, pc.RequestVerification(noRoles, "test", "t", "", mysql.SelectPriv))
+	require.False(t, pc.RequestVerification(noRoles, "test", "t", "", mysql.SelectPriv))
+
+	// The cached decisions are dropped once the privileges are reloaded.
+	mustExec(t, rootSe, `GRANT SELECT ON test.t TO 'testcache'@'localhost';`)
+	require.True(t, pc.RequestVerification(noRoles, "test", "t", "", mysql.SelectPriv))
+	mustExec(t, rootSe, `REVOKE SELECT ON test.t FROM 'testcache'@'localhost';`)
+	require.False(t, pc.RequestVerification(noRoles, "test", "t", "", mysql.SelectPriv))
+
+	// The cached decisions are dropped once the active roles change.
+	mustExec(t, rootSe, `GRANT UPDATE ON test.t TO 'r_cache'@'localhost';`)
+	require.False(t, pc.RequestVerification(noRoles, "test", "t", "", mysql.UpdatePriv))
+	roles := []*auth.RoleIdentity{{Username: "r_cache", Hostname: "localhost"}}
+	require.True(t, pc.RequestVerification(roles, "test", "t", "", mysql.UpdatePriv))
+	require.False(t, pc.RequestVerification(noRoles, "test", "t", "", mysql.UpdatePriv))
+}
+
 func TestCheckPointGetDBPrivilege(t *testing.T) {
 	store, clean := newStore(t)
 	defer clean()
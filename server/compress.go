@@ -0,0 +1,241 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/parser/terror"
+)
+
+const (
+	// compressedHeaderLen is the length of the header of a compressed packet: 3 bytes of the compressed length,
+	// 1 byte of the compressed sequence and 3 bytes of the uncompressed length.
+	compressedHeaderLen = 7
+	// minCompressLength is the same as MIN_COMPRESS_LENGTH of MySQL, the shorter payloads are sent uncompressed.
+	minCompressLength = 50
+	// defaultZstdLevel is used when the client doesn't send the zstd level.
+	defaultZstdLevel = 3
+)
+
+// compressor compresses and decompresses the payload of the compressed packets.
+type compressor interface {
+	// compress appends the compressed src to dst.
+	compress(dst, src []byte) ([]byte, error)
+	// decompress decompresses src, which is n bytes after decompressed, into a new slice.
+	decompress(src []byte, n int) ([]byte, error)
+	close()
+}
+
+type zlibCompressor struct {
+	level int
+	w     *zlib.Writer
+	r     io.ReadCloser
+}
+
+func newZlibCompressor(level int) *zlibCompressor {
+	return &zlibCompressor{level: level}
+}
+
+func (c *zlibCompressor) compress(dst, src []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	if c.w == nil {
+		w, err := zlib.NewWriterLevel(buf, c.level)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		c.w = w
+	} else {
+		c.w.Reset(buf)
+	}
+	if _, err := c.w.Write(src); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := c.w.Close(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *zlibCompressor) decompress(src []byte, n int) ([]byte, error) {
+	var err error
+	if c.r == nil {
+		c.r, err = zlib.NewReader(bytes.NewReader(src))
+	} else {
+		err = c.r.(zlib.Resetter).Reset(bytes.NewReader(src), nil)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	data := make([]byte, n)
+	if _, err = io.ReadFull(c.r, data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return data, nil
+}
+
+func (c *zlibCompressor) close() {
+	if c.r != nil {
+		terror.Log(c.r.Close())
+		c.r = nil
+	}
+}
+
+// zstdCompressor holds the zstd encoder and decoder of a connection, the decoder is created on the first
+// compressed packet from the client because most of the commands are too short to be compressed.
+type zstdCompressor struct {
+	enc *zstd.Encoder
+	dec *zstd.Decoder
+}
+
+func newZstdCompressor(level int) (*zstdCompressor, error) {
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)), zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &zstdCompressor{enc: enc}, nil
+}
+
+func (c *zstdCompressor) compress(dst, src []byte) ([]byte, error) {
+	return c.enc.EncodeAll(src, dst), nil
+}
+
+func (c *zstdCompressor) decompress(src []byte, n int) ([]byte, error) {
+	if c.dec == nil {
+		dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(mysql.MaxPayloadLen)))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		c.dec = dec
+	}
+	data, err := c.dec.DecodeAll(src, make([]byte, 0, n))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return data, nil
+}
+
+// close releases the encoder and the decoder if it's created, the goroutines of the decoder are leaked otherwise.
+func (c *zstdCompressor) close() {
+	terror.Log(c.enc.Close())
+	if c.dec != nil {
+		c.dec.Close()
+		c.dec = nil
+	}
+}
+
+// compressedReadWriter reads and writes the compressed packets of the MySQL protocol, the payloads of the
+// compressed packets are the stream of the ordinary packets, so packetIO reads from and writes to it as the connection.
+type compressedReadWriter struct {
+	p *packetIO
+	compressor
+	// data is the decompressed payload which hasn't been read.
+	data []byte
+	// buf is reused to build the compressed packets.
+	buf []byte
+}
+
+func (rw *compressedReadWriter) Read(b []byte) (int, error) {
+	for len(rw.data) == 0 {
+		if err := rw.readCompressedPacket(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(b, rw.data)
+	rw.data = rw.data[n:]
+	return n, nil
+}
+
+func (rw *compressedReadWriter) readCompressedPacket() error {
+	var header [compressedHeaderLen]byte
+	if _, err := io.ReadFull(rw.p.bufReadConn, header[:]); err != nil {
+		return errors.Trace(err)
+	}
+	length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+	// The clients keep the compressed sequence in step with the server, follow it rather than reject it.
+	rw.p.compressedSequence = header[3] + 1
+	uncompressedLength := int(uint32(header[4]) | uint32(header[5])<<8 | uint32(header[6])<<16)
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(rw.p.bufReadConn, payload); err != nil {
+		return errors.Trace(err)
+	}
+	// The uncompressed length is 0 if the payload isn't compressed.
+	if uncompressedLength == 0 {
+		rw.data = payload
+		return nil
+	}
+	data, err := rw.decompress(payload, uncompressedLength)
+	if err != nil {
+		return errors.Trace(mysql.ErrMalformPacket)
+	}
+	if len(data) != uncompressedLength {
+		return errors.Trace(mysql.ErrMalformPacket)
+	}
+	rw.data = data
+	return nil
+}
+
+// Write is called by the bufio.Writer of packetIO, it writes b as one or more compressed packets.
+func (rw *compressedReadWriter) Write(b []byte) (int, error) {
+	written := 0
+	for written < len(b) {
+		chunk := b[written:]
+		if len(chunk) > mysql.MaxPayloadLen {
+			chunk = chunk[:mysql.MaxPayloadLen]
+		}
+		if err := rw.writeCompressedPacket(chunk); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+	}
+	return written, nil
+}
+
+func (rw *compressedReadWriter) writeCompressedPacket(chunk []byte) error {
+	rw.buf = append(rw.buf[:0], make([]byte, compressedHeaderLen)...)
+	uncompressedLength := 0
+	if len(chunk) >= minCompressLength {
+		buf, err := rw.compress(rw.buf, chunk)
+		if err != nil {
+			return err
+		}
+		rw.buf = buf
+		uncompressedLength = len(chunk)
+	}
+	// Send the chunk as it is if it's too short or not compressible.
+	if uncompressedLength == 0 || len(rw.buf)-compressedHeaderLen >= len(chunk) {
+		rw.buf = append(rw.buf[:compressedHeaderLen], chunk...)
+		uncompressedLength = 0
+	}
+	length := len(rw.buf) - compressedHeaderLen
+	rw.buf[0] = byte(length)
+	rw.buf[1] = byte(length >> 8)
+	rw.buf[2] = byte(length >> 16)
+	rw.buf[3] = rw.p.compressedSequence
+	rw.buf[4] = byte(uncompressedLength)
+	rw.buf[5] = byte(uncompressedLength >> 8)
+	rw.buf[6] = byte(uncompressedLength >> 16)
+	if _, err := rw.p.timedWriter.Write(rw.buf); err != nil {
+		return errors.Trace(err)
+	}
+	rw.p.compressedSequence++
+	return nil
+}
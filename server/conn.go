@@ -77,6 +77,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/store/copr"
 	storeerr "github.com/pingcap/tidb/store/driver/error"
 	"github.com/pingcap/tidb/tablecodec"
 	tidbutil "github.com/pingcap/tidb/util"
@@ -195,6 +196,7 @@ type clientConn struct {
 	rsEncoder     *resultEncoder    // rsEncoder is used to encode the string result to different charsets.
 	inputDecoder  *inputDecoder     // inputDecoder is used to decode the different charsets of incoming strings to utf-8.
 	socketCredUID uint32            // UID from the other end of the Unix Socket
+	zstdLevel     int               // zstd compression level sent by the client, 0 if it's not sent.
 	// mu is used for cancelling the execution of current transaction.
 	mu struct {
 		sync.RWMutex
@@ -303,13 +305,38 @@ func (cc *clientConn) handshake(ctx context.Context) error {
 		logutil.Logger(ctx).Debug("flush response to client failed", zap.Error(err))
 		return err
 	}
-	return err
+	// The compressed protocol is used after the OK packet of the handshake.
+	return cc.initCompression()
+}
+
+// initCompression switches the connection to the compressed protocol if the client negotiates it in the handshake,
+// zstd is used if the client supports both zstd and zlib.
+func (cc *clientConn) initCompression() error {
+	var c compressor
+	switch {
+	case cc.capability&mysql.ClientZstdCompressionAlgorithm > 0:
+		level := cc.zstdLevel
+		if level == 0 {
+			level = defaultZstdLevel
+		}
+		zc, err := newZstdCompressor(level)
+		if err != nil {
+			return err
+		}
+		c = zc
+	case cc.capability&mysql.ClientCompress > 0:
+		c = newZlibCompressor(cc.server.cfg.Performance.ProtocolCompressionLevel)
+	default:
+		return nil
+	}
+	cc.pkt.setCompressor(c)
+	return nil
 }
 
 func (cc *clientConn) Close() error {
 	cc.server.rwlock.Lock()
 	delete(cc.server.clients, cc.connectionID)
-	connections := len(cc.server.clients)
+	connections := len(cc.server.clients) + len(cc.server.xClients)
 	cc.server.rwlock.Unlock()
 	return closeConn(cc, connections)
 }
@@ -328,7 +355,7 @@ func closeConn(cc *clientConn, connections int) error {
 
 func (cc *clientConn) closeWithoutLock() error {
 	delete(cc.server.clients, cc.connectionID)
-	return closeConn(cc, len(cc.server.clients))
+	return closeConn(cc, len(cc.server.clients)+len(cc.server.xClients))
 }
 
 // writeInitialHandshake sends server version, connection ID, server capability, collation, server status
@@ -429,6 +456,7 @@ type handshakeResponse41 struct {
 	Auth       []byte
 	AuthPlugin string
 	Attrs      map[string]string
+	ZstdLevel  uint8
 }
 
 // parseOldHandshakeResponseHeader parses the old version handshake header HandshakeResponse320
@@ -581,9 +609,14 @@ func parseHandshakeResponseBody(ctx context.Context, packet *handshakeResponse41
 				return nil
 			}
 			packet.Attrs = attrs
+			offset += int(num)
 		}
 	}
 
+	if packet.Capability&mysql.ClientZstdCompressionAlgorithm > 0 && len(data[offset:]) > 0 {
+		packet.ZstdLevel = data[offset]
+	}
+
 	return nil
 }
 
@@ -688,6 +721,7 @@ func (cc *clientConn) readOptionalSSLRequestAndHandshakeResponse(ctx context.Con
 	cc.dbname = resp.DBName
 	cc.collation = resp.Collation
 	cc.attrs = resp.Attrs
+	cc.zstdLevel = int(resp.ZstdLevel)
 
 	err = cc.handleAuthPlugin(ctx, &resp)
 	if err != nil {
@@ -1128,9 +1162,16 @@ func (cc *clientConn) Run(ctx context.Context) {
 			}
 			err1 := cc.writeError(ctx, err)
 			terror.Log(err1)
+			if data[0] == mysql.ComChangeUser {
+				// The old session is closed and the new user isn't authenticated, the connection can't
+				// be used anymore, which is the same as MySQL.
+				logutil.Logger(ctx).Info("change user failed, close this connection", zap.Error(err))
+				return
+			}
 		}
 		cc.addMetrics(data[0], startTime, err)
 		cc.pkt.sequence = 0
+		cc.pkt.compressedSequence = 0
 	}
 }
 
@@ -1152,11 +1193,23 @@ func (cc *clientConn) ShutdownOrNotify() bool {
 
 func errStrForLog(err error, enableRedactLog bool) string {
 	if enableRedactLog {
-		// currently, only ErrParse is considered when enableRedactLog because it may contain sensitive information like
-		// password or accesskey
+		// ErrParse may contain sensitive information like password or accesskey
 		if parser.ErrParse.Equal(err) {
 			return "fail to parse SQL and can't redact when enable log redaction"
 		}
+		// The TiDB errors redact their own arguments, but not the arguments of their causes. The causes
+		// are dropped, only the class, the code and the message template of the error are logged.
+		if _, ok := errors.Cause(err).(*terror.Error); !ok {
+			if tErr := errors.Find(err, func(err error) bool {
+				_, ok := err.(*terror.Error)
+				return ok
+			}); tErr != nil {
+				e := tErr.(*terror.Error)
+				return fmt.Sprintf("[%s]%s", e.RFCCode(), errno.RedactTemplate(e.MessageTemplate()))
+			}
+			// The arguments of the other errors are unknown, the quoted strings and the numbers are redacted.
+			return errno.RedactMessage(err.Error())
+		}
 	}
 	if kv.ErrKeyExists.Equal(err) || parser.ErrParse.Equal(err) || infoschema.ErrTableNotExists.Equal(err) {
 		// Do not log stack for duplicated entry error.
@@ -1229,6 +1282,9 @@ func (cc *clientConn) addMetrics(cmd byte, startTime time.Time, err error) {
 	default:
 		metrics.QueryDurationHistogram.WithLabelValues(sqlType).Observe(cost.Seconds())
 	}
+	metrics.ObserveSampled(func() prometheus.Observer {
+		return metrics.QueryDurationByDBHistogram.WithLabelValues(sessionVar.CurrentDB, sqlType)
+	}, cost.Seconds())
 }
 
 // dispatch handles client request based on command which is the first byte of the data.
@@ -1473,21 +1529,14 @@ func (cc *clientConn) writeOkWith(ctx context.Context, msg string, affectedRows,
 }
 
 func (cc *clientConn) writeError(ctx context.Context, e error) error {
-	var (
-		m  *mysql.SQLError
-		te *terror.Error
-		ok bool
-	)
-	originErr := errors.Cause(e)
-	if te, ok = originErr.(*terror.Error); ok {
-		m = terror.ToSQLError(te)
-	} else {
-		e := errors.Cause(originErr)
-		switch y := e.(type) {
-		case *terror.Error:
-			m = terror.ToSQLError(y)
-		default:
-			m = mysql.NewErrf(mysql.ErrUnknown, "%s", nil, e.Error())
+	m := toSQLError(e)
+	if cc.ctx != nil {
+		vars := cc.ctx.GetSessionVars()
+		if vars.EnableStableSQLState {
+			m.State = errno.SQLState(m.Code)
+		}
+		if vars.EnableErrorDocURL {
+			m.Message = errno.FormatMessage(m.Code, m.Message)
 		}
 	}
 
@@ -1510,6 +1559,25 @@ func (cc *clientConn) writeError(ctx context.Context, e error) error {
 	return cc.flush(ctx)
 }
 
+// toSQLError converts the error to the error sent to the client, the errors which aren't
+// terror.Error are converted to ErrUnknown.
+func toSQLError(e error) *mysql.SQLError {
+	var m *mysql.SQLError
+	originErr := errors.Cause(e)
+	if te, ok := originErr.(*terror.Error); ok {
+		m = terror.ToSQLError(te)
+	} else {
+		e := errors.Cause(originErr)
+		switch y := e.(type) {
+		case *terror.Error:
+			m = terror.ToSQLError(y)
+		default:
+			m = mysql.NewErrf(mysql.ErrUnknown, "%s", nil, e.Error())
+		}
+	}
+	return m
+}
+
 // writeEOF writes an EOF packet.
 // Note this function won't flush the stream because maybe there are more
 // packets following it.
@@ -1845,7 +1913,7 @@ func (cc *clientConn) handleQuery(ctx context.Context, sql string) (err error) {
 		}
 		retryable, err = cc.handleStmt(ctx, stmt, parserWarns, i == len(stmts)-1)
 		if err != nil {
-			if !retryable || !errors.ErrorEqual(err, storeerr.ErrTiFlashServerTimeout) {
+			if !retryable || !copr.IsStoreUnavailable(err, kv.TiFlash) {
 				break
 			}
 			_, allowTiFlashFallback := cc.ctx.GetSessionVars().AllowFallbackToTiKV[kv.TiFlash]
@@ -2100,7 +2168,7 @@ func (cc *clientConn) writeResultset(ctx context.Context, rs ResultSet, binary b
 		if r == nil {
 			return
 		}
-		if str, ok := r.(string); !ok || !strings.HasPrefix(str, memory.PanicMemoryExceed) {
+		if str, ok := r.(string); !ok || !(strings.HasPrefix(str, memory.PanicMemoryExceed) || strings.HasPrefix(str, memory.PanicStorageExceed)) {
 			panic(r)
 		}
 		// TODO(jianzhang.zj: add metrics here)
@@ -2112,6 +2180,7 @@ func (cc *clientConn) writeResultset(ctx context.Context, rs ResultSet, binary b
 	}()
 	cc.initResultEncoder(ctx)
 	defer cc.rsEncoder.clean()
+	defer cc.recordClientStall(ctx, cc.pkt.writeElapsed())
 	if mysql.HasCursorExistsFlag(serverStatus) {
 		if err := cc.writeChunksWithFetchSize(ctx, rs, serverStatus, fetchSize); err != nil {
 			return false, err
@@ -2125,6 +2194,31 @@ func (cc *clientConn) writeResultset(ctx context.Context, rs ResultSet, binary b
 	return false, cc.flush(ctx)
 }
 
+// recordClientStall records the time blocked in writing the result set to the client since writeStart.
+func (cc *clientConn) recordClientStall(ctx context.Context, writeStart time.Duration) {
+	stall := cc.pkt.writeElapsed() - writeStart
+	metrics.ClientStallDurationHistogram.Observe(stall.Seconds())
+	if stmtDetail, ok := ctx.Value(execdetails.StmtExecDetailKey).(*execdetails.StmtExecDetails); ok {
+		stmtDetail.ClientStallDuration += stall
+	}
+}
+
+// flushResultIfNeeded flushes the buffered rows to the client once they reach tidb_result_flush_threshold.
+// It also checks whether the query is killed after the flush, so writing a large result set to a slow
+// client can be interrupted between the flushes.
+func (cc *clientConn) flushResultIfNeeded(threshold int) error {
+	if threshold <= 0 || cc.pkt.bufWriter.Buffered() < threshold {
+		return nil
+	}
+	if err := cc.pkt.flush(); err != nil {
+		return err
+	}
+	if atomic.LoadUint32(&cc.ctx.GetSessionVars().Killed) == 1 {
+		return executor.ErrQueryInterrupted
+	}
+	return nil
+}
+
 func (cc *clientConn) writeColumnInfo(columns []*ColumnInfo, serverStatus uint16) error {
 	data := cc.alloc.AllocWithLen(4, 1024)
 	data = dumpLengthEncodedInt(data, uint64(len(columns)))
@@ -2155,6 +2249,7 @@ func (cc *clientConn) writeChunks(ctx context.Context, rs ResultSet, binary bool
 	if stmtDetailRaw != nil {
 		stmtDetail = stmtDetailRaw.(*execdetails.StmtExecDetails)
 	}
+	flushThreshold := cc.ctx.GetSessionVars().ResultFlushThreshold
 	for {
 		failpoint.Inject("fetchNextErr", func(value failpoint.Value) {
 			switch value.(string) {
@@ -2202,6 +2297,10 @@ func (cc *clientConn) writeChunks(ctx context.Context, rs ResultSet, binary bool
 				reg.End()
 				return false, err
 			}
+			if err = cc.flushResultIfNeeded(flushThreshold); err != nil {
+				reg.End()
+				return false, err
+			}
 		}
 		reg.End()
 		if stmtDetail != nil {
@@ -2266,6 +2365,7 @@ func (cc *clientConn) writeChunksWithFetchSize(ctx context.Context, rs ResultSet
 		stmtDetail = stmtDetailRaw.(*execdetails.StmtExecDetails)
 	}
 	start := time.Now()
+	flushThreshold := cc.ctx.GetSessionVars().ResultFlushThreshold
 	var err error
 	for _, row := range curRows {
 		data = data[0:4]
@@ -2276,6 +2376,9 @@ func (cc *clientConn) writeChunksWithFetchSize(ctx context.Context, rs ResultSet
 		if err = cc.writePacket(data); err != nil {
 			return err
 		}
+		if err = cc.flushResultIfNeeded(flushThreshold); err != nil {
+			return err
+		}
 	}
 	if stmtDetail != nil {
 		stmtDetail.WriteSQLRespDuration += time.Since(start)
@@ -2307,29 +2410,90 @@ func (cc *clientConn) upgradeToTLS(tlsConfig *tls.Config) error {
 	return nil
 }
 
+// handleChangeUser handles COM_CHANGE_USER, it authenticates the new user in a new session, so the state
+// of the old session, such as the variables, the prepared statements and the transaction, is reset.
+// The authentication method is switched like the handshake if the client and the user use different plugins.
 func (cc *clientConn) handleChangeUser(ctx context.Context, data []byte) error {
+	resp, err := parseChangeUserPacket(data, cc.capability)
+	if err != nil {
+		return err
+	}
+	cc.user = resp.User
+	cc.dbname = resp.DBName
+	if resp.Collation != 0 {
+		cc.collation = resp.Collation
+	}
+	if resp.Attrs != nil {
+		cc.attrs = resp.Attrs
+	}
+
+	if err := cc.ctx.Close(); err != nil {
+		logutil.Logger(ctx).Debug("close old context failed", zap.Error(err))
+	}
+	if err := cc.openSession(); err != nil {
+		return err
+	}
+	if err := cc.handleAuthPlugin(ctx, resp); err != nil {
+		return err
+	}
+	switch resp.AuthPlugin {
+	case mysql.AuthCachingSha2Password:
+		resp.Auth, err = cc.authSha(ctx)
+		if err != nil {
+			return err
+		}
+	case mysql.AuthNativePassword:
+	case mysql.AuthSocket:
+	default:
+		return errors.New("Unknown auth plugin")
+	}
+	if err := cc.openSessionAndDoAuth(resp.Auth, resp.AuthPlugin); err != nil {
+		return err
+	}
+	return cc.handleCommonConnectionReset(ctx)
+}
+
+// parseChangeUserPacket parses the payload of COM_CHANGE_USER. The character set, the authentication plugin
+// and the connection attributes are optional, they're absent in the packets of the old clients.
+func parseChangeUserPacket(data []byte, capability uint32) (*handshakeResponse41, error) {
+	resp := &handshakeResponse41{Capability: capability}
 	user, data := parseNullTermString(data)
-	cc.user = string(hack.String(user))
+	resp.User = string(hack.String(user))
 	if len(data) < 1 {
-		return mysql.ErrMalformPacket
+		return nil, mysql.ErrMalformPacket
 	}
 	passLen := int(data[0])
 	data = data[1:]
 	if passLen > len(data) {
-		return mysql.ErrMalformPacket
+		return nil, mysql.ErrMalformPacket
 	}
-	pass := data[:passLen]
+	resp.Auth = data[:passLen]
 	data = data[passLen:]
-	dbName, _ := parseNullTermString(data)
-	cc.dbname = string(hack.String(dbName))
-
-	if err := cc.ctx.Close(); err != nil {
-		logutil.Logger(ctx).Debug("close old context failed", zap.Error(err))
+	dbName, data := parseNullTermString(data)
+	resp.DBName = string(hack.String(dbName))
+	if len(data) == 0 {
+		return resp, nil
 	}
-	if err := cc.openSessionAndDoAuth(pass, ""); err != nil {
-		return err
+	if len(data) < 2 {
+		return nil, mysql.ErrMalformPacket
+	}
+	resp.Collation = data[0]
+	data = data[2:]
+	if capability&mysql.ClientPluginAuth > 0 && len(data) > 0 {
+		var plugin []byte
+		plugin, data = parseNullTermString(data)
+		resp.AuthPlugin = string(hack.String(plugin))
+	}
+	if capability&mysql.ClientConnectAtts > 0 && len(data) > 0 {
+		if num, null, off := parseLengthEncodedInt(data); !null && off+int(num) <= len(data) {
+			attrs, err := parseAttrs(data[off : off+int(num)])
+			if err != nil {
+				return nil, mysql.ErrMalformPacket
+			}
+			resp.Attrs = attrs
+		}
 	}
-	return cc.handleCommonConnectionReset(ctx)
+	return resp, nil
 }
 
 func (cc *clientConn) handleResetConnection(ctx context.Context) error {
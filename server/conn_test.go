@@ -21,15 +21,21 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/pingcap/errors"
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/session"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	derr "github.com/pingcap/tidb/store/driver/error"
 	"github.com/pingcap/tidb/store/mockstore"
 	"github.com/pingcap/tidb/store/mockstore/unistore"
 	"github.com/pingcap/tidb/testkit"
@@ -99,6 +105,18 @@ func TestParseHandshakeResponse(t *testing.T) {
 	require.Equal(t, "pam", p.User)
 	require.Equal(t, "test", p.DBName)
 
+	// The zstd compression level follows the auth plugin name if ClientZstdCompressionAlgorithm is set.
+	data = append(data, 0x07)
+	data[3] |= byte(mysql.ClientZstdCompressionAlgorithm >> 24)
+	p = handshakeResponse41{}
+	offset, err = parseHandshakeResponseHeader(context.Background(), &p, data)
+	require.NoError(t, err)
+	require.Equal(t, mysql.ClientZstdCompressionAlgorithm, p.Capability&mysql.ClientZstdCompressionAlgorithm)
+	err = parseHandshakeResponseBody(context.Background(), &p, data, offset)
+	require.NoError(t, err)
+	require.Equal(t, "mysql_native_password", p.AuthPlugin)
+	require.Equal(t, uint8(7), p.ZstdLevel)
+
 	// Test for compatibility of Protocol::HandshakeResponse320
 	data = []byte{
 		0x00, 0x80, 0x00, 0x00, 0x01, 0x72, 0x6f, 0x6f, 0x74, 0x00, 0x00,
@@ -871,6 +889,235 @@ func TestShowErrors(t *testing.T) {
 	tk.MustQuery("show errors").Check(testkit.Rows("Error 1051 Unknown table 'test.idontexist'"))
 }
 
+func TestParseChangeUserPacket(t *testing.T) {
+	// The packet of the old clients only contains the user, the auth data and the database.
+	data := append([]byte("root"), 0x0, 0x2, 0x1, 0x2)
+	data = append(data, []byte("test")...)
+	data = append(data, 0x0)
+	resp, err := parseChangeUserPacket(data, mysql.ClientProtocol41)
+	require.NoError(t, err)
+	require.Equal(t, "root", resp.User)
+	require.Equal(t, []byte{0x1, 0x2}, resp.Auth)
+	require.Equal(t, "test", resp.DBName)
+	require.Equal(t, uint8(0), resp.Collation)
+	require.Equal(t, "", resp.AuthPlugin)
+	require.Nil(t, resp.Attrs)
+
+	// The character set, the auth plugin and the connection attributes.
+	data = append(data, mysql.DefaultCollationID, 0x0)
+	data = append(data, []byte(mysql.AuthCachingSha2Password)...)
+	data = append(data, 0x0)
+	attrs := dumpLengthEncodedString(nil, []byte("_client_name"))
+	attrs = dumpLengthEncodedString(attrs, []byte("libmysql"))
+	data = dumpLengthEncodedString(data, attrs)
+	resp, err = parseChangeUserPacket(data, mysql.ClientProtocol41|mysql.ClientPluginAuth|mysql.ClientConnectAtts)
+	require.NoError(t, err)
+	require.Equal(t, "root", resp.User)
+	require.Equal(t, uint8(mysql.DefaultCollationID), resp.Collation)
+	require.Equal(t, mysql.AuthCachingSha2Password, resp.AuthPlugin)
+	require.Equal(t, map[string]string{"_client_name": "libmysql"}, resp.Attrs)
+
+	// The plugin and the attributes are ignored if the capabilities aren't set.
+	resp, err = parseChangeUserPacket(data, mysql.ClientProtocol41)
+	require.NoError(t, err)
+	require.Equal(t, "", resp.AuthPlugin)
+	require.Nil(t, resp.Attrs)
+
+	_, err = parseChangeUserPacket([]byte("root\x00"), mysql.ClientProtocol41)
+	require.Equal(t, mysql.ErrMalformPacket, err)
+	_, err = parseChangeUserPacket(append([]byte("root"), 0x0, 0x5, 0x1), mysql.ClientProtocol41)
+	require.Equal(t, mysql.ErrMalformPacket, err)
+	_, err = parseChangeUserPacket(append([]byte("root"), 0x0, 0x0, 0x0, 0x1), mysql.ClientProtocol41)
+	require.Equal(t, mysql.ErrMalformPacket, err)
+}
+
+func TestChangeUser(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("create user 'u1'@'localhost'")
+	tk.MustExec("create user 'u2'@'localhost' identified by 'pwd'")
+	tk.MustExec("grant select on test.* to 'u1'@'localhost'")
+
+	cfg := newTestConfig()
+	cfg.Port, cfg.Status.StatusPort = 0, 0
+	cfg.Status.ReportStatus = false
+	server, err := NewServer(cfg, NewTiDBDriver(store))
+	require.NoError(t, err)
+	defer server.Close()
+	cc := &clientConn{
+		connectionID: 1,
+		salt:         []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F, 0x10, 0x11, 0x12, 0x13, 0x14},
+		server:       server,
+		pkt: &packetIO{
+			bufWriter: bufio.NewWriter(bytes.NewBuffer(nil)),
+		},
+		collation:  mysql.DefaultCollationID,
+		peerHost:   "localhost",
+		alloc:      arena.NewAllocator(512),
+		chunkAlloc: chunk.NewAllocator(),
+		capability: mysql.ClientProtocol41,
+		user:       "root",
+	}
+	require.NoError(t, cc.openSessionAndDoAuth(nil, mysql.AuthNativePassword))
+	ctx := context.Background()
+	require.NoError(t, cc.handleQuery(ctx, "set @a = 1, @@tidb_result_flush_threshold = 1024"))
+	require.NoError(t, cc.handleQuery(ctx, "begin"))
+	oldSession := cc.ctx
+
+	changeUser := func(user, db string) error {
+		data := append([]byte(user), 0x0, 0x0)
+		data = append(data, []byte(db)...)
+		data = append(data, 0x0)
+		return cc.handleChangeUser(ctx, data)
+	}
+	require.NoError(t, changeUser("u1", "test"))
+	require.NotSame(t, oldSession, cc.ctx)
+	vars := cc.ctx.GetSessionVars()
+	require.Equal(t, "u1", vars.User.Username)
+	require.Equal(t, "localhost", vars.User.AuthHostname)
+	require.Equal(t, "test", vars.CurrentDB)
+	require.Equal(t, 0, vars.ResultFlushThreshold)
+	require.Equal(t, uint16(0), cc.ctx.Status()&mysql.ServerStatusInTrans)
+	_, ok := vars.Users["a"]
+	require.False(t, ok)
+
+	// The auth data doesn't match the password.
+	err = changeUser("u2", "")
+	require.Error(t, err)
+	require.True(t, errAccessDenied.Equal(err))
+}
+
+// slowClientWriter emulates a client which reads the result slowly.
+type slowClientWriter struct {
+	writes  int
+	onWrite func()
+}
+
+func (w *slowClientWriter) Write(b []byte) (int, error) {
+	w.writes++
+	time.Sleep(time.Millisecond)
+	if w.onWrite != nil {
+		w.onWrite()
+	}
+	return len(b), nil
+}
+
+func TestResultFlushThreshold(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int, b varchar(64))")
+	for i := 0; i < 100; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t values (%d, '%s')", i, strings.Repeat("x", 64)))
+	}
+
+	w := &slowClientWriter{}
+	pkt := &packetIO{timedWriter: &timedWriter{w: w}}
+	pkt.bufWriter = bufio.NewWriterSize(pkt.timedWriter, defaultWriterSize)
+	cc := &clientConn{
+		alloc:      arena.NewAllocator(1024),
+		chunkAlloc: chunk.NewAllocator(),
+		pkt:        pkt,
+	}
+	cc.ctx = &TiDBContext{Session: tk.Session(), stmts: make(map[int]*TiDBStatement)}
+	ctx := context.Background()
+
+	// The result is less than the write buffer, it's written to the client by the final flush.
+	require.NoError(t, cc.handleQuery(ctx, "select * from t"))
+	require.Equal(t, 1, w.writes)
+
+	// The result is flushed every 1024 bytes.
+	w.writes = 0
+	tk.MustExec("set @@tidb_result_flush_threshold = 1024")
+	require.NoError(t, cc.handleQuery(ctx, "select * from t"))
+	require.Greater(t, w.writes, 6)
+	require.GreaterOrEqual(t, pkt.writeElapsed(), time.Duration(w.writes+1)*time.Millisecond)
+
+	// The kill is checked after each flush.
+	w.writes = 0
+	w.onWrite = func() {
+		atomic.StoreUint32(&tk.Session().GetSessionVars().Killed, 1)
+	}
+	require.Equal(t, executor.ErrQueryInterrupted, cc.handleQuery(ctx, "select * from t"))
+	require.Equal(t, 1, w.writes)
+	atomic.StoreUint32(&tk.Session().GetSessionVars().Killed, 0)
+	tk.MustExec("set @@tidb_result_flush_threshold = 0")
+}
+
+func TestErrStrForLog(t *testing.T) {
+	// The arguments of the errors that aren't defined by TiDB are redacted by the log redaction.
+	err := errors.New("key 'secret' not found")
+	require.Equal(t, "key ? not found", errStrForLog(err, true))
+	require.Contains(t, errStrForLog(err, false), "secret")
+	require.Equal(t, "HY000", toSQLError(err).State)
+
+	err = executor.ErrWriteInHistoryRead.GenWithStackByArgs(variable.TiDBSnapshot)
+	require.Contains(t, errStrForLog(err, true), "[executor:8247]can not execute write statement when 'tidb_snapshot' is set")
+	require.Equal(t, "HY000", toSQLError(err).State)
+
+	// The causes of the TiDB errors are dropped, the class and the code are kept.
+	err = executor.ErrWriteInHistoryRead.Wrap(errors.New("key 'secret' not found"))
+	require.Equal(t, "[executor:8247]can not execute write statement when '?' is set", errStrForLog(err, true))
+}
+
+func TestStableSQLState(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+
+	var outBuffer bytes.Buffer
+	cc := &clientConn{
+		capability: mysql.ClientProtocol41,
+		pkt: &packetIO{
+			bufWriter: bufio.NewWriter(&outBuffer),
+		},
+		alloc: arena.NewAllocator(512),
+	}
+	cc.ctx = &TiDBContext{Session: tk.Session(), stmts: make(map[int]*TiDBStatement)}
+	// The packet is the header, the error header, the code, '#' and the SQLSTATE.
+	sqlState := func(err error) string {
+		outBuffer.Reset()
+		require.NoError(t, cc.writeError(context.Background(), err))
+		return string(outBuffer.Bytes()[8:13])
+	}
+
+	// The TiDB specific codes are sent with HY000 by default.
+	require.Equal(t, "HY000", sqlState(kv.ErrWriteConflict.FastGenByArgs()))
+	require.Equal(t, "HY000", sqlState(derr.ErrTiKVServerTimeout.FastGenByArgs()))
+
+	tk.MustExec("set @@tidb_enable_stable_sqlstate = 1")
+	require.Equal(t, "40001", sqlState(kv.ErrWriteConflict.FastGenByArgs()))
+	// The unavailable storage is never reported by the class 08, which means the connection is broken.
+	require.Equal(t, "HY000", sqlState(derr.ErrTiKVServerTimeout.FastGenByArgs()))
+	// The MySQL codes keep their SQLSTATE.
+	require.Equal(t, "23000", sqlState(kv.ErrKeyExists.FastGenByArgs("1", "PRIMARY")))
+}
+
+func TestInitCompression(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Performance.ProtocolCompressionLevel = 9
+	cc := &clientConn{
+		server: &Server{cfg: cfg},
+		pkt:    newPacketIO(newBufferedReadConn(&bytesConn{})),
+	}
+	require.NoError(t, cc.initCompression())
+	require.Nil(t, cc.pkt.compressed)
+
+	cc.capability = mysql.ClientCompress
+	require.NoError(t, cc.initCompression())
+	require.Equal(t, 9, cc.pkt.compressed.compressor.(*zlibCompressor).level)
+	cc.pkt.closeCompressor()
+
+	// zstd is preferred if the client supports both.
+	cc.capability = mysql.ClientCompress | mysql.ClientZstdCompressionAlgorithm
+	cc.zstdLevel = 19
+	require.NoError(t, cc.initCompression())
+	require.IsType(t, &zstdCompressor{}, cc.pkt.compressed.compressor)
+	cc.pkt.closeCompressor()
+}
+
 func TestHandleAuthPlugin(t *testing.T) {
 	store, clean := testkit.CreateMockStore(t)
 	defer clean()
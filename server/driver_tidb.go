@@ -165,8 +165,8 @@ func (ts *TiDBStatement) Close() error {
 			if !ok {
 				return errors.Errorf("invalid CachedPrepareStmt type")
 			}
-			ts.ctx.PreparedPlanCache().Delete(core.NewPlanCacheKey(
-				ts.ctx.GetSessionVars(), ts.id, preparedObj.PreparedAst.SchemaVersion))
+			core.DeletePlanCache(ts.ctx.PreparedPlanCache(), core.NewPlanCacheKey(
+				ts.ctx.GetSessionVars(), ts.id, preparedObj.PreparedAst.SchemaVersion), core.PlanCacheEvictDeallocate)
 		}
 		ts.ctx.GetSessionVars().RemovePreparedStmt(ts.id)
 	}
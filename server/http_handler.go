@@ -25,6 +25,7 @@ import (
 	"net/http"
 	"net/url"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -44,6 +45,7 @@ import (
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/metrics"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/session"
@@ -51,6 +53,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/store/copr"
 	derr "github.com/pingcap/tidb/store/driver/error"
 	"github.com/pingcap/tidb/store/gcworker"
 	"github.com/pingcap/tidb/store/helper"
@@ -87,6 +90,7 @@ const (
 	pRowBin     = "rowBin"
 	pSnapshot   = "snapshot"
 	pFileName   = "filename"
+	pServiceID  = "serviceID"
 )
 
 // For query string
@@ -95,6 +99,13 @@ const (
 	qLimit     = "limit"
 	qOperation = "op"
 	qSeconds   = "seconds"
+	qSafePoint = "safepoint"
+	qTTL       = "ttl"
+	qStoreType = "store_type"
+	qZone      = "zone"
+	qNodes     = "nodes"
+	qStartKey  = "start_key"
+	qEndKey    = "end_key"
 )
 
 const (
@@ -324,6 +335,9 @@ type settingsHandler struct {
 	*tikvHandlerTool
 }
 
+// configHandler is the handler for getting the config and changing the online changeable items of it.
+type configHandler struct{}
+
 // binlogRecover is used to recover binlog service.
 // When config binlog IgnoreError, binlog service will stop after meeting the first error.
 // It can be recovered using HTTP API.
@@ -369,6 +383,11 @@ type ddlResignOwnerHandler struct {
 	store kv.Storage
 }
 
+// gcSafePointHandler is the handler for listing, setting and expiring the service safe points in PD.
+type gcSafePointHandler struct {
+	*tikvHandlerTool
+}
+
 type serverInfoHandler struct {
 	*tikvHandlerTool
 }
@@ -392,13 +411,24 @@ type valueHandler struct {
 }
 
 const (
-	opTableRegions     = "regions"
-	opTableRanges      = "ranges"
-	opTableDiskUsage   = "disk-usage"
-	opTableScatter     = "scatter-table"
-	opStopTableScatter = "stop-scatter-table"
+	opTableRegions       = "regions"
+	opTableRanges        = "ranges"
+	opTableDiskUsage     = "disk-usage"
+	opTableScatter       = "scatter-table"
+	opStopTableScatter   = "stop-scatter-table"
+	opTableBatchCopTasks = "batch-cop-tasks"
 )
 
+// batchCopTasksHandler is the handler for building the batch cop tasks of a key range without sending them.
+type batchCopTasksHandler struct {
+	*tikvHandlerTool
+}
+
+// batchCopBalanceHandler is the handler for dumping the latest balance decisions of the batch cop tasks.
+type batchCopBalanceHandler struct {
+	*tikvHandlerTool
+}
+
 // mvccTxnHandler is the handler for txn debugger.
 type mvccTxnHandler struct {
 	*tikvHandlerTool
@@ -622,6 +652,25 @@ func (t *tikvHandlerTool) getRegionsMeta(regionIDs []uint64) ([]RegionMeta, erro
 	return regions, nil
 }
 
+// ServeHTTP handles request of the config.
+func (h configHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodPost {
+		var items map[string]interface{}
+		if err := json.NewDecoder(req.Body).Decode(&items); err != nil {
+			writeError(w, err)
+			return
+		}
+		if err := config.UpdateOnline(items); err != nil {
+			writeError(w, err)
+			return
+		}
+		cfg := config.GetGlobalConfig()
+		metrics.SetLabelValueLimit(cfg.Status.MetricsLabelValueLimit)
+		metrics.SetHistogramSampleRate(cfg.Status.MetricsHistogramSampleRate)
+	}
+	writeData(w, config.GetGlobalConfig())
+}
+
 // ServeHTTP handles request of list tidb server settings.
 func (h settingsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	if req.Method == "POST" {
@@ -1206,6 +1255,8 @@ func (h tableHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		h.handleRangeRequest(schema, tableVal, w, req)
 	case opTableDiskUsage:
 		h.handleDiskUsageRequest(tableVal, w)
+	case opTableBatchCopTasks:
+		h.handleBatchCopTasksRequest(tableVal, w, req)
 	case opTableScatter:
 		// supports partition table, only get one physical table, prevent too many scatter schedulers.
 		ptbl, err := h.getPartition(tableVal, partitionName)
@@ -1309,6 +1360,45 @@ func (h ddlResignOwnerHandler) ServeHTTP(w http.ResponseWriter, req *http.Reques
 	writeData(w, "success!")
 }
 
+// ServeHTTP lists the service safe points, or sets and expires the service safe point of a service.
+func (h gcSafePointHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	serviceID := mux.Vars(req)[pServiceID]
+	pdClient := h.RegionCache.PDClient()
+	switch {
+	case req.Method == http.MethodGet && serviceID == "":
+		safePoints, err := h.GetServiceGCSafePoints()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeData(w, safePoints)
+	case req.Method == http.MethodPost && serviceID != "":
+		safePoint, err := strconv.ParseUint(req.FormValue(qSafePoint), 10, 64)
+		if err != nil {
+			writeError(w, errors.Errorf("parse safepoint(%s) failed", req.FormValue(qSafePoint)))
+			return
+		}
+		ttl, err := strconv.ParseInt(req.FormValue(qTTL), 10, 64)
+		if err != nil {
+			writeError(w, errors.Errorf("parse ttl(%s) failed", req.FormValue(qTTL)))
+			return
+		}
+		if err = gcutil.SetServiceSafePoint(req.Context(), pdClient, serviceID, safePoint, ttl); err != nil {
+			writeError(w, err)
+			return
+		}
+		writeData(w, "success!")
+	case req.Method == http.MethodDelete && serviceID != "":
+		if err := gcutil.ExpireServiceSafePoint(req.Context(), pdClient, serviceID); err != nil {
+			writeError(w, err)
+			return
+		}
+		writeData(w, "success!")
+	default:
+		writeError(w, errors.Errorf("This api doesn't support %s method.", req.Method))
+	}
+}
+
 func (h tableHandler) getPDAddr() ([]string, error) {
 	etcd, ok := h.Store.(kv.EtcdBackend)
 	if !ok {
@@ -1551,6 +1641,105 @@ func (h tableHandler) handleDiskUsageRequest(tbl table.Table, w http.ResponseWri
 	writeData(w, stats.StorageSize)
 }
 
+// handleBatchCopTasksRequest builds the batch cop tasks to scan the records of the table, including all its
+// partitions, and writes their layout.
+func (h tableHandler) handleBatchCopTasksRequest(tbl table.Table, w http.ResponseWriter, req *http.Request) {
+	ids := []int64{tbl.Meta().ID}
+	if pi := tbl.Meta().GetPartitionInfo(); pi != nil {
+		ids = ids[:0]
+		for _, def := range pi.Definitions {
+			ids = append(ids, def.ID)
+		}
+	}
+	ranges := make([]kv.KeyRange, 0, len(ids))
+	for _, id := range ids {
+		startKey, endKey := tablecodec.GetTableHandleKeyRange(id)
+		ranges = append(ranges, kv.KeyRange{StartKey: startKey, EndKey: endKey})
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return bytes.Compare(ranges[i].StartKey, ranges[j].StartKey) < 0
+	})
+	layout, err := h.dryRunBatchCop(req, ranges)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeData(w, layout)
+}
+
+// ServeHTTP handles request of building the batch cop tasks of the key range in hex, such as
+// /batch-cop-tasks?start_key=7480&end_key=7481&store_type=tiflash.
+func (h batchCopTasksHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	startKey, err := hex.DecodeString(req.FormValue(qStartKey))
+	if err != nil {
+		writeError(w, errors.Errorf("invalid start_key %s", req.FormValue(qStartKey)))
+		return
+	}
+	endKey, err := hex.DecodeString(req.FormValue(qEndKey))
+	if err != nil {
+		writeError(w, errors.Errorf("invalid end_key %s", req.FormValue(qEndKey)))
+		return
+	}
+	if len(endKey) > 0 && bytes.Compare(startKey, endKey) >= 0 {
+		writeError(w, errors.New("start_key should be less than end_key"))
+		return
+	}
+	layout, err := h.dryRunBatchCop(req, []kv.KeyRange{{StartKey: startKey, EndKey: endKey}})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeData(w, layout)
+}
+
+// ServeHTTP dumps the latest balance decisions of the batch cop tasks, at most the limit parameter of them.
+func (h batchCopBalanceHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	client, ok := h.Store.GetClient().(*copr.CopClient)
+	if !ok {
+		writeError(w, errors.New("the store doesn't support the batch cop requests"))
+		return
+	}
+	limit := 0
+	if v := req.FormValue(qLimit); len(v) > 0 {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 {
+			writeError(w, errors.Errorf("invalid limit %s", v))
+			return
+		}
+	}
+	writeData(w, client.BatchCopBalanceDecisions(limit))
+}
+
+// dryRunBatchCop builds the batch cop tasks of the ranges to the store of the store_type parameter, TiFlash by
+// default. The TiFlash tasks are built for the zone parameter, and the comma separated TiFlash addresses of the
+// nodes parameter, as tidb_tiflash_replica_read does.
+func (t *tikvHandlerTool) dryRunBatchCop(req *http.Request, ranges []kv.KeyRange) (*copr.BatchCopLayout, error) {
+	client, ok := t.Store.GetClient().(*copr.CopClient)
+	if !ok {
+		return nil, errors.New("the store doesn't support the batch cop requests")
+	}
+	kvReq := &kv.Request{
+		Tp:            kv.ReqTypeDAG,
+		KeyRanges:     ranges,
+		StoreType:     kv.TiFlash,
+		BatchCop:      true,
+		PreferredZone: req.FormValue(qZone),
+	}
+	switch storeType := strings.ToLower(req.FormValue(qStoreType)); storeType {
+	case "", kv.TiFlash.Name():
+	case kv.TiKV.Name():
+		kvReq.StoreType = kv.TiKV
+	default:
+		return nil, errors.Errorf("unsupported store type %s", storeType)
+	}
+	for _, node := range strings.Split(req.FormValue(qNodes), ",") {
+		if node = strings.TrimSpace(node); node != "" {
+			kvReq.TiFlashReplicaReadNodes = append(kvReq.TiFlashReplicaReadNodes, node)
+		}
+	}
+	return client.DryRunBatchCop(req.Context(), kvReq)
+}
+
 // ServeHTTP handles request of get region by ID.
 func (h regionHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	// parse and check params
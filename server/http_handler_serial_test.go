@@ -34,6 +34,7 @@ import (
 	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/metrics"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/session"
@@ -188,6 +189,39 @@ func TestPostSettings(t *testing.T) {
 	config.GetGlobalConfig().CheckMb4ValueInUTF8.Store(true)
 }
 
+func TestPostConfig(t *testing.T) {
+	ts := createBasicHTTPHandlerTestSuite()
+	ts.startServer(t)
+	defer ts.stopServer(t)
+	defer config.RestoreFunc()()
+	defer metrics.SetLabelValueLimit(0)
+	defer metrics.SetHistogramSampleRate(0)
+
+	resp, err := ts.postStatus("/config", "application/json", bytes.NewBufferString(`{"status.metrics-label-value-limit":100,"status.metrics-histogram-sample-rate":0.5}`))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var cfg config.Config
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&cfg))
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, int64(100), cfg.Status.MetricsLabelValueLimit)
+	require.Equal(t, 0.5, cfg.Status.MetricsHistogramSampleRate)
+	require.Equal(t, int64(100), config.GetGlobalConfig().Status.MetricsLabelValueLimit)
+	require.Equal(t, 0.5, config.GetGlobalConfig().Status.MetricsHistogramSampleRate)
+
+	for _, body := range []string{
+		`{"log.level":"info"}`,
+		`{"status.metrics-histogram-sample-rate":2}`,
+		`{"status.metrics-label-value-limit":"100"}`,
+	} {
+		resp, err = ts.postStatus("/config", "application/json", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
+		require.NoError(t, resp.Body.Close())
+	}
+	require.Equal(t, int64(100), config.GetGlobalConfig().Status.MetricsLabelValueLimit)
+	require.Equal(t, 0.5, config.GetGlobalConfig().Status.MetricsHistogramSampleRate)
+}
+
 func TestAllServerInfo(t *testing.T) {
 	ts := createBasicHTTPHandlerTestSuite()
 	ts.startServer(t)
@@ -46,6 +46,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/store/copr"
 	"github.com/pingcap/tidb/store/helper"
 	"github.com/pingcap/tidb/store/mockstore"
 	"github.com/pingcap/tidb/tablecodec"
@@ -360,6 +361,51 @@ func TestListTableRanges(t *testing.T) {
 	}
 }
 
+func TestBatchCopTasks(t *testing.T) {
+	ts := createBasicHTTPHandlerTestSuite()
+	ts.startServer(t)
+	defer ts.stopServer(t)
+	ts.prepareData(t)
+
+	resp, err := ts.fetchStatus("/tables/tidb/pt/batch-cop-tasks?store_type=tikv")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var layout copr.BatchCopLayout
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&layout))
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "tikv", layout.StoreType)
+	require.Len(t, layout.Tasks, 1)
+	require.Equal(t, 1, layout.RegionCount)
+	require.Equal(t, 3, layout.Tasks[0].Regions[0].RangeCount)
+
+	resp, err = ts.fetchStatus("/batch-cop-tasks?store_type=tikv&start_key=74&end_key=75")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&layout))
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, 1, layout.RegionCount)
+
+	resp, err = ts.fetchStatus("/batch-cop-tasks/balance-decisions?limit=10")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var decisions []copr.BatchCopBalanceDecision
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decisions))
+	require.NoError(t, resp.Body.Close())
+	require.LessOrEqual(t, len(decisions), 10)
+
+	for _, path := range []string{
+		"/tables/tidb/pt/batch-cop-tasks?store_type=tidb",
+		"/batch-cop-tasks?start_key=xx",
+		"/batch-cop-tasks?start_key=75&end_key=74",
+		"/batch-cop-tasks/balance-decisions?limit=-1",
+	} {
+		resp, err = ts.fetchStatus(path)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, path)
+		require.NoError(t, resp.Body.Close())
+	}
+}
+
 func TestGetRegionByIDWithError(t *testing.T) {
 	ts := createBasicHTTPHandlerTestSuite()
 	ts.startServer(t)
@@ -37,9 +37,9 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/failpoint"
-	"github.com/pingcap/fn"
 	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/metrics"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/util"
@@ -48,6 +48,7 @@ import (
 	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/printer"
 	"github.com/pingcap/tidb/util/versioninfo"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/soheilhy/cmux"
 	"github.com/tiancaiamao/appdash/traceapp"
@@ -195,7 +196,8 @@ func (s *Server) startHTTPServer() {
 
 	router.HandleFunc("/status", s.handleStatus).Name("Status")
 	// HTTP path for prometheus.
-	router.Handle("/metrics", promhttp.Handler()).Name("Metrics")
+	router.Handle("/metrics", promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(metrics.Gatherer(), promhttp.HandlerOpts{}))).Name("Metrics")
 
 	// HTTP path for dump statistics.
 	router.Handle("/stats/dump/{db}/{table}", s.newStatsHandler()).Name("StatsDump")
@@ -221,10 +223,12 @@ func (s *Server) startHTTPServer() {
 	router.Handle("/ddl/history", ddlHistoryJobHandler{tikvHandlerTool}).Name("DDL_History")
 	router.Handle("/ddl/owner/resign", ddlResignOwnerHandler{tikvHandlerTool.Store.(kv.Storage)}).Name("DDL_Owner_Resign")
 
-	// HTTP path for get the TiDB config
-	router.Handle("/config", fn.Wrap(func() (*config.Config, error) {
-		return config.GetGlobalConfig(), nil
-	}))
+	// HTTP path for listing, setting and expiring the service safe points.
+	router.Handle("/gc/safepoints", gcSafePointHandler{tikvHandlerTool}).Name("GC_SafePoints")
+	router.Handle("/gc/safepoints/{serviceID}", gcSafePointHandler{tikvHandlerTool})
+
+	// HTTP path for get the TiDB config, and change the online changeable items by SET CONFIG.
+	router.Handle("/config", configHandler{})
 
 	// HTTP path for get server info.
 	router.Handle("/info", serverInfoHandler{tikvHandlerTool}).Name("Info")
@@ -241,6 +245,9 @@ func (s *Server) startHTTPServer() {
 		router.Handle("/tables/{db}/{table}/scatter", tableHandler{tikvHandlerTool, opTableScatter})
 		router.Handle("/tables/{db}/{table}/stop-scatter", tableHandler{tikvHandlerTool, opStopTableScatter})
 		router.Handle("/tables/{db}/{table}/disk-usage", tableHandler{tikvHandlerTool, opTableDiskUsage})
+		router.Handle("/tables/{db}/{table}/batch-cop-tasks", tableHandler{tikvHandlerTool, opTableBatchCopTasks})
+		router.Handle("/batch-cop-tasks", batchCopTasksHandler{tikvHandlerTool}).Name("BatchCopTasks")
+		router.Handle("/batch-cop-tasks/balance-decisions", batchCopBalanceHandler{tikvHandlerTool}).Name("BatchCopBalanceDecisions")
 		router.Handle("/regions/meta", regionHandler{tikvHandlerTool}).Name("RegionsMeta")
 		router.Handle("/regions/hot", regionHandler{tikvHandlerTool}).Name("RegionHot")
 		router.Handle("/regions/{regionID}", regionHandler{tikvHandlerTool})
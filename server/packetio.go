@@ -57,8 +57,27 @@ var (
 type packetIO struct {
 	bufReadConn *bufferedReadConn
 	bufWriter   *bufio.Writer
+	timedWriter *timedWriter
+	// compressed is set after the handshake if the client negotiates the compressed protocol.
+	compressed  *compressedReadWriter
 	sequence    uint8
 	readTimeout time.Duration
+	// compressedSequence is the sequence of the compressed packets, it's reset with sequence at the beginning of a command.
+	compressedSequence uint8
+}
+
+// timedWriter records the time blocked in writing to the connection, it's mostly the time waiting for
+// the client to read the data when the socket buffer is full.
+type timedWriter struct {
+	w       io.Writer
+	elapsed time.Duration
+}
+
+func (w *timedWriter) Write(b []byte) (int, error) {
+	start := time.Now()
+	n, err := w.w.Write(b)
+	w.elapsed += time.Since(start)
+	return n, err
 }
 
 func newPacketIO(bufReadConn *bufferedReadConn) *packetIO {
@@ -69,7 +88,30 @@ func newPacketIO(bufReadConn *bufferedReadConn) *packetIO {
 
 func (p *packetIO) setBufferedReadConn(bufReadConn *bufferedReadConn) {
 	p.bufReadConn = bufReadConn
-	p.bufWriter = bufio.NewWriterSize(bufReadConn, defaultWriterSize)
+	p.timedWriter = &timedWriter{w: bufReadConn}
+	p.bufWriter = bufio.NewWriterSize(p.timedWriter, defaultWriterSize)
+}
+
+// setCompressor switches to the compressed protocol, the buffered data must have been flushed.
+func (p *packetIO) setCompressor(c compressor) {
+	p.compressed = &compressedReadWriter{p: p, compressor: c}
+	p.compressedSequence = 0
+	p.bufWriter = bufio.NewWriterSize(p.compressed, defaultWriterSize)
+}
+
+// closeCompressor releases the resources held by the compressor, it's called when the connection is closed.
+func (p *packetIO) closeCompressor() {
+	if p.compressed != nil {
+		p.compressed.close()
+	}
+}
+
+// writeElapsed returns the total time blocked in writing to the connection.
+func (p *packetIO) writeElapsed() time.Duration {
+	if p == nil || p.timedWriter == nil {
+		return 0
+	}
+	return p.timedWriter.elapsed
 }
 
 func (p *packetIO) setReadTimeout(timeout time.Duration) {
@@ -83,12 +125,20 @@ func (p *packetIO) readOnePacket() ([]byte, error) {
 			return nil, err
 		}
 	}
-	if _, err := io.ReadFull(p.bufReadConn, header[:]); err != nil {
+	var r io.Reader = p.bufReadConn
+	if p.compressed != nil {
+		r = p.compressed
+	}
+	if _, err := io.ReadFull(r, header[:]); err != nil {
 		return nil, errors.Trace(err)
 	}
 
 	sequence := header[3]
-	if sequence != p.sequence {
+	if p.compressed != nil {
+		// The sequence of the packets inside the compressed packets isn't checked, like MySQL, because
+		// the clients may sync it with the compressed sequence.
+		p.sequence = sequence
+	} else if sequence != p.sequence {
 		return nil, errInvalidSequence.GenWithStack("invalid sequence %d != %d", sequence, p.sequence)
 	}
 
@@ -102,7 +152,7 @@ func (p *packetIO) readOnePacket() ([]byte, error) {
 			return nil, err
 		}
 	}
-	if _, err := io.ReadFull(p.bufReadConn, data); err != nil {
+	if _, err := io.ReadFull(r, data); err != nil {
 		return nil, errors.Trace(err)
 	}
 	return data, nil
@@ -86,6 +86,56 @@ func TestPacketIORead(t *testing.T) {
 	require.Equal(t, byte(0x0a), bytes[mysql.MaxPayloadLen])
 }
 
+func TestCompressedPacketIO(t *testing.T) {
+	zc, err := newZstdCompressor(defaultZstdLevel)
+	require.NoError(t, err)
+	for _, c := range []compressor{newZlibCompressor(6), zc} {
+		conn := &loopbackConn{}
+		pkt := newPacketIO(newBufferedReadConn(conn))
+		pkt.setCompressor(c)
+
+		// The short payload is sent uncompressed.
+		require.NoError(t, pkt.writePacket([]byte{0x00, 0x00, 0x00, 0x00, 0x01, 0x02, 0x03}))
+		require.NoError(t, pkt.flush())
+		require.Equal(t, []byte{0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x02, 0x03}, conn.b.Bytes())
+		require.Equal(t, uint8(1), pkt.compressedSequence)
+		pkt.sequence, pkt.compressedSequence = 0, 0
+		data, err := pkt.readPacket()
+		require.NoError(t, err)
+		require.Equal(t, []byte{0x01, 0x02, 0x03}, data)
+		require.Equal(t, uint8(1), pkt.compressedSequence)
+
+		// The long payloads are compressed, including the one split into multiple packets.
+		payloads := [][]byte{bytes.Repeat([]byte("tidb"), 1000), bytes.Repeat([]byte{0x0a}, mysql.MaxPayloadLen+10)}
+		for _, payload := range payloads {
+			require.NoError(t, pkt.writePacket(append(make([]byte, 4), payload...)))
+		}
+		require.NoError(t, pkt.flush())
+		require.Less(t, conn.b.Len(), len(payloads[1]))
+		for _, payload := range payloads {
+			data, err = pkt.readPacket()
+			require.NoError(t, err)
+			require.Equal(t, payload, data)
+		}
+		require.Zero(t, conn.b.Len())
+		if c == compressor(zc) {
+			// The decoder of zstd is created by the reads and released by the close.
+			require.NotNil(t, zc.dec)
+		}
+		pkt.closeCompressor()
+	}
+	require.Nil(t, zc.dec)
+}
+
+// loopbackConn reads the data written to it.
+type loopbackConn struct {
+	bytesConn
+}
+
+func (c *loopbackConn) Write(b []byte) (n int, err error) {
+	return c.b.Write(b)
+}
+
 type bytesConn struct {
 	b bytes.Buffer
 }
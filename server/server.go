@@ -120,9 +120,11 @@ type Server struct {
 	driver            IDriver
 	listener          net.Listener
 	socket            net.Listener
+	xListener         net.Listener
 	rwlock            sync.RWMutex
 	concurrentLimiter *TokenLimiter
 	clients           map[uint64]*clientConn
+	xClients          map[uint64]*xConn
 	capability        uint32
 	dom               *domain.Domain
 	globalConnID      util.GlobalConnID
@@ -137,7 +139,7 @@ type Server struct {
 // ConnectionCount gets current connection count.
 func (s *Server) ConnectionCount() int {
 	s.rwlock.RLock()
-	cnt := len(s.clients)
+	cnt := len(s.clients) + len(s.xClients)
 	s.rwlock.RUnlock()
 	return cnt
 }
@@ -193,12 +195,19 @@ func NewServer(cfg *config.Config, driver IDriver) (*Server, error) {
 		driver:            driver,
 		concurrentLimiter: NewTokenLimiter(cfg.TokenLimit),
 		clients:           make(map[uint64]*clientConn),
+		xClients:          make(map[uint64]*xConn),
 		globalConnID:      util.GlobalConnID{ServerID: 0, Is64bits: true},
 	}
 	s.capability = defaultCapability
 	setTxnScope()
 	setSystemTimeZoneVariable()
 
+	// The X Protocol listener doesn't support TLS, so none of its connections could be secure.
+	if cfg.XProtocol.Enable && cfg.Security.RequireSecureTransport {
+		logutil.BgLogger().Error("the X Protocol can't be enabled while require-secure-transport is on")
+		return nil, errSecureTransportRequired.FastGenByArgs()
+	}
+
 	tlsConfig, autoReload, err := util.LoadTLSCertificates(
 		s.cfg.Security.SSLCA, s.cfg.Security.SSLKey, s.cfg.Security.SSLCert,
 		s.cfg.Security.AutoTLS, s.cfg.Security.RSAKeySize)
@@ -235,6 +244,9 @@ func NewServer(cfg *config.Config, driver IDriver) (*Server, error) {
 	if s.tlsConfig != nil {
 		s.capability |= mysql.ClientSSL
 	}
+	if s.cfg.Performance.ProtocolCompression {
+		s.capability |= mysql.ClientCompress | mysql.ClientZstdCompressionAlgorithm
+	}
 
 	if s.cfg.Host != "" && (s.cfg.Port != 0 || RunInGoTest) {
 		addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
@@ -251,6 +263,21 @@ func NewServer(cfg *config.Config, driver IDriver) (*Server, error) {
 		}
 	}
 
+	if s.cfg.XProtocol.Enable {
+		addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.XProtocol.Port)
+		tcpProto := "tcp"
+		if s.cfg.EnableTCP4Only {
+			tcpProto = "tcp4"
+		}
+		if s.xListener, err = net.Listen(tcpProto, addr); err != nil {
+			return nil, errors.Trace(err)
+		}
+		logutil.BgLogger().Info("server is running X protocol", zap.String("addr", addr))
+		if RunInGoTest && s.cfg.XProtocol.Port == 0 {
+			s.cfg.XProtocol.Port = uint(s.xListener.Addr().(*net.TCPAddr).Port)
+		}
+	}
+
 	if s.cfg.Socket != "" {
 		if err := cleanupStaleSocket(s.cfg.Socket); err != nil {
 			return nil, errors.Trace(err)
@@ -365,11 +392,13 @@ func (s *Server) Run() error {
 	errChan := make(chan error)
 	go s.startNetworkListener(s.listener, false, errChan)
 	go s.startNetworkListener(s.socket, true, errChan)
-	err := <-errChan
-	if err != nil {
-		return err
+	go s.startXListener(s.xListener, errChan)
+	for i := 0; i < 3; i++ {
+		if err := <-errChan; err != nil {
+			return err
+		}
 	}
-	return <-errChan
+	return nil
 }
 
 func (s *Server) startNetworkListener(listener net.Listener, isUnixSocket bool, errChan chan error) {
@@ -482,6 +511,11 @@ func (s *Server) Close() {
 		terror.Log(errors.Trace(err))
 		s.socket = nil
 	}
+	if s.xListener != nil {
+		err := s.xListener.Close()
+		terror.Log(errors.Trace(err))
+		s.xListener = nil
+	}
 	if s.statusServer != nil {
 		err := s.statusServer.Close()
 		terror.Log(errors.Trace(err))
@@ -525,13 +559,14 @@ func (s *Server) onConn(conn *clientConn) {
 	}
 
 	logutil.Logger(ctx).Debug("new connection", zap.String("remoteAddr", conn.bufReadConn.RemoteAddr().String()))
+	defer conn.pkt.closeCompressor()
 
 	defer func() {
 		logutil.Logger(ctx).Debug("connection closed")
 	}()
 	s.rwlock.Lock()
 	s.clients[conn.connectionID] = conn
-	connections := len(s.clients)
+	connections := len(s.clients) + len(s.xClients)
 	s.rwlock.Unlock()
 	metrics.ConnGauge.Set(float64(connections))
 
@@ -606,7 +641,7 @@ func (s *Server) checkConnectionCount() error {
 	}
 
 	s.rwlock.RLock()
-	conns := len(s.clients)
+	conns := len(s.clients) + len(s.xClients)
 	s.rwlock.RUnlock()
 
 	if conns >= int(s.cfg.MaxServerConnections) {
@@ -621,7 +656,7 @@ func (s *Server) checkConnectionCount() error {
 func (s *Server) ShowProcessList() map[uint64]*util.ProcessInfo {
 	s.rwlock.RLock()
 	defer s.rwlock.RUnlock()
-	rs := make(map[uint64]*util.ProcessInfo, len(s.clients))
+	rs := make(map[uint64]*util.ProcessInfo, len(s.clients)+len(s.xClients))
 	for _, client := range s.clients {
 		if atomic.LoadInt32(&client.status) == connStatusWaitShutdown {
 			continue
@@ -630,6 +665,14 @@ func (s *Server) ShowProcessList() map[uint64]*util.ProcessInfo {
 			rs[pi.ID] = pi
 		}
 	}
+	for _, client := range s.xClients {
+		if atomic.LoadInt32(&client.status) == connStatusWaitShutdown {
+			continue
+		}
+		if pi := client.ctx.ShowProcess(); pi != nil {
+			rs[pi.ID] = pi
+		}
+	}
 	return rs
 }
 
@@ -637,7 +680,7 @@ func (s *Server) ShowProcessList() map[uint64]*util.ProcessInfo {
 func (s *Server) ShowTxnList() []*txninfo.TxnInfo {
 	s.rwlock.RLock()
 	defer s.rwlock.RUnlock()
-	rs := make([]*txninfo.TxnInfo, 0, len(s.clients))
+	rs := make([]*txninfo.TxnInfo, 0, len(s.clients)+len(s.xClients))
 	for _, client := range s.clients {
 		if client.ctx.Session != nil {
 			info := client.ctx.Session.TxnInfo()
@@ -646,6 +689,11 @@ func (s *Server) ShowTxnList() []*txninfo.TxnInfo {
 			}
 		}
 	}
+	for _, client := range s.xClients {
+		if info := client.ctx.Session.TxnInfo(); info != nil {
+			rs = append(rs, info)
+		}
+	}
 	return rs
 }
 
@@ -653,11 +701,15 @@ func (s *Server) ShowTxnList() []*txninfo.TxnInfo {
 func (s *Server) GetProcessInfo(id uint64) (*util.ProcessInfo, bool) {
 	s.rwlock.RLock()
 	conn, ok := s.clients[id]
+	xc, xok := s.xClients[id]
 	s.rwlock.RUnlock()
-	if !ok {
-		return &util.ProcessInfo{}, false
+	if ok {
+		return conn.ctx.ShowProcess(), ok
 	}
-	return conn.ctx.ShowProcess(), ok
+	if xok {
+		return xc.ctx.ShowProcess(), xok
+	}
+	return &util.ProcessInfo{}, false
 }
 
 // Kill implements the SessionManager interface.
@@ -669,6 +721,12 @@ func (s *Server) Kill(connectionID uint64, query bool) {
 	defer s.rwlock.RUnlock()
 	conn, ok := s.clients[connectionID]
 	if !ok {
+		if xc, ok := s.xClients[connectionID]; ok {
+			if !query {
+				atomic.StoreInt32(&xc.status, connStatusWaitShutdown)
+			}
+			killXConn(xc)
+		}
 		return
 	}
 
@@ -713,6 +771,11 @@ func (s *Server) KillAllConnections() {
 		}
 		killConn(conn)
 	}
+	for _, xc := range s.xClients {
+		atomic.StoreInt32(&xc.status, connStatusShutdown)
+		terror.Log(xc.conn.Close())
+		killXConn(xc)
+	}
 }
 
 var gracefulCloseConnectionsTimeout = 15 * time.Second
@@ -762,6 +825,7 @@ func (s *Server) GracefulDown(ctx context.Context, done chan struct{}) {
 
 func (s *Server) kickIdleConnection() {
 	var conns []*clientConn
+	var xConns []*xConn
 	s.rwlock.RLock()
 	for _, cc := range s.clients {
 		if cc.ShutdownOrNotify() {
@@ -769,6 +833,11 @@ func (s *Server) kickIdleConnection() {
 			conns = append(conns, cc)
 		}
 	}
+	for _, xc := range s.xClients {
+		if xc.shutdownOrNotify() {
+			xConns = append(xConns, xc)
+		}
+	}
 	s.rwlock.RUnlock()
 
 	for _, cc := range conns {
@@ -777,6 +846,12 @@ func (s *Server) kickIdleConnection() {
 			logutil.BgLogger().Error("close connection", zap.Error(err))
 		}
 	}
+	// The X Protocol connections unregister themselves once their connections are closed.
+	for _, xc := range xConns {
+		if err := xc.conn.Close(); err != nil {
+			logutil.BgLogger().Error("close connection", zap.Error(err))
+		}
+	}
 }
 
 // ServerID implements SessionManager interface.
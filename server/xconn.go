@@ -0,0 +1,688 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/parser/terror"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/fastrand"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"go.uber.org/zap"
+)
+
+// xCapability is the capability of the sessions of the X Protocol, the X Protocol has its own result sets,
+// so the capability only affects the behavior of the sessions.
+const xCapability = mysql.ClientLongPassword | mysql.ClientLongFlag | mysql.ClientProtocol41 |
+	mysql.ClientTransactions | mysql.ClientSecureConnection | mysql.ClientPluginAuth
+
+// xAuthMySQL41 is the only authentication mechanism supported, it's the same as mysql_native_password.
+const xAuthMySQL41 = "MYSQL41"
+
+// xConn is a connection of the X Protocol. The connection is registered in the server while its session is open,
+// so it's shown in the process list, can be killed by KILL and is counted by max-server-connections.
+type xConn struct {
+	server       *Server
+	conn         net.Conn
+	bufReader    *bufio.Reader
+	bufWriter    *bufio.Writer
+	connectionID uint64
+	salt         []byte
+	ctx          *TiDBContext
+	dbname       string
+	status       int32 // dispatching/reading/shutdown/waitshutdown
+	// mu is used for cancelling the execution of the current message.
+	mu struct {
+		sync.RWMutex
+		cancelFunc context.CancelFunc
+	}
+}
+
+func (s *Server) newXConn(conn net.Conn) *xConn {
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		if err := tcpConn.SetKeepAlive(s.cfg.Performance.TCPKeepAlive); err != nil {
+			logutil.BgLogger().Error("failed to set tcp keep alive option", zap.Error(err))
+		}
+		if err := tcpConn.SetNoDelay(s.cfg.Performance.TCPNoDelay); err != nil {
+			logutil.BgLogger().Error("failed to set tcp no delay option", zap.Error(err))
+		}
+	}
+	return &xConn{
+		server:       s,
+		conn:         conn,
+		bufReader:    bufio.NewReaderSize(conn, defaultReaderSize),
+		bufWriter:    bufio.NewWriterSize(conn, defaultWriterSize),
+		connectionID: s.globalConnID.NextID(),
+		salt:         fastrand.Buf(20),
+		status:       connStatusDispatching,
+	}
+}
+
+// startXListener accepts the connections of the X Protocol.
+func (s *Server) startXListener(listener net.Listener, errChan chan error) {
+	if listener == nil {
+		errChan <- nil
+		return
+	}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if opErr, ok := err.(*net.OpError); ok {
+				if opErr.Err.Error() == "use of closed network connection" {
+					if s.inShutdownMode {
+						errChan <- nil
+					} else {
+						errChan <- err
+					}
+					return
+				}
+			}
+			logutil.BgLogger().Error("accept failed", zap.Error(err))
+			errChan <- err
+			return
+		}
+		if s.dom != nil && s.dom.IsLostConnectionToPD() {
+			logutil.BgLogger().Warn("reject connection due to lost connection to PD")
+			terror.Log(conn.Close())
+			continue
+		}
+		go s.onXConn(s.newXConn(conn))
+	}
+}
+
+// onXConn runs in its own goroutine, a connection may be authenticated again after Sess.Close.
+func (s *Server) onXConn(xc *xConn) {
+	ctx := logutil.WithConnID(context.Background(), xc.connectionID)
+	defer func() {
+		if r := recover(); r != nil {
+			const size = 4096
+			buf := make([]byte, size)
+			buf = buf[:runtime.Stack(buf, false)]
+			logutil.Logger(ctx).Error("X protocol connection panicked", zap.Reflect("r", r), zap.String("stack", string(buf)))
+		}
+		xc.closeSession()
+		terror.Log(xc.conn.Close())
+		logutil.Logger(ctx).Debug("X protocol connection closed")
+	}()
+	for {
+		err := xc.authenticate(ctx)
+		if err == nil {
+			err = xc.run(ctx)
+		}
+		if err == nil {
+			continue
+		}
+		if errors.Cause(err) != io.EOF {
+			logutil.Logger(ctx).Warn("X protocol connection failed", zap.Error(err),
+				zap.String("remote addr", xc.conn.RemoteAddr().String()))
+		}
+		return
+	}
+}
+
+// authenticate handles the messages before the session is authenticated.
+func (xc *xConn) authenticate(ctx context.Context) error {
+	for {
+		tp, msg, err := readXMessage(xc.bufReader)
+		if err != nil {
+			return err
+		}
+		switch tp {
+		case xClientConCapabilitiesGet:
+			err = xc.writeCapabilities()
+		case xClientConCapabilitiesSet:
+			if err = xc.setCapabilities(msg); err == nil {
+				err = xc.writeMessage(xServerOk, nil)
+			}
+		case xClientConClose:
+			if err = xc.writeMessage(xServerOk, nil); err != nil {
+				return err
+			}
+			return io.EOF
+		case xClientSessAuthenticateStart:
+			if err = xc.handleAuthenticate(ctx, msg); err == nil {
+				return nil
+			}
+		default:
+			err = errXBadMessage.GenWithStackByArgs(fmt.Sprintf("unexpected message type %d before authentication", tp))
+		}
+		if err != nil {
+			if err = xc.writeError(err); err != nil {
+				return err
+			}
+		}
+		if err = xc.flush(); err != nil {
+			return err
+		}
+	}
+}
+
+func (xc *xConn) writeCapabilities() error {
+	names := []string{"authentication.mechanisms", "doc.formats", "node_type", "client.pwd_expire_ok", "client.interactive"}
+	values := [][]byte{
+		encodeXAnyArray(encodeXAnyScalar(encodeXScalarString(xAuthMySQL41))),
+		encodeXAnyScalar(encodeXScalarString("text")),
+		encodeXAnyScalar(encodeXScalarString("mysql")),
+		encodeXAnyBool(false),
+		encodeXAnyBool(false),
+	}
+	return xc.writeMessage(xServerConCapabilities, encodeXCapabilities(names, values))
+}
+
+// setCapabilities checks the capabilities set by the client, the connection attributes and the client
+// flags are accepted and ignored, TLS and compression aren't supported.
+func (xc *xConn) setCapabilities(msg []byte) error {
+	fields, err := decodeXFields(msg)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.num != 1 {
+			continue
+		}
+		// CapabilitiesSet{capabilities: Capabilities{capabilities: Capability{name, value}}}
+		capabilities, err := decodeXFields(f.b)
+		if err != nil {
+			return err
+		}
+		for _, c := range capabilities {
+			capability, err := decodeXFields(c.b)
+			if err != nil {
+				return err
+			}
+			for _, cf := range capability {
+				if cf.num != 1 {
+					continue
+				}
+				switch name := string(cf.b); name {
+				case "session_connect_attrs", "client.pwd_expire_ok", "client.interactive":
+				case "tls", "compression":
+					return errXCapabilitiesPrepareFailed.GenWithStackByArgs(name)
+				default:
+					return errXCapabilityNotFound.GenWithStackByArgs(name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// handleAuthenticate handles AuthenticateStart and the following AuthenticateContinue of MYSQL41,
+// the client sends "schema\0user\0*password" where the password is the hex of the scramble.
+func (xc *xConn) handleAuthenticate(ctx context.Context, msg []byte) error {
+	fields, err := decodeXFields(msg)
+	if err != nil {
+		return err
+	}
+	var mech string
+	for _, f := range fields {
+		if f.num == 1 {
+			mech = string(f.b)
+		}
+	}
+	if mech != xAuthMySQL41 {
+		return errXNotSupported.GenWithStackByArgs("authentication mechanism " + mech)
+	}
+	if err = xc.writeMessage(xServerSessAuthenticateContinue, appendXBytes(nil, 1, xc.salt)); err != nil {
+		return err
+	}
+	if err = xc.flush(); err != nil {
+		return err
+	}
+
+	tp, msg, err := readXMessage(xc.bufReader)
+	if err != nil {
+		return err
+	}
+	if tp != xClientSessAuthenticateContinue {
+		return errXBadMessage.GenWithStackByArgs(fmt.Sprintf("unexpected message type %d during authentication", tp))
+	}
+	if fields, err = decodeXFields(msg); err != nil {
+		return err
+	}
+	var authData []byte
+	for _, f := range fields {
+		if f.num == 1 {
+			authData = f.b
+		}
+	}
+	parts := bytes.SplitN(authData, []byte{0}, 3)
+	if len(parts) != 3 {
+		return errXBadMessage.GenWithStackByArgs("invalid authentication data")
+	}
+	dbname, user, password := string(parts[0]), string(parts[1]), parts[2]
+	var scramble []byte
+	if len(password) > 0 {
+		if scramble, err = hex.DecodeString(string(bytes.TrimPrefix(password, []byte{'*'}))); err != nil {
+			return errXBadMessage.GenWithStackByArgs("invalid authentication data")
+		}
+	}
+	if err = xc.openSession(ctx, dbname, func(host string) error {
+		hasPassword := "YES"
+		if len(scramble) == 0 {
+			hasPassword = "NO"
+		}
+		if !xc.ctx.Auth(&auth.UserIdentity{Username: user, Hostname: host}, scramble, xc.salt) {
+			return errAccessDenied.FastGenByArgs(user, host, hasPassword)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	notice := encodeXSessionStateChanged(xStateClientIDAssigned, encodeXScalarUInt(xc.connectionID))
+	if err = xc.writeMessage(xServerNotice, encodeXNotice(xNoticeSessionStateChanged, notice)); err != nil {
+		return err
+	}
+	if err = xc.writeMessage(xServerSessAuthenticateOk, nil); err != nil {
+		return err
+	}
+	return xc.flush()
+}
+
+// openSession opens the session and authenticates the user by verify.
+func (xc *xConn) openSession(ctx context.Context, dbname string, verify func(host string) error) error {
+	xc.closeSession()
+	var err error
+	xc.ctx, err = xc.server.driver.OpenCtx(xc.connectionID, xCapability, uint8(mysql.DefaultCollationID), dbname, nil)
+	if err != nil {
+		return err
+	}
+	host, port := variable.DefHostname, ""
+	if addr, ok := xc.conn.RemoteAddr().(*net.TCPAddr); ok {
+		host, port = addr.IP.String(), fmt.Sprint(addr.Port)
+	}
+	if err = verify(host); err != nil {
+		xc.closeSession()
+		return err
+	}
+	xc.ctx.SetPort(port)
+	if dbname != "" {
+		if err = xc.useDB(ctx, dbname); err != nil {
+			xc.closeSession()
+			return err
+		}
+	}
+	if err = xc.server.checkConnectionCount(); err != nil {
+		xc.closeSession()
+		return err
+	}
+	xc.dbname = dbname
+	xc.ctx.SetSessionManager(xc.server)
+	xc.server.registerXConn(xc)
+	return nil
+}
+
+func (xc *xConn) useDB(ctx context.Context, dbname string) error {
+	stmts, err := xc.ctx.Parse(ctx, sqlexec.MustEscapeSQL("USE %n", dbname))
+	if err != nil {
+		return err
+	}
+	_, err = xc.ctx.ExecuteStmt(ctx, stmts[0])
+	return err
+}
+
+// registerXConn adds the connection to the clients of the server after its session is opened.
+func (s *Server) registerXConn(xc *xConn) {
+	s.rwlock.Lock()
+	s.xClients[xc.connectionID] = xc
+	connections := len(s.clients) + len(s.xClients)
+	s.rwlock.Unlock()
+	metrics.ConnGauge.Set(float64(connections))
+}
+
+func (s *Server) unregisterXConn(xc *xConn) {
+	s.rwlock.Lock()
+	if _, ok := s.xClients[xc.connectionID]; !ok {
+		s.rwlock.Unlock()
+		return
+	}
+	delete(s.xClients, xc.connectionID)
+	connections := len(s.clients) + len(s.xClients)
+	s.rwlock.Unlock()
+	metrics.ConnGauge.Set(float64(connections))
+}
+
+func (xc *xConn) closeSession() {
+	xc.server.unregisterXConn(xc)
+	if xc.ctx != nil {
+		terror.Log(xc.ctx.Close())
+		xc.ctx = nil
+	}
+}
+
+// run handles the messages of the authenticated session, it returns nil if the session is closed by
+// Sess.Close or Sess.Reset, and the connection can be authenticated again. Like clientConn.Run, it exits
+// when the server fails to change the status, which means the connection is killed or shut down.
+func (xc *xConn) run(ctx context.Context) error {
+	for {
+		if !atomic.CompareAndSwapInt32(&xc.status, connStatusDispatching, connStatusReading) {
+			return io.EOF
+		}
+		tp, msg, err := readXMessage(xc.bufReader)
+		if err != nil {
+			return err
+		}
+		if !atomic.CompareAndSwapInt32(&xc.status, connStatusReading, connStatusDispatching) {
+			return io.EOF
+		}
+		token := xc.server.getToken()
+		err = xc.dispatchWithCancel(ctx, tp, msg)
+		xc.server.releaseToken(token)
+		switch err {
+		case io.EOF:
+			return err
+		case errXSessionClosed:
+			return xc.flush()
+		case nil:
+		default:
+			logutil.Logger(ctx).Info("X protocol command failed", zap.Uint8("type", tp), zap.Error(err))
+			if err = xc.writeError(err); err != nil {
+				return err
+			}
+		}
+		if err = xc.flush(); err != nil {
+			return err
+		}
+	}
+}
+
+// errXSessionClosed is returned by dispatch when the connection should be authenticated again.
+var errXSessionClosed = errors.New("X protocol session closed")
+
+// dispatchWithCancel dispatches the message with a context which is cancelled by KILL.
+func (xc *xConn) dispatchWithCancel(ctx context.Context, tp byte, msg []byte) error {
+	ctx, cancelFunc := context.WithCancel(ctx)
+	defer cancelFunc()
+	xc.mu.Lock()
+	xc.mu.cancelFunc = cancelFunc
+	xc.mu.Unlock()
+	defer func() {
+		// Reset killed for each message, the session may be closed by the message.
+		if xc.ctx != nil {
+			atomic.StoreUint32(&xc.ctx.GetSessionVars().Killed, 0)
+		}
+	}()
+	return xc.dispatch(ctx, tp, msg)
+}
+
+func (xc *xConn) dispatch(ctx context.Context, tp byte, msg []byte) error {
+	switch tp {
+	case xClientSQLStmtExecute:
+		return xc.handleStmtExecute(ctx, msg)
+	case xClientCrudFind:
+		return xc.handleFind(ctx, msg)
+	case xClientCrudInsert:
+		return xc.handleInsert(ctx, msg)
+	case xClientCrudUpdate:
+		return errXNotSupported.GenWithStackByArgs("Crud.Update")
+	case xClientCrudDelete:
+		return errXNotSupported.GenWithStackByArgs("Crud.Delete")
+	case xClientSessReset:
+		return xc.handleSessReset(ctx, msg)
+	case xClientSessClose:
+		xc.closeSession()
+		if err := xc.writeMessage(xServerOk, nil); err != nil {
+			return err
+		}
+		return errXSessionClosed
+	case xClientConClose:
+		if err := xc.writeMessage(xServerOk, nil); err != nil {
+			return err
+		}
+		if err := xc.flush(); err != nil {
+			return err
+		}
+		return io.EOF
+	case xClientExpectOpen, xClientExpectClose:
+		// The expectations are accepted but not checked, every message is handled even if a previous one fails.
+		return xc.writeMessage(xServerOk, nil)
+	case xClientConCapabilitiesGet:
+		return xc.writeCapabilities()
+	}
+	return errXBadMessage.GenWithStackByArgs(fmt.Sprintf("unexpected message type %d", tp))
+}
+
+// handleSessReset resets the session, the user doesn't need to authenticate again if keep_open is set.
+func (xc *xConn) handleSessReset(ctx context.Context, msg []byte) error {
+	fields, err := decodeXFields(msg)
+	if err != nil {
+		return err
+	}
+	keepOpen := false
+	for _, f := range fields {
+		if f.num == 1 {
+			keepOpen = f.v != 0
+		}
+	}
+	if !keepOpen {
+		xc.closeSession()
+		if err = xc.writeMessage(xServerOk, nil); err != nil {
+			return err
+		}
+		return errXSessionClosed
+	}
+	user := xc.ctx.GetSessionVars().User
+	if err = xc.openSession(ctx, xc.dbname, func(string) error {
+		if !xc.ctx.AuthWithoutVerification(user) {
+			return errors.New("Could not reset session")
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return xc.writeMessage(xServerOk, nil)
+}
+
+// handleStmtExecute handles StmtExecute{stmt, args, namespace}, the namespace is "sql" by default,
+// "mysqlx" and the deprecated "xplugin" are the admin commands.
+func (xc *xConn) handleStmtExecute(ctx context.Context, msg []byte) error {
+	fields, err := decodeXFields(msg)
+	if err != nil {
+		return err
+	}
+	var stmt, namespace string
+	var args []*xAny
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			stmt = string(f.b)
+		case 2:
+			arg, err := decodeXAny(f.b)
+			if err != nil {
+				return err
+			}
+			args = append(args, arg)
+		case 3:
+			namespace = string(f.b)
+		}
+	}
+	switch namespace {
+	case "", "sql":
+		params := make([]types.Datum, 0, len(args))
+		for _, arg := range args {
+			if arg.tp != xAnyScalar {
+				return errXBadMessage.GenWithStackByArgs("the arguments of the SQL statement must be scalars")
+			}
+			params = append(params, types.NewDatum(arg.scalar.value()))
+		}
+		return xc.executeSQL(ctx, stmt, params)
+	case "mysqlx", "xplugin":
+		return xc.executeAdmin(ctx, stmt, args)
+	}
+	return errXNotSupported.GenWithStackByArgs("namespace " + namespace)
+}
+
+// executeSQL executes a statement and writes the result, the statement is prepared if there are params.
+func (xc *xConn) executeSQL(ctx context.Context, sql string, params []types.Datum) error {
+	return xc.executeSQLWithDocIDs(ctx, sql, params, nil)
+}
+
+// executeSQLWithDocIDs is the same as executeSQL, but also sends the ids of the inserted documents.
+func (xc *xConn) executeSQLWithDocIDs(ctx context.Context, sql string, params []types.Datum, docIDs []string) error {
+	var rs ResultSet
+	if len(params) > 0 {
+		stmt, _, _, err := xc.ctx.Prepare(sql)
+		if err != nil {
+			return err
+		}
+		defer terror.Call(stmt.Close)
+		if rs, err = stmt.Execute(ctx, params); err != nil {
+			return err
+		}
+	} else {
+		stmts, err := xc.ctx.Parse(ctx, sql)
+		if err != nil {
+			return err
+		}
+		if len(stmts) > 1 {
+			return errXNotSupported.GenWithStackByArgs("multiple statements")
+		}
+		if len(stmts) == 1 {
+			if rs, err = xc.ctx.ExecuteStmt(ctx, stmts[0]); err != nil {
+				return err
+			}
+		}
+	}
+	return xc.writeResult(ctx, rs, docIDs)
+}
+
+// writeResult writes the result set if there is one, then the warnings and the other notices, and StmtExecuteOk.
+func (xc *xConn) writeResult(ctx context.Context, rs ResultSet, docIDs []string) error {
+	if rs != nil {
+		defer terror.Call(rs.Close)
+		columns := rs.Columns()
+		for _, col := range columns {
+			if err := xc.writeMessage(xServerResultsetColumnMetaData, encodeXColumnMetaData(col)); err != nil {
+				return err
+			}
+		}
+		req := rs.NewChunk(nil)
+		for {
+			if err := rs.Next(ctx, req); err != nil {
+				return err
+			}
+			if req.NumRows() == 0 {
+				break
+			}
+			for i := 0; i < req.NumRows(); i++ {
+				row, err := encodeXRow(columns, req.GetRow(i))
+				if err != nil {
+					return err
+				}
+				if err = xc.writeMessage(xServerResultsetRow, row); err != nil {
+					return err
+				}
+			}
+		}
+		if err := xc.writeMessage(xServerResultsetFetchDone, nil); err != nil {
+			return err
+		}
+	}
+	for _, w := range xc.ctx.GetWarnings() {
+		var level uint64
+		switch w.Level {
+		case stmtctx.WarnLevelNote:
+			level = 1
+		case stmtctx.WarnLevelWarning:
+			level = 2
+		default:
+			level = 3
+		}
+		m := toSQLError(w.Err)
+		if err := xc.writeMessage(xServerNotice, encodeXNotice(xNoticeWarning, encodeXWarning(level, m.Code, m.Message))); err != nil {
+			return err
+		}
+	}
+	if rs == nil {
+		notice := encodeXSessionStateChanged(xStateRowsAffected, encodeXScalarUInt(xc.ctx.AffectedRows()))
+		if err := xc.writeMessage(xServerNotice, encodeXNotice(xNoticeSessionStateChanged, notice)); err != nil {
+			return err
+		}
+		if id := xc.ctx.LastInsertID(); id > 0 {
+			notice = encodeXSessionStateChanged(xStateGeneratedInsertID, encodeXScalarUInt(id))
+			if err := xc.writeMessage(xServerNotice, encodeXNotice(xNoticeSessionStateChanged, notice)); err != nil {
+				return err
+			}
+		}
+	}
+	if len(docIDs) > 0 {
+		ids := make([][]byte, 0, len(docIDs))
+		for _, id := range docIDs {
+			ids = append(ids, encodeXScalarOctets(id))
+		}
+		notice := encodeXSessionStateChanged(xStateGeneratedDocumentIDs, ids...)
+		if err := xc.writeMessage(xServerNotice, encodeXNotice(xNoticeSessionStateChanged, notice)); err != nil {
+			return err
+		}
+	}
+	return xc.writeMessage(xServerSQLStmtExecuteOk, nil)
+}
+
+// shutdownOrNotify is the same as clientConn.ShutdownOrNotify.
+func (xc *xConn) shutdownOrNotify() bool {
+	if (xc.ctx.Status() & mysql.ServerStatusInTrans) > 0 {
+		return false
+	}
+	if atomic.CompareAndSwapInt32(&xc.status, connStatusReading, connStatusShutdown) {
+		return true
+	}
+	atomic.StoreInt32(&xc.status, connStatusWaitShutdown)
+	return false
+}
+
+func killXConn(xc *xConn) {
+	atomic.StoreUint32(&xc.ctx.GetSessionVars().Killed, 1)
+	xc.mu.RLock()
+	cancelFunc := xc.mu.cancelFunc
+	xc.mu.RUnlock()
+	if cancelFunc != nil {
+		cancelFunc()
+	}
+}
+
+func (xc *xConn) writeMessage(tp byte, msg []byte) error {
+	return writeXMessage(xc.bufWriter, tp, msg)
+}
+
+func (xc *xConn) writeError(e error) error {
+	m := toSQLError(e)
+	if xc.ctx != nil && xc.ctx.GetSessionVars().EnableStableSQLState {
+		m.State = errno.SQLState(m.Code)
+	}
+	return xc.writeMessage(xServerError, encodeXError(false, m.Code, m.Message, m.State))
+}
+
+func (xc *xConn) flush() error {
+	return errors.Trace(xc.bufWriter.Flush())
+}
@@ -0,0 +1,706 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/types/json"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+// The collections of the document store are the tables of a JSON column doc and a primary key _id
+// generated from doc, the CRUD messages are translated to the SQL statements of the tables.
+
+// xDocIDStartTime and xDocIDCounter generate the ids of the inserted documents without _id.
+var (
+	xDocIDStartTime = uint32(time.Now().Unix())
+	xDocIDCounter   uint64
+)
+
+var xFuncNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// The binary operators of the X Protocol and the SQL operators.
+var xBinaryOperators = map[string]string{
+	"==":         "=",
+	"!=":         "!=",
+	"<":          "<",
+	">":          ">",
+	"<=":         "<=",
+	">=":         ">=",
+	"&&":         "AND",
+	"||":         "OR",
+	"xor":        "XOR",
+	"+":          "+",
+	"-":          "-",
+	"*":          "*",
+	"/":          "/",
+	"div":        "DIV",
+	"%":          "%",
+	"&":          "&",
+	"|":          "|",
+	"^":          "^",
+	"<<":         "<<",
+	">>":         ">>",
+	"is":         "IS",
+	"is_not":     "IS NOT",
+	"like":       "LIKE",
+	"not_like":   "NOT LIKE",
+	"regexp":     "REGEXP",
+	"not_regexp": "NOT REGEXP",
+}
+
+// The unary operators of the X Protocol and the SQL operators.
+var xUnaryOperators = map[string]string{
+	"not":        "NOT ",
+	"!":          "NOT ",
+	"~":          "~",
+	"sign_minus": "-",
+	"sign_plus":  "+",
+}
+
+// xExprBuilder builds the SQL of the expressions, the identifiers without the column names are
+// the paths of the documents, args are the values of the placeholders.
+type xExprBuilder struct {
+	sql  strings.Builder
+	args []*xScalar
+}
+
+func (b *xExprBuilder) build(e *xExpr) error {
+	switch e.tp {
+	case xExprIdent:
+		b.buildIdent(e)
+	case xExprLiteral:
+		b.buildScalar(e.literal)
+	case xExprPlaceholder:
+		if e.position >= uint64(len(b.args)) {
+			return errXBadMessage.GenWithStackByArgs(fmt.Sprintf("placeholder %d is out of range", e.position))
+		}
+		b.buildScalar(b.args[e.position])
+	case xExprFuncCall:
+		if !xFuncNameRegexp.MatchString(e.name) {
+			return errXBadMessage.GenWithStackByArgs("invalid function name " + e.name)
+		}
+		if e.schema != "" {
+			sqlexec.MustFormatSQL(&b.sql, "%n.", e.schema)
+		}
+		b.sql.WriteString(e.name)
+		return b.buildList("(", e.params, ")")
+	case xExprOperator:
+		return b.buildOperator(e)
+	case xExprObject:
+		b.sql.WriteString("JSON_OBJECT(")
+		for i, key := range e.keys {
+			if i > 0 {
+				b.sql.WriteString(", ")
+			}
+			sqlexec.MustFormatSQL(&b.sql, "%?, ", key)
+			if err := b.build(e.params[i]); err != nil {
+				return err
+			}
+		}
+		b.sql.WriteString(")")
+	case xExprArray:
+		return b.buildList("JSON_ARRAY(", e.params, ")")
+	default:
+		return errXNotSupported.GenWithStackByArgs(fmt.Sprintf("expression type %d", e.tp))
+	}
+	return nil
+}
+
+func (b *xExprBuilder) buildList(prefix string, params []*xExpr, suffix string) error {
+	b.sql.WriteString(prefix)
+	for i, param := range params {
+		if i > 0 {
+			b.sql.WriteString(", ")
+		}
+		if err := b.build(param); err != nil {
+			return err
+		}
+	}
+	b.sql.WriteString(suffix)
+	return nil
+}
+
+func (b *xExprBuilder) buildIdent(e *xExpr) {
+	if len(e.path) == 0 {
+		if e.table != "" {
+			sqlexec.MustFormatSQL(&b.sql, "%n.", e.table)
+		}
+		sqlexec.MustFormatSQL(&b.sql, "%n", e.name)
+		return
+	}
+	column := e.name
+	if column == "" {
+		column = "doc"
+	}
+	sqlexec.MustFormatSQL(&b.sql, "JSON_EXTRACT(%n, %?)", column, xDocPath(e.path))
+}
+
+// xDocPath returns the JSON path of the document path items.
+func xDocPath(items []xDocPathItem) string {
+	var sb strings.Builder
+	sb.WriteString("$")
+	for _, item := range items {
+		switch item.tp {
+		case xPathMember:
+			sb.WriteString(".")
+			if xFuncNameRegexp.MatchString(item.value) {
+				sb.WriteString(item.value)
+			} else {
+				sb.WriteString(json.CreateBinary(item.value).String())
+			}
+		case xPathMemberAsterisk:
+			sb.WriteString(".*")
+		case xPathArrayIndex:
+			fmt.Fprintf(&sb, "[%d]", item.index)
+		case xPathArrayIndexAsterisk:
+			sb.WriteString("[*]")
+		case xPathDoubleAsterisk:
+			sb.WriteString("**")
+		}
+	}
+	return sb.String()
+}
+
+func (b *xExprBuilder) buildScalar(s *xScalar) {
+	if s.tp == xScalarOctets && s.contentType == xContentTypeJSON {
+		sqlexec.MustFormatSQL(&b.sql, "CAST(%? AS JSON)", string(s.octets))
+		return
+	}
+	sqlexec.MustFormatSQL(&b.sql, "%?", s.value())
+}
+
+// buildJSON builds the expression as a JSON value, the strings are quoted rather than parsed.
+func (b *xExprBuilder) buildJSON(e *xExpr) error {
+	if e.tp == xExprPlaceholder && e.position < uint64(len(b.args)) {
+		e = &xExpr{tp: xExprLiteral, literal: b.args[e.position]}
+	}
+	switch {
+	case e.tp == xExprIdent && len(e.path) > 0, e.tp == xExprObject, e.tp == xExprArray:
+		return b.build(e)
+	case e.tp == xExprLiteral && (e.literal.tp == xScalarString || e.literal.tp == xScalarOctets) && e.literal.contentType != xContentTypeJSON:
+		sqlexec.MustFormatSQL(&b.sql, "JSON_QUOTE(%?)", string(e.literal.octets))
+		return nil
+	}
+	b.sql.WriteString("CAST(")
+	if err := b.build(e); err != nil {
+		return err
+	}
+	b.sql.WriteString(" AS JSON)")
+	return nil
+}
+
+// buildUnquoted builds the expression, the document paths are unquoted for the string operators.
+func (b *xExprBuilder) buildUnquoted(e *xExpr) error {
+	if e.tp != xExprIdent || len(e.path) == 0 {
+		return b.build(e)
+	}
+	b.sql.WriteString("JSON_UNQUOTE(")
+	b.buildIdent(e)
+	b.sql.WriteString(")")
+	return nil
+}
+
+func (b *xExprBuilder) buildOperator(e *xExpr) error {
+	name := strings.ToLower(e.name)
+	checkParams := func(n int) error {
+		if len(e.params) != n {
+			return errXBadMessage.GenWithStackByArgs(fmt.Sprintf("operator %s takes %d parameters", e.name, n))
+		}
+		return nil
+	}
+	if op, ok := xBinaryOperators[name]; ok {
+		if err := checkParams(2); err != nil {
+			return err
+		}
+		build := b.build
+		switch name {
+		case "like", "not_like", "regexp", "not_regexp":
+			build = b.buildUnquoted
+		}
+		b.sql.WriteString("(")
+		if err := build(e.params[0]); err != nil {
+			return err
+		}
+		b.sql.WriteString(" " + op + " ")
+		if err := build(e.params[1]); err != nil {
+			return err
+		}
+		b.sql.WriteString(")")
+		return nil
+	}
+	switch name {
+	case "not", "!", "~", "sign_minus", "sign_plus":
+		if err := checkParams(1); err != nil {
+			return err
+		}
+		b.sql.WriteString("(" + xUnaryOperators[name])
+		if err := b.build(e.params[0]); err != nil {
+			return err
+		}
+		b.sql.WriteString(")")
+	case "in", "not_in":
+		if len(e.params) < 2 {
+			return errXBadMessage.GenWithStackByArgs(fmt.Sprintf("operator %s takes at least 2 parameters", e.name))
+		}
+		b.sql.WriteString("(")
+		if err := b.build(e.params[0]); err != nil {
+			return err
+		}
+		if name == "not_in" {
+			b.sql.WriteString(" NOT")
+		}
+		if err := b.buildList(" IN (", e.params[1:], "))"); err != nil {
+			return err
+		}
+	case "between", "not_between":
+		if err := checkParams(3); err != nil {
+			return err
+		}
+		b.sql.WriteString("(")
+		if err := b.build(e.params[0]); err != nil {
+			return err
+		}
+		if name == "not_between" {
+			b.sql.WriteString(" NOT")
+		}
+		b.sql.WriteString(" BETWEEN ")
+		if err := b.build(e.params[1]); err != nil {
+			return err
+		}
+		b.sql.WriteString(" AND ")
+		if err := b.build(e.params[2]); err != nil {
+			return err
+		}
+		b.sql.WriteString(")")
+	case "cont_in", "not_cont_in":
+		// a cont_in b means a is contained in b.
+		if err := checkParams(2); err != nil {
+			return err
+		}
+		if name == "not_cont_in" {
+			b.sql.WriteString("NOT ")
+		}
+		b.sql.WriteString("JSON_CONTAINS(")
+		if err := b.buildJSON(e.params[1]); err != nil {
+			return err
+		}
+		b.sql.WriteString(", ")
+		if err := b.buildJSON(e.params[0]); err != nil {
+			return err
+		}
+		b.sql.WriteString(")")
+	default:
+		return errXNotSupported.GenWithStackByArgs("operator " + e.name)
+	}
+	return nil
+}
+
+// xCollection returns the schema and the name of the collection, the schema is the current database by default.
+func (xc *xConn) xCollection(msg []byte) (string, string, error) {
+	name, schema, err := decodeXCollection(msg)
+	if err != nil {
+		return "", "", err
+	}
+	if schema == "" {
+		schema = xc.ctx.CurrentDB()
+	}
+	if schema == "" {
+		return "", "", plannercore.ErrNoDB
+	}
+	return schema, name, nil
+}
+
+// handleFind translates Crud.Find of a collection to SELECT.
+func (xc *xConn) handleFind(ctx context.Context, msg []byte) error {
+	fields, err := decodeXFields(msg)
+	if err != nil {
+		return err
+	}
+	b := &xExprBuilder{}
+	var (
+		schema, table  string
+		dataModel      uint64 = xDataModelDocument
+		projection     [][]byte
+		criteria       *xExpr
+		order          [][]byte
+		limit          []byte
+		lock, lockOpts uint64
+	)
+	for _, f := range fields {
+		switch f.num {
+		case 2:
+			if schema, table, err = xc.xCollection(f.b); err != nil {
+				return err
+			}
+		case 3:
+			dataModel = f.v
+		case 4:
+			projection = append(projection, f.b)
+		case 5:
+			if criteria, err = decodeXExpr(f.b); err != nil {
+				return err
+			}
+		case 6:
+			limit = f.b
+		case 7:
+			order = append(order, f.b)
+		case 8, 9:
+			return errXNotSupported.GenWithStackByArgs("grouping of Crud.Find")
+		case 11:
+			arg, err := decodeXScalar(f.b)
+			if err != nil {
+				return err
+			}
+			b.args = append(b.args, arg)
+		case 12:
+			lock = f.v
+		case 13:
+			lockOpts = f.v
+		}
+	}
+	if dataModel != xDataModelDocument {
+		return errXNotSupported.GenWithStackByArgs("the table data model")
+	}
+
+	b.sql.WriteString("SELECT ")
+	if err = b.buildProjection(projection); err != nil {
+		return err
+	}
+	sqlexec.MustFormatSQL(&b.sql, " FROM %n.%n", schema, table)
+	if criteria != nil {
+		b.sql.WriteString(" WHERE ")
+		if err = b.build(criteria); err != nil {
+			return err
+		}
+	}
+	for i, o := range order {
+		if i == 0 {
+			b.sql.WriteString(" ORDER BY ")
+		} else {
+			b.sql.WriteString(", ")
+		}
+		if err = b.buildOrder(o); err != nil {
+			return err
+		}
+	}
+	if limit != nil {
+		if err = b.buildLimit(limit); err != nil {
+			return err
+		}
+	}
+	switch lock {
+	case 0:
+	case 2:
+		b.sql.WriteString(" FOR UPDATE")
+		switch lockOpts {
+		case 1:
+			b.sql.WriteString(" NOWAIT")
+		case 2:
+			b.sql.WriteString(" SKIP LOCKED")
+		}
+	default:
+		return errXNotSupported.GenWithStackByArgs("shared lock of Crud.Find")
+	}
+	return xc.executeSQL(ctx, b.sql.String(), nil)
+}
+
+// buildProjection builds the documents of Projection{source, alias}, the whole documents are returned by default.
+func (b *xExprBuilder) buildProjection(projection [][]byte) error {
+	if len(projection) == 0 {
+		b.sql.WriteString("doc")
+		return nil
+	}
+	b.sql.WriteString("JSON_OBJECT(")
+	for i, p := range projection {
+		fields, err := decodeXFields(p)
+		if err != nil {
+			return err
+		}
+		var source *xExpr
+		var alias string
+		for _, f := range fields {
+			switch f.num {
+			case 1:
+				if source, err = decodeXExpr(f.b); err != nil {
+					return err
+				}
+			case 2:
+				alias = string(f.b)
+			}
+		}
+		if source == nil {
+			return errXBadMessage.GenWithStackByArgs("source of projection is missing")
+		}
+		if alias == "" {
+			if n := len(source.path); n > 0 && source.path[n-1].tp == xPathMember {
+				alias = source.path[n-1].value
+			} else {
+				return errXBadMessage.GenWithStackByArgs("alias of projection is missing")
+			}
+		}
+		if i > 0 {
+			b.sql.WriteString(", ")
+		}
+		sqlexec.MustFormatSQL(&b.sql, "%?, ", alias)
+		if err = b.build(source); err != nil {
+			return err
+		}
+	}
+	b.sql.WriteString(") AS doc")
+	return nil
+}
+
+// buildOrder builds Order{expr, direction}, the direction is ASC=1 or DESC=2.
+func (b *xExprBuilder) buildOrder(msg []byte) error {
+	fields, err := decodeXFields(msg)
+	if err != nil {
+		return err
+	}
+	var expr *xExpr
+	desc := false
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			if expr, err = decodeXExpr(f.b); err != nil {
+				return err
+			}
+		case 2:
+			desc = f.v == 2
+		}
+	}
+	if expr == nil {
+		return errXBadMessage.GenWithStackByArgs("expression of order is missing")
+	}
+	if err = b.build(expr); err != nil {
+		return err
+	}
+	if desc {
+		b.sql.WriteString(" DESC")
+	}
+	return nil
+}
+
+// buildLimit builds Limit{row_count, offset}.
+func (b *xExprBuilder) buildLimit(msg []byte) error {
+	fields, err := decodeXFields(msg)
+	if err != nil {
+		return err
+	}
+	var count, offset uint64
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			count = f.v
+		case 2:
+			offset = f.v
+		}
+	}
+	sqlexec.MustFormatSQL(&b.sql, " LIMIT %?, %?", offset, count)
+	return nil
+}
+
+// handleInsert translates Crud.Insert of a collection to INSERT, the documents without _id are given the
+// generated ids, which are sent to the client.
+func (xc *xConn) handleInsert(ctx context.Context, msg []byte) error {
+	fields, err := decodeXFields(msg)
+	if err != nil {
+		return err
+	}
+	b := &xExprBuilder{}
+	var (
+		schema, table string
+		dataModel     uint64 = xDataModelDocument
+		rows          [][]byte
+		upsert        bool
+	)
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			if schema, table, err = xc.xCollection(f.b); err != nil {
+				return err
+			}
+		case 2:
+			dataModel = f.v
+		case 4:
+			rows = append(rows, f.b)
+		case 5:
+			arg, err := decodeXScalar(f.b)
+			if err != nil {
+				return err
+			}
+			b.args = append(b.args, arg)
+		case 6:
+			upsert = f.v != 0
+		}
+	}
+	if dataModel != xDataModelDocument {
+		return errXNotSupported.GenWithStackByArgs("the table data model")
+	}
+	if len(rows) == 0 {
+		return errXBadMessage.GenWithStackByArgs("no documents to insert")
+	}
+
+	sqlexec.MustFormatSQL(&b.sql, "INSERT INTO %n.%n (doc) VALUES ", schema, table)
+	var docIDs []string
+	for i, row := range rows {
+		// TypedRow{field}, a document is a row of one field.
+		rowFields, err := decodeXFields(row)
+		if err != nil {
+			return err
+		}
+		if len(rowFields) != 1 {
+			return errXBadMessage.GenWithStackByArgs("a document must be a row of one field")
+		}
+		doc, err := decodeXExpr(rowFields[0].b)
+		if err != nil {
+			return err
+		}
+		if doc.tp == xExprPlaceholder {
+			if doc.position >= uint64(len(b.args)) {
+				return errXBadMessage.GenWithStackByArgs(fmt.Sprintf("placeholder %d is out of range", doc.position))
+			}
+			doc = &xExpr{tp: xExprLiteral, literal: b.args[doc.position]}
+		}
+		id, err := xc.ensureDocID(doc)
+		if err != nil {
+			return err
+		}
+		if id != "" {
+			docIDs = append(docIDs, id)
+		}
+		if i > 0 {
+			b.sql.WriteString(", ")
+		}
+		b.sql.WriteString("(")
+		if err = b.buildJSON(doc); err != nil {
+			return err
+		}
+		b.sql.WriteString(")")
+	}
+	if upsert {
+		b.sql.WriteString(" ON DUPLICATE KEY UPDATE doc = VALUES(doc)")
+	}
+	return xc.executeSQLWithDocIDs(ctx, b.sql.String(), nil, docIDs)
+}
+
+// ensureDocID adds the generated _id to the document if it doesn't have one, and returns the generated id.
+func (xc *xConn) ensureDocID(doc *xExpr) (string, error) {
+	switch doc.tp {
+	case xExprObject:
+		for _, key := range doc.keys {
+			if key == "_id" {
+				return "", nil
+			}
+		}
+		id := xc.generateDocID()
+		doc.keys = append(doc.keys, "_id")
+		doc.params = append(doc.params, &xExpr{tp: xExprLiteral, literal: &xScalar{tp: xScalarString, octets: []byte(id)}})
+		return id, nil
+	case xExprLiteral:
+		if doc.literal.tp != xScalarString && doc.literal.tp != xScalarOctets {
+			break
+		}
+		bj, err := json.ParseBinaryFromString(string(doc.literal.octets))
+		if err != nil {
+			return "", err
+		}
+		if bj.TypeCode != json.TypeCodeObject {
+			break
+		}
+		path, err := json.ParseJSONPathExpr("$._id")
+		if err != nil {
+			return "", err
+		}
+		var id string
+		if _, found := bj.Extract([]json.PathExpression{path}); !found {
+			id = xc.generateDocID()
+			if bj, err = bj.Modify([]json.PathExpression{path}, []json.BinaryJSON{json.CreateBinary(id)}, json.ModifyInsert); err != nil {
+				return "", err
+			}
+		}
+		// The documents in strings are inserted as JSON rather than JSON strings.
+		doc.literal = &xScalar{tp: xScalarOctets, octets: []byte(bj.String()), contentType: xContentTypeJSON}
+		return id, nil
+	}
+	return "", errXBadMessage.GenWithStackByArgs("a document must be an object")
+}
+
+// generateDocID generates the id in the same format as MySQL: 4 hex digits of the prefix, 8 hex digits
+// of the start time and 16 hex digits of the counter.
+func (xc *xConn) generateDocID() string {
+	return fmt.Sprintf("%04x%08x%016x", uint16(xc.server.globalConnID.ServerID), xDocIDStartTime, atomic.AddUint64(&xDocIDCounter, 1))
+}
+
+// executeAdmin executes the admin commands of the mysqlx namespace, the arguments are an object
+// of the named arguments, or the positional scalars of the deprecated xplugin namespace.
+func (xc *xConn) executeAdmin(ctx context.Context, cmd string, args []*xAny) error {
+	arg := func(key string, pos int) string {
+		if len(args) == 1 && args[0].tp == xAnyObject {
+			s, _ := args[0].field(key).str()
+			return s
+		}
+		if pos < len(args) {
+			s, _ := args[pos].str()
+			return s
+		}
+		return ""
+	}
+	var sql strings.Builder
+	switch cmd {
+	case "ping":
+		return xc.writeMessage(xServerSQLStmtExecuteOk, nil)
+	case "create_collection", "ensure_collection", "drop_collection":
+		schema, name := arg("schema", 0), arg("name", 1)
+		if schema == "" {
+			schema = xc.ctx.CurrentDB()
+		}
+		if name == "" {
+			return errXBadMessage.GenWithStackByArgs("collection name is missing")
+		}
+		switch cmd {
+		case "create_collection", "ensure_collection":
+			sql.WriteString("CREATE TABLE ")
+			if cmd == "ensure_collection" {
+				sql.WriteString("IF NOT EXISTS ")
+			}
+			sqlexec.MustFormatSQL(&sql, "%n.%n (doc JSON, _id VARBINARY(32) GENERATED ALWAYS AS (JSON_UNQUOTE(JSON_EXTRACT(doc, '$._id'))) STORED PRIMARY KEY)", schema, name)
+		default:
+			sqlexec.MustFormatSQL(&sql, "DROP TABLE %n.%n", schema, name)
+		}
+	case "list_objects":
+		schema, pattern := arg("schema", 0), arg("pattern", 1)
+		if schema == "" {
+			schema = xc.ctx.CurrentDB()
+		}
+		if pattern == "" {
+			pattern = "%"
+		}
+		sqlexec.MustFormatSQL(&sql, "SELECT t.table_name AS name, CASE WHEN t.table_type = 'VIEW' THEN 'VIEW' WHEN c.cnt = 2 THEN 'COLLECTION' ELSE 'TABLE' END AS type "+
+			"FROM information_schema.tables t LEFT JOIN (SELECT table_name, COUNT(*) AS cnt FROM information_schema.columns "+
+			"WHERE table_schema = %? AND column_name IN ('doc', '_id') GROUP BY table_name) c ON t.table_name = c.table_name "+
+			"WHERE t.table_schema = %? AND t.table_name LIKE %? ORDER BY name", schema, schema, pattern)
+	default:
+		return errXNotSupported.GenWithStackByArgs("admin command " + cmd)
+	}
+	return xc.executeSQL(ctx, sql.String(), nil)
+}
@@ -0,0 +1,326 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/session"
+	"github.com/pingcap/tidb/store/mockstore"
+	"github.com/stretchr/testify/require"
+)
+
+type xTestClient struct {
+	t    *testing.T
+	conn net.Conn
+}
+
+func (c *xTestClient) send(tp byte, msg []byte) {
+	require.NoError(c.t, writeXMessage(c.conn, tp, msg))
+}
+
+// recv returns the next message which isn't a notice unless the notices are wanted.
+func (c *xTestClient) recv(notices bool) (byte, []xField) {
+	for {
+		tp, msg, err := readXMessage(c.conn)
+		require.NoError(c.t, err)
+		if tp == xServerNotice && !notices {
+			continue
+		}
+		fields, err := decodeXFields(msg)
+		require.NoError(c.t, err)
+		return tp, fields
+	}
+}
+
+func (c *xTestClient) expect(tp byte) []xField {
+	got, fields := c.recv(false)
+	if got == xServerError && tp != xServerError {
+		require.FailNow(c.t, "unexpected error", "%v", fields)
+	}
+	require.Equal(c.t, tp, got)
+	return fields
+}
+
+// rows reads a result set and returns the fields of the rows.
+func (c *xTestClient) rows() [][]string {
+	var rows [][]string
+	for {
+		tp, fields := c.recv(false)
+		switch tp {
+		case xServerResultsetColumnMetaData:
+		case xServerResultsetRow:
+			row := make([]string, 0, len(fields))
+			for _, f := range fields {
+				row = append(row, strings.TrimSuffix(string(f.b), "\x00"))
+			}
+			rows = append(rows, row)
+		case xServerResultsetFetchDone:
+			c.expect(xServerSQLStmtExecuteOk)
+			return rows
+		default:
+			require.FailNow(c.t, "unexpected message", "%d %v", tp, fields)
+		}
+	}
+}
+
+func (c *xTestClient) admin(cmd string, keys []string, values ...string) {
+	var obj []byte
+	for i, key := range keys {
+		fld := appendXString(nil, 1, key)
+		obj = appendXBytes(obj, 1, appendXBytes(fld, 2, encodeXAnyScalar(encodeXScalarString(values[i]))))
+	}
+	args := appendXBytes(appendXVarint(nil, 1, xAnyObject), 3, obj)
+	msg := appendXString(nil, 1, cmd)
+	msg = appendXBytes(msg, 2, args)
+	c.send(xClientSQLStmtExecute, appendXString(msg, 3, "mysqlx"))
+}
+
+func (c *xTestClient) authenticate(dbname string) {
+	c.send(xClientSessAuthenticateStart, appendXString(nil, 1, xAuthMySQL41))
+	c.expect(xServerSessAuthenticateContinue)
+	c.send(xClientSessAuthenticateContinue, appendXString(nil, 1, dbname+"\x00root\x00"))
+	c.expect(xServerSessAuthenticateOk)
+}
+
+// newXTestServer runs a server with the X Protocol listener, clean closes the server and the store.
+func newXTestServer(t *testing.T, maxConns uint32) (server *Server, clean func()) {
+	store, err := mockstore.NewMockStore()
+	require.NoError(t, err)
+	session.DisableStats4Test()
+	dom, err := session.BootstrapSession(store)
+	require.NoError(t, err)
+
+	cfg := newTestConfig()
+	cfg.Port = 0
+	cfg.Status.ReportStatus = false
+	cfg.MaxServerConnections = maxConns
+	cfg.XProtocol.Enable = true
+	cfg.XProtocol.Port = 0
+	server, err = NewServer(cfg, NewTiDBDriver(store))
+	require.NoError(t, err)
+	go func() {
+		require.NoError(t, server.Run())
+	}()
+	return server, func() {
+		server.Close()
+		dom.Close()
+		require.NoError(t, store.Close())
+	}
+}
+
+func TestXProtocol(t *testing.T) {
+	server, clean := newXTestServer(t, 0)
+	defer clean()
+
+	conn, err := net.Dial("tcp", server.xListener.Addr().String())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, conn.Close())
+	}()
+	c := &xTestClient{t: t, conn: conn}
+
+	c.send(xClientConCapabilitiesGet, nil)
+	fields := c.expect(xServerConCapabilities)
+	require.Contains(t, string(fields[0].b), "authentication.mechanisms")
+
+	capability := appendXString(nil, 1, "tls")
+	capability = appendXBytes(capability, 2, encodeXAnyBool(true))
+	c.send(xClientConCapabilitiesSet, appendXBytes(nil, 1, appendXBytes(nil, 1, capability)))
+	fields = c.expect(xServerError)
+	require.Equal(t, uint64(5001), fields[0].v)
+
+	c.send(xClientSessAuthenticateStart, appendXString(nil, 1, "PLAIN"))
+	c.expect(xServerError)
+	c.send(xClientSessAuthenticateStart, appendXString(nil, 1, xAuthMySQL41))
+	fields = c.expect(xServerSessAuthenticateContinue)
+	require.Len(t, fields[0].b, 20)
+	c.send(xClientSessAuthenticateContinue, appendXString(nil, 1, "test\x00root\x00"))
+	tp, fields := c.recv(true)
+	require.Equal(t, byte(xServerNotice), tp)
+	require.Equal(t, uint64(xNoticeSessionStateChanged), fields[0].v)
+	c.expect(xServerSessAuthenticateOk)
+
+	// SQL statements.
+	c.send(xClientSQLStmtExecute, appendXString(nil, 1, "select 1, 'a', null"))
+	rows := c.rows()
+	require.Len(t, rows, 1)
+	require.Equal(t, []string{"\x02", "a", ""}, rows[0])
+	stmt := appendXString(nil, 1, "select concat(?, 'b')")
+	c.send(xClientSQLStmtExecute, appendXBytes(stmt, 2, encodeXAnyScalar(encodeXScalarString("a"))))
+	require.Equal(t, [][]string{{"ab"}}, c.rows())
+	c.send(xClientSQLStmtExecute, appendXString(nil, 1, "select * from no_such_table"))
+	fields = c.expect(xServerError)
+	require.Equal(t, uint64(1146), fields[0].v)
+	c.send(xClientSQLStmtExecute, appendXString(nil, 1, "select 1; select 2"))
+	c.expect(xServerError)
+
+	// Collections.
+	c.admin("create_collection", []string{"schema", "name"}, "test", "c")
+	c.expect(xServerSQLStmtExecuteOk)
+	c.admin("ensure_collection", []string{"schema", "name"}, "test", "c")
+	c.expect(xServerSQLStmtExecuteOk)
+	c.admin("list_objects", []string{"schema"}, "test")
+	require.Equal(t, [][]string{{"c", "COLLECTION"}}, c.rows())
+
+	collection := appendXString(appendXString(nil, 1, "c"), 2, "test")
+	doc1 := encodeXObjectExpr([]string{"name", "age"}, [][]byte{encodeXLiteralExpr(encodeXScalarString("a")), encodeXLiteralExpr(encodeXScalarUInt(10))})
+	doc2 := encodeXLiteralExpr(encodeXScalarString(`{"_id": "2", "name": "b", "age": 20}`))
+	insert := appendXBytes(nil, 1, collection)
+	insert = appendXVarint(insert, 2, xDataModelDocument)
+	insert = appendXBytes(insert, 4, appendXBytes(nil, 1, doc1))
+	insert = appendXBytes(insert, 4, appendXBytes(nil, 1, doc2))
+	c.send(xClientCrudInsert, insert)
+	var docIDs []string
+	for {
+		tp, fields := c.recv(true)
+		if tp == xServerSQLStmtExecuteOk {
+			break
+		}
+		require.Equal(t, byte(xServerNotice), tp)
+		state, err := decodeXFields(fields[2].b)
+		require.NoError(t, err)
+		if state[0].v == xStateGeneratedDocumentIDs {
+			for _, v := range state[1:] {
+				scalar, err := decodeXScalar(v.b)
+				require.NoError(t, err)
+				docIDs = append(docIDs, string(scalar.octets))
+			}
+		}
+	}
+	require.Len(t, docIDs, 1)
+	require.Len(t, docIDs[0], 28)
+
+	find := appendXBytes(nil, 2, collection)
+	find = appendXBytes(find, 5, encodeXOperatorExpr(">", encodeXIdentExpr("", "age"), encodeXPlaceholderExpr(0)))
+	find = appendXBytes(find, 11, encodeXScalarUInt(15))
+	c.send(xClientCrudFind, find)
+	require.Equal(t, [][]string{{`{"_id": "2", "age": 20, "name": "b"}`}}, c.rows())
+
+	projection := appendXBytes(nil, 1, encodeXIdentExpr("", "name"))
+	order := appendXBytes(nil, 1, encodeXIdentExpr("", "age"))
+	find = appendXBytes(nil, 2, collection)
+	find = appendXBytes(find, 4, projection)
+	find = appendXBytes(find, 6, appendXVarint(nil, 1, 1))
+	find = appendXBytes(find, 7, appendXVarint(order, 2, 2))
+	c.send(xClientCrudFind, find)
+	require.Equal(t, [][]string{{`{"name": "b"}`}}, c.rows())
+
+	c.send(xClientCrudDelete, appendXBytes(nil, 1, collection))
+	c.expect(xServerError)
+	c.admin("drop_collection", []string{"schema", "name"}, "test", "c")
+	c.expect(xServerSQLStmtExecuteOk)
+
+	// The session can be reset and authenticated again after Sess.Close.
+	c.send(xClientSessReset, appendXVarint(nil, 1, 1))
+	c.expect(xServerOk)
+	c.send(xClientSQLStmtExecute, appendXString(nil, 1, "select database()"))
+	require.Equal(t, [][]string{{"test"}}, c.rows())
+	c.send(xClientSessClose, nil)
+	c.expect(xServerOk)
+	c.send(xClientSQLStmtExecute, appendXString(nil, 1, "select 1"))
+	c.expect(xServerError)
+	c.authenticate("")
+	c.send(xClientConClose, nil)
+	c.expect(xServerOk)
+}
+
+func TestXProtocolRegistered(t *testing.T) {
+	server, clean := newXTestServer(t, 1)
+	defer clean()
+
+	dial := func() *xTestClient {
+		conn, err := net.Dial("tcp", server.xListener.Addr().String())
+		require.NoError(t, err)
+		return &xTestClient{t: t, conn: conn}
+	}
+	c := dial()
+	defer func() {
+		require.NoError(t, c.conn.Close())
+	}()
+	c.authenticate("test")
+	c.send(xClientSQLStmtExecute, appendXString(nil, 1, "select 1"))
+	require.Len(t, c.rows(), 1)
+
+	// The connection is shown in the process list and counted by max-server-connections.
+	require.Equal(t, 1, server.ConnectionCount())
+	processes := server.ShowProcessList()
+	require.Len(t, processes, 1)
+	var id uint64
+	for _, pi := range processes {
+		id = pi.ID
+	}
+	pi, ok := server.GetProcessInfo(id)
+	require.True(t, ok)
+	require.Equal(t, "root", pi.User)
+	require.Equal(t, "test", pi.DB)
+
+	c2 := dial()
+	defer func() {
+		require.NoError(t, c2.conn.Close())
+	}()
+	c2.send(xClientSessAuthenticateStart, appendXString(nil, 1, xAuthMySQL41))
+	c2.expect(xServerSessAuthenticateContinue)
+	c2.send(xClientSessAuthenticateContinue, appendXString(nil, 1, "test\x00root\x00"))
+	fields := c2.expect(xServerError)
+	require.Equal(t, uint64(1040), fields[0].v)
+	require.Equal(t, 1, server.ConnectionCount())
+
+	// KILL closes the connection before it handles the next message.
+	server.Kill(id, false)
+	require.NoError(t, writeXMessage(c.conn, xClientSQLStmtExecute, appendXString(nil, 1, "select 1")))
+	_, _, err := readXMessage(c.conn)
+	require.Error(t, err)
+	require.Eventually(t, func() bool {
+		return server.ConnectionCount() == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestXProtocolRequireSecureTransport(t *testing.T) {
+	store, err := mockstore.NewMockStore()
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, store.Close())
+	}()
+
+	dir := t.TempDir()
+	caCert, caKey, err := generateCert(0, "TiDB CA", nil, nil, filepath.Join(dir, "ca-key.pem"), filepath.Join(dir, "ca-cert.pem"))
+	require.NoError(t, err)
+	_, _, err = generateCert(1, "tidb-server", caCert, caKey, filepath.Join(dir, "server-key.pem"), filepath.Join(dir, "server-cert.pem"))
+	require.NoError(t, err)
+
+	cfg := newTestConfig()
+	cfg.Port = 0
+	cfg.Status.ReportStatus = false
+	cfg.Security = config.Security{
+		SSLCert:                filepath.Join(dir, "server-cert.pem"),
+		SSLKey:                 filepath.Join(dir, "server-key.pem"),
+		RequireSecureTransport: true,
+	}
+	server, err := NewServer(cfg, NewTiDBDriver(store))
+	require.NoError(t, err)
+	server.Close()
+
+	// The X Protocol connections can't be secure, so the server refuses to start even if TLS is configured.
+	cfg.XProtocol.Enable = true
+	cfg.XProtocol.Port = 0
+	_, err = NewServer(cfg, NewTiDBDriver(store))
+	require.True(t, errSecureTransportRequired.Equal(err))
+}
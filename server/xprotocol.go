@@ -0,0 +1,665 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/util/dbterror"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The X Protocol frames the protobuf messages defined by the mysqlx*.proto files of MySQL, each frame is
+// a 4 bytes little-endian length, a 1 byte message type and the message, the length includes the type byte.
+// Only the messages and the fields used by TiDB are decoded and encoded here.
+
+// The types of the client messages, see Mysqlx.ClientMessages.
+const (
+	xClientConCapabilitiesGet       = 1
+	xClientConCapabilitiesSet       = 2
+	xClientConClose                 = 3
+	xClientSessAuthenticateStart    = 4
+	xClientSessAuthenticateContinue = 5
+	xClientSessReset                = 6
+	xClientSessClose                = 7
+	xClientSQLStmtExecute           = 12
+	xClientCrudFind                 = 17
+	xClientCrudInsert               = 18
+	xClientCrudUpdate               = 19
+	xClientCrudDelete               = 20
+	xClientExpectOpen               = 24
+	xClientExpectClose              = 25
+)
+
+// The types of the server messages, see Mysqlx.ServerMessages.
+const (
+	xServerOk                       = 0
+	xServerError                    = 1
+	xServerConCapabilities          = 2
+	xServerSessAuthenticateContinue = 3
+	xServerSessAuthenticateOk       = 4
+	xServerNotice                   = 11
+	xServerResultsetColumnMetaData  = 12
+	xServerResultsetRow             = 13
+	xServerResultsetFetchDone       = 14
+	xServerSQLStmtExecuteOk         = 17
+)
+
+// xMaxMessageLen is the same as the default mysqlx_max_allowed_packet of MySQL.
+const xMaxMessageLen = 64 << 20
+
+// The types of Mysqlx.Datatypes.Scalar.
+const (
+	xScalarSInt   = 1
+	xScalarUInt   = 2
+	xScalarNull   = 3
+	xScalarOctets = 4
+	xScalarDouble = 5
+	xScalarFloat  = 6
+	xScalarBool   = 7
+	xScalarString = 8
+)
+
+// The types of Mysqlx.Datatypes.Any.
+const (
+	xAnyScalar = 1
+	xAnyObject = 2
+	xAnyArray  = 3
+)
+
+// The types of Mysqlx.Expr.Expr.
+const (
+	xExprIdent       = 1
+	xExprLiteral     = 2
+	xExprVariable    = 3
+	xExprFuncCall    = 4
+	xExprOperator    = 5
+	xExprPlaceholder = 6
+	xExprObject      = 7
+	xExprArray       = 8
+)
+
+// The types of Mysqlx.Expr.DocumentPathItem.
+const (
+	xPathMember             = 1
+	xPathMemberAsterisk     = 2
+	xPathArrayIndex         = 3
+	xPathArrayIndexAsterisk = 4
+	xPathDoubleAsterisk     = 5
+)
+
+// The content types of the bytes, see Mysqlx.Resultset.ContentType_BYTES.
+const (
+	xContentTypeGeometry = 1
+	xContentTypeJSON     = 2
+)
+
+// The data models of Mysqlx.Crud.DataModel.
+const (
+	xDataModelDocument = 1
+	xDataModelTable    = 2
+)
+
+var (
+	errXBadMessage                = dbterror.ClassServer.NewStd(errno.ErrXBadMessage)
+	errXCapabilitiesPrepareFailed = dbterror.ClassServer.NewStd(errno.ErrXCapabilitiesPrepareFailed)
+	errXCapabilityNotFound        = dbterror.ClassServer.NewStd(errno.ErrXCapabilityNotFound)
+	errXNotSupported              = dbterror.ClassServer.NewStd(errno.ErrNotSupportedYet)
+)
+
+// readXMessage reads a frame of the X Protocol and returns the message type and the message.
+func readXMessage(r io.Reader) (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, errors.Trace(err)
+	}
+	length := binary.LittleEndian.Uint32(header[:4])
+	if length == 0 || length > xMaxMessageLen {
+		return 0, nil, errXBadMessage.GenWithStackByArgs("message too large")
+	}
+	msg := make([]byte, length-1)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return 0, nil, errors.Trace(err)
+	}
+	return header[4], msg, nil
+}
+
+// writeXMessage writes a frame of the X Protocol.
+func writeXMessage(w io.Writer, tp byte, msg []byte) error {
+	var header [5]byte
+	binary.LittleEndian.PutUint32(header[:4], uint32(len(msg)+1))
+	header[4] = tp
+	if _, err := w.Write(header[:]); err != nil {
+		return errors.Trace(err)
+	}
+	_, err := w.Write(msg)
+	return errors.Trace(err)
+}
+
+// xField is a field of a protobuf message, v is the value of the varint and fixed fields,
+// b is the value of the bytes fields, which includes the strings and the embedded messages.
+type xField struct {
+	num protowire.Number
+	v   uint64
+	b   []byte
+}
+
+func decodeXFields(msg []byte) ([]xField, error) {
+	var fields []xField
+	for len(msg) > 0 {
+		num, tp, n := protowire.ConsumeTag(msg)
+		if n < 0 {
+			return nil, errXBadMessage.GenWithStackByArgs(protowire.ParseError(n))
+		}
+		msg = msg[n:]
+		f := xField{num: num}
+		switch tp {
+		case protowire.VarintType:
+			f.v, n = protowire.ConsumeVarint(msg)
+		case protowire.Fixed64Type:
+			f.v, n = protowire.ConsumeFixed64(msg)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(msg)
+			f.v = uint64(v)
+		case protowire.BytesType:
+			f.b, n = protowire.ConsumeBytes(msg)
+		default:
+			n = protowire.ConsumeFieldValue(num, tp, msg)
+		}
+		if n < 0 {
+			return nil, errXBadMessage.GenWithStackByArgs(protowire.ParseError(n))
+		}
+		msg = msg[n:]
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// xScalar is Mysqlx.Datatypes.Scalar, octets holds the value of V_OCTETS and V_STRING.
+type xScalar struct {
+	tp          uint64
+	sint        int64
+	uint        uint64
+	octets      []byte
+	contentType uint64
+	double      float64
+	bool        bool
+}
+
+func decodeXScalar(msg []byte) (*xScalar, error) {
+	fields, err := decodeXFields(msg)
+	if err != nil {
+		return nil, err
+	}
+	s := &xScalar{}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			s.tp = f.v
+		case 2:
+			s.sint = protowire.DecodeZigZag(f.v)
+		case 3:
+			s.uint = f.v
+		case 5, 9:
+			// Octets and String have the same layout, the content type and the collation are the 2nd fields.
+			sub, err := decodeXFields(f.b)
+			if err != nil {
+				return nil, err
+			}
+			for _, sf := range sub {
+				switch sf.num {
+				case 1:
+					s.octets = sf.b
+				case 2:
+					s.contentType = sf.v
+				}
+			}
+			if f.num == 9 {
+				s.contentType = 0
+			}
+		case 6:
+			s.double = math.Float64frombits(f.v)
+		case 7:
+			s.double = float64(math.Float32frombits(uint32(f.v)))
+		case 8:
+			s.bool = f.v != 0
+		}
+	}
+	return s, nil
+}
+
+// value returns the value used as the argument of sqlexec.EscapeSQL.
+func (s *xScalar) value() interface{} {
+	switch s.tp {
+	case xScalarSInt:
+		return s.sint
+	case xScalarUInt:
+		return s.uint
+	case xScalarOctets, xScalarString:
+		return string(s.octets)
+	case xScalarDouble, xScalarFloat:
+		return s.double
+	case xScalarBool:
+		return s.bool
+	}
+	return nil
+}
+
+// xAny is Mysqlx.Datatypes.Any.
+type xAny struct {
+	tp     uint64
+	scalar *xScalar
+	keys   []string
+	values []*xAny
+}
+
+func decodeXAny(msg []byte) (*xAny, error) {
+	fields, err := decodeXFields(msg)
+	if err != nil {
+		return nil, err
+	}
+	a := &xAny{}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			a.tp = f.v
+		case 2:
+			if a.scalar, err = decodeXScalar(f.b); err != nil {
+				return nil, err
+			}
+		case 3:
+			// Object is a list of ObjectField{key, value}.
+			objFields, err := decodeXFields(f.b)
+			if err != nil {
+				return nil, err
+			}
+			for _, of := range objFields {
+				kv, err := decodeXFields(of.b)
+				if err != nil {
+					return nil, err
+				}
+				var key string
+				var value *xAny
+				for _, x := range kv {
+					switch x.num {
+					case 1:
+						key = string(x.b)
+					case 2:
+						if value, err = decodeXAny(x.b); err != nil {
+							return nil, err
+						}
+					}
+				}
+				a.keys = append(a.keys, key)
+				a.values = append(a.values, value)
+			}
+		case 4:
+			arrFields, err := decodeXFields(f.b)
+			if err != nil {
+				return nil, err
+			}
+			for _, af := range arrFields {
+				value, err := decodeXAny(af.b)
+				if err != nil {
+					return nil, err
+				}
+				a.values = append(a.values, value)
+			}
+		}
+	}
+	if a.tp == xAnyScalar && a.scalar == nil {
+		return nil, errXBadMessage.GenWithStackByArgs("scalar is missing")
+	}
+	return a, nil
+}
+
+// field returns the value of the key of an object.
+func (a *xAny) field(key string) *xAny {
+	if a == nil || a.tp != xAnyObject {
+		return nil
+	}
+	for i, k := range a.keys {
+		if k == key {
+			return a.values[i]
+		}
+	}
+	return nil
+}
+
+// str returns the string value of a scalar.
+func (a *xAny) str() (string, bool) {
+	if a == nil || a.tp != xAnyScalar || (a.scalar.tp != xScalarString && a.scalar.tp != xScalarOctets) {
+		return "", false
+	}
+	return string(a.scalar.octets), true
+}
+
+// xDocPathItem is Mysqlx.Expr.DocumentPathItem.
+type xDocPathItem struct {
+	tp    uint64
+	value string
+	index uint64
+}
+
+// xExpr is Mysqlx.Expr.Expr, name is the name of the identifier, the variable, the function or the operator,
+// keys are the keys of an object, params are the parameters of a function or an operator, the values of
+// an object or the elements of an array.
+type xExpr struct {
+	tp       uint64
+	path     []xDocPathItem
+	name     string
+	table    string
+	schema   string
+	literal  *xScalar
+	position uint64
+	keys     []string
+	params   []*xExpr
+}
+
+func decodeXExpr(msg []byte) (*xExpr, error) {
+	fields, err := decodeXFields(msg)
+	if err != nil {
+		return nil, err
+	}
+	e := &xExpr{}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			e.tp = f.v
+		case 2:
+			if err = e.decodeIdent(f.b); err != nil {
+				return nil, err
+			}
+		case 3:
+			e.name = string(f.b)
+		case 4:
+			if e.literal, err = decodeXScalar(f.b); err != nil {
+				return nil, err
+			}
+		case 5:
+			if err = e.decodeCall(f.b, true); err != nil {
+				return nil, err
+			}
+		case 6:
+			if err = e.decodeCall(f.b, false); err != nil {
+				return nil, err
+			}
+		case 7:
+			e.position = f.v
+		case 8:
+			objFields, err := decodeXFields(f.b)
+			if err != nil {
+				return nil, err
+			}
+			for _, of := range objFields {
+				kv, err := decodeXFields(of.b)
+				if err != nil {
+					return nil, err
+				}
+				var key string
+				var value *xExpr
+				for _, x := range kv {
+					switch x.num {
+					case 1:
+						key = string(x.b)
+					case 2:
+						if value, err = decodeXExpr(x.b); err != nil {
+							return nil, err
+						}
+					}
+				}
+				if value == nil {
+					return nil, errXBadMessage.GenWithStackByArgs("value of object field is missing")
+				}
+				e.keys = append(e.keys, key)
+				e.params = append(e.params, value)
+			}
+		case 9:
+			arrFields, err := decodeXFields(f.b)
+			if err != nil {
+				return nil, err
+			}
+			for _, af := range arrFields {
+				value, err := decodeXExpr(af.b)
+				if err != nil {
+					return nil, err
+				}
+				e.params = append(e.params, value)
+			}
+		}
+	}
+	if e.tp == xExprLiteral && e.literal == nil {
+		return nil, errXBadMessage.GenWithStackByArgs("literal is missing")
+	}
+	return e, nil
+}
+
+// decodeCall decodes Mysqlx.Expr.FunctionCall{name: Identifier{name, schema_name}, param} if isFunc,
+// otherwise Mysqlx.Expr.Operator{name, param}.
+func (e *xExpr) decodeCall(msg []byte, isFunc bool) error {
+	fields, err := decodeXFields(msg)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		switch {
+		case f.num == 1 && !isFunc:
+			e.name = string(f.b)
+		case f.num == 1:
+			ident, err := decodeXFields(f.b)
+			if err != nil {
+				return err
+			}
+			for _, x := range ident {
+				switch x.num {
+				case 1:
+					e.name = string(x.b)
+				case 2:
+					e.schema = string(x.b)
+				}
+			}
+		case f.num == 2:
+			param, err := decodeXExpr(f.b)
+			if err != nil {
+				return err
+			}
+			e.params = append(e.params, param)
+		}
+	}
+	return nil
+}
+
+// decodeIdent decodes Mysqlx.Expr.ColumnIdentifier.
+func (e *xExpr) decodeIdent(msg []byte) error {
+	fields, err := decodeXFields(msg)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			item, err := decodeXDocPathItem(f.b)
+			if err != nil {
+				return err
+			}
+			e.path = append(e.path, item)
+		case 2:
+			e.name = string(f.b)
+		case 3:
+			e.table = string(f.b)
+		case 4:
+			e.schema = string(f.b)
+		}
+	}
+	return nil
+}
+
+func decodeXDocPathItem(msg []byte) (xDocPathItem, error) {
+	var item xDocPathItem
+	fields, err := decodeXFields(msg)
+	if err != nil {
+		return item, err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			item.tp = f.v
+		case 2:
+			item.value = string(f.b)
+		case 3:
+			item.index = f.v
+		}
+	}
+	return item, nil
+}
+
+// decodeXCollection decodes Mysqlx.Crud.Collection.
+func decodeXCollection(msg []byte) (name, schema string, err error) {
+	fields, err := decodeXFields(msg)
+	if err != nil {
+		return "", "", err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			name = string(f.b)
+		case 2:
+			schema = string(f.b)
+		}
+	}
+	return name, schema, nil
+}
+
+func appendXString(b []byte, num protowire.Number, s string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendXBytes(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendXVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// encodeXScalarUInt encodes a V_UINT Mysqlx.Datatypes.Scalar.
+func encodeXScalarUInt(v uint64) []byte {
+	b := appendXVarint(nil, 1, xScalarUInt)
+	return appendXVarint(b, 3, v)
+}
+
+// encodeXScalarString encodes a V_STRING Mysqlx.Datatypes.Scalar.
+func encodeXScalarString(s string) []byte {
+	b := appendXVarint(nil, 1, xScalarString)
+	return appendXBytes(b, 9, appendXString(nil, 1, s))
+}
+
+// encodeXScalarOctets encodes a V_OCTETS Mysqlx.Datatypes.Scalar.
+func encodeXScalarOctets(s string) []byte {
+	b := appendXVarint(nil, 1, xScalarOctets)
+	return appendXBytes(b, 5, appendXString(nil, 1, s))
+}
+
+// encodeXAnyScalar encodes a Mysqlx.Datatypes.Any of the encoded scalar.
+func encodeXAnyScalar(scalar []byte) []byte {
+	b := appendXVarint(nil, 1, xAnyScalar)
+	return appendXBytes(b, 2, scalar)
+}
+
+// encodeXAnyBool encodes a Mysqlx.Datatypes.Any of a V_BOOL scalar.
+func encodeXAnyBool(v bool) []byte {
+	var i uint64
+	if v {
+		i = 1
+	}
+	scalar := appendXVarint(nil, 1, xScalarBool)
+	return encodeXAnyScalar(appendXVarint(scalar, 8, i))
+}
+
+// encodeXAnyArray encodes a Mysqlx.Datatypes.Any of an array of the encoded Any values.
+func encodeXAnyArray(values ...[]byte) []byte {
+	var arr []byte
+	for _, v := range values {
+		arr = appendXBytes(arr, 1, v)
+	}
+	b := appendXVarint(nil, 1, xAnyArray)
+	return appendXBytes(b, 4, arr)
+}
+
+// encodeXCapabilities encodes Mysqlx.Connection.Capabilities, values are the encoded Any values of the names.
+func encodeXCapabilities(names []string, values [][]byte) []byte {
+	var b []byte
+	for i, name := range names {
+		capability := appendXString(nil, 1, name)
+		capability = appendXBytes(capability, 2, values[i])
+		b = appendXBytes(b, 1, capability)
+	}
+	return b
+}
+
+// encodeXError encodes Mysqlx.Error.
+func encodeXError(fatal bool, code uint16, msg, state string) []byte {
+	var b []byte
+	if fatal {
+		b = appendXVarint(b, 1, 1)
+	}
+	b = appendXVarint(b, 2, uint64(code))
+	b = appendXString(b, 3, msg)
+	return appendXString(b, 4, state)
+}
+
+// The types, the scopes and the parameters of the notices, see Mysqlx.Notice.
+const (
+	xNoticeWarning             = 1
+	xNoticeSessionStateChanged = 3
+
+	xNoticeScopeLocal = 2
+
+	xStateGeneratedInsertID    = 3
+	xStateRowsAffected         = 4
+	xStateClientIDAssigned     = 11
+	xStateGeneratedDocumentIDs = 12
+)
+
+// encodeXNotice encodes Mysqlx.Notice.Frame of a local notice.
+func encodeXNotice(tp uint64, payload []byte) []byte {
+	b := appendXVarint(nil, 1, tp)
+	b = appendXVarint(b, 2, xNoticeScopeLocal)
+	return appendXBytes(b, 3, payload)
+}
+
+// encodeXSessionStateChanged encodes Mysqlx.Notice.SessionStateChanged, values are the encoded scalars.
+func encodeXSessionStateChanged(param uint64, values ...[]byte) []byte {
+	b := appendXVarint(nil, 1, param)
+	for _, v := range values {
+		b = appendXBytes(b, 2, v)
+	}
+	return b
+}
+
+// encodeXWarning encodes Mysqlx.Notice.Warning, the levels are NOTE=1, WARNING=2 and ERROR=3.
+func encodeXWarning(level uint64, code uint16, msg string) []byte {
+	b := appendXVarint(nil, 1, level)
+	b = appendXVarint(b, 2, uint64(code))
+	return appendXString(b, 3, msg)
+}
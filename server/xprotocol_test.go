@@ -0,0 +1,195 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func encodeXScalarSInt(v int64) []byte {
+	b := appendXVarint(nil, 1, xScalarSInt)
+	return appendXVarint(b, 2, protowire.EncodeZigZag(v))
+}
+
+func encodeXIdentExpr(column string, members ...string) []byte {
+	var ident []byte
+	for _, m := range members {
+		item := appendXVarint(nil, 1, xPathMember)
+		ident = appendXBytes(ident, 1, appendXString(item, 2, m))
+	}
+	if column != "" {
+		ident = appendXString(ident, 2, column)
+	}
+	b := appendXVarint(nil, 1, xExprIdent)
+	return appendXBytes(b, 2, ident)
+}
+
+func encodeXLiteralExpr(scalar []byte) []byte {
+	b := appendXVarint(nil, 1, xExprLiteral)
+	return appendXBytes(b, 4, scalar)
+}
+
+func encodeXPlaceholderExpr(position uint64) []byte {
+	b := appendXVarint(nil, 1, xExprPlaceholder)
+	return appendXVarint(b, 7, position)
+}
+
+func encodeXOperatorExpr(name string, params ...[]byte) []byte {
+	op := appendXString(nil, 1, name)
+	for _, p := range params {
+		op = appendXBytes(op, 2, p)
+	}
+	b := appendXVarint(nil, 1, xExprOperator)
+	return appendXBytes(b, 6, op)
+}
+
+func encodeXObjectExpr(keys []string, values [][]byte) []byte {
+	var obj []byte
+	for i, key := range keys {
+		fld := appendXString(nil, 1, key)
+		obj = appendXBytes(obj, 1, appendXBytes(fld, 2, values[i]))
+	}
+	b := appendXVarint(nil, 1, xExprObject)
+	return appendXBytes(b, 8, obj)
+}
+
+func TestXMessage(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeXMessage(&buf, xServerOk, nil))
+	require.NoError(t, writeXMessage(&buf, xServerError, encodeXError(true, 1045, "denied", "28000")))
+	require.Equal(t, []byte{1, 0, 0, 0, xServerOk}, buf.Bytes()[:5])
+
+	tp, msg, err := readXMessage(&buf)
+	require.NoError(t, err)
+	require.Equal(t, byte(xServerOk), tp)
+	require.Len(t, msg, 0)
+	tp, msg, err = readXMessage(&buf)
+	require.NoError(t, err)
+	require.Equal(t, byte(xServerError), tp)
+	fields, err := decodeXFields(msg)
+	require.NoError(t, err)
+	require.Len(t, fields, 4)
+	require.Equal(t, uint64(1), fields[0].v)
+	require.Equal(t, uint64(1045), fields[1].v)
+	require.Equal(t, "denied", string(fields[2].b))
+	require.Equal(t, "28000", string(fields[3].b))
+
+	_, _, err = readXMessage(bytes.NewReader([]byte{0, 0, 0, 0x10, 1}))
+	require.True(t, errXBadMessage.Equal(err))
+	_, _, err = readXMessage(bytes.NewReader([]byte{5, 0, 0, 0, 1}))
+	require.Error(t, err)
+}
+
+func TestDecodeXAny(t *testing.T) {
+	var obj []byte
+	fld := appendXString(nil, 1, "schema")
+	obj = appendXBytes(obj, 1, appendXBytes(fld, 2, encodeXAnyScalar(encodeXScalarString("test"))))
+	fld = appendXString(nil, 1, "name")
+	obj = appendXBytes(obj, 1, appendXBytes(fld, 2, encodeXAnyScalar(encodeXScalarOctets("c"))))
+	msg := appendXBytes(appendXVarint(nil, 1, xAnyObject), 3, obj)
+
+	a, err := decodeXAny(msg)
+	require.NoError(t, err)
+	s, ok := a.field("schema").str()
+	require.True(t, ok)
+	require.Equal(t, "test", s)
+	s, ok = a.field("name").str()
+	require.True(t, ok)
+	require.Equal(t, "c", s)
+	require.Nil(t, a.field("options"))
+
+	a, err = decodeXAny(encodeXAnyArray(encodeXAnyScalar(encodeXScalarSInt(-3)), encodeXAnyBool(true)))
+	require.NoError(t, err)
+	require.Len(t, a.values, 2)
+	require.Equal(t, int64(-3), a.values[0].scalar.value())
+	require.Equal(t, true, a.values[1].scalar.value())
+
+	_, err = decodeXAny(appendXVarint(nil, 1, xAnyScalar))
+	require.True(t, errXBadMessage.Equal(err))
+	_, err = decodeXAny([]byte{0x0a, 0x10})
+	require.True(t, errXBadMessage.Equal(err))
+}
+
+func TestXExprBuilder(t *testing.T) {
+	args := []*xScalar{{tp: xScalarString, octets: []byte("x")}}
+	tests := []struct {
+		expr []byte
+		sql  string
+	}{
+		{
+			encodeXOperatorExpr("==", encodeXIdentExpr("", "name"), encodeXLiteralExpr(encodeXScalarString("a"))),
+			"(JSON_EXTRACT(`doc`, '$.name') = 'a')",
+		},
+		{
+			encodeXOperatorExpr("&&",
+				encodeXOperatorExpr(">", encodeXIdentExpr("", "a", "my key"), encodeXLiteralExpr(encodeXScalarSInt(-1))),
+				encodeXOperatorExpr("not", encodeXIdentExpr("c"))),
+			"((JSON_EXTRACT(`doc`, '$.a.\\\"my key\\\"') > -1) AND (NOT `c`))",
+		},
+		{
+			// The keys are quoted as the JSON strings.
+			encodeXOperatorExpr("==", encodeXIdentExpr("", "k\x01"), encodeXLiteralExpr(encodeXScalarSInt(1))),
+			"(JSON_EXTRACT(`doc`, '$.\\\"k\\\\u0001\\\"') = 1)",
+		},
+		{
+			encodeXOperatorExpr("like", encodeXIdentExpr("", "name"), encodeXPlaceholderExpr(0)),
+			"(JSON_UNQUOTE(JSON_EXTRACT(`doc`, '$.name')) LIKE 'x')",
+		},
+		{
+			encodeXOperatorExpr("in", encodeXIdentExpr("", "age"), encodeXLiteralExpr(encodeXScalarUInt(10)), encodeXLiteralExpr(encodeXScalarUInt(30))),
+			"(JSON_EXTRACT(`doc`, '$.age') IN (10, 30))",
+		},
+		{
+			encodeXOperatorExpr("not_between", encodeXIdentExpr("", "age"), encodeXLiteralExpr(encodeXScalarUInt(10)), encodeXLiteralExpr(encodeXScalarUInt(30))),
+			"(JSON_EXTRACT(`doc`, '$.age') NOT BETWEEN 10 AND 30)",
+		},
+		{
+			encodeXOperatorExpr("cont_in", encodeXPlaceholderExpr(0), encodeXIdentExpr("", "tags")),
+			"JSON_CONTAINS(JSON_EXTRACT(`doc`, '$.tags'), JSON_QUOTE('x'))",
+		},
+		{
+			encodeXObjectExpr([]string{"a", "b"}, [][]byte{encodeXLiteralExpr(encodeXScalarUInt(1)), encodeXIdentExpr("", "b")}),
+			"JSON_OBJECT('a', 1, 'b', JSON_EXTRACT(`doc`, '$.b'))",
+		},
+	}
+	for _, tt := range tests {
+		e, err := decodeXExpr(tt.expr)
+		require.NoError(t, err)
+		b := &xExprBuilder{args: args}
+		require.NoError(t, b.build(e))
+		require.Equal(t, tt.sql, b.sql.String())
+	}
+
+	for _, expr := range [][]byte{
+		encodeXOperatorExpr("==", encodeXIdentExpr("a")),
+		encodeXOperatorExpr("==", encodeXIdentExpr("a"), encodeXPlaceholderExpr(1)),
+		encodeXOperatorExpr("unknown_op", encodeXIdentExpr("a")),
+	} {
+		e, err := decodeXExpr(expr)
+		require.NoError(t, err)
+		b := &xExprBuilder{args: args}
+		require.Error(t, b.build(e))
+	}
+}
+
+func TestAppendXDecimal(t *testing.T) {
+	require.Equal(t, []byte{3, 0x12, 0x34, 0x5d}, appendXDecimal(nil, "-12.345"))
+	require.Equal(t, []byte{0, 0x1c}, appendXDecimal(nil, "1"))
+	require.Equal(t, []byte{2, 0x10, 0x0c}, appendXDecimal(nil, "1.00"))
+}
@@ -0,0 +1,230 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"math"
+	"strings"
+
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The column types of the X Protocol, see Mysqlx.Resultset.ColumnMetaData.FieldType.
+const (
+	xColumnSInt     = 1
+	xColumnUInt     = 2
+	xColumnDouble   = 5
+	xColumnFloat    = 6
+	xColumnBytes    = 7
+	xColumnTime     = 10
+	xColumnDatetime = 12
+	xColumnBit      = 17
+	xColumnDecimal  = 18
+)
+
+// The flags of the columns, the type specific flag is 0x0001: UNSIGNED of the float, double and decimal,
+// ZEROFILL of the unsigned integer and TIMESTAMP of the datetime.
+const (
+	xColumnFlagTypeSpecific  = 0x0001
+	xColumnFlagNotNull       = 0x0010
+	xColumnFlagPrimaryKey    = 0x0020
+	xColumnFlagUniqueKey     = 0x0040
+	xColumnFlagMultipleKey   = 0x0080
+	xColumnFlagAutoIncrement = 0x0100
+)
+
+// xColumnType returns the column type and the type specific flag of the X Protocol, the enum and set
+// columns are sent as bytes.
+func xColumnType(col *ColumnInfo) (uint64, bool) {
+	unsigned := mysql.HasUnsignedFlag(uint(col.Flag))
+	switch col.Type {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+		if unsigned {
+			return xColumnUInt, mysql.HasZerofillFlag(uint(col.Flag))
+		}
+		return xColumnSInt, false
+	case mysql.TypeYear:
+		return xColumnUInt, false
+	case mysql.TypeFloat:
+		return xColumnFloat, unsigned
+	case mysql.TypeDouble:
+		return xColumnDouble, unsigned
+	case mysql.TypeNewDecimal:
+		return xColumnDecimal, unsigned
+	case mysql.TypeDate, mysql.TypeDatetime:
+		return xColumnDatetime, false
+	case mysql.TypeTimestamp:
+		return xColumnDatetime, true
+	case mysql.TypeDuration:
+		return xColumnTime, false
+	case mysql.TypeBit:
+		return xColumnBit, false
+	}
+	return xColumnBytes, false
+}
+
+// encodeXColumnMetaData encodes Mysqlx.Resultset.ColumnMetaData.
+func encodeXColumnMetaData(col *ColumnInfo) []byte {
+	tp, specific := xColumnType(col)
+	b := appendXVarint(nil, 1, tp)
+	b = appendXString(b, 2, col.Name)
+	b = appendXString(b, 3, col.OrgName)
+	b = appendXString(b, 4, col.Table)
+	b = appendXString(b, 5, col.OrgTable)
+	b = appendXString(b, 6, col.Schema)
+	b = appendXString(b, 7, "def")
+	if tp == xColumnBytes {
+		b = appendXVarint(b, 8, uint64(col.Charset))
+	}
+	if col.Decimal > 0 && int(col.Decimal) != mysql.NotFixedDec {
+		b = appendXVarint(b, 9, uint64(col.Decimal))
+	}
+	b = appendXVarint(b, 10, uint64(col.ColumnLength))
+	var flags uint64
+	if specific {
+		flags |= xColumnFlagTypeSpecific
+	}
+	for _, f := range []struct {
+		flag   uint
+		xFlags uint64
+	}{
+		{mysql.NotNullFlag, xColumnFlagNotNull},
+		{mysql.PriKeyFlag, xColumnFlagPrimaryKey},
+		{mysql.UniqueKeyFlag, xColumnFlagUniqueKey},
+		{mysql.MultipleKeyFlag, xColumnFlagMultipleKey},
+		{mysql.AutoIncrementFlag, xColumnFlagAutoIncrement},
+	} {
+		if uint(col.Flag)&f.flag > 0 {
+			flags |= f.xFlags
+		}
+	}
+	b = appendXVarint(b, 11, flags)
+	switch col.Type {
+	case mysql.TypeJSON:
+		b = appendXVarint(b, 12, xContentTypeJSON)
+	case mysql.TypeGeometry:
+		b = appendXVarint(b, 12, xContentTypeGeometry)
+	}
+	return b
+}
+
+// encodeXRow encodes Mysqlx.Resultset.Row, the NULL values are empty fields.
+func encodeXRow(columns []*ColumnInfo, row chunk.Row) ([]byte, error) {
+	var b, field []byte
+	for i, col := range columns {
+		field = field[:0]
+		if !row.IsNull(i) {
+			var err error
+			if field, err = appendXValue(field, col, row, i); err != nil {
+				return nil, err
+			}
+		}
+		b = appendXBytes(b, 1, field)
+	}
+	return b, nil
+}
+
+func appendXValue(b []byte, col *ColumnInfo, row chunk.Row, i int) ([]byte, error) {
+	tp, _ := xColumnType(col)
+	switch tp {
+	case xColumnSInt:
+		return protowire.AppendVarint(b, protowire.EncodeZigZag(row.GetInt64(i))), nil
+	case xColumnUInt:
+		return protowire.AppendVarint(b, row.GetUint64(i)), nil
+	case xColumnFloat:
+		return protowire.AppendFixed32(b, math.Float32bits(row.GetFloat32(i))), nil
+	case xColumnDouble:
+		return protowire.AppendFixed64(b, math.Float64bits(row.GetFloat64(i))), nil
+	case xColumnDecimal:
+		return appendXDecimal(b, row.GetMyDecimal(i).String()), nil
+	case xColumnDatetime:
+		t := row.GetTime(i)
+		b = protowire.AppendVarint(b, uint64(t.Year()))
+		b = protowire.AppendVarint(b, uint64(t.Month()))
+		b = protowire.AppendVarint(b, uint64(t.Day()))
+		if col.Type != mysql.TypeDate {
+			b = appendXClock(b, t.Hour(), t.Minute(), t.Second(), t.Microsecond())
+		}
+		return b, nil
+	case xColumnTime:
+		d := row.GetDuration(i, int(col.Decimal))
+		if d.Duration < 0 {
+			b = append(b, 0x01)
+			d = d.Neg()
+		} else {
+			b = append(b, 0x00)
+		}
+		return appendXClock(b, d.Hour(), d.Minute(), d.Second(), d.MicroSecond()), nil
+	case xColumnBit:
+		v, err := types.BinaryLiteral(row.GetBytes(i)).ToInt(nil)
+		if err != nil {
+			return nil, err
+		}
+		return protowire.AppendVarint(b, v), nil
+	}
+	// The bytes are followed by 0x00 to tell the empty bytes from NULL.
+	switch col.Type {
+	case mysql.TypeEnum:
+		b = append(b, row.GetEnum(i).String()...)
+	case mysql.TypeSet:
+		b = append(b, row.GetSet(i).String()...)
+	case mysql.TypeJSON:
+		b = append(b, row.GetJSON(i).String()...)
+	default:
+		b = append(b, row.GetBytes(i)...)
+	}
+	return append(b, 0x00), nil
+}
+
+func appendXClock(b []byte, hour, minute, second, microsecond int) []byte {
+	b = protowire.AppendVarint(b, uint64(hour))
+	b = protowire.AppendVarint(b, uint64(minute))
+	b = protowire.AppendVarint(b, uint64(second))
+	if microsecond > 0 {
+		b = protowire.AppendVarint(b, uint64(microsecond))
+	}
+	return b
+}
+
+// appendXDecimal appends the decimal in the format of the X Protocol: a byte of the scale, followed by the
+// BCD digits and the sign nibble, 0xc for positive and 0xd for negative, the last nibble is 0 if it's unused.
+func appendXDecimal(b []byte, s string) []byte {
+	sign := byte(0xc)
+	if strings.HasPrefix(s, "-") {
+		sign = 0xd
+		s = s[1:]
+	}
+	scale := 0
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		scale = len(s) - dot - 1
+		s = s[:dot] + s[dot+1:]
+	}
+	b = append(b, byte(scale))
+	nibbles := make([]byte, 0, len(s)+2)
+	for i := 0; i < len(s); i++ {
+		nibbles = append(nibbles, s[i]-'0')
+	}
+	nibbles = append(nibbles, sign)
+	if len(nibbles)%2 == 1 {
+		nibbles = append(nibbles, 0)
+	}
+	for i := 0; i < len(nibbles); i += 2 {
+		b = append(b, nibbles[i]<<4|nibbles[i+1])
+	}
+	return b
+}
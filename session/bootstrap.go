@@ -41,6 +41,7 @@ import (
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/sessionctx/clustersetting"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
@@ -386,6 +387,29 @@ const (
 		UNIQUE KEY table_version_seq (table_id, version, seq_no),
 		KEY table_create_time (table_id, create_time, seq_no)
 	);`
+	// CreateServersTable stores the foreign servers created by CREATE SERVER.
+	CreateServersTable = `CREATE TABLE IF NOT EXISTS mysql.servers (
+		Server_name CHAR(64) NOT NULL DEFAULT '',
+		Host CHAR(255) NOT NULL DEFAULT '',
+		Db CHAR(64) NOT NULL DEFAULT '',
+		Username CHAR(64) NOT NULL DEFAULT '',
+		Password VARCHAR(512) NOT NULL DEFAULT '',
+		Port INT NOT NULL DEFAULT 0,
+		Socket CHAR(64) NOT NULL DEFAULT '',
+		Wrapper CHAR(64) NOT NULL DEFAULT '',
+		Owner CHAR(64) NOT NULL DEFAULT '',
+		PRIMARY KEY (Server_name)
+	);`
+	// CreateClusterSettingHistoryTable stores the changes of the cluster settings made by ADMIN SET CLUSTER SETTING.
+	CreateClusterSettingHistoryTable = `CREATE TABLE IF NOT EXISTS mysql.cluster_setting_history (
+		setting_name VARCHAR(64) NOT NULL,
+		old_value VARCHAR(1024) DEFAULT NULL,
+		new_value VARCHAR(1024) NOT NULL,
+		user CHAR(32) NOT NULL DEFAULT '',
+		host CHAR(255) NOT NULL DEFAULT '',
+		change_time TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		KEY setting_time (setting_name, change_time)
+	);`
 )
 
 // bootstrap initiates system DB for a store.
@@ -569,11 +593,33 @@ const (
 	version82 = 82
 	// version83 adds the tables mysql.stats_history
 	version83 = 83
+	// version84 adds the table mysql.servers
+	version84 = 84
+	// version85 adds the table mysql.cluster_setting_history
+	version85 = 85
 )
 
+// The settings stored in mysql.tidb by the bootstrap and the system variables.
+var tidbTableSettings = []*clustersetting.Setting{
+	{Name: bootstrappedVar, ReadOnly: true, Comment: "Bootstrap flag. Do not delete."},
+	{Name: tidbServerVersionVar, ReadOnly: true, Comment: "TiDB bootstrap version."},
+	{Name: tidbSystemTZ, ReadOnly: true, Comment: "TiDB Global System Timezone."},
+	{Name: tidbNewCollationEnabled, ReadOnly: true, Comment: "If the new collations are enabled. Do not edit it."},
+	{Name: tidbDefMemoryQuotaQuery, ReadOnly: true, Comment: "The default value of mem-quota-query of a cluster upgraded from v3.0.x."},
+	{Name: tidbDefOOMAction, ReadOnly: true, Comment: "The default value of oom-action of a cluster upgraded from v3.0.x."},
+	{Name: variable.TiDBEnableHistoricalStats, Type: clustersetting.TypeBool, Default: "false", Comment: "Current historical statistics enable status"},
+	{Name: variable.TiDBDisableColumnTrackingTime, ReadOnly: true, Comment: "Record the last time tidb_enable_column_tracking is set off"},
+}
+
+func init() {
+	for _, s := range tidbTableSettings {
+		clustersetting.Register(s)
+	}
+}
+
 // currentBootstrapVersion is defined as a variable, so we can modify its value for testing.
 // please make sure this is the largest version
-var currentBootstrapVersion int64 = version83
+var currentBootstrapVersion int64 = version85
 
 var (
 	bootstrapVersion = []func(Session, int64){
@@ -660,6 +706,8 @@ var (
 		upgradeToVer81,
 		upgradeToVer82,
 		upgradeToVer83,
+		upgradeToVer84,
+		upgradeToVer85,
 	}
 )
 
@@ -725,12 +773,33 @@ func upgrade(s Session) {
 		// It is already bootstrapped/upgraded by a higher version TiDB server.
 		return
 	}
-	// Do upgrade works then update bootstrap version.
-	for _, upgrade := range bootstrapVersion {
-		upgrade(s, ver)
+	// Do upgrade works batch by batch, the progress is persisted after every batch, so the upgrade resumes
+	// from the last finished batch if the server crashes.
+	start := 0
+	state, err := loadUpgradeState(s)
+	terror.MustNil(err)
+	if state != nil && state.From == ver && state.To == currentBootstrapVersion && state.Done <= len(bootstrapVersion) {
+		start = state.Done
+		logutil.BgLogger().Info("[Upgrade] resume the upgrade",
+			zap.Int64("from", ver),
+			zap.Int64("to", currentBootstrapVersion),
+			zap.Int("done steps", start))
+	}
+	for i := start; i < len(bootstrapVersion); i += upgradeBatchSize {
+		end := i + upgradeBatchSize
+		if end > len(bootstrapVersion) {
+			end = len(bootstrapVersion)
+		}
+		for _, upgrade := range bootstrapVersion[i:end] {
+			upgrade(s, ver)
+		}
+		saveUpgradeState(s, &upgradeState{From: ver, To: currentBootstrapVersion, Done: end})
 	}
 
+	// Update bootstrap version and finish the upgrade atomically.
+	mustExecute(s, "BEGIN")
 	updateBootstrapVer(s)
+	clearUpgradeState(s)
 	_, err = s.ExecuteInternal(context.Background(), "COMMIT")
 
 	if err != nil {
@@ -1722,6 +1791,20 @@ func upgradeToVer83(s Session, ver int64) {
 	doReentrantDDL(s, CreateStatsHistory)
 }
 
+func upgradeToVer84(s Session, ver int64) {
+	if ver >= version84 {
+		return
+	}
+	doReentrantDDL(s, CreateServersTable)
+}
+
+func upgradeToVer85(s Session, ver int64) {
+	if ver >= version85 {
+		return
+	}
+	doReentrantDDL(s, CreateClusterSettingHistoryTable)
+}
+
 func writeOOMAction(s Session) {
 	comment := "oom-action is `log` by default in v3.0.x, `cancel` by default in v4.0.11+"
 	mustExecute(s, `INSERT HIGH_PRIORITY INTO %n.%n VALUES (%?, %?, %?) ON DUPLICATE KEY UPDATE VARIABLE_VALUE= %?`,
@@ -1810,6 +1893,10 @@ func doDDLWorks(s Session) {
 	mustExecute(s, CreateAnalyzeOptionsTable)
 	// Create stats_history table.
 	mustExecute(s, CreateStatsHistory)
+	// Create servers table.
+	mustExecute(s, CreateServersTable)
+	// Create cluster_setting_history table.
+	mustExecute(s, CreateClusterSettingHistoryTable)
 }
 
 // doDMLWorks executes DML statements in bootstrap stage.
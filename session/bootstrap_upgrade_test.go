@@ -16,9 +16,12 @@ package session_test
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/session"
 	"github.com/pingcap/tidb/testkit"
 	"github.com/stretchr/testify/require"
@@ -55,3 +58,86 @@ func TestUpgradeVersion83(t *testing.T) {
 		require.Equal(t, statsHistoryTblFields[i].tp, strings.ToLower(row.GetString(1)))
 	}
 }
+
+func TestUpgradeResume(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	downgrade := func() {
+		tk.MustExec("drop table if exists mysql.cluster_setting_history")
+		tk.MustExec("update mysql.tidb set variable_value='84' where variable_name='tidb_server_version'")
+	}
+
+	// All the steps are done before the crash, so they are not run again.
+	downgrade()
+	tk.MustExec(fmt.Sprintf(`insert into mysql.tidb values ('tidb_upgrade_state', '{"from":84,"to":%d,"done":%d}', '')`,
+		session.CurrentBootstrapVersion, session.UpgradeStepCount))
+	session.Upgrade(tk.Session())
+	ver, err := session.GetBootstrapVersion(tk.Session())
+	require.NoError(t, err)
+	require.Equal(t, session.CurrentBootstrapVersion, ver)
+	tk.MustQuery("select count(*) from information_schema.tables where table_schema = 'mysql' and table_name = 'cluster_setting_history'").Check(testkit.Rows("0"))
+	tk.MustQuery("select count(*) from mysql.tidb where variable_name = 'tidb_upgrade_state'").Check(testkit.Rows("0"))
+
+	// The state of an upgrade from another version is ignored.
+	downgrade()
+	tk.MustExec(fmt.Sprintf(`insert into mysql.tidb values ('tidb_upgrade_state', '{"from":80,"to":%d,"done":%d}', '')`,
+		session.CurrentBootstrapVersion, session.UpgradeStepCount))
+	session.Upgrade(tk.Session())
+	tk.MustQuery("select count(*) from information_schema.tables where table_schema = 'mysql' and table_name = 'cluster_setting_history'").Check(testkit.Rows("1"))
+	tk.MustQuery("select count(*) from mysql.tidb where variable_name = 'tidb_upgrade_state'").Check(testkit.Rows("0"))
+}
+
+func TestDryRunUpgrade(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	report, err := session.DryRunUpgrade(store)
+	require.NoError(t, err)
+	require.Equal(t, session.CurrentBootstrapVersion, report.From)
+	require.Empty(t, report.Steps)
+
+	setMetaVersion := func(ver int64) {
+		txn, err := store.Begin()
+		require.NoError(t, err)
+		require.NoError(t, meta.NewMeta(txn).FinishBootstrap(ver))
+		require.NoError(t, txn.Commit(context.Background()))
+	}
+	setMetaVersion(83)
+	defer setMetaVersion(session.CurrentBootstrapVersion)
+	report, err = session.DryRunUpgrade(store)
+	require.NoError(t, err)
+	require.Equal(t, int64(83), report.From)
+	require.Equal(t, session.CurrentBootstrapVersion, report.To)
+	require.Equal(t, []string{"upgradeToVer84", "upgradeToVer85"}, report.Steps)
+	require.Equal(t, fmt.Sprintf("the store will be upgraded from version 83 to %d in 2 steps:\n  upgradeToVer84\n  upgradeToVer85",
+		session.CurrentBootstrapVersion), report.String())
+}
+
+func TestWaitUpgrade(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	require.NoError(t, session.WaitUpgrade(context.Background(), store))
+
+	// The state left by an upgrade to an old version is stale.
+	tk.MustExec(fmt.Sprintf(`insert into mysql.tidb values ('tidb_upgrade_state', '{"from":80,"to":%d,"done":3}', '')`,
+		session.CurrentBootstrapVersion))
+	require.NoError(t, session.WaitUpgrade(context.Background(), store))
+
+	// Wait for the upgrade to a newer version.
+	tk.MustExec(fmt.Sprintf(`update mysql.tidb set variable_value = '{"from":%d,"to":%d,"done":3}' where variable_name = 'tidb_upgrade_state'`,
+		session.CurrentBootstrapVersion, session.CurrentBootstrapVersion+1))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, session.WaitUpgrade(ctx, store), context.DeadlineExceeded)
+
+	done := make(chan error)
+	go func() {
+		done <- session.WaitUpgrade(context.Background(), store)
+	}()
+	tk.MustExec("delete from mysql.tidb where variable_name = 'tidb_upgrade_state'")
+	require.NoError(t, <-done)
+}
@@ -22,6 +22,8 @@ import (
 
 var GetBootstrapVersion = getBootstrapVersion
 var CurrentBootstrapVersion = currentBootstrapVersion
+var Upgrade = upgrade
+var UpgradeStepCount = len(bootstrapVersion)
 
 func TestIndexUsageSyncLease(t *testing.T) {
 	store, dom := createStoreAndBootstrap(t)
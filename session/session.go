@@ -79,6 +79,7 @@ import (
 	"github.com/pingcap/tidb/session/txninfo"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
+	"github.com/pingcap/tidb/sessionctx/clustersetting"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/statistics"
@@ -97,6 +98,7 @@ import (
 	"github.com/pingcap/tidb/util/sli"
 	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/pingcap/tidb/util/tableutil"
+	"github.com/pingcap/tidb/util/tableworkload"
 	"github.com/pingcap/tidb/util/timeutil"
 	tikvstore "github.com/tikv/client-go/v2/kv"
 	"github.com/tikv/client-go/v2/oracle"
@@ -336,7 +338,7 @@ func (s *session) cleanRetryInfo() {
 			if i > 0 && preparedAst != nil {
 				plannercore.SetPstmtIDSchemaVersion(cacheKey, stmtID, preparedAst.SchemaVersion, s.sessionVars.IsolationReadEngines)
 			}
-			s.PreparedPlanCache().Delete(cacheKey)
+			plannercore.DeletePlanCache(s.PreparedPlanCache(), cacheKey, plannercore.PlanCacheEvictDeallocate)
 		}
 		s.sessionVars.RemovePreparedStmt(stmtID)
 	}
@@ -928,6 +930,7 @@ func (s *session) doCommitWithRetry(ctx context.Context) error {
 		return err
 	}
 	s.updateStatsDeltaToCollector()
+	s.recordTableWriteWorkload()
 	return nil
 }
 
@@ -942,6 +945,15 @@ func (s *session) updateStatsDeltaToCollector() {
 	}
 }
 
+// recordTableWriteWorkload records the tables written by the committed transaction for INFORMATION_SCHEMA.TABLE_WORKLOAD.
+func (s *session) recordTableWriteWorkload() {
+	for _, item := range s.GetSessionVars().TxnCtx.TableDeltaMap {
+		if item.TableID > 0 {
+			tableworkload.RecordWrite(item.TableID, item.Count)
+		}
+	}
+}
+
 func (s *session) CommitTxn(ctx context.Context) error {
 	if span := opentracing.SpanFromContext(ctx); span != nil && span.Tracer() != nil {
 		span1 := span.Tracer().StartSpan("session.CommitTxn", opentracing.ChildOf(span.Context()))
@@ -1080,11 +1092,18 @@ func (s *session) retry(ctx context.Context, maxCnt uint) (err error) {
 		err = ErrForUpdateCantRetry.GenWithStackByArgs(connID)
 		return err
 	}
+	if atomic.LoadUint32(&s.sessionVars.TxnCtx.Returning) == 1 {
+		err = ErrReturningCantRetry.GenWithStackByArgs(connID)
+		return err
+	}
 
 	nh := GetHistory(s)
 	var schemaVersion int64
 	sessVars := s.GetSessionVars()
 	orgStartTS := sessVars.TxnCtx.StartTS
+	// The commutative updates are retried only if they update the same rows as before, otherwise
+	// the affected rows returned to the client are wrong.
+	commutativeRetry := sessVars.TxnCtx.CommutativeRetry
 	label := s.GetSQLLabel()
 	for {
 		if err = s.PrepareTxnCtx(ctx); err != nil {
@@ -1094,6 +1113,7 @@ func (s *session) retry(ctx context.Context, maxCnt uint) (err error) {
 		for i, sr := range nh.history {
 			st := sr.st
 			s.sessionVars.StmtCtx = sr.stmtCtx
+			affectedRows := sr.stmtCtx.AffectedRows()
 			s.sessionVars.StmtCtx.ResetForRetry()
 			s.sessionVars.PreparedParams = s.sessionVars.PreparedParams[:0]
 			schemaVersion, err = st.RebuildPlan(ctx)
@@ -1123,6 +1143,9 @@ func (s *session) retry(ctx context.Context, maxCnt uint) (err error) {
 			s.txn.onStmtStart(digest.String())
 			_, err = st.Exec(ctx)
 			s.txn.onStmtEnd()
+			if err == nil && commutativeRetry && s.sessionVars.StmtCtx.AffectedRows() != affectedRows {
+				err = ErrCommutativeUpdateCantRetry.GenWithStackByArgs(connID)
+			}
 			if err != nil {
 				s.StmtRollback()
 				break
@@ -1348,14 +1371,45 @@ func (s *session) SetGlobalSysVarOnly(name, value string) (err error) {
 }
 
 // SetTiDBTableValue implements GlobalVarAccessor.SetTiDBTableValue interface.
-func (s *session) SetTiDBTableValue(name, value, comment string) error {
-	_, _, err := s.ExecRestrictedSQL(context.TODO(), nil, `REPLACE INTO mysql.tidb (variable_name, variable_value, comment) VALUES (%?, %?, %?)`, name, value, comment)
-	return err
+func (s *session) SetTiDBTableValue(name, value string) error {
+	var user, host string
+	if u := s.sessionVars.User; u != nil {
+		user, host = u.Username, u.Hostname
+	}
+	return s.withSysSession(func(se *session) error {
+		return clustersetting.Change(context.TODO(), se, name, value, user, host)
+	})
 }
 
 // GetTiDBTableValue implements GlobalVarAccessor.GetTiDBTableValue interface.
-func (s *session) GetTiDBTableValue(name string) (string, error) {
-	return s.getTableValue(context.TODO(), mysql.TiDBTable, name)
+func (s *session) GetTiDBTableValue(name string) (value string, err error) {
+	err = s.withSysSession(func(se *session) error {
+		var ok bool
+		value, ok, err = clustersetting.Load(context.TODO(), se, name)
+		if err == nil && !ok {
+			err = errResultIsEmpty
+		}
+		return err
+	})
+	return value, err
+}
+
+// withSysSession runs fn with a session of the system session pool, the session is dedicated to fn,
+// so fn can run its statements in a transaction of the session.
+func (s *session) withSysSession(fn func(se *session) error) error {
+	tmp, err := s.sysSessionPool().Get()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	se := tmp.(*session)
+	se.sessionVars.InRestrictedSQL = true
+	err = fn(se)
+	if _, rollbackErr := se.ExecuteInternal(context.TODO(), "rollback"); rollbackErr != nil {
+		tmp.Close()
+		return err
+	}
+	s.sysSessionPool().Put(tmp)
+	return err
 }
 
 var _ sqlexec.SQLParser = &session{}
@@ -1403,21 +1457,22 @@ func (s *session) SetProcessInfo(sql string, t time.Time, command byte, maxExecu
 		p = explain.TargetPlan
 	}
 	pi := util.ProcessInfo{
-		ID:               s.sessionVars.ConnectionID,
-		Port:             s.sessionVars.Port,
-		DB:               s.sessionVars.CurrentDB,
-		Command:          command,
-		Plan:             p,
-		PlanExplainRows:  plannercore.GetExplainRowsForPlan(p),
-		RuntimeStatsColl: s.sessionVars.StmtCtx.RuntimeStatsColl,
-		Time:             t,
-		State:            s.Status(),
-		Info:             sql,
-		CurTxnStartTS:    curTxnStartTS,
-		StmtCtx:          s.sessionVars.StmtCtx,
-		StatsInfo:        plannercore.GetStatsInfo,
-		MaxExecutionTime: maxExecutionTime,
-		RedactSQL:        s.sessionVars.EnableRedactLog,
+		ID:                 s.sessionVars.ConnectionID,
+		Port:               s.sessionVars.Port,
+		DB:                 s.sessionVars.CurrentDB,
+		Command:            command,
+		Plan:               p,
+		PlanExplainRows:    plannercore.GetExplainRowsForPlan(p),
+		RuntimeStatsColl:   s.sessionVars.StmtCtx.RuntimeStatsColl,
+		Time:               t,
+		State:              s.Status(),
+		Info:               sql,
+		CurTxnStartTS:      curTxnStartTS,
+		StmtCtx:            s.sessionVars.StmtCtx,
+		StatsInfo:          plannercore.GetStatsInfo,
+		ExplainAnalyzeRows: plannercore.GetExplainAnalyzeRowsForPlan,
+		MaxExecutionTime:   maxExecutionTime,
+		RedactSQL:          s.sessionVars.EnableRedactLog,
 	}
 	oldPi := s.ShowProcess()
 	if p == nil {
@@ -1717,6 +1772,9 @@ func (s *session) getInternalSession(execOption sqlexec.ExecOption) (*session, f
 
 	// for analyze stmt we need let worker session follow user session that executing stmt.
 	se.sessionVars.PartitionPruneMode.Store(s.sessionVars.PartitionPruneMode.Load())
+	// SHOW TABLE STATUS reads information_schema.TABLES by the worker session, which estimates the tables like the user session.
+	prevTableStatsEstimation := se.sessionVars.TableStatsEstimation
+	se.sessionVars.TableStatsEstimation = s.sessionVars.TableStatsEstimation
 
 	return se, func() {
 		se.sessionVars.AnalyzeVersion = prevStatsVer
@@ -1731,6 +1789,7 @@ func (s *session) getInternalSession(execOption sqlexec.ExecOption) (*session, f
 			}
 		}
 		se.sessionVars.PartitionPruneMode.Store(prePruneMode)
+		se.sessionVars.TableStatsEstimation = prevTableStatsEstimation
 		se.sessionVars.OptimizerUseInvisibleIndexes = false
 		se.sessionVars.InspectionTableCache = nil
 		s.sysSessionPool().Put(tmp)
@@ -1796,6 +1855,10 @@ func (s *session) ExecuteStmt(ctx context.Context, stmtNode ast.StmtNode) (sqlex
 		ctx = opentracing.ContextWithSpan(ctx, span1)
 	}
 
+	if err := s.checkStmtClass(stmtNode); err != nil {
+		return nil, err
+	}
+
 	if err := s.PrepareTxnCtx(ctx); err != nil {
 		return nil, err
 	}
@@ -1913,6 +1976,104 @@ func (s *session) validateStatementReadOnlyInStaleness(stmtNode ast.StmtNode) er
 	return nil
 }
 
+// checkStmtClass checks whether the class of the statement is allowed by the config allowed-statement-classes, the
+// internal statements are always allowed.
+func (s *session) checkStmtClass(node ast.Node) error {
+	allowed := config.GetGlobalConfig().AllowedStmtClasses
+	if len(allowed) == 0 || s.sessionVars.InRestrictedSQL {
+		return nil
+	}
+	class, err := stmtClass(node, s.sessionVars)
+	if err != nil {
+		return err
+	}
+	for _, c := range allowed {
+		if c == class {
+			return nil
+		}
+	}
+	return ErrStmtClassNotAllowed.GenWithStackByArgs(class, strings.Join(allowed, ", "))
+}
+
+// stmtClass returns the class of the statement, see the constants of the statement classes in the config package.
+func stmtClass(node ast.Node, vars *variable.SessionVars) (string, error) {
+	switch x := node.(type) {
+	case *ast.ExecuteStmt:
+		prepared, err := plannercore.GetPreparedStmt(x, vars)
+		if err != nil {
+			return "", err
+		}
+		return stmtClass(prepared.PreparedAst.Stmt, vars)
+	case *ast.ExplainStmt:
+		// EXPLAIN ANALYZE executes the statement.
+		if x.Analyze {
+			return stmtClass(x.Stmt, vars)
+		}
+		return config.StmtClassExplain, nil
+	case *ast.ExplainForStmt:
+		return config.StmtClassExplain, nil
+	case *ast.TraceStmt:
+		return stmtClass(x.Stmt, vars)
+	case *ast.ShowStmt:
+		return config.StmtClassShow, nil
+	case *ast.SetStmt:
+		for _, v := range x.Variables {
+			if v.IsGlobal {
+				return config.StmtClassAdmin, nil
+			}
+		}
+		return config.StmtClassSession, nil
+	case *ast.UseStmt, *ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt, *ast.PrepareStmt, *ast.DeallocateStmt, *ast.SetRoleStmt:
+		return config.StmtClassSession, nil
+	case *ast.DoStmt:
+		if hasSideEffects(x) {
+			return config.StmtClassDML, nil
+		}
+		return config.StmtClassRead, nil
+	case ast.DDLNode:
+		return config.StmtClassDDL, nil
+	case ast.DMLNode:
+		if ast.IsReadOnly(x) && !hasSideEffects(x) {
+			return config.StmtClassRead, nil
+		}
+		return config.StmtClassDML, nil
+	}
+	return config.StmtClassAdmin, nil
+}
+
+// hasSideEffects checks whether a statement which reads only changes something else, e.g. SELECT ... INTO OUTFILE
+// writes a file, and nextval or setval changes the value of a sequence.
+func hasSideEffects(node ast.Node) bool {
+	checker := &sideEffectChecker{}
+	node.Accept(checker)
+	return checker.found
+}
+
+type sideEffectChecker struct {
+	found bool
+}
+
+// Enter implements ast.Visitor interface.
+func (c *sideEffectChecker) Enter(in ast.Node) (ast.Node, bool) {
+	switch x := in.(type) {
+	case *ast.SelectStmt:
+		if x.SelectIntoOpt != nil {
+			c.found = true
+		}
+	case *ast.FuncCallExpr:
+		switch x.FnName.L {
+		case ast.NextVal, ast.SetVal:
+			c.found = true
+		}
+	}
+	return in, c.found
+}
+
+// Leave implements ast.Visitor interface.
+func (c *sideEffectChecker) Leave(in ast.Node) (ast.Node, bool) {
+	return in, !c.found
+}
+
 // querySpecialKeys contains the keys of special query, the special query will handled by handleQuerySpecial method.
 var querySpecialKeys = []fmt.Stringer{
 	executor.LoadDataVarKey,
@@ -1979,6 +2140,7 @@ func runStmt(ctx context.Context, se *session, s sqlexec.Statement) (rs sqlexec.
 	if err != nil {
 		return nil, err
 	}
+	se.txn.onStmtTables(s.(*executor.ExecStmt).RelatedTableIDs())
 	rs, err = s.Exec(ctx)
 	se.updateTelemetryMetric(s.(*executor.ExecStmt))
 	sessVars.TxnCtx.StatementCount++
@@ -2268,6 +2430,9 @@ func (s *session) ExecutePreparedStmt(ctx context.Context, stmtID uint32, args [
 	if !ok {
 		return nil, errors.Errorf("invalid CachedPrepareStmt type")
 	}
+	if err = s.checkStmtClass(preparedStmt.PreparedAst.Stmt); err != nil {
+		return nil, err
+	}
 
 	var is infoschema.InfoSchema
 	var snapshotTS uint64
@@ -2360,6 +2525,10 @@ func (s *session) Txn(active bool) (kv.Transaction, error) {
 			s.sessionVars.SetInTxn(true)
 		}
 		s.sessionVars.TxnCtx.CouldRetry = s.isTxnRetryable()
+		if !s.sessionVars.TxnCtx.CouldRetry && s.isCommutativeRetryable() {
+			s.sessionVars.TxnCtx.CouldRetry = true
+			s.sessionVars.TxnCtx.CommutativeRetry = true
+		}
 		s.txn.SetVars(s.sessionVars.KVVars)
 		readReplicaType := s.sessionVars.GetReplicaRead()
 		if readReplicaType.IsFollowerRead() {
@@ -2411,6 +2580,13 @@ func (s *session) isTxnRetryable() bool {
 	return false
 }
 
+// isCommutativeRetryable (if returns true) means the transaction could retry as long as it only runs commutative updates,
+// which is checked after every statement in finishStmt.
+func (s *session) isCommutativeRetryable() bool {
+	sessVars := s.sessionVars
+	return sessVars.EnableCommutativeRetry && !sessVars.TxnCtx.IsPessimistic && sessVars.RetryLimit > 0
+}
+
 func (s *session) NewTxn(ctx context.Context) error {
 	if err := s.checkBeforeNewTxn(ctx); err != nil {
 		return err
@@ -2538,6 +2714,7 @@ func (s *session) Close() {
 	if s.sessionVars != nil {
 		s.sessionVars.WithdrawAllPreparedStmt()
 	}
+	plannercore.ReleasePlanCache(s.preparedPlanCache)
 	if s.stmtStats != nil {
 		s.stmtStats.SetFinished()
 	}
@@ -2684,7 +2861,7 @@ func CreateSessionWithOpt(store kv.Storage, opt *Opt) (Session, error) {
 
 // loadCollationParameter loads collation parameter from mysql.tidb
 func loadCollationParameter(se *session) (bool, error) {
-	para, err := se.getTableValue(context.TODO(), mysql.TiDBTable, tidbNewCollationEnabled)
+	para, err := se.GetTiDBTableValue(tidbNewCollationEnabled)
 	if err != nil {
 		return false, err
 	}
@@ -2705,7 +2882,7 @@ func loadCollationParameter(se *session) (bool, error) {
 // version is v4.0.9.
 // See the comment upon the function `upgradeToVer54` for details.
 func loadDefMemQuotaQuery(se *session) (int64, error) {
-	_, err := se.getTableValue(context.TODO(), mysql.TiDBTable, tidbDefMemoryQuotaQuery)
+	_, err := se.GetTiDBTableValue(tidbDefMemoryQuotaQuery)
 	if err != nil {
 		if err == errResultIsEmpty {
 			return 1 << 30, nil
@@ -2717,7 +2894,7 @@ func loadDefMemQuotaQuery(se *session) (int64, error) {
 }
 
 func loadDefOOMAction(se *session) (string, error) {
-	defOOMAction, err := se.getTableValue(context.TODO(), mysql.TiDBTable, tidbDefOOMAction)
+	defOOMAction, err := se.GetTiDBTableValue(tidbDefOOMAction)
 	if err != nil {
 		if err == errResultIsEmpty {
 			return config.GetGlobalConfig().OOMAction, nil
@@ -2760,7 +2937,7 @@ func BootstrapSession(store kv.Storage) (*domain.Domain, error) {
 	se.GetSessionVars().InRestrictedSQL = true
 
 	// get system tz from mysql.tidb
-	tz, err := se.getTableValue(context.TODO(), mysql.TiDBTable, "system_tz")
+	tz, err := se.GetTiDBTableValue(tidbSystemTZ)
 	if err != nil {
 		return nil, err
 	}
@@ -2937,8 +3114,7 @@ func createSessionWithOpt(store kv.Storage, opt *Opt) (*session, error) {
 		if opt != nil && opt.PreparedPlanCache != nil {
 			s.preparedPlanCache = opt.PreparedPlanCache
 		} else {
-			s.preparedPlanCache = kvcache.NewSimpleLRUCache(plannercore.PreparedPlanCacheCapacity,
-				plannercore.PreparedPlanCacheMemoryGuardRatio, plannercore.PreparedPlanCacheMaxMemory.Load())
+			s.preparedPlanCache = plannercore.NewPreparedPlanCache()
 		}
 	}
 	s.mu.values = make(map[fmt.Stringer]interface{})
@@ -2968,8 +3144,7 @@ func CreateSessionWithDomain(store kv.Storage, dom *domain.Domain) (*session, er
 		stmtStats:            stmtstats.CreateStatementStats(),
 	}
 	if plannercore.PreparedPlanCacheEnabled() {
-		s.preparedPlanCache = kvcache.NewSimpleLRUCache(plannercore.PreparedPlanCacheCapacity,
-			plannercore.PreparedPlanCacheMemoryGuardRatio, plannercore.PreparedPlanCacheMaxMemory.Load())
+		s.preparedPlanCache = plannercore.NewPreparedPlanCache()
 	}
 	s.mu.values = make(map[fmt.Stringer]interface{})
 	s.lockedTables = make(map[int64]model.TableLockTpInfo)
@@ -893,6 +893,29 @@ func (s *testSessionSuite) TestRetryUnion(c *C) {
 	c.Assert(err, ErrorMatches, ".*can not retry select for update statement")
 }
 
+func (s *testSessionSuite) TestRetryReturning(c *C) {
+	tk := testkit.NewTestKitWithInit(c, s.store)
+	tk1 := testkit.NewTestKitWithInit(c, s.store)
+	tk.MustExec("create table history (a int primary key, b int)")
+	tk.MustExec("insert history values (1, 1)")
+	tk.MustExec("set tidb_disable_txn_auto_retry = 0")
+
+	// The results of RETURNING are already returned to the client, so the txn can't retry.
+	tk.MustExec("begin optimistic")
+	tk.MustQuery("update history set b = b + 1 where a = 1 returning b").Check(testkit.Rows("2"))
+	tk1.MustExec("update history set b = 10 where a = 1")
+	err := tk.ExecToErr("commit")
+	c.Assert(session.ErrReturningCantRetry.Equal(err), IsTrue, Commentf("err %v", err))
+	tk.MustQuery("select b from history").Check(testkit.Rows("10"))
+
+	tk.MustExec("begin optimistic")
+	tk.MustQuery("delete from history where a = 1 returning b").Check(testkit.Rows("10"))
+	tk1.MustExec("update history set b = 20 where a = 1")
+	err = tk.ExecToErr("commit")
+	c.Assert(session.ErrReturningCantRetry.Equal(err), IsTrue, Commentf("err %v", err))
+	tk.MustQuery("select b from history").Check(testkit.Rows("20"))
+}
+
 func (s *testSessionSuite) TestRetryGlobalTempTable(c *C) {
 	tk := testkit.NewTestKitWithInit(c, s.store)
 	tk.MustExec("drop table if exists normal_table")
@@ -1025,6 +1048,118 @@ func (s *testSessionSuite) TestRetryForCurrentTxn(c *C) {
 	tk.MustQuery("select * from history").Check(testkit.Rows("2"))
 }
 
+func (s *testSessionSuite) TestCommutativeRetry(c *C) {
+	tk := testkit.NewTestKitWithInit(c, s.store)
+	tk1 := testkit.NewTestKitWithInit(c, s.store)
+	tk.MustExec("create table counter (id int primary key, k int unique, c int, d int, index(d))")
+	tk.MustExec("insert counter values (1, 1, 0, 0), (2, 2, 0, 0)")
+	tk.MustExec("set tidb_disable_txn_auto_retry = 1")
+	tk.MustExec("set tidb_enable_commutative_retry = 1")
+
+	// The increments by the handles and unique keys are retried.
+	tk.MustExec("begin")
+	tk.MustExec("update counter set c = c + 1 where id = 1")
+	tk.MustExec("update counter set c = 2 + c where k in (1, 2)")
+	tk1.MustExec("update counter set c = c + 10 where id in (1, 2)")
+	tk.MustExec("commit")
+	tk.MustQuery("select c from counter order by id").Check(testkit.Rows("13", "12"))
+
+	// The retry fails if the updated rows change.
+	tk.MustExec("begin")
+	tk.MustExec("update counter set c = c + 1 where id = 1")
+	tk1.MustExec("delete from counter where id = 1")
+	err := tk.ExecToErr("commit")
+	c.Assert(session.ErrCommutativeUpdateCantRetry.Equal(err), IsTrue, Commentf("err %v", err))
+	tk.MustQuery("select c from counter order by id").Check(testkit.Rows("12"))
+
+	// The other statements make the transaction unable to retry.
+	for _, sql := range []string{
+		"select c from counter where id = 2",
+		"update counter set c = 1 where id = 2",
+		"update counter set c = c + 1 where c > 0",
+		"update counter set d = d + 1 where id = 2",
+		"update counter set c = c + 1.5 where id = 2",
+	} {
+		tk.MustExec("begin")
+		tk.MustExec("update counter set c = c + 1 where id = 2")
+		tk.MustExec(sql)
+		tk1.MustExec("update counter set c = c + 1 where id = 2")
+		c.Assert(tk.ExecToErr("commit"), NotNil, Commentf("sql %s", sql))
+	}
+
+	tk.MustExec("set tidb_enable_commutative_retry = 0")
+	tk.MustExec("begin")
+	tk.MustExec("update counter set c = c + 1 where id = 2")
+	tk1.MustExec("update counter set c = c + 1 where id = 2")
+	c.Assert(tk.ExecToErr("commit"), NotNil)
+}
+
+func (s *testSessionSuite) TestAllowedStmtClasses(c *C) {
+	tk := testkit.NewTestKitWithInit(c, s.store)
+	tk.MustExec("create table t (a int primary key)")
+	tk.MustExec("insert t values (1)")
+	tk.MustExec("create sequence seq")
+	defer tk.MustExec("drop sequence seq")
+	tk.MustExec("prepare ins from 'insert t values (?)'")
+	insID, _, _, err := tk.Se.PrepareStmt("insert t values (?)")
+	c.Assert(err, IsNil)
+	selID, _, _, err := tk.Se.PrepareStmt("select a from t where a = ?")
+	c.Assert(err, IsNil)
+
+	defer config.RestoreFunc()()
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.AllowedStmtClasses = []string{config.StmtClassRead, config.StmtClassExplain, config.StmtClassShow, config.StmtClassSession}
+	})
+	for _, sql := range []string{
+		"select * from t",
+		"explain select * from t",
+		"show tables",
+		"set @a = 2",
+		"set tidb_mem_quota_query = 1073741824",
+		"begin",
+		"commit",
+		"prepare sel from 'select a from t where a = ?'",
+		"execute sel using @a",
+		"select lastval(seq)",
+		"do 1",
+	} {
+		tk.MustExec(sql)
+	}
+	for _, sql := range []string{
+		"insert t values (2)",
+		"select * from t for update",
+		"explain analyze delete from t",
+		"execute ins using @a",
+		"create table t1 (a int)",
+		"set global tidb_mem_quota_query = 1073741824",
+		"analyze table t",
+		"select a from t into outfile '/tmp/tidb-stmt-class.txt'",
+		"select nextval(seq)",
+		"select setval(seq, 10)",
+		"do nextval(seq)",
+		"select a from t where a in (select nextval(seq))",
+	} {
+		err := tk.ExecToErr(sql)
+		c.Assert(session.ErrStmtClassNotAllowed.Equal(err), IsTrue, Commentf("sql %s, err %v", sql, err))
+	}
+	rs, err := tk.Se.ExecutePreparedStmt(context.Background(), selID, []types.Datum{types.NewDatum(1)})
+	c.Assert(err, IsNil)
+	tk.ResultSetToResult(rs, Commentf("execute sel")).Check(testkit.Rows("1"))
+	_, err = tk.Se.ExecutePreparedStmt(context.Background(), insID, []types.Datum{types.NewDatum(3)})
+	c.Assert(session.ErrStmtClassNotAllowed.Equal(err), IsTrue, Commentf("err %v", err))
+
+	// The internal statements are not restricted.
+	tk.Se.GetSessionVars().InRestrictedSQL = true
+	tk.MustExec("insert t values (4)")
+	tk.Se.GetSessionVars().InRestrictedSQL = false
+
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.AllowedStmtClasses = nil
+	})
+	tk.MustExec("insert t values (5)")
+	tk.MustQuery("select a from t").Check(testkit.Rows("1", "4", "5"))
+}
+
 // TestTruncateAlloc tests that the auto_increment ID does not reuse the old table's allocator.
 func (s *testSessionSuite) TestTruncateAlloc(c *C) {
 	tk := testkit.NewTestKitWithInit(c, s.store)
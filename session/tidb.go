@@ -32,6 +32,7 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/parser/ast"
+	plannercore "github.com/pingcap/tidb/planner/core"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util"
@@ -220,6 +221,9 @@ func recordAbortTxnDuration(sessVars *variable.SessionVars) {
 
 func finishStmt(ctx context.Context, se *session, meetsErr error, sql sqlexec.Statement) error {
 	sessVars := se.sessionVars
+	if sessVars.TxnCtx.CommutativeRetry && sessVars.TxnCtx.CouldRetry && !isCommutativeStmt(sql) {
+		sessVars.TxnCtx.CouldRetry = false
+	}
 	if !sql.IsReadOnly(sessVars) {
 		// All the history should be added here.
 		if meetsErr == nil && sessVars.TxnCtx.CouldRetry {
@@ -253,6 +257,21 @@ func finishStmt(ctx context.Context, se *session, meetsErr error, sql sqlexec.St
 	return checkStmtLimit(ctx, se)
 }
 
+// isCommutativeStmt checks whether the statement keeps the transaction retryable if the transaction could retry
+// only because it runs commutative updates.
+func isCommutativeStmt(sql sqlexec.Statement) bool {
+	stmt, ok := sql.(*executor.ExecStmt)
+	if !ok {
+		return false
+	}
+	switch stmt.StmtNode.(type) {
+	case *ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt:
+		return true
+	}
+	update, ok := stmt.Plan.(*plannercore.Update)
+	return ok && update.IsCommutative()
+}
+
 func autoCommitAfterStmt(ctx context.Context, se *session, meetsErr error, sql sqlexec.Statement) error {
 	sessVars := se.sessionVars
 	if meetsErr != nil {
@@ -270,8 +289,10 @@ func autoCommitAfterStmt(ctx context.Context, se *session, meetsErr error, sql s
 
 	if !sessVars.InTxn() {
 		if err := se.CommitTxn(ctx); err != nil {
-			if _, ok := sql.(*executor.ExecStmt).StmtNode.(*ast.CommitStmt); ok {
-				err = errors.Annotatef(err, "previous statement: %s", se.GetSessionVars().PrevStmt)
+			if stmt, ok := sql.(*executor.ExecStmt); ok {
+				if _, ok := stmt.StmtNode.(*ast.CommitStmt); ok {
+					err = errors.Annotatef(err, "previous statement: %s", se.GetSessionVars().PrevStmt)
+				}
 			}
 			return err
 		}
@@ -370,5 +391,8 @@ func ResultSetToStringSlice(ctx context.Context, s Session, rs sqlexec.RecordSet
 
 // Session errors.
 var (
-	ErrForUpdateCantRetry = dbterror.ClassSession.NewStd(errno.ErrForUpdateCantRetry)
+	ErrForUpdateCantRetry         = dbterror.ClassSession.NewStd(errno.ErrForUpdateCantRetry)
+	ErrCommutativeUpdateCantRetry = dbterror.ClassSession.NewStd(errno.ErrCommutativeUpdateCantRetry)
+	ErrReturningCantRetry         = dbterror.ClassSession.NewStd(errno.ErrReturningCantRetry)
+	ErrStmtClassNotAllowed        = dbterror.ClassSession.NewStd(errno.ErrStmtClassNotAllowed)
 )
@@ -136,6 +136,7 @@ func (txn *LazyTxn) resetTxnInfo(
 	entriesSize uint64,
 	currentSQLDigest string,
 	allSQLDigests []string,
+	relatedTableIDs []int64,
 ) {
 	txn.mu.TxnInfo = txninfo.TxnInfo{}
 	txn.mu.TxnInfo.StartTS = startTS
@@ -144,6 +145,7 @@ func (txn *LazyTxn) resetTxnInfo(
 	txn.mu.TxnInfo.EntriesSize = entriesSize
 	txn.mu.TxnInfo.CurrentSQLDigest = currentSQLDigest
 	txn.mu.TxnInfo.AllSQLDigests = allSQLDigests
+	txn.mu.TxnInfo.RelatedTableIDs = relatedTableIDs
 }
 
 // Size implements the MemBuffer interface.
@@ -222,6 +224,7 @@ func (txn *LazyTxn) changeInvalidToValid(kvTxn kv.Transaction) {
 		uint64(txn.Transaction.Len()),
 		uint64(txn.Transaction.Size()),
 		"",
+		nil,
 		nil)
 }
 
@@ -247,7 +250,7 @@ func (txn *LazyTxn) changePendingToValid(ctx context.Context) error {
 	txn.Transaction = t
 	txn.initStmtBuf()
 
-	// The txnInfo may already recorded the first statement (usually "begin") when it's pending, so keep them.
+	// The txnInfo may already recorded the first statement (usually "begin") and its tables when it's pending, so keep them.
 	txn.mu.Lock()
 	defer txn.mu.Unlock()
 	txn.resetTxnInfo(
@@ -256,7 +259,8 @@ func (txn *LazyTxn) changePendingToValid(ctx context.Context) error {
 		uint64(txn.Transaction.Len()),
 		uint64(txn.Transaction.Size()),
 		txn.mu.TxnInfo.CurrentSQLDigest,
-		txn.mu.TxnInfo.AllSQLDigests)
+		txn.mu.TxnInfo.AllSQLDigests,
+		txn.mu.TxnInfo.RelatedTableIDs)
 
 	return nil
 }
@@ -290,6 +294,29 @@ func (txn *LazyTxn) onStmtStart(currentSQLDigest string) {
 	}
 }
 
+// onStmtTables records the tables accessed by the statement, they're reported by DDL_BLOCKERS if a DDL job
+// changes them while the transaction is running.
+func (txn *LazyTxn) onStmtTables(tableIDs []int64) {
+	if len(tableIDs) == 0 {
+		return
+	}
+
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	for _, id := range tableIDs {
+		related := false
+		for _, relatedID := range txn.mu.TxnInfo.RelatedTableIDs {
+			if relatedID == id {
+				related = true
+				break
+			}
+		}
+		if !related {
+			txn.mu.TxnInfo.RelatedTableIDs = append(txn.mu.TxnInfo.RelatedTableIDs, id)
+		}
+	}
+}
+
 func (txn *LazyTxn) onStmtEnd() {
 	txn.mu.Lock()
 	txn.mu.TxnInfo.CurrentSQLDigest = ""
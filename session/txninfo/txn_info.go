@@ -83,6 +83,8 @@ type TxnInfo struct {
 	CurrentSQLDigest string
 	// Digests of all SQLs executed in the transaction.
 	AllSQLDigests []string
+	// IDs of the tables accessed by the transaction, the partitions are recorded as their tables.
+	RelatedTableIDs []int64
 
 	// The following fields are mutable and needs to be read or written by atomic operations. But since only the
 	// transaction's thread can modify its value, it's ok for the transaction's thread to read it without atomic
@@ -0,0 +1,178 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+const (
+	// tidbUpgradeState is the variable name in mysql.tidb table, it records the progress of the upgrade running
+	// by a TiDB server, and it's deleted when the upgrade finishes.
+	tidbUpgradeState = "tidb_upgrade_state"
+	// upgradeBatchSize is the number of the upgrade steps whose progress is persisted at once.
+	upgradeBatchSize = 8
+	// upgradeWaitInterval is the interval to check whether the upgrade running by other servers finishes.
+	upgradeWaitInterval = time.Second
+)
+
+// upgradeState is the progress of the upgrade persisted in mysql.tidb table.
+type upgradeState struct {
+	// From and To are the bootstrap versions the store is upgraded from and to.
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+	// Done is the number of the steps of bootstrapVersion done.
+	Done int `json:"done"`
+}
+
+// loadUpgradeState loads the progress of the upgrade, it returns nil if no upgrade is running.
+func loadUpgradeState(s Session) (*upgradeState, error) {
+	sVal, isNull, err := getTiDBVar(s, tidbUpgradeState)
+	if err != nil || isNull {
+		return nil, errors.Trace(err)
+	}
+	state := &upgradeState{}
+	if err = json.Unmarshal([]byte(sVal), state); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return state, nil
+}
+
+func saveUpgradeState(s Session, state *upgradeState) {
+	value, err := json.Marshal(state)
+	if err != nil {
+		logutil.BgLogger().Fatal("[Upgrade] marshal upgrade state failed", zap.Error(err))
+	}
+	mustExecute(s, `INSERT HIGH_PRIORITY INTO %n.%n VALUES (%?, %?, "TiDB upgrade state, don't change it.") ON DUPLICATE KEY UPDATE VARIABLE_VALUE=%?`,
+		mysql.SystemDB, mysql.TiDBTable, tidbUpgradeState, string(value), string(value),
+	)
+}
+
+func clearUpgradeState(s Session) {
+	mustExecute(s, `DELETE HIGH_PRIORITY FROM %n.%n WHERE VARIABLE_NAME=%?`, mysql.SystemDB, mysql.TiDBTable, tidbUpgradeState)
+}
+
+// WaitUpgrade blocks until no TiDB server of a newer version is upgrading the store, so the clients don't see
+// the system tables upgraded halfway. It's called after BootstrapSession, which finishes the upgrade of this
+// server. The state is left behind if the upgrading server crashes, so the callers should bound the wait by ctx.
+func WaitUpgrade(ctx context.Context, store kv.Storage) error {
+	se, err := createSession(store)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer se.Close()
+	for {
+		state, err := loadUpgradeState(se)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ver, err := getBootstrapVersion(se)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		// The state left by an upgrade to a version not newer than the store is stale.
+		if state == nil || state.To <= ver {
+			return nil
+		}
+		logutil.BgLogger().Info("[Upgrade] wait for the upgrade of another TiDB server",
+			zap.Int64("from", state.From),
+			zap.Int64("to", state.To),
+			zap.Int("done steps", state.Done))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(upgradeWaitInterval):
+		}
+	}
+}
+
+// UpgradeReport describes what BootstrapSession would do to the store.
+type UpgradeReport struct {
+	// From is the bootstrap version of the store, it's 0 if the store is not bootstrapped.
+	From int64
+	// To is the bootstrap version of this TiDB server.
+	To int64
+	// Steps are the upgrade steps to run.
+	Steps []string
+}
+
+// String implements the fmt.Stringer interface.
+func (r *UpgradeReport) String() string {
+	var sb strings.Builder
+	switch {
+	case r.From == notBootstrapped:
+		fmt.Fprintf(&sb, "the store is not bootstrapped, it will be bootstrapped to version %d", r.To)
+	case r.From >= r.To:
+		fmt.Fprintf(&sb, "the store is at version %d, no upgrade is needed by version %d", r.From, r.To)
+	default:
+		fmt.Fprintf(&sb, "the store will be upgraded from version %d to %d in %d steps:", r.From, r.To, len(r.Steps))
+		for _, step := range r.Steps {
+			sb.WriteString("\n  ")
+			sb.WriteString(step)
+		}
+	}
+	return sb.String()
+}
+
+// DryRunUpgrade reports what BootstrapSession would do to the store without changing it.
+func DryRunUpgrade(store kv.Storage) (*UpgradeReport, error) {
+	var ver int64
+	err := kv.RunInNewTxn(context.Background(), store, false, func(ctx context.Context, txn kv.Transaction) error {
+		var err error
+		ver, err = meta.NewMeta(txn).GetBootstrapVersion()
+		return err
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	report := &UpgradeReport{From: ver, To: currentBootstrapVersion}
+	if ver == notBootstrapped || ver >= currentBootstrapVersion {
+		return report, nil
+	}
+	for _, upgrade := range bootstrapVersion {
+		name, stepVer := upgradeStepName(upgrade)
+		// A step whose version is unknown may do something.
+		if stepVer == 0 || stepVer > ver {
+			report.Steps = append(report.Steps, name)
+		}
+	}
+	return report, nil
+}
+
+// upgradeStepName returns the name of the upgrade step and the version it upgrades to, the version is 0 if it's
+// unknown.
+func upgradeStepName(upgrade func(Session, int64)) (string, int64) {
+	name := runtime.FuncForPC(reflect.ValueOf(upgrade).Pointer()).Name()
+	name = name[strings.LastIndex(name, ".")+1:]
+	ver, err := strconv.ParseInt(strings.TrimPrefix(name, "upgradeToVer"), 10, 64)
+	if err != nil {
+		return name, 0
+	}
+	return name, ver
+}
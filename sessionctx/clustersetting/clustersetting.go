@@ -0,0 +1,167 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clustersetting defines the typed cluster settings. The settings are registered by
+// the packages maintaining them and stored in the mysql.tidb table, which is only accessed by
+// this package. They are shown by ADMIN SHOW CLUSTER SETTINGS and changed by ADMIN SET CLUSTER
+// SETTING or the SET GLOBAL statements of the related system variables, every change made by
+// the users is recorded in mysql.cluster_setting_history.
+package clustersetting
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	mysql "github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/util/dbterror"
+)
+
+// Error instances.
+var (
+	ErrUnknownClusterSetting       = dbterror.ClassVariable.NewStd(mysql.ErrUnknownClusterSetting)
+	ErrClusterSettingReadOnly      = dbterror.ClassVariable.NewStd(mysql.ErrClusterSettingReadOnly)
+	ErrWrongValueForClusterSetting = dbterror.ClassVariable.NewStd(mysql.ErrWrongValueForClusterSetting)
+)
+
+// Type is the type of the value of a cluster setting.
+type Type int
+
+// The types of the cluster settings.
+const (
+	TypeString Type = iota
+	TypeBool
+	TypeInt
+	TypeDuration
+	TypeEnum
+)
+
+// String implements the fmt.Stringer interface.
+func (t Type) String() string {
+	switch t {
+	case TypeBool:
+		return "bool"
+	case TypeInt:
+		return "int"
+	case TypeDuration:
+		return "duration"
+	case TypeEnum:
+		return "enum"
+	default:
+		return "string"
+	}
+}
+
+// Setting is a cluster setting, its value is stored as a row of mysql.tidb.
+type Setting struct {
+	// Name is the variable_name in mysql.tidb.
+	Name    string
+	Type    Type
+	Default string
+	// Comment is written to mysql.tidb with the value.
+	Comment string
+	// ReadOnly means the setting is maintained by TiDB itself, such as the bootstrap version
+	// and the GC safe point, it can't be changed by ADMIN SET CLUSTER SETTING.
+	ReadOnly bool
+	// MinValue and MaxValue are the bounds of a TypeInt setting.
+	MinValue int64
+	MaxValue int64
+	// MinDuration is the lower bound of a TypeDuration setting.
+	MinDuration time.Duration
+	// PossibleValues are the lower-case values allowed by a TypeEnum setting.
+	PossibleValues []string
+}
+
+// Validate checks the value against the type of the setting, and returns the value in the format
+// stored in mysql.tidb: booleans are "true" or "false", durations are in the Go format.
+func (s *Setting) Validate(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch s.Type {
+	case TypeBool:
+		switch strings.ToLower(value) {
+		case "true", "on", "1":
+			return "true", nil
+		case "false", "off", "0":
+			return "false", nil
+		}
+		return "", s.errWrongValue(value, "it should be true or false")
+	case TypeInt:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", s.errWrongValue(value, "it should be an integer")
+		}
+		if v < s.MinValue || v > s.MaxValue {
+			return "", s.errWrongValue(value, fmt.Sprintf("it should be in [%d, %d]", s.MinValue, s.MaxValue))
+		}
+		return strconv.FormatInt(v, 10), nil
+	case TypeDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return "", s.errWrongValue(value, "it should be a duration in the Go format, e.g. 10m")
+		}
+		if d < s.MinDuration {
+			return "", s.errWrongValue(value, fmt.Sprintf("it should be at least %s", s.MinDuration))
+		}
+		return d.String(), nil
+	case TypeEnum:
+		for _, v := range s.PossibleValues {
+			if strings.EqualFold(v, value) {
+				return v, nil
+			}
+		}
+		return "", s.errWrongValue(value, fmt.Sprintf("it should be one of %s", strings.Join(s.PossibleValues, ", ")))
+	}
+	return value, nil
+}
+
+func (s *Setting) errWrongValue(value, reason string) error {
+	return ErrWrongValueForClusterSetting.GenWithStackByArgs(s.Name, value, reason)
+}
+
+var settings = struct {
+	sync.RWMutex
+	m map[string]*Setting
+}{m: make(map[string]*Setting)}
+
+// Register registers a cluster setting, the name is case insensitive.
+func Register(s *Setting) {
+	settings.Lock()
+	defer settings.Unlock()
+	s.Name = strings.ToLower(s.Name)
+	settings.m[s.Name] = s
+}
+
+// Get returns the registered setting, or nil if it doesn't exist.
+func Get(name string) *Setting {
+	settings.RLock()
+	defer settings.RUnlock()
+	return settings.m[strings.ToLower(name)]
+}
+
+// GetAll returns all the registered settings ordered by the names.
+func GetAll() []*Setting {
+	settings.RLock()
+	defer settings.RUnlock()
+	all := make([]*Setting, 0, len(settings.m))
+	for _, s := range settings.m {
+		all = append(all, s)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+	return all
+}
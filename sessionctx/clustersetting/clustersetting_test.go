@@ -0,0 +1,108 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustersetting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/parser/terror"
+	"github.com/stretchr/testify/require"
+)
+
+var testSettings = []*Setting{
+	{Name: "test_bool", Type: TypeBool, Default: "true"},
+	{Name: "test_int", Type: TypeInt, Default: "2", MinValue: 1, MaxValue: 128},
+	{Name: "test_duration", Type: TypeDuration, Default: "10m0s", MinDuration: 10 * time.Minute},
+	{Name: "test_enum", Type: TypeEnum, Default: "b", PossibleValues: []string{"a", "b"}},
+	{Name: "test_read_only", ReadOnly: true},
+}
+
+func registerTestSettings(t *testing.T) {
+	for _, s := range testSettings {
+		Register(s)
+	}
+	t.Cleanup(func() {
+		settings.Lock()
+		defer settings.Unlock()
+		for _, s := range testSettings {
+			delete(settings.m, s.Name)
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	registerTestSettings(t)
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+		valid    bool
+	}{
+		{"test_bool", "ON", "true", true},
+		{"test_bool", " 0 ", "false", true},
+		{"test_bool", "yes", "", false},
+		{"test_int", "128", "128", true},
+		{"test_int", "0", "", false},
+		{"test_int", "1.5", "", false},
+		{"test_duration", "24h", "24h0m0s", true},
+		{"test_duration", "90m", "1h30m0s", true},
+		{"test_duration", "9m59s", "", false},
+		{"test_duration", "10", "", false},
+		{"test_enum", "A", "a", true},
+		{"test_enum", "c", "", false},
+	}
+	for _, test := range tests {
+		s := Get(test.name)
+		require.NotNil(t, s, test.name)
+		v, err := s.Validate(test.value)
+		if !test.valid {
+			require.True(t, terror.ErrorEqual(err, ErrWrongValueForClusterSetting), "%s = %s", test.name, test.value)
+			continue
+		}
+		require.NoError(t, err, "%s = %s", test.name, test.value)
+		require.Equal(t, test.expected, v)
+	}
+
+	err := (&Setting{Name: "tikv_gc_concurrency", Type: TypeInt, MinValue: 1, MaxValue: 128}).errWrongValue("0", "it should be in [1, 128]")
+	require.EqualError(t, err, "[variable:8246]Cluster setting 'tikv_gc_concurrency' can't be set to the value of '0': it should be in [1, 128]")
+}
+
+func TestRegistry(t *testing.T) {
+	registerTestSettings(t)
+	require.Nil(t, Get("test_unknown"))
+	require.Same(t, Get("test_duration"), Get("Test_Duration"))
+	require.True(t, Get("test_read_only").ReadOnly)
+	require.False(t, Get("test_duration").ReadOnly)
+
+	all := GetAll()
+	for i := 1; i < len(all); i++ {
+		require.Less(t, all[i-1].Name, all[i].Name)
+	}
+
+	s := &Setting{Name: "Test_Setting", Type: TypeString, Default: "a"}
+	Register(s)
+	defer func() {
+		settings.Lock()
+		delete(settings.m, s.Name)
+		settings.Unlock()
+	}()
+	require.Equal(t, "test_setting", s.Name)
+	require.Same(t, s, Get("TEST_SETTING"))
+	require.Len(t, GetAll(), len(all)+1)
+	v, err := s.Validate(" b ")
+	require.NoError(t, err)
+	require.Equal(t, "b", v)
+}
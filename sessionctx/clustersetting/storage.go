@@ -0,0 +1,108 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustersetting
+
+import (
+	"context"
+
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+// The functions in this file execute the statements in the session passed in, rather than
+// a session of the pool, so the session must be dedicated to the internal statements, such as
+// the ones of the GC worker and the system session pool.
+var useCurSession = []sqlexec.OptionFuncAlias{sqlexec.ExecOptionUseCurSession}
+
+// Executor executes the statements in a transaction of the session, it's required by Change.
+type Executor interface {
+	sqlexec.SQLExecutor
+	sqlexec.RestrictedSQLExecutor
+}
+
+// Load returns the stored value of the setting, ok is false if the value isn't stored.
+func Load(ctx context.Context, exec sqlexec.RestrictedSQLExecutor, name string) (value string, ok bool, err error) {
+	rows, _, err := exec.ExecRestrictedSQL(ctx, useCurSession, "SELECT HIGH_PRIORITY variable_value FROM %n.%n WHERE variable_name = %?",
+		mysql.SystemDB, mysql.TiDBTable, name)
+	if err != nil || len(rows) == 0 {
+		return "", false, err
+	}
+	return rows[0].GetString(0), true, nil
+}
+
+// LoadAll returns the stored values of all the settings.
+func LoadAll(ctx context.Context, exec sqlexec.RestrictedSQLExecutor) (map[string]string, error) {
+	rows, _, err := exec.ExecRestrictedSQL(ctx, useCurSession, "SELECT HIGH_PRIORITY variable_name, variable_value FROM %n.%n", mysql.SystemDB, mysql.TiDBTable)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(rows))
+	for _, row := range rows {
+		values[row.GetString(0)] = row.GetString(1)
+	}
+	return values, nil
+}
+
+// Store writes the value of the setting without recording it in mysql.cluster_setting_history,
+// it's used by TiDB itself to maintain the settings, such as the GC leader lease.
+func Store(ctx context.Context, exec sqlexec.RestrictedSQLExecutor, name, value string) error {
+	s := Get(name)
+	if s == nil {
+		return ErrUnknownClusterSetting.GenWithStackByArgs(name)
+	}
+	_, _, err := exec.ExecRestrictedSQL(ctx, useCurSession, "INSERT HIGH_PRIORITY INTO %n.%n VALUES (%?, %?, %?) ON DUPLICATE KEY UPDATE variable_value = %?, comment = %?",
+		mysql.SystemDB, mysql.TiDBTable, s.Name, value, s.Comment, value, s.Comment)
+	return err
+}
+
+// Change writes the value of the setting and records the change made by the user in
+// mysql.cluster_setting_history. The value isn't validated, and the old value is locked in a
+// pessimistic transaction of exec, so the concurrent changes of the same setting are recorded in order.
+func Change(ctx context.Context, exec Executor, name, value, user, host string) error {
+	s := Get(name)
+	if s == nil {
+		return ErrUnknownClusterSetting.GenWithStackByArgs(name)
+	}
+	if _, err := exec.ExecuteInternal(ctx, "begin pessimistic"); err != nil {
+		return err
+	}
+	if err := change(ctx, exec, s, value, user, host); err != nil {
+		if _, rollbackErr := exec.ExecuteInternal(ctx, "rollback"); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+	_, err := exec.ExecuteInternal(ctx, "commit")
+	return err
+}
+
+func change(ctx context.Context, exec Executor, s *Setting, value, user, host string) error {
+	rows, _, err := exec.ExecRestrictedSQL(ctx, useCurSession,
+		"SELECT variable_value FROM %n.%n WHERE variable_name = %? FOR UPDATE", mysql.SystemDB, mysql.TiDBTable, s.Name)
+	if err != nil {
+		return err
+	}
+	var oldValue interface{}
+	if len(rows) > 0 {
+		oldValue = rows[0].GetString(0)
+	}
+	if _, err = exec.ExecuteInternal(ctx, "REPLACE HIGH_PRIORITY INTO %n.%n (variable_name, variable_value, comment) VALUES (%?, %?, %?)",
+		mysql.SystemDB, mysql.TiDBTable, s.Name, value, s.Comment); err != nil {
+		return err
+	}
+	_, err = exec.ExecuteInternal(ctx, "INSERT HIGH_PRIORITY INTO %n.%n (setting_name, old_value, new_value, user, host) VALUES (%?, %?, %?, %?, %?)",
+		mysql.SystemDB, mysql.ClusterSettingHistoryTable, s.Name, oldValue, value, user, host)
+	return err
+}
@@ -16,6 +16,7 @@ package stmtctx
 
 import (
 	"math"
+	"math/rand"
 	"sort"
 	"strconv"
 	"sync"
@@ -46,11 +47,16 @@ const (
 	WarnLevelNote = "Note"
 )
 
-var taskIDAlloc uint64
+// TaskIDSeqBits is the number of the low bits of the task IDs reserved for the sequence numbers of the cop tasks.
+const TaskIDSeqBits = 16
 
-// AllocateTaskID allocates a new unique ID for a statement execution
+// taskIDAlloc starts from a random ID, so the task IDs of the TiDB instances hardly collide in the TiKV logs.
+var taskIDAlloc = uint64(rand.New(rand.NewSource(time.Now().UnixNano())).Int63()) &^ (1<<TaskIDSeqBits - 1) // #nosec G404
+
+// AllocateTaskID allocates a new unique ID for a statement execution, it's sent to TiKV in kvrpcpb.Context as the
+// trace ID of the statement. The low TaskIDSeqBits bits of it are 0.
 func AllocateTaskID() uint64 {
-	return atomic.AddUint64(&taskIDAlloc, 1)
+	return atomic.AddUint64(&taskIDAlloc, 1<<TaskIDSeqBits)
 }
 
 // SQLWarn relates a sql warning and it's level.
@@ -178,6 +184,8 @@ type StatementContext struct {
 	TblInfo2UnionScan     map[*model.TableInfo]bool
 	TaskID                uint64 // unique ID for an execution of a statement
 	TaskMapBakTS          uint64 // counter for
+	// copTaskSeq is the last sequence number allocated to the cop tasks of the statement.
+	copTaskSeq uint64
 
 	// stmtCache is used to store some statement-related values.
 	stmtCache map[StmtCacheKey]interface{}
@@ -238,6 +246,9 @@ type StatementContext struct {
 
 	// SysdateIsNow indicates whether sysdate() is an alias of now() in this statement
 	SysdateIsNow bool
+
+	// Queued indicates whether the statement is waiting in the admission queue, it's shown in the processlist.
+	Queued atomic2.Bool
 }
 
 // StmtHints are SessionVars related sql hints.
@@ -254,6 +265,9 @@ type StmtHints struct {
 	// ForceNthPlan indicates the PlanCounterTp number for finding physical plan.
 	// -1 for disable.
 	ForceNthPlan int64
+	// QueryType is the query type given by the QUERY_TYPE hint, "olap" or "oltp". It classifies the statement in the
+	// admission queue instead of the estimated cost.
+	QueryType string
 
 	// Hint flags
 	HasAllowInSubqToJoinAndAggHint bool
@@ -261,6 +275,7 @@ type StmtHints struct {
 	HasReplicaReadHint             bool
 	HasMaxExecutionTime            bool
 	HasEnableCascadesPlannerHint   bool
+	HasQueryTypeHint               bool
 	SetVars                        map[string]string
 }
 
@@ -642,6 +657,14 @@ func (sc *StatementContext) ResetForRetry() {
 	sc.TableIDs = sc.TableIDs[:0]
 	sc.IndexNames = sc.IndexNames[:0]
 	sc.TaskID = AllocateTaskID()
+	atomic.StoreUint64(&sc.copTaskSeq, 0)
+}
+
+// AllocCopTaskSeq allocates the sequence number of a cop task of the statement, which is added to TaskID in the
+// requests of the task. The sequence numbers start from 1 and wrap around if there are too many cop tasks.
+func (sc *StatementContext) AllocCopTaskSeq() uint64 {
+	seq := atomic.AddUint64(&sc.copTaskSeq, 1)
+	return (seq-1)%(1<<TaskIDSeqBits-1) + 1
 }
 
 // MergeExecDetails merges a single region execution details into self, used to print
@@ -69,6 +69,24 @@ func TestCopTasksDetails(t *testing.T) {
 	}
 }
 
+func TestCopTaskSeq(t *testing.T) {
+	id1, id2 := stmtctx.AllocateTaskID(), stmtctx.AllocateTaskID()
+	require.NotEqual(t, id1, id2)
+	require.Zero(t, id1&(1<<stmtctx.TaskIDSeqBits-1))
+	require.Zero(t, id2&(1<<stmtctx.TaskIDSeqBits-1))
+
+	sc := new(stmtctx.StatementContext)
+	require.Equal(t, uint64(1), sc.AllocCopTaskSeq())
+	require.Equal(t, uint64(2), sc.AllocCopTaskSeq())
+	sc.ResetForRetry()
+	require.Equal(t, uint64(1), sc.AllocCopTaskSeq())
+	for i := 2; i <= 1<<stmtctx.TaskIDSeqBits-1; i++ {
+		sc.AllocCopTaskSeq()
+	}
+	// The sequence numbers wrap around without touching the bits of the task ID.
+	require.Equal(t, uint64(1), sc.AllocCopTaskSeq())
+}
+
 func TestStatementContextPushDownFLags(t *testing.T) {
 	testCases := []struct {
 		in  *stmtctx.StatementContext
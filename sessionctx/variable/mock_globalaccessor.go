@@ -100,6 +100,6 @@ func (m *MockGlobalAccessor) GetTiDBTableValue(name string) (string, error) {
 }
 
 // SetTiDBTableValue implements GlobalVarAccessor.SetTiDBTableValue interface.
-func (m *MockGlobalAccessor) SetTiDBTableValue(name, value, comment string) error {
+func (m *MockGlobalAccessor) SetTiDBTableValue(name, value string) error {
 	panic("not supported")
 }
@@ -275,7 +275,6 @@ var noopSysVars = []*SysVar{
 	{Scope: ScopeNone, Name: "performance_schema_digests_size", Value: "10000"},
 	{Scope: ScopeGlobal | ScopeSession, Name: Profiling, Value: Off, Type: TypeBool},
 	{Scope: ScopeGlobal, Name: "sha256_password_proxy_users", Value: ""},
-	{Scope: ScopeGlobal | ScopeSession, Name: SQLQuoteShowCreate, Value: On, Type: TypeBool},
 	{Scope: ScopeGlobal | ScopeSession, Name: "binlogging_impossible_mode", Value: "IGNORE_ERROR"},
 	{Scope: ScopeGlobal | ScopeSession, Name: QueryCacheSize, Value: "1048576"},
 	{Scope: ScopeGlobal, Name: "innodb_stats_transient_sample_pages", Value: "8"},
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"crypto/tls"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"math"
 	"math/rand"
@@ -32,6 +33,7 @@ import (
 	"github.com/pingcap/errors"
 	pumpcli "github.com/pingcap/tidb-tools/tidb-binlog/pump_client"
 	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/ddl/placement"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/metrics"
@@ -47,6 +49,7 @@ import (
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/execdetails"
 	utilMath "github.com/pingcap/tidb/util/math"
+	"github.com/pingcap/tidb/util/netsim"
 	"github.com/pingcap/tidb/util/rowcodec"
 	"github.com/pingcap/tidb/util/stringutil"
 	"github.com/pingcap/tidb/util/tableutil"
@@ -162,6 +165,9 @@ type TransactionContext struct {
 	StatementCount int
 	CouldRetry     bool
 	IsPessimistic  bool
+	// CommutativeRetry indicates the txn could retry only because it runs commutative updates, CouldRetry is reset
+	// once it runs other statements, since their results returned to the client may change in the retry.
+	CommutativeRetry bool
 	// IsStaleness indicates whether the txn is read only staleness txn.
 	IsStaleness bool
 	// IsExplicit indicates whether the txn is an interactive txn, which is typically started with a BEGIN
@@ -170,6 +176,9 @@ type TransactionContext struct {
 	Isolation  string
 	LockExpire uint32
 	ForUpdate  uint32
+	// Returning indicates the txn runs a DML with a RETURNING clause, the txn can't retry since the results are
+	// already returned to the client.
+	Returning uint32
 	// TxnScope indicates the value of txn_scope
 	TxnScope string
 
@@ -878,6 +887,32 @@ type SessionVars struct {
 	// see https://dev.mysql.com/doc/refman/8.0/en/window-function-optimization.html for more details.
 	WindowingUseHighPrecision bool
 
+	// QuoteShowCreate indicates whether the identifiers are always quoted in the output of SHOW CREATE statements.
+	QuoteShowCreate bool
+
+	// ShowCreateTableMySQLCompatible indicates whether SHOW CREATE TABLE and DATABASE omit the TiDB specific attributes.
+	ShowCreateTableMySQLCompatible bool
+
+	// PlanCacheRowDeviationRatio is how many times the actual rows can exceed the estimated ones for a cached plan,
+	// 0 disables the check.
+	PlanCacheRowDeviationRatio float64
+
+	// PlanCacheRowDeviationExecutions is the number of the consecutive deviated executions evicting a cached plan.
+	PlanCacheRowDeviationExecutions int
+
+	// OptimizerFixControl maps the numbered fixes of the optimizer to their values set by tidb_opt_fix_control.
+	OptimizerFixControl map[uint64]string
+
+	// MaxRowSize is the max size of a row written to a table besides the txn-entry-size-limit, 0 means no limit.
+	MaxRowSize uint64
+
+	// MaxColumnSize is the max size of a column value written to a table, 0 means no limit.
+	MaxColumnSize uint64
+
+	// TableStatsEstimation is how the rows and sizes of the tables in information_schema are estimated, see
+	// TiDBTableStatsEstimation.
+	TableStatsEstimation string
+
 	// FoundInPlanCache indicates whether this statement was found in plan cache.
 	FoundInPlanCache bool
 	// PrevFoundInPlanCache indicates whether the last statement was found in plan cache.
@@ -906,6 +941,12 @@ type SessionVars struct {
 	// EnableRedactLog indicates that whether redact log.
 	EnableRedactLog bool
 
+	// EnableErrorDocURL indicates whether the documentation URL is appended to the error messages sent to the client.
+	EnableErrorDocURL bool
+
+	// EnableStableSQLState indicates whether the SQLSTATE of the errors sent to the client is mapped by errno.SQLState.
+	EnableStableSQLState bool
+
 	// ShardAllocateStep indicates the max size of continuous rowid shard in one transaction.
 	ShardAllocateStep int64
 
@@ -971,6 +1012,22 @@ type SessionVars struct {
 	// RegardNULLAsPoint if regard NULL as Point
 	RegardNULLAsPoint bool
 
+	// EnableNullAwareAntiJoin indicates whether to build the hash join keys from the conditions of NOT IN
+	// on nullable columns, instead of evaluating them as a cartesian anti semi join.
+	EnableNullAwareAntiJoin bool
+
+	// EnablePartitionWiseJoin indicates whether to rewrite the join of two tables partitioned identically on
+	// the join key into a union of the joins of the partition pairs, under the static partition prune mode.
+	EnablePartitionWiseJoin bool
+
+	// ResultFlushThreshold is the number of buffered bytes of the query result to flush to the client, so the
+	// client receives the rows earlier. 0 means the result is flushed only when the write buffer is full.
+	ResultFlushThreshold int
+
+	// EnableCommutativeRetry indicates whether the optimistic transactions only running commutative updates could retry
+	// when tidb_disable_txn_auto_retry is on. See TransactionContext.CommutativeRetry.
+	EnableCommutativeRetry bool
+
 	// LocalTemporaryTables is *infoschema.LocalTemporaryTables, use interface to avoid circle dependency.
 	// It's nil if there is no local temporary table.
 	LocalTemporaryTables interface{}
@@ -984,6 +1041,56 @@ type SessionVars struct {
 	// MPPStoreFailTTL indicates the duration that protect TiDB from sending task to a new recovered TiFlash.
 	MPPStoreFailTTL string
 
+	// TiFlashPreferLocalZone indicates whether the batch cop and MPP tasks prefer the TiFlash stores in the same zone as TiDB.
+	TiFlashPreferLocalZone bool
+
+	// TiFlashReplicaRead decides the TiFlash stores the batch cop and MPP tasks are sent to.
+	TiFlashReplicaRead string
+
+	// TiFlashReplicaReadNodes are the addresses of the TiFlash stores used if TiFlashReplicaRead is "specified_nodes".
+	TiFlashReplicaReadNodes []string
+
+	// BatchCopResponseChannelSize is the number of the batch cop responses that can be queued before being consumed.
+	BatchCopResponseChannelSize int
+
+	// BatchCopResponseMaxBytes limits the bytes of the queued batch cop responses, 0 means no limit.
+	BatchCopResponseMaxBytes int64
+
+	// EnableBatchCopResponseSpill indicates whether the batch cop responses are spilled to the disk once the memory
+	// quota of the query is exceeded.
+	EnableBatchCopResponseSpill bool
+
+	// EnableTiKVBatchCop indicates whether the table scans on TiKV are sent by the batch coprocessor requests.
+	EnableTiKVBatchCop bool
+
+	// TraceCopTaskSeq indicates whether the sequence numbers of the cop tasks are added to the task IDs sent to TiKV.
+	TraceCopTaskSeq bool
+
+	// MaxRegionsPerBatchCopTask limits the number of the regions in a batch cop task, 0 means no limit.
+	MaxRegionsPerBatchCopTask int
+
+	// BatchCopConcurrency limits the number of the running batch cop tasks of a request, 0 means no limit.
+	BatchCopConcurrency int
+
+	// BatchCopStreamStallThreshold is how long a batch cop stream waits for the next response before it's regarded
+	// as stalled, 0 means no detection.
+	BatchCopStreamStallThreshold time.Duration
+
+	// BatchCopStreamStallReconnect indicates whether the stalled batch cop streams are cancelled and sent again.
+	BatchCopStreamStallReconnect bool
+
+	// EnableBatchCopTaskCache indicates whether the layouts of the batch cop tasks are cached.
+	EnableBatchCopTaskCache bool
+
+	// TiFlashFallbackRangesToTiKV indicates whether the regions without available TiFlash replicas are read from TiKV.
+	TiFlashFallbackRangesToTiKV bool
+
+	// TmpStorageQuota limits the bytes of the temporary storage a statement spills to, the negative values mean no limit.
+	TmpStorageQuota int64
+
+	// ReadOnlyStmtRetryLimit is the max times to re-execute a read-only statement meeting a transient store error.
+	ReadOnlyStmtRetryLimit int
+
 	// ReadStaleness indicates the staleness duration for the following query
 	ReadStaleness time.Duration
 
@@ -1011,6 +1118,9 @@ type SessionVars struct {
 	EnableMutationChecker bool
 	// AssertionLevel controls how strict the assertions on data mutations should be.
 	AssertionLevel AssertionLevel
+	// StoreNetworkSimulation is the simulated network between TiDB and the stores for the KV requests of the session,
+	// it only takes effect in the failpoint-enabled builds.
+	StoreNetworkSimulation netsim.Simulation
 }
 
 // InitStatementContext initializes a StatementContext, the object is reused to reduce allocation.
@@ -1224,6 +1334,7 @@ func NewSessionVars() *SessionVars {
 		MetricSchemaRangeDuration:   DefTiDBMetricSchemaRangeDuration,
 		SequenceState:               NewSequenceState(),
 		WindowingUseHighPrecision:   true,
+		QuoteShowCreate:             true,
 		PrevFoundInPlanCache:        DefTiDBFoundInPlanCache,
 		FoundInPlanCache:            DefTiDBFoundInPlanCache,
 		PrevFoundInBinding:          DefTiDBFoundInBinding,
@@ -1249,6 +1360,11 @@ func NewSessionVars() *SessionVars {
 		TMPTableSize:                DefTiDBTmpTableMaxSize,
 		MPPStoreLastFailTime:        make(map[string]time.Time),
 		MPPStoreFailTTL:             DefTiDBMPPStoreFailTTL,
+		TiFlashReplicaRead:          DefTiDBTiFlashReplicaRead,
+		BatchCopResponseChannelSize: DefTiDBBatchCopResponseChannelSize,
+		BatchCopResponseMaxBytes:    DefTiDBBatchCopResponseMaxBytes,
+		TableStatsEstimation:        DefTiDBTableStatsEstimation,
+		TmpStorageQuota:             DefTiDBTmpStorageQuota,
 		Rng:                         utilMath.NewWithTime(),
 		StatsLoadSyncWait:           StatsLoadSyncWait.Load(),
 	}
@@ -1380,6 +1496,15 @@ func (s *SessionVars) SetEnablePseudoForOutdatedStats(val bool) {
 	s.EnablePseudoForOutdatedStats = val
 }
 
+// GetMaxExecutionTime gets the max execution time of the statement in milliseconds,
+// the MAX_EXECUTION_TIME hint takes precedence over the session variable.
+func (s *SessionVars) GetMaxExecutionTime() uint64 {
+	if s.StmtCtx.HasMaxExecutionTime {
+		return s.StmtCtx.MaxExecutionTime
+	}
+	return s.MaxExecutionTime
+}
+
 // GetReplicaRead get ReplicaRead from sql hints and SessionVars.replicaRead.
 func (s *SessionVars) GetReplicaRead() kv.ReplicaReadType {
 	if s.StmtCtx.HasReplicaReadHint {
@@ -1388,6 +1513,24 @@ func (s *SessionVars) GetReplicaRead() kv.ReplicaReadType {
 	return s.replicaRead
 }
 
+// GetTiFlashPreferredZone returns the zone whose TiFlash stores are preferred by the batch cop and MPP tasks,
+// it's empty if there is no preference.
+func (s *SessionVars) GetTiFlashPreferredZone() string {
+	if !s.TiFlashPreferLocalZone && s.TiFlashReplicaRead != TiFlashReplicaReadClosestAdaptive {
+		return ""
+	}
+	return config.GetGlobalConfig().Labels[placement.DCLabelKey]
+}
+
+// GetTiFlashReplicaReadNodes returns the addresses of the TiFlash stores the batch cop and MPP tasks are restricted
+// to, it's empty if all the TiFlash stores can be used.
+func (s *SessionVars) GetTiFlashReplicaReadNodes() []string {
+	if s.TiFlashReplicaRead != TiFlashReplicaReadSpecifiedNodes {
+		return nil
+	}
+	return s.TiFlashReplicaReadNodes
+}
+
 // SetReplicaRead set SessionVars.replicaRead.
 func (s *SessionVars) SetReplicaRead(val kv.ReplicaReadType) {
 	s.replicaRead = val
@@ -1950,6 +2093,8 @@ const (
 	SlowLogTimeStr = "Time"
 	// SlowLogStartPrefixStr is slow log start row prefix.
 	SlowLogStartPrefixStr = SlowLogRowPrefixStr + SlowLogTimeStr + SlowLogSpaceMarkStr
+	// SlowLogJSONStartPrefixStr is the prefix of slow log entries in json format, which are in one line.
+	SlowLogJSONStartPrefixStr = `{"` + SlowLogTimeStr + `":`
 	// SlowLogTxnStartTSStr is slow log field name.
 	SlowLogTxnStartTSStr = "Txn_start_ts"
 	// SlowLogUserAndHostStr is the user and host field name, which is compatible with MySQL.
@@ -1960,6 +2105,8 @@ const (
 	SlowLogHostStr = "Host"
 	// SlowLogConnIDStr is slow log field name.
 	SlowLogConnIDStr = "Conn_ID"
+	// SlowLogTraceIDStr is the trace ID of the statement, which is the task ID of the requests sent to TiKV.
+	SlowLogTraceIDStr = "Trace_id"
 	// SlowLogQueryTimeStr is slow log field name.
 	SlowLogQueryTimeStr = "Query_time"
 	// SlowLogParseTimeStr is the parse sql time.
@@ -2042,6 +2189,10 @@ const (
 	SlowLogBackoffTotal = "Backoff_total"
 	// SlowLogWriteSQLRespTotal is the total time used to write response to client.
 	SlowLogWriteSQLRespTotal = "Write_sql_response_total"
+	// SlowLogClientStallTotal is the total time blocked in writing response to client, waiting for the client to read it.
+	SlowLogClientStallTotal = "Client_stall_total"
+	// SlowLogTiDBExecTime is the time TiDB spends on executing the statement, excluding the time waiting for TiKV, PD, the backoff and the client.
+	SlowLogTiDBExecTime = "Tidb_exec_time"
 	// SlowLogExecRetryCount is the execution retry count.
 	SlowLogExecRetryCount = "Exec_retry_count"
 	// SlowLogExecRetryTime is the execution retry time.
@@ -2060,6 +2211,7 @@ const (
 // slow query log.
 type SlowQueryLogItems struct {
 	TxnTS             uint64
+	TraceID           uint64
 	SQL               string
 	Digest            string
 	TimeTotal         time.Duration
@@ -2086,6 +2238,8 @@ type SlowQueryLogItems struct {
 	PDTotal           time.Duration
 	BackoffTotal      time.Duration
 	WriteSQLRespTotal time.Duration
+	ClientStallTotal  time.Duration
+	TiDBExecTime      time.Duration
 	ExecRetryCount    uint
 	ExecRetryTime     time.Duration
 	ResultRows        int64
@@ -2099,6 +2253,7 @@ type SlowQueryLogItems struct {
 // # Txn_start_ts: 406315658548871171
 // # User@Host: root[root] @ localhost [127.0.0.1]
 // # Conn_ID: 6
+// # Trace_id: 1103806595072
 // # Query_time: 4.895492
 // # Process_time: 0.161 Request_count: 1 Total_keys: 100001 Processed_keys: 100000
 // # DB: test
@@ -2115,81 +2270,112 @@ type SlowQueryLogItems struct {
 // # Prev_stmt: begin;
 // select * from t_slim;
 func (s *SessionVars) SlowLogFormat(logItems *SlowQueryLogItems) string {
-	var buf bytes.Buffer
+	return s.NewSlowLogEntry(logItems).Text()
+}
+
+// slowLogField is a field of a slow log entry, which is written as "${key}: ${value}" in the text format.
+type slowLogField struct {
+	key   string
+	value string
+	// quoted is true if the value is a string in the json format, otherwise it's a number or a bool.
+	quoted bool
+}
+
+func stringField(key, value string) slowLogField {
+	return slowLogField{key: key, value: value, quoted: true}
+}
+
+func secondsField(key string, d time.Duration) slowLogField {
+	return slowLogField{key: key, value: strconv.FormatFloat(d.Seconds(), 'f', -1, 64)}
+}
+
+func boolField(key string, v bool) slowLogField {
+	return slowLogField{key: key, value: strconv.FormatBool(v)}
+}
+
+func numberField(key string, v interface{}) slowLogField {
+	return slowLogField{key: key, value: fmt.Sprintf("%v", v)}
+}
+
+// SlowLogEntry is a slow log entry, both the text format and the json format are built from its fields.
+type SlowLogEntry struct {
+	// lines are the fields grouped by the lines of the text format.
+	lines [][]slowLogField
+	// useDB is the database used by the statement if it has been changed since the last slow log entry.
+	useDB string
+	sql   string
+}
 
-	writeSlowLogItem(&buf, SlowLogTxnStartTSStr, strconv.FormatUint(logItems.TxnTS, 10))
+func (e *SlowLogEntry) addLine(fields ...slowLogField) {
+	e.lines = append(e.lines, fields)
+}
+
+// NewSlowLogEntry builds the slow log entry of the statement.
+func (s *SessionVars) NewSlowLogEntry(logItems *SlowQueryLogItems) *SlowLogEntry {
+	e := &SlowLogEntry{}
+	e.addLine(numberField(SlowLogTxnStartTSStr, logItems.TxnTS))
 	if s.User != nil {
 		hostAddress := s.User.Hostname
 		if s.ConnectionInfo != nil {
 			hostAddress = s.ConnectionInfo.ClientIP
 		}
-		writeSlowLogItem(&buf, SlowLogUserAndHostStr, fmt.Sprintf("%s[%s] @ %s [%s]", s.User.Username, s.User.Username, s.User.Hostname, hostAddress))
+		e.addLine(stringField(SlowLogUserAndHostStr, fmt.Sprintf("%s[%s] @ %s [%s]", s.User.Username, s.User.Username, s.User.Hostname, hostAddress)))
 	}
 	if s.ConnectionID != 0 {
-		writeSlowLogItem(&buf, SlowLogConnIDStr, strconv.FormatUint(s.ConnectionID, 10))
+		e.addLine(numberField(SlowLogConnIDStr, s.ConnectionID))
+	}
+	if logItems.TraceID != 0 {
+		e.addLine(numberField(SlowLogTraceIDStr, logItems.TraceID))
 	}
 	if logItems.ExecRetryCount > 0 {
-		buf.WriteString(SlowLogRowPrefixStr)
-		buf.WriteString(SlowLogExecRetryTime)
-		buf.WriteString(SlowLogSpaceMarkStr)
-		buf.WriteString(strconv.FormatFloat(logItems.ExecRetryTime.Seconds(), 'f', -1, 64))
-		buf.WriteString(" ")
-		buf.WriteString(SlowLogExecRetryCount)
-		buf.WriteString(SlowLogSpaceMarkStr)
-		buf.WriteString(strconv.Itoa(int(logItems.ExecRetryCount)))
-		buf.WriteString("\n")
+		e.addLine(secondsField(SlowLogExecRetryTime, logItems.ExecRetryTime), numberField(SlowLogExecRetryCount, logItems.ExecRetryCount))
 	}
-	writeSlowLogItem(&buf, SlowLogQueryTimeStr, strconv.FormatFloat(logItems.TimeTotal.Seconds(), 'f', -1, 64))
-	writeSlowLogItem(&buf, SlowLogParseTimeStr, strconv.FormatFloat(logItems.TimeParse.Seconds(), 'f', -1, 64))
-	writeSlowLogItem(&buf, SlowLogCompileTimeStr, strconv.FormatFloat(logItems.TimeCompile.Seconds(), 'f', -1, 64))
+	e.addLine(secondsField(SlowLogQueryTimeStr, logItems.TimeTotal))
+	e.addLine(secondsField(SlowLogParseTimeStr, logItems.TimeParse))
+	e.addLine(secondsField(SlowLogCompileTimeStr, logItems.TimeCompile))
 
-	buf.WriteString(SlowLogRowPrefixStr + fmt.Sprintf("%v%v%v", SlowLogRewriteTimeStr,
-		SlowLogSpaceMarkStr, strconv.FormatFloat(logItems.RewriteInfo.DurationRewrite.Seconds(), 'f', -1, 64)))
+	rewriteLine := []slowLogField{secondsField(SlowLogRewriteTimeStr, logItems.RewriteInfo.DurationRewrite)}
 	if logItems.RewriteInfo.PreprocessSubQueries > 0 {
-		buf.WriteString(fmt.Sprintf(" %v%v%v %v%v%v", SlowLogPreprocSubQueriesStr, SlowLogSpaceMarkStr, logItems.RewriteInfo.PreprocessSubQueries,
-			SlowLogPreProcSubQueryTimeStr, SlowLogSpaceMarkStr, strconv.FormatFloat(logItems.RewriteInfo.DurationPreprocessSubQuery.Seconds(), 'f', -1, 64)))
+		rewriteLine = append(rewriteLine, numberField(SlowLogPreprocSubQueriesStr, logItems.RewriteInfo.PreprocessSubQueries),
+			secondsField(SlowLogPreProcSubQueryTimeStr, logItems.RewriteInfo.DurationPreprocessSubQuery))
 	}
-	buf.WriteString("\n")
+	e.addLine(rewriteLine...)
 
-	writeSlowLogItem(&buf, SlowLogOptimizeTimeStr, strconv.FormatFloat(logItems.TimeOptimize.Seconds(), 'f', -1, 64))
-	writeSlowLogItem(&buf, SlowLogWaitTSTimeStr, strconv.FormatFloat(logItems.TimeWaitTS.Seconds(), 'f', -1, 64))
+	e.addLine(secondsField(SlowLogOptimizeTimeStr, logItems.TimeOptimize))
+	e.addLine(secondsField(SlowLogWaitTSTimeStr, logItems.TimeWaitTS))
 
-	if execDetailStr := logItems.ExecDetail.String(); len(execDetailStr) > 0 {
-		buf.WriteString(SlowLogRowPrefixStr + execDetailStr + "\n")
+	if execDetailFields := logItems.ExecDetail.ToSlowLogFields(); len(execDetailFields) > 0 {
+		line := make([]slowLogField, 0, len(execDetailFields))
+		for _, f := range execDetailFields {
+			line = append(line, slowLogField{key: f.Key, value: f.Value, quoted: f.Key == execdetails.BackoffTypesStr})
+		}
+		e.addLine(line...)
 	}
 
 	if len(s.CurrentDB) > 0 {
-		writeSlowLogItem(&buf, SlowLogDBStr, strings.ToLower(s.CurrentDB))
+		e.addLine(stringField(SlowLogDBStr, strings.ToLower(s.CurrentDB)))
 	}
 	if len(logItems.IndexNames) > 0 {
-		writeSlowLogItem(&buf, SlowLogIndexNamesStr, logItems.IndexNames)
+		e.addLine(stringField(SlowLogIndexNamesStr, logItems.IndexNames))
 	}
 
-	writeSlowLogItem(&buf, SlowLogIsInternalStr, strconv.FormatBool(s.InRestrictedSQL))
+	e.addLine(boolField(SlowLogIsInternalStr, s.InRestrictedSQL))
 	if len(logItems.Digest) > 0 {
-		writeSlowLogItem(&buf, SlowLogDigestStr, logItems.Digest)
+		e.addLine(stringField(SlowLogDigestStr, logItems.Digest))
 	}
 	if len(logItems.StatsInfos) > 0 {
-		buf.WriteString(SlowLogRowPrefixStr + SlowLogStatsInfoStr + SlowLogSpaceMarkStr)
-		firstComma := false
-		vStr := ""
+		stats := make([]string, 0, len(logItems.StatsInfos))
 		for k, v := range logItems.StatsInfos {
-			if v == 0 {
-				vStr = "pseudo"
-			} else {
+			vStr := "pseudo"
+			if v != 0 {
 				vStr = strconv.FormatUint(v, 10)
 			}
-			if firstComma {
-				buf.WriteString("," + k + ":" + vStr)
-			} else {
-				buf.WriteString(k + ":" + vStr)
-				firstComma = true
-			}
+			stats = append(stats, k+":"+vStr)
 		}
-		buf.WriteString("\n")
+		e.addLine(stringField(SlowLogStatsInfoStr, strings.Join(stats, ",")))
 	}
 	if logItems.CopTasks != nil {
-		writeSlowLogItem(&buf, SlowLogNumCopTasksStr, strconv.FormatInt(int64(logItems.CopTasks.NumCopTasks), 10))
+		e.addLine(numberField(SlowLogNumCopTasksStr, logItems.CopTasks.NumCopTasks))
 		if logItems.CopTasks.NumCopTasks > 0 {
 			// make the result stable
 			backoffs := make([]string, 0, 3)
@@ -2199,91 +2385,132 @@ func (s *SessionVars) SlowLogFormat(logItems *SlowQueryLogItems) string {
 			sort.Strings(backoffs)
 
 			if logItems.CopTasks.NumCopTasks == 1 {
-				buf.WriteString(SlowLogRowPrefixStr + fmt.Sprintf("%v%v%v %v%v%v",
-					SlowLogCopProcAvg, SlowLogSpaceMarkStr, logItems.CopTasks.AvgProcessTime.Seconds(),
-					SlowLogCopProcAddr, SlowLogSpaceMarkStr, logItems.CopTasks.MaxProcessAddress) + "\n")
-				buf.WriteString(SlowLogRowPrefixStr + fmt.Sprintf("%v%v%v %v%v%v",
-					SlowLogCopWaitAvg, SlowLogSpaceMarkStr, logItems.CopTasks.AvgWaitTime.Seconds(),
-					SlowLogCopWaitAddr, SlowLogSpaceMarkStr, logItems.CopTasks.MaxWaitAddress) + "\n")
+				e.addLine(numberField(SlowLogCopProcAvg, logItems.CopTasks.AvgProcessTime.Seconds()),
+					stringField(SlowLogCopProcAddr, logItems.CopTasks.MaxProcessAddress))
+				e.addLine(numberField(SlowLogCopWaitAvg, logItems.CopTasks.AvgWaitTime.Seconds()),
+					stringField(SlowLogCopWaitAddr, logItems.CopTasks.MaxWaitAddress))
 				for _, backoff := range backoffs {
 					backoffPrefix := SlowLogCopBackoffPrefix + backoff + "_"
-					buf.WriteString(SlowLogRowPrefixStr + fmt.Sprintf("%v%v%v %v%v%v\n",
-						backoffPrefix+"total_times", SlowLogSpaceMarkStr, logItems.CopTasks.TotBackoffTimes[backoff],
-						backoffPrefix+"total_time", SlowLogSpaceMarkStr, logItems.CopTasks.TotBackoffTime[backoff].Seconds(),
-					))
+					e.addLine(numberField(backoffPrefix+"total_times", logItems.CopTasks.TotBackoffTimes[backoff]),
+						numberField(backoffPrefix+"total_time", logItems.CopTasks.TotBackoffTime[backoff].Seconds()))
 				}
 			} else {
-				buf.WriteString(SlowLogRowPrefixStr + fmt.Sprintf("%v%v%v %v%v%v %v%v%v %v%v%v",
-					SlowLogCopProcAvg, SlowLogSpaceMarkStr, logItems.CopTasks.AvgProcessTime.Seconds(),
-					SlowLogCopProcP90, SlowLogSpaceMarkStr, logItems.CopTasks.P90ProcessTime.Seconds(),
-					SlowLogCopProcMax, SlowLogSpaceMarkStr, logItems.CopTasks.MaxProcessTime.Seconds(),
-					SlowLogCopProcAddr, SlowLogSpaceMarkStr, logItems.CopTasks.MaxProcessAddress) + "\n")
-				buf.WriteString(SlowLogRowPrefixStr + fmt.Sprintf("%v%v%v %v%v%v %v%v%v %v%v%v",
-					SlowLogCopWaitAvg, SlowLogSpaceMarkStr, logItems.CopTasks.AvgWaitTime.Seconds(),
-					SlowLogCopWaitP90, SlowLogSpaceMarkStr, logItems.CopTasks.P90WaitTime.Seconds(),
-					SlowLogCopWaitMax, SlowLogSpaceMarkStr, logItems.CopTasks.MaxWaitTime.Seconds(),
-					SlowLogCopWaitAddr, SlowLogSpaceMarkStr, logItems.CopTasks.MaxWaitAddress) + "\n")
+				e.addLine(numberField(SlowLogCopProcAvg, logItems.CopTasks.AvgProcessTime.Seconds()),
+					numberField(SlowLogCopProcP90, logItems.CopTasks.P90ProcessTime.Seconds()),
+					numberField(SlowLogCopProcMax, logItems.CopTasks.MaxProcessTime.Seconds()),
+					stringField(SlowLogCopProcAddr, logItems.CopTasks.MaxProcessAddress))
+				e.addLine(numberField(SlowLogCopWaitAvg, logItems.CopTasks.AvgWaitTime.Seconds()),
+					numberField(SlowLogCopWaitP90, logItems.CopTasks.P90WaitTime.Seconds()),
+					numberField(SlowLogCopWaitMax, logItems.CopTasks.MaxWaitTime.Seconds()),
+					stringField(SlowLogCopWaitAddr, logItems.CopTasks.MaxWaitAddress))
 				for _, backoff := range backoffs {
 					backoffPrefix := SlowLogCopBackoffPrefix + backoff + "_"
-					buf.WriteString(SlowLogRowPrefixStr + fmt.Sprintf("%v%v%v %v%v%v %v%v%v %v%v%v %v%v%v %v%v%v\n",
-						backoffPrefix+"total_times", SlowLogSpaceMarkStr, logItems.CopTasks.TotBackoffTimes[backoff],
-						backoffPrefix+"total_time", SlowLogSpaceMarkStr, logItems.CopTasks.TotBackoffTime[backoff].Seconds(),
-						backoffPrefix+"max_time", SlowLogSpaceMarkStr, logItems.CopTasks.MaxBackoffTime[backoff].Seconds(),
-						backoffPrefix+"max_addr", SlowLogSpaceMarkStr, logItems.CopTasks.MaxBackoffAddress[backoff],
-						backoffPrefix+"avg_time", SlowLogSpaceMarkStr, logItems.CopTasks.AvgBackoffTime[backoff].Seconds(),
-						backoffPrefix+"p90_time", SlowLogSpaceMarkStr, logItems.CopTasks.P90BackoffTime[backoff].Seconds(),
-					))
+					e.addLine(numberField(backoffPrefix+"total_times", logItems.CopTasks.TotBackoffTimes[backoff]),
+						numberField(backoffPrefix+"total_time", logItems.CopTasks.TotBackoffTime[backoff].Seconds()),
+						numberField(backoffPrefix+"max_time", logItems.CopTasks.MaxBackoffTime[backoff].Seconds()),
+						stringField(backoffPrefix+"max_addr", logItems.CopTasks.MaxBackoffAddress[backoff]),
+						numberField(backoffPrefix+"avg_time", logItems.CopTasks.AvgBackoffTime[backoff].Seconds()),
+						numberField(backoffPrefix+"p90_time", logItems.CopTasks.P90BackoffTime[backoff].Seconds()))
 				}
 			}
 		}
 	}
 	if logItems.MemMax > 0 {
-		writeSlowLogItem(&buf, SlowLogMemMax, strconv.FormatInt(logItems.MemMax, 10))
+		e.addLine(numberField(SlowLogMemMax, logItems.MemMax))
 	}
 	if logItems.DiskMax > 0 {
-		writeSlowLogItem(&buf, SlowLogDiskMax, strconv.FormatInt(logItems.DiskMax, 10))
-	}
-
-	writeSlowLogItem(&buf, SlowLogPrepared, strconv.FormatBool(logItems.Prepared))
-	writeSlowLogItem(&buf, SlowLogPlanFromCache, strconv.FormatBool(logItems.PlanFromCache))
-	writeSlowLogItem(&buf, SlowLogPlanFromBinding, strconv.FormatBool(logItems.PlanFromBinding))
-	writeSlowLogItem(&buf, SlowLogHasMoreResults, strconv.FormatBool(logItems.HasMoreResults))
-	writeSlowLogItem(&buf, SlowLogKVTotal, strconv.FormatFloat(logItems.KVTotal.Seconds(), 'f', -1, 64))
-	writeSlowLogItem(&buf, SlowLogPDTotal, strconv.FormatFloat(logItems.PDTotal.Seconds(), 'f', -1, 64))
-	writeSlowLogItem(&buf, SlowLogBackoffTotal, strconv.FormatFloat(logItems.BackoffTotal.Seconds(), 'f', -1, 64))
-	writeSlowLogItem(&buf, SlowLogWriteSQLRespTotal, strconv.FormatFloat(logItems.WriteSQLRespTotal.Seconds(), 'f', -1, 64))
-	writeSlowLogItem(&buf, SlowLogResultRows, strconv.FormatInt(logItems.ResultRows, 10))
-	writeSlowLogItem(&buf, SlowLogSucc, strconv.FormatBool(logItems.Succ))
-	writeSlowLogItem(&buf, SlowLogIsExplicitTxn, strconv.FormatBool(logItems.IsExplicitTxn))
+		e.addLine(numberField(SlowLogDiskMax, logItems.DiskMax))
+	}
+
+	e.addLine(boolField(SlowLogPrepared, logItems.Prepared))
+	e.addLine(boolField(SlowLogPlanFromCache, logItems.PlanFromCache))
+	e.addLine(boolField(SlowLogPlanFromBinding, logItems.PlanFromBinding))
+	e.addLine(boolField(SlowLogHasMoreResults, logItems.HasMoreResults))
+	e.addLine(secondsField(SlowLogKVTotal, logItems.KVTotal))
+	e.addLine(secondsField(SlowLogPDTotal, logItems.PDTotal))
+	e.addLine(secondsField(SlowLogBackoffTotal, logItems.BackoffTotal))
+	e.addLine(secondsField(SlowLogWriteSQLRespTotal, logItems.WriteSQLRespTotal))
+	e.addLine(secondsField(SlowLogClientStallTotal, logItems.ClientStallTotal))
+	e.addLine(secondsField(SlowLogTiDBExecTime, logItems.TiDBExecTime))
+	e.addLine(numberField(SlowLogResultRows, logItems.ResultRows))
+	e.addLine(boolField(SlowLogSucc, logItems.Succ))
+	e.addLine(boolField(SlowLogIsExplicitTxn, logItems.IsExplicitTxn))
 	if s.StmtCtx.WaitLockLeaseTime > 0 {
-		writeSlowLogItem(&buf, SlowLogIsWriteCacheTable, strconv.FormatBool(logItems.IsWriteCacheTable))
+		e.addLine(boolField(SlowLogIsWriteCacheTable, logItems.IsWriteCacheTable))
 	}
 	if len(logItems.Plan) != 0 {
-		writeSlowLogItem(&buf, SlowLogPlan, logItems.Plan)
+		e.addLine(stringField(SlowLogPlan, logItems.Plan))
 	}
 	if len(logItems.PlanDigest) != 0 {
-		writeSlowLogItem(&buf, SlowLogPlanDigest, logItems.PlanDigest)
+		e.addLine(stringField(SlowLogPlanDigest, logItems.PlanDigest))
 	}
 
 	if logItems.PrevStmt != "" {
-		writeSlowLogItem(&buf, SlowLogPrevStmt, logItems.PrevStmt)
+		e.addLine(stringField(SlowLogPrevStmt, logItems.PrevStmt))
 	}
 
 	if s.CurrentDBChanged {
-		buf.WriteString(fmt.Sprintf("use %s;\n", strings.ToLower(s.CurrentDB)))
+		e.useDB = strings.ToLower(s.CurrentDB)
 		s.CurrentDBChanged = false
 	}
 
-	buf.WriteString(logItems.SQL)
-	if len(logItems.SQL) == 0 || logItems.SQL[len(logItems.SQL)-1] != ';' {
-		buf.WriteString(";")
+	e.sql = logItems.SQL
+	if len(e.sql) == 0 || e.sql[len(e.sql)-1] != ';' {
+		e.sql += ";"
+	}
+	return e
+}
+
+// Text formats the slow log entry as the text format, whose fields are written as "# ${key}: ${value}" lines
+// followed by the SQL.
+func (e *SlowLogEntry) Text() string {
+	var buf bytes.Buffer
+	for _, line := range e.lines {
+		buf.WriteString(SlowLogRowPrefixStr)
+		for i, f := range line {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(f.key + SlowLogSpaceMarkStr + f.value)
+		}
+		buf.WriteString("\n")
 	}
+	if len(e.useDB) > 0 {
+		buf.WriteString(fmt.Sprintf("use %s;\n", e.useDB))
+	}
+	buf.WriteString(e.sql)
 	return buf.String()
 }
 
-// writeSlowLogItem writes a slow log item in the form of: "# ${key}:${value}"
-func writeSlowLogItem(buf *bytes.Buffer, key, value string) {
-	buf.WriteString(SlowLogRowPrefixStr + key + SlowLogSpaceMarkStr + value + "\n")
+// JSON formats the slow log entry as a json object in one line. The keys are the same as the text format and
+// the SQL is the value of SlowLogQuerySQLStr, the time of the entry is added by the slow query logger.
+func (e *SlowLogEntry) JSON() string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	writeString := func(s string) {
+		// Encode never fails on a string, it appends a newline after the value.
+		_ = enc.Encode(s)
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteByte('{')
+	for _, line := range e.lines {
+		for _, f := range line {
+			writeString(f.key)
+			buf.WriteByte(':')
+			if f.quoted {
+				writeString(f.value)
+			} else {
+				buf.WriteString(f.value)
+			}
+			buf.WriteByte(',')
+		}
+	}
+	writeString(SlowLogQuerySQLStr)
+	buf.WriteByte(':')
+	writeString(e.sql)
+	buf.WriteByte('}')
+	return buf.String()
 }
 
 // QueryInfo represents the information of last executed query. It's used to expose information for test purpose.
@@ -15,6 +15,8 @@
 package variable_test
 
 import (
+	"encoding/json"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -143,6 +145,55 @@ func TestAllocMPPID(t *testing.T) {
 	require.Equal(t, int64(3), seVar.AllocMPPTaskID(2))
 }
 
+func TestSlowLogFormatJSON(t *testing.T) {
+	ctx := mock.NewContext()
+	seVar := ctx.GetSessionVars()
+	seVar.User = &auth.UserIdentity{Username: "root", Hostname: "localhost"}
+	seVar.ConnectionInfo = &variable.ConnectionInfo{ClientIP: "192.168.0.1"}
+	seVar.ConnectionID = 1
+	seVar.CurrentDB = "TeST"
+	execDetail := execdetails.ExecDetails{
+		RequestCount: 2,
+		TimeDetail: util.TimeDetail{
+			ProcessTime: time.Second * 2,
+		},
+	}
+	logItems := &variable.SlowQueryLogItems{
+		TxnTS:      406649736972468225,
+		SQL:        "select * from t;",
+		Digest:     "42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772",
+		TimeTotal:  time.Second,
+		ExecDetail: execDetail,
+		StatsInfos: map[string]uint64{"t1": 0},
+		MemMax:     2333,
+		Succ:       true,
+		ResultRows: 10,
+	}
+	seVar.CurrentDBChanged = true
+	entry := seVar.NewSlowLogEntry(logItems)
+	require.False(t, seVar.CurrentDBChanged)
+	require.Contains(t, entry.Text(), "# Process_time: 2 Request_count: 2\n# DB: test\n")
+	require.True(t, strings.HasSuffix(entry.Text(), "use test;\nselect * from t;"))
+
+	var item map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(entry.JSON()), &item))
+	require.Equal(t, float64(406649736972468225), item[variable.SlowLogTxnStartTSStr])
+	require.Equal(t, "root[root] @ localhost [192.168.0.1]", item[variable.SlowLogUserAndHostStr])
+	require.Equal(t, float64(1), item[variable.SlowLogConnIDStr])
+	require.Equal(t, float64(1), item[variable.SlowLogQueryTimeStr])
+	require.Equal(t, float64(2), item[execdetails.ProcessTimeStr])
+	require.Equal(t, float64(2), item[execdetails.RequestCountStr])
+	require.Equal(t, "test", item[variable.SlowLogDBStr])
+	require.Equal(t, logItems.Digest, item[variable.SlowLogDigestStr])
+	require.Equal(t, "t1:pseudo", item[variable.SlowLogStatsInfoStr])
+	require.Equal(t, float64(2333), item[variable.SlowLogMemMax])
+	require.Equal(t, true, item[variable.SlowLogSucc])
+	require.Equal(t, float64(10), item[variable.SlowLogResultRows])
+	require.Equal(t, "select * from t;", item[variable.SlowLogQuerySQLStr])
+	_, ok := item[variable.SlowLogPlan]
+	require.False(t, ok)
+}
+
 func TestSlowLogFormat(t *testing.T) {
 	ctx := mock.NewContext()
 
@@ -205,6 +256,7 @@ func TestSlowLogFormat(t *testing.T) {
 	resultFields := `# Txn_start_ts: 406649736972468225
 # User@Host: root[root] @ 192.168.0.1 [192.168.0.1]
 # Conn_ID: 1
+# Trace_id: 1103806595072
 # Exec_retry_time: 5.1 Exec_retry_count: 3
 # Query_time: 1
 # Parse_time: 0.00000001
@@ -234,6 +286,8 @@ func TestSlowLogFormat(t *testing.T) {
 # PD_total: 11
 # Backoff_total: 12
 # Write_sql_response_total: 1
+# Client_stall_total: 0.5
+# Tidb_exec_time: 1.5
 # Result_rows: 12345
 # Succ: true
 # IsExplicitTxn: true
@@ -242,6 +296,7 @@ func TestSlowLogFormat(t *testing.T) {
 	_, digest := parser.NormalizeDigest(sql)
 	logItems := &variable.SlowQueryLogItems{
 		TxnTS:             txnTS,
+		TraceID:           1103806595072,
 		SQL:               sql,
 		Digest:            digest.String(),
 		TimeTotal:         costTime,
@@ -263,6 +318,8 @@ func TestSlowLogFormat(t *testing.T) {
 		PDTotal:           11 * time.Second,
 		BackoffTotal:      12 * time.Second,
 		WriteSQLRespTotal: 1 * time.Second,
+		ClientStallTotal:  500 * time.Millisecond,
+		TiDBExecTime:      1500 * time.Millisecond,
 		ResultRows:        12345,
 		Succ:              true,
 		RewriteInfo: variable.RewritePhaseInfo{
@@ -297,3 +354,40 @@ func TestIsolationRead(t *testing.T) {
 	_, ok = sessVars.IsolationReadEngines[kv.TiFlash]
 	require.True(t, ok)
 }
+
+func TestTiFlashPreferredZone(t *testing.T) {
+	defer config.RestoreFunc()()
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.Labels = map[string]string{"zone": "z1", "host": "h1"}
+	})
+	sessVars := variable.NewSessionVars()
+	require.Equal(t, "", sessVars.GetTiFlashPreferredZone())
+	require.NoError(t, sessVars.SetSystemVar(variable.TiDBTiFlashPreferLocalZone, "ON"))
+	require.Equal(t, "z1", sessVars.GetTiFlashPreferredZone())
+	require.NoError(t, sessVars.SetSystemVar(variable.TiDBTiFlashPreferLocalZone, "OFF"))
+	require.Equal(t, "", sessVars.GetTiFlashPreferredZone())
+}
+
+func TestTiFlashReplicaRead(t *testing.T) {
+	defer config.RestoreFunc()()
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.Labels = map[string]string{"zone": "z1", "host": "h1"}
+	})
+	sessVars := variable.NewSessionVars()
+	require.NoError(t, sessVars.SetSystemVar(variable.TiDBTiFlashReplicaRead, variable.TiFlashReplicaReadClosestAdaptive))
+	require.Equal(t, "z1", sessVars.GetTiFlashPreferredZone())
+	require.Nil(t, sessVars.GetTiFlashReplicaReadNodes())
+
+	// The nodes are only used by "specified_nodes".
+	require.NoError(t, sessVars.SetSystemVar(variable.TiDBTiFlashReplicaReadNodes, " 127.0.0.1:3930, ,127.0.0.2:3930"))
+	require.Nil(t, sessVars.GetTiFlashReplicaReadNodes())
+	require.NoError(t, sessVars.SetSystemVar(variable.TiDBTiFlashReplicaRead, variable.TiFlashReplicaReadSpecifiedNodes))
+	require.Equal(t, "", sessVars.GetTiFlashPreferredZone())
+	require.Equal(t, []string{"127.0.0.1:3930", "127.0.0.2:3930"}, sessVars.GetTiFlashReplicaReadNodes())
+
+	sv := variable.GetSysVar(variable.TiDBTiFlashReplicaReadNodes)
+	_, err := sv.Validate(sessVars, "127.0.0.1", variable.ScopeSession)
+	require.Error(t, err)
+	_, err = sv.Validate(sessVars, "127.0.0.1:3930,tiflash-0:3930", variable.ScopeSession)
+	require.NoError(t, err)
+}
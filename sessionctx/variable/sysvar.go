@@ -30,10 +30,12 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser/charset"
 	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/planner/util/fixcontrol"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/netsim"
 	"github.com/pingcap/tidb/util/stmtsummary"
 	topsqlstate "github.com/pingcap/tidb/util/topsql/state"
 	"github.com/pingcap/tidb/util/versioninfo"
@@ -235,6 +237,10 @@ var defaultSysVars = []*SysVar{
 		s.WindowingUseHighPrecision = TiDBOptOn(val)
 		return nil
 	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: SQLQuoteShowCreate, Value: On, Type: TypeBool, SetSession: func(s *SessionVars, val string) error {
+		s.QuoteShowCreate = TiDBOptOn(val)
+		return nil
+	}},
 	{Scope: ScopeNone, Name: "license", Value: "Apache License 2.0"},
 	{Scope: ScopeGlobal | ScopeSession, Name: BlockEncryptionMode, Value: "aes-128-ecb"},
 	{Scope: ScopeSession, Name: LastInsertID, Value: "", skipInit: true, GetSession: func(s *SessionVars) (string, error) {
@@ -315,6 +321,112 @@ var defaultSysVars = []*SysVar{
 		s.MPPStoreFailTTL = val
 		return nil
 	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBTiFlashPreferLocalZone, Type: TypeBool, Value: BoolToOnOff(DefTiDBTiFlashPreferLocalZone), SetSession: func(s *SessionVars, val string) error {
+		s.TiFlashPreferLocalZone = TiDBOptOn(val)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBTiFlashReplicaRead, Type: TypeEnum, Value: DefTiDBTiFlashReplicaRead, IsHintUpdatable: true, PossibleValues: []string{TiFlashReplicaReadAllReplicas, TiFlashReplicaReadClosestAdaptive, TiFlashReplicaReadSpecifiedNodes}, SetSession: func(s *SessionVars, val string) error {
+		s.TiFlashReplicaRead = val
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBTiFlashReplicaReadNodes, Type: TypeStr, Value: DefTiDBTiFlashReplicaReadNodes, IsHintUpdatable: true, Validation: func(vars *SessionVars, normalizedValue string, originalValue string, scope ScopeFlag) (string, error) {
+		_, err := parseTiFlashReplicaReadNodes(normalizedValue)
+		return normalizedValue, err
+	}, SetSession: func(s *SessionVars, val string) error {
+		nodes, err := parseTiFlashReplicaReadNodes(val)
+		s.TiFlashReplicaReadNodes = nodes
+		return err
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBBatchCopResponseChannelSize, Type: TypeUnsigned, Value: strconv.Itoa(DefTiDBBatchCopResponseChannelSize), MinValue: 1, MaxValue: 1 << 16, SetSession: func(s *SessionVars, val string) error {
+		s.BatchCopResponseChannelSize = tidbOptPositiveInt32(val, DefTiDBBatchCopResponseChannelSize)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBBatchCopResponseMaxBytes, Type: TypeUnsigned, Value: strconv.Itoa(DefTiDBBatchCopResponseMaxBytes), MinValue: 0, MaxValue: math.MaxInt64, SetSession: func(s *SessionVars, val string) error {
+		s.BatchCopResponseMaxBytes = TidbOptInt64(val, DefTiDBBatchCopResponseMaxBytes)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBEnableBatchCopResponseSpill, Type: TypeBool, Value: BoolToOnOff(DefTiDBEnableBatchCopResponseSpill), SetSession: func(s *SessionVars, val string) error {
+		s.EnableBatchCopResponseSpill = TiDBOptOn(val)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBEnableTiKVBatchCop, Type: TypeBool, Value: BoolToOnOff(DefTiDBEnableTiKVBatchCop), SetSession: func(s *SessionVars, val string) error {
+		s.EnableTiKVBatchCop = TiDBOptOn(val)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBTraceCopTaskSeq, Type: TypeBool, Value: BoolToOnOff(DefTiDBTraceCopTaskSeq), SetSession: func(s *SessionVars, val string) error {
+		s.TraceCopTaskSeq = TiDBOptOn(val)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBMaxRegionsPerBatchCopTask, Type: TypeUnsigned, Value: strconv.Itoa(DefTiDBMaxRegionsPerBatchCopTask), MinValue: 0, MaxValue: math.MaxInt32, SetSession: func(s *SessionVars, val string) error {
+		s.MaxRegionsPerBatchCopTask = TidbOptInt(val, DefTiDBMaxRegionsPerBatchCopTask)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBBatchCopConcurrency, Type: TypeUnsigned, Value: strconv.Itoa(DefTiDBBatchCopConcurrency), MinValue: 0, MaxValue: math.MaxInt32, SetSession: func(s *SessionVars, val string) error {
+		s.BatchCopConcurrency = TidbOptInt(val, DefTiDBBatchCopConcurrency)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBBatchCopStreamStallThreshold, Type: TypeDuration, Value: DefTiDBBatchCopStreamStallThreshold, MinValue: 0, MaxValue: uint64(time.Hour), SetSession: func(s *SessionVars, val string) error {
+		d, err := time.ParseDuration(val)
+		s.BatchCopStreamStallThreshold = d
+		return err
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBBatchCopStreamStallReconnect, Type: TypeBool, Value: BoolToOnOff(DefTiDBBatchCopStreamStallReconnect), SetSession: func(s *SessionVars, val string) error {
+		s.BatchCopStreamStallReconnect = TiDBOptOn(val)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBEnableBatchCopTaskCache, Type: TypeBool, Value: BoolToOnOff(DefTiDBEnableBatchCopTaskCache), SetSession: func(s *SessionVars, val string) error {
+		s.EnableBatchCopTaskCache = TiDBOptOn(val)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBTiFlashFallbackRangesToTiKV, Type: TypeBool, Value: BoolToOnOff(DefTiDBTiFlashFallbackRangesToTiKV), SetSession: func(s *SessionVars, val string) error {
+		s.TiFlashFallbackRangesToTiKV = TiDBOptOn(val)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBTmpStorageQuota, Type: TypeInt, Value: strconv.Itoa(DefTiDBTmpStorageQuota), MinValue: -1, MaxValue: math.MaxInt64, SetSession: func(s *SessionVars, val string) error {
+		s.TmpStorageQuota = TidbOptInt64(val, DefTiDBTmpStorageQuota)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBReadOnlyStmtRetryLimit, Type: TypeUnsigned, Value: strconv.Itoa(DefTiDBReadOnlyStmtRetryLimit), MinValue: 0, MaxValue: math.MaxInt32, SetSession: func(s *SessionVars, val string) error {
+		s.ReadOnlyStmtRetryLimit = TidbOptInt(val, DefTiDBReadOnlyStmtRetryLimit)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBShowCreateTableMySQLCompatible, Type: TypeBool, Value: BoolToOnOff(DefTiDBShowCreateTableMySQLCompatible), SetSession: func(s *SessionVars, val string) error {
+		s.ShowCreateTableMySQLCompatible = TiDBOptOn(val)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBPlanCacheRowDeviationRatio, Value: strconv.FormatFloat(DefTiDBPlanCacheRowDeviationRatio, 'f', -1, 64), Type: TypeFloat, MinValue: 0, MaxValue: math.MaxUint64, SetSession: func(s *SessionVars, val string) error {
+		s.PlanCacheRowDeviationRatio = tidbOptFloat64(val, DefTiDBPlanCacheRowDeviationRatio)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBPlanCacheRowDeviationExecutions, Value: strconv.Itoa(DefTiDBPlanCacheRowDeviationExecs), Type: TypeUnsigned, MinValue: 1, MaxValue: math.MaxInt32, SetSession: func(s *SessionVars, val string) error {
+		s.PlanCacheRowDeviationExecutions = TidbOptInt(val, DefTiDBPlanCacheRowDeviationExecs)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBOptFixControl, Value: "", Type: TypeStr, Validation: func(vars *SessionVars, normalizedValue string, originalValue string, scope ScopeFlag) (string, error) {
+		if _, err := fixcontrol.ParseToMap(normalizedValue); err != nil {
+			return normalizedValue, ErrWrongValueForVar.GenWithStackByArgs(TiDBOptFixControl, originalValue)
+		}
+		return normalizedValue, nil
+	}, SetSession: func(s *SessionVars, val string) error {
+		fixControlMap, err := fixcontrol.ParseToMap(val)
+		if err != nil {
+			return err
+		}
+		s.OptimizerFixControl = fixControlMap
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBMaxRowSize, Value: strconv.Itoa(DefTiDBMaxRowSize), Type: TypeUnsigned, MinValue: 0, MaxValue: math.MaxInt64, SetSession: func(s *SessionVars, val string) error {
+		s.MaxRowSize = uint64(TidbOptInt64(val, DefTiDBMaxRowSize))
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBMaxColumnSize, Value: strconv.Itoa(DefTiDBMaxColumnSize), Type: TypeUnsigned, MinValue: 0, MaxValue: math.MaxInt64, SetSession: func(s *SessionVars, val string) error {
+		s.MaxColumnSize = uint64(TidbOptInt64(val, DefTiDBMaxColumnSize))
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBTableStatsEstimation, Type: TypeEnum, Value: DefTiDBTableStatsEstimation, PossibleValues: []string{TableStatsEstimationFast, TableStatsEstimationAccurate}, SetSession: func(s *SessionVars, val string) error {
+		s.TableStatsEstimation = val
+		return nil
+	}},
 	{Scope: ScopeGlobal | ScopeSession, Name: TiDBHashExchangeWithNewCollation, Type: TypeBool, Value: BoolToOnOff(DefTiDBHashExchangeWithNewCollation), SetSession: func(s *SessionVars, val string) error {
 		s.HashExchangeWithNewCollation = TiDBOptOn(val)
 		return nil
@@ -959,6 +1071,10 @@ var defaultSysVars = []*SysVar{
 		SetGlobal: func(s *SessionVars, val string) error {
 			return stmtsummary.StmtSummaryByDigestMap.SetMaxSQLLength(TidbOptInt(val, DefTiDBStmtSummaryMaxSQLLength))
 		}},
+	{Scope: ScopeGlobal, Name: TiDBStmtSummaryPlanHistorySize, Value: strconv.Itoa(DefTiDBStmtSummaryPlanHistorySize), Type: TypeInt, MinValue: 0, MaxValue: math.MaxUint8, AllowEmpty: true,
+		SetGlobal: func(s *SessionVars, val string) error {
+			return stmtsummary.StmtSummaryByDigestMap.SetPlanHistorySize(TidbOptInt(val, DefTiDBStmtSummaryPlanHistorySize))
+		}},
 	{Scope: ScopeGlobal, Name: TiDBCapturePlanBaseline, Value: DefTiDBCapturePlanBaseline, Type: TypeBool, AllowEmptyAll: true},
 	{Scope: ScopeGlobal | ScopeSession, Name: TiDBUsePlanBaselines, Value: BoolToOnOff(DefTiDBUsePlanBaselines), Type: TypeBool, SetSession: func(s *SessionVars, val string) error {
 		s.UsePlanBaselines = TiDBOptOn(val)
@@ -1115,6 +1231,14 @@ var defaultSysVars = []*SysVar{
 		errors.RedactLogEnabled.Store(s.EnableRedactLog)
 		return nil
 	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBEnableErrorDocURL, Value: BoolToOnOff(DefTiDBEnableErrorDocURL), Type: TypeBool, SetSession: func(s *SessionVars, val string) error {
+		s.EnableErrorDocURL = TiDBOptOn(val)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBEnableStableSQLState, Value: BoolToOnOff(DefTiDBEnableStableSQLState), Type: TypeBool, SetSession: func(s *SessionVars, val string) error {
+		s.EnableStableSQLState = TiDBOptOn(val)
+		return nil
+	}},
 	{Scope: ScopeGlobal, Name: TiDBRestrictedReadOnly, Value: BoolToOnOff(DefTiDBRestrictedReadOnly), Type: TypeBool, SetGlobal: func(s *SessionVars, val string) error {
 		on := TiDBOptOn(val)
 		if on {
@@ -1147,6 +1271,9 @@ var defaultSysVars = []*SysVar{
 		return nil
 	}},
 	{Scope: ScopeGlobal, Name: TiDBEnableTelemetry, Value: BoolToOnOff(DefTiDBEnableTelemetry), Type: TypeBool},
+	{Scope: ScopeGlobal, Name: TiDBTelemetryReportFeatureUsage, Value: BoolToOnOff(DefTiDBTelemetryReportFeatureUsage), Type: TypeBool},
+	{Scope: ScopeGlobal, Name: TiDBTelemetryReportHardware, Value: BoolToOnOff(DefTiDBTelemetryReportHardware), Type: TypeBool},
+	{Scope: ScopeGlobal, Name: TiDBTelemetryReportSlowQuery, Value: BoolToOnOff(DefTiDBTelemetryReportSlowQuery), Type: TypeBool},
 	{Scope: ScopeGlobal | ScopeSession, Name: TiDBEnableAmendPessimisticTxn, Value: BoolToOnOff(DefTiDBEnableAmendPessimisticTxn), Type: TypeBool, SetSession: func(s *SessionVars, val string) error {
 		s.EnableAmendPessimisticTxn = TiDBOptOn(val)
 		return nil
@@ -1209,23 +1336,23 @@ var defaultSysVars = []*SysVar{
 	{Scope: ScopeGlobal, Name: TiDBEnableHistoricalStats, Value: Off, Type: TypeBool, GetGlobal: func(s *SessionVars) (string, error) {
 		return getTiDBTableValue(s, "tidb_enable_historical_stats", Off)
 	}, SetGlobal: func(s *SessionVars, val string) error {
-		return setTiDBTableValue(s, "tidb_enable_historical_stats", val, "Current historical statistics enable status")
+		return setTiDBTableValue(s, "tidb_enable_historical_stats", val)
 	}},
 	/* tikv gc metrics */
 	{Scope: ScopeGlobal, Name: TiDBGCEnable, Value: On, Type: TypeBool, GetGlobal: func(s *SessionVars) (string, error) {
 		return getTiDBTableValue(s, "tikv_gc_enable", On)
 	}, SetGlobal: func(s *SessionVars, val string) error {
-		return setTiDBTableValue(s, "tikv_gc_enable", val, "Current GC enable status")
+		return setTiDBTableValue(s, "tikv_gc_enable", val)
 	}},
 	{Scope: ScopeGlobal, Name: TiDBGCRunInterval, Value: "10m0s", Type: TypeDuration, MinValue: int64(time.Minute * 10), MaxValue: uint64(time.Hour * 24 * 365), GetGlobal: func(s *SessionVars) (string, error) {
 		return getTiDBTableValue(s, "tikv_gc_run_interval", "10m0s")
 	}, SetGlobal: func(s *SessionVars, val string) error {
-		return setTiDBTableValue(s, "tikv_gc_run_interval", val, "GC run interval, at least 10m, in Go format.")
+		return setTiDBTableValue(s, "tikv_gc_run_interval", val)
 	}},
 	{Scope: ScopeGlobal, Name: TiDBGCLifetime, Value: "10m0s", Type: TypeDuration, MinValue: int64(time.Minute * 10), MaxValue: uint64(time.Hour * 24 * 365), GetGlobal: func(s *SessionVars) (string, error) {
 		return getTiDBTableValue(s, "tikv_gc_life_time", "10m0s")
 	}, SetGlobal: func(s *SessionVars, val string) error {
-		return setTiDBTableValue(s, "tikv_gc_life_time", val, "All versions within life time will not be collected by GC, at least 10m, in Go format.")
+		return setTiDBTableValue(s, "tikv_gc_life_time", val)
 	}},
 	{Scope: ScopeGlobal, Name: TiDBGCConcurrency, Value: "-1", Type: TypeInt, MinValue: 1, MaxValue: MaxConfigurableConcurrency, AllowAutoValue: true, GetGlobal: func(s *SessionVars) (string, error) {
 		autoConcurrencyVal, err := getTiDBTableValue(s, "tikv_gc_auto_concurrency", On)
@@ -1239,15 +1366,15 @@ var defaultSysVars = []*SysVar{
 			autoConcurrency = On
 		}
 		// Update both autoconcurrency and concurrency.
-		if err := setTiDBTableValue(s, "tikv_gc_auto_concurrency", autoConcurrency, "Let TiDB pick the concurrency automatically. If set false, tikv_gc_concurrency will be used"); err != nil {
+		if err := setTiDBTableValue(s, "tikv_gc_auto_concurrency", autoConcurrency); err != nil {
 			return err
 		}
-		return setTiDBTableValue(s, "tikv_gc_concurrency", val, "How many goroutines used to do GC parallel, [1, 256], default 2")
+		return setTiDBTableValue(s, "tikv_gc_concurrency", val)
 	}},
 	{Scope: ScopeGlobal, Name: TiDBGCScanLockMode, Value: "LEGACY", Type: TypeEnum, PossibleValues: []string{"PHYSICAL", "LEGACY"}, GetGlobal: func(s *SessionVars) (string, error) {
 		return getTiDBTableValue(s, "tikv_gc_scan_lock_mode", "LEGACY")
 	}, SetGlobal: func(s *SessionVars, val string) error {
-		return setTiDBTableValue(s, "tikv_gc_scan_lock_mode", val, "Mode of scanning locks, \"physical\" or \"legacy\"")
+		return setTiDBTableValue(s, "tikv_gc_scan_lock_mode", val)
 	}},
 	// It's different from tmp_table_size or max_heap_table_size. See https://github.com/pingcap/tidb/issues/28691.
 	{Scope: ScopeGlobal | ScopeSession, Name: TiDBTmpTableMaxSize, Value: strconv.Itoa(DefTiDBTmpTableMaxSize), Type: TypeUnsigned, MinValue: 1 << 20, MaxValue: 1 << 37, SetSession: func(s *SessionVars, val string) error {
@@ -1301,6 +1428,23 @@ var defaultSysVars = []*SysVar{
 		s.RegardNULLAsPoint = TiDBOptOn(val)
 		return nil
 	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBEnableNullAwareAntiJoin, Value: BoolToOnOff(DefTiDBEnableNullAwareAntiJoin), Type: TypeBool, SetSession: func(s *SessionVars, val string) error {
+		s.EnableNullAwareAntiJoin = TiDBOptOn(val)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBEnablePartitionWiseJoin, Value: BoolToOnOff(DefTiDBEnablePartitionWiseJoin), Type: TypeBool, SetSession: func(s *SessionVars, val string) error {
+		s.EnablePartitionWiseJoin = TiDBOptOn(val)
+		return nil
+	}},
+	// The max value is the size of the write buffer of the connection, which is always flushed when it's full.
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBResultFlushThreshold, Value: strconv.Itoa(DefTiDBResultFlushThreshold), Type: TypeUnsigned, MinValue: 0, MaxValue: 16 * 1024, SetSession: func(s *SessionVars, val string) error {
+		s.ResultFlushThreshold = TidbOptInt(val, DefTiDBResultFlushThreshold)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBEnableCommutativeRetry, Value: BoolToOnOff(DefTiDBEnableCommutativeRetry), Type: TypeBool, SetSession: func(s *SessionVars, val string) error {
+		s.EnableCommutativeRetry = TiDBOptOn(val)
+		return nil
+	}},
 
 	{Scope: ScopeNone, Name: "version_compile_os", Value: runtime.GOOS},
 	{Scope: ScopeNone, Name: "version_compile_machine", Value: runtime.GOARCH},
@@ -1337,7 +1481,7 @@ var defaultSysVars = []*SysVar{
 		if !v {
 			// Set the location to UTC to avoid time zone interference.
 			disableTime := time.Now().UTC().Format(types.UTCTimeFormat)
-			if err := setTiDBTableValue(s, TiDBDisableColumnTrackingTime, disableTime, "Record the last time tidb_enable_column_tracking is set off"); err != nil {
+			if err := setTiDBTableValue(s, TiDBDisableColumnTrackingTime, disableTime); err != nil {
 				return err
 			}
 		}
@@ -1353,6 +1497,32 @@ var defaultSysVars = []*SysVar{
 			return nil
 		},
 	},
+	{Scope: ScopeSession, Name: TiDBSimulatedStoreRTT, Value: "", Type: TypeStr, Hidden: true,
+		Validation: func(_ *SessionVars, normalized string, _ string, _ ScopeFlag) (string, error) {
+			if _, err := netsim.ParseRTT(normalized); err != nil {
+				return normalized, ErrWrongValueForVar.GenWithStackByArgs(TiDBSimulatedStoreRTT, normalized)
+			}
+			return normalized, nil
+		},
+		SetSession: func(s *SessionVars, val string) error {
+			rtt, err := netsim.ParseRTT(val)
+			s.StoreNetworkSimulation.RTT = rtt
+			return err
+		},
+	},
+	{Scope: ScopeSession, Name: TiDBSimulatedStoreBandwidth, Value: "", Type: TypeStr, Hidden: true,
+		Validation: func(_ *SessionVars, normalized string, _ string, _ ScopeFlag) (string, error) {
+			if _, err := netsim.ParseBandwidth(normalized); err != nil {
+				return normalized, ErrWrongValueForVar.GenWithStackByArgs(TiDBSimulatedStoreBandwidth, normalized)
+			}
+			return normalized, nil
+		},
+		SetSession: func(s *SessionVars, val string) error {
+			bandwidth, err := netsim.ParseBandwidth(val)
+			s.StoreNetworkSimulation.Bandwidth = bandwidth
+			return err
+		},
+	},
 	{Scope: ScopeGlobal | ScopeSession, Name: TiDBStatsLoadSyncWait, Value: strconv.Itoa(DefTiDBStatsLoadSyncWait), skipInit: true, Type: TypeInt, MinValue: 0, MaxValue: math.MaxInt32,
 		SetSession: func(s *SessionVars, val string) error {
 			s.StatsLoadSyncWait = TidbOptInt64(val, DefTiDBStatsLoadSyncWait)
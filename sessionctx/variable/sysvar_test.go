@@ -22,6 +22,7 @@ import (
 	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/parser/mysql"
@@ -882,3 +883,41 @@ func TestNetBufferLength(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "524288", val) // unchanged
 }
+
+func TestOptFixControl(t *testing.T) {
+	sv := GetSysVar(TiDBOptFixControl)
+	vars := NewSessionVars()
+	vars.GlobalVarsAccessor = NewMockGlobalAccessor4Tests()
+
+	_, err := sv.Validate(vars, "756:OFF,abc", ScopeSession)
+	require.Error(t, err)
+	val, err := sv.Validate(vars, "756:OFF, 1234:ON", ScopeSession)
+	require.NoError(t, err)
+	require.NoError(t, sv.SetSessionFromHook(vars, val))
+	require.Equal(t, map[uint64]string{756: "OFF", 1234: "ON"}, vars.OptimizerFixControl)
+}
+
+func TestSimulatedStoreNetwork(t *testing.T) {
+	vars := NewSessionVars()
+	vars.GlobalVarsAccessor = NewMockGlobalAccessor4Tests()
+
+	rtt := GetSysVar(TiDBSimulatedStoreRTT)
+	_, err := rtt.Validate(vars, "1=50", ScopeSession)
+	require.Error(t, err)
+	val, err := rtt.Validate(vars, "1=50ms,2=1s", ScopeSession)
+	require.NoError(t, err)
+	require.NoError(t, rtt.SetSessionFromHook(vars, val))
+	require.Equal(t, map[uint64]time.Duration{1: 50 * time.Millisecond, 2: time.Second}, vars.StoreNetworkSimulation.RTT)
+
+	bandwidth := GetSysVar(TiDBSimulatedStoreBandwidth)
+	_, err = bandwidth.Validate(vars, "1=1MB", ScopeSession)
+	require.Error(t, err)
+	val, err = bandwidth.Validate(vars, "1=1048576", ScopeSession)
+	require.NoError(t, err)
+	require.NoError(t, bandwidth.SetSessionFromHook(vars, val))
+	require.Equal(t, map[uint64]uint64{1: 1048576}, vars.StoreNetworkSimulation.Bandwidth)
+
+	require.NoError(t, rtt.SetSessionFromHook(vars, ""))
+	require.NoError(t, bandwidth.SetSessionFromHook(vars, ""))
+	require.True(t, vars.StoreNetworkSimulation.IsEmpty())
+}
@@ -223,6 +223,14 @@ const (
 
 	// TiDBReadConsistency indicates whether the autocommit read statement goes through TiKV RC.
 	TiDBReadConsistency = "tidb_read_consistency"
+
+	// TiDBSimulatedStoreRTT is the simulated round-trip time between TiDB and the stores, e.g. "1=50ms,2=100ms".
+	// It only takes effect in the failpoint-enabled builds.
+	TiDBSimulatedStoreRTT = "tidb_simulated_store_rtt"
+
+	// TiDBSimulatedStoreBandwidth is the simulated bandwidth in bytes per second between TiDB and the stores,
+	// e.g. "1=1048576". It only takes effect in the failpoint-enabled builds.
+	TiDBSimulatedStoreBandwidth = "tidb_simulated_store_bandwidth"
 )
 
 // TiDB system variable names that both in session and global scope.
@@ -335,6 +343,104 @@ const (
 	// TiFlash even though the failed TiFlash node has been recovered.
 	TiDBMPPStoreFailTTL = "tidb_mpp_store_fail_ttl"
 
+	// TiDBTiFlashPreferLocalZone indicates whether the batch cop and MPP tasks prefer the TiFlash stores in the same zone as TiDB.
+	// The zone is the value of the "zone" label of TiDB, the regions are still sent to other zones if they have no replica in it.
+	TiDBTiFlashPreferLocalZone = "tidb_tiflash_prefer_local_zone"
+
+	// TiDBTiFlashReplicaRead decides the TiFlash stores the batch cop and MPP tasks are sent to. "all_replicas" balances
+	// the regions between all the stores having their replicas, "closest_adaptive" prefers the stores in the same zone
+	// as TiDB like tidb_tiflash_prefer_local_zone, and "specified_nodes" only uses the stores in
+	// tidb_tiflash_replica_read_nodes, the statement fails if a region has no replica in them.
+	TiDBTiFlashReplicaRead = "tidb_tiflash_replica_read"
+
+	// TiDBTiFlashReplicaReadNodes is the comma separated addresses of the TiFlash stores used if tidb_tiflash_replica_read
+	// is "specified_nodes", e.g. "10.0.1.1:3930,10.0.1.2:3930".
+	TiDBTiFlashReplicaReadNodes = "tidb_tiflash_replica_read_nodes"
+
+	// TiDBBatchCopResponseChannelSize is the number of the batch cop responses that can be queued before being consumed.
+	TiDBBatchCopResponseChannelSize = "tidb_batch_cop_response_channel_size"
+
+	// TiDBBatchCopResponseMaxBytes limits the bytes of the queued batch cop responses, the TiFlash responses
+	// are not received until the queued ones are consumed. 0 means only the channel size limits the responses.
+	TiDBBatchCopResponseMaxBytes = "tidb_batch_cop_response_max_bytes"
+
+	// TiDBEnableBatchCopResponseSpill indicates whether the batch cop responses are spilled to the temporary disk once
+	// the memory quota of the query is exceeded, so the TiFlash responses are still received while the consumer is slow.
+	// It only takes effect when oom-use-tmp-storage is enabled and the order of the responses needn't be kept.
+	TiDBEnableBatchCopResponseSpill = "tidb_enable_batch_cop_response_spill"
+
+	// TiDBEnableTiKVBatchCop indicates whether the table scans on TiKV are sent by the batch coprocessor requests,
+	// which batch the regions of a store into one request. The regions of the TiKV stores not supporting it are
+	// sent by the coprocessor requests. It only works if the order of the results needn't be kept.
+	TiDBEnableTiKVBatchCop = "tidb_enable_tikv_batch_cop"
+
+	// TiDBTraceCopTaskSeq indicates whether the task IDs sent to TiKV are the trace ID of the statement plus the
+	// sequence numbers of the cop tasks, so the requests of the cop tasks can be told apart in the TiKV logs.
+	// The cop tasks of a statement are not regarded as the same task by the read pool of TiKV if it's on.
+	TiDBTraceCopTaskSeq = "tidb_trace_cop_task_seq"
+
+	// TiDBMaxRegionsPerBatchCopTask limits the number of the regions in a batch cop task, the regions of a TiFlash store
+	// are split into several tasks running in parallel, so a slow region doesn't block the others. 0 means no limit.
+	TiDBMaxRegionsPerBatchCopTask = "tidb_max_regions_per_batch_cop_task"
+
+	// TiDBBatchCopConcurrency limits the number of the batch cop tasks of a request running at the same time,
+	// so the requests to the clusters having many TiFlash stores don't start too many streams at once. 0 means no limit.
+	TiDBBatchCopConcurrency = "tidb_batch_cop_concurrency"
+
+	// TiDBBatchCopStreamStallThreshold is how long the stream of a batch cop task waits for the next response before
+	// it's regarded as stalled, a warning is logged with the address of the store then. 0 disables the detection.
+	TiDBBatchCopStreamStallThreshold = "tidb_batch_cop_stream_stall_threshold"
+
+	// TiDBBatchCopStreamStallReconnect indicates whether the stalled streams are cancelled and their tasks are sent
+	// again at once, instead of waiting for the ultra-long timeout. The streams having returned data are not cancelled.
+	TiDBBatchCopStreamStallReconnect = "tidb_batch_cop_stream_stall_reconnect"
+
+	// TiDBEnableBatchCopTaskCache indicates whether the layouts of the batch cop tasks are cached and reused by the
+	// queries reading the same ranges, instead of splitting the ranges and balancing the regions again.
+	TiDBEnableBatchCopTaskCache = "tidb_enable_batch_cop_task_cache"
+
+	// TiDBTiFlashFallbackRangesToTiKV indicates whether the regions of a TiFlash table reader without available TiFlash
+	// replicas, like the ones whose replicas are not synced yet, are read from TiKV in the same query instead of failing
+	// it. It only works if the operators and expressions pushed down to TiFlash are all supported by TiKV.
+	TiDBTiFlashFallbackRangesToTiKV = "tidb_tiflash_fallback_ranges_to_tikv"
+
+	// TiDBTmpStorageQuota limits the bytes of the temporary storage a statement spills to, the statement is cancelled
+	// once it exceeds. The negative values mean no limit, and the [tmp-storage-quota] of all the statements still works.
+	TiDBTmpStorageQuota = "tidb_tmp_storage_quota"
+
+	// TiDBReadOnlyStmtRetryLimit is the max times to re-execute a read-only statement from the same snapshot once it
+	// meets a transient error of TiKV, TiFlash or PD before returning any rows, instead of aborting the transaction.
+	// 0 disables the retry.
+	TiDBReadOnlyStmtRetryLimit = "tidb_read_only_stmt_retry_limit"
+
+	// TiDBShowCreateTableMySQLCompatible indicates whether SHOW CREATE TABLE and DATABASE omit the TiDB specific attributes,
+	// like AUTO_RANDOM, SHARD_ROW_ID_BITS and PLACEMENT POLICY, so the output can be replicated into MySQL.
+	TiDBShowCreateTableMySQLCompatible = "tidb_show_create_table_mysql_compatible"
+
+	// TiDBPlanCacheRowDeviationRatio is how many times the actual rows of an operator can exceed the estimated ones
+	// when the plan is got from the prepared plan cache. The cached plan is evicted and rebuilt once the rows deviate
+	// beyond the ratio for TiDBPlanCacheRowDeviationExecutions consecutive executions. 0 disables the check.
+	TiDBPlanCacheRowDeviationRatio = "tidb_plan_cache_row_deviation_ratio"
+
+	// TiDBPlanCacheRowDeviationExecutions is the number of the consecutive deviated executions evicting a cached plan.
+	TiDBPlanCacheRowDeviationExecutions = "tidb_plan_cache_row_deviation_executions"
+
+	// TiDBOptFixControl turns the numbered behavior changes of the optimizer on or off, like "756:OFF,1234:ON",
+	// so a plan regression caused by a fix can be avoided without downgrading. See the fixcontrol package for the fixes.
+	TiDBOptFixControl = "tidb_opt_fix_control"
+
+	// TiDBMaxRowSize is the max size in bytes of a row written to a table, including its key. A row is also limited by
+	// the txn-entry-size-limit config, 0 means it's the only limit.
+	TiDBMaxRowSize = "tidb_max_row_size"
+
+	// TiDBMaxColumnSize is the max size in bytes of a column value written to a table, 0 means no limit.
+	TiDBMaxColumnSize = "tidb_max_column_size"
+
+	// TiDBTableStatsEstimation chooses how TABLE_ROWS, DATA_LENGTH and INDEX_LENGTH of information_schema.TABLES
+	// and PARTITIONS are estimated. FAST estimates them from the stats, and ACCURATE loads the approximate sizes
+	// of the tables from PD, which is slower but not affected by the stale stats.
+	TiDBTableStatsEstimation = "tidb_table_stats_estimation"
+
 	// TiDBInitChunkSize is used to control the init chunk size during query execution.
 	TiDBInitChunkSize = "tidb_init_chunk_size"
 
@@ -493,6 +599,9 @@ const (
 	// TiDBStmtSummaryMaxSQLLength indicates the max length of displayed normalized sql and sample sql.
 	TiDBStmtSummaryMaxSQLLength = "tidb_stmt_summary_max_sql_length"
 
+	// TiDBStmtSummaryPlanHistorySize indicates the max number of distinct plans kept for each statement in the plan history.
+	TiDBStmtSummaryPlanHistorySize = "tidb_stmt_summary_plan_history_size"
+
 	// TiDBCapturePlanBaseline indicates whether the capture of plan baselines is enabled.
 	TiDBCapturePlanBaseline = "tidb_capture_plan_baselines"
 
@@ -537,6 +646,13 @@ const (
 	// TiDBRedactLog indicates that whether redact log.
 	TiDBRedactLog = "tidb_redact_log"
 
+	// TiDBEnableErrorDocURL indicates whether the documentation URL is appended to the error messages.
+	TiDBEnableErrorDocURL = "tidb_enable_error_doc_url"
+
+	// TiDBEnableStableSQLState indicates whether the TiDB specific error codes are sent with the SQLSTATE values
+	// mapped by their meaning, such as 40001 for the retryable errors, instead of HY000.
+	TiDBEnableStableSQLState = "tidb_enable_stable_sqlstate"
+
 	// TiDBRestrictedReadOnly is meant for the cloud admin to toggle the cluster read only
 	TiDBRestrictedReadOnly = "tidb_restricted_read_only"
 
@@ -548,6 +664,15 @@ const (
 	// TiDBEnableTelemetry indicates that whether usage data report to PingCAP is enabled.
 	TiDBEnableTelemetry = "tidb_enable_telemetry"
 
+	// TiDBTelemetryReportFeatureUsage indicates whether feature usage data is included in the telemetry report.
+	TiDBTelemetryReportFeatureUsage = "tidb_telemetry_report_feature_usage"
+
+	// TiDBTelemetryReportHardware indicates whether hardware and host data is included in the telemetry report.
+	TiDBTelemetryReportHardware = "tidb_telemetry_report_hardware"
+
+	// TiDBTelemetryReportSlowQuery indicates whether slow query histograms are included in the telemetry report.
+	TiDBTelemetryReportSlowQuery = "tidb_telemetry_report_slow_query"
+
 	// TiDBEnableAmendPessimisticTxn indicates if amend pessimistic transactions is enabled.
 	TiDBEnableAmendPessimisticTxn = "tidb_enable_amend_pessimistic_txn"
 
@@ -609,6 +734,19 @@ const (
 	// TiDBRegardNULLAsPoint indicates whether regard NULL as point when optimizing
 	TiDBRegardNULLAsPoint = "tidb_regard_null_as_point"
 
+	// TiDBEnableNullAwareAntiJoin indicates whether to use the null-aware anti semi join for NOT IN on nullable columns.
+	TiDBEnableNullAwareAntiJoin = "tidb_enable_null_aware_anti_join"
+
+	// TiDBEnablePartitionWiseJoin indicates whether to join the co-partitioned tables partition by partition.
+	TiDBEnablePartitionWiseJoin = "tidb_enable_partition_wise_join"
+
+	// TiDBResultFlushThreshold indicates the number of buffered bytes of the query result to flush to the client.
+	TiDBResultFlushThreshold = "tidb_result_flush_threshold"
+
+	// TiDBEnableCommutativeRetry indicates whether to retry the optimistic transactions only running commutative updates,
+	// such as `UPDATE t SET c = c + 1 WHERE id = 1`, even if tidb_disable_txn_auto_retry is on.
+	TiDBEnableCommutativeRetry = "tidb_enable_commutative_retry"
+
 	// TiDBTmpTableMaxSize indicates the max memory size of temporary tables.
 	TiDBTmpTableMaxSize = "tidb_tmp_table_max_size"
 
@@ -734,6 +872,28 @@ const (
 	DefTiDBHashExchangeWithNewCollation   = true
 	DefTiDBEnforceMPPExecution            = false
 	DefTiDBMPPStoreFailTTL                = "60s"
+	DefTiDBTiFlashPreferLocalZone         = false
+	DefTiDBTiFlashReplicaRead             = TiFlashReplicaReadAllReplicas
+	DefTiDBTiFlashReplicaReadNodes        = ""
+	DefTiDBBatchCopResponseChannelSize    = 2048
+	DefTiDBBatchCopResponseMaxBytes       = 0
+	DefTiDBEnableBatchCopResponseSpill    = false
+	DefTiDBEnableTiKVBatchCop             = false
+	DefTiDBTraceCopTaskSeq                = false
+	DefTiDBMaxRegionsPerBatchCopTask      = 0
+	DefTiDBBatchCopConcurrency            = 0
+	DefTiDBBatchCopStreamStallThreshold   = "0s"
+	DefTiDBBatchCopStreamStallReconnect   = false
+	DefTiDBEnableBatchCopTaskCache        = false
+	DefTiDBTiFlashFallbackRangesToTiKV    = false
+	DefTiDBTmpStorageQuota                = -1
+	DefTiDBReadOnlyStmtRetryLimit         = 0
+	DefTiDBShowCreateTableMySQLCompatible = false
+	DefTiDBPlanCacheRowDeviationRatio     = 0.0
+	DefTiDBPlanCacheRowDeviationExecs     = 3
+	DefTiDBMaxRowSize                     = 0
+	DefTiDBMaxColumnSize                  = 0
+	DefTiDBTableStatsEstimation           = TableStatsEstimationFast
 	DefTiDBTxnMode                        = ""
 	DefTiDBRowFormatV1                    = 1
 	DefTiDBRowFormatV2                    = 2
@@ -780,10 +940,15 @@ const (
 	DefTiDBAllowAutoRandExplicitInsert    = false
 	DefTiDBEnableClusteredIndex           = ClusteredIndexDefModeIntOnly
 	DefTiDBRedactLog                      = false
+	DefTiDBEnableErrorDocURL              = false
+	DefTiDBEnableStableSQLState           = false
 	DefTiDBRestrictedReadOnly             = false
 	DefTiDBSuperReadOnly                  = false
 	DefTiDBShardAllocateStep              = math.MaxInt64
 	DefTiDBEnableTelemetry                = true
+	DefTiDBTelemetryReportFeatureUsage    = true
+	DefTiDBTelemetryReportHardware        = true
+	DefTiDBTelemetryReportSlowQuery       = true
 	DefTiDBEnableParallelApply            = false
 	DefTiDBEnableAmendPessimisticTxn      = false
 	DefTiDBPartitionPruneMode             = "static"
@@ -803,6 +968,10 @@ const (
 	DefTiDBEnableOrderedResultMode        = false
 	DefTiDBEnablePseudoForOutdatedStats   = true
 	DefTiDBRegardNULLAsPoint              = true
+	DefTiDBEnableNullAwareAntiJoin        = false
+	DefTiDBEnablePartitionWiseJoin        = false
+	DefTiDBResultFlushThreshold           = 0
+	DefTiDBEnableCommutativeRetry         = false
 	DefEnablePlacementCheck               = true
 	DefTimestamp                          = "0"
 	DefTiDBEnableStmtSummary              = true
@@ -811,6 +980,7 @@ const (
 	DefTiDBStmtSummaryHistorySize         = 24
 	DefTiDBStmtSummaryMaxStmtCount        = 3000
 	DefTiDBStmtSummaryMaxSQLLength        = 4096
+	DefTiDBStmtSummaryPlanHistorySize     = 5
 	DefTiDBCapturePlanBaseline            = Off
 	DefTiDBEnableIndexMerge               = true
 	DefTiDBTableCacheLease                = 3 // 3s
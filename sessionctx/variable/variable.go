@@ -73,6 +73,23 @@ const (
 	AssertionFastStr = "FAST"
 	// AssertionOffStr is a choice of variable TiDBTxnAssertionLevel that means no assertion should be performed.
 	AssertionOffStr = "OFF"
+
+	// TableStatsEstimationFast is a choice of variable TiDBTableStatsEstimation that means the rows and sizes of the
+	// tables are estimated from the stats.
+	TableStatsEstimationFast = "FAST"
+	// TableStatsEstimationAccurate is a choice of variable TiDBTableStatsEstimation that means the sizes of the
+	// tables are loaded from PD.
+	TableStatsEstimationAccurate = "ACCURATE"
+
+	// TiFlashReplicaReadAllReplicas is a choice of variable TiDBTiFlashReplicaRead that means all the TiFlash stores
+	// having the replicas of the regions are used.
+	TiFlashReplicaReadAllReplicas = "all_replicas"
+	// TiFlashReplicaReadClosestAdaptive is a choice of variable TiDBTiFlashReplicaRead that means the TiFlash stores
+	// in the same zone as TiDB are preferred.
+	TiFlashReplicaReadClosestAdaptive = "closest_adaptive"
+	// TiFlashReplicaReadSpecifiedNodes is a choice of variable TiDBTiFlashReplicaRead that means only the TiFlash stores
+	// in TiDBTiFlashReplicaReadNodes are used.
+	TiFlashReplicaReadSpecifiedNodes = "specified_nodes"
 )
 
 // Global config name list.
@@ -592,6 +609,6 @@ type GlobalVarAccessor interface {
 	SetGlobalSysVarOnly(name string, value string) error
 	// GetTiDBTableValue gets a value from mysql.tidb for the key 'name'
 	GetTiDBTableValue(name string) (string, error)
-	// SetTiDBTableValue sets a value+comment for the mysql.tidb key 'name'
-	SetTiDBTableValue(name, value, comment string) error
+	// SetTiDBTableValue sets a value for the mysql.tidb key 'name', the change is recorded in mysql.cluster_setting_history
+	SetTiDBTableValue(name, value string) error
 }
@@ -16,6 +16,7 @@ package variable
 
 import (
 	"fmt"
+	"net"
 	"sort"
 	"strconv"
 	"strings"
@@ -237,9 +238,9 @@ func getTiDBTableValue(vars *SessionVars, name, defaultVal string) (string, erro
 	return trueFalseToOnOff(val), nil
 }
 
-func setTiDBTableValue(vars *SessionVars, name, value, comment string) error {
+func setTiDBTableValue(vars *SessionVars, name, value string) error {
 	value = OnOffToTrueFalse(value)
-	return vars.GlobalVarsAccessor.SetTiDBTableValue(name, value, comment)
+	return vars.GlobalVarsAccessor.SetTiDBTableValue(name, value)
 }
 
 // In mysql.tidb the convention has been to store the string value "true"/"false",
@@ -386,6 +387,22 @@ func tidbOptFloat64(opt string, defaultVal float64) float64 {
 	return val
 }
 
+// parseTiFlashReplicaReadNodes parses the comma separated addresses of TiDBTiFlashReplicaReadNodes.
+func parseTiFlashReplicaReadNodes(s string) ([]string, error) {
+	var nodes []string
+	for _, node := range strings.Split(s, ",") {
+		node = strings.TrimSpace(node)
+		if node == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(node); err != nil {
+			return nil, ErrWrongValueForVar.GenWithStackByArgs(TiDBTiFlashReplicaReadNodes, s)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
 func parseTimeZone(s string) (*time.Location, error) {
 	if strings.EqualFold(s, "SYSTEM") {
 		return timeutil.SystemLocation(), nil
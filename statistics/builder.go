@@ -19,6 +19,7 @@ import (
 	"math"
 
 	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/planner/util/fixcontrol"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/types"
@@ -263,10 +264,12 @@ func BuildHistAndTopN(
 	}
 	curCnt := float64(0)
 	var corrXYSum float64
+	// The correlation of the index is only collected if it's turned on by the fix control.
+	calcCorr := isColumn || fixcontrol.GetBoolWithDefault(ctx.GetSessionVars().OptimizerFixControl, fixcontrol.Fix784, false)
 
 	// Iterate through the samples
 	for i := int64(0); i < sampleNum; i++ {
-		if isColumn {
+		if calcCorr {
 			corrXYSum += float64(i) * float64(samples[i].Ordinal)
 		}
 
@@ -307,8 +310,8 @@ func BuildHistAndTopN(
 		cur, curCnt = sampleBytes, 1
 	}
 
-	// Calc the correlation of the column between the handle column.
-	if isColumn {
+	// Calc the correlation of the column or the index between the handle column.
+	if calcCorr {
 		hg.Correlation = calcCorrelation(sampleNum, corrXYSum)
 	}
 
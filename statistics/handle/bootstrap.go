@@ -108,6 +108,7 @@ func (h *Handle) initStatsHistograms4Chunk(is infoschema.InfoSchema, cache *stat
 				terror.Log(errors.Trace(err))
 			}
 			hist := statistics.NewHistogram(id, ndv, nullCount, version, types.NewFieldType(mysql.TypeBlob), chunk.InitialCapacity, 0)
+			hist.Correlation = row.GetFloat64(9)
 			index := &statistics.Index{
 				Histogram: *hist,
 				CMSketch:  cms,
@@ -756,6 +756,12 @@ func TestCorrelation(t *testing.T) {
 	result = testKit.MustQuery("show stats_histograms where Table_name = 't' and Is_index = 1").Sort()
 	require.Len(t, result.Rows(), 1)
 	require.Equal(t, "0", result.Rows()[0][9])
+	// The correlation of the index is collected by the version 2 stats if the fix control turns it on.
+	testKit.MustExec("set @@tidb_opt_fix_control = '784:ON'")
+	testKit.MustExec("analyze table t")
+	result = testKit.MustQuery("show stats_histograms where Table_name = 't' and Is_index = 1").Sort()
+	require.Len(t, result.Rows(), 1)
+	require.Equal(t, "1", result.Rows()[0][9])
 }
 
 func TestAnalyzeVirtualCol(t *testing.T) {
@@ -769,6 +775,10 @@ func TestAnalyzeVirtualCol(t *testing.T) {
 	tk.MustExec("set @@tidb_analyze_version = 2")
 	tk.MustExec("analyze table t")
 	require.Len(t, tk.MustQuery("show stats_histograms where table_name ='t'").Rows(), 3)
+	// The virtual column is analyzed with the fix 783.
+	tk.MustExec("set @@tidb_opt_fix_control = '783:ON'")
+	tk.MustExec("analyze table t")
+	require.Len(t, tk.MustQuery("show stats_histograms where table_name ='t'").Rows(), 4)
 }
 
 func TestShowGlobalStats(t *testing.T) {
@@ -76,8 +76,8 @@ type Histogram struct {
 	TotColSize int64
 
 	// Correlation is the statistical correlation between physical row ordering and logical ordering of
-	// the column values. This ranges from -1 to +1, and it is only valid for Column histogram, not for
-	// Index histogram.
+	// the column values. This ranges from -1 to +1. For Index histogram, it is only collected by the
+	// version 2 stats if fixcontrol.Fix784 is on, and it's the correlation between the handles and the index values.
 	Correlation float64
 }
 
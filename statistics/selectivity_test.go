@@ -747,6 +747,36 @@ func TestRangeStepOverflow(t *testing.T) {
 	tk.MustQuery("select * from t where col between '8499-1-23 2:14:38' and '9961-7-23 18:35:26'").Check(testkit.Rows())
 }
 
+func TestExpressionIndexEstimation(t *testing.T) {
+	domain.RunAutoAnalyze = false
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("set @@tidb_analyze_version = 2")
+	tk.MustExec("set @@tidb_opt_fix_control = '783:ON'")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int, b int, index idx((a + 1)))")
+	tk.MustExec("insert into t values (1, 1), (1, 2), (1, 3), (2, 4), (3, 5), (4, 6), (5, 7), (null, 8)")
+	h := dom.StatsHandle()
+	require.Nil(t, h.DumpStatsDeltaToKV(handle.DumpAll))
+	tk.MustExec("analyze table t with 0 topn")
+	// The hidden column of the expression index is analyzed.
+	tk.MustQuery("select distinct_count, null_count from mysql.stats_histograms where is_index = 0 and hist_id = 3").Check(testkit.Rows("5 1"))
+	// Trigger the loading of the column stats.
+	tk.MustQuery("select * from t ignore index(idx) where a + 1 = 2").Sort().Check(testkit.Rows("1 1", "1 2", "1 3"))
+	require.Nil(t, h.LoadNeededHistograms())
+	// The predicates on the expression are estimated by the histogram of the hidden column, though the index is ignored.
+	tk.MustQuery("explain format = 'brief' select * from t ignore index(idx) where a + 1 = 2").Check(testkit.Rows(
+		"TableReader 3.00 root  data:Selection",
+		"└─Selection 3.00 cop[tikv]  eq(plus(test.t.a, 1), 2)",
+		"  └─TableFullScan 8.00 cop[tikv] table:t keep order:false"))
+	tk.MustQuery("explain format = 'brief' select * from t ignore index(idx) where a + 1 < 3").Check(testkit.Rows(
+		"TableReader 3.00 root  data:Selection",
+		"└─Selection 3.00 cop[tikv]  lt(plus(test.t.a, 1), 3)",
+		"  └─TableFullScan 8.00 cop[tikv] table:t keep order:false"))
+}
+
 func TestSmallRangeEstimation(t *testing.T) {
 	domain.RunAutoAnalyze = false
 	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
@@ -0,0 +1,160 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/driver/backoff"
+	"github.com/tikv/client-go/v2/tikv"
+)
+
+// BackoffPolicy decides how the coprocessor tasks back off before retrying on the errors, so the analytical
+// workloads can retry more patiently than the point reads.
+// The backoffs made inside client-go, e.g. on the region errors while sending the requests, are not affected.
+type BackoffPolicy interface {
+	backoff.Policy
+	// BuildTaskMaxSleep returns the max total time(in ms) to sleep while building the tasks.
+	BuildTaskMaxSleep() int
+	// TaskMaxSleep returns the max total time(in ms) to sleep while handling a task.
+	TaskMaxSleep() int
+}
+
+// ExponentialBackoffPolicy doubles the sleep before every retry from Base until Cap, with the equal jitter,
+// i.e. the time to sleep is a random one in [d/2, d] where d is the doubled one.
+type ExponentialBackoffPolicy struct {
+	Base int
+	Cap  int
+
+	buildTaskMaxSleep int
+	taskMaxSleep      int
+}
+
+// NewExponentialBackoffPolicy creates an ExponentialBackoffPolicy, the times are in ms.
+func NewExponentialBackoffPolicy(base, ceiling, buildTaskMaxSleep, taskMaxSleep int) *ExponentialBackoffPolicy {
+	return &ExponentialBackoffPolicy{
+		Base:              base,
+		Cap:               ceiling,
+		buildTaskMaxSleep: buildTaskMaxSleep,
+		taskMaxSleep:      taskMaxSleep,
+	}
+}
+
+// Sleep implements the BackoffPolicy interface.
+func (p *ExponentialBackoffPolicy) Sleep(_ string, attempt int) int {
+	d := p.Cap
+	if attempt < 30 && p.Base<<attempt < p.Cap {
+		d = p.Base << attempt
+	}
+	if d <= 0 {
+		return 0
+	}
+	/* #nosec G404 */
+	return d/2 + rand.Intn(d-d/2+1)
+}
+
+// BuildTaskMaxSleep implements the BackoffPolicy interface.
+func (p *ExponentialBackoffPolicy) BuildTaskMaxSleep() int {
+	return p.buildTaskMaxSleep
+}
+
+// TaskMaxSleep implements the BackoffPolicy interface.
+func (p *ExponentialBackoffPolicy) TaskMaxSleep() int {
+	return p.taskMaxSleep
+}
+
+// FixedBackoffPolicy sleeps the same time before every retry.
+type FixedBackoffPolicy struct {
+	Interval int
+
+	buildTaskMaxSleep int
+	taskMaxSleep      int
+}
+
+// NewFixedBackoffPolicy creates a FixedBackoffPolicy, the times are in ms.
+func NewFixedBackoffPolicy(interval, buildTaskMaxSleep, taskMaxSleep int) *FixedBackoffPolicy {
+	return &FixedBackoffPolicy{
+		Interval:          interval,
+		buildTaskMaxSleep: buildTaskMaxSleep,
+		taskMaxSleep:      taskMaxSleep,
+	}
+}
+
+// Sleep implements the BackoffPolicy interface.
+func (p *FixedBackoffPolicy) Sleep(string, int) int {
+	return p.Interval
+}
+
+// BuildTaskMaxSleep implements the BackoffPolicy interface.
+func (p *FixedBackoffPolicy) BuildTaskMaxSleep() int {
+	return p.buildTaskMaxSleep
+}
+
+// TaskMaxSleep implements the BackoffPolicy interface.
+func (p *FixedBackoffPolicy) TaskMaxSleep() int {
+	return p.taskMaxSleep
+}
+
+// backoffPolicies keeps the backoff policies of the store types, the ones not set follow the backoff configs
+// of client-go with copBuildTaskMaxBackoff and copNextMaxBackoff.
+// The zero value is ready to use.
+type backoffPolicies struct {
+	mu       sync.RWMutex
+	policies map[kv.StoreType]BackoffPolicy
+}
+
+func (p *backoffPolicies) set(storeType kv.StoreType, policy BackoffPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if policy == nil {
+		delete(p.policies, storeType)
+		return
+	}
+	if p.policies == nil {
+		p.policies = make(map[kv.StoreType]BackoffPolicy)
+	}
+	p.policies[storeType] = policy
+}
+
+func (p *backoffPolicies) get(storeType kv.StoreType) BackoffPolicy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.policies[storeType]
+}
+
+// SetBackoffPolicy sets the backoff policy of the requests to the store type, it's shared by all the clients
+// of the store. A nil policy restores the default one.
+func (c *CopClient) SetBackoffPolicy(storeType kv.StoreType, policy BackoffPolicy) {
+	c.store.backoffPolicies.set(storeType, policy)
+}
+
+// newBuildTaskBackoffer creates the Backoffer to build the tasks to the store type.
+func (s *kvStore) newBuildTaskBackoffer(ctx context.Context, storeType kv.StoreType, vars *tikv.Variables) *Backoffer {
+	if policy := s.backoffPolicies.get(storeType); policy != nil {
+		return backoff.NewBackofferWithPolicy(ctx, policy.BuildTaskMaxSleep(), vars, policy)
+	}
+	return backoff.NewBackofferWithVars(ctx, copBuildTaskMaxBackoff, vars)
+}
+
+// newTaskBackoffer creates the Backoffer to handle a task to the store type.
+func (s *kvStore) newTaskBackoffer(ctx context.Context, storeType kv.StoreType, vars *tikv.Variables) *Backoffer {
+	if policy := s.backoffPolicies.get(storeType); policy != nil {
+		return backoff.NewBackofferWithPolicy(ctx, policy.TaskMaxSleep(), vars, policy)
+	}
+	return backoff.NewBackofferWithVars(ctx, copNextMaxBackoff, vars)
+}
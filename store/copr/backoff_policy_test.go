@@ -0,0 +1,86 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/kv"
+	derr "github.com/pingcap/tidb/store/driver/error"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/client-go/v2/tikv"
+)
+
+func TestExponentialBackoffPolicy(t *testing.T) {
+	p := NewExponentialBackoffPolicy(10, 100, 500, 2000)
+	require.Equal(t, 500, p.BuildTaskMaxSleep())
+	require.Equal(t, 2000, p.TaskMaxSleep())
+	expected := []int{10, 20, 40, 80, 100, 100}
+	for attempt, d := range expected {
+		for i := 0; i < 20; i++ {
+			sleep := p.Sleep("tikvRPC", attempt)
+			require.GreaterOrEqual(t, sleep, d/2)
+			require.LessOrEqual(t, sleep, d)
+		}
+	}
+	// The sleep doesn't overflow after many retries.
+	sleep := p.Sleep("tikvRPC", 100)
+	require.GreaterOrEqual(t, sleep, 50)
+	require.LessOrEqual(t, sleep, 100)
+}
+
+func TestFixedBackoffPolicy(t *testing.T) {
+	p := NewFixedBackoffPolicy(3, 10, 20)
+	require.Equal(t, 10, p.BuildTaskMaxSleep())
+	require.Equal(t, 20, p.TaskMaxSleep())
+	for attempt := 0; attempt < 5; attempt++ {
+		require.Equal(t, 3, p.Sleep("tiflashRPC", attempt))
+	}
+}
+
+func TestBackoffPolicyOfStoreType(t *testing.T) {
+	store := &kvStore{}
+	ctx := context.Background()
+	client := &CopClient{store: &Store{kvStore: store}}
+	client.SetBackoffPolicy(kv.TiFlash, NewFixedBackoffPolicy(3, 5, 7))
+
+	// The store types without a policy follow client-go.
+	bo := store.newTaskBackoffer(ctx, kv.TiKV, nil)
+	require.NoError(t, bo.Backoff(tikv.BoTiKVRPC(), errors.New("tikv rpc")))
+	require.Equal(t, 1, bo.GetBackoffTimes()["tikvRPC"])
+
+	bo = store.newBuildTaskBackoffer(ctx, kv.TiFlash, nil)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, bo.Backoff(tikv.BoTiFlashRPC(), errors.New("tiflash rpc")))
+	}
+	require.Equal(t, 2, bo.GetBackoffTimes()["tiflashRPC"])
+	require.Equal(t, 6, bo.GetBackoffSleepMS()["tiflashRPC"])
+	require.Equal(t, 6, bo.GetTotalSleep())
+	err := bo.Backoff(tikv.BoTiFlashRPC(), errors.New("tiflash rpc"))
+	require.True(t, derr.ErrTiFlashServerTimeout.Equal(err))
+
+	bo = store.newTaskBackoffer(ctx, kv.TiFlash, nil)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, bo.Backoff(tikv.BoRegionMiss(), errors.New("region miss")))
+	}
+	err = bo.Backoff(tikv.BoRegionMiss(), errors.New("region miss"))
+	require.True(t, derr.ErrRegionUnavailable.Equal(err))
+
+	// A nil policy restores the default one.
+	client.SetBackoffPolicy(kv.TiFlash, nil)
+	require.Nil(t, store.backoffPolicies.get(kv.TiFlash))
+}
@@ -0,0 +1,135 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/tikv/client-go/v2/tikv"
+	"go.uber.org/zap"
+)
+
+var (
+	// maxBatchCopBalanceDecisions is the number of the latest balance decisions kept for diagnosis.
+	maxBatchCopBalanceDecisions = 128
+	// batchCopBalanceLogInterval is the min interval between the logs of the balance decisions, the decisions
+	// between them are only counted.
+	batchCopBalanceLogInterval = 10 * time.Second
+)
+
+// BatchCopBalanceDecision is how the regions of a request are balanced between the stores when its batch cop tasks
+// are built.
+type BatchCopBalanceDecision struct {
+	Time        time.Time `json:"time"`
+	RegionCount int       `json:"region_count"`
+	// MovedRegionCount is the number of the regions moved to other stores than the ones they are located at.
+	MovedRegionCount int    `json:"moved_region_count"`
+	BalanceElapsed   string `json:"balance_elapsed"`
+	// Before and After are the numbers of the regions of every store before and after the balance.
+	Before []BatchCopStoreRegions `json:"before"`
+	After  []BatchCopStoreRegions `json:"after"`
+}
+
+// BatchCopStoreRegions is the number of the regions read from a store.
+type BatchCopStoreRegions struct {
+	StoreAddr   string `json:"store_addr"`
+	RegionCount int    `json:"region_count"`
+}
+
+func newBatchCopStoreRegions(tasks []*batchCopTask) []BatchCopStoreRegions {
+	stores := make([]BatchCopStoreRegions, 0, len(tasks))
+	for _, task := range tasks {
+		stores = append(stores, BatchCopStoreRegions{StoreAddr: task.storeAddr, RegionCount: len(task.regionInfos)})
+	}
+	return stores
+}
+
+// batchCopBalanceLog keeps the latest balance decisions, and logs them by sampling, at most one log per
+// batchCopBalanceLogInterval. The zero value is ready to use.
+type batchCopBalanceLog struct {
+	mu        sync.Mutex
+	decisions []BatchCopBalanceDecision
+	// next is the index the next decision is stored at once the decisions are full.
+	next       int
+	lastLogged time.Time
+	// skipped is the number of the decisions not logged since the last log.
+	skipped int
+}
+
+// record keeps the decision of the balance, before is the regions of the stores before the balance, and regionAddrs
+// are the addresses of the stores the regions are located at.
+func (l *batchCopBalanceLog) record(before []BatchCopStoreRegions, after []*batchCopTask, regionAddrs map[tikv.RegionVerID]string, elapsed time.Duration, now time.Time) {
+	d := BatchCopBalanceDecision{
+		Time:           now,
+		BalanceElapsed: elapsed.String(),
+		Before:         before,
+		After:          newBatchCopStoreRegions(after),
+	}
+	for _, task := range after {
+		d.RegionCount += len(task.regionInfos)
+		for _, ri := range task.regionInfos {
+			if addr, ok := regionAddrs[ri.Region]; ok && addr != task.storeAddr {
+				d.MovedRegionCount++
+			}
+		}
+	}
+
+	l.mu.Lock()
+	if len(l.decisions) < maxBatchCopBalanceDecisions {
+		l.decisions = append(l.decisions, d)
+	} else {
+		l.decisions[l.next] = d
+		l.next = (l.next + 1) % len(l.decisions)
+	}
+	if now.Sub(l.lastLogged) < batchCopBalanceLogInterval {
+		l.skipped++
+		l.mu.Unlock()
+		return
+	}
+	skipped := l.skipped
+	l.lastLogged, l.skipped = now, 0
+	l.mu.Unlock()
+
+	logutil.BgLogger().Info("balance batch cop tasks",
+		zap.Int("region count", d.RegionCount),
+		zap.Int("moved region count", d.MovedRegionCount),
+		zap.Any("before", d.Before),
+		zap.Any("after", d.After),
+		zap.String("balance elapsed", d.BalanceElapsed),
+		zap.Int("skipped decisions", skipped))
+}
+
+// latest returns at most n latest decisions, the latest one is the first.
+func (l *batchCopBalanceLog) latest(n int) []BatchCopBalanceDecision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n <= 0 || n > len(l.decisions) {
+		n = len(l.decisions)
+	}
+	decisions := make([]BatchCopBalanceDecision, 0, n)
+	for i := 1; i <= n; i++ {
+		idx := (l.next - i + len(l.decisions)) % len(l.decisions)
+		decisions = append(decisions, l.decisions[idx])
+	}
+	return decisions
+}
+
+// BatchCopBalanceDecisions returns at most n latest balance decisions of the batch cop tasks built by the client,
+// the latest one is the first. All the kept decisions are returned if n is not positive.
+func (c *CopClient) BatchCopBalanceDecisions(n int) []BatchCopBalanceDecision {
+	return c.store.batchCopBalanceLog.latest(n)
+}
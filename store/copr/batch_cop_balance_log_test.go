@@ -0,0 +1,68 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/client-go/v2/tikv"
+)
+
+func TestBatchCopBalanceLog(t *testing.T) {
+	defer func(max int) {
+		maxBatchCopBalanceDecisions = max
+	}(maxBatchCopBalanceDecisions)
+	maxBatchCopBalanceDecisions = 3
+
+	var l batchCopBalanceLog
+	require.Len(t, l.latest(10), 0)
+
+	region1, region2 := tikv.NewRegionVerID(1, 1, 1), tikv.NewRegionVerID(2, 1, 1)
+	regionAddrs := map[tikv.RegionVerID]string{region1: "store1", region2: "store1"}
+	before := []BatchCopStoreRegions{{StoreAddr: "store1", RegionCount: 2}}
+	after := []*batchCopTask{
+		{storeAddr: "store1", regionInfos: []RegionInfo{{Region: region1}}},
+		{storeAddr: "store2", regionInfos: []RegionInfo{{Region: region2}}},
+	}
+	now := time.Now()
+	l.record(before, after, regionAddrs, time.Millisecond, now)
+	decisions := l.latest(0)
+	require.Len(t, decisions, 1)
+	require.Equal(t, 2, decisions[0].RegionCount)
+	require.Equal(t, 1, decisions[0].MovedRegionCount)
+	require.Equal(t, before, decisions[0].Before)
+	require.Equal(t, []BatchCopStoreRegions{{StoreAddr: "store1", RegionCount: 1}, {StoreAddr: "store2", RegionCount: 1}}, decisions[0].After)
+	require.Equal(t, 0, l.skipped)
+
+	// The decisions within the log interval are only counted, and only the latest ones are kept.
+	for i := 1; i <= 4; i++ {
+		l.record(before, after, regionAddrs, time.Millisecond, now.Add(time.Duration(i)*time.Second))
+	}
+	require.Equal(t, 4, l.skipped)
+	decisions = l.latest(0)
+	require.Len(t, decisions, 3)
+	for i, d := range decisions {
+		require.Equal(t, now.Add(time.Duration(4-i)*time.Second), d.Time)
+	}
+	decisions = l.latest(2)
+	require.Len(t, decisions, 2)
+	require.Equal(t, now.Add(4*time.Second), decisions[0].Time)
+
+	l.record(before, after, regionAddrs, time.Millisecond, now.Add(batchCopBalanceLogInterval))
+	require.Equal(t, 0, l.skipped)
+	require.Equal(t, now.Add(batchCopBalanceLogInterval), l.latest(1)[0].Time)
+}
@@ -0,0 +1,120 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"context"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/tikv/client-go/v2/tikv"
+)
+
+// BatchCopLayout is the layout of the batch cop tasks of a request. It's built by DryRunBatchCop without sending
+// the requests, to diagnose the skew of the scans between the stores.
+type BatchCopLayout struct {
+	StoreType   string `json:"store_type"`
+	RegionCount int    `json:"region_count"`
+	// Skew is the ratio of the most regions of a task to the average regions of the tasks, 1 means the regions
+	// are evenly distributed.
+	Skew float64 `json:"skew"`
+	// MovedRegionCount is the number of the regions moved to other stores by the balance of the tasks.
+	MovedRegionCount int                  `json:"moved_region_count"`
+	Tasks            []BatchCopTaskLayout `json:"tasks"`
+}
+
+// BatchCopTaskLayout is the layout of a batch cop task, which is the regions read from a store by one request.
+type BatchCopTaskLayout struct {
+	StoreID     uint64 `json:"store_id"`
+	StoreAddr   string `json:"store_addr"`
+	RegionCount int    `json:"region_count"`
+	// FallbackToCop is whether the regions are read by the coprocessor requests, since the store doesn't
+	// support the batch cop requests.
+	FallbackToCop bool                   `json:"fallback_to_cop,omitempty"`
+	Regions       []BatchCopRegionLayout `json:"regions"`
+}
+
+// BatchCopRegionLayout is a region read by a batch cop task.
+type BatchCopRegionLayout struct {
+	RegionID   uint64 `json:"region_id"`
+	RangeCount int    `json:"range_count"`
+	// AllStores are the IDs of the stores the region can be read from.
+	AllStores []uint64 `json:"all_stores"`
+	// LocatedStoreAddr is the address of the store the region is read from before the tasks are balanced.
+	LocatedStoreAddr string `json:"located_store_addr"`
+	Moved            bool   `json:"moved"`
+}
+
+// DryRunBatchCop builds the batch cop tasks of the request the same way as sending it, and returns their layout
+// instead of sending them.
+func (c *CopClient) DryRunBatchCop(ctx context.Context, req *kv.Request) (*BatchCopLayout, error) {
+	ctx = context.WithValue(ctx, tikv.TxnStartKey(), req.StartTs)
+	bo := c.store.newBuildTaskBackoffer(ctx, req.StoreType, nil)
+	tasks, err := c.buildBatchCopTasks(bo, req)
+	if err != nil {
+		return nil, err
+	}
+	if req.MaxRegionsPerBatchCopTask > 0 {
+		tasks = splitBatchCopTasksBySize(tasks, req.MaxRegionsPerBatchCopTask)
+	}
+
+	cache := c.store.GetRegionCache()
+	layout := &BatchCopLayout{StoreType: req.StoreType.Name(), Tasks: make([]BatchCopTaskLayout, 0, len(tasks))}
+	most := 0
+	for _, task := range tasks {
+		taskLayout := BatchCopTaskLayout{
+			StoreAddr:     task.storeAddr,
+			RegionCount:   len(task.regionInfos),
+			FallbackToCop: task.fallbackToCop,
+			Regions:       make([]BatchCopRegionLayout, 0, len(task.regionInfos)),
+		}
+		if task.ctx != nil && task.ctx.Store != nil {
+			taskLayout.StoreID = task.ctx.Store.StoreID()
+		}
+		for _, ri := range task.regionInfos {
+			regionLayout := BatchCopRegionLayout{
+				RegionID:         ri.Region.GetID(),
+				RangeCount:       ri.Ranges.Len(),
+				AllStores:        ri.AllStores,
+				LocatedStoreAddr: task.storeAddr,
+			}
+			if req.StoreType == kv.TiFlash {
+				// The regions of the TiFlash tasks are balanced, the store the region is located at is the one
+				// it's read from without balancing.
+				rpcCtx, err := cache.GetTiFlashRPCContext(bo.TiKVBackoffer(), ri.Region, false)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				if rpcCtx != nil {
+					regionLayout.LocatedStoreAddr = rpcCtx.Addr
+				}
+			}
+			if regionLayout.LocatedStoreAddr != task.storeAddr {
+				regionLayout.Moved = true
+				layout.MovedRegionCount++
+			}
+			taskLayout.Regions = append(taskLayout.Regions, regionLayout)
+		}
+		layout.RegionCount += taskLayout.RegionCount
+		if taskLayout.RegionCount > most {
+			most = taskLayout.RegionCount
+		}
+		layout.Tasks = append(layout.Tasks, taskLayout)
+	}
+	if layout.RegionCount > 0 {
+		layout.Skew = float64(most) * float64(len(tasks)) / float64(layout.RegionCount)
+	}
+	return layout, nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/tidb/kv"
+	derr "github.com/pingcap/tidb/store/driver/error"
+	"github.com/tikv/client-go/v2/tikv"
+)
+
+// batchCopQueueCheckInterval is the interval the waiting requests check whether their queries are killed.
+var batchCopQueueCheckInterval = 100 * time.Millisecond
+
+// batchCopWaiter is a batch cop request waiting in the queue.
+type batchCopWaiter struct {
+	priority int
+	// seq is the order the request enters the queue, the requests of the same priority are admitted by it.
+	seq uint64
+	// admitted is closed once the request is admitted.
+	admitted chan struct{}
+	// index is the index of the waiter in the heap, -1 if it's removed.
+	index int
+}
+
+type batchCopWaiterHeap []*batchCopWaiter
+
+func (h batchCopWaiterHeap) Len() int { return len(h) }
+
+func (h batchCopWaiterHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h batchCopWaiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *batchCopWaiterHeap) Push(x interface{}) {
+	w := x.(*batchCopWaiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *batchCopWaiterHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*h = old[:n-1]
+	return w
+}
+
+// batchCopQueue limits the number of the batch cop requests running in the tidb-server, so the analytical queries
+// arriving at once don't open the streams to TiFlash all together. The requests beyond the limit wait in the queue,
+// and the ones of the higher priorities are admitted first. The zero value is ready to use.
+type batchCopQueue struct {
+	mu      sync.Mutex
+	running int
+	seq     uint64
+	waiters batchCopWaiterHeap
+}
+
+// batchCopPriority ranks the priority of the request, the larger one is admitted first.
+func batchCopPriority(priority int) int {
+	switch priority {
+	case kv.PriorityHigh:
+		return 2
+	case kv.PriorityLow:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// acquire waits until the request is admitted. The request is rejected if maxQueued requests are already waiting,
+// and it stops waiting if the query is killed, the deadline passes or the ctx is done. The returned function must be
+// called once the admitted request is finished. Both limits mean no limit if they're 0.
+func (q *batchCopQueue) acquire(ctx context.Context, priority int, maxRunning, maxQueued int, vars *tikv.Variables, deadline time.Time) (release func(), err error) {
+	if maxRunning <= 0 {
+		return func() {}, nil
+	}
+	q.mu.Lock()
+	if q.running < maxRunning && q.waiters.Len() == 0 {
+		q.running++
+		q.mu.Unlock()
+		return q.releaseFunc(maxRunning), nil
+	}
+	if maxQueued > 0 && q.waiters.Len() >= maxQueued {
+		running, queued := q.running, q.waiters.Len()
+		q.mu.Unlock()
+		return nil, derr.ErrBatchCopQueueFull.GenWithStackByArgs(running, queued)
+	}
+	q.seq++
+	w := &batchCopWaiter{priority: batchCopPriority(priority), seq: q.seq, admitted: make(chan struct{})}
+	heap.Push(&q.waiters, w)
+	q.mu.Unlock()
+
+	ticker := time.NewTicker(batchCopQueueCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.admitted:
+			return q.releaseFunc(maxRunning), nil
+		case <-ctx.Done():
+			return nil, q.cancel(w, maxRunning, ctx.Err())
+		case <-ticker.C:
+			killed := vars != nil && atomic.LoadUint32(vars.Killed) == 1
+			if killed || (!deadline.IsZero() && !time.Now().Before(deadline)) {
+				return nil, q.cancel(w, maxRunning, &QueryCancelled{err: derr.ErrQueryInterrupted})
+			}
+		}
+	}
+}
+
+// cancel removes the waiter from the queue. If it has been admitted meanwhile, the admission is passed on.
+func (q *batchCopQueue) cancel(w *batchCopWaiter, maxRunning int, err error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if w.index >= 0 {
+		heap.Remove(&q.waiters, w.index)
+		return err
+	}
+	q.running--
+	q.admitLocked(maxRunning)
+	return err
+}
+
+func (q *batchCopQueue) releaseFunc(maxRunning int) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			q.running--
+			q.admitLocked(maxRunning)
+		})
+	}
+}
+
+// admitLocked admits the waiting requests by their priorities until the limit is reached.
+func (q *batchCopQueue) admitLocked(maxRunning int) {
+	for q.running < maxRunning && q.waiters.Len() > 0 {
+		w := heap.Pop(&q.waiters).(*batchCopWaiter)
+		q.running++
+		close(w.admitted)
+	}
+}
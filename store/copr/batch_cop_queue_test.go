@@ -0,0 +1,114 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/kv"
+	derr "github.com/pingcap/tidb/store/driver/error"
+	"github.com/stretchr/testify/require"
+	tikvstore "github.com/tikv/client-go/v2/kv"
+)
+
+func TestBatchCopQueue(t *testing.T) {
+	var q batchCopQueue
+	ctx := context.Background()
+
+	// No limit.
+	release, err := q.acquire(ctx, kv.PriorityNormal, 0, 0, nil, time.Time{})
+	require.NoError(t, err)
+	release()
+	require.Equal(t, 0, q.running)
+
+	release1, err := q.acquire(ctx, kv.PriorityNormal, 1, 3, nil, time.Time{})
+	require.NoError(t, err)
+
+	// The waiting requests are admitted by their priorities, then by the order they enter the queue.
+	admitted := make(chan string, 3)
+	releases := make(chan func(), 3)
+	wait := func(name string, priority int) {
+		go func() {
+			release, err := q.acquire(ctx, priority, 1, 3, nil, time.Time{})
+			require.NoError(t, err)
+			admitted <- name
+			releases <- release
+		}()
+		require.Eventually(t, func() bool {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			for _, w := range q.waiters {
+				if w.seq == q.seq {
+					return true
+				}
+			}
+			return false
+		}, time.Second, time.Millisecond)
+	}
+	wait("low", kv.PriorityLow)
+	wait("normal", kv.PriorityNormal)
+	wait("high", kv.PriorityHigh)
+
+	// The queue is full.
+	_, err = q.acquire(ctx, kv.PriorityHigh, 1, 3, nil, time.Time{})
+	require.True(t, derr.ErrBatchCopQueueFull.Equal(err))
+	require.Contains(t, err.Error(), "1 are running and 3 are waiting")
+
+	release1()
+	release1()
+	for _, name := range []string{"high", "normal", "low"} {
+		require.Equal(t, name, <-admitted)
+		(<-releases)()
+	}
+	require.Equal(t, 0, q.running)
+	require.Equal(t, 0, q.waiters.Len())
+}
+
+func TestBatchCopQueueCancel(t *testing.T) {
+	defer func(interval time.Duration) {
+		batchCopQueueCheckInterval = interval
+	}(batchCopQueueCheckInterval)
+	batchCopQueueCheckInterval = time.Millisecond
+
+	var q batchCopQueue
+	release, err := q.acquire(context.Background(), kv.PriorityNormal, 1, 0, nil, time.Time{})
+	require.NoError(t, err)
+
+	// The waiting request stops once the ctx is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = q.acquire(ctx, kv.PriorityNormal, 1, 0, nil, time.Time{})
+	require.ErrorIs(t, err, context.Canceled)
+
+	// The waiting request stops once the query is killed.
+	killed := uint32(1)
+	_, err = q.acquire(context.Background(), kv.PriorityNormal, 1, 0, tikvstore.NewVariables(&killed), time.Time{})
+	require.ErrorAs(t, err, new(*QueryCancelled))
+
+	// The waiting request stops once the deadline passes.
+	_, err = q.acquire(context.Background(), kv.PriorityNormal, 1, 0, nil, time.Now().Add(10*time.Millisecond))
+	require.ErrorAs(t, err, new(*QueryCancelled))
+	require.Equal(t, 0, q.waiters.Len())
+
+	atomic.StoreUint32(&killed, 0)
+	release()
+	require.Equal(t, 0, q.running)
+	release, err = q.acquire(context.Background(), kv.PriorityNormal, 1, 0, tikvstore.NewVariables(&killed), time.Time{})
+	require.NoError(t, err)
+	release()
+}
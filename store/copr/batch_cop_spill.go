@@ -0,0 +1,148 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/coprocessor"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/memory"
+	"go.uber.org/zap"
+)
+
+// batchCopRespSpillFieldTypes is the field types of the chunks the spilled responses are stored in,
+// every chunk has one row which is the encoded response.
+var batchCopRespSpillFieldTypes = []*types.FieldType{types.NewFieldType(mysql.TypeLongBlob)}
+
+// batchCopRespSpiller implements memory.ActionOnExceed for the batch cop responses. Once the memory quota of
+// the query is exceeded, the following responses are stored in the temporary disk instead of respChan, so the
+// workers keep receiving the responses from TiFlash when the consumer is slower than them.
+// The responses already queued in respChan are still kept in memory until they are consumed.
+type batchCopRespSpiller struct {
+	memory.BaseOOMAction
+	// spilled is set to 1 once the action is triggered.
+	spilled uint32
+	// closed is set to 1 once the iterator is closed, the action delegates to the fallback since then.
+	closed uint32
+
+	mu struct {
+		sync.Mutex
+		list *chunk.ListInDisk
+		// resps are the spilled responses without the pbResp, whose encoded data is the chunk at the same index.
+		resps []*batchCopResponse
+		// read is the index of the next response to read.
+		read int
+	}
+	// notify is signaled once a response is spilled, so the consumer waiting on respChan can read it.
+	notify chan struct{}
+}
+
+func newBatchCopRespSpiller() *batchCopRespSpiller {
+	s := &batchCopRespSpiller{notify: make(chan struct{}, 1)}
+	s.mu.list = chunk.NewListInDisk(batchCopRespSpillFieldTypes)
+	return s
+}
+
+// Action implements memory.ActionOnExceed.
+func (s *batchCopRespSpiller) Action(t *memory.Tracker) {
+	if atomic.LoadUint32(&s.closed) == 0 && atomic.CompareAndSwapUint32(&s.spilled, 0, 1) {
+		logutil.BgLogger().Info("memory exceeds quota, spill the batch cop responses to disk now.",
+			zap.Int64("consumed", t.BytesConsumed()), zap.Int64("quota", t.GetBytesLimit()))
+		return
+	}
+	if fallback := s.GetFallback(); fallback != nil {
+		fallback.Action(t)
+	}
+}
+
+// SetLogHook implements memory.ActionOnExceed, it does nothing.
+func (s *batchCopRespSpiller) SetLogHook(hook func(uint64)) {}
+
+// GetPriority implements memory.ActionOnExceed.
+func (s *batchCopRespSpiller) GetPriority() int64 {
+	return memory.DefSpillPriority
+}
+
+func (s *batchCopRespSpiller) isSpilled() bool {
+	return atomic.LoadUint32(&s.spilled) == 1
+}
+
+// spill stores the response in the disk, the response must have no error.
+func (s *batchCopRespSpiller) spill(resp *batchCopResponse) error {
+	data, err := resp.pbResp.Marshal()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	chk := chunk.NewChunkWithCapacity(batchCopRespSpillFieldTypes, 1)
+	chk.AppendBytes(0, data)
+	resp.pbResp = nil
+	resp.respSize = 0
+
+	s.mu.Lock()
+	err = s.mu.list.Add(chk)
+	if err == nil {
+		s.mu.resps = append(s.mu.resps, resp)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// pop reads the earliest spilled response which is not read, it returns false if there is none.
+func (s *batchCopRespSpiller) pop() (*batchCopResponse, bool) {
+	s.mu.Lock()
+	if s.mu.read >= len(s.mu.resps) {
+		s.mu.Unlock()
+		return nil, false
+	}
+	idx := s.mu.read
+	resp := s.mu.resps[idx]
+	s.mu.resps[idx] = nil
+	s.mu.read++
+	chk, err := s.mu.list.GetChunk(idx)
+	s.mu.Unlock()
+	if err != nil {
+		return &batchCopResponse{err: errors.Trace(err)}, true
+	}
+	resp.pbResp = &coprocessor.BatchResponse{}
+	if err = resp.pbResp.Unmarshal(chk.GetRow(0).GetBytes(0)); err != nil {
+		return &batchCopResponse{err: errors.Trace(err)}, true
+	}
+	return resp, true
+}
+
+// close removes the temporary file, it should be called after all the workers exit.
+func (s *batchCopRespSpiller) close() {
+	if !atomic.CompareAndSwapUint32(&s.closed, 0, 1) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.mu.list.Close(); err != nil {
+		logutil.BgLogger().Warn("close the spilled batch cop responses failed", zap.Error(err))
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/driver/backoff"
+	"github.com/pingcap/tidb/util/kvcache"
+	"github.com/tikv/client-go/v2/tikv"
+)
+
+const (
+	// batchCopTaskCacheCapacity is the max number of the task layouts kept in the cache.
+	batchCopTaskCacheCapacity = 256
+	// batchCopTaskCacheTTL is how long a task layout can be reused, so the layout is balanced again
+	// with the recent health and load of the stores from time to time.
+	batchCopTaskCacheTTL = 10 * time.Second
+)
+
+// batchCopTaskCacheKey is the fingerprint of the ranges and the options to build the batch cop tasks.
+type batchCopTaskCacheKey []byte
+
+// Hash implements the kvcache.Key interface.
+func (k batchCopTaskCacheKey) Hash() []byte {
+	return k
+}
+
+func newBatchCopTaskCacheKey(ranges *KeyRanges, storeType kv.StoreType, balanceWithContinuity bool, balanceContinuousRegionCount int64, preferredZone string, allowedAddrs []string) batchCopTaskCacheKey {
+	h := sha256.New()
+	var buf [binary.MaxVarintLen64]byte
+	writeUint := func(v uint64) {
+		h.Write(buf[:binary.PutUvarint(buf[:], v)])
+	}
+	writeBytes := func(b []byte) {
+		writeUint(uint64(len(b)))
+		h.Write(b)
+	}
+	writeUint(uint64(storeType))
+	if balanceWithContinuity {
+		writeUint(1)
+	} else {
+		writeUint(0)
+	}
+	writeUint(uint64(balanceContinuousRegionCount))
+	writeBytes([]byte(preferredZone))
+	writeUint(uint64(len(allowedAddrs)))
+	for _, addr := range allowedAddrs {
+		writeBytes([]byte(addr))
+	}
+	writeUint(uint64(ranges.Len()))
+	ranges.Do(func(ran *kv.KeyRange) {
+		writeBytes(ran.StartKey)
+		writeBytes(ran.EndKey)
+	})
+	return h.Sum(nil)
+}
+
+// batchCopTaskCacheEntry is a cached task layout.
+type batchCopTaskCacheEntry struct {
+	tasks []*batchCopTask
+	// regionAddrs is the address of the TiFlash peer each region is resolved to when the layout is built.
+	regionAddrs map[tikv.RegionVerID]string
+	createTime  time.Time
+}
+
+// batchCopTaskCache memoizes the region-to-store layouts of the batch cop tasks, so the queries reading
+// the same ranges don't need to split the ranges and balance the regions again. A layout is reused only
+// when all its regions are still valid in the region cache with the same epoch and TiFlash peer.
+// The zero value is ready to use.
+type batchCopTaskCache struct {
+	mu  sync.Mutex
+	lru *kvcache.SimpleLRUCache
+}
+
+// get returns a copy of the cached layout of the key, or nil if there is no valid one.
+func (c *batchCopTaskCache) get(bo *backoff.Backoffer, cache *RegionCache, key batchCopTaskCacheKey, now time.Time) []*batchCopTask {
+	c.mu.Lock()
+	var entry *batchCopTaskCacheEntry
+	if c.lru != nil {
+		if v, ok := c.lru.Get(key); ok {
+			entry = v.(*batchCopTaskCacheEntry)
+		}
+	}
+	c.mu.Unlock()
+	if entry == nil {
+		return nil
+	}
+	if now.Sub(entry.createTime) < batchCopTaskCacheTTL && entry.isValid(bo, cache) {
+		return cloneBatchCopTasks(entry.tasks)
+	}
+	c.mu.Lock()
+	if v, ok := c.lru.Get(key); ok && v.(*batchCopTaskCacheEntry) == entry {
+		c.lru.Delete(key)
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+// put caches a copy of the layout of the key.
+func (c *batchCopTaskCache) put(key batchCopTaskCacheKey, tasks []*batchCopTask, regionAddrs map[tikv.RegionVerID]string, now time.Time) {
+	entry := &batchCopTaskCacheEntry{
+		tasks:       cloneBatchCopTasks(tasks),
+		regionAddrs: regionAddrs,
+		createTime:  now,
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru == nil {
+		c.lru = kvcache.NewSimpleLRUCache(batchCopTaskCacheCapacity, 0, 0)
+	}
+	c.lru.Put(key, entry)
+}
+
+// isValid checks whether every region of the layout is still resolved to the same TiFlash peer. The
+// region cache drops the old version of a region once its epoch changes, so the layout of a region
+// split, merged or moved is never reused.
+func (e *batchCopTaskCacheEntry) isValid(bo *backoff.Backoffer, cache *RegionCache) bool {
+	for _, task := range e.tasks {
+		for _, ri := range task.regionInfos {
+			rpcCtx, err := cache.GetTiFlashRPCContext(bo.TiKVBackoffer(), ri.Region, false)
+			if err != nil || rpcCtx == nil || rpcCtx.Addr != e.regionAddrs[ri.Region] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// cloneBatchCopTasks copies the tasks and their region lists, the regions themselves are shared.
+func cloneBatchCopTasks(tasks []*batchCopTask) []*batchCopTask {
+	cloned := make([]*batchCopTask, 0, len(tasks))
+	for _, task := range tasks {
+		cloned = append(cloned, &batchCopTask{
+			storeAddr:   task.storeAddr,
+			cmdType:     task.cmdType,
+			ctx:         task.ctx,
+			regionInfos: append([]RegionInfo(nil), task.regionInfos...),
+		})
+	}
+	return cloned
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/driver/backoff"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchCopTaskCache(t *testing.T) {
+	// nil --- 'g' --- 'n' --- nil
+	// <-  0  -> <- 1 -> <- 2 ->
+	mock := newBatchCopTestStore(t, 3, 1, nil)
+	store := &kvStore{store: mock.KVStore}
+	bo := backoff.NewBackofferWithVars(context.Background(), 3000, nil)
+
+	tasks, err := buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{useTaskCache: true})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.Len(t, tasks[0].regionInfos, 3)
+
+	// The same ranges reuse the layout, but get their own tasks.
+	cached, err := buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{useTaskCache: true})
+	require.NoError(t, err)
+	require.Len(t, cached, 1)
+	require.NotSame(t, tasks[0], cached[0])
+	require.Equal(t, tasks[0].storeAddr, cached[0].storeAddr)
+	require.Equal(t, tasks[0].regionInfos, cached[0].regionInfos)
+	cached[0].regionInfos = cached[0].regionInfos[:1]
+	cached, err = buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{useTaskCache: true})
+	require.NoError(t, err)
+	require.Len(t, cached[0].regionInfos, 3)
+
+	// The other ranges or options don't share the layout.
+	key := newBatchCopTaskCacheKey(buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, false, 0, "", nil)
+	require.NotEqual(t, key, newBatchCopTaskCacheKey(buildCopRanges("a", "c", "h", "j", "o", "p"), kv.TiFlash, false, 0, "", nil))
+	require.NotEqual(t, key, newBatchCopTaskCacheKey(buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, true, 20, "", nil))
+	require.NotEqual(t, key, newBatchCopTaskCacheKey(buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, false, 0, "z1", nil))
+
+	// The layout is built again once the epoch of a region changes.
+	peers := []uint64{mock.cluster.AllocID(), mock.cluster.AllocID()}
+	mock.cluster.Split(mock.regionIDs[1], mock.cluster.AllocID(), []byte("k"), peers, peers[0])
+	store.GetRegionCache().InvalidateCachedRegion(tasks[0].regionInfos[1].Region)
+	rebuilt, err := buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{useTaskCache: true})
+	require.NoError(t, err)
+	require.Len(t, rebuilt, 1)
+	require.Len(t, rebuilt[0].regionInfos, 3)
+	for _, ri := range rebuilt[0].regionInfos {
+		if ri.Region.GetID() == mock.regionIDs[1] {
+			require.Greater(t, ri.Region.GetVer(), tasks[0].regionInfos[1].Region.GetVer())
+		}
+	}
+
+	// The expired layout is not reused.
+	key = newBatchCopTaskCacheKey(buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, false, 0, "", nil)
+	cache := store.GetRegionCache()
+	require.NotNil(t, store.batchCopTasks.get(bo, cache, key, time.Now()))
+	require.Nil(t, store.batchCopTasks.get(bo, cache, key, time.Now().Add(batchCopTaskCacheTTL)))
+	require.Nil(t, store.batchCopTasks.get(bo, cache, key, time.Now()))
+}
+
+func BenchmarkBuildBatchCopTasks(b *testing.B) {
+	for _, useTaskCache := range []bool{false, true} {
+		b.Run(fmt.Sprintf("useTaskCache=%v", useTaskCache), func(b *testing.B) {
+			mock := newBatchCopTestStore(b, 4, 3, nil)
+			store := &kvStore{store: mock.KVStore}
+			bo := backoff.NewBackofferWithVars(context.Background(), 3000, nil)
+			opts := batchCopTaskOptions{useTaskCache: useTaskCache}
+			// Load the regions into the region cache, and the layout into the task cache if it's used.
+			_, err := buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p", "u", "v"), kv.TiFlash, opts)
+			require.NoError(b, err)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, err = buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p", "u", "v"), kv.TiFlash, opts)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
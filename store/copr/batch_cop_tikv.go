@@ -0,0 +1,178 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/coprocessor"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/driver/backoff"
+	"github.com/pingcap/tidb/store/driver/options"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/tikv/client-go/v2/tikv"
+	"github.com/tikv/client-go/v2/tikvrpc"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// batchCopUnsupportedTTL is how long a TiKV store replying the batch cop requests are not implemented is not sent
+// the batch cop requests, so the store is used again after it's upgraded.
+const batchCopUnsupportedTTL = 10 * time.Minute
+
+// batchCopSupport remembers the TiKV stores which don't support the batch cop requests, the regions of them are
+// read by the coprocessor requests instead.
+type batchCopSupport struct {
+	// unsupported maps the address of a store to the time it's found not supporting the batch cop requests.
+	unsupported sync.Map
+}
+
+func (s *batchCopSupport) markUnsupported(addr string, now time.Time) {
+	s.unsupported.Store(addr, now)
+}
+
+func (s *batchCopSupport) isUnsupported(addr string, now time.Time) bool {
+	value, ok := s.unsupported.Load(addr)
+	if !ok {
+		return false
+	}
+	if now.Sub(value.(time.Time)) < batchCopUnsupportedTTL {
+		return true
+	}
+	s.unsupported.Delete(addr)
+	return false
+}
+
+// isBatchCopUnsupportedErr checks whether the error is replied by a store not implementing the batch cop requests.
+func isBatchCopUnsupportedErr(err error) bool {
+	return status.Code(errors.Cause(err)) == codes.Unimplemented
+}
+
+// useTiKVBatchCop checks whether the request to TiKV can be sent by the batch cop requests, whose responses are
+// not ordered by the regions.
+func useTiKVBatchCop(req *kv.Request) bool {
+	return req.StoreType == kv.TiKV && req.BatchCop && !req.KeepOrder && !req.Desc && !req.Streaming && !req.Paging
+}
+
+// buildTiKVBatchCopTasks groups the regions of the ranges by the TiKV stores they are read from. Unlike TiFlash,
+// the regions are read from the peers chosen by the replica read type, so they are not balanced between the stores.
+func buildTiKVBatchCopTasks(bo *backoff.Backoffer, store *kvStore, ranges *KeyRanges, req *kv.Request, replicaReadSeed uint32) ([]*batchCopTask, error) {
+	cache := store.GetRegionCache()
+	var opts []tikv.StoreSelectorOption
+	if len(req.MatchStoreLabels) > 0 {
+		opts = append(opts, tikv.WithMatchLabels(req.MatchStoreLabels))
+	}
+	for {
+		locations, err := cache.SplitKeyRangesByLocations(bo, ranges)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		var (
+			tasks        []*batchCopTask
+			storeTaskMap = make(map[string]*batchCopTask)
+			needRetry    bool
+			now          = time.Now()
+		)
+		for _, lo := range locations {
+			rpcCtx, err := cache.GetTiKVRPCContext(bo.TiKVBackoffer(), lo.Location.Region, options.GetTiKVReplicaReadType(req.ReplicaRead), replicaReadSeed, opts...)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if rpcCtx == nil {
+				// The region is invalidated, the ranges are split by the region cache again.
+				needRetry = true
+				break
+			}
+			regionInfo := RegionInfo{Region: lo.Location.Region, Meta: rpcCtx.Meta, Ranges: lo.Ranges, AllStores: []uint64{rpcCtx.Store.StoreID()}}
+			if task, ok := storeTaskMap[rpcCtx.Addr]; ok {
+				task.regionInfos = append(task.regionInfos, regionInfo)
+				continue
+			}
+			task := &batchCopTask{
+				storeAddr:     rpcCtx.Addr,
+				cmdType:       tikvrpc.CmdBatchCop,
+				ctx:           rpcCtx,
+				regionInfos:   []RegionInfo{regionInfo},
+				fallbackToCop: store.batchCopSupport.isUnsupported(rpcCtx.Addr, now),
+			}
+			storeTaskMap[rpcCtx.Addr] = task
+			tasks = append(tasks, task)
+		}
+		if !needRetry {
+			return tasks, nil
+		}
+		err = bo.Backoff(tikv.BoRegionMiss(), errors.New("cannot find the TiKV peers of the regions for batch cop"))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		refreshRegions(bo, cache, ranges)
+	}
+}
+
+// handleTaskByCop reads the regions of the task by the coprocessor requests, it's used if the TiKV store of the
+// task doesn't support the batch cop requests, or the regions of a TiFlash request have no available TiFlash peer.
+func (b *batchCopIterator) handleTaskByCop(ctx context.Context, task *batchCopTask) error {
+	req := *b.req
+	req.BatchCop = false
+	if req.StoreType == kv.TiFlash {
+		// The DAG request of TiFlash is a tree of the executors, which TiKV doesn't support.
+		req.StoreType = kv.TiKV
+		req.Data = req.TiKVFallbackData
+	}
+	req.KeyRanges = nil
+	for _, ri := range task.regionInfos {
+		ri.Ranges.Do(func(ran *kv.KeyRange) {
+			req.KeyRanges = append(req.KeyRanges, *ran)
+		})
+	}
+	sort.Slice(req.KeyRanges, func(i, j int) bool {
+		return bytes.Compare(req.KeyRanges[i].StartKey, req.KeyRanges[j].StartKey) < 0
+	})
+	resp := b.copClient.Send(ctx, &req, b.vars, &kv.ClientSendOption{EnableCollectExecutionInfo: b.enableCollectExecutionInfo})
+	defer func() {
+		if err := resp.Close(); err != nil {
+			logutil.BgLogger().Warn("close the coprocessor response failed", zap.Error(err))
+		}
+	}()
+	for {
+		subset, err := resp.Next(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if subset == nil {
+			return nil
+		}
+		copResp := subset.(*copResponse)
+		batchResp := &batchCopResponse{
+			pbResp: &coprocessor.BatchResponse{
+				Data:        copResp.GetData(),
+				ExecDetails: copResp.pbResp.GetExecDetails(),
+			},
+			detail: copResp.detail,
+		}
+		if batchResp.detail == nil {
+			batchResp.detail = new(CopRuntimeStats)
+		}
+		if b.sendToRespCh(batchResp) {
+			return nil
+		}
+	}
+}
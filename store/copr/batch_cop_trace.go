@@ -0,0 +1,67 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// The span names and tags of the batch cop requests, the spans are the children of the span of the query, so the
+// traces show how long the tasks are built, sent to the stores and read.
+const (
+	batchCopBuildTasksSpan = "copr.buildBatchCopTasks"
+	batchCopTaskSpan       = "copr.batchCopTask"
+	batchCopStreamSpan     = "copr.batchCopStream"
+
+	batchCopSpanTagStoreAddr     = "store_addr"
+	batchCopSpanTagRangeCount    = "range_count"
+	batchCopSpanTagRegionCount   = "region_count"
+	batchCopSpanTagTaskCount     = "task_count"
+	batchCopSpanTagRespCount     = "response_count"
+	batchCopSpanTagBytes         = "bytes"
+	batchCopSpanTagFallbackToCop = "fallback_to_cop"
+	batchCopSpanTagError         = "error"
+)
+
+// startBatchCopSpan starts a span as the child of the span in ctx, and returns the context with the new span.
+// The span is nil if the query isn't traced, so nothing is recorded.
+func startBatchCopSpan(ctx context.Context, operationName string) (opentracing.Span, context.Context) {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil || span.Tracer() == nil {
+		return nil, ctx
+	}
+	span1 := span.Tracer().StartSpan(operationName, opentracing.ChildOf(span.Context()))
+	return span1, opentracing.ContextWithSpan(ctx, span1)
+}
+
+// finishBatchCopSpan records the error on the span if any, and finishes it.
+func finishBatchCopSpan(span opentracing.Span, err error) {
+	if err != nil {
+		span.SetTag(batchCopSpanTagError, true)
+		span.LogKV(batchCopSpanTagError, err.Error())
+	}
+	span.Finish()
+}
+
+// batchCopTasksRegionCount returns the number of the regions read by the tasks.
+func batchCopTasksRegionCount(tasks []*batchCopTask) int {
+	count := 0
+	for _, task := range tasks {
+		count += len(task.regionInfos)
+	}
+	return count
+}
@@ -21,7 +21,6 @@ import (
 	"io"
 	"math"
 	"sort"
-	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -33,16 +32,27 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/mpp"
 	"github.com/pingcap/log"
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/ddl/placement"
 	"github.com/pingcap/tidb/kv"
+	tidbmetrics "github.com/pingcap/tidb/metrics"
 	"github.com/pingcap/tidb/store/driver/backoff"
 	derr "github.com/pingcap/tidb/store/driver/error"
 	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/tikv/client-go/v2/metrics"
 	"github.com/tikv/client-go/v2/tikv"
 	"github.com/tikv/client-go/v2/tikvrpc"
+	"github.com/tikv/client-go/v2/util"
 	"go.uber.org/zap"
 )
 
+var (
+	batchCopRetryCounterRegionMissing = tidbmetrics.DistSQLBatchCopRetryCounter.WithLabelValues("region_missing")
+	batchCopRetryCounterSendFail      = tidbmetrics.DistSQLBatchCopRetryCounter.WithLabelValues("send_fail")
+	batchCopRetryCounterStreamError   = tidbmetrics.DistSQLBatchCopRetryCounter.WithLabelValues("stream_error")
+)
+
 // batchCopTask comprises of multiple copTask that will send to same store.
 type batchCopTask struct {
 	storeAddr string
@@ -50,13 +60,28 @@ type batchCopTask struct {
 	ctx       *tikv.RPCContext
 
 	regionInfos []RegionInfo
+	// reconnect is whether the task is rebuilt after the stream of the previous task of its regions breaks.
+	reconnect bool
+	// fallbackToCop is whether the regions are read by the coprocessor requests, since the TiKV store of the task
+	// doesn't support the batch cop requests.
+	fallbackToCop bool
+	// taskID is the task ID sent in the requests of the task, it's allocated when the task is sent at first.
+	taskID uint64
+}
+
+func (task *batchCopTask) getTaskID(req *kv.Request) uint64 {
+	if task.taskID == 0 {
+		task.taskID = allocCopTaskID(req)
+	}
+	return task.taskID
 }
 
 type batchCopResponse struct {
 	pbResp *coprocessor.BatchResponse
 	detail *CopRuntimeStats
 
-	// batch Cop Response is yet to return startKey. So batchCop cannot retry partially.
+	// The batch cop responses don't return the start key, so it's always nil. A failed task is retried by its
+	// regions instead, see retryBatchCopTask, and a stream broken after returning data can't be resumed.
 	startKey kv.Key
 	err      error
 	respSize int64
@@ -73,8 +98,8 @@ func (rs *batchCopResponse) GetStartKey() kv.Key {
 	return rs.startKey
 }
 
-// GetExecDetails is unavailable currently, because TiFlash has not collected exec details for batch cop.
-// TODO: Will fix in near future.
+// GetCopRuntimeStats returns the runtime stats of the batch cop task, the scan and time details
+// are the ones TiFlash returns for all the regions of the task.
 func (rs *batchCopResponse) GetCopRuntimeStats() *CopRuntimeStats {
 	return rs.detail
 }
@@ -290,102 +315,126 @@ func balanceBatchCopTaskWithContinuity(storeTaskMap map[uint64]*batchCopTask, ca
 //
 // The second balance strategy: Not only consider the region count between TiFlash stores, but also try to make the regions' range continuous(stored in TiFlash closely).
 // If balanceWithContinuity is true, the second balance strategy is enable.
-func balanceBatchCopTask(ctx context.Context, kvStore *kvStore, originalTasks []*batchCopTask, mppStoreLastFailTime map[string]time.Time, ttl time.Duration, balanceWithContinuity bool, balanceContinuousRegionCount int64) []*batchCopTask {
+//
+// If preferredZone is not empty, the regions that have replicas in the TiFlash stores of the zone are only balanced between these stores.
+//
+// The regions that have replicas in the TiFlash stores that are alive and not going offline in PD are not put into
+// the unavailable ones, neither is the first region of the original task.
+//
+// The regions that have replicas in the healthy stores are not put into the stores meeting many errors recently, and
+// the region counts of the stores are scaled by their recent load when balancing.
+//
+// The regions are weighted by their approximate sizes and keys in PD when balancing, so a store taking many large
+// regions isn't regarded as balanced with a store taking as many tiny ones.
+func balanceBatchCopTask(ctx context.Context, kvStore *kvStore, originalTasks []*batchCopTask, mppStoreLastFailTime map[string]time.Time, ttl time.Duration, balanceWithContinuity bool, balanceContinuousRegionCount int64, preferredZone string) []*batchCopTask {
 	if len(originalTasks) == 0 {
 		log.Info("Batch cop task balancer got an empty task set.")
 		return originalTasks
 	}
 	isMPP := mppStoreLastFailTime != nil
+	// The first region of each original task stays in its store, unless the tasks prefer a zone and the store may
+	// be in another one.
+	keepFirstRegion := !isMPP && preferredZone == ""
+	cache := kvStore.GetRegionCache()
+	unavailableStores := unavailableTiFlashStores(kvStore, cache.RegionCache.GetTiFlashStores())
+	var allRegions []RegionInfo
+	for _, task := range originalTasks {
+		allRegions = append(allRegions, task.regionInfos...)
+	}
+	kvStore.regionStats.request(allRegions, time.Now())
+	regionWeights := kvStore.regionStats.weights(allRegions, time.Now())
+	// The first region is moved away if the store is going offline or down.
+	keepTaskFirstRegion := func(task *batchCopTask) bool {
+		_, unavailable := unavailableStores[task.regionInfos[0].AllStores[0]]
+		return keepFirstRegion && !unavailable
+	}
 	// for mpp, we still need to detect the store availability
-	if len(originalTasks) <= 1 && !isMPP {
+	if len(originalTasks) <= 1 && keepTaskFirstRegion(originalTasks[0]) {
 		return originalTasks
 	}
-	cache := kvStore.GetRegionCache()
 	storeTaskMap := make(map[uint64]*batchCopTask)
 	// storeCandidateRegionMap stores all the possible store->region map. Its content is
 	// store id -> region signature -> region info. We can see it as store id -> region lists.
 	storeCandidateRegionMap := make(map[uint64]map[string]RegionInfo)
 	totalRegionCandidateNum := 0
 	totalRemainingRegionNum := 0
+	// storeCandidateWeights and storeTaskWeights are the total weights of the candidate regions and the regions
+	// put into the tasks of the stores.
+	storeCandidateWeights := make(map[uint64]float64)
+	storeTaskWeights := make(map[uint64]float64)
 
 	if !isMPP {
+		hasUnavailableStore := false
 		for _, task := range originalTasks {
 			taskStoreID := task.regionInfos[0].AllStores[0]
 			batchTask := &batchCopTask{
-				storeAddr:   task.storeAddr,
-				cmdType:     task.cmdType,
-				ctx:         task.ctx,
-				regionInfos: []RegionInfo{task.regionInfos[0]},
+				storeAddr: task.storeAddr,
+				cmdType:   task.cmdType,
+				ctx:       task.ctx,
+			}
+			if keepTaskFirstRegion(task) {
+				batchTask.regionInfos = []RegionInfo{task.regionInfos[0]}
+				storeTaskWeights[taskStoreID] += regionWeights[task.regionInfos[0].Region.GetID()]
+			}
+			if _, ok := unavailableStores[taskStoreID]; ok {
+				hasUnavailableStore = true
 			}
 			storeTaskMap[taskStoreID] = batchTask
 		}
-	} else {
-		logutil.BgLogger().Info("detecting available mpp stores")
-		// decide the available stores
-		stores := cache.RegionCache.GetTiFlashStores()
-		var wg sync.WaitGroup
-		var mu sync.Mutex
-		wg.Add(len(stores))
-		cur := time.Now()
-		for i := range stores {
-			go func(idx int) {
-				defer wg.Done()
-				s := stores[idx]
-
-				var last time.Time
-				var ok bool
-				mu.Lock()
-				if last, ok = mppStoreLastFailTime[s.GetAddr()]; ok && cur.Sub(last) < 100*time.Millisecond {
-					// The interval time is so short that may happen in a same query, so we needn't to check again.
-					mu.Unlock()
-					return
+		// The regions of the unavailable stores can be moved to any other TiFlash store.
+		if hasUnavailableStore {
+			for _, s := range cache.RegionCache.GetTiFlashStores() {
+				if _, unavailable := unavailableStores[s.StoreID()]; unavailable {
+					continue
 				}
-				mu.Unlock()
-
-				resp, err := kvStore.GetTiKVClient().SendRequest(ctx, s.GetAddr(), &tikvrpc.Request{
-					Type:    tikvrpc.CmdMPPAlive,
-					StoreTp: tikvrpc.TiFlash,
-					Req:     &mpp.IsAliveRequest{},
-					Context: kvrpcpb.Context{},
-				}, 2*time.Second)
-
-				if err != nil || !resp.Resp.(*mpp.IsAliveResponse).Available {
-					errMsg := "store not ready to serve"
-					if err != nil {
-						errMsg = err.Error()
+				if _, ok := storeTaskMap[s.StoreID()]; !ok {
+					storeTaskMap[s.StoreID()] = &batchCopTask{
+						storeAddr: s.GetAddr(),
+						cmdType:   originalTasks[0].cmdType,
+						ctx:       &tikv.RPCContext{Addr: s.GetAddr(), Store: s},
 					}
-					logutil.BgLogger().Warn("Store is not ready", zap.String("store address", s.GetAddr()), zap.String("err message", errMsg))
-					mu.Lock()
-					mppStoreLastFailTime[s.GetAddr()] = time.Now()
-					mu.Unlock()
-					return
-				}
-
-				if cur.Sub(last) < ttl {
-					logutil.BgLogger().Warn("Cannot detect store's availability because the current time has not reached MPPStoreLastFailTime + MPPStoreFailTTL", zap.String("store address", s.GetAddr()), zap.Time("last fail time", last))
-					return
 				}
+			}
+		}
+	} else {
+		storeTaskMap = detectMPPStores(ctx, kvStore, cache.RegionCache.GetTiFlashStores(), originalTasks[0].cmdType, mppStoreLastFailTime, ttl)
+	}
 
-				mu.Lock()
-				defer mu.Unlock()
+	var zoneStores map[uint64]struct{}
+	if preferredZone != "" {
+		zoneStores = make(map[uint64]struct{})
+		zoneLabels := []*metapb.StoreLabel{{Key: placement.DCLabelKey, Value: preferredZone}}
+		for _, s := range cache.RegionCache.GetTiFlashStores() {
+			if !s.IsLabelsMatch(zoneLabels) {
+				continue
+			}
+			zoneStores[s.StoreID()] = struct{}{}
+			// The batch cop tasks are only sent to the stores of the original tasks, add the stores in the zone too.
+			if _, ok := storeTaskMap[s.StoreID()]; !ok && !isMPP {
 				storeTaskMap[s.StoreID()] = &batchCopTask{
 					storeAddr: s.GetAddr(),
 					cmdType:   originalTasks[0].cmdType,
 					ctx:       &tikv.RPCContext{Addr: s.GetAddr(), Store: s},
 				}
-			}(i)
+			}
 		}
-		wg.Wait()
 	}
 
+	now := time.Now()
 	var candidateRegionInfos []RegionInfo
 	for _, task := range originalTasks {
+		keepTaskFirst := keepTaskFirstRegion(task)
 		for index, ri := range task.regionInfos {
 			// for each region, figure out the valid store num
 			validStoreNum := 0
-			if index == 0 && !isMPP {
+			if index == 0 && keepTaskFirst {
 				continue
 			}
+			ri.AllStores = preferAvailableStores(ri.AllStores, unavailableStores)
+			if len(zoneStores) > 0 {
+				ri.AllStores = preferZoneStores(ri.AllStores, zoneStores, storeTaskMap)
+			}
+			ri.AllStores = preferHealthyStores(ri.AllStores, storeTaskMap, &kvStore.health, now)
 			var validStoreID uint64
 			for _, storeID := range ri.AllStores {
 				if _, ok := storeTaskMap[storeID]; ok {
@@ -400,6 +449,7 @@ func balanceBatchCopTask(ctx context.Context, kvStore *kvStore, originalTasks []
 			} else if validStoreNum == 1 {
 				// if only one store is valid, just put it to storeTaskMap
 				storeTaskMap[validStoreID].regionInfos = append(storeTaskMap[validStoreID].regionInfos, ri)
+				storeTaskWeights[validStoreID] += regionWeights[ri.Region.GetID()]
 			} else {
 				// if more than one store is valid, put the region
 				// to store candidate map
@@ -421,6 +471,7 @@ func balanceBatchCopTask(ctx context.Context, kvStore *kvStore, originalTasks []
 						return originalTasks
 					}
 					storeCandidateRegionMap[storeID][taskKey] = ri
+					storeCandidateWeights[storeID] += regionWeights[ri.Region.GetID()]
 				}
 			}
 		}
@@ -438,6 +489,11 @@ func balanceBatchCopTask(ctx context.Context, kvStore *kvStore, originalTasks []
 	}
 
 	if totalRemainingRegionNum > 0 {
+		// The busy or unhealthy stores are deprioritized by scaling their region weights.
+		loadFactors := make(map[uint64]float64, len(storeTaskMap))
+		for storeID, task := range storeTaskMap {
+			loadFactors[storeID] = kvStore.health.loadFactor(task.storeAddr, now)
+		}
 		avgStorePerRegion := float64(totalRegionCandidateNum) / float64(totalRemainingRegionNum)
 		findNextStore := func(candidateStores []uint64) uint64 {
 			store := uint64(math.MaxUint64)
@@ -447,7 +503,7 @@ func balanceBatchCopTask(ctx context.Context, kvStore *kvStore, originalTasks []
 					if _, validStore := storeCandidateRegionMap[storeID]; !validStore {
 						continue
 					}
-					num := float64(len(storeCandidateRegionMap[storeID]))/avgStorePerRegion + float64(len(storeTaskMap[storeID].regionInfos))
+					num := (storeCandidateWeights[storeID]/avgStorePerRegion + storeTaskWeights[storeID]) * loadFactors[storeID]
 					if num < weightedRegionNum {
 						store = storeID
 						weightedRegionNum = num
@@ -461,7 +517,7 @@ func balanceBatchCopTask(ctx context.Context, kvStore *kvStore, originalTasks []
 				if _, validStore := storeCandidateRegionMap[storeID]; !validStore {
 					continue
 				}
-				num := float64(len(storeCandidateRegionMap[storeID]))/avgStorePerRegion + float64(len(storeTaskMap[storeID].regionInfos))
+				num := (storeCandidateWeights[storeID]/avgStorePerRegion + storeTaskWeights[storeID]) * loadFactors[storeID]
 				if num < weightedRegionNum {
 					store = storeID
 					weightedRegionNum = num
@@ -482,9 +538,13 @@ func balanceBatchCopTask(ctx context.Context, kvStore *kvStore, originalTasks []
 				break
 			}
 			storeTaskMap[store].regionInfos = append(storeTaskMap[store].regionInfos, ri)
+			storeTaskWeights[store] += regionWeights[ri.Region.GetID()]
 			totalRemainingRegionNum--
 			for _, id := range ri.AllStores {
 				if _, ok := storeCandidateRegionMap[id]; ok {
+					if _, ok := storeCandidateRegionMap[id][key]; ok {
+						storeCandidateWeights[id] -= regionWeights[ri.Region.GetID()]
+					}
 					delete(storeCandidateRegionMap[id], key)
 					totalRegionCandidateNum--
 					if len(storeCandidateRegionMap[id]) == 0 {
@@ -520,14 +580,344 @@ func balanceBatchCopTask(ctx context.Context, kvStore *kvStore, originalTasks []
 			ret = append(ret, task)
 		}
 	}
+	observeBatchCopBalanceSkew(ret)
 	return ret
 }
 
-func buildBatchCopTasks(bo *backoff.Backoffer, store *kvStore, ranges *KeyRanges, storeType kv.StoreType, mppStoreLastFailTime map[string]time.Time, ttl time.Duration, balanceWithContinuity bool, balanceContinuousRegionCount int64) ([]*batchCopTask, error) {
+// observeBatchCopBalanceSkew records the ratio of the most regions of a store to the average regions of the stores
+// of the balanced tasks.
+func observeBatchCopBalanceSkew(tasks []*batchCopTask) {
+	if len(tasks) == 0 {
+		return
+	}
+	total, most := 0, 0
+	for _, task := range tasks {
+		total += len(task.regionInfos)
+		if len(task.regionInfos) > most {
+			most = len(task.regionInfos)
+		}
+	}
+	tidbmetrics.DistSQLBatchCopBalanceSkewHistogram.Observe(float64(most) * float64(len(tasks)) / float64(total))
+}
+
+// preferZoneStores returns the available stores of the region in the preferred zone, all the stores are returned
+// if there is none.
+func preferZoneStores(allStores []uint64, zoneStores map[uint64]struct{}, storeTaskMap map[uint64]*batchCopTask) []uint64 {
+	var stores []uint64
+	for _, storeID := range allStores {
+		if _, ok := zoneStores[storeID]; !ok {
+			continue
+		}
+		if _, ok := storeTaskMap[storeID]; ok {
+			stores = append(stores, storeID)
+		}
+	}
+	if len(stores) == 0 {
+		return allStores
+	}
+	return stores
+}
+
+// runTiFlashStoreProber probes the TiFlash stores every storeProbeInterval until ctx is done. The batch cop tasks
+// read the results of the last probes, so they are not delayed by the stores that don't respond.
+func (s *kvStore) runTiFlashStoreProber(ctx context.Context) {
+	ticker := time.NewTicker(storeProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.probeTiFlashStores(ctx)
+		}
+	}
+}
+
+// probeTiFlashStores checks whether the TiFlash stores in the region cache are alive, so the regions are not
+// assigned to the stores that are down but still in the region cache, whose requests only fail after a long timeout.
+func (s *kvStore) probeTiFlashStores(ctx context.Context) {
+	addrs := tiflashStoreAddrs(s.GetRegionCache().RegionCache.GetTiFlashStores())
+	var wg sync.WaitGroup
+	wg.Add(len(addrs))
+	for _, addr := range addrs {
+		go func(addr string) {
+			defer wg.Done()
+			resp, err := s.GetTiKVClient().SendRequest(ctx, addr, &tikvrpc.Request{
+				Type:    tikvrpc.CmdMPPAlive,
+				StoreTp: tikvrpc.TiFlash,
+				Req:     &mpp.IsAliveRequest{},
+				Context: kvrpcpb.Context{},
+			}, 2*time.Second)
+			alive := err == nil && resp.Resp.(*mpp.IsAliveResponse).Available
+			if !alive {
+				errMsg := "store not ready to serve"
+				if err != nil {
+					errMsg = err.Error()
+				}
+				logutil.BgLogger().Warn("Store is not ready", zap.String("store address", addr), zap.String("err message", errMsg))
+			}
+			s.health.onProbe(addr, alive)
+		}(addr)
+	}
+	wg.Wait()
+}
+
+// detectMPPStores returns the tasks of the stores that are ready to serve the MPP tasks. The stores failed recently,
+// whose last fail time is recorded in mppStoreLastFailTime, are not used until the ttl passes.
+func detectMPPStores(ctx context.Context, kvStore *kvStore, stores []*tikv.Store, cmdType tikvrpc.CmdType, mppStoreLastFailTime map[string]time.Time, ttl time.Duration) map[uint64]*batchCopTask {
+	storeTaskMap := make(map[uint64]*batchCopTask)
+	logutil.BgLogger().Info("detecting available mpp stores")
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	wg.Add(len(stores))
+	cur := time.Now()
+	for i := range stores {
+		go func(idx int) {
+			defer wg.Done()
+			s := stores[idx]
+
+			var last time.Time
+			var ok bool
+			mu.Lock()
+			if last, ok = mppStoreLastFailTime[s.GetAddr()]; ok && cur.Sub(last) < 100*time.Millisecond {
+				// The interval time is so short that may happen in a same query, so we needn't to check again.
+				mu.Unlock()
+				return
+			}
+			mu.Unlock()
+
+			resp, err := kvStore.GetTiKVClient().SendRequest(ctx, s.GetAddr(), &tikvrpc.Request{
+				Type:    tikvrpc.CmdMPPAlive,
+				StoreTp: tikvrpc.TiFlash,
+				Req:     &mpp.IsAliveRequest{},
+				Context: kvrpcpb.Context{},
+			}, 2*time.Second)
+
+			if err != nil || !resp.Resp.(*mpp.IsAliveResponse).Available {
+				errMsg := "store not ready to serve"
+				if err != nil {
+					errMsg = err.Error()
+				}
+				logutil.BgLogger().Warn("Store is not ready", zap.String("store address", s.GetAddr()), zap.String("err message", errMsg))
+				mu.Lock()
+				mppStoreLastFailTime[s.GetAddr()] = time.Now()
+				mu.Unlock()
+				return
+			}
+
+			if cur.Sub(last) < ttl {
+				logutil.BgLogger().Warn("Cannot detect store's availability because the current time has not reached MPPStoreLastFailTime + MPPStoreFailTTL", zap.String("store address", s.GetAddr()), zap.Time("last fail time", last))
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			storeTaskMap[s.StoreID()] = &batchCopTask{
+				storeAddr: s.GetAddr(),
+				cmdType:   cmdType,
+				ctx:       &tikv.RPCContext{Addr: s.GetAddr(), Store: s},
+			}
+		}(i)
+	}
+	wg.Wait()
+	return storeTaskMap
+}
+
+func tiflashStoreAddrs(stores []*tikv.Store) []string {
+	addrs := make([]string, 0, len(stores))
+	for _, s := range stores {
+		addrs = append(addrs, s.GetAddr())
+	}
+	return addrs
+}
+
+// hasUnavailableStore returns whether any task is sent to a store that is being drained in PD, didn't respond
+// the last probe or keeps timing out.
+func hasUnavailableStore(kvStore *kvStore, tasks []*batchCopTask) bool {
+	now := time.Now()
+	for _, task := range tasks {
+		if task.ctx != nil && task.ctx.Store != nil && !kvStore.pdStores.isAvailable(task.ctx.Store.StoreID()) {
+			return true
+		}
+		if kvStore.health.isDown(task.storeAddr) || kvStore.health.isBroken(task.storeAddr, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// unavailableTiFlashStores returns the IDs of the stores that are being drained in PD, didn't respond the probe
+// or keep timing out.
+func unavailableTiFlashStores(kvStore *kvStore, stores []*tikv.Store) map[uint64]struct{} {
+	unavailableStores := make(map[uint64]struct{})
+	now := time.Now()
+	for _, s := range stores {
+		if !kvStore.pdStores.isAvailable(s.StoreID()) || kvStore.health.isDown(s.GetAddr()) || kvStore.health.isBroken(s.GetAddr(), now) {
+			unavailableStores[s.StoreID()] = struct{}{}
+		}
+	}
+	return unavailableStores
+}
+
+// preferAvailableStores returns the stores in allStores that are not unavailable, or allStores if there is
+// no such store.
+func preferAvailableStores(allStores []uint64, unavailableStores map[uint64]struct{}) []uint64 {
+	var stores []uint64
+	for _, storeID := range allStores {
+		if _, ok := unavailableStores[storeID]; !ok {
+			stores = append(stores, storeID)
+		}
+	}
+	if len(stores) == 0 {
+		return allStores
+	}
+	return stores
+}
+
+// preferHealthyStores returns the available stores in allStores that don't meet too many errors recently,
+// or allStores if there is no such store.
+func preferHealthyStores(allStores []uint64, storeTaskMap map[uint64]*batchCopTask, health *storeHealthTracker, now time.Time) []uint64 {
+	var stores []uint64
+	for _, storeID := range allStores {
+		task, ok := storeTaskMap[storeID]
+		if !ok || health.isUnhealthy(task.storeAddr, now) {
+			continue
+		}
+		stores = append(stores, storeID)
+	}
+	if len(stores) == 0 {
+		return allStores
+	}
+	return stores
+}
+
+// allowedTiFlashStores returns the TiFlash stores whose addresses are in allowedAddrs, or nil if allowedAddrs is empty,
+// which means all the stores are allowed.
+func allowedTiFlashStores(cache *RegionCache, allowedAddrs []string) map[uint64]*tikv.Store {
+	if len(allowedAddrs) == 0 {
+		return nil
+	}
+	addrs := make(map[string]struct{}, len(allowedAddrs))
+	for _, addr := range allowedAddrs {
+		addrs[addr] = struct{}{}
+	}
+	allowedStores := make(map[uint64]*tikv.Store, len(allowedAddrs))
+	for _, s := range cache.RegionCache.GetTiFlashStores() {
+		if _, ok := addrs[s.GetAddr()]; ok {
+			allowedStores[s.StoreID()] = s
+		}
+	}
+	return allowedStores
+}
+
+// filterAllowedStores returns the stores in allStores that are allowed, allStores is returned if allowedStores is nil.
+func filterAllowedStores(allStores []uint64, allowedStores map[uint64]*tikv.Store) []uint64 {
+	if allowedStores == nil {
+		return allStores
+	}
+	var stores []uint64
+	for _, storeID := range allStores {
+		if _, ok := allowedStores[storeID]; ok {
+			stores = append(stores, storeID)
+		}
+	}
+	return stores
+}
+
+// restrictBatchCopTasks moves the regions of the tasks to the TiFlash stores of allowedAddrs, and removes the other
+// stores from their AllStores. It's called after the regions are located, when their stores are loaded in the
+// region cache.
+func restrictBatchCopTasks(cache *RegionCache, tasks []*batchCopTask, allowedAddrs []string) ([]*batchCopTask, error) {
+	allowedStores := allowedTiFlashStores(cache, allowedAddrs)
+	storeTaskMap := make(map[uint64]*batchCopTask)
+	var restricted []*batchCopTask
+	for _, task := range tasks {
+		for _, ri := range task.regionInfos {
+			ri.AllStores = filterAllowedStores(ri.AllStores, allowedStores)
+			if len(ri.AllStores) == 0 {
+				return nil, errors.Errorf("region %d has no TiFlash replica in the specified nodes %v", ri.Region.GetID(), allowedAddrs)
+			}
+			// AllStores[0] is the store of the task.
+			storeID := ri.AllStores[0]
+			if restrictedTask, ok := storeTaskMap[storeID]; ok {
+				restrictedTask.regionInfos = append(restrictedTask.regionInfos, ri)
+				continue
+			}
+			taskCtx := task.ctx
+			if taskCtx.Store.StoreID() != storeID {
+				s := allowedStores[storeID]
+				taskCtx = &tikv.RPCContext{Addr: s.GetAddr(), Store: s}
+			}
+			restrictedTask := &batchCopTask{
+				storeAddr:   taskCtx.Addr,
+				cmdType:     task.cmdType,
+				ctx:         taskCtx,
+				regionInfos: []RegionInfo{ri},
+			}
+			storeTaskMap[storeID] = restrictedTask
+			restricted = append(restricted, restrictedTask)
+		}
+	}
+	return restricted, nil
+}
+
+// batchCopTaskOptions is the optional inputs of buildBatchCopTasks, the zero value balances the regions by the region
+// count between all the TiFlash stores.
+type batchCopTaskOptions struct {
+	// mppStoreLastFailTime is the last time the MPP stores failed, the stores failed within ttl are not balanced to.
+	// The layout balanced with it is not cached.
+	mppStoreLastFailTime map[string]time.Time
+	ttl                  time.Duration
+	// balanceWithContinuity balances the regions by balanceContinuousRegionCount continuous regions at a time.
+	balanceWithContinuity        bool
+	balanceContinuousRegionCount int64
+	// preferredZone is the zone whose TiFlash stores are preferred, empty means no preference.
+	preferredZone string
+	// allowedAddrs restricts the regions to the TiFlash stores of these addresses if it's not empty, and an error is
+	// returned if any region has no replica in them.
+	allowedAddrs []string
+	// fallbackToTiKV indicates the regions still having no available TiFlash peer after the region cache is reloaded
+	// are put into a task read from TiKV, instead of backing off until the request fails.
+	fallbackToTiKV bool
+	// useTaskCache indicates the layout is reused from the batch cop task cache if it's still valid, and is cached
+	// once it's built.
+	useTaskCache bool
+}
+
+// newBatchCopTaskOptions returns the options of building the batch cop tasks of the request.
+func newBatchCopTaskOptions(req *kv.Request) batchCopTaskOptions {
+	return batchCopTaskOptions{
+		preferredZone:  req.PreferredZone,
+		allowedAddrs:   req.TiFlashReplicaReadNodes,
+		fallbackToTiKV: req.TiKVFallbackData != nil,
+		useTaskCache:   req.BatchCopTaskCache,
+	}
+}
+
+// buildBatchCopTasks groups the regions of the ranges by the TiFlash stores and balances them between the stores.
+func buildBatchCopTasks(bo *backoff.Backoffer, store *kvStore, ranges *KeyRanges, storeType kv.StoreType, opts batchCopTaskOptions) (res []*batchCopTask, err error) {
 	cache := store.GetRegionCache()
 	start := time.Now()
 	const cmdType = tikvrpc.CmdBatchCop
 	rangesLen := ranges.Len()
+	if span, _ := startBatchCopSpan(bo.GetCtx(), batchCopBuildTasksSpan); span != nil {
+		span.SetTag(batchCopSpanTagRangeCount, rangesLen)
+		defer func() {
+			span.SetTag(batchCopSpanTagTaskCount, len(res))
+			span.SetTag(batchCopSpanTagRegionCount, batchCopTasksRegionCount(res))
+			finishBatchCopSpan(span, err)
+		}()
+	}
+	// The layout balanced with the failed MPP stores depends on more than the ranges, so it's not cached.
+	var cacheKey batchCopTaskCacheKey
+	if opts.useTaskCache && opts.mppStoreLastFailTime == nil {
+		cacheKey = newBatchCopTaskCacheKey(ranges, storeType, opts.balanceWithContinuity, opts.balanceContinuousRegionCount, opts.preferredZone, opts.allowedAddrs)
+		if batchTasks := store.batchCopTasks.get(bo, cache, cacheKey, start); batchTasks != nil && !hasUnavailableStore(store, batchTasks) {
+			metrics.TxnRegionsNumHistogramWithBatchCoprocessor.Observe(float64(len(batchTasks)))
+			return batchTasks, nil
+		}
+	}
+	retried := false
 	for {
 		locations, err := cache.SplitKeyRangesByLocations(bo, ranges)
 		if err != nil {
@@ -546,23 +936,34 @@ func buildBatchCopTasks(bo *backoff.Backoffer, store *kvStore, ranges *KeyRanges
 		var batchTasks []*batchCopTask
 
 		storeTaskMap := make(map[string]*batchCopTask)
+		regionAddrs := make(map[tikv.RegionVerID]string, len(tasks))
 		needRetry := false
+		var fallbackRegions []RegionInfo
 		for _, task := range tasks {
 			rpcCtx, err := cache.GetTiFlashRPCContext(bo.TiKVBackoffer(), task.region, false)
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
+			if rpcCtx == nil && opts.fallbackToTiKV && retried {
+				// The region is reloaded but still has no available TiFlash peer, e.g. the replica is not synced yet.
+				fallbackRegions = append(fallbackRegions, RegionInfo{Region: task.region, Ranges: task.ranges})
+				continue
+			}
 			// When rpcCtx is nil, it's not only attributed to the miss region, but also
 			// some TiFlash stores crash and can't be recovered.
 			// That is not an error that can be easily recovered, so we regard this error
 			// same as rpc error.
 			if rpcCtx == nil {
+				if !needRetry {
+					batchCopRetryCounterRegionMissing.Inc()
+				}
 				needRetry = true
 				logutil.BgLogger().Info("retry for TiFlash peer with region missing", zap.Uint64("region id", task.region.GetID()))
 				// Probably all the regions are invalid. Make the loop continue and mark all the regions invalid.
 				// Then `splitRegion` will reloads these regions.
 				continue
 			}
+			regionAddrs[task.region] = rpcCtx.Addr
 			allStores := cache.GetAllValidTiFlashStores(task.region, rpcCtx.Store)
 			if batchCop, ok := storeTaskMap[rpcCtx.Addr]; ok {
 				batchCop.regionInfos = append(batchCop.regionInfos, RegionInfo{Region: task.region, Meta: rpcCtx.Meta, Ranges: task.ranges, AllStores: allStores})
@@ -584,28 +985,33 @@ func buildBatchCopTasks(bo *backoff.Backoffer, store *kvStore, ranges *KeyRanges
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
+			refreshRegions(bo, cache, ranges)
+			retried = true
 			continue
 		}
 
 		for _, task := range storeTaskMap {
 			batchTasks = append(batchTasks, task)
 		}
-		if log.GetLevel() <= zap.DebugLevel {
-			msg := "Before region balance:"
-			for _, task := range batchTasks {
-				msg += " store " + task.storeAddr + ": " + strconv.Itoa(len(task.regionInfos)) + " regions,"
+		if len(opts.allowedAddrs) > 0 {
+			batchTasks, err = restrictBatchCopTasks(cache, batchTasks, opts.allowedAddrs)
+			if err != nil {
+				return nil, err
 			}
-			logutil.BgLogger().Debug(msg)
 		}
+		beforeBalance := newBatchCopStoreRegions(batchTasks)
 		balanceStart := time.Now()
-		batchTasks = balanceBatchCopTask(bo.GetCtx(), store, batchTasks, mppStoreLastFailTime, ttl, balanceWithContinuity, balanceContinuousRegionCount)
+		batchTasks = balanceBatchCopTask(bo.GetCtx(), store, batchTasks, opts.mppStoreLastFailTime, opts.ttl, opts.balanceWithContinuity, opts.balanceContinuousRegionCount, opts.preferredZone)
 		balanceElapsed := time.Since(balanceStart)
-		if log.GetLevel() <= zap.DebugLevel {
-			msg := "After region balance:"
-			for _, task := range batchTasks {
-				msg += " store " + task.storeAddr + ": " + strconv.Itoa(len(task.regionInfos)) + " regions,"
-			}
-			logutil.BgLogger().Debug(msg)
+		store.batchCopBalanceLog.record(beforeBalance, batchTasks, regionAddrs, balanceElapsed, time.Now())
+		if len(fallbackRegions) > 0 {
+			logutil.BgLogger().Info("read the regions without available TiFlash peers from TiKV",
+				zap.Int("region count", len(fallbackRegions)))
+			batchTasks = append(batchTasks, &batchCopTask{
+				cmdType:       cmdType,
+				regionInfos:   fallbackRegions,
+				fallbackToCop: true,
+			})
 		}
 
 		if elapsed := time.Since(start); elapsed > time.Millisecond*500 {
@@ -615,46 +1021,149 @@ func buildBatchCopTasks(bo *backoff.Backoffer, store *kvStore, ranges *KeyRanges
 				zap.Int("range len", rangesLen),
 				zap.Int("task len", len(batchTasks)))
 		}
+		// The regions read from TiKV may have TiFlash peers soon, so the layout isn't cached.
+		if cacheKey != nil && len(fallbackRegions) == 0 {
+			store.batchCopTasks.put(cacheKey, batchTasks, regionAddrs, start)
+		}
 		metrics.TxnRegionsNumHistogramWithBatchCoprocessor.Observe(float64(len(batchTasks)))
 		return batchTasks, nil
 	}
 }
 
+// refreshRegions reloads the regions of the ranges in bulk before the batch cop tasks are built again, otherwise the
+// regions invalidated by the last attempt are reloaded one by one. The regions are still located one by one if it
+// fails.
+func refreshRegions(bo *backoff.Backoffer, cache *RegionCache, ranges *KeyRanges) {
+	start := time.Now()
+	if err := cache.RefreshRegionsInRanges(bo, ranges); err != nil {
+		logutil.BgLogger().Warn("failed to refresh the regions for batch cop", zap.Int("range len", ranges.Len()), zap.Error(err))
+		return
+	}
+	logutil.BgLogger().Info("refresh the regions for batch cop", zap.Int("range len", ranges.Len()), zap.Duration("elapsed", time.Since(start)))
+}
+
+// splitBatchCopTasksBySize splits the batch cop tasks having more than maxRegions regions into the tasks of the
+// same store with maxRegions regions at most.
+func splitBatchCopTasksBySize(tasks []*batchCopTask, maxRegions int) []*batchCopTask {
+	var splitTasks []*batchCopTask
+	for _, task := range tasks {
+		if len(task.regionInfos) <= maxRegions {
+			splitTasks = append(splitTasks, task)
+			continue
+		}
+		for start := 0; start < len(task.regionInfos); start += maxRegions {
+			end := start + maxRegions
+			if end > len(task.regionInfos) {
+				end = len(task.regionInfos)
+			}
+			splitTasks = append(splitTasks, &batchCopTask{
+				storeAddr:     task.storeAddr,
+				cmdType:       task.cmdType,
+				ctx:           task.ctx,
+				regionInfos:   task.regionInfos[start:end:end],
+				fallbackToCop: task.fallbackToCop,
+			})
+		}
+	}
+	return splitTasks
+}
+
 func (c *CopClient) sendBatch(ctx context.Context, req *kv.Request, vars *tikv.Variables, option *kv.ClientSendOption) kv.Response {
 	if req.KeepOrder || req.Desc {
 		return copErrorResponse{errors.New("batch coprocessor cannot prove keep order or desc property")}
 	}
 	ctx = context.WithValue(ctx, tikv.TxnStartKey(), req.StartTs)
-	bo := backoff.NewBackofferWithVars(ctx, copBuildTaskMaxBackoff, vars)
-	ranges := NewKeyRanges(req.KeyRanges)
-	tasks, err := buildBatchCopTasks(bo, c.store.kvStore, ranges, req.StoreType, nil, 0, false, 0)
+	deadline := requestDeadline(req, time.Now())
+	perfCfg := config.GetGlobalConfig().Performance
+	releaseQueue, err := c.store.batchCopQueue.acquire(ctx, req.Priority, int(perfCfg.MaxBatchCopQueries), int(perfCfg.BatchCopQueueSize), vars, deadline)
 	if err != nil {
 		return copErrorResponse{err}
 	}
+	bo := c.store.newBuildTaskBackoffer(ctx, req.StoreType, vars)
+	tasks, err := c.buildBatchCopTasks(bo, req)
+	if err != nil {
+		releaseQueue()
+		return copErrorResponse{err}
+	}
 	it := &batchCopIterator{
 		store:                      c.store.kvStore,
+		copClient:                  c,
+		coprCache:                  c.store.coprCache,
 		req:                        req,
 		finishCh:                   make(chan struct{}),
 		vars:                       vars,
 		rpcCancel:                  tikv.NewRPCanceller(),
+		memTracker:                 req.MemTracker,
+		deadline:                   deadline,
 		enableCollectExecutionInfo: option.EnableCollectExecutionInfo,
+		releaseQueue:               releaseQueue,
 	}
 	ctx = context.WithValue(ctx, tikv.RPCCancellerCtxKey{}, it.rpcCancel)
+	respChanSize := req.BatchCopRespChanSize
+	if respChanSize <= 0 {
+		respChanSize = defaultBatchCopRespChanSize
+	}
+	it.respChan = make(chan *batchCopResponse, respChanSize)
+	if req.BatchCopRespMaxBytes > 0 {
+		it.respMaxBytes = req.BatchCopRespMaxBytes
+		it.respBytesReleased = make(chan struct{}, 1)
+	}
+	if req.BatchCopRespSpill && option.SessionMemTracker != nil {
+		it.spiller = newBatchCopRespSpiller()
+		option.SessionMemTracker.FallbackOldAndSetNewAction(it.spiller)
+	}
+	if req.MaxRegionsPerBatchCopTask > 0 {
+		// The smaller tasks of a store are sent with the concurrency of the request at most.
+		tasks = splitBatchCopTasksBySize(tasks, req.MaxRegionsPerBatchCopTask)
+		it.storeConcurrency = req.Concurrency
+		if it.storeConcurrency < 1 {
+			it.storeConcurrency = 1
+		}
+	}
+	if req.BatchCopConcurrency > 0 && req.BatchCopConcurrency < len(tasks) {
+		it.taskRate = util.NewRateLimit(req.BatchCopConcurrency)
+	}
 	it.tasks = tasks
-	it.respChan = make(chan *batchCopResponse, 2048)
 	go it.run(ctx)
 	return it
 }
 
+// buildBatchCopTasks builds the batch cop tasks of the request by the stores the regions are read from.
+func (c *CopClient) buildBatchCopTasks(bo *backoff.Backoffer, req *kv.Request) ([]*batchCopTask, error) {
+	ranges := NewKeyRanges(req.KeyRanges)
+	if req.StoreType == kv.TiKV {
+		return buildTiKVBatchCopTasks(bo, c.store.kvStore, ranges, req, c.replicaReadSeed)
+	}
+	return buildBatchCopTasks(bo, c.store.kvStore, ranges, req.StoreType, newBatchCopTaskOptions(req))
+}
+
+const defaultBatchCopRespChanSize = 2048
+
 type batchCopIterator struct {
-	store    *kvStore
-	req      *kv.Request
-	finishCh chan struct{}
+	store *kvStore
+	// copClient reads the regions of the TiKV stores not supporting the batch cop requests.
+	copClient *CopClient
+	req       *kv.Request
+	finishCh  chan struct{}
+	// coprCache caches the responses of the tasks, it's nil if the cache is disabled.
+	coprCache *coprCache
 
 	tasks []*batchCopTask
+	// storeConcurrency limits the number of the running tasks of each store if it's not 0.
+	storeConcurrency int
+	// taskRate limits the number of the running tasks of all the stores if it's not nil.
+	taskRate *util.RateLimit
 
 	// Batch results are stored in respChan.
 	respChan chan *batchCopResponse
+	// respMaxBytes limits the bytes of the responses queued in respChan, 0 means no limit.
+	// The workers wait on respBytesReleased until the queued responses are consumed.
+	respMaxBytes      int64
+	respBytes         int64
+	respBytesReleased chan struct{}
+	// spiller stores the responses in the disk instead of respChan once the memory quota is exceeded,
+	// it's nil if the spill is disabled.
+	spiller *batchCopRespSpiller
 
 	vars *tikv.Variables
 
@@ -666,24 +1175,89 @@ type batchCopIterator struct {
 	// when the Close is called. we use atomic.CompareAndSwap `closed` to to make sure the channel is not closed twice.
 	closed uint32
 
+	// memTracker tracks the memory of the responses queued in respChan, the
+	// oom-action is triggered once it exceeds tidb_mem_quota_query.
+	memTracker *memory.Tracker
+
+	// timeoutRetries is the number of the tasks sent again after their stores time out.
+	timeoutRetries int32
+	// deadline is the deadline of the request, the streams are cancelled by the gRPC deadline once it passes.
+	// Zero means there is no deadline.
+	deadline time.Time
+
 	enableCollectExecutionInfo bool
+
+	// releaseQueue releases the admission of the request in the batch cop queue of the tidb-server.
+	releaseQueue func()
 }
 
 func (b *batchCopIterator) run(ctx context.Context) {
-	// We run workers for every batch cop.
+	if b.storeConcurrency > 0 {
+		b.runByStore(ctx)
+	} else {
+		b.runTasks(ctx, b.tasks, nil)
+	}
+	b.wg.Wait()
+	close(b.respChan)
+}
+
+// runByStore runs the tasks of every store with the limited concurrency.
+func (b *batchCopIterator) runByStore(ctx context.Context) {
+	var addrs []string
+	storeTasks := make(map[string][]*batchCopTask)
 	for _, task := range b.tasks {
+		if _, ok := storeTasks[task.storeAddr]; !ok {
+			addrs = append(addrs, task.storeAddr)
+		}
+		storeTasks[task.storeAddr] = append(storeTasks[task.storeAddr], task)
+	}
+	for _, addr := range addrs {
+		// The dispatcher of the store is counted too, so the iterator isn't regarded as finished before it exits.
+		b.wg.Add(1)
+		go func(tasks []*batchCopTask) {
+			defer b.wg.Done()
+			b.runTasks(ctx, tasks, util.NewRateLimit(b.storeConcurrency))
+		}(storeTasks[addr])
+	}
+}
+
+// runTasks starts the workers of the tasks. If storeRate is not nil, the next task is not started until a
+// running one is finished once the limit is reached.
+func (b *batchCopIterator) runTasks(ctx context.Context, tasks []*batchCopTask, storeRate *util.RateLimit) {
+	// We run workers for every batch cop.
+	for _, task := range tasks {
+		if storeRate != nil && storeRate.GetToken(b.finishCh) {
+			break
+		}
+		if b.taskRate != nil && b.taskRate.GetToken(b.finishCh) {
+			if storeRate != nil {
+				storeRate.PutToken()
+			}
+			break
+		}
 		b.wg.Add(1)
-		boMaxSleep := copNextMaxBackoff
+		bo := b.store.newTaskBackoffer(ctx, b.req.StoreType, b.vars)
 		failpoint.Inject("ReduceCopNextMaxBackoff", func(value failpoint.Value) {
 			if value.(bool) {
-				boMaxSleep = 2
+				bo = backoff.NewBackofferWithVars(ctx, 2, b.vars)
 			}
 		})
-		bo := backoff.NewBackofferWithVars(ctx, boMaxSleep, b.vars)
-		go b.handleTask(ctx, bo, task)
+		if storeRate == nil && b.taskRate == nil {
+			go b.handleTask(ctx, bo, task)
+			continue
+		}
+		go func(task *batchCopTask) {
+			defer func() {
+				if b.taskRate != nil {
+					b.taskRate.PutToken()
+				}
+				if storeRate != nil {
+					storeRate.PutToken()
+				}
+			}()
+			b.handleTask(ctx, bo, task)
+		}(task)
 	}
-	b.wg.Wait()
-	close(b.respChan)
 }
 
 // Next returns next coprocessor result.
@@ -715,13 +1289,35 @@ func (b *batchCopIterator) Next(ctx context.Context) (kv.ResultSubset, error) {
 func (b *batchCopIterator) recvFromRespCh(ctx context.Context) (resp *batchCopResponse, ok bool, exit bool) {
 	ticker := time.NewTicker(3 * time.Second)
 	defer ticker.Stop()
+	var spilled <-chan struct{}
+	if b.spiller != nil {
+		spilled = b.spiller.notify
+		// The spilled responses are read first, the order of the responses doesn't matter.
+		if resp, ok = b.spiller.pop(); ok {
+			return
+		}
+	}
 	for {
 		select {
 		case resp, ok = <-b.respChan:
+			if !ok && b.spiller != nil {
+				// respChan is closed after all the workers exit, so no response is spilled since then.
+				resp, ok = b.spiller.pop()
+				return
+			}
+			if resp != nil {
+				b.releaseResp(resp)
+			}
 			return
+		case <-spilled:
+			if resp, ok = b.spiller.pop(); ok {
+				return
+			}
 		case <-ticker.C:
 			if atomic.LoadUint32(b.vars.Killed) == 1 {
-				resp = &batchCopResponse{err: derr.ErrQueryInterrupted}
+				// Cancel the in-flight tasks too, otherwise TiFlash keeps computing them until they are finished.
+				b.cancelTasks()
+				resp = &batchCopResponse{err: &QueryCancelled{err: derr.ErrQueryInterrupted}}
 				ok = true
 				return
 			}
@@ -739,22 +1335,43 @@ func (b *batchCopIterator) recvFromRespCh(ctx context.Context) (resp *batchCopRe
 	}
 }
 
-// Close releases the resource.
-func (b *batchCopIterator) Close() error {
+// cancelTasks stops the workers and cancels the streams of the in-flight tasks, so TiFlash stops
+// computing them and releases their resources.
+func (b *batchCopIterator) cancelTasks() {
 	if atomic.CompareAndSwapUint32(&b.closed, 0, 1) {
 		close(b.finishCh)
 	}
 	b.rpcCancel.CancelAll()
+}
+
+// Close releases the resource.
+func (b *batchCopIterator) Close() error {
+	b.cancelTasks()
 	b.wg.Wait()
+	b.drainRespChan()
+	if b.releaseQueue != nil {
+		b.releaseQueue()
+	}
+	if b.spiller != nil {
+		b.spiller.close()
+	}
 	return nil
 }
 
 func (b *batchCopIterator) handleTask(ctx context.Context, bo *Backoffer, task *batchCopTask) {
 	tasks := []*batchCopTask{task}
 	for idx := 0; idx < len(tasks); idx++ {
+		if tasks[idx].fallbackToCop {
+			if err := b.handleTaskByCop(ctx, tasks[idx]); err != nil {
+				resp := &batchCopResponse{err: classifyErr(errors.Trace(err), b.req.StoreType, tasks[idx].storeAddr), detail: new(CopRuntimeStats)}
+				b.sendToRespCh(resp)
+				break
+			}
+			continue
+		}
 		ret, err := b.handleTaskOnce(ctx, bo, tasks[idx])
 		if err != nil {
-			resp := &batchCopResponse{err: errors.Trace(err), detail: new(CopRuntimeStats)}
+			resp := &batchCopResponse{err: classifyErr(errors.Trace(err), b.req.StoreType, tasks[idx].storeAddr), detail: new(CopRuntimeStats)}
 			b.sendToRespCh(resp)
 			break
 		}
@@ -763,23 +1380,132 @@ func (b *batchCopIterator) handleTask(ctx context.Context, bo *Backoffer, task *
 	b.wg.Done()
 }
 
-// Merge all ranges and request again.
+// retryBatchCopTask rebuilds the tasks of a failed batch. The regions still valid in the
+// region cache keep their ranges and are spread over the available TiFlash stores having
+// their peers, just the ranges of the invalidated regions are merged and split by the region
+// cache again.
 func (b *batchCopIterator) retryBatchCopTask(ctx context.Context, bo *backoff.Backoffer, batchTask *batchCopTask) ([]*batchCopTask, error) {
-	var ranges []kv.KeyRange
+	if b.req.StoreType == kv.TiKV {
+		// The regions of TiKV are read from the peers chosen by the region cache, which skips the failed store.
+		return b.rebuildBatchCopTask(bo, batchTask)
+	}
+	cache := b.store.GetRegionCache()
+	var (
+		ranges       []kv.KeyRange
+		retryTasks   []*batchCopTask
+		storeTaskMap = make(map[uint64]*batchCopTask)
+	)
+	// The store list is fresh, so the regions aren't all moved to the next peer of the failed store
+	// when the other stores having their peers are available, or a new store joins.
+	now := time.Now()
+	stores := cache.RegionCache.GetTiFlashStores()
+	unavailableStores := unavailableTiFlashStores(b.store, stores)
+	if batchTask.ctx != nil && batchTask.ctx.Store != nil {
+		unavailableStores[batchTask.ctx.Store.StoreID()] = struct{}{}
+	}
+	storeMap := make(map[uint64]*tikv.Store, len(stores))
+	for _, s := range stores {
+		storeMap[s.StoreID()] = s
+	}
+	allowedStores := allowedTiFlashStores(cache, b.req.TiFlashReplicaReadNodes)
 	for _, ri := range batchTask.regionInfos {
-		ri.Ranges.Do(func(ran *kv.KeyRange) {
-			ranges = append(ranges, *ran)
-		})
+		rpcCtx, err := cache.GetTiFlashRPCContext(bo.TiKVBackoffer(), ri.Region, false)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if rpcCtx == nil {
+			ri.Ranges.Do(func(ran *kv.KeyRange) {
+				ranges = append(ranges, *ran)
+			})
+			continue
+		}
+		allStores := filterAllowedStores(cache.GetAllValidTiFlashStores(ri.Region, rpcCtx.Store), allowedStores)
+		if len(allStores) == 0 {
+			return nil, errors.Errorf("region %d has no TiFlash replica in the specified nodes %v", ri.Region.GetID(), b.req.TiFlashReplicaReadNodes)
+		}
+		storeID := b.pickRetryStore(allStores, storeMap, unavailableStores, storeTaskMap, now)
+		// AllStores[0] is the store of the task.
+		for i, id := range allStores {
+			if id == storeID {
+				allStores[0], allStores[i] = allStores[i], allStores[0]
+				break
+			}
+		}
+		regionInfo := RegionInfo{Region: ri.Region, Meta: rpcCtx.Meta, Ranges: ri.Ranges, AllStores: allStores}
+		if task, ok := storeTaskMap[storeID]; ok {
+			task.regionInfos = append(task.regionInfos, regionInfo)
+			continue
+		}
+		taskCtx := rpcCtx
+		if storeID != rpcCtx.Store.StoreID() {
+			s := storeMap[storeID]
+			taskCtx = &tikv.RPCContext{Addr: s.GetAddr(), Store: s}
+		}
+		task := &batchCopTask{
+			storeAddr:   taskCtx.Addr,
+			cmdType:     batchTask.cmdType,
+			ctx:         taskCtx,
+			regionInfos: []RegionInfo{regionInfo},
+		}
+		storeTaskMap[storeID] = task
+		retryTasks = append(retryTasks, task)
+	}
+	if len(ranges) == 0 {
+		return retryTasks, nil
+	}
+	logutil.BgLogger().Info("rebuild the ranges of the invalidated regions for batch cop",
+		zap.Int("region count", len(batchTask.regionInfos)),
+		zap.Int("range count", len(ranges)))
+	tasks, err := buildBatchCopTasks(bo, b.store, NewKeyRanges(ranges), b.req.StoreType, newBatchCopTaskOptions(b.req))
+	if err != nil {
+		return nil, errors.Trace(err)
 	}
-	return buildBatchCopTasks(bo, b.store, NewKeyRanges(ranges), b.req.StoreType, nil, 0, false, 0)
+	return append(retryTasks, tasks...), nil
+}
+
+// pickRetryStore picks the store of a retried region from the stores having its peers, allStores[0] is the one
+// chosen by the region cache. The available store having the fewest retried regions, weighted by its load, is
+// picked, and allStores[0] is used if no other store is available.
+func (b *batchCopIterator) pickRetryStore(allStores []uint64, storeMap map[uint64]*tikv.Store, unavailableStores map[uint64]struct{}, storeTaskMap map[uint64]*batchCopTask, now time.Time) uint64 {
+	picked := allStores[0]
+	weightedRegionNum := math.MaxFloat64
+	for _, storeID := range allStores {
+		s, ok := storeMap[storeID]
+		if !ok {
+			continue
+		}
+		if _, unavailable := unavailableStores[storeID]; unavailable {
+			continue
+		}
+		regionNum := 1
+		if task, ok := storeTaskMap[storeID]; ok {
+			regionNum += len(task.regionInfos)
+		}
+		if num := float64(regionNum) * b.store.health.loadFactor(s.GetAddr(), now); num < weightedRegionNum {
+			picked, weightedRegionNum = storeID, num
+		}
+	}
+	return picked
 }
 
 const readTimeoutUltraLong = 3600 * time.Second // For requests that may scan many regions for tiflash.
 
-func (b *batchCopIterator) handleTaskOnce(ctx context.Context, bo *backoff.Backoffer, task *batchCopTask) ([]*batchCopTask, error) {
+func (b *batchCopIterator) handleTaskOnce(ctx context.Context, bo *backoff.Backoffer, task *batchCopTask) (_ []*batchCopTask, err error) {
+	span, spanCtx := startBatchCopSpan(ctx, batchCopTaskSpan)
+	if span != nil {
+		span.SetTag(batchCopSpanTagStoreAddr, task.storeAddr)
+		span.SetTag(batchCopSpanTagRegionCount, len(task.regionInfos))
+		defer func() {
+			finishBatchCopSpan(span, err)
+		}()
+		ctx = spanCtx
+	}
 	sender := NewRegionBatchRequestSender(b.store.GetRegionCache(), b.store.GetTiKVClient(), b.enableCollectExecutionInfo)
+	sender.deadline = b.deadline
 	var regionInfos = make([]*coprocessor.RegionInfo, 0, len(task.regionInfos))
+	rangeCount := 0
 	for _, ri := range task.regionInfos {
+		rangeCount += ri.Ranges.Len()
 		regionInfos = append(regionInfos, &coprocessor.RegionInfo{
 			RegionId: ri.Region.GetID(),
 			RegionEpoch: &metapb.RegionEpoch{
@@ -798,53 +1524,196 @@ func (b *batchCopIterator) handleTaskOnce(ctx context.Context, bo *backoff.Backo
 		Regions:   regionInfos,
 	}
 
+	var cacheKey []byte
+	if task.cmdType == tikvrpc.CmdBatchCop && b.coprCache != nil && b.req.Cacheable && b.coprCache.CheckRequestAdmission(rangeCount) {
+		cKey, err := coprCacheBuildBatchKey(&copReq)
+		if err != nil {
+			logutil.BgLogger().Warn("Failed to build copr cache key", zap.Error(err))
+		} else if cValue := b.coprCache.Get(cKey); cValue != nil && len(cValue.BatchData) > 0 {
+			b.sendCachedBatchCopResponses(bo, cValue, task)
+			return nil, nil
+		} else {
+			cacheKey = cKey
+		}
+	}
+
+	// The task is only sent to a store keeping timing out if the regions have no replicas in the other stores,
+	// fail fast if the statement can fall back to TiKV.
+	if b.req.StoreType == kv.TiFlash && b.req.AllowFallbackToTiKV && b.store.health.isBroken(task.storeAddr, time.Now()) {
+		logutil.BgLogger().Info("fail the batch cop task of the store timing out to fall back to TiKV",
+			zap.String("store addr", task.storeAddr))
+		return nil, derr.ErrTiFlashServerTimeout
+	}
+
+	timeout, err := timeoutBefore(b.deadline, readTimeoutUltraLong)
+	if err != nil {
+		return nil, err
+	}
 	req := tikvrpc.NewRequest(task.cmdType, &copReq, kvrpcpb.Context{
-		IsolationLevel: isolationLevelToPB(b.req.IsolationLevel),
-		Priority:       priorityToPB(b.req.Priority),
-		NotFillCache:   b.req.NotFillCache,
-		RecordTimeStat: true,
-		RecordScanStat: true,
-		TaskId:         b.req.TaskID,
+		IsolationLevel:         isolationLevelToPB(b.req.IsolationLevel),
+		Priority:               priorityToPB(b.req.Priority),
+		NotFillCache:           b.req.NotFillCache,
+		RecordTimeStat:         true,
+		RecordScanStat:         true,
+		TaskId:                 task.getTaskID(b.req),
+		MaxExecutionDurationMs: uint64(timeout.Milliseconds()),
 	})
 	if b.req.ResourceGroupTagger != nil {
 		b.req.ResourceGroupTagger(req)
 	}
-	req.StoreTp = tikvrpc.TiFlash
+	req.StoreTp = getEndPointType(b.req.StoreType)
+	req.ReadReplicaScope = b.req.ReadReplicaScope
+	if b.req.IsStaleness {
+		// The snapshot of a stale read is safe to read on the learners, so TiFlash serves it without waiting for
+		// the replica to catch up with the leader.
+		req.EnableStaleRead()
+	}
 
 	logutil.BgLogger().Debug("send batch request to ", zap.String("req info", req.String()), zap.Int("cop task len", len(task.regionInfos)))
-	resp, retry, cancel, err := sender.SendReqToAddr(bo, task.ctx, task.regionInfos, req, readTimeoutUltraLong)
+	b.store.health.onTaskStart(task.storeAddr)
+	defer b.store.health.onTaskFinish(task.storeAddr)
+	start := time.Now()
+	resp, retry, cancel, err := sender.SendReqToAddr(bo, task.ctx, task.regionInfos, req, timeout)
+	// Running out of the time of the request is not the fault of the store.
+	b.store.health.onResponse(task.storeAddr, time.Since(start), retry || (err != nil && !derr.ErrQueryInterrupted.Equal(err)), time.Now())
 	// If there are store errors, we should retry for all regions.
 	if retry {
+		batchCopRetryCounterSendFail.Inc()
 		return b.retryBatchCopTask(ctx, bo, task)
 	}
 	if err != nil {
+		if b.req.StoreType == kv.TiKV && isBatchCopUnsupportedErr(err) {
+			logutil.BgLogger().Info("the TiKV store doesn't support batch cop, read the regions by cop instead",
+				zap.String("store addr", task.storeAddr), zap.Int("region count", len(task.regionInfos)))
+			b.store.batchCopSupport.markUnsupported(task.storeAddr, time.Now())
+			task.fallbackToCop = true
+			if span != nil {
+				span.SetTag(batchCopSpanTagFallbackToCop, true)
+			}
+			return []*batchCopTask{task}, nil
+		}
 		err = derr.ToTiDBErr(err)
 		return nil, errors.Trace(err)
 	}
 	defer cancel()
-	return nil, b.handleStreamedBatchCopResponse(ctx, bo, resp.Resp.(*tikvrpc.BatchCopStreamResponse), task)
+	if task.reconnect {
+		tidbmetrics.DistSQLBatchCopStreamReconnectCounter.Inc()
+	}
+	dataSent, err := b.handleStreamedBatchCopResponse(ctx, bo, resp.Resp.(*tikvrpc.BatchCopStreamResponse), task, cacheKey, start)
+	if err == nil {
+		b.store.health.onSuccess(task.storeAddr)
+	} else if errors.ErrorEqual(err, derr.ErrTiFlashServerTimeout) && atomic.LoadUint32(&b.closed) == 0 {
+		// The streams are cancelled once the iterator is closed, which is not the fault of the store.
+		now := time.Now()
+		b.store.health.onResponse(task.storeAddr, 0, true, now)
+		b.store.health.onTimeout(task.storeAddr, now)
+		// The task can be sent again if none of its data is returned, the stores keeping timing out are avoided
+		// when the regions are balanced again.
+		if !dataSent && atomic.AddInt32(&b.timeoutRetries, 1) <= batchCopTimeoutRetryLimit {
+			logutil.BgLogger().Info("retry the batch cop task after the store times out",
+				zap.String("store addr", task.storeAddr), zap.Int("region count", len(task.regionInfos)))
+			batchCopRetryCounterStreamError.Inc()
+			tasks, err := b.rebuildBatchCopTask(bo, task)
+			for _, t := range tasks {
+				t.reconnect = true
+			}
+			return tasks, err
+		}
+	}
+	return nil, err
+}
+
+// deadlineExceeded checks whether the deadline of the request has passed.
+func (b *batchCopIterator) deadlineExceeded() bool {
+	return !b.deadline.IsZero() && !time.Now().Before(b.deadline)
+}
+
+// batchCopTimeoutRetryLimit is the number of the tasks of a request that can be sent again after their stores
+// time out, so a request doesn't hang on the failing stores for long.
+const batchCopTimeoutRetryLimit = 3
+
+// rebuildBatchCopTask builds the tasks of the regions of a batch again, which are balanced with the recent health
+// of the stores.
+func (b *batchCopIterator) rebuildBatchCopTask(bo *backoff.Backoffer, batchTask *batchCopTask) ([]*batchCopTask, error) {
+	var ranges []kv.KeyRange
+	for _, ri := range batchTask.regionInfos {
+		ri.Ranges.Do(func(ran *kv.KeyRange) {
+			ranges = append(ranges, *ran)
+		})
+	}
+	if b.req.StoreType == kv.TiKV {
+		tasks, err := buildTiKVBatchCopTasks(bo, b.store, NewKeyRanges(ranges), b.req, b.copClient.replicaReadSeed)
+		return tasks, errors.Trace(err)
+	}
+	tasks, err := buildBatchCopTasks(bo, b.store, NewKeyRanges(ranges), b.req.StoreType, newBatchCopTaskOptions(b.req))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return tasks, nil
 }
 
-func (b *batchCopIterator) handleStreamedBatchCopResponse(ctx context.Context, bo *Backoffer, response *tikvrpc.BatchCopStreamResponse, task *batchCopTask) (err error) {
+// handleStreamedBatchCopResponse handles the responses of the stream, and returns whether any data is sent to the
+// consumer. If cacheKey is not nil, the responses are cached once they are all received, unless some regions are
+// not read or they are too big.
+func (b *batchCopIterator) handleStreamedBatchCopResponse(ctx context.Context, bo *Backoffer, response *tikvrpc.BatchCopStreamResponse, task *batchCopTask, cacheKey []byte, start time.Time) (dataSent bool, err error) {
 	defer response.Close()
+	var respCount, respBytes int
+	if span, _ := startBatchCopSpan(ctx, batchCopStreamSpan); span != nil {
+		span.SetTag(batchCopSpanTagStoreAddr, task.storeAddr)
+		defer func() {
+			span.SetTag(batchCopSpanTagRespCount, respCount)
+			span.SetTag(batchCopSpanTagBytes, respBytes)
+			finishBatchCopSpan(span, err)
+		}()
+	}
 	resp := response.BatchResponse
 	if resp == nil {
 		// streaming request returns io.EOF, so the first Response is nil.
-		return
+		return dataSent, nil
 	}
+	var (
+		cacheData [][]byte
+		cacheSize int
+	)
 	for {
+		respCount++
+		respBytes += len(resp.Data)
+		if cacheKey != nil {
+			if len(resp.RetryRegions) > 0 || cacheSize+len(resp.Data) > b.coprCache.admissionMaxSize {
+				cacheKey, cacheData = nil, nil
+			} else if len(resp.Data) > 0 {
+				cacheData = append(cacheData, append([]byte(nil), resp.Data...))
+				cacheSize += len(resp.Data)
+			}
+		}
 		err = b.handleBatchCopResponse(bo, resp, task)
 		if err != nil {
-			return errors.Trace(err)
+			return dataSent, errors.Trace(err)
 		}
-		resp, err = response.Recv()
+		if len(resp.RetryRegions) == 0 && len(resp.Data) > 0 {
+			dataSent = true
+		}
+		// Stop receiving once the query is killed, the stream is cancelled when the task returns.
+		if b.vars != nil && atomic.LoadUint32(b.vars.Killed) == 1 {
+			return dataSent, derr.ErrQueryInterrupted
+		}
+		resp, err = b.recvBatchCopStream(response, task, dataSent)
 		if err != nil {
+			if b.deadlineExceeded() {
+				return dataSent, derr.ErrQueryInterrupted
+			}
 			if errors.Cause(err) == io.EOF {
-				return nil
+				if cacheKey != nil && b.coprCache.CheckResponseAdmission(cacheSize, time.Since(start)) {
+					b.coprCache.Set(cacheKey, &coprCacheValue{
+						BatchData: cacheData,
+						TimeStamp: b.req.StartTs,
+					})
+				}
+				return dataSent, nil
 			}
 
 			if err1 := bo.Backoff(tikv.BoTiKVRPC(), errors.Errorf("recv stream response error: %v, task store addr: %s", err, task.storeAddr)); err1 != nil {
-				return errors.Trace(err)
+				return dataSent, &StreamBroken{StoreType: b.req.StoreType, StoreAddr: task.storeAddr, err: errors.Trace(err)}
 			}
 
 			// No coprocessor.Response for network error, rebuild task based on the last success one.
@@ -853,7 +1722,51 @@ func (b *batchCopIterator) handleStreamedBatchCopResponse(ctx context.Context, b
 			} else {
 				logutil.BgLogger().Info("stream unknown error", zap.Error(err))
 			}
-			return derr.ErrTiFlashServerTimeout
+			return dataSent, &StreamBroken{StoreType: b.req.StoreType, StoreAddr: task.storeAddr, err: derr.ErrTiFlashServerTimeout}
+		}
+	}
+}
+
+// recvBatchCopStream receives the next response of the stream, a warning is logged once it waits longer than the
+// stall threshold of the request. If the request reconnects the stalled streams and no data of the task is sent,
+// the stream is cancelled then, and Recv fails like the store times out, so the task is sent again.
+func (b *batchCopIterator) recvBatchCopStream(response *tikvrpc.BatchCopStreamResponse, task *batchCopTask, dataSent bool) (*coprocessor.BatchResponse, error) {
+	start := time.Now()
+	var stallTimer *time.Timer
+	if threshold := b.req.BatchCopStreamStallThreshold; threshold > 0 {
+		stallTimer = time.AfterFunc(threshold, func() {
+			reconnect := b.req.BatchCopStreamStallReconnect && !dataSent && response.Lease.Cancel != nil
+			logutil.BgLogger().Warn("batch cop stream stalls",
+				zap.String("store addr", task.storeAddr),
+				zap.Uint64("task id", task.getTaskID(b.req)),
+				zap.Int("region count", len(task.regionInfos)),
+				zap.Duration("threshold", threshold),
+				zap.Bool("reconnect", reconnect))
+			tidbmetrics.DistSQLBatchCopStreamStallCounter.Inc()
+			if reconnect {
+				response.Lease.Cancel()
+			}
+		})
+	}
+	resp, err := response.Recv()
+	if stallTimer != nil {
+		stallTimer.Stop()
+	}
+	tidbmetrics.DistSQLBatchCopStreamRecvGapHistogram.Observe(time.Since(start).Seconds())
+	return resp, err
+}
+
+// sendCachedBatchCopResponses answers the task with the cached responses, the task is not sent to TiFlash.
+func (b *batchCopIterator) sendCachedBatchCopResponses(bo *Backoffer, cacheValue *coprCacheValue, task *batchCopTask) {
+	for _, data := range cacheValue.BatchData {
+		resp := &batchCopResponse{
+			pbResp: &coprocessor.BatchResponse{Data: append([]byte(nil), data...)},
+			detail: new(CopRuntimeStats),
+		}
+		b.handleCollectExecutionInfo(bo, resp, task)
+		resp.detail.CoprCacheHit = true
+		if b.sendToRespCh(resp) {
+			return
 		}
 	}
 }
@@ -894,14 +1807,67 @@ func (b *batchCopIterator) handleBatchCopResponse(bo *Backoffer, response *copro
 }
 
 func (b *batchCopIterator) sendToRespCh(resp *batchCopResponse) (exit bool) {
+	if b.spiller != nil && b.spiller.isSpilled() && resp.err == nil {
+		err := b.spiller.spill(resp)
+		if err == nil {
+			return false
+		}
+		logutil.BgLogger().Warn("spill the batch cop response failed", zap.Error(err))
+		resp = &batchCopResponse{err: err}
+	}
+	if b.respMaxBytes > 0 {
+		// At least one response is queued even if it alone exceeds the limit.
+		for queued := atomic.LoadInt64(&b.respBytes); queued > 0 && queued+resp.MemSize() > b.respMaxBytes; queued = atomic.LoadInt64(&b.respBytes) {
+			select {
+			case <-b.respBytesReleased:
+			case <-b.finishCh:
+				return true
+			}
+		}
+		atomic.AddInt64(&b.respBytes, resp.MemSize())
+	}
+	if b.memTracker != nil {
+		b.memTracker.Consume(resp.MemSize())
+	}
 	select {
 	case b.respChan <- resp:
 	case <-b.finishCh:
+		b.releaseResp(resp)
 		exit = true
 	}
 	return
 }
 
+// releaseResp releases the memory of a response taken out of respChan, or never sent to it.
+func (b *batchCopIterator) releaseResp(resp *batchCopResponse) {
+	if b.memTracker != nil {
+		b.memTracker.Consume(-resp.MemSize())
+	}
+	if b.respMaxBytes > 0 {
+		atomic.AddInt64(&b.respBytes, -resp.MemSize())
+		select {
+		case b.respBytesReleased <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// drainRespChan releases the responses left in respChan, which are never read once the iterator is closed.
+// It's called after all the workers exit.
+func (b *batchCopIterator) drainRespChan() {
+	for {
+		select {
+		case resp, ok := <-b.respChan:
+			if !ok {
+				return
+			}
+			b.releaseResp(resp)
+		default:
+			return
+		}
+	}
+}
+
 func (b *batchCopIterator) handleCollectExecutionInfo(bo *Backoffer, resp *batchCopResponse, task *batchCopTask) {
 	if !b.enableCollectExecutionInfo {
 		return
@@ -915,4 +1881,5 @@ func (b *batchCopIterator) handleCollectExecutionInfo(bo *Backoffer, resp *batch
 		resp.detail.BackoffSleep[backoff] = time.Duration(bo.GetBackoffSleepMS()[backoff]) * time.Millisecond
 	}
 	resp.detail.CalleeAddress = task.storeAddr
+	resp.detail.ScanDetail, resp.detail.TimeDetail = execDetailsFromPB(nil, resp.pbResp.ExecDetails)
 }
@@ -15,15 +15,39 @@
 package copr
 
 import (
+	"context"
+	"io"
 	"math/rand"
 	"sort"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/coprocessor"
+	"github.com/pingcap/kvproto/pkg/kvrpcpb"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/mpp"
+	"github.com/pingcap/kvproto/pkg/tikvpb"
 	"github.com/pingcap/tidb/kv"
+	tidbmetrics "github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/store/driver/backoff"
+	derr "github.com/pingcap/tidb/store/driver/error"
+	"github.com/pingcap/tidb/store/helper"
+	"github.com/pingcap/tidb/util/memory"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/require"
+	"github.com/tikv/client-go/v2/config"
+	tikvstore "github.com/tikv/client-go/v2/kv"
+	"github.com/tikv/client-go/v2/testutils"
 	"github.com/tikv/client-go/v2/tikv"
+	"github.com/tikv/client-go/v2/tikvrpc"
+	"github.com/tikv/client-go/v2/util"
 )
 
 // StoreID: [1, storeCount]
@@ -119,13 +143,13 @@ func TestBalanceBatchCopTaskWithContinuity(t *testing.T) {
 func TestBalanceBatchCopTaskWithEmptyTaskSet(t *testing.T) {
 	{
 		var nilTaskSet []*batchCopTask
-		nilResult := balanceBatchCopTask(nil, nil, nilTaskSet, nil, time.Second, false, 0)
+		nilResult := balanceBatchCopTask(nil, nil, nilTaskSet, nil, time.Second, false, 0, "")
 		require.True(t, nilResult == nil)
 	}
 
 	{
 		emptyTaskSet := make([]*batchCopTask, 0)
-		emptyResult := balanceBatchCopTask(nil, nil, emptyTaskSet, nil, time.Second, false, 0)
+		emptyResult := balanceBatchCopTask(nil, nil, emptyTaskSet, nil, time.Second, false, 0, "")
 		require.True(t, emptyResult != nil)
 		require.True(t, len(emptyResult) == 0)
 	}
@@ -151,3 +175,1303 @@ func TestDeepCopyStoreTaskMap(t *testing.T) {
 		require.Equal(t, 2, len(task.regionInfos))
 	}
 }
+
+func TestPreferZoneStores(t *testing.T) {
+	storeTasks := buildStoreTaskMap(4)
+	// Store 3 is in the zone but unavailable.
+	delete(storeTasks, 3)
+	zoneStores := map[uint64]struct{}{2: {}, 3: {}}
+
+	require.Equal(t, []uint64{2}, preferZoneStores([]uint64{1, 2, 4}, zoneStores, storeTasks))
+	require.Equal(t, []uint64{2}, preferZoneStores([]uint64{2, 3}, zoneStores, storeTasks))
+	// All the stores are kept if the region has no available replica in the zone.
+	require.Equal(t, []uint64{1, 3, 4}, preferZoneStores([]uint64{1, 3, 4}, zoneStores, storeTasks))
+	require.Equal(t, []uint64{1, 4}, preferZoneStores([]uint64{1, 4}, zoneStores, storeTasks))
+}
+
+func TestRetryBatchCopTask(t *testing.T) {
+	// nil --- 'g' --- 'n' --- nil
+	// <-  0  -> <- 1 -> <- 2 ->
+	mock := newBatchCopTestStore(t, 3, 2, nil)
+	store := &kvStore{store: mock.KVStore}
+	it := &batchCopIterator{store: store, req: &kv.Request{StoreType: kv.TiFlash}}
+	bo := backoff.NewBackofferWithVars(context.Background(), 3000, nil)
+
+	tasks, err := buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	task := tasks[0]
+	require.Len(t, task.regionInfos, 3)
+
+	// The regions are switched to the other TiFlash store and keep their ranges.
+	store.GetRegionCache().OnSendFailForBatchRegions(bo, task.ctx.Store, task.regionInfos, false, errors.New("mock send error"))
+	retryTasks, err := it.retryBatchCopTask(context.Background(), bo, task)
+	require.NoError(t, err)
+	require.Len(t, retryTasks, 1)
+	require.NotEqual(t, task.storeAddr, retryTasks[0].storeAddr)
+	require.Len(t, retryTasks[0].regionInfos, 3)
+	for i, ri := range retryTasks[0].regionInfos {
+		require.Equal(t, task.regionInfos[i].Region, ri.Region)
+		require.Same(t, task.regionInfos[i].Ranges, ri.Ranges)
+	}
+
+	// Only the ranges of the invalidated region are split again.
+	task = retryTasks[0]
+	store.GetRegionCache().InvalidateCachedRegion(task.regionInfos[1].Region)
+	retryTasks, err = it.retryBatchCopTask(context.Background(), bo, task)
+	require.NoError(t, err)
+	require.Equal(t, 3, calcReginCount(retryTasks))
+	require.Len(t, retryTasks[0].regionInfos, 2)
+	require.Same(t, task.regionInfos[0].Ranges, retryTasks[0].regionInfos[0].Ranges)
+	require.Same(t, task.regionInfos[2].Ranges, retryTasks[0].regionInfos[1].Ranges)
+	rebuilt := retryTasks[1].regionInfos[0]
+	require.Equal(t, mock.regionIDs[1], rebuilt.Region.GetID())
+	require.Equal(t, 1, rebuilt.Ranges.Len())
+	require.Equal(t, kv.Key("h"), rebuilt.Ranges.At(0).StartKey)
+	require.Equal(t, kv.Key("i"), rebuilt.Ranges.At(0).EndKey)
+}
+
+func TestBatchCopTasksFallbackToTiKV(t *testing.T) {
+	// nil --- 'g' --- 'n' --- nil
+	// <-  0  -> <- 1 -> <- 2 ->
+	mock := newBatchCopTestStore(t, 3, 1, nil)
+	// The TiFlash replica of region 1 is not synced yet.
+	mock.removePeer(mock.regionIDs[1], mock.storeIDs[0])
+	store := &kvStore{store: mock.KVStore}
+
+	bo := backoff.NewBackofferWithVars(context.Background(), 300, nil)
+	_, err := buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{})
+	require.Error(t, err)
+
+	// The region without TiFlash peers is read from TiKV, the others are still read from TiFlash.
+	bo = backoff.NewBackofferWithVars(context.Background(), 3000, nil)
+	tasks, err := buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{fallbackToTiKV: true})
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	require.False(t, tasks[0].fallbackToCop)
+	require.Equal(t, "tiflash0", tasks[0].storeAddr)
+	require.Len(t, tasks[0].regionInfos, 2)
+	require.True(t, tasks[1].fallbackToCop)
+	require.Len(t, tasks[1].regionInfos, 1)
+	ri := tasks[1].regionInfos[0]
+	require.Equal(t, mock.regionIDs[1], ri.Region.GetID())
+	require.Equal(t, 1, ri.Ranges.Len())
+	require.Equal(t, kv.Key("h"), ri.Ranges.At(0).StartKey)
+	require.Equal(t, kv.Key("i"), ri.Ranges.At(0).EndKey)
+
+	// The tasks split by size are still read from TiKV.
+	sizeTasks := splitBatchCopTasksBySize(tasks, 1)
+	require.Len(t, sizeTasks, 3)
+	require.True(t, sizeTasks[2].fallbackToCop)
+}
+
+func TestRetryBatchCopTaskSpreadRegions(t *testing.T) {
+	// nil --- 'g' --- 'n' --- nil
+	// <-  0  -> <- 1 -> <- 2 ->
+	mock := newBatchCopTestStore(t, 3, 3, nil)
+	bo := backoff.NewBackofferWithVars(context.Background(), 3000, nil)
+	buildFailedTask := func(store *kvStore) *batchCopTask {
+		tasks, err := buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{})
+		require.NoError(t, err)
+		// All the regions are sent to the same store.
+		task := tasks[0]
+		for _, other := range tasks[1:] {
+			task.regionInfos = append(task.regionInfos, other.regionInfos...)
+		}
+		require.Len(t, task.regionInfos, 3)
+		store.GetRegionCache().OnSendFailForBatchRegions(bo, task.ctx.Store, task.regionInfos, false, errors.New("mock send error"))
+		return task
+	}
+
+	// The regions of the failed store are spread over the other two stores.
+	store := &kvStore{store: mock.KVStore}
+	it := &batchCopIterator{store: store, req: &kv.Request{StoreType: kv.TiFlash}}
+	task := buildFailedTask(store)
+	retryTasks, err := it.retryBatchCopTask(context.Background(), bo, task)
+	require.NoError(t, err)
+	require.Len(t, retryTasks, 2)
+	require.Equal(t, 3, calcReginCount(retryTasks))
+	for _, retryTask := range retryTasks {
+		require.NotEqual(t, task.storeAddr, retryTask.storeAddr)
+		require.Equal(t, retryTask.storeAddr, retryTask.ctx.Addr)
+	}
+
+	// The regions aren't moved to the store found down by the last probe.
+	store = &kvStore{store: mock.KVStore}
+	it = &batchCopIterator{store: store, req: &kv.Request{StoreType: kv.TiFlash}}
+	task = buildFailedTask(store)
+	var downAddr string
+	for i := 0; i < 3; i++ {
+		if addr := "tiflash" + strconv.Itoa(i); addr != task.storeAddr {
+			downAddr = addr
+			mock.probe.down[addr] = true
+			break
+		}
+	}
+	store.probeTiFlashStores(context.Background())
+	retryTasks, err = it.retryBatchCopTask(context.Background(), bo, task)
+	require.NoError(t, err)
+	require.Len(t, retryTasks, 1)
+	require.Len(t, retryTasks[0].regionInfos, 3)
+	require.NotEqual(t, task.storeAddr, retryTasks[0].storeAddr)
+	require.NotEqual(t, downAddr, retryTasks[0].storeAddr)
+}
+
+func TestBatchCopTasksSpecifiedNodes(t *testing.T) {
+	// nil --- 'g' --- 'n' --- nil
+	// <-  0  -> <- 1 -> <- 2 ->
+	mock := newBatchCopTestStore(t, 3, 3, nil)
+	bo := backoff.NewBackofferWithVars(context.Background(), 3000, nil)
+	store := &kvStore{store: mock.KVStore}
+
+	// The regions are balanced between the specified stores only.
+	nodes := []string{"tiflash1", "tiflash2"}
+	tasks, err := buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{allowedAddrs: nodes})
+	require.NoError(t, err)
+	require.Equal(t, 3, calcReginCount(tasks))
+	for _, task := range tasks {
+		require.Contains(t, nodes, task.storeAddr)
+		require.Equal(t, task.storeAddr, task.ctx.Addr)
+		for _, ri := range task.regionInfos {
+			require.Len(t, ri.AllStores, 2)
+		}
+	}
+
+	// The regions are not moved out of the specified store when it fails.
+	tasks, err = buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{allowedAddrs: []string{"tiflash1"}})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.Equal(t, "tiflash1", tasks[0].storeAddr)
+	require.Len(t, tasks[0].regionInfos, 3)
+	it := &batchCopIterator{store: store, req: &kv.Request{StoreType: kv.TiFlash, TiFlashReplicaReadNodes: []string{"tiflash1"}}}
+	retryTasks, err := it.retryBatchCopTask(context.Background(), bo, tasks[0])
+	require.NoError(t, err)
+	require.Len(t, retryTasks, 1)
+	require.Equal(t, "tiflash1", retryTasks[0].storeAddr)
+
+	// The regions without a replica in the specified stores can't be read.
+	_, err = buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{allowedAddrs: []string{"tiflash9"}})
+	require.Error(t, err)
+
+	// The MPP tasks without ranges are only sent to the specified stores.
+	mppTasks := (&MPPClient{store: store}).selectMPPStores(context.Background(), &kv.MPPBuildTasksRequest{TiFlashReplicaReadNodes: nodes}, nil, 0)
+	require.Len(t, mppTasks, 2)
+	for _, task := range mppTasks {
+		require.Contains(t, nodes, task.(*batchCopTask).storeAddr)
+	}
+}
+
+func TestDryRunBatchCop(t *testing.T) {
+	// nil --- 'g' --- 'n' --- 't' --- nil
+	// <-  0  -> <- 1 -> <- 2 -> <- 3 ->
+	// Region 0 is only in tiflash0, the others are located at tiflash1 at first.
+	mock := newBatchCopTestStore(t, 4, 2, nil)
+	mock.removePeer(mock.regionIDs[0], mock.storeIDs[1])
+	for _, regionID := range mock.regionIDs[1:] {
+		mock.removePeer(regionID, mock.storeIDs[0])
+		mock.cluster.AddPeer(regionID, mock.storeIDs[0], mock.cluster.AllocID())
+	}
+	store, err := NewStore(mock.KVStore, nil)
+	require.NoError(t, err)
+	defer store.Close()
+	copClient := store.GetClient().(*CopClient)
+
+	checkLayout := func(layout *BatchCopLayout) {
+		regions, moved := 0, 0
+		for _, task := range layout.Tasks {
+			require.Equal(t, len(task.Regions), task.RegionCount)
+			for _, region := range task.Regions {
+				require.Equal(t, region.LocatedStoreAddr != task.StoreAddr, region.Moved)
+				if region.Moved {
+					moved++
+				}
+			}
+			regions += task.RegionCount
+		}
+		require.Equal(t, 4, regions)
+		require.Equal(t, regions, layout.RegionCount)
+		require.Equal(t, moved, layout.MovedRegionCount)
+	}
+
+	// A region located at tiflash1 is balanced to tiflash0.
+	layout, err := copClient.DryRunBatchCop(context.Background(), &kv.Request{
+		Tp:        kv.ReqTypeDAG,
+		KeyRanges: buildKeyRanges("a", "c", "h", "i", "o", "p", "u", "v"),
+		StoreType: kv.TiFlash,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "tiflash", layout.StoreType)
+	checkLayout(layout)
+	require.Len(t, layout.Tasks, 2)
+	require.Equal(t, 1, layout.MovedRegionCount)
+	require.Equal(t, 1.0, layout.Skew)
+	for _, task := range layout.Tasks {
+		require.Equal(t, 2, task.RegionCount)
+		require.Contains(t, mock.storeIDs, task.StoreID)
+		for _, region := range task.Regions {
+			if region.Moved {
+				require.Equal(t, "tiflash1", region.LocatedStoreAddr)
+				require.Equal(t, "tiflash0", task.StoreAddr)
+				require.Len(t, region.AllStores, 2)
+			}
+		}
+	}
+
+	// The TiFlash tasks are restricted to the specified nodes.
+	layout, err = copClient.DryRunBatchCop(context.Background(), &kv.Request{
+		Tp:                      kv.ReqTypeDAG,
+		KeyRanges:               buildKeyRanges("a", "c", "h", "i", "o", "p", "u", "v"),
+		StoreType:               kv.TiFlash,
+		TiFlashReplicaReadNodes: []string{"tiflash0"},
+	})
+	require.NoError(t, err)
+	checkLayout(layout)
+	require.Len(t, layout.Tasks, 1)
+	require.Equal(t, "tiflash0", layout.Tasks[0].StoreAddr)
+	require.Equal(t, 3, layout.MovedRegionCount)
+	require.Equal(t, 1.0, layout.Skew)
+
+	// The regions of the TiKV tasks are read from the leaders.
+	layout, err = copClient.DryRunBatchCop(context.Background(), &kv.Request{
+		Tp:        kv.ReqTypeDAG,
+		KeyRanges: buildKeyRanges("a", "c", "h", "i", "o", "p", "u", "v"),
+		StoreType: kv.TiKV,
+		BatchCop:  true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "tikv", layout.StoreType)
+	checkLayout(layout)
+	require.Len(t, layout.Tasks, 1)
+	require.Zero(t, layout.MovedRegionCount)
+}
+
+func TestBatchCopExecDetails(t *testing.T) {
+	it := &batchCopIterator{enableCollectExecutionInfo: true}
+	bo := backoff.NewBackofferWithVars(context.Background(), 3000, nil)
+	resp := &batchCopResponse{
+		pbResp: &coprocessor.BatchResponse{
+			ExecDetails: &kvrpcpb.ExecDetails{
+				TimeDetail: &kvrpcpb.TimeDetail{ProcessWallTimeMs: 20, WaitWallTimeMs: 5},
+				ScanDetail: &kvrpcpb.ScanDetail{Write: &kvrpcpb.ScanInfo{Processed: 100, Total: 120}},
+			},
+		},
+		detail: new(CopRuntimeStats),
+	}
+	it.handleCollectExecutionInfo(bo, resp, &batchCopTask{storeAddr: "tiflash0"})
+	detail := resp.GetCopRuntimeStats()
+	require.Equal(t, "tiflash0", detail.CalleeAddress)
+	require.Equal(t, 20*time.Millisecond, detail.TimeDetail.ProcessTime)
+	require.Equal(t, 5*time.Millisecond, detail.TimeDetail.WaitTime)
+	require.Equal(t, int64(100), detail.ScanDetail.ProcessedKeys)
+	require.Equal(t, int64(120), detail.ScanDetail.TotalKeys)
+}
+
+func TestBatchCopRespMemTracker(t *testing.T) {
+	resp := &batchCopResponse{pbResp: &coprocessor.BatchResponse{Data: make([]byte, 100)}}
+	size := resp.MemSize()
+	exceeded := false
+	tracker := memory.NewTracker(0, size+size/2)
+	action := &memory.LogOnExceed{}
+	action.SetLogHook(func(uint64) { exceeded = true })
+	tracker.SetActionOnExceed(action)
+	it := &batchCopIterator{
+		finishCh:   make(chan struct{}),
+		respChan:   make(chan *batchCopResponse, 2),
+		memTracker: tracker,
+		rpcCancel:  tikv.NewRPCanceller(),
+	}
+
+	require.False(t, it.sendToRespCh(resp))
+	require.Equal(t, size, tracker.BytesConsumed())
+	require.False(t, exceeded)
+	// The queued responses exceeding the quota trigger the oom-action.
+	require.False(t, it.sendToRespCh(&batchCopResponse{pbResp: &coprocessor.BatchResponse{Data: make([]byte, 100)}}))
+	require.Equal(t, 2*size, tracker.BytesConsumed())
+	require.True(t, exceeded)
+
+	for i := 0; i < 2; i++ {
+		_, ok, exit := it.recvFromRespCh(context.Background())
+		require.True(t, ok)
+		require.False(t, exit)
+	}
+	require.Equal(t, int64(0), tracker.BytesConsumed())
+
+	// The responses queued when the iterator is closed are released by Close.
+	require.False(t, it.sendToRespCh(resp))
+	require.False(t, it.sendToRespCh(resp))
+	require.Equal(t, 2*size, tracker.BytesConsumed())
+	require.NoError(t, it.Close())
+	require.Equal(t, int64(0), tracker.BytesConsumed())
+	// The responses not sent after the iterator is closed are released too.
+	it.respChan = make(chan *batchCopResponse)
+	require.True(t, it.sendToRespCh(resp))
+	require.Equal(t, int64(0), tracker.BytesConsumed())
+}
+
+func TestBatchCopRespFlowControl(t *testing.T) {
+	newResp := func() *batchCopResponse {
+		return &batchCopResponse{pbResp: &coprocessor.BatchResponse{Data: make([]byte, 100)}}
+	}
+	size := newResp().MemSize()
+	it := &batchCopIterator{
+		finishCh:          make(chan struct{}),
+		respChan:          make(chan *batchCopResponse, 10),
+		respMaxBytes:      size + size/2,
+		respBytesReleased: make(chan struct{}, 1),
+	}
+
+	// A response exceeding the limit alone is still queued.
+	it.respMaxBytes = size / 2
+	require.False(t, it.sendToRespCh(newResp()))
+	_, ok, _ := it.recvFromRespCh(context.Background())
+	require.True(t, ok)
+	require.Equal(t, int64(0), atomic.LoadInt64(&it.respBytes))
+
+	it.respMaxBytes = size + size/2
+	require.False(t, it.sendToRespCh(newResp()))
+	sent := make(chan bool)
+	go func() {
+		sent <- it.sendToRespCh(newResp())
+	}()
+	select {
+	case <-sent:
+		require.FailNow(t, "the response exceeding the limit should wait")
+	case <-time.After(100 * time.Millisecond):
+	}
+	_, ok, _ = it.recvFromRespCh(context.Background())
+	require.True(t, ok)
+	require.False(t, <-sent)
+	require.Equal(t, size, atomic.LoadInt64(&it.respBytes))
+
+	// The waiting workers exit once the iterator is closed.
+	go func() {
+		sent <- it.sendToRespCh(newResp())
+	}()
+	close(it.finishCh)
+	require.True(t, <-sent)
+}
+
+func TestBatchCopRespSpill(t *testing.T) {
+	newResp := func(i byte) *batchCopResponse {
+		data := make([]byte, 100)
+		data[0] = i
+		return &batchCopResponse{pbResp: &coprocessor.BatchResponse{Data: data}, detail: new(CopRuntimeStats)}
+	}
+	size := newResp(0).MemSize()
+	exceeded := false
+	tracker := memory.NewTracker(0, size+size/2)
+	action := &memory.LogOnExceed{}
+	action.SetLogHook(func(uint64) { exceeded = true })
+	tracker.SetActionOnExceed(action)
+	it := &batchCopIterator{
+		finishCh:   make(chan struct{}),
+		respChan:   make(chan *batchCopResponse, 2),
+		memTracker: tracker,
+		spiller:    newBatchCopRespSpiller(),
+	}
+	tracker.FallbackOldAndSetNewAction(it.spiller)
+	defer it.spiller.close()
+
+	// The responses are queued in memory until the quota is exceeded.
+	require.False(t, it.sendToRespCh(newResp(0)))
+	require.False(t, it.spiller.isSpilled())
+	require.False(t, it.sendToRespCh(newResp(1)))
+	require.True(t, it.spiller.isSpilled())
+	require.False(t, exceeded)
+	require.Equal(t, 2*size, tracker.BytesConsumed())
+
+	// The following responses are spilled even though respChan is full.
+	require.False(t, it.sendToRespCh(newResp(2)))
+	require.False(t, it.sendToRespCh(newResp(3)))
+	require.Equal(t, 2*size, tracker.BytesConsumed())
+	// The fallback action is triggered if the quota is still exceeded.
+	tracker.Consume(1)
+	require.True(t, exceeded)
+	tracker.Consume(-1)
+
+	// The error responses are not spilled.
+	respChan := it.respChan
+	it.respChan = make(chan *batchCopResponse, 1)
+	require.False(t, it.sendToRespCh(&batchCopResponse{err: errors.New("mock error")}))
+	require.Len(t, it.respChan, 1)
+	it.respChan = respChan
+
+	var received []byte
+	recv := func() {
+		resp, ok, exit := it.recvFromRespCh(context.Background())
+		require.True(t, ok)
+		require.False(t, exit)
+		require.NoError(t, resp.err)
+		require.Len(t, resp.GetData(), 100)
+		received = append(received, resp.GetData()[0])
+	}
+	for i := 0; i < 4; i++ {
+		recv()
+	}
+	require.Equal(t, []byte{2, 3, 0, 1}, received)
+	require.Equal(t, int64(0), tracker.BytesConsumed())
+
+	// The consumer waiting on respChan is woken up by the spilled response.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		recv()
+	}()
+	time.Sleep(50 * time.Millisecond)
+	require.False(t, it.sendToRespCh(newResp(4)))
+	<-done
+	require.Equal(t, byte(4), received[4])
+
+	// The spilled responses are still read after respChan is closed.
+	require.False(t, it.sendToRespCh(newResp(5)))
+	close(it.respChan)
+	recv()
+	require.Equal(t, byte(5), received[5])
+	_, ok, _ := it.recvFromRespCh(context.Background())
+	require.False(t, ok)
+}
+
+func TestSplitBatchCopTasksBySize(t *testing.T) {
+	newTask := func(addr string, regionCount int) *batchCopTask {
+		task := &batchCopTask{storeAddr: addr}
+		for i := 0; i < regionCount; i++ {
+			task.regionInfos = append(task.regionInfos, RegionInfo{Region: tikv.NewRegionVerID(uint64(i), 0, 0)})
+		}
+		return task
+	}
+	tasks := splitBatchCopTasksBySize([]*batchCopTask{newTask("store1", 7), newTask("store2", 3)}, 3)
+	require.Len(t, tasks, 4)
+	for i, regionCount := range []int{3, 3, 1, 3} {
+		require.Len(t, tasks[i].regionInfos, regionCount)
+	}
+	require.Equal(t, "store1", tasks[2].storeAddr)
+	require.Equal(t, uint64(6), tasks[2].regionInfos[0].Region.GetID())
+	require.Equal(t, "store2", tasks[3].storeAddr)
+	require.Equal(t, 10, calcReginCount(tasks))
+
+	// Appending to a split task doesn't overwrite the regions of the next one.
+	tasks[0].regionInfos = append(tasks[0].regionInfos, RegionInfo{})
+	require.Equal(t, uint64(3), tasks[1].regionInfos[0].Region.GetID())
+}
+
+func TestBatchCopCancelOnKill(t *testing.T) {
+	killed := uint32(0)
+	it := &batchCopIterator{
+		req:       &kv.Request{},
+		finishCh:  make(chan struct{}),
+		respChan:  make(chan *batchCopResponse, 1),
+		vars:      tikvstore.NewVariables(&killed),
+		rpcCancel: tikv.NewRPCanceller(),
+	}
+	streamCtx, cancel := it.rpcCancel.WithCancel(context.Background())
+	defer cancel()
+
+	atomic.StoreUint32(&killed, 1)
+	_, err := it.Next(context.Background())
+	require.True(t, derr.ErrQueryInterrupted.Equal(err))
+	// The workers are stopped and the in-flight streams are cancelled.
+	select {
+	case <-it.finishCh:
+	default:
+		require.Fail(t, "the workers are not stopped")
+	}
+	require.Error(t, streamCtx.Err())
+	require.NoError(t, it.Close())
+}
+
+// aliveProbeClient answers the probes of the TiFlash stores, the stores in down don't respond.
+type aliveProbeClient struct {
+	tikv.Client
+	down map[string]bool
+}
+
+func (c *aliveProbeClient) SendRequest(ctx context.Context, addr string, req *tikvrpc.Request, timeout time.Duration) (*tikvrpc.Response, error) {
+	if req.Type != tikvrpc.CmdMPPAlive {
+		return c.Client.SendRequest(ctx, addr, req, timeout)
+	}
+	if c.down[addr] {
+		return nil, errors.New("mock store down")
+	}
+	return &tikvrpc.Response{Resp: &mpp.IsAliveResponse{Available: true}}, nil
+}
+
+// batchCopTestStore is the mock store of the batch cop tests, the TiFlash stores tiflash0, tiflash1... in it hold a
+// peer of every region.
+type batchCopTestStore struct {
+	*tikv.KVStore
+	cluster   *testutils.MockCluster
+	regionIDs []uint64
+	storeIDs  []uint64
+	probe     *aliveProbeClient
+}
+
+// newBatchCopTestStore creates a mock store with regionCount regions split at "g", "n" and "t", and tiflashStores
+// TiFlash stores. The probes of the TiFlash stores are answered by probe, the other requests are sent by the client
+// returned by wrapClient, or the mock client if wrapClient is nil. The store is closed once the test ends.
+func newBatchCopTestStore(t testing.TB, regionCount, tiflashStores int, wrapClient func(tikv.Client) tikv.Client) *batchCopTestStore {
+	splitKeys := [][]byte{[]byte("g"), []byte("n"), []byte("t")}
+	require.LessOrEqual(t, regionCount, len(splitKeys)+1)
+	mockClient, cluster, pdClient, err := testutils.NewMockTiKV("", nil)
+	require.NoError(t, err)
+	_, regionIDs, _ := testutils.BootstrapWithMultiRegions(cluster, splitKeys[:regionCount-1]...)
+	mock := &batchCopTestStore{cluster: cluster, regionIDs: regionIDs}
+	for i := 0; i < tiflashStores; i++ {
+		storeID := cluster.AllocID()
+		mock.storeIDs = append(mock.storeIDs, storeID)
+		cluster.AddStore(storeID, "tiflash"+strconv.Itoa(i), &metapb.StoreLabel{Key: "engine", Value: "tiflash"})
+		for _, regionID := range regionIDs {
+			cluster.AddPeer(regionID, storeID, cluster.AllocID())
+		}
+	}
+	var client tikv.Client = mockClient
+	if wrapClient != nil {
+		client = wrapClient(mockClient)
+	}
+	mock.probe = &aliveProbeClient{Client: client, down: make(map[string]bool)}
+	mock.KVStore, err = tikv.NewTestTiKVStore(mock.probe, pdClient, nil, nil, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, mock.KVStore.Close())
+	})
+	return mock
+}
+
+// removePeer removes the peer of the region in the store. Note that Cluster.RemovePeer takes the peer ID actually.
+func (mock *batchCopTestStore) removePeer(regionID, storeID uint64) {
+	region, _ := mock.cluster.GetRegion(regionID)
+	for _, peer := range region.Peers {
+		if peer.StoreId == storeID {
+			mock.cluster.RemovePeer(regionID, peer.Id)
+		}
+	}
+}
+
+func TestBalanceBatchCopTaskAvoidOfflineStores(t *testing.T) {
+	// nil --- 'g' --- 'n' --- nil
+	// <-  0  -> <- 1 -> <- 2 ->
+	mock := newBatchCopTestStore(t, 3, 2, nil)
+	bo := backoff.NewBackofferWithVars(context.Background(), 3000, nil)
+
+	store := &kvStore{store: mock.KVStore}
+	tasks, err := buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	offlineAddr := tasks[0].storeAddr
+	// Building the tasks doesn't load the states of the stores, the background loop does.
+	require.Nil(t, store.pdStores.stores.Load())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		store.pdStores.run(ctx, mock.KVStore.GetPDClient())
+	}()
+	require.Eventually(t, func() bool {
+		return store.pdStores.stores.Load() != nil
+	}, 3*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+	require.True(t, store.pdStores.isAvailable(mock.storeIDs[0]))
+
+	// All the regions are moved to the other store, including the first one.
+	store = &kvStore{store: mock.KVStore}
+	var stores []*metapb.Store
+	for i, storeID := range mock.storeIDs {
+		s := &metapb.Store{Id: storeID, Address: "tiflash" + strconv.Itoa(i)}
+		if s.Address == offlineAddr {
+			s.State = metapb.StoreState_Offline
+		}
+		stores = append(stores, s)
+	}
+	store.pdStores.update(stores)
+	tasks, err = buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.NotEqual(t, offlineAddr, tasks[0].storeAddr)
+	require.Len(t, tasks[0].regionInfos, 3)
+
+	// The regions stay in the offline store if there is no other one.
+	for _, s := range stores {
+		s.State = metapb.StoreState_Offline
+	}
+	store = &kvStore{store: mock.KVStore}
+	store.pdStores.update(stores)
+	tasks, err = buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 3, calcReginCount(tasks))
+}
+
+func TestBatchCopTasksAvoidDrainedStores(t *testing.T) {
+	// nil --- 'g' --- 'n' --- nil
+	// <-  0  -> <- 1 -> <- 2 ->
+	mock := newBatchCopTestStore(t, 3, 2, nil)
+	bo := backoff.NewBackofferWithVars(context.Background(), 3000, nil)
+
+	store := &kvStore{store: mock.KVStore}
+	var stores []*metapb.Store
+	for i, storeID := range mock.storeIDs {
+		stores = append(stores, &metapb.Store{Id: storeID, Address: "tiflash" + strconv.Itoa(i)})
+	}
+	store.pdStores.update(stores)
+	tasks, err := buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	drainedAddr := tasks[0].storeAddr
+
+	// The cached layout isn't reused once its store is labeled to be drained.
+	for _, s := range stores {
+		if s.Address == drainedAddr {
+			s.Labels = []*metapb.StoreLabel{{Key: drainLabelKey, Value: "true"}}
+		}
+	}
+	store.pdStores.update(stores)
+	tasks, err = buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.NotEqual(t, drainedAddr, tasks[0].storeAddr)
+	require.Len(t, tasks[0].regionInfos, 3)
+
+	// The tombstone stores take no task either.
+	for _, s := range stores {
+		s.Labels = nil
+		if s.Address == drainedAddr {
+			s.State = metapb.StoreState_Tombstone
+		}
+	}
+	store = &kvStore{store: mock.KVStore}
+	store.pdStores.update(stores)
+	tasks, err = buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.NotEqual(t, drainedAddr, tasks[0].storeAddr)
+
+	// The MPP tasks without ranges are not sent to the drained stores.
+	mppTasks := (&MPPClient{store: store}).selectMPPStores(context.Background(), &kv.MPPBuildTasksRequest{}, nil, 0)
+	require.Len(t, mppTasks, 1)
+	require.NotEqual(t, drainedAddr, mppTasks[0].(*batchCopTask).storeAddr)
+	for _, s := range stores {
+		s.State = metapb.StoreState_Offline
+	}
+	store.pdStores.update(stores)
+	require.Len(t, (&MPPClient{store: store}).selectMPPStores(context.Background(), &kv.MPPBuildTasksRequest{}, nil, 0), 2)
+}
+
+func TestBalanceBatchCopTaskAvoidDownStores(t *testing.T) {
+	// nil --- 'g' --- 'n' --- nil
+	// <-  0  -> <- 1 -> <- 2 ->
+	mock := newBatchCopTestStore(t, 3, 2, nil)
+	bo := backoff.NewBackofferWithVars(context.Background(), 3000, nil)
+
+	store := &kvStore{store: mock.KVStore}
+	tasks, err := buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	downAddr := tasks[0].storeAddr
+	store.probeTiFlashStores(context.Background())
+	require.False(t, store.health.isDown(downAddr))
+
+	// The stores are not probed while the tasks are built, the result of the last probe is read.
+	mock.probe.down[downAddr] = true
+	tasks, err = buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "p", "q"), kv.TiFlash, batchCopTaskOptions{})
+	require.NoError(t, err)
+	require.Equal(t, downAddr, tasks[0].storeAddr)
+
+	// All the regions are moved to the store that is alive once the store is found down, the cached layout is not
+	// used either.
+	store.probeTiFlashStores(context.Background())
+	require.True(t, store.health.isDown(downAddr))
+	tasks, err = buildBatchCopTasks(bo, store, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.NotEqual(t, downAddr, tasks[0].storeAddr)
+	require.Len(t, tasks[0].regionInfos, 3)
+}
+
+func TestBalanceBatchCopTaskByRegionSize(t *testing.T) {
+	// nil --- 'g' --- 'n' --- 't' --- nil
+	// <-  0  -> <- 1 -> <- 2 -> <- 3 ->
+	mock := newBatchCopTestStore(t, 4, 2, nil)
+	bo := backoff.NewBackofferWithVars(context.Background(), 3000, nil)
+
+	store := &kvStore{store: mock.KVStore}
+	tasks, err := buildBatchCopTasks(bo, store, buildCopRanges("a", "z"), kv.TiFlash, batchCopTaskOptions{})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.Len(t, tasks[0].regionInfos, 4)
+	// The stats of the regions are requested, but they're not loaded until the next load in the background.
+	require.Nil(t, store.regionStats.snapshot())
+	require.Equal(t, regionStatsRequest{limit: 8}, store.regionStats.pending)
+	// The first region is large, it stays in the first store with two small ones, the last small region is in
+	// the other store.
+	var stats []helper.RegionInfo
+	for i, ri := range tasks[0].regionInfos {
+		stat := helper.RegionInfo{ID: int64(ri.Region.GetID()), ApproximateSize: 10, ApproximateKeys: 10}
+		stat.Epoch.Version = int64(ri.Region.GetVer())
+		if i == 0 {
+			stat.ApproximateSize, stat.ApproximateKeys = 1000, 1000
+		}
+		stats = append(stats, stat)
+	}
+	store.regionStats.update(stats, time.Now())
+	last := tasks[0].regionInfos[3]
+	last.AllStores = []uint64{last.AllStores[1], last.AllStores[0]}
+	var otherStore *tikv.Store
+	for _, s := range store.GetRegionCache().RegionCache.GetTiFlashStores() {
+		if s.StoreID() == last.AllStores[0] {
+			otherStore = s
+		}
+	}
+	require.NotNil(t, otherStore)
+	originalTasks := []*batchCopTask{
+		{storeAddr: tasks[0].storeAddr, cmdType: tasks[0].cmdType, ctx: tasks[0].ctx, regionInfos: tasks[0].regionInfos[:3]},
+		{storeAddr: otherStore.GetAddr(), cmdType: tasks[0].cmdType, ctx: &tikv.RPCContext{Addr: otherStore.GetAddr(), Store: otherStore}, regionInfos: []RegionInfo{last}},
+	}
+
+	// The small regions are all put into the other store, whose data is much less.
+	balancedTasks := balanceBatchCopTask(context.Background(), store, originalTasks, nil, time.Second, false, 0, "")
+	require.Len(t, balancedTasks, 2)
+	for _, task := range balancedTasks {
+		if task.storeAddr == tasks[0].storeAddr {
+			require.Len(t, task.regionInfos, 1)
+			require.Equal(t, mock.regionIDs[0], task.regionInfos[0].Region.GetID())
+		} else {
+			require.Len(t, task.regionInfos, 3)
+		}
+	}
+}
+
+// batchCopStubClient answers the batch cop requests by handle and counts them, the other requests are sent to the
+// mock TiKV.
+type batchCopStubClient struct {
+	tikv.Client
+	requests int32
+	// handle returns the response of the nth batch cop request.
+	handle func(ctx context.Context, addr string, req *tikvrpc.Request, n int32) *tikvrpc.BatchCopStreamResponse
+}
+
+func (c *batchCopStubClient) SendRequest(ctx context.Context, addr string, req *tikvrpc.Request, timeout time.Duration) (*tikvrpc.Response, error) {
+	if req.Type != tikvrpc.CmdBatchCop {
+		return c.Client.SendRequest(ctx, addr, req, timeout)
+	}
+	n := atomic.AddInt32(&c.requests, 1)
+	return &tikvrpc.Response{Resp: c.handle(ctx, addr, req, n)}, nil
+}
+
+func (c *batchCopStubClient) wrap(client tikv.Client) tikv.Client {
+	c.Client = client
+	return c
+}
+
+// newBatchCopStubStore creates a store of the regions and the TiFlash stores, whose batch cop requests are answered
+// by handle.
+func newBatchCopStubStore(t *testing.T, regionCount, tiflashStores int, handle func(ctx context.Context, addr string, req *tikvrpc.Request, n int32) *tikvrpc.BatchCopStreamResponse) (*Store, *batchCopStubClient) {
+	client := &batchCopStubClient{handle: handle}
+	mock := newBatchCopTestStore(t, regionCount, tiflashStores, client.wrap)
+	store, err := NewStore(mock.KVStore, nil)
+	require.NoError(t, err)
+	t.Cleanup(store.Close)
+	return store, client
+}
+
+// dataStream returns the responses one by one, and then err, or io.EOF if err is nil.
+type dataStream struct {
+	tikvpb.Tikv_BatchCoprocessorClient
+	responses []*coprocessor.BatchResponse
+	err       error
+}
+
+func (s *dataStream) Recv() (*coprocessor.BatchResponse, error) {
+	if len(s.responses) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	resp := s.responses[0]
+	s.responses = s.responses[1:]
+	return resp, nil
+}
+
+// streamResponse returns the stream with a response for each piece of the data.
+func streamResponse(data ...string) *tikvrpc.BatchCopStreamResponse {
+	stream := &dataStream{}
+	for _, d := range data {
+		stream.responses = append(stream.responses, &coprocessor.BatchResponse{Data: []byte(d)})
+	}
+	first, _ := stream.Recv()
+	return &tikvrpc.BatchCopStreamResponse{
+		Tikv_BatchCoprocessorClient: stream,
+		BatchResponse:               first,
+	}
+}
+
+// blockedStream blocks until its context is done, like a store still computing the result.
+type blockedStream struct {
+	tikvpb.Tikv_BatchCoprocessorClient
+	ctx context.Context
+}
+
+func (s *blockedStream) Recv() (*coprocessor.BatchResponse, error) {
+	<-s.ctx.Done()
+	return nil, s.ctx.Err()
+}
+
+// readBatchCop sends the batch cop request and returns the data of all its responses.
+func readBatchCop(ctx context.Context, t *testing.T, copClient *CopClient, req *kv.Request) ([]string, error) {
+	resp := copClient.sendBatch(ctx, req, nil, &kv.ClientSendOption{})
+	defer func() {
+		require.NoError(t, resp.Close())
+	}()
+	var data []string
+	for {
+		subset, err := resp.Next(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		if subset == nil {
+			return data, nil
+		}
+		data = append(data, string(subset.GetData()))
+	}
+}
+
+func TestBatchCopConcurrency(t *testing.T) {
+	// nil --- 'g' --- 'n' --- nil
+	// <-  0  -> <- 1 -> <- 2 ->
+	var running, maxRunning int32
+	store, _ := newBatchCopStubStore(t, 3, 1, func(ctx context.Context, addr string, req *tikvrpc.Request, n int32) *tikvrpc.BatchCopStreamResponse {
+		r := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if r <= m || atomic.CompareAndSwapInt32(&maxRunning, m, r) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return &tikvrpc.BatchCopStreamResponse{}
+	})
+	bo := backoff.NewBackofferWithVars(context.Background(), 3000, nil)
+
+	for _, concurrency := range []int{1, 2} {
+		tasks, err := buildBatchCopTasks(bo, store.kvStore, buildCopRanges("a", "c", "h", "i", "o", "p"), kv.TiFlash, batchCopTaskOptions{})
+		require.NoError(t, err)
+		tasks = splitBatchCopTasksBySize(tasks, 1)
+		require.Len(t, tasks, 3)
+		it := &batchCopIterator{
+			store:            store.kvStore,
+			req:              &kv.Request{Tp: kv.ReqTypeDAG},
+			finishCh:         make(chan struct{}),
+			tasks:            tasks,
+			storeConcurrency: 3,
+			taskRate:         util.NewRateLimit(concurrency),
+			respChan:         make(chan *batchCopResponse, 3),
+			rpcCancel:        tikv.NewRPCanceller(),
+		}
+		atomic.StoreInt32(&maxRunning, 0)
+		it.run(context.Background())
+		for resp := range it.respChan {
+			require.NoError(t, resp.err)
+		}
+		require.Equal(t, int32(concurrency), atomic.LoadInt32(&maxRunning))
+	}
+}
+
+func TestBatchCopCache(t *testing.T) {
+	// The stores answer every request with its start ts.
+	var sent atomic.Value
+	client := &batchCopStubClient{handle: func(ctx context.Context, addr string, req *tikvrpc.Request, n int32) *tikvrpc.BatchCopStreamResponse {
+		sent.Store(req.BatchCop())
+		return streamResponse(strconv.FormatUint(req.BatchCop().StartTs, 10))
+	}}
+	mock := newBatchCopTestStore(t, 2, 1, client.wrap)
+	store, err := NewStore(mock.KVStore, &config.CoprocessorCache{CapacityMB: 1, AdmissionMaxResultMB: 1, AdmissionMaxRanges: 10})
+	require.NoError(t, err)
+	defer store.Close()
+	copClient := store.GetClient().(*CopClient)
+
+	read := func(startTS uint64, cacheable bool) string {
+		data, err := readBatchCop(context.Background(), t, copClient, &kv.Request{
+			Tp:          kv.ReqTypeDAG,
+			StartTs:     startTS,
+			KeyRanges:   buildKeyRanges("a", "c", "h", "i"),
+			StoreType:   kv.TiFlash,
+			Concurrency: 1,
+			Cacheable:   cacheable,
+		})
+		require.NoError(t, err)
+		return strings.Join(data, "")
+	}
+
+	require.Equal(t, "10", read(10, true))
+	require.Equal(t, int32(1), atomic.LoadInt32(&client.requests))
+	// The cache is set asynchronously.
+	key, err := coprCacheBuildBatchKey(sent.Load().(*coprocessor.BatchRequest))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return store.coprCache.Get(key) != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The same snapshot is read from the cache.
+	require.Equal(t, "10", read(10, true))
+	require.Equal(t, int32(1), atomic.LoadInt32(&client.requests))
+
+	// The other snapshots or the uncacheable requests are sent to TiFlash.
+	require.Equal(t, "11", read(11, true))
+	require.Equal(t, int32(2), atomic.LoadInt32(&client.requests))
+	require.Equal(t, "10", read(10, false))
+	require.Equal(t, int32(3), atomic.LoadInt32(&client.requests))
+}
+
+func TestBatchCopResourceGroupTag(t *testing.T) {
+	// nil --- 'g' --- nil
+	// The stores answer every request with the resource group tag in its context.
+	store, _ := newBatchCopStubStore(t, 2, 1, func(ctx context.Context, addr string, req *tikvrpc.Request, n int32) *tikvrpc.BatchCopStreamResponse {
+		return streamResponse(string(req.BatchCop().GetContext().GetResourceGroupTag()))
+	})
+	copClient := store.GetClient().(*CopClient)
+	req := &kv.Request{
+		Tp:          kv.ReqTypeDAG,
+		KeyRanges:   buildKeyRanges("a", "c", "h", "i"),
+		StoreType:   kv.TiFlash,
+		Concurrency: 1,
+	}
+
+	// The tag of the statement is set to the context of the batch cop requests, so the stores can attribute the
+	// resources they use to it.
+	req.ResourceGroupTagger = func(req *tikvrpc.Request) {
+		req.ResourceGroupTag = []byte("tag")
+	}
+	tags, err := readBatchCop(context.Background(), t, copClient, req)
+	require.NoError(t, err)
+	require.Equal(t, []string{"tag"}, tags)
+	req.ResourceGroupTagger = nil
+	tags, err = readBatchCop(context.Background(), t, copClient, req)
+	require.NoError(t, err)
+	require.Equal(t, []string{""}, tags)
+}
+
+func TestBatchCopAvoidTimeoutStores(t *testing.T) {
+	// nil --- 'g' --- nil
+	// The stores answer every request with their addresses, the streams of the stores in timeout fail before
+	// returning any data.
+	timeout := map[string]bool{"tiflash0": true}
+	store, client := newBatchCopStubStore(t, 2, 2, func(ctx context.Context, addr string, req *tikvrpc.Request, n int32) *tikvrpc.BatchCopStreamResponse {
+		if timeout[addr] {
+			return &tikvrpc.BatchCopStreamResponse{
+				Tikv_BatchCoprocessorClient: &dataStream{err: errors.New("mock stream timeout")},
+				BatchResponse:               &coprocessor.BatchResponse{},
+			}
+		}
+		return streamResponse(addr)
+	})
+	copClient := store.GetClient().(*CopClient)
+
+	read := func(allowFallback bool) ([]string, error) {
+		data, err := readBatchCop(context.Background(), t, copClient, &kv.Request{
+			Tp:                  kv.ReqTypeDAG,
+			KeyRanges:           buildKeyRanges("a", "c", "h", "i"),
+			StoreType:           kv.TiFlash,
+			Concurrency:         1,
+			AllowFallbackToTiKV: allowFallback,
+		})
+		var addrs []string
+		for _, d := range data {
+			if len(d) > 0 {
+				addrs = append(addrs, d)
+			}
+		}
+		return addrs, err
+	}
+
+	// The store times out continuously, so the regions are sent to the other store again.
+	for i := 1; i < storeBreakTimeouts; i++ {
+		store.health.onTimeout("tiflash0", time.Now())
+	}
+	retries, reconnects := readCounter(t, batchCopRetryCounterStreamError), readCounter(t, tidbmetrics.DistSQLBatchCopStreamReconnectCounter)
+	addrs, err := read(false)
+	require.NoError(t, err)
+	require.NotEmpty(t, addrs)
+	for _, addr := range addrs {
+		require.Equal(t, "tiflash1", addr)
+	}
+	require.True(t, store.health.isBroken("tiflash0", time.Now()))
+	require.Equal(t, retries+1, readCounter(t, batchCopRetryCounterStreamError))
+	require.Equal(t, reconnects+1, readCounter(t, tidbmetrics.DistSQLBatchCopStreamReconnectCounter))
+
+	// The tasks fail fast if no store can serve the regions and the statement can fall back to TiKV.
+	for i := 0; i < storeBreakTimeouts; i++ {
+		store.health.onTimeout("tiflash1", time.Now())
+	}
+	requests := atomic.LoadInt32(&client.requests)
+	_, err = read(true)
+	require.True(t, derr.ErrTiFlashServerTimeout.Equal(err))
+	require.True(t, IsStoreUnavailable(err, kv.TiFlash))
+	require.Equal(t, requests, atomic.LoadInt32(&client.requests))
+
+	// The store that succeeds is used again.
+	timeout["tiflash0"] = false
+	store.health.onSuccess("tiflash0")
+	store.health.onSuccess("tiflash1")
+	_, err = read(true)
+	require.NoError(t, err)
+}
+
+func TestBatchCopTimeout(t *testing.T) {
+	// The stores block until the streams are cancelled, the streams having a deadline are counted.
+	var deadlines int32
+	store, _ := newBatchCopStubStore(t, 2, 1, func(ctx context.Context, addr string, req *tikvrpc.Request, n int32) *tikvrpc.BatchCopStreamResponse {
+		if _, ok := ctx.Deadline(); ok {
+			atomic.AddInt32(&deadlines, 1)
+		}
+		return &tikvrpc.BatchCopStreamResponse{
+			Tikv_BatchCoprocessorClient: &blockedStream{ctx: ctx},
+			BatchResponse:               &coprocessor.BatchResponse{},
+		}
+	})
+	copClient := store.GetClient().(*CopClient)
+
+	start := time.Now()
+	_, err := readBatchCop(context.Background(), t, copClient, &kv.Request{
+		Tp:          kv.ReqTypeDAG,
+		KeyRanges:   buildKeyRanges("a", "c", "h", "i"),
+		StoreType:   kv.TiFlash,
+		Concurrency: 1,
+		Timeout:     100 * time.Millisecond,
+	})
+	require.True(t, derr.ErrQueryInterrupted.Equal(err))
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.Greater(t, atomic.LoadInt32(&deadlines), int32(0))
+
+	// Running out of the time is not the fault of the store.
+	store.health.mu.Lock()
+	h := store.health.getLocked("tiflash0")
+	require.Equal(t, 0, h.timeouts)
+	require.Zero(t, h.errScore)
+	store.health.mu.Unlock()
+}
+
+func TestBatchCopStreamStall(t *testing.T) {
+	// The first request is answered by a stream blocking until it's cancelled by its lease, and the others by the
+	// address of the store.
+	store, client := newBatchCopStubStore(t, 2, 1, func(ctx context.Context, addr string, req *tikvrpc.Request, n int32) *tikvrpc.BatchCopStreamResponse {
+		if n == 1 {
+			ctx, cancel := context.WithCancel(ctx)
+			return &tikvrpc.BatchCopStreamResponse{
+				Tikv_BatchCoprocessorClient: &blockedStream{ctx: ctx},
+				BatchResponse:               &coprocessor.BatchResponse{},
+				Lease:                       tikvrpc.Lease{Cancel: cancel},
+			}
+		}
+		return streamResponse(addr)
+	})
+	copClient := store.GetClient().(*CopClient)
+
+	// The stalled stream is cancelled and the task is sent again.
+	stalls, reconnects := readCounter(t, tidbmetrics.DistSQLBatchCopStreamStallCounter), readCounter(t, tidbmetrics.DistSQLBatchCopStreamReconnectCounter)
+	start := time.Now()
+	data, err := readBatchCop(context.Background(), t, copClient, &kv.Request{
+		Tp:                           kv.ReqTypeDAG,
+		KeyRanges:                    buildKeyRanges("a", "c", "h", "i"),
+		StoreType:                    kv.TiFlash,
+		Concurrency:                  1,
+		BatchCopStreamStallThreshold: 50 * time.Millisecond,
+		BatchCopStreamStallReconnect: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "tiflash0", strings.Join(data, ""))
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.Equal(t, int32(2), atomic.LoadInt32(&client.requests))
+	require.Equal(t, stalls+1, readCounter(t, tidbmetrics.DistSQLBatchCopStreamStallCounter))
+	require.Equal(t, reconnects+1, readCounter(t, tidbmetrics.DistSQLBatchCopStreamReconnectCounter))
+
+	// The stalled stream is only reported if it's not reconnected, the request waits until its timeout.
+	atomic.StoreInt32(&client.requests, 0)
+	_, err = readBatchCop(context.Background(), t, copClient, &kv.Request{
+		Tp:                           kv.ReqTypeDAG,
+		KeyRanges:                    buildKeyRanges("a", "c", "h", "i"),
+		StoreType:                    kv.TiFlash,
+		Concurrency:                  1,
+		Timeout:                      300 * time.Millisecond,
+		BatchCopStreamStallThreshold: 50 * time.Millisecond,
+	})
+	require.True(t, derr.ErrQueryInterrupted.Equal(err))
+	require.Equal(t, int32(1), atomic.LoadInt32(&client.requests))
+	require.Equal(t, stalls+2, readCounter(t, tidbmetrics.DistSQLBatchCopStreamStallCounter))
+}
+
+func readCounter(t *testing.T, counter prometheus.Counter) float64 {
+	pb := &dto.Metric{}
+	require.NoError(t, counter.Write(pb))
+	return pb.GetCounter().GetValue()
+}
+
+func TestBatchCopTrace(t *testing.T) {
+	store, _ := newBatchCopStubStore(t, 2, 1, func(ctx context.Context, addr string, req *tikvrpc.Request, n int32) *tikvrpc.BatchCopStreamResponse {
+		return streamResponse("a", "b", "c", "d", "e")
+	})
+	copClient := store.GetClient().(*CopClient)
+
+	tracer := mocktracer.New()
+	root := tracer.StartSpan("root")
+	ctx := opentracing.ContextWithSpan(context.Background(), root)
+	_, err := readBatchCop(ctx, t, copClient, &kv.Request{
+		Tp:          kv.ReqTypeDAG,
+		KeyRanges:   buildKeyRanges("a", "c", "h", "i"),
+		StoreType:   kv.TiFlash,
+		Concurrency: 1,
+	})
+	require.NoError(t, err)
+	root.Finish()
+
+	spans := make(map[string][]*mocktracer.MockSpan)
+	for _, span := range tracer.FinishedSpans() {
+		spans[span.OperationName] = append(spans[span.OperationName], span)
+	}
+	rootID := root.Context().(mocktracer.MockSpanContext).SpanID
+	require.Len(t, spans[batchCopBuildTasksSpan], 1)
+	build := spans[batchCopBuildTasksSpan][0]
+	require.Equal(t, rootID, build.ParentID)
+	require.Equal(t, 2, build.Tag(batchCopSpanTagRangeCount))
+	require.Equal(t, 1, build.Tag(batchCopSpanTagTaskCount))
+	require.Equal(t, 2, build.Tag(batchCopSpanTagRegionCount))
+
+	// The stream of the task is traced as the child of the task.
+	require.Len(t, spans[batchCopTaskSpan], 1)
+	require.Len(t, spans[batchCopStreamSpan], 1)
+	task, stream := spans[batchCopTaskSpan][0], spans[batchCopStreamSpan][0]
+	require.Equal(t, rootID, task.ParentID)
+	require.Equal(t, "tiflash0", task.Tag(batchCopSpanTagStoreAddr))
+	require.Equal(t, 2, task.Tag(batchCopSpanTagRegionCount))
+	require.Nil(t, task.Tag(batchCopSpanTagError))
+	require.Equal(t, task.SpanContext.SpanID, stream.ParentID)
+	require.Equal(t, "tiflash0", stream.Tag(batchCopSpanTagStoreAddr))
+	require.Equal(t, 5, stream.Tag(batchCopSpanTagRespCount))
+	require.Equal(t, 5, stream.Tag(batchCopSpanTagBytes))
+}
+
+func TestBatchCopStaleRead(t *testing.T) {
+	var staleReads []bool
+	store, _ := newBatchCopStubStore(t, 2, 1, func(ctx context.Context, addr string, req *tikvrpc.Request, n int32) *tikvrpc.BatchCopStreamResponse {
+		staleReads = append(staleReads, req.BatchCop().GetContext().GetStaleRead())
+		return streamResponse(strconv.FormatUint(req.BatchCop().StartTs, 10))
+	})
+	copClient := store.GetClient().(*CopClient)
+
+	for _, isStaleness := range []bool{true, false} {
+		_, err := readBatchCop(context.Background(), t, copClient, &kv.Request{
+			Tp:               kv.ReqTypeDAG,
+			StartTs:          10,
+			KeyRanges:        buildKeyRanges("a", "c", "h", "i"),
+			StoreType:        kv.TiFlash,
+			Concurrency:      1,
+			IsStaleness:      isStaleness,
+			ReadReplicaScope: kv.GlobalReplicaScope,
+		})
+		require.NoError(t, err)
+	}
+	require.Equal(t, []bool{true, false}, staleReads)
+}
+
+func TestMPPTasksWithoutRangesSelectStores(t *testing.T) {
+	mockClient, cluster, pdClient, err := testutils.NewMockTiKV("", nil)
+	require.NoError(t, err)
+	_, _, regionID := testutils.BootstrapWithSingleStore(cluster)
+	for i := 0; i < 2; i++ {
+		storeID := cluster.AllocID()
+		cluster.AddStore(storeID, "tiflash"+strconv.Itoa(i), &metapb.StoreLabel{Key: "engine", Value: "tiflash"},
+			&metapb.StoreLabel{Key: "zone", Value: "z" + strconv.Itoa(i)})
+		cluster.AddPeer(regionID, storeID, cluster.AllocID())
+	}
+	client := &aliveProbeClient{Client: mockClient, down: make(map[string]bool)}
+	tikvStore, err := tikv.NewTestTiKVStore(client, pdClient, nil, nil, 0)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, tikvStore.Close())
+	}()
+	store := &kvStore{store: tikvStore}
+	c := &MPPClient{store: store}
+	ctx := context.Background()
+	// Load the TiFlash stores into the region cache.
+	bo := backoff.NewBackofferWithVars(ctx, 3000, nil)
+	_, err = buildBatchCopTasks(bo, store, buildCopRanges("a", "z"), kv.TiFlash, batchCopTaskOptions{})
+	require.NoError(t, err)
+	require.Len(t, c.selectMPPStores(ctx, &kv.MPPBuildTasksRequest{}, make(map[string]time.Time), 0), 2)
+
+	// The stores in the preferred zone are preferred.
+	tasks := c.selectMPPStores(ctx, &kv.MPPBuildTasksRequest{PreferredZone: "z1"}, nil, 0)
+	require.Len(t, tasks, 1)
+	require.Equal(t, "tiflash1", tasks[0].GetAddress())
+	require.Len(t, c.selectMPPStores(ctx, &kv.MPPBuildTasksRequest{PreferredZone: "z9"}, nil, 0), 2)
+
+	// The stores not ready to serve are detected like the stores of the MPP tasks with ranges.
+	client.down["tiflash1"] = true
+	failTime := make(map[string]time.Time)
+	tasks = c.selectMPPStores(ctx, &kv.MPPBuildTasksRequest{PreferredZone: "z1"}, failTime, time.Minute)
+	require.Len(t, tasks, 1)
+	require.Equal(t, "tiflash0", tasks[0].GetAddress())
+	require.Contains(t, failTime, "tiflash1")
+
+	// The tasks stay in the stores if none of them is ready.
+	client.down["tiflash0"] = true
+	require.Len(t, c.selectMPPStores(ctx, &kv.MPPBuildTasksRequest{}, make(map[string]time.Time), 0), 2)
+}
+
+func TestMPPTaskCheckRegionEpochs(t *testing.T) {
+	// nil --- 'g' --- 'n' --- nil
+	// <-  0  -> <- 1 -> <- 2 ->
+	mock := newBatchCopTestStore(t, 3, 1, nil)
+	store := &kvStore{store: mock.KVStore}
+	bo := backoff.NewBackofferWithVars(context.Background(), 3000, nil)
+	tasks, err := buildBatchCopTasks(bo, store, buildCopRanges("a", "z"), kv.TiFlash, batchCopTaskOptions{
+		mppStoreLastFailTime:         make(map[string]time.Time),
+		balanceWithContinuity:        true,
+		balanceContinuousRegionCount: 20,
+	})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	task := tasks[0]
+	require.Len(t, task.regionInfos, 3)
+	m := &mppIterator{store: store}
+
+	// The task is dispatched as is if its regions are not changed.
+	require.NoError(t, m.checkRegionEpochs(bo, task))
+	require.Len(t, task.regionInfos, 3)
+
+	split := func(regionID uint64, key string) uint64 {
+		region, leaderID := mock.cluster.GetRegion(regionID)
+		newRegionID := mock.cluster.AllocID()
+		peerIDs := mock.cluster.AllocIDs(len(region.Peers))
+		leaderPeerID := peerIDs[0]
+		for i, peer := range region.Peers {
+			if peer.Id == leaderID {
+				leaderPeerID = peerIDs[i]
+			}
+		}
+		mock.cluster.Split(regionID, newRegionID, []byte(key), peerIDs, leaderPeerID)
+		// TiFlash reports the stale region after the split.
+		for _, ri := range task.regionInfos {
+			if ri.Region.GetID() == regionID {
+				store.GetRegionCache().InvalidateCachedRegion(ri.Region)
+			}
+		}
+		return newRegionID
+	}
+
+	// The split region is located again.
+	split(mock.regionIDs[1], "k")
+	require.NoError(t, m.checkRegionEpochs(bo, task))
+	require.Len(t, task.regionInfos, 4)
+
+	// The task fails if a located region has no replica in its store.
+	newRegionID := split(mock.regionIDs[1], "i")
+	region, _ := mock.cluster.GetRegion(newRegionID)
+	for _, peer := range region.Peers {
+		if peer.StoreId == mock.storeIDs[0] {
+			mock.cluster.RemovePeer(newRegionID, peer.Id)
+		}
+	}
+	err = m.checkRegionEpochs(bo, task)
+	require.Error(t, err)
+	require.True(t, IsRetryable(err))
+	require.True(t, errors.ErrorEqual(err, derr.ErrRegionUnavailable))
+}
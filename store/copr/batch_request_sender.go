@@ -20,6 +20,7 @@ import (
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/kvproto/pkg/metapb"
+	derr "github.com/pingcap/tidb/store/driver/error"
 	tikverr "github.com/tikv/client-go/v2/error"
 	"github.com/tikv/client-go/v2/tikv"
 	"github.com/tikv/client-go/v2/tikvrpc"
@@ -39,6 +40,8 @@ type RegionInfo struct {
 type RegionBatchRequestSender struct {
 	*tikv.RegionRequestSender
 	enableCollectExecutionInfo bool
+	// deadline is the gRPC deadline of the requests, zero means there is no deadline.
+	deadline time.Time
 }
 
 // NewRegionBatchRequestSender creates a RegionBatchRequestSender object.
@@ -59,6 +62,16 @@ func (ss *RegionBatchRequestSender) SendReqToAddr(bo *Backoffer, rpcCtx *tikv.RP
 	if rawHook := ctx.Value(tikv.RPCCancellerCtxKey{}); rawHook != nil {
 		ctx, cancel = rawHook.(*tikv.RPCCanceller).WithCancel(ctx)
 	}
+	if !ss.deadline.IsZero() {
+		// The deadline covers the whole stream, which is cancelled by the caller after it's read.
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadline(ctx, ss.deadline)
+		cancelRPC := cancel
+		cancel = func() {
+			cancelRPC()
+			cancelDeadline()
+		}
+	}
 	start := time.Now()
 	resp, err = ss.GetClient().SendRequest(ctx, rpcCtx.Addr, req, timout)
 	if ss.Stats != nil && ss.enableCollectExecutionInfo {
@@ -66,7 +79,14 @@ func (ss *RegionBatchRequestSender) SendReqToAddr(bo *Backoffer, rpcCtx *tikv.RP
 	}
 	if err != nil {
 		cancel()
+		if !ss.deadline.IsZero() && !time.Now().Before(ss.deadline) {
+			return nil, false, func() {}, derr.ErrQueryInterrupted
+		}
 		ss.SetRPCError(err)
+		if isBatchCopUnsupportedErr(err) {
+			// The store is healthy but doesn't implement the request, retrying it doesn't help.
+			return nil, false, func() {}, errors.Trace(err)
+		}
 		e := ss.onSendFailForBatchRegions(bo, rpcCtx, regionInfos, err)
 		if e != nil {
 			return nil, false, func() {}, errors.Trace(e)
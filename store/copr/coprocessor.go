@@ -36,7 +36,6 @@ import (
 	"github.com/pingcap/tidb/kv"
 	tidbmetrics "github.com/pingcap/tidb/metrics"
 	"github.com/pingcap/tidb/parser/terror"
-	"github.com/pingcap/tidb/store/driver/backoff"
 	derr "github.com/pingcap/tidb/store/driver/error"
 	"github.com/pingcap/tidb/store/driver/options"
 	"github.com/pingcap/tidb/util/execdetails"
@@ -56,7 +55,7 @@ import (
 
 var coprCacheHistogramEvict = tidbmetrics.DistSQLCoprCacheHistogram.WithLabelValues("evict")
 
-// Maximum total sleep time(in ms) for kv/cop commands.
+// Maximum total sleep time(in ms) for kv/cop commands, unless a BackoffPolicy is set for the store type.
 const (
 	copBuildTaskMaxBackoff = 5000
 	copNextMaxBackoff      = 20000
@@ -78,7 +77,7 @@ func (c *CopClient) Send(ctx context.Context, req *kv.Request, variables interfa
 	if !ok {
 		return copErrorResponse{errors.Errorf("unsupported variables:%+v", variables)}
 	}
-	if req.StoreType == kv.TiFlash && req.BatchCop {
+	if req.StoreType == kv.TiFlash && req.BatchCop || useTiKVBatchCop(req) {
 		logutil.BgLogger().Debug("send batch requests")
 		return c.sendBatch(ctx, req, vars, option)
 	}
@@ -86,7 +85,7 @@ func (c *CopClient) Send(ctx context.Context, req *kv.Request, variables interfa
 		return copErrorResponse{errors.New("streaming and paging are both on")}
 	}
 	ctx = context.WithValue(ctx, tikv.TxnStartKey(), req.StartTs)
-	bo := backoff.NewBackofferWithVars(ctx, copBuildTaskMaxBackoff, vars)
+	bo := c.store.newBuildTaskBackoffer(ctx, req.StoreType, vars)
 	ranges := NewKeyRanges(req.KeyRanges)
 	tasks, err := buildCopTasks(bo, c.store.GetRegionCache(), ranges, req, eventCb)
 	if err != nil {
@@ -157,6 +156,8 @@ type copTask struct {
 	eventCb    trxevents.EventCallback
 	paging     bool
 	pagingSize uint64
+	// taskID is the task ID sent in the requests of the task, it's allocated when the task is sent at first.
+	taskID uint64
 }
 
 func (r *copTask) String() string {
@@ -164,6 +165,22 @@ func (r *copTask) String() string {
 		r.region.GetID(), r.region.GetConfVer(), r.region.GetVer(), r.ranges.Len(), r.storeAddr)
 }
 
+func (r *copTask) getTaskID(req *kv.Request) uint64 {
+	if r.taskID == 0 {
+		r.taskID = allocCopTaskID(req)
+	}
+	return r.taskID
+}
+
+// allocCopTaskID allocates the task ID of a cop task, which is TaskID of the request plus the sequence number of
+// the task if the sequence numbers are traced, otherwise the cop tasks share TaskID of the request.
+func allocCopTaskID(req *kv.Request) uint64 {
+	if req.CopTaskSeqAllocator == nil {
+		return req.TaskID
+	}
+	return req.TaskID + req.CopTaskSeqAllocator()
+}
+
 // rangesPerTask limits the length of the ranges slice sent in one copTask.
 const rangesPerTask = 25000
 
@@ -513,7 +530,7 @@ func (it *copIterator) recvFromRespCh(ctx context.Context, respCh <-chan *copRes
 			return
 		case <-ticker.C:
 			if atomic.LoadUint32(it.vars.Killed) == 1 {
-				resp = &copResponse{err: derr.ErrQueryInterrupted}
+				resp = &copResponse{err: &QueryCancelled{err: derr.ErrQueryInterrupted}}
 				ok = true
 				return
 			}
@@ -647,7 +664,7 @@ func chooseBackoffer(ctx context.Context, backoffermap map[uint64]*Backoffer, ta
 	if ok {
 		return bo
 	}
-	newbo := backoff.NewBackofferWithVars(ctx, copNextMaxBackoff, worker.vars)
+	newbo := worker.store.newTaskBackoffer(ctx, worker.req.StoreType, worker.vars)
 	backoffermap[task.region.GetID()] = newbo
 	return newbo
 }
@@ -672,7 +689,7 @@ func (worker *copIteratorWorker) handleTask(ctx context.Context, task *copTask,
 		bo := chooseBackoffer(ctx, backoffermap, curTask, worker)
 		tasks, err := worker.handleTaskOnce(bo, curTask, respCh)
 		if err != nil {
-			resp := &copResponse{err: errors.Trace(err)}
+			resp := &copResponse{err: classifyErr(errors.Trace(err), curTask.storeType, curTask.storeAddr)}
 			worker.sendToRespCh(resp, respCh, true)
 			return
 		}
@@ -698,6 +715,11 @@ func (worker *copIteratorWorker) handleTaskOnce(bo *Backoffer, task *copTask, ch
 			failpoint.Return(nil, errors.New("mock handleTaskOnce error"))
 		}
 	})
+	failpoint.Inject("handleTaskOnceTransientError", func(val failpoint.Value) {
+		if val.(bool) {
+			failpoint.Return(nil, derr.ErrTiKVServerTimeout)
+		}
+	})
 
 	copReq := coprocessor.Request{
 		Tp:         worker.req.Tp,
@@ -733,13 +755,18 @@ func (worker *copIteratorWorker) handleTaskOnce(bo *Backoffer, task *copTask, ch
 		}
 	}
 
+	timeout, err := rpcTimeout(worker.req, tikv.ReadTimeoutMedium)
+	if err != nil {
+		return nil, err
+	}
 	req := tikvrpc.NewReplicaReadRequest(task.cmdType, &copReq, options.GetTiKVReplicaReadType(worker.req.ReplicaRead), &worker.replicaReadSeed, kvrpcpb.Context{
-		IsolationLevel: isolationLevelToPB(worker.req.IsolationLevel),
-		Priority:       priorityToPB(worker.req.Priority),
-		NotFillCache:   worker.req.NotFillCache,
-		RecordTimeStat: true,
-		RecordScanStat: true,
-		TaskId:         worker.req.TaskID,
+		IsolationLevel:         isolationLevelToPB(worker.req.IsolationLevel),
+		Priority:               priorityToPB(worker.req.Priority),
+		NotFillCache:           worker.req.NotFillCache,
+		RecordTimeStat:         true,
+		RecordScanStat:         true,
+		TaskId:                 task.getTaskID(worker.req),
+		MaxExecutionDurationMs: uint64(timeout.Milliseconds()),
 	})
 	if worker.req.ResourceGroupTagger != nil {
 		worker.req.ResourceGroupTagger(req)
@@ -758,7 +785,7 @@ func (worker *copIteratorWorker) handleTaskOnce(bo *Backoffer, task *copTask, ch
 	if len(worker.req.MatchStoreLabels) > 0 {
 		ops = append(ops, tikv.WithMatchLabels(worker.req.MatchStoreLabels))
 	}
-	resp, rpcCtx, storeAddr, err := worker.kvclient.SendReqCtx(bo.TiKVBackoffer(), req, task.region, tikv.ReadTimeoutMedium, getEndPointType(task.storeType), task.storeAddr, ops...)
+	resp, rpcCtx, storeAddr, err := worker.kvclient.SendReqCtx(bo.TiKVBackoffer(), req, task.region, timeout, getEndPointType(task.storeType), task.storeAddr, ops...)
 	err = derr.ToTiDBErr(err)
 	if err != nil {
 		if task.storeType == kv.TiDB {
@@ -885,7 +912,7 @@ func (worker *copIteratorWorker) handleCopStreamResult(bo *Backoffer, rpcCtx *ti
 			}
 
 			if err1 != nil {
-				return nil, errors.Trace(err)
+				return nil, &StreamBroken{StoreType: task.storeType, StoreAddr: task.storeAddr, err: errors.Trace(err)}
 			}
 
 			// No coprocessor.Response for network error, rebuild task based on the last success one.
@@ -1044,29 +1071,33 @@ func (worker *copIteratorWorker) handleCollectExecutionInfo(bo *Backoffer, rpcCt
 	if rpcCtx != nil {
 		resp.detail.CalleeAddress = rpcCtx.Addr
 	}
+	resp.detail.ScanDetail, resp.detail.TimeDetail = execDetailsFromPB(resp.pbResp.ExecDetailsV2, resp.pbResp.ExecDetails)
+}
+
+// execDetailsFromPB converts the exec details returned by the storage into the scan and time details.
+func execDetailsFromPB(detailsV2 *kvrpcpb.ExecDetailsV2, details *kvrpcpb.ExecDetails) (*util.ScanDetail, util.TimeDetail) {
 	sd := &util.ScanDetail{}
 	td := util.TimeDetail{}
-	if pbDetails := resp.pbResp.ExecDetailsV2; pbDetails != nil {
+	if detailsV2 != nil {
 		// Take values in `ExecDetailsV2` first.
-		if timeDetail := pbDetails.TimeDetail; timeDetail != nil {
+		if timeDetail := detailsV2.TimeDetail; timeDetail != nil {
 			td.MergeFromTimeDetail(timeDetail)
 		}
-		if scanDetailV2 := pbDetails.ScanDetailV2; scanDetailV2 != nil {
+		if scanDetailV2 := detailsV2.ScanDetailV2; scanDetailV2 != nil {
 			sd.MergeFromScanDetailV2(scanDetailV2)
 		}
-	} else if pbDetails := resp.pbResp.ExecDetails; pbDetails != nil {
-		if timeDetail := pbDetails.TimeDetail; timeDetail != nil {
+	} else if details != nil {
+		if timeDetail := details.TimeDetail; timeDetail != nil {
 			td.MergeFromTimeDetail(timeDetail)
 		}
-		if scanDetail := pbDetails.ScanDetail; scanDetail != nil {
+		if scanDetail := details.ScanDetail; scanDetail != nil {
 			if scanDetail.Write != nil {
 				sd.ProcessedKeys = scanDetail.Write.Processed
 				sd.TotalKeys = scanDetail.Write.Total
 			}
 		}
 	}
-	resp.detail.ScanDetail = sd
-	resp.detail.TimeDetail = td
+	return sd, td
 }
 
 // CopRuntimeStats contains execution detail information.
@@ -1330,6 +1361,40 @@ func priorityToPB(pri int) kvrpcpb.CommandPri {
 	}
 }
 
+// rpcTimeout caps the timeout of a RPC by the max execution deadline of the request,
+// the capped timeout is also sent to the store to abort the processing in time.
+func rpcTimeout(req *kv.Request, timeout time.Duration) (time.Duration, error) {
+	return timeoutBefore(req.MaxExecutionDeadline, timeout)
+}
+
+// timeoutBefore caps the timeout of an RPC by the deadline, zero deadline means no limit. It returns
+// ErrQueryInterrupted if the deadline has passed.
+func timeoutBefore(deadline time.Time, timeout time.Duration) (time.Duration, error) {
+	if deadline.IsZero() {
+		return timeout, nil
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return 0, derr.ErrQueryInterrupted
+	}
+	if remaining < timeout {
+		return remaining, nil
+	}
+	return timeout, nil
+}
+
+// requestDeadline returns the deadline of the request sent at now, which is the earlier one of its timeout and
+// max execution deadline. Zero means there is no deadline.
+func requestDeadline(req *kv.Request, now time.Time) time.Time {
+	deadline := req.MaxExecutionDeadline
+	if req.Timeout > 0 {
+		if timeoutDeadline := now.Add(req.Timeout); deadline.IsZero() || timeoutDeadline.Before(deadline) {
+			deadline = timeoutDeadline
+		}
+	}
+	return deadline
+}
+
 func isolationLevelToPB(level kv.IsoLevel) kvrpcpb.IsolationLevel {
 	switch level {
 	case kv.RC:
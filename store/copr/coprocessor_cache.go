@@ -36,8 +36,10 @@ type coprCache struct {
 }
 
 type coprCacheValue struct {
-	Key               []byte
-	Data              []byte
+	Key  []byte
+	Data []byte
+	// BatchData is the data of the responses of a batch cop stream, Data is not used by the batch cop requests.
+	BatchData         [][]byte
 	TimeStamp         uint64
 	RegionID          uint64
 	RegionDataVersion uint64
@@ -54,7 +56,11 @@ func (v *coprCacheValue) String() string {
 const coprCacheValueSize = int(unsafe.Sizeof(coprCacheValue{}))
 
 func (v *coprCacheValue) Len() int {
-	return coprCacheValueSize + len(v.Key) + len(v.Data)
+	l := coprCacheValueSize + len(v.Key) + len(v.Data)
+	for _, data := range v.BatchData {
+		l += len(data)
+	}
+	return l
 }
 
 func newCoprCache(config *config.CoprocessorCache) (*coprCache, error) {
@@ -144,6 +150,89 @@ func coprCacheBuildKey(copReq *coprocessor.Request) ([]byte, error) {
 	return key, nil
 }
 
+// coprCacheBuildBatchKey builds the cache key of a batch cop request. TiFlash doesn't report the data version
+// of the regions, so the key contains the start ts, and the cached responses are only reused by the requests
+// reading the same snapshot, e.g. the repeated reads in a transaction or the stale reads.
+func coprCacheBuildBatchKey(copReq *coprocessor.BatchRequest) ([]byte, error) {
+	if copReq.Tp > math.MaxUint8 {
+		return nil, errors.New("Request Tp too big")
+	}
+	if len(copReq.Data) > math.MaxUint32 {
+		return nil, errors.New("Cache data too big")
+	}
+	if len(copReq.Regions) > math.MaxUint32 {
+		return nil, errors.New("Cache regions too many")
+	}
+	totalLength := 1 + 8 + 4 + len(copReq.Data) + 4
+	for _, region := range copReq.Regions {
+		if len(region.Ranges) > math.MaxUint32 {
+			return nil, errors.New("Cache ranges too many")
+		}
+		totalLength += 8 + 4
+		for _, r := range region.Ranges {
+			if len(r.Start) > math.MaxUint16 {
+				return nil, errors.New("Cache start key too big")
+			}
+			if len(r.End) > math.MaxUint16 {
+				return nil, errors.New("Cache end key too big")
+			}
+			totalLength += 2 + len(r.Start) + 2 + len(r.End)
+		}
+	}
+
+	key := make([]byte, totalLength)
+
+	// 1 byte Tp
+	key[0] = uint8(copReq.Tp)
+	dest := 1
+
+	// 8 bytes StartTs
+	binary.LittleEndian.PutUint64(key[dest:], copReq.StartTs)
+	dest += 8
+
+	// 4 bytes Data len
+	binary.LittleEndian.PutUint32(key[dest:], uint32(len(copReq.Data)))
+	dest += 4
+
+	// N bytes Data
+	copy(key[dest:], copReq.Data)
+	dest += len(copReq.Data)
+
+	// 4 bytes Regions len
+	binary.LittleEndian.PutUint32(key[dest:], uint32(len(copReq.Regions)))
+	dest += 4
+
+	for _, region := range copReq.Regions {
+		// 8 bytes RegionId
+		binary.LittleEndian.PutUint64(key[dest:], region.RegionId)
+		dest += 8
+
+		// 4 bytes Ranges len
+		binary.LittleEndian.PutUint32(key[dest:], uint32(len(region.Ranges)))
+		dest += 4
+
+		for _, r := range region.Ranges {
+			// 2 bytes Key len
+			binary.LittleEndian.PutUint16(key[dest:], uint16(len(r.Start)))
+			dest += 2
+
+			// N bytes Key
+			copy(key[dest:], r.Start)
+			dest += len(r.Start)
+
+			// 2 bytes Key len
+			binary.LittleEndian.PutUint16(key[dest:], uint16(len(r.End)))
+			dest += 2
+
+			// N bytes Key
+			copy(key[dest:], r.End)
+			dest += len(r.End)
+		}
+	}
+
+	return key, nil
+}
+
 // Get gets a cache item according to cache key.
 func (c *coprCache) Get(key []byte) *coprCacheValue {
 	if c == nil {
@@ -68,6 +68,50 @@ func TestBuildCacheKey(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestBuildBatchCacheKey(t *testing.T) {
+	req := coprocessor.BatchRequest{
+		Tp:      0xAB,
+		StartTs: 0xAABBCC,
+		Data:    []uint8{0x18, 0x0, 0x20, 0x0},
+		Regions: []*coprocessor.RegionInfo{
+			{
+				RegionId: 0x12,
+				Ranges: []*coprocessor.KeyRange{
+					{
+						Start: kv.Key{0x01},
+						End:   kv.Key{0x01, 0x02},
+					},
+				},
+			},
+			{
+				RegionId: 0x34,
+			},
+		},
+	}
+
+	key, err := coprCacheBuildBatchKey(&req)
+	require.NoError(t, err)
+	expectKey := ""
+	expectKey += "\xab"                             // 1 byte Tp
+	expectKey += "\xcc\xbb\xaa\x00\x00\x00\x00\x00" // 8 bytes StartTs
+	expectKey += "\x04\x00\x00\x00"                 // 4 bytes Data len
+	expectKey += "\x18\x00\x20\x00"                 // Data
+	expectKey += "\x02\x00\x00\x00"                 // 4 bytes Regions len
+	expectKey += "\x12\x00\x00\x00\x00\x00\x00\x00" // 8 bytes RegionId
+	expectKey += "\x01\x00\x00\x00"                 // 4 bytes Ranges len
+	expectKey += "\x01\x00"                         // 2 bytes StartKey len
+	expectKey += "\x01"                             // StartKey
+	expectKey += "\x02\x00"                         // 2 bytes EndKey len
+	expectKey += "\x01\x02"                         // EndKey
+	expectKey += "\x34\x00\x00\x00\x00\x00\x00\x00" // 8 bytes RegionId
+	expectKey += "\x00\x00\x00\x00"                 // 4 bytes Ranges len
+	require.EqualValues(t, []byte(expectKey), key)
+
+	req.Tp = 0xABCC // Tp too big
+	_, err = coprCacheBuildBatchKey(&req)
+	require.Error(t, err)
+}
+
 func TestDisable(t *testing.T) {
 	cache, err := newCoprCache(&config.CoprocessorCache{CapacityMB: 0})
 	require.NoError(t, err)
@@ -155,8 +199,8 @@ func TestCacheValueLen(t *testing.T) {
 		RegionID:          0x1,
 		RegionDataVersion: 0x3,
 	}
-	// 72 = (8 byte pointer + 8 byte for length + 8 byte for cap) * 2 + 8 byte * 3
-	require.Equal(t, 72, v.Len())
+	// 96 = (8 byte pointer + 8 byte for length + 8 byte for cap) * 3 + 8 byte * 3
+	require.Equal(t, 96, v.Len())
 
 	v = coprCacheValue{
 		Key:               []byte("foobar"),
@@ -165,7 +209,14 @@ func TestCacheValueLen(t *testing.T) {
 		RegionID:          0x1,
 		RegionDataVersion: 0x3,
 	}
-	require.Equal(t, 72+len(v.Key)+len(v.Data), v.Len())
+	require.Equal(t, 96+len(v.Key)+len(v.Data), v.Len())
+
+	v = coprCacheValue{
+		Key:       []byte("foobar"),
+		BatchData: [][]byte{[]byte("1234"), []byte("5678")},
+		TimeStamp: 0x123,
+	}
+	require.Equal(t, 96+len(v.Key)+8, v.Len())
 }
 
 func TestGetSet(t *testing.T) {
@@ -17,10 +17,12 @@ package copr
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/pingcap/kvproto/pkg/coprocessor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/driver/backoff"
+	derr "github.com/pingcap/tidb/store/driver/error"
 	"github.com/pingcap/tidb/util/paging"
 	"github.com/stretchr/testify/require"
 	"github.com/tikv/client-go/v2/testutils"
@@ -456,3 +458,26 @@ func TestCalculateRemain(t *testing.T) {
 		rangeEqual(t, toRange(remain), "a", "c", "e", "g")
 	}
 }
+
+func TestRPCTimeout(t *testing.T) {
+	req := &kv.Request{}
+	timeout, err := rpcTimeout(req, tikv.ReadTimeoutMedium)
+	require.NoError(t, err)
+	require.Equal(t, tikv.ReadTimeoutMedium, timeout)
+
+	// The remaining time of the statement caps the timeout.
+	req.MaxExecutionDeadline = time.Now().Add(time.Second)
+	timeout, err = rpcTimeout(req, tikv.ReadTimeoutMedium)
+	require.NoError(t, err)
+	require.LessOrEqual(t, timeout, time.Second)
+	require.Greater(t, timeout, time.Duration(0))
+
+	req.MaxExecutionDeadline = time.Now().Add(time.Hour)
+	timeout, err = rpcTimeout(req, tikv.ReadTimeoutMedium)
+	require.NoError(t, err)
+	require.Equal(t, tikv.ReadTimeoutMedium, timeout)
+
+	req.MaxExecutionDeadline = time.Now().Add(-time.Second)
+	_, err = rpcTimeout(req, tikv.ReadTimeoutMedium)
+	require.True(t, derr.ErrQueryInterrupted.Equal(err))
+}
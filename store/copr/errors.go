@@ -0,0 +1,158 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	stderrs "errors"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/kv"
+	derr "github.com/pingcap/tidb/store/driver/error"
+)
+
+// Error is an error of the cop and batch cop requests classified by its cause. The message and the cause of the
+// error are the ones of the wrapped error, so the errors can still be compared by errors.ErrorEqual.
+type Error interface {
+	error
+	// Retryable returns whether the statement may succeed if it's executed again, since the error is caused by the
+	// transient state of the cluster instead of the request itself.
+	Retryable() bool
+}
+
+// RegionError is returned if the regions of a request are still unavailable when the backoff runs out, e.g. they
+// have no leader or no available replica.
+type RegionError struct {
+	err error
+}
+
+// StoreUnavailable is returned if a store of a request times out, is busy or can't be connected.
+type StoreUnavailable struct {
+	StoreType kv.StoreType
+	StoreAddr string
+	err       error
+}
+
+// StreamBroken is returned if the stream of the responses breaks after the request is sent to the store.
+type StreamBroken struct {
+	StoreType kv.StoreType
+	StoreAddr string
+	err       error
+}
+
+// QueryCancelled is returned if the query is killed or runs out of its max execution time.
+type QueryCancelled struct {
+	err error
+}
+
+var (
+	_ Error = &RegionError{}
+	_ Error = &StoreUnavailable{}
+	_ Error = &StreamBroken{}
+	_ Error = &QueryCancelled{}
+)
+
+// Error implements the error interface.
+func (e *RegionError) Error() string { return e.err.Error() }
+
+// Cause returns the wrapped error.
+func (e *RegionError) Cause() error { return e.err }
+
+// Unwrap returns the wrapped error.
+func (e *RegionError) Unwrap() error { return e.err }
+
+// Retryable implements Error, the regions may be available once they are recovered.
+func (e *RegionError) Retryable() bool { return true }
+
+// Error implements the error interface.
+func (e *StoreUnavailable) Error() string { return e.err.Error() }
+
+// Cause returns the wrapped error.
+func (e *StoreUnavailable) Cause() error { return e.err }
+
+// Unwrap returns the wrapped error.
+func (e *StoreUnavailable) Unwrap() error { return e.err }
+
+// Retryable implements Error, the request may be sent to the other stores or succeed once the store recovers.
+func (e *StoreUnavailable) Retryable() bool { return true }
+
+// Error implements the error interface.
+func (e *StreamBroken) Error() string { return e.err.Error() }
+
+// Cause returns the wrapped error.
+func (e *StreamBroken) Cause() error { return e.err }
+
+// Unwrap returns the wrapped error.
+func (e *StreamBroken) Unwrap() error { return e.err }
+
+// Retryable implements Error, the stream is mostly broken by the network or the restart of the store.
+func (e *StreamBroken) Retryable() bool { return true }
+
+// Error implements the error interface.
+func (e *QueryCancelled) Error() string { return e.err.Error() }
+
+// Cause returns the wrapped error.
+func (e *QueryCancelled) Cause() error { return e.err }
+
+// Unwrap returns the wrapped error.
+func (e *QueryCancelled) Unwrap() error { return e.err }
+
+// Retryable implements Error, the query is cancelled on purpose.
+func (e *QueryCancelled) Retryable() bool { return false }
+
+// IsRetryable checks whether the error of a cop or batch cop request is retryable, see Error.Retryable. The errors
+// not classified, like the other errors replied by the stores, are not retryable.
+func IsRetryable(err error) bool {
+	var e Error
+	return stderrs.As(err, &e) && e.Retryable()
+}
+
+// IsStoreUnavailable checks whether the error is caused by a store of the type, which times out, is busy or breaks
+// the stream, the statement can fall back to the other type of stores then.
+func IsStoreUnavailable(err error, storeType kv.StoreType) bool {
+	var unavailable *StoreUnavailable
+	if stderrs.As(err, &unavailable) {
+		return unavailable.StoreType == storeType
+	}
+	var broken *StreamBroken
+	if stderrs.As(err, &broken) {
+		return broken.StoreType == storeType
+	}
+	// The errors of the MPP requests are not classified.
+	return storeType == kv.TiFlash && errors.ErrorEqual(err, derr.ErrTiFlashServerTimeout)
+}
+
+// classifyErr wraps the error of a task sent to the store by its cause, the errors already classified or of the
+// other causes are returned as is.
+func classifyErr(err error, storeType kv.StoreType, storeAddr string) error {
+	if err == nil {
+		return nil
+	}
+	var e Error
+	if stderrs.As(err, &e) {
+		return err
+	}
+	err = derr.ToTiDBErr(err)
+	cause := errors.Cause(err)
+	switch {
+	case derr.ErrQueryInterrupted.Equal(cause):
+		return &QueryCancelled{err: err}
+	case derr.ErrRegionUnavailable.Equal(cause):
+		return &RegionError{err: err}
+	case derr.ErrTiKVServerTimeout.Equal(cause), derr.ErrTiKVServerBusy.Equal(cause),
+		derr.ErrTiFlashServerTimeout.Equal(cause), derr.ErrTiFlashServerBusy.Equal(cause):
+		return &StoreUnavailable{StoreType: storeType, StoreAddr: storeAddr, err: err}
+	}
+	return err
+}
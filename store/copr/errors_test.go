@@ -0,0 +1,59 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"testing"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/kv"
+	derr "github.com/pingcap/tidb/store/driver/error"
+	"github.com/stretchr/testify/require"
+	tikverr "github.com/tikv/client-go/v2/error"
+)
+
+func TestClassifyErr(t *testing.T) {
+	otherErr := errors.New("other error: mock")
+	cases := []struct {
+		err         error
+		origin      error
+		retryable   bool
+		unavailable bool
+	}{
+		{errors.Trace(derr.ErrTiFlashServerTimeout), derr.ErrTiFlashServerTimeout, true, true},
+		{derr.ErrTiFlashServerBusy, derr.ErrTiFlashServerBusy, true, true},
+		{tikverr.ErrTiFlashServerTimeout, derr.ErrTiFlashServerTimeout, true, true},
+		{errors.Trace(derr.ErrRegionUnavailable), derr.ErrRegionUnavailable, true, false},
+		{derr.ErrQueryInterrupted, derr.ErrQueryInterrupted, false, false},
+		{otherErr, otherErr, false, false},
+	}
+	for _, c := range cases {
+		err := classifyErr(c.err, kv.TiFlash, "tiflash0")
+		require.True(t, errors.ErrorEqual(err, c.origin), "%v", c.err)
+		require.Equal(t, c.origin.Error(), err.Error())
+		require.Equal(t, c.retryable, IsRetryable(err), "%v", c.err)
+		require.Equal(t, c.unavailable, IsStoreUnavailable(errors.Trace(err), kv.TiFlash), "%v", c.err)
+		require.False(t, IsStoreUnavailable(err, kv.TiKV), "%v", c.err)
+		// The errors are classified once.
+		require.Equal(t, err, classifyErr(err, kv.TiKV, "tikv0"))
+	}
+
+	err := &StreamBroken{StoreType: kv.TiFlash, StoreAddr: "tiflash0", err: errors.New("stream closed")}
+	require.True(t, IsRetryable(err))
+	require.True(t, IsStoreUnavailable(err, kv.TiFlash))
+	// The timeouts of the MPP requests are not classified.
+	require.True(t, IsStoreUnavailable(derr.ErrTiFlashServerTimeout, kv.TiFlash))
+	require.False(t, IsRetryable(derr.ErrTiFlashServerTimeout))
+}
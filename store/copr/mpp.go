@@ -31,6 +31,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/mpp"
 	"github.com/pingcap/log"
 	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/ddl/placement"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/driver/backoff"
 	derr "github.com/pingcap/tidb/store/driver/error"
@@ -52,24 +53,85 @@ func (c *batchCopTask) GetAddress() string {
 	return c.storeAddr
 }
 
-func (c *MPPClient) selectAllTiFlashStore() []kv.MPPTaskMeta {
-	resultTasks := make([]kv.MPPTaskMeta, 0)
-	for _, s := range c.store.GetRegionCache().GetTiFlashStores() {
-		task := &batchCopTask{storeAddr: s.GetAddr(), cmdType: tikvrpc.CmdMPPTask}
-		resultTasks = append(resultTasks, task)
+// selectMPPStores selects the TiFlash stores for the MPP tasks without ranges, e.g. the tasks receiving the data
+// exchanged from the other tasks. The stores are selected the same way as the stores of the regions are balanced
+// between by balanceBatchCopTask: the stores being drained, down or failed recently take no new tasks, and the stores
+// in the preferred zone are preferred, unless there is no other store.
+func (c *MPPClient) selectMPPStores(ctx context.Context, req *kv.MPPBuildTasksRequest, mppStoreLastFailTime map[string]time.Time, ttl time.Duration) []kv.MPPTaskMeta {
+	cache := c.store.GetRegionCache()
+	stores := cache.GetTiFlashStores()
+	if allowedStores := allowedTiFlashStores(cache, req.TiFlashReplicaReadNodes); allowedStores != nil {
+		stores = stores[:0:0]
+		for _, s := range allowedStores {
+			stores = append(stores, s)
+		}
+	}
+	unavailableStores := unavailableTiFlashStores(c.store, stores)
+	storeIDs := make([]uint64, 0, len(stores))
+	storeTaskMap := make(map[uint64]*batchCopTask, len(stores))
+	for _, s := range stores {
+		storeIDs = append(storeIDs, s.StoreID())
+		storeTaskMap[s.StoreID()] = &batchCopTask{
+			storeAddr: s.GetAddr(),
+			cmdType:   tikvrpc.CmdMPPTask,
+			ctx:       &tikv.RPCContext{Addr: s.GetAddr(), Store: s},
+		}
+	}
+	storeIDs = preferAvailableStores(storeIDs, unavailableStores)
+	if mppStoreLastFailTime != nil {
+		// The tasks stay in the stores if none of them is ready, like balanceBatchCopTask gives up balancing.
+		if readyStores := detectMPPStores(ctx, c.store, stores, tikvrpc.CmdMPPTask, mppStoreLastFailTime, ttl); len(readyStores) > 0 {
+			storeIDs = preferReadyStores(storeIDs, readyStores)
+		}
+	}
+	if req.PreferredZone != "" {
+		zoneStores := make(map[uint64]struct{})
+		zoneLabels := []*metapb.StoreLabel{{Key: placement.DCLabelKey, Value: req.PreferredZone}}
+		for _, s := range stores {
+			if s.IsLabelsMatch(zoneLabels) {
+				zoneStores[s.StoreID()] = struct{}{}
+			}
+		}
+		storeIDs = preferZoneStores(storeIDs, zoneStores, storeTaskMap)
+	}
+	resultTasks := make([]kv.MPPTaskMeta, 0, len(storeIDs))
+	for _, storeID := range storeIDs {
+		resultTasks = append(resultTasks, storeTaskMap[storeID])
 	}
 	return resultTasks
 }
 
+// preferReadyStores returns the stores in allStores that are ready to serve the MPP tasks, or allStores if there is
+// no such store.
+func preferReadyStores(allStores []uint64, readyStores map[uint64]*batchCopTask) []uint64 {
+	var stores []uint64
+	for _, storeID := range allStores {
+		if _, ok := readyStores[storeID]; ok {
+			stores = append(stores, storeID)
+		}
+	}
+	if len(stores) == 0 {
+		return allStores
+	}
+	return stores
+}
+
 // ConstructMPPTasks receives ScheduleRequest, which are actually collects of kv ranges. We allocates MPPTaskMeta for them and returns.
 func (c *MPPClient) ConstructMPPTasks(ctx context.Context, req *kv.MPPBuildTasksRequest, mppStoreLastFailTime map[string]time.Time, ttl time.Duration) ([]kv.MPPTaskMeta, error) {
 	ctx = context.WithValue(ctx, tikv.TxnStartKey(), req.StartTS)
-	bo := backoff.NewBackofferWithVars(ctx, copBuildTaskMaxBackoff, nil)
+	bo := c.store.newBuildTaskBackoffer(ctx, kv.TiFlash, nil)
 	if req.KeyRanges == nil {
-		return c.selectAllTiFlashStore(), nil
+		return c.selectMPPStores(ctx, req, mppStoreLastFailTime, ttl), nil
 	}
 	ranges := NewKeyRanges(req.KeyRanges)
-	tasks, err := buildBatchCopTasks(bo, c.store, ranges, kv.TiFlash, mppStoreLastFailTime, ttl, true, 20)
+	tasks, err := buildBatchCopTasks(bo, c.store, ranges, kv.TiFlash, batchCopTaskOptions{
+		mppStoreLastFailTime:         mppStoreLastFailTime,
+		ttl:                          ttl,
+		balanceWithContinuity:        true,
+		balanceContinuousRegionCount: 20,
+		preferredZone:                req.PreferredZone,
+		allowedAddrs:                 req.TiFlashReplicaReadNodes,
+	})
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -160,13 +222,12 @@ func (m *mppIterator) run(ctx context.Context) {
 		}
 		m.mu.Unlock()
 		m.wg.Add(1)
-		boMaxSleep := copNextMaxBackoff
+		bo := m.store.newTaskBackoffer(ctx, kv.TiFlash, nil)
 		failpoint.Inject("ReduceCopNextMaxBackoff", func(value failpoint.Value) {
 			if value.(bool) {
-				boMaxSleep = 2
+				bo = backoff.NewBackoffer(ctx, 2)
 			}
 		})
-		bo := backoff.NewBackoffer(ctx, boMaxSleep)
 		go func(mppTask *kv.MPPDispatchRequest) {
 			defer func() {
 				m.wg.Done()
@@ -198,6 +259,17 @@ func (m *mppIterator) sendToRespCh(resp *mppResponse) (exit bool) {
 func (m *mppIterator) handleDispatchReq(ctx context.Context, bo *Backoffer, req *kv.MPPDispatchRequest) {
 	var regionInfos []*coprocessor.RegionInfo
 	originalTask, ok := req.Meta.(*batchCopTask)
+	if ok && len(originalTask.regionInfos) > 0 {
+		if err := m.checkRegionEpochs(bo, originalTask); err != nil {
+			logutil.BgLogger().Error("mpp dispatch meet stale regions", zap.Error(err), zap.Uint64("timestamp", req.StartTs), zap.Int64("task", req.ID))
+			// if needTriggerFallback is true, we return timeout to trigger tikv's fallback
+			if m.needTriggerFallback {
+				err = derr.ErrTiFlashServerTimeout
+			}
+			m.sendError(err)
+			return
+		}
+	}
 	if ok {
 		for _, ri := range originalTask.regionInfos {
 			regionInfos = append(regionInfos, &coprocessor.RegionInfo{
@@ -306,6 +378,65 @@ func (m *mppIterator) handleDispatchReq(ctx context.Context, bo *Backoffer, req
 	m.establishMPPConns(bo, req, taskMeta)
 }
 
+// checkRegionEpochs checks whether the regions of the task are still the latest ones in the region cache before the
+// task is dispatched. The regions split or merged after the task is built are located again, and they are read by the
+// task if the store of the task has their replicas, since the other tasks exchange data with the task in the store.
+func (m *mppIterator) checkRegionEpochs(bo *Backoffer, task *batchCopTask) error {
+	cache := m.store.GetRegionCache()
+	regionInfos := make([]RegionInfo, 0, len(task.regionInfos))
+	var staleRanges []kv.KeyRange
+	for _, ri := range task.regionInfos {
+		rpcCtx, err := cache.GetTiFlashRPCContext(bo.TiKVBackoffer(), ri.Region, false)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if rpcCtx != nil {
+			regionInfos = append(regionInfos, ri)
+			continue
+		}
+		ri.Ranges.Do(func(ran *kv.KeyRange) {
+			staleRanges = append(staleRanges, *ran)
+		})
+	}
+	if len(staleRanges) == 0 {
+		return nil
+	}
+	if task.ctx == nil || task.ctx.Store == nil {
+		return &RegionError{err: errors.Annotatef(derr.ErrRegionUnavailable, "the regions of the mpp task to %s are stale", task.storeAddr)}
+	}
+	storeID := task.ctx.Store.StoreID()
+	locations, err := cache.SplitKeyRangesByLocations(bo, NewKeyRanges(staleRanges))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, lo := range locations {
+		rpcCtx, err := cache.GetTiFlashRPCContext(bo.TiKVBackoffer(), lo.Location.Region, false)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		var allStores []uint64
+		if rpcCtx != nil {
+			allStores = cache.GetAllValidTiFlashStores(lo.Location.Region, rpcCtx.Store)
+		}
+		if !containsStore(allStores, storeID) {
+			return &RegionError{err: errors.Annotatef(derr.ErrRegionUnavailable, "region %d has no replica in the store %s of the mpp task", lo.Location.Region.GetID(), task.storeAddr)}
+		}
+		regionInfos = append(regionInfos, RegionInfo{Region: lo.Location.Region, Meta: rpcCtx.Meta, Ranges: lo.Ranges, AllStores: allStores})
+	}
+	logutil.BgLogger().Info("mpp task relocated its stale regions", zap.String("store address", task.storeAddr), zap.Int("region count", len(regionInfos)))
+	task.regionInfos = regionInfos
+	return nil
+}
+
+func containsStore(stores []uint64, storeID uint64) bool {
+	for _, id := range stores {
+		if id == storeID {
+			return true
+		}
+	}
+	return false
+}
+
 // NOTE: We do not retry here, because retry is helpless when errors result from TiFlash or Network. If errors occur, the execution on TiFlash will finally stop after some minutes.
 // This function is exclusively called, and only the first call succeeds sending tasks and setting all tasks as cancelled, while others will not work.
 func (m *mppIterator) cancelMppTasks() {
@@ -490,7 +621,9 @@ func (m *mppIterator) Next(ctx context.Context) (kv.ResultSubset, error) {
 	return resp, nil
 }
 
-// DispatchMPPTasks dispatches all the mpp task and waits for the responses.
+// DispatchMPPTasks dispatches all the mpp task and waits for the responses. The regions of each task are checked
+// against the region cache before the task is dispatched, the ones split or merged since the task is built are
+// located again.
 func (c *MPPClient) DispatchMPPTasks(ctx context.Context, variables interface{}, dispatchReqs []*kv.MPPDispatchRequest, needTriggerFallback bool, startTs uint64) kv.Response {
 	vars := variables.(*tikv.Variables)
 	ctxChild, cancelFunc := context.WithCancel(ctx)
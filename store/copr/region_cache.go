@@ -112,6 +112,46 @@ func (c *RegionCache) SplitKeyRangesByLocations(bo *Backoffer, ranges *KeyRanges
 	return res, nil
 }
 
+// refreshRegionsBatchSize is the max number of the regions loaded from PD by a request when the regions are refreshed.
+const refreshRegionsBatchSize = 128
+
+// RefreshRegionsInRanges loads the regions covering the ranges from PD in batches, the loaded regions replace the
+// cached ones. It's much faster than locating the invalidated regions again, which loads them one by one.
+func (c *RegionCache) RefreshRegionsInRanges(bo *Backoffer, ranges *KeyRanges) error {
+	// loadedEnd is the end key of the last loaded region, the ranges before it are loaded already.
+	var loadedEnd []byte
+	loaded := false
+	for i := 0; i < ranges.Len(); i++ {
+		r := ranges.At(i)
+		startKey := r.StartKey
+		if loaded {
+			if len(loadedEnd) == 0 {
+				// The last loaded region is the last region of the cluster.
+				return nil
+			}
+			if len(r.EndKey) > 0 && bytes.Compare(loadedEnd, r.EndKey) >= 0 {
+				continue
+			}
+			if bytes.Compare(loadedEnd, startKey) > 0 {
+				startKey = loadedEnd
+			}
+		}
+		for {
+			regions, err := c.BatchLoadRegionsWithKeyRange(bo.TiKVBackoffer(), startKey, r.EndKey, refreshRegionsBatchSize)
+			if err != nil {
+				return derr.ToTiDBErr(err)
+			}
+			loaded = true
+			loadedEnd = regions[len(regions)-1].EndKey()
+			if len(loadedEnd) == 0 || (len(r.EndKey) > 0 && bytes.Compare(loadedEnd, r.EndKey) >= 0) {
+				break
+			}
+			startKey = loadedEnd
+		}
+	}
+	return nil
+}
+
 // OnSendFailForBatchRegions handles send request fail logic.
 func (c *RegionCache) OnSendFailForBatchRegions(bo *Backoffer, store *tikv.Store, regionInfos []RegionInfo, scheduleReload bool, err error) {
 	metrics.RegionCacheCounterWithSendFail.Add(float64(len(regionInfos)))
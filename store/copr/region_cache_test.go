@@ -0,0 +1,109 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pingcap/tidb/store/driver/backoff"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/client-go/v2/testutils"
+	"github.com/tikv/client-go/v2/tikv"
+	pd "github.com/tikv/pd/client"
+)
+
+// countingPDClient counts the requests loading the regions from PD.
+type countingPDClient struct {
+	pd.Client
+	getRegion   int32
+	scanRegions int32
+}
+
+func (c *countingPDClient) GetRegion(ctx context.Context, key []byte) (*pd.Region, error) {
+	atomic.AddInt32(&c.getRegion, 1)
+	return c.Client.GetRegion(ctx, key)
+}
+
+func (c *countingPDClient) ScanRegions(ctx context.Context, key, endKey []byte, limit int) ([]*pd.Region, error) {
+	atomic.AddInt32(&c.scanRegions, 1)
+	return c.Client.ScanRegions(ctx, key, endKey, limit)
+}
+
+func (c *countingPDClient) reset() {
+	atomic.StoreInt32(&c.getRegion, 0)
+	atomic.StoreInt32(&c.scanRegions, 0)
+}
+
+func TestRefreshRegionsInRanges(t *testing.T) {
+	// nil --- k001 --- k002 --- ... --- k299 --- nil
+	mockClient, cluster, pdClient, err := testutils.NewMockTiKV("", nil)
+	require.NoError(t, err)
+	splitKeys := make([][]byte, 0, 299)
+	for i := 1; i < 300; i++ {
+		splitKeys = append(splitKeys, []byte(fmt.Sprintf("k%03d", i)))
+	}
+	_, regionIDs, _ := testutils.BootstrapWithMultiRegions(cluster, splitKeys...)
+	require.Len(t, regionIDs, 300)
+	countingPD := &countingPDClient{Client: pdClient}
+	tikvStore, err := tikv.NewTestTiKVStore(mockClient, countingPD, nil, nil, 0)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, tikvStore.Close())
+	}()
+	store, err := NewStore(tikvStore, nil)
+	require.NoError(t, err)
+	defer store.Close()
+	cache := store.GetRegionCache()
+	bo := backoff.NewBackofferWithVars(context.Background(), 3000, nil)
+
+	invalidateAll := func(ranges *KeyRanges) {
+		locations, err := cache.SplitKeyRangesByLocations(bo, ranges)
+		require.NoError(t, err)
+		for _, loc := range locations {
+			cache.InvalidateCachedRegion(loc.Location.Region)
+		}
+	}
+
+	// The regions are loaded by batches, instead of one by one.
+	ranges := NewKeyRanges(buildKeyRanges("a", "z"))
+	invalidateAll(ranges)
+	countingPD.reset()
+	require.NoError(t, cache.RefreshRegionsInRanges(bo, ranges))
+	require.Equal(t, int32(3), atomic.LoadInt32(&countingPD.scanRegions))
+	locations, err := cache.SplitKeyRangesByLocations(bo, ranges)
+	require.NoError(t, err)
+	require.Len(t, locations, 300)
+	require.Equal(t, int32(0), atomic.LoadInt32(&countingPD.getRegion))
+
+	// The regions loaded for the former ranges aren't loaded again.
+	ranges = NewKeyRanges(buildKeyRanges("k000a", "k000b", "k000c", "k000d", "k005", "k010b", "k010c", "k010d"))
+	invalidateAll(ranges)
+	countingPD.reset()
+	require.NoError(t, cache.RefreshRegionsInRanges(bo, ranges))
+	require.Equal(t, int32(2), atomic.LoadInt32(&countingPD.scanRegions))
+	locations, err = cache.SplitKeyRangesByLocations(bo, ranges)
+	require.NoError(t, err)
+	require.Len(t, locations, 7)
+	require.Equal(t, int32(0), atomic.LoadInt32(&countingPD.getRegion))
+
+	// The last range is unbounded.
+	ranges = NewKeyRanges(buildKeyRanges("k298", ""))
+	countingPD.reset()
+	require.NoError(t, cache.RefreshRegionsInRanges(bo, ranges))
+	require.Equal(t, int32(1), atomic.LoadInt32(&countingPD.scanRegions))
+}
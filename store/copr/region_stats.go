@@ -0,0 +1,209 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/tidb/store/helper"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+const (
+	// regionStatsTTL is how long the approximate size and keys of a region are used after they are loaded.
+	regionStatsTTL = 5 * time.Minute
+	// regionStatsLoadInterval is the interval between two loads, so PD is not flooded by the loads.
+	regionStatsLoadInterval = 10 * time.Second
+	// maxRegionStatsLoadCount is the max number of regions loaded from PD at once.
+	maxRegionStatsLoadCount = 10240
+	// minRegionWeight is the min weight of a region relative to the average one, every region costs a request
+	// to scan no matter how small it is.
+	minRegionWeight = 0.1
+)
+
+// regionStat is the approximate size and keys of a region reported by PD.
+type regionStat struct {
+	// version is the epoch version of the region, the stat is stale once the region is split or merged.
+	version    uint64
+	size       int64
+	keys       int64
+	updateTime time.Time
+}
+
+// regionStats caches the approximate sizes and keys of the regions reported by PD, so the regions of the batch
+// cop tasks are balanced by the data the stores scan instead of the region count. The batch cop tasks only read
+// the last loaded snapshot and request the stats they miss, which are loaded by the background loop of the store.
+// The regions not loaded yet are regarded as average ones.
+// The zero value is ready to use.
+type regionStats struct {
+	// regions is the snapshot of the loaded stats, a map[uint64]regionStat keyed by the region IDs. It's replaced
+	// as a whole by every load, so it can be read without locking.
+	regions atomic.Value
+	mu      sync.Mutex
+	// pending is the key range of the regions whose stats are requested since the last load.
+	pending regionStatsRequest
+}
+
+// regionStatsRequest is the key range of the regions whose stats should be loaded, limit is 0 if nothing is requested.
+type regionStatsRequest struct {
+	startKey []byte
+	// An empty end key means the end of the key space.
+	endKey []byte
+	limit  int
+}
+
+func (s *regionStats) snapshot() map[uint64]regionStat {
+	regions, _ := s.regions.Load().(map[uint64]regionStat)
+	return regions
+}
+
+// request requests the stats of the regions that are unknown or stale in the snapshot, they are loaded by the next
+// load.
+func (s *regionStats) request(regions []RegionInfo, now time.Time) {
+	snapshot := s.snapshot()
+	var startKey, endKey []byte
+	missing := 0
+	for _, ri := range regions {
+		if ri.Meta == nil {
+			continue
+		}
+		if stat, ok := snapshot[ri.Region.GetID()]; ok && stat.version == ri.Region.GetVer() && now.Sub(stat.updateTime) < regionStatsTTL {
+			continue
+		}
+		if missing == 0 || bytes.Compare(ri.Meta.StartKey, startKey) < 0 {
+			startKey = ri.Meta.StartKey
+		}
+		if missing == 0 || (len(endKey) > 0 && (len(ri.Meta.EndKey) == 0 || bytes.Compare(ri.Meta.EndKey, endKey) > 0)) {
+			endKey = ri.Meta.EndKey
+		}
+		missing++
+	}
+	if missing == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending.limit > 0 {
+		if bytes.Compare(s.pending.startKey, startKey) < 0 {
+			startKey = s.pending.startKey
+		}
+		if len(s.pending.endKey) == 0 || (len(endKey) > 0 && bytes.Compare(s.pending.endKey, endKey) > 0) {
+			endKey = s.pending.endKey
+		}
+	}
+	limit := s.pending.limit + len(regions)*2
+	if limit > maxRegionStatsLoadCount {
+		limit = maxRegionStatsLoadCount
+	}
+	s.pending = regionStatsRequest{startKey: startKey, endKey: endKey, limit: limit}
+}
+
+// run loads the requested stats from PD every regionStatsLoadInterval until ctx is done.
+func (s *regionStats) run(ctx context.Context, pdHelper *helper.Helper) {
+	ticker := time.NewTicker(regionStatsLoadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.load(pdHelper)
+		}
+	}
+}
+
+// load loads the stats of the regions requested since the last load from PD.
+func (s *regionStats) load(pdHelper *helper.Helper) {
+	s.mu.Lock()
+	req := s.pending
+	s.pending = regionStatsRequest{}
+	s.mu.Unlock()
+	if req.limit == 0 {
+		return
+	}
+	regionsInfo, err := pdHelper.ScanRegionsInfo(req.startKey, req.endKey, req.limit)
+	if err != nil {
+		logutil.BgLogger().Warn("load the stats of the regions from PD failed", zap.Error(err))
+		return
+	}
+	s.update(regionsInfo.Regions, time.Now())
+}
+
+// update replaces the snapshot by the one with the stats, the expired stats are dropped so the cache doesn't keep
+// the regions not read any more.
+func (s *regionStats) update(stats []helper.RegionInfo, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.snapshot()
+	regions := make(map[uint64]regionStat, len(old)+len(stats))
+	for id, stat := range old {
+		if now.Sub(stat.updateTime) < regionStatsTTL {
+			regions[id] = stat
+		}
+	}
+	for _, stat := range stats {
+		regions[uint64(stat.ID)] = regionStat{
+			version:    uint64(stat.Epoch.Version),
+			size:       stat.ApproximateSize,
+			keys:       stat.ApproximateKeys,
+			updateTime: now,
+		}
+	}
+	s.regions.Store(regions)
+}
+
+// weights returns the costs to scan the regions, keyed by the region IDs. The cost of a region is the mean of
+// its approximate size and keys relative to the average ones of the regions, so an average region costs 1,
+// and so does a region whose stat is unknown.
+func (s *regionStats) weights(regions []RegionInfo, now time.Time) map[uint64]float64 {
+	snapshot := s.snapshot()
+	known := make(map[uint64]regionStat, len(regions))
+	var totalSize, totalKeys int64
+	for _, ri := range regions {
+		stat, ok := snapshot[ri.Region.GetID()]
+		if !ok || stat.version != ri.Region.GetVer() || now.Sub(stat.updateTime) >= regionStatsTTL {
+			continue
+		}
+		known[ri.Region.GetID()] = stat
+		totalSize += stat.size
+		totalKeys += stat.keys
+	}
+	weights := make(map[uint64]float64, len(regions))
+	for _, ri := range regions {
+		weight := 1.0
+		if stat, ok := known[ri.Region.GetID()]; ok {
+			avgSize := float64(totalSize) / float64(len(known))
+			avgKeys := float64(totalKeys) / float64(len(known))
+			switch {
+			case avgSize > 0 && avgKeys > 0:
+				weight = (float64(stat.size)/avgSize + float64(stat.keys)/avgKeys) / 2
+			case avgSize > 0:
+				weight = float64(stat.size) / avgSize
+			case avgKeys > 0:
+				weight = float64(stat.keys) / avgKeys
+			}
+			if weight < minRegionWeight {
+				weight = minRegionWeight
+			}
+		}
+		weights[ri.Region.GetID()] = weight
+	}
+	return weights
+}
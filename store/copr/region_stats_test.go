@@ -0,0 +1,85 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb/store/helper"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/client-go/v2/tikv"
+)
+
+func TestRegionStatsWeights(t *testing.T) {
+	newStat := func(id, version, size, keys int64) helper.RegionInfo {
+		return helper.RegionInfo{ID: id, Epoch: helper.RegionEpoch{Version: version}, ApproximateSize: size, ApproximateKeys: keys}
+	}
+	var regions []RegionInfo
+	for i := uint64(1); i <= 4; i++ {
+		regions = append(regions, RegionInfo{Region: tikv.NewRegionVerID(i, 0, 1)})
+	}
+	var stats regionStats
+	now := time.Now()
+	// The regions are average ones before the stats are loaded.
+	for _, weight := range stats.weights(regions, now) {
+		require.Equal(t, 1.0, weight)
+	}
+
+	// Region 3 is split after its stat is loaded, and region 4 is unknown.
+	stats.update([]helper.RegionInfo{newStat(1, 1, 300, 100), newStat(2, 1, 100, 300), newStat(3, 0, 100, 100)}, now)
+	weights := stats.weights(regions, now)
+	require.InDelta(t, 1.0, weights[1], 1e-9)
+	require.InDelta(t, 1.0, weights[2], 1e-9)
+	require.Equal(t, 1.0, weights[3])
+	require.Equal(t, 1.0, weights[4])
+
+	// The tiny regions still cost a request.
+	stats.update([]helper.RegionInfo{newStat(3, 1, 0, 0)}, now)
+	weights = stats.weights(regions, now)
+	require.InDelta(t, 1.5, weights[1], 1e-9)
+	require.Equal(t, minRegionWeight, weights[3])
+
+	// The stats expire.
+	weights = stats.weights(regions, now.Add(regionStatsTTL))
+	require.Equal(t, 1.0, weights[1])
+	stats.update(nil, now.Add(regionStatsTTL))
+	require.Empty(t, stats.snapshot())
+}
+
+func TestRegionStatsRequest(t *testing.T) {
+	newRegion := func(id uint64, startKey, endKey string) RegionInfo {
+		return RegionInfo{Region: tikv.NewRegionVerID(id, 0, 1), Meta: &metapb.Region{Id: id, StartKey: []byte(startKey), EndKey: []byte(endKey)}}
+	}
+	var stats regionStats
+	now := time.Now()
+	stats.request([]RegionInfo{newRegion(2, "g", "n"), newRegion(3, "n", "t")}, now)
+	require.Equal(t, regionStatsRequest{startKey: []byte("g"), endKey: []byte("t"), limit: 4}, stats.pending)
+
+	// The requests are merged until the next load.
+	stats.request([]RegionInfo{newRegion(1, "", "g")}, now)
+	require.Equal(t, regionStatsRequest{startKey: []byte(""), endKey: []byte("t"), limit: 6}, stats.pending)
+	stats.request([]RegionInfo{newRegion(4, "t", "")}, now)
+	require.Equal(t, regionStatsRequest{startKey: []byte(""), endKey: []byte(""), limit: 8}, stats.pending)
+
+	// The regions having fresh stats are not requested.
+	stats.pending = regionStatsRequest{}
+	stats.update([]helper.RegionInfo{{ID: 2, Epoch: helper.RegionEpoch{Version: 1}}}, now)
+	stats.request([]RegionInfo{newRegion(2, "g", "n")}, now)
+	require.Zero(t, stats.pending.limit)
+	stats.request([]RegionInfo{newRegion(2, "g", "n")}, now.Add(regionStatsTTL))
+	require.Equal(t, regionStatsRequest{startKey: []byte("g"), endKey: []byte("n"), limit: 2}, stats.pending)
+}
@@ -17,6 +17,7 @@ package copr
 import (
 	"context"
 	"math/rand"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/driver/backoff"
 	derr "github.com/pingcap/tidb/store/driver/error"
+	"github.com/pingcap/tidb/store/helper"
 	"github.com/tikv/client-go/v2/config"
 	"github.com/tikv/client-go/v2/tikv"
 	"github.com/tikv/client-go/v2/tikvrpc"
@@ -31,6 +33,22 @@ import (
 
 type kvStore struct {
 	store *tikv.KVStore
+	// health tracks the feedback of the batch cop tasks sent to the TiFlash stores.
+	health storeHealthTracker
+	// batchCopTasks caches the layouts of the batch cop tasks.
+	batchCopTasks batchCopTaskCache
+	// pdStores caches the states of the stores in PD.
+	pdStores pdStoreStates
+	// regionStats caches the approximate sizes and keys of the regions in PD.
+	regionStats regionStats
+	// backoffPolicies keeps the backoff policies of the store types.
+	backoffPolicies backoffPolicies
+	// batchCopSupport remembers the TiKV stores not supporting the batch cop requests.
+	batchCopSupport batchCopSupport
+	// batchCopQueue limits the number of the batch cop requests running at the same time.
+	batchCopQueue batchCopQueue
+	// batchCopBalanceLog keeps and logs the latest balance decisions of the batch cop tasks.
+	batchCopBalanceLog batchCopBalanceLog
 }
 
 // GetRegionCache returns the region cache instance.
@@ -70,6 +88,11 @@ type Store struct {
 	*kvStore
 	coprCache       *coprCache
 	replicaReadSeed uint32
+	// bgCtx is the context of the background workers of the store, like the prober of the TiFlash stores.
+	// cancelBg stops them and bgWorkers waits for them to exit.
+	bgCtx     context.Context
+	cancelBg  context.CancelFunc
+	bgWorkers sync.WaitGroup
 }
 
 // NewStore creates a new store instance.
@@ -78,16 +101,43 @@ func NewStore(s *tikv.KVStore, coprCacheConfig *config.CoprocessorCache) (*Store
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	ctx, cancel := context.WithCancel(context.Background())
 	/* #nosec G404 */
-	return &Store{
+	store := &Store{
 		kvStore:         &kvStore{store: s},
 		coprCache:       coprCache,
 		replicaReadSeed: rand.Uint32(),
-	}, nil
+		bgCtx:           ctx,
+		cancelBg:        cancel,
+	}
+	store.runInBackground(store.runTiFlashStoreProber)
+	store.runInBackground(func(ctx context.Context) {
+		store.pdStores.run(ctx, s.GetPDClient())
+	})
+	return store, nil
+}
+
+// SetPDHelper sets the helper to load the stats of the regions from PD, which balance the batch cop tasks.
+// The stats are not loaded until it's called.
+func (s *Store) SetPDHelper(h *helper.Helper) {
+	s.runInBackground(func(ctx context.Context) {
+		s.regionStats.run(ctx, h)
+	})
+}
+
+// runInBackground runs f in a goroutine until the store is closed.
+func (s *Store) runInBackground(f func(ctx context.Context)) {
+	s.bgWorkers.Add(1)
+	go func() {
+		defer s.bgWorkers.Done()
+		f(s.bgCtx)
+	}()
 }
 
 // Close releases resources allocated for coprocessor.
 func (s *Store) Close() {
+	s.cancelBg()
+	s.bgWorkers.Wait()
 	if s.coprCache != nil {
 		s.coprCache.cache.Close()
 	}
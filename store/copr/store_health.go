@@ -0,0 +1,175 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// storeErrHalfLife is the time after which the recorded errors of a store count half.
+	storeErrHalfLife = 10 * time.Second
+	// unhealthyStoreErrScore is the decayed error count from which a store is considered unhealthy.
+	unhealthyStoreErrScore = 3
+	// The weights of the in-flight batch tasks, the decayed errors and the response latency (in seconds) of a store
+	// when balancing the regions between the stores.
+	storeInflightTaskWeight = 0.1
+	storeErrWeight          = 0.5
+	storeLatencyWeight      = 0.1
+	// storeProbeInterval is the interval the TiFlash stores are probed at in the background.
+	storeProbeInterval = 5 * time.Second
+	// storeBreakTimeouts is the number of the consecutive timeouts from which the circuit of a store is broken,
+	// the batch cop tasks are not sent to the store for storeBreakDuration then.
+	storeBreakTimeouts = 3
+	storeBreakDuration = 30 * time.Second
+)
+
+// storeHealth is the recent feedback of the batch cop tasks sent to a store.
+type storeHealth struct {
+	inflight int64
+	errScore float64
+	// errTime is the time the errScore was last decayed at.
+	errTime     time.Time
+	lastLatency time.Duration
+	// down is whether the store didn't respond the last probe.
+	down bool
+	// timeouts is the number of the consecutive timeouts of the store, and breakUntil is when its broken circuit
+	// is closed again.
+	timeouts   int
+	breakUntil time.Time
+}
+
+func (h *storeHealth) decayedErrScore(now time.Time) float64 {
+	if h.errScore == 0 {
+		return 0
+	}
+	return h.errScore * math.Exp2(-float64(now.Sub(h.errTime))/float64(storeErrHalfLife))
+}
+
+// storeHealthTracker tracks the health and load of the TiFlash stores by their addresses, so the batch cop tasks
+// can avoid the stores that fail or are overloaded recently. The zero value is ready to use.
+type storeHealthTracker struct {
+	mu     sync.Mutex
+	stores map[string]*storeHealth
+}
+
+func (t *storeHealthTracker) getLocked(addr string) *storeHealth {
+	if t.stores == nil {
+		t.stores = make(map[string]*storeHealth)
+	}
+	h, ok := t.stores[addr]
+	if !ok {
+		h = &storeHealth{}
+		t.stores[addr] = h
+	}
+	return h
+}
+
+// onTaskStart records a batch task is sent to the store.
+func (t *storeHealthTracker) onTaskStart(addr string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.getLocked(addr).inflight++
+}
+
+// onTaskFinish records a batch task sent to the store is finished.
+func (t *storeHealthTracker) onTaskFinish(addr string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.getLocked(addr)
+	if h.inflight > 0 {
+		h.inflight--
+	}
+}
+
+// onResponse records the latency of the store to respond a batch task, and whether it meets a store error.
+func (t *storeHealthTracker) onResponse(addr string, latency time.Duration, failed bool, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.getLocked(addr)
+	if !failed {
+		h.lastLatency = latency
+		return
+	}
+	h.errScore = h.decayedErrScore(now) + 1
+	h.errTime = now
+}
+
+// onTimeout records the store times out to respond a batch task. The circuit of the store is broken once it times
+// out continuously, and it's broken again by the first timeout after it's closed, until the store succeeds.
+func (t *storeHealthTracker) onTimeout(addr string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.getLocked(addr)
+	h.timeouts++
+	if h.timeouts >= storeBreakTimeouts && !now.Before(h.breakUntil) {
+		h.breakUntil = now.Add(storeBreakDuration)
+	}
+}
+
+// onSuccess records the store responds a batch task completely, which resets its timeouts.
+func (t *storeHealthTracker) onSuccess(addr string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.getLocked(addr)
+	h.timeouts = 0
+	h.breakUntil = time.Time{}
+}
+
+// isBroken returns whether the circuit of the store is broken by the consecutive timeouts.
+func (t *storeHealthTracker) isBroken(addr string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h, ok := t.stores[addr]
+	return ok && now.Before(h.breakUntil)
+}
+
+// isUnhealthy returns whether the store meets too many errors recently.
+func (t *storeHealthTracker) isUnhealthy(addr string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h, ok := t.stores[addr]
+	return ok && h.decayedErrScore(now) >= unhealthyStoreErrScore
+}
+
+// loadFactor returns the factor, not less than 1, to scale the region count of the store by when balancing
+// the regions. The busier or the less healthy the store is, the bigger the factor is.
+func (t *storeHealthTracker) loadFactor(addr string, now time.Time) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h, ok := t.stores[addr]
+	if !ok {
+		return 1
+	}
+	return 1 + float64(h.inflight)*storeInflightTaskWeight + h.decayedErrScore(now)*storeErrWeight +
+		h.lastLatency.Seconds()*storeLatencyWeight
+}
+
+// onProbe records whether the store is alive.
+func (t *storeHealthTracker) onProbe(addr string, alive bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.getLocked(addr).down = !alive
+}
+
+// isDown returns whether the store didn't respond the last probe.
+func (t *storeHealthTracker) isDown(addr string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h, ok := t.stores[addr]
+	return ok && h.down
+}
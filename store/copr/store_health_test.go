@@ -0,0 +1,95 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreHealthTracker(t *testing.T) {
+	var tracker storeHealthTracker
+	now := time.Now()
+	require.False(t, tracker.isUnhealthy("s1", now))
+	require.Equal(t, 1.0, tracker.loadFactor("s1", now))
+
+	// The in-flight tasks and the latency make the store busier.
+	tracker.onTaskStart("s1")
+	tracker.onTaskStart("s1")
+	tracker.onResponse("s1", 2*time.Second, false, now)
+	require.InDelta(t, 1+2*storeInflightTaskWeight+2*storeLatencyWeight, tracker.loadFactor("s1", now), 1e-9)
+	tracker.onTaskFinish("s1")
+	tracker.onTaskFinish("s1")
+	tracker.onTaskFinish("s1")
+	require.InDelta(t, 1+2*storeLatencyWeight, tracker.loadFactor("s1", now), 1e-9)
+
+	// The store is unhealthy after meeting errors, and recovers as the errors decay.
+	for i := 0; i < unhealthyStoreErrScore; i++ {
+		tracker.onResponse("s2", 0, true, now)
+	}
+	require.True(t, tracker.isUnhealthy("s2", now))
+	require.InDelta(t, 1+unhealthyStoreErrScore*storeErrWeight, tracker.loadFactor("s2", now), 1e-9)
+	require.False(t, tracker.isUnhealthy("s2", now.Add(storeErrHalfLife)))
+	require.InDelta(t, 1+unhealthyStoreErrScore*storeErrWeight/2, tracker.loadFactor("s2", now.Add(storeErrHalfLife)), 1e-9)
+}
+
+func TestPreferHealthyStores(t *testing.T) {
+	storeTasks := buildStoreTaskMap(4)
+	for storeID, task := range storeTasks {
+		task.storeAddr = string(rune('0' + storeID))
+	}
+	// Store 4 is unavailable.
+	delete(storeTasks, 4)
+	var tracker storeHealthTracker
+	now := time.Now()
+	for i := 0; i < unhealthyStoreErrScore; i++ {
+		tracker.onResponse(storeTasks[2].storeAddr, 0, true, now)
+	}
+
+	require.Equal(t, []uint64{1, 3}, preferHealthyStores([]uint64{1, 2, 3, 4}, storeTasks, &tracker, now))
+	require.Equal(t, []uint64{3}, preferHealthyStores([]uint64{2, 3}, storeTasks, &tracker, now))
+	// All the stores are kept if the region has no healthy replica.
+	require.Equal(t, []uint64{2, 4}, preferHealthyStores([]uint64{2, 4}, storeTasks, &tracker, now))
+	// The store is used again after its errors decay.
+	require.Equal(t, []uint64{2, 3}, preferHealthyStores([]uint64{2, 3}, storeTasks, &tracker, now.Add(storeErrHalfLife)))
+}
+
+func TestStoreCircuitBreaker(t *testing.T) {
+	var tracker storeHealthTracker
+	now := time.Now()
+	for i := 1; i < storeBreakTimeouts; i++ {
+		tracker.onTimeout("s1", now)
+	}
+	require.False(t, tracker.isBroken("s1", now))
+
+	// The circuit is broken by the consecutive timeouts for a while.
+	tracker.onTimeout("s1", now)
+	require.True(t, tracker.isBroken("s1", now))
+	require.True(t, tracker.isBroken("s1", now.Add(storeBreakDuration-time.Second)))
+	require.False(t, tracker.isBroken("s1", now.Add(storeBreakDuration)))
+
+	// The store keeps timing out after the circuit is closed, so it's broken again at once.
+	now = now.Add(storeBreakDuration)
+	tracker.onTimeout("s1", now)
+	require.True(t, tracker.isBroken("s1", now))
+
+	// The success resets the timeouts.
+	tracker.onSuccess("s1")
+	require.False(t, tracker.isBroken("s1", now))
+	tracker.onTimeout("s1", now)
+	require.False(t, tracker.isBroken("s1", now))
+}
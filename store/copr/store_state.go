@@ -0,0 +1,104 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copr
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb/util/logutil"
+	pd "github.com/tikv/pd/client"
+	"go.uber.org/zap"
+)
+
+const (
+	// pdStoreStatesRefreshInterval is how often the states of the stores are loaded from PD.
+	pdStoreStatesRefreshInterval = 30 * time.Second
+	// pdStoreStatesLoadTimeout is the timeout to load the states of the stores from PD.
+	pdStoreStatesLoadTimeout = 3 * time.Second
+	// drainLabelKey is the label of the stores that should take no more requests, e.g. set by
+	// `pd-ctl store label <id> tidb_drain true` before the store is scaled in, so it's drained gracefully.
+	drainLabelKey = "tidb_drain"
+)
+
+// pdStoreStates caches the states of the stores reported by PD, so the regions are not balanced to the TiFlash
+// stores that are being drained, i.e. going offline, removed or labeled with drainLabelKey. The region cache only
+// knows whether the stores are reachable, while these stores still serve requests until all their regions are
+// moved away or the region cache expires. The tasks already sent to them finish normally.
+// The states are refreshed by the background loop of the store, the batch cop tasks only read the last snapshot.
+// The zero value is ready to use.
+type pdStoreStates struct {
+	// stores is the snapshot of the stores, a map[uint64]*metapb.Store keyed by the store IDs.
+	stores atomic.Value
+}
+
+// run refreshes the states of the stores from PD every pdStoreStatesRefreshInterval until ctx is done.
+func (s *pdStoreStates) run(ctx context.Context, pdClient pd.Client) {
+	ticker := time.NewTicker(pdStoreStatesRefreshInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.refresh(ctx, pdClient); err != nil && ctx.Err() == nil {
+			logutil.BgLogger().Warn("load the states of the stores from PD failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh loads the states of the stores from PD.
+func (s *pdStoreStates) refresh(ctx context.Context, pdClient pd.Client) error {
+	ctx, cancel := context.WithTimeout(ctx, pdStoreStatesLoadTimeout)
+	defer cancel()
+	// The tombstone stores are loaded too, the region cache may still have them.
+	stores, err := pdClient.GetAllStores(ctx)
+	if err != nil {
+		return err
+	}
+	s.update(stores)
+	return nil
+}
+
+func (s *pdStoreStates) update(stores []*metapb.Store) {
+	storeMap := make(map[uint64]*metapb.Store, len(stores))
+	for _, store := range stores {
+		storeMap[store.GetId()] = store
+	}
+	s.stores.Store(storeMap)
+}
+
+// isAvailable returns whether the store can take more regions. The stores unknown to the cache are regarded
+// as available, e.g. the ones joining the cluster after the last refresh.
+func (s *pdStoreStates) isAvailable(storeID uint64) bool {
+	stores, _ := s.stores.Load().(map[uint64]*metapb.Store)
+	store, ok := stores[storeID]
+	if !ok {
+		return true
+	}
+	if store.GetState() != metapb.StoreState_Up || store.GetPhysicallyDestroyed() {
+		return false
+	}
+	for _, label := range store.GetLabels() {
+		if label.GetKey() == drainLabelKey && strings.EqualFold(label.GetValue(), "true") {
+			return false
+		}
+	}
+	return true
+}
@@ -16,15 +16,36 @@ package backoff
 
 import (
 	"context"
+	"sync/atomic"
+	"time"
 
+	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/kv"
 	derr "github.com/pingcap/tidb/store/driver/error"
+	"github.com/pingcap/tidb/util/logutil"
 	"github.com/tikv/client-go/v2/tikv"
+	"github.com/tikv/client-go/v2/util"
+	"go.uber.org/zap"
 )
 
+// Policy decides how long to sleep before the retries instead of the backoff configs of client-go.
+type Policy interface {
+	// Sleep returns the time(in ms) to sleep before the attempt-th retry on the errors of the backoff type,
+	// the attempt starts from 0.
+	Sleep(tp string, attempt int) int
+}
+
 // Backoffer wraps tikv.Backoffer and converts the error which returns by the functions of tikv.Backoffer to tidb error.
 type Backoffer struct {
 	b *tikv.Backoffer
+
+	// policy decides the sleeps of Backoff if it's not nil, the sleeps are recorded below while the ones made
+	// by client-go are recorded in b.
+	policy         Policy
+	maxSleep       int
+	totalSleep     int
+	backoffTimes   map[string]int
+	backoffSleepMS map[string]int
 }
 
 // NewBackofferWithVars creates a Backoffer with maximum sleep time(in ms) and kv.Variables.
@@ -33,6 +54,13 @@ func NewBackofferWithVars(ctx context.Context, maxSleep int, vars *kv.Variables)
 	return &Backoffer{b: b}
 }
 
+// NewBackofferWithPolicy creates a Backoffer with maximum sleep time(in ms) and kv.Variables, whose Backoff
+// sleeps as the policy decides. The backoffs made inside client-go still follow the configs of client-go.
+func NewBackofferWithPolicy(ctx context.Context, maxSleep int, vars *kv.Variables, policy Policy) *Backoffer {
+	b := tikv.NewBackofferWithVars(ctx, maxSleep, vars)
+	return &Backoffer{b: b, policy: policy, maxSleep: maxSleep}
+}
+
 // NewBackoffer creates a Backoffer with maximum sleep time(in ms).
 func NewBackoffer(ctx context.Context, maxSleep int) *Backoffer {
 	b := tikv.NewBackoffer(ctx, maxSleep)
@@ -47,10 +75,70 @@ func (b *Backoffer) TiKVBackoffer() *tikv.Backoffer {
 // Backoff sleeps a while base on the BackoffConfig and records the error message.
 // It returns a retryable error if total sleep time exceeds maxSleep.
 func (b *Backoffer) Backoff(cfg *tikv.BackoffConfig, err error) error {
+	if b.policy != nil {
+		return b.backoffWithPolicy(cfg.String(), err)
+	}
 	e := b.b.Backoff(cfg, err)
 	return derr.ToTiDBErr(e)
 }
 
+func (b *Backoffer) backoffWithPolicy(tp string, err error) error {
+	ctx := b.b.GetCtx()
+	select {
+	case <-ctx.Done():
+		return errors.Trace(err)
+	default:
+	}
+	if b.maxSleep > 0 && b.GetTotalSleep() >= b.maxSleep {
+		logutil.Logger(ctx).Warn("backoffer.maxSleep is exceeded",
+			zap.String("type", tp), zap.Int("maxSleep", b.maxSleep), zap.Error(err))
+		return exceededError(tp, err)
+	}
+	sleep := b.policy.Sleep(tp, b.backoffTimes[tp])
+	if sleep > 0 {
+		timer := time.NewTimer(time.Duration(sleep) * time.Millisecond)
+		start := time.Now()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			sleep = int(time.Since(start) / time.Millisecond)
+		}
+	}
+	b.totalSleep += sleep
+	if b.backoffTimes == nil {
+		b.backoffTimes = make(map[string]int)
+		b.backoffSleepMS = make(map[string]int)
+	}
+	b.backoffTimes[tp]++
+	b.backoffSleepMS[tp] += sleep
+	if detail, ok := ctx.Value(util.ExecDetailsKey).(*util.ExecDetails); ok {
+		atomic.AddInt64(&detail.BackoffDuration, int64(sleep)*int64(time.Millisecond))
+		atomic.AddInt64(&detail.BackoffCount, 1)
+	}
+	if vars := b.b.GetVars(); vars != nil && vars.Killed != nil && atomic.LoadUint32(vars.Killed) == 1 {
+		return derr.ErrQueryInterrupted
+	}
+	return nil
+}
+
+// exceededError returns the error when the max sleep time is exceeded on the errors of the backoff type, like
+// the one returned by client-go.
+func exceededError(tp string, err error) error {
+	switch tp {
+	case tikv.BoTiKVRPC().String():
+		return derr.ErrTiKVServerTimeout
+	case tikv.BoTiFlashRPC().String():
+		return derr.ErrTiFlashServerTimeout
+	case tikv.BoRegionMiss().String():
+		return derr.ErrRegionUnavailable
+	case tikv.BoTxnLock().String():
+		return derr.ErrResolveLockTimeout
+	default:
+		return derr.ToTiDBErr(err)
+	}
+}
+
 // BackoffWithMaxSleepTxnLockFast sleeps a while for the operation TxnLockFast and records the error message
 // and never sleep more than maxSleepMs for each sleep.
 func (b *Backoffer) BackoffWithMaxSleepTxnLockFast(maxSleepMs int, err error) error {
@@ -60,7 +148,7 @@ func (b *Backoffer) BackoffWithMaxSleepTxnLockFast(maxSleepMs int, err error) er
 
 // GetBackoffTimes returns a map contains backoff time count by type.
 func (b *Backoffer) GetBackoffTimes() map[string]int {
-	return b.b.GetBackoffTimes()
+	return mergeCounts(b.b.GetBackoffTimes(), b.backoffTimes)
 }
 
 // GetCtx returns the binded context.
@@ -75,10 +163,24 @@ func (b *Backoffer) GetVars() *tikv.Variables {
 
 // GetBackoffSleepMS returns a map contains backoff sleep time by type.
 func (b *Backoffer) GetBackoffSleepMS() map[string]int {
-	return b.b.GetBackoffSleepMS()
+	return mergeCounts(b.b.GetBackoffSleepMS(), b.backoffSleepMS)
 }
 
 // GetTotalSleep returns total sleep time.
 func (b *Backoffer) GetTotalSleep() int {
-	return b.b.GetTotalSleep()
+	return b.b.GetTotalSleep() + b.totalSleep
+}
+
+func mergeCounts(a, b map[string]int) map[string]int {
+	if len(b) == 0 {
+		return a
+	}
+	result := make(map[string]int, len(a)+len(b))
+	for k, v := range a {
+		result[k] += v
+	}
+	for k, v := range b {
+		result[k] += v
+	}
+	return result
 }
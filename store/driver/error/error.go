@@ -55,6 +55,8 @@ var (
 	ErrRegionUnavailable = dbterror.ClassTiKV.NewStd(errno.ErrRegionUnavailable)
 	// ErrUnknown is the unknow error.
 	ErrUnknown = dbterror.ClassTiKV.NewStd(errno.ErrUnknown)
+	// ErrBatchCopQueueFull is the error when too many batch cop requests are running and waiting in the tidb-server.
+	ErrBatchCopQueueFull = dbterror.ClassTiKV.NewStd(errno.ErrBatchCopQueueFull)
 )
 
 // Registers error returned from TiKV.
@@ -168,3 +170,16 @@ func ToTiDBErr(err error) error {
 
 	return errors.Trace(err)
 }
+
+// IsTransientStoreError checks whether the error is caused by a transient outage of TiKV, TiFlash or PD,
+// which may disappear once the regions and the stores recover. The read-only statements meeting such
+// errors can be executed again from the same snapshot.
+func IsTransientStoreError(err error) bool {
+	if err == nil {
+		return false
+	}
+	err = ToTiDBErr(err)
+	return ErrTiKVServerTimeout.Equal(err) || ErrTiFlashServerTimeout.Equal(err) ||
+		ErrTiKVServerBusy.Equal(err) || ErrTiFlashServerBusy.Equal(err) ||
+		ErrPDServerTimeout.Equal(err) || ErrRegionUnavailable.Equal(err)
+}
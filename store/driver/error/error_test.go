@@ -45,3 +45,14 @@ func TestConvertError(t *testing.T) {
 		assert.True(t, errors.ErrorEqual(tidbErr, terror.ErrResultUndetermined))
 	}
 }
+
+func TestIsTransientStoreError(t *testing.T) {
+	assert.False(t, IsTransientStoreError(nil))
+	assert.False(t, IsTransientStoreError(errors.New("dummy")))
+	assert.False(t, IsTransientStoreError(ErrQueryInterrupted))
+	assert.True(t, IsTransientStoreError(ErrRegionUnavailable))
+	assert.True(t, IsTransientStoreError(errors.Trace(ErrTiFlashServerTimeout)))
+	// The errors of client-go are converted first.
+	assert.True(t, IsTransientStoreError(errors.Trace(tikverr.ErrTiKVServerTimeout)))
+	assert.True(t, IsTransientStoreError(&tikverr.ErrPDServerTimeout{}))
+}
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/pingcap/errors"
+	"github.com/pingcap/failpoint"
 	deadlockpb "github.com/pingcap/kvproto/pkg/deadlock"
 	"github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pingcap/tidb/kv"
@@ -32,7 +33,9 @@ import (
 	derr "github.com/pingcap/tidb/store/driver/error"
 	txn_driver "github.com/pingcap/tidb/store/driver/txn"
 	"github.com/pingcap/tidb/store/gcworker"
+	"github.com/pingcap/tidb/store/helper"
 	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/netsim"
 	"github.com/tikv/client-go/v2/config"
 	"github.com/tikv/client-go/v2/tikv"
 	"github.com/tikv/client-go/v2/tikvrpc"
@@ -158,7 +161,12 @@ func (d TiKVDriver) OpenWithOptions(path string, options ...Option) (kv.Storage,
 	}
 
 	pdClient := tikv.CodecPDClient{Client: pdCli}
-	s, err := tikv.NewKVStore(uuid, &pdClient, spkv, tikv.NewRPCClient(tikv.WithSecurity(d.security)))
+	var rpcClient tikv.Client = tikv.NewRPCClient(tikv.WithSecurity(d.security))
+	// The simulated network of the sessions takes effect only in the failpoint-enabled builds.
+	failpoint.Inject("simulateStoreNetwork", func() {
+		rpcClient = netsim.NewClient(rpcClient)
+	})
+	s, err := tikv.NewKVStore(uuid, &pdClient, spkv, rpcClient)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -179,6 +187,7 @@ func (d TiKVDriver) OpenWithOptions(path string, options ...Option) (kv.Storage,
 		enableGC:  !disableGC,
 		coprStore: coprStore,
 	}
+	coprStore.SetPDHelper(helper.NewHelper(store))
 
 	mc.cache[uuid] = store
 	return store, nil
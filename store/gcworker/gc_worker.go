@@ -42,14 +42,16 @@ import (
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/metrics"
 	"github.com/pingcap/tidb/parser/model"
-	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/session"
+	"github.com/pingcap/tidb/sessionctx/clustersetting"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/admin"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/gcutil"
 	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/sqlexec"
 	tikverr "github.com/tikv/client-go/v2/error"
 	tikvstore "github.com/tikv/client-go/v2/kv"
 	"github.com/tikv/client-go/v2/oracle"
@@ -135,6 +137,7 @@ const (
 	gcLastRunTimeKey       = "tikv_gc_last_run_time"
 	gcRunIntervalKey       = "tikv_gc_run_interval"
 	gcDefaultRunInterval   = time.Minute * 10
+	gcMinRunInterval       = time.Minute * 10
 	gcWaitTime             = time.Minute * 1
 	gcRedoDeleteRangeDelay = 24 * time.Hour
 
@@ -165,7 +168,7 @@ const (
 	gcAutoConcurrencyKey     = "tikv_gc_auto_concurrency"
 	gcDefaultAutoConcurrency = true
 
-	gcWorkerServiceSafePointID = "gc_worker"
+	gcWorkerServiceSafePointID = gcutil.GCWorkerServiceSafePointID
 
 	// Status var names start with tidb_%
 	tidbGCLastRunTime = "tidb_gc_last_run_time"
@@ -177,19 +180,31 @@ const (
 
 var gcSafePointCacheInterval = tikv.GcSafePointCacheInterval
 
-var gcVariableComments = map[string]string{
-	gcLeaderUUIDKey:      "Current GC worker leader UUID. (DO NOT EDIT)",
-	gcLeaderDescKey:      "Host name and pid of current GC leader. (DO NOT EDIT)",
-	gcLeaderLeaseKey:     "Current GC worker leader lease. (DO NOT EDIT)",
-	gcLastRunTimeKey:     "The time when last GC starts. (DO NOT EDIT)",
-	gcRunIntervalKey:     "GC run interval, at least 10m, in Go format.",
-	gcLifeTimeKey:        "All versions within life time will not be collected by GC, at least 10m, in Go format.",
-	gcSafePointKey:       "All versions after safe point can be accessed. (DO NOT EDIT)",
-	gcConcurrencyKey:     "How many goroutines used to do GC parallel, [1, 128], default 2",
-	gcEnableKey:          "Current GC enable status",
-	gcModeKey:            "Mode of GC, \"central\" or \"distributed\"",
-	gcAutoConcurrencyKey: "Let TiDB pick the concurrency automatically. If set false, tikv_gc_concurrency will be used",
-	gcScanLockModeKey:    "Mode of scanning locks, \"physical\" or \"legacy\"",
+var gcSettings = []*clustersetting.Setting{
+	{Name: gcLeaderUUIDKey, ReadOnly: true, Comment: "Current GC worker leader UUID. (DO NOT EDIT)"},
+	{Name: gcLeaderDescKey, ReadOnly: true, Comment: "Host name and pid of current GC leader. (DO NOT EDIT)"},
+	{Name: gcLeaderLeaseKey, ReadOnly: true, Comment: "Current GC worker leader lease. (DO NOT EDIT)"},
+	{Name: gcLastRunTimeKey, ReadOnly: true, Comment: "The time when last GC starts. (DO NOT EDIT)"},
+	{Name: gcRunIntervalKey, Type: clustersetting.TypeDuration, Default: gcDefaultRunInterval.String(), MinDuration: gcMinRunInterval,
+		Comment: "GC run interval, at least 10m, in Go format."},
+	{Name: gcLifeTimeKey, Type: clustersetting.TypeDuration, Default: gcDefaultLifeTime.String(), MinDuration: gcMinLifeTime,
+		Comment: "All versions within life time will not be collected by GC, at least 10m, in Go format."},
+	{Name: gcSafePointKey, ReadOnly: true, Comment: "All versions after safe point can be accessed. (DO NOT EDIT)"},
+	{Name: gcConcurrencyKey, Type: clustersetting.TypeInt, Default: strconv.Itoa(gcDefaultConcurrency), MinValue: gcMinConcurrency, MaxValue: gcMaxConcurrency,
+		Comment: "How many goroutines used to do GC parallel, [1, 128], default 2"},
+	{Name: gcEnableKey, Type: clustersetting.TypeBool, Default: strconv.FormatBool(gcDefaultEnableValue), Comment: "Current GC enable status"},
+	{Name: gcModeKey, Type: clustersetting.TypeEnum, Default: gcModeDefault, PossibleValues: []string{gcModeCentral, gcModeDistributed},
+		Comment: "Mode of GC, \"central\" or \"distributed\""},
+	{Name: gcAutoConcurrencyKey, Type: clustersetting.TypeBool, Default: strconv.FormatBool(gcDefaultAutoConcurrency),
+		Comment: "Let TiDB pick the concurrency automatically. If set false, tikv_gc_concurrency will be used"},
+	{Name: gcScanLockModeKey, Type: clustersetting.TypeEnum, Default: gcScanLockModeDefault, PossibleValues: []string{gcScanLockModeLegacy, gcScanLockModePhysical},
+		Comment: "Mode of scanning locks, \"physical\" or \"legacy\""},
+}
+
+func init() {
+	for _, s := range gcSettings {
+		clustersetting.Register(s)
+	}
 }
 
 const (
@@ -1816,27 +1831,12 @@ func (w *GCWorker) loadDurationWithDefault(key string, def time.Duration) (*time
 }
 
 func (w *GCWorker) loadValueFromSysTable(key string) (string, error) {
-	ctx := context.Background()
 	se := createSession(w.store)
 	defer se.Close()
-	rs, err := se.ExecuteInternal(ctx, `SELECT HIGH_PRIORITY (variable_value) FROM mysql.tidb WHERE variable_name=%? FOR UPDATE`, key)
-	if rs != nil {
-		defer terror.Call(rs.Close)
-	}
+	value, _, err := clustersetting.Load(context.Background(), se.(sqlexec.RestrictedSQLExecutor), key)
 	if err != nil {
 		return "", errors.Trace(err)
 	}
-	req := rs.NewChunk(nil)
-	err = rs.Next(ctx, req)
-	if err != nil {
-		return "", errors.Trace(err)
-	}
-	if req.NumRows() == 0 {
-		logutil.BgLogger().Debug("[gc worker] load kv",
-			zap.String("key", key))
-		return "", nil
-	}
-	value := req.GetRow(0).GetString(0)
 	logutil.BgLogger().Debug("[gc worker] load kv",
 		zap.String("key", key),
 		zap.String("value", value))
@@ -1844,14 +1844,9 @@ func (w *GCWorker) loadValueFromSysTable(key string) (string, error) {
 }
 
 func (w *GCWorker) saveValueToSysTable(key, value string) error {
-	const stmt = `INSERT HIGH_PRIORITY INTO mysql.tidb VALUES (%?, %?, %?)
-			       ON DUPLICATE KEY
-			       UPDATE variable_value = %?, comment = %?`
 	se := createSession(w.store)
 	defer se.Close()
-	_, err := se.ExecuteInternal(context.Background(), stmt,
-		key, value, gcVariableComments[key],
-		value, gcVariableComments[key])
+	err := clustersetting.Store(context.Background(), se.(sqlexec.RestrictedSQLExecutor), key, value)
 	logutil.BgLogger().Debug("[gc worker] save kv",
 		zap.String("key", key),
 		zap.String("value", value),
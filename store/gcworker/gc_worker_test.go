@@ -37,6 +37,8 @@ import (
 	"github.com/pingcap/tidb/domain/infosync"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/terror"
+	"github.com/pingcap/tidb/sessionctx/clustersetting"
 	"github.com/pingcap/tidb/store/mockstore"
 	"github.com/pingcap/tidb/testkit"
 	"github.com/stretchr/testify/require"
@@ -584,6 +586,38 @@ func TestCheckScanLockMode(t *testing.T) {
 	require.False(t, usePhysical)
 }
 
+func TestGCSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+		valid    bool
+	}{
+		{gcEnableKey, "ON", "true", true},
+		{gcEnableKey, "yes", "", false},
+		{gcConcurrencyKey, "128", "128", true},
+		{gcConcurrencyKey, "0", "", false},
+		{gcLifeTimeKey, "24h", "24h0m0s", true},
+		{gcLifeTimeKey, "9m59s", "", false},
+		{gcRunIntervalKey, "9m59s", "", false},
+		{gcModeKey, "CENTRAL", gcModeCentral, true},
+		{gcScanLockModeKey, "fast", "", false},
+	}
+	for _, test := range tests {
+		s := clustersetting.Get(test.name)
+		require.NotNil(t, s, test.name)
+		v, err := s.Validate(test.value)
+		if !test.valid {
+			require.True(t, terror.ErrorEqual(err, clustersetting.ErrWrongValueForClusterSetting), "%s = %s", test.name, test.value)
+			continue
+		}
+		require.NoError(t, err, "%s = %s", test.name, test.value)
+		require.Equal(t, test.expected, v)
+	}
+	require.True(t, clustersetting.Get(gcSafePointKey).ReadOnly)
+	require.Equal(t, gcDefaultLifeTime.String(), clustersetting.Get(gcLifeTimeKey).Default)
+}
+
 func TestNeedsGCOperationForStore(t *testing.T) {
 	newStore := func(state metapb.StoreState, hasEngineLabel bool, engineLabel string) *metapb.Store {
 		store := &metapb.Store{}
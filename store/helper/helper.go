@@ -779,6 +779,22 @@ func (h *Helper) GetStoreRegionsInfo(storeID uint64) (*RegionsInfo, error) {
 	return &regionsInfo, err
 }
 
+// ScanRegionsInfo gets the information of at most limit regions in the key range by using PD's api,
+// starting from the region that contains startKey. An empty endKey means the end of the key space.
+func (h *Helper) ScanRegionsInfo(startKey, endKey kv.Key, limit int) (*RegionsInfo, error) {
+	var regionsInfo RegionsInfo
+	if len(startKey) > 0 {
+		startKey = codec.EncodeBytes(nil, startKey)
+	}
+	if len(endKey) > 0 {
+		endKey = codec.EncodeBytes(nil, endKey)
+	}
+	uri := fmt.Sprintf("%s?key=%s&end_key=%s&limit=%d", pdapi.ScanRegions,
+		url.QueryEscape(string(startKey)), url.QueryEscape(string(endKey)), limit)
+	err := h.requestPD("GET", uri, nil, &regionsInfo)
+	return &regionsInfo, err
+}
+
 // GetRegionInfoByID gets the region information of the region ID by using PD's api.
 func (h *Helper) GetRegionInfoByID(regionID uint64) (*RegionInfo, error) {
 	var regionInfo RegionInfo
@@ -786,6 +802,27 @@ func (h *Helper) GetRegionInfoByID(regionID uint64) (*RegionInfo, error) {
 	return &regionInfo, err
 }
 
+// ServiceGCSafePoint is the safe point a service registers to PD to block the GC.
+type ServiceGCSafePoint struct {
+	ServiceID string `json:"service_id"`
+	// ExpiredAt is the unix time in seconds when the safe point expires.
+	ExpiredAt int64  `json:"expired_at"`
+	SafePoint uint64 `json:"safe_point"`
+}
+
+// ServiceGCSafePoints is the json response of the GC safe point api of PD.
+type ServiceGCSafePoints struct {
+	ServiceGCSafePoints []ServiceGCSafePoint `json:"service_gc_safe_points"`
+	GCSafePoint         uint64               `json:"gc_safe_point"`
+}
+
+// GetServiceGCSafePoints gets the GC safe point and the service safe points registered to PD by using PD's api.
+func (h *Helper) GetServiceGCSafePoints() (*ServiceGCSafePoints, error) {
+	var safePoints ServiceGCSafePoints
+	err := h.requestPD("GET", pdapi.GCSafePoint, nil, &safePoints)
+	return &safePoints, err
+}
+
 // request PD API, decode the response body into res
 func (h *Helper) requestPD(method, uri string, body io.Reader, res interface{}) error {
 	etcd, ok := h.Store.(kv.EtcdBackend)
@@ -1018,6 +1055,39 @@ func (h *Helper) SetPlacementRule(rule placement.Rule) error {
 	return nil
 }
 
+// MergeRegion asks PD to merge the source region into its adjacent target region by creating a merge operator.
+func (h *Helper) MergeRegion(sourceRegionID, targetRegionID uint64) error {
+	pdAddrs, err := h.GetPDAddr()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	m, _ := json.Marshal(map[string]interface{}{
+		"name":             "merge-region",
+		"source_region_id": sourceRegionID,
+		"target_region_id": targetRegionID,
+	})
+
+	postURL := fmt.Sprintf("%s://%s%s",
+		util.InternalHTTPSchema(),
+		pdAddrs[0],
+		pdapi.Operators,
+	)
+	resp, err := util.InternalHTTPClient().Post(postURL, "application/json", bytes.NewBuffer(m))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			logutil.BgLogger().Error("err", zap.Error(err))
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return errors.Errorf("merge region %d into %d failed: %s", sourceRegionID, targetRegionID, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
 // GetGroupRules to get all placement rule in a certain group.
 func (h *Helper) GetGroupRules(group string) ([]placement.Rule, error) {
 	pdAddrs, err := h.GetPDAddr()
@@ -19,6 +19,7 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -115,6 +116,19 @@ func TestTiKVStoresStat(t *testing.T) {
 	require.Equal(t, expected, string(data))
 }
 
+func TestServiceGCSafePoints(t *testing.T) {
+	store, clean := createMockStore(t)
+	defer clean()
+
+	h := helper.Helper{
+		Store:       store,
+		RegionCache: store.GetRegionCache(),
+	}
+	safePoints, err := h.GetServiceGCSafePoints()
+	require.NoError(t, err)
+	require.Equal(t, getMockServiceGCSafePoints(), safePoints)
+}
+
 type mockStore struct {
 	helper.Storage
 	pdAddrs []string
@@ -168,6 +182,7 @@ func mockPDHTTPServer() *httptest.Server {
 	router.HandleFunc(pdapi.HotRead, mockHotRegionResponse)
 	router.HandleFunc(pdapi.Regions, mockTiKVRegionsInfoResponse)
 	router.HandleFunc(pdapi.Stores, mockStoreStatResponse)
+	router.HandleFunc(pdapi.GCSafePoint, mockGCSafePointResponse)
 	serverMux := http.NewServeMux()
 	serverMux.Handle("/", router)
 	return httptest.NewServer(serverMux)
@@ -435,6 +450,29 @@ func mockStoreStatResponse(w http.ResponseWriter, _ *http.Request) {
 	}
 }
 
+func getMockServiceGCSafePoints() *helper.ServiceGCSafePoints {
+	return &helper.ServiceGCSafePoints{
+		ServiceGCSafePoints: []helper.ServiceGCSafePoint{
+			{ServiceID: "gc_worker", ExpiredAt: math.MaxInt64, SafePoint: 430000000000000000},
+			{ServiceID: "ticdc", ExpiredAt: 1650000000, SafePoint: 430000000000000001},
+		},
+		GCSafePoint: 430000000000000000,
+	}
+}
+
+func mockGCSafePointResponse(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	data, err := json.MarshalIndent(getMockServiceGCSafePoints(), "", "	")
+	if err != nil {
+		log.Panic("json marshal failed", zap.Error(err))
+	}
+	_, err = w.Write(data)
+	if err != nil {
+		log.Panic("write http response failed", zap.Error(err))
+	}
+}
+
 func TestComputeTiFlashStatus(t *testing.T) {
 	regionReplica := make(map[int64]int)
 	// There are no region in this TiFlash store.
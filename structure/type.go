@@ -110,6 +110,12 @@ func (t *TxStructure) hashDataKeyPrefix(key []byte) kv.Key {
 	return codec.EncodeUint(ek, uint64(HashData))
 }
 
+// EncodeHashKeyRange returns the key range of all the fields of the hash.
+func (t *TxStructure) EncodeHashKeyRange(key []byte) kv.KeyRange {
+	prefix := t.hashDataKeyPrefix(key)
+	return kv.KeyRange{StartKey: prefix, EndKey: prefix.PrefixNext()}
+}
+
 func (t *TxStructure) encodeListMetaKey(key []byte) kv.Key {
 	ek := make([]byte, 0, len(t.prefix)+len(key)+24)
 	ek = append(ek, t.prefix...)
@@ -104,6 +104,10 @@ var (
 	ErrTempTableFull = dbterror.ClassTable.NewStd(mysql.ErrRecordFileFull)
 	// ErrOptOnCacheTable returns when exec unsupported opt at cache mode
 	ErrOptOnCacheTable = dbterror.ClassDDL.NewStd(mysql.ErrOptOnCacheTable)
+	// ErrRowSizeTooLarge returns when the written row exceeds tidb_max_row_size or txn-entry-size-limit.
+	ErrRowSizeTooLarge = dbterror.ClassTable.NewStd(mysql.ErrRowSizeTooLarge)
+	// ErrColumnValueTooLarge returns when the written column value exceeds tidb_max_column_size.
+	ErrColumnValueTooLarge = dbterror.ClassTable.NewStd(mysql.ErrColumnValueTooLarge)
 )
 
 // RecordIterFunc is used for low-level record iteration.
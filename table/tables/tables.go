@@ -24,6 +24,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
@@ -427,6 +428,9 @@ func (t *TableCommon) UpdateRecord(ctx context.Context, sctx sessionctx.Context,
 	if err != nil {
 		return err
 	}
+	if err = t.checkRowSize(sessVars, key, value, colIDs, row); err != nil {
+		return err
+	}
 	if err = memBuffer.Set(key, value); err != nil {
 		return err
 	}
@@ -816,6 +820,9 @@ func (t *TableCommon) AddRecord(sctx sessionctx.Context, r []types.Datum, opts .
 		return nil, err
 	}
 	value := writeBufs.RowValBuf
+	if err = t.checkRowSize(sessVars, key, value, colIDs, row); err != nil {
+		return nil, err
+	}
 
 	var setPresume bool
 	if !sctx.GetSessionVars().StmtCtx.BatchCheck {
@@ -1555,6 +1562,56 @@ func (t *TableCommon) Type() table.Type {
 	return table.NormalTable
 }
 
+// checkRowSize checks the row written to the table against tidb_max_column_size, tidb_max_row_size and the
+// txn-entry-size-limit config, so an oversized row fails with the names of the table and its largest column
+// instead of the entry too large error of the transaction.
+func (t *TableCommon) checkRowSize(sessVars *variable.SessionVars, key kv.Key, value []byte, colIDs []int64, row []types.Datum) error {
+	maxRowSize := atomic.LoadUint64(&kv.TxnEntrySizeLimit)
+	if sessVars.MaxRowSize > 0 && sessVars.MaxRowSize < maxRowSize {
+		maxRowSize = sessVars.MaxRowSize
+	}
+	rowSize := uint64(len(key) + len(value))
+	if rowSize <= maxRowSize && sessVars.MaxColumnSize == 0 {
+		return nil
+	}
+	largestColID, largestColSize := int64(0), uint64(0)
+	for i, d := range row {
+		size := datumValueSize(d)
+		if sessVars.MaxColumnSize > 0 && size > sessVars.MaxColumnSize {
+			return table.ErrColumnValueTooLarge.GenWithStackByArgs(t.columnName(colIDs[i]), size, sessVars.MaxColumnSize)
+		}
+		if size > largestColSize {
+			largestColID, largestColSize = colIDs[i], size
+		}
+	}
+	if rowSize > maxRowSize {
+		return table.ErrRowSizeTooLarge.GenWithStackByArgs(t.meta.Name.O, rowSize, maxRowSize, t.columnName(largestColID), largestColSize)
+	}
+	return nil
+}
+
+func (t *TableCommon) columnName(colID int64) string {
+	for _, col := range t.meta.Columns {
+		if col.ID == colID {
+			return col.Name.O
+		}
+	}
+	return ""
+}
+
+// datumValueSize returns the size of the value stored for the datum, the fixed-size values are counted as 8 bytes.
+func datumValueSize(d types.Datum) uint64 {
+	switch d.Kind() {
+	case types.KindString, types.KindBytes, types.KindBinaryLiteral, types.KindMysqlBit:
+		return uint64(len(d.GetBytes()))
+	case types.KindMysqlJSON:
+		return uint64(len(d.GetMysqlJSON().Value))
+	case types.KindNull:
+		return 0
+	}
+	return 8
+}
+
 func shouldWriteBinlog(ctx sessionctx.Context, tblInfo *model.TableInfo) bool {
 	if ctx.GetSessionVars().BinlogClient == nil {
 		return false
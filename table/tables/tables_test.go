@@ -441,6 +441,32 @@ func TestShardRowIDBitsStep(t *testing.T) {
 	require.Equal(t, 4, len(shards))
 }
 
+func TestRowSizeLimit(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (id int primary key, b longblob, c text)")
+	tk.MustExec("insert into t values (1, repeat('a', 100), 'c')")
+
+	tk.MustExec("set @@tidb_max_column_size = 50")
+	tk.MustGetErrMsg("insert into t values (2, 'b', repeat('c', 51))",
+		"[table:8251]The value of column 'c' is 51 bytes, exceeds the max column size 50 bytes")
+	tk.MustGetErrMsg("update t set c = 'd' where id = 1",
+		"[table:8251]The value of column 'b' is 100 bytes, exceeds the max column size 50 bytes")
+	tk.MustExec("insert into t values (2, 'b', repeat('c', 50))")
+
+	tk.MustExec("set @@tidb_max_column_size = 0")
+	tk.MustExec("set @@tidb_max_row_size = 200")
+	tk.MustGetErrMsg("insert into t values (3, repeat('b', 200), 'c')",
+		"[table:8250]The row of table 't' is 232 bytes, exceeds the max row size 200 bytes, the largest column is 'b' of 200 bytes")
+	tk.MustGetErrMsg("update t set b = repeat('b', 200) where id = 1",
+		"[table:8250]The row of table 't' is 232 bytes, exceeds the max row size 200 bytes, the largest column is 'b' of 200 bytes")
+	tk.MustExec("set @@tidb_max_row_size = 0")
+	tk.MustExec("update t set b = repeat('b', 200) where id = 1")
+	tk.MustQuery("select id, length(b), length(c) from t").Check(testkit.Rows("1 200 1", "2 1 50"))
+}
+
 func TestHiddenColumn(t *testing.T) {
 	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
 	defer clean()
@@ -15,9 +15,12 @@
 package telemetry
 
 import (
+	"encoding/json"
 	"time"
 
+	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
 )
 
 type telemetryData struct {
@@ -31,26 +34,64 @@ type telemetryData struct {
 	SlowQueryStats     *slowQueryStats         `json:"slowQueryStats"`
 }
 
+// reportCategories records which optional categories of the usage data are reported.
+// Each category can be opted out by its own global variable.
+type reportCategories struct {
+	FeatureUsage   bool `json:"featureUsage"`
+	Hardware       bool `json:"hardware"`
+	SlowQueryStats bool `json:"slowQueryStats"`
+}
+
+// getReportCategories reads the category switches from global variables. A category whose
+// switch cannot be read is regarded as opted out.
+func getReportCategories(ctx sessionctx.Context) reportCategories {
+	isOn := func(name string) bool {
+		val, err := ctx.GetSessionVars().GlobalVarsAccessor.GetGlobalSysVar(name)
+		return err == nil && variable.TiDBOptOn(val)
+	}
+	return reportCategories{
+		FeatureUsage:   isOn(variable.TiDBTelemetryReportFeatureUsage),
+		Hardware:       isOn(variable.TiDBTelemetryReportHardware),
+		SlowQueryStats: isOn(variable.TiDBTelemetryReportSlowQuery),
+	}
+}
+
+// GetReportCategories returns the switches of the optional telemetry categories in JSON.
+func GetReportCategories(ctx sessionctx.Context) (string, error) {
+	j, err := json.Marshal(getReportCategories(ctx))
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return string(j), nil
+}
+
 func generateTelemetryData(ctx sessionctx.Context, trackingID string) telemetryData {
 	r := telemetryData{
 		ReportTimestamp: time.Now().Unix(),
 		TrackingID:      trackingID,
 	}
-	if h, err := getClusterHardware(ctx); err == nil {
-		r.Hardware = h
+	categories := getReportCategories(ctx)
+	if categories.Hardware {
+		if h, err := getClusterHardware(ctx); err == nil {
+			r.Hardware = h
+		}
+		r.TelemetryHostExtra = getTelemetryHostExtraInfo()
 	}
 	if i, err := getClusterInfo(ctx); err == nil {
 		r.Instances = i
 	}
-	if f, err := getFeatureUsage(ctx); err == nil {
-		r.FeatureUsage = f
+	if categories.FeatureUsage {
+		if f, err := getFeatureUsage(ctx); err == nil {
+			r.FeatureUsage = f
+		}
 	}
-	if s, err := getSlowQueryStats(ctx); err == nil {
-		r.SlowQueryStats = s
+	if categories.SlowQueryStats {
+		if s, err := getSlowQueryStats(ctx); err == nil {
+			r.SlowQueryStats = s
+		}
 	}
 
 	r.WindowedStats = getWindowData()
-	r.TelemetryHostExtra = getTelemetryHostExtraInfo()
 	return r
 }
 
@@ -71,7 +71,22 @@ func PreviewUsageData(ctx sessionctx.Context, etcdClient *clientv3.Client) (stri
 	if enabled, err := IsTelemetryEnabled(ctx); err != nil || !enabled {
 		return "", err
 	}
+	return generateUsageDataJSON(ctx, etcdClient)
+}
+
+// AuditUsageData returns the usage data that would be reported for ADMIN SHOW TELEMETRY.
+// Unlike PreviewUsageData, it ignores tidb_enable_telemetry, so that the data can be audited before enabling.
+func AuditUsageData(ctx sessionctx.Context, etcdClient *clientv3.Client) (string, error) {
+	if etcdClient == nil {
+		return "", nil
+	}
+	if !config.GetGlobalConfig().EnableTelemetry {
+		return "", nil
+	}
+	return generateUsageDataJSON(ctx, etcdClient)
+}
 
+func generateUsageDataJSON(ctx sessionctx.Context, etcdClient *clientv3.Client) (string, error) {
 	trackingID, err := GetTrackingID(etcdClient)
 	if err != nil {
 		return "", errors.Trace(err)
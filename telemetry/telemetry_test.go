@@ -87,6 +87,21 @@ func TestPreview(t *testing.T) {
 	require.Equal(t, "tikv", jsonParsed.Path("instances.1.instanceType").Data().(string))
 	require.True(t, jsonParsed.ExistsP("hardware"))
 
+	_, err = se.Execute(context.Background(), "SET @@global.tidb_telemetry_report_hardware = 0")
+	require.NoError(t, err)
+	r, err = telemetry.PreviewUsageData(se, etcdCluster.RandClient())
+	require.NoError(t, err)
+	jsonParsed, err = gabs.ParseJSON([]byte(r))
+	require.NoError(t, err)
+	require.Nil(t, jsonParsed.Path("hardware").Data())
+	require.Nil(t, jsonParsed.Path("hostExtra").Data())
+	require.Len(t, jsonParsed.Path("instances").Children(), 2)
+	categories, err := telemetry.GetReportCategories(se)
+	require.NoError(t, err)
+	require.Equal(t, `{"featureUsage":true,"hardware":false,"slowQueryStats":true}`, categories)
+	_, err = se.Execute(context.Background(), "SET @@global.tidb_telemetry_report_hardware = 1")
+	require.NoError(t, err)
+
 	_, err = se.Execute(context.Background(), "SET @@global.tidb_enable_telemetry = 0")
 	require.NoError(t, err)
 	r, err = telemetry.PreviewUsageData(se, etcdCluster.RandClient())
@@ -102,6 +117,39 @@ func TestPreview(t *testing.T) {
 	require.Equal(t, "", r)
 }
 
+func TestAuditUsageData(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("integration.NewClusterV3 will create file contains a colon which is not allowed on Windows")
+	}
+
+	etcdCluster := integration.NewClusterV3(t, &integration.ClusterConfig{Size: 1})
+	defer etcdCluster.Terminate(t)
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	se, err := session.CreateSession4Test(store)
+	require.NoError(t, err)
+	defer se.Close()
+
+	trackingID, err := telemetry.ResetTrackingID(etcdCluster.RandClient())
+	require.NoError(t, err)
+
+	// The data is available for auditing before tidb_enable_telemetry is turned on.
+	config.GetGlobalConfig().EnableTelemetry = true
+	_, err = se.Execute(context.Background(), "SET @@global.tidb_enable_telemetry = 0")
+	require.NoError(t, err)
+	r, err := telemetry.AuditUsageData(se, etcdCluster.RandClient())
+	require.NoError(t, err)
+	jsonParsed, err := gabs.ParseJSON([]byte(r))
+	require.NoError(t, err)
+	require.Equal(t, trackingID, jsonParsed.Path("trackingId").Data().(string))
+
+	// The config still disables telemetry on the instance.
+	config.GetGlobalConfig().EnableTelemetry = false
+	r, err = telemetry.AuditUsageData(se, etcdCluster.RandClient())
+	require.NoError(t, err)
+	require.Equal(t, "", r)
+}
+
 func TestReport(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("integration.NewClusterV3 will create file contains a colon which is not allowed on Windows")
@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/terror"
@@ -374,6 +375,14 @@ func MockGC(tk *TestKit) (string, string, string, func()) {
 	return timeBeforeDrop, timeAfterDrop, safePointSQL, resetGC
 }
 
+// MockMemTable makes the memory table of information_schema return the rows, every row has the values of the
+// columns of the table in order, the columns missing at the end are NULL. The returned function restores the real rows.
+func MockMemTable(table string, rows ...[]interface{}) func() {
+	mock := infoschema.NewMemTableMock()
+	mock.SetRows(table, rows...)
+	return infoschema.SetMemTableMockProvider(mock)
+}
+
 func containGlobal(rs *Result) bool {
 	partitionNameCol := 2
 	for i := range rs.rows {
@@ -53,10 +53,12 @@ import (
 	"github.com/pingcap/tidb/store/driver"
 	"github.com/pingcap/tidb/store/mockstore"
 	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/admission"
 	"github.com/pingcap/tidb/util/cpuprofile"
 	"github.com/pingcap/tidb/util/deadlockhistory"
 	"github.com/pingcap/tidb/util/disk"
 	"github.com/pingcap/tidb/util/domainutil"
+	_ "github.com/pingcap/tidb/util/extfile/parquet" // register the Parquet format of the EXTERNAL table function
 	"github.com/pingcap/tidb/util/kvcache"
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/memory"
@@ -67,7 +69,6 @@ import (
 	storageSys "github.com/pingcap/tidb/util/sys/storage"
 	"github.com/pingcap/tidb/util/systimemon"
 	"github.com/pingcap/tidb/util/topsql"
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/push"
 	"github.com/tikv/client-go/v2/tikv"
 	"github.com/tikv/client-go/v2/txnkv/transaction"
@@ -113,6 +114,9 @@ const (
 
 	nmInitializeSecure   = "initialize-secure"
 	nmInitializeInsecure = "initialize-insecure"
+
+	nmUpgradeDryRun      = "upgrade-dry-run"
+	nmUpgradeWaitTimeout = "upgrade-wait-timeout"
 )
 
 var (
@@ -159,6 +163,10 @@ var (
 	// Security
 	initializeSecure   = flagBoolean(nmInitializeSecure, false, "bootstrap tidb-server in secure mode")
 	initializeInsecure = flagBoolean(nmInitializeInsecure, true, "bootstrap tidb-server in insecure mode")
+
+	// Upgrade
+	upgradeDryRun      = flagBoolean(nmUpgradeDryRun, false, "print what the upgrade of the store would do and exit")
+	upgradeWaitTimeout = flag.String(nmUpgradeWaitTimeout, "0s", "the max duration to wait for the upgrade running by a newer tidb-server before serving, \"0s\" means not to wait")
 )
 
 func main() {
@@ -199,7 +207,13 @@ func main() {
 	setupBinlogClient()
 	setupMetrics()
 
+	if *upgradeDryRun {
+		dryRunUpgrade()
+		os.Exit(0)
+	}
+
 	storage, dom := createStoreAndDomain()
+	waitUpgrade(storage)
 	svr := createServer(storage, dom)
 
 	// Register error API is not thread-safe, the caller MUST NOT register errors after initialization.
@@ -298,6 +312,35 @@ func createStoreAndDomain() (kv.Storage, *domain.Domain) {
 	return storage, dom
 }
 
+// waitUpgrade blocks the connections until the system tables are upgraded by the newer tidb-server. It gives up
+// after the timeout, so the server still starts if the upgrading tidb-server crashed.
+func waitUpgrade(storage kv.Storage) {
+	timeout := parseDuration(*upgradeWaitTimeout)
+	if timeout == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := session.WaitUpgrade(ctx, storage)
+	if errors.Cause(err) == context.DeadlineExceeded {
+		logutil.BgLogger().Warn("[Upgrade] the upgrade running by another tidb-server doesn't finish in time, serve anyway",
+			zap.Duration("timeout", timeout))
+		return
+	}
+	terror.MustNil(err)
+}
+
+func dryRunUpgrade() {
+	cfg := config.GetGlobalConfig()
+	fullPath := fmt.Sprintf("%s://%s", cfg.Store, cfg.Path)
+	storage, err := kvstore.New(fullPath)
+	terror.MustNil(err)
+	report, err := session.DryRunUpgrade(storage)
+	terror.MustNil(err)
+	fmt.Println(report)
+	terror.Log(storage.Close())
+}
+
 func setupBinlogClient() {
 	cfg := config.GetGlobalConfig()
 	if !cfg.Binlog.Enable {
@@ -352,7 +395,7 @@ func prometheusPushClient(addr string, interval time.Duration) {
 	// TODO: TiDB do not have uniq name, so we use host+port to compose a name.
 	job := "tidb"
 	pusher := push.New(addr, job)
-	pusher = pusher.Gatherer(prometheus.DefaultGatherer)
+	pusher = pusher.Gatherer(metrics.Gatherer())
 	pusher = pusher.Grouping("instance", instanceName())
 	for {
 		err := pusher.Push()
@@ -541,6 +584,8 @@ func setGlobalVars() {
 	// The default value of MaxProcs is 0, runtime.GOMAXPROCS(0) is no-op.
 	runtime.GOMAXPROCS(int(cfg.Performance.MaxProcs))
 	metrics.MaxProcs.Set(float64(runtime.GOMAXPROCS(0)))
+	metrics.SetLabelValueLimit(cfg.Status.MetricsLabelValueLimit)
+	metrics.SetHistogramSampleRate(cfg.Status.MetricsHistogramSampleRate)
 
 	util.SetGOGC(cfg.Performance.GOGC)
 
@@ -568,6 +613,9 @@ func setGlobalVars() {
 		log.Fatal("cannot set txn entry size limit larger than 120M")
 	}
 	kv.TxnEntrySizeLimit = cfg.Performance.TxnEntrySizeLimit
+	if cfg.AdmissionControl.MaxInteractiveConcurrency > 0 || cfg.AdmissionControl.MaxBatchConcurrency > 0 {
+		executor.AdmissionQueue = admission.NewQueue(cfg.AdmissionControl.MaxInteractiveConcurrency, cfg.AdmissionControl.MaxBatchConcurrency)
+	}
 
 	priority := mysql.Str2Priority(cfg.Performance.ForcePriority)
 	variable.ForcePriority = int32(priority)
@@ -0,0 +1,174 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb/metrics"
+)
+
+// Class is the class of the statements admitted by the Queue.
+type Class int
+
+const (
+	// Interactive is the class of the statements whose latency matters, like the OLTP queries.
+	Interactive Class = iota
+	// Batch is the class of the heavy statements, like the analytical reports.
+	Batch
+)
+
+// String implements the fmt.Stringer interface.
+func (c Class) String() string {
+	if c == Batch {
+		return "batch"
+	}
+	return "interactive"
+}
+
+// checkInterruptedInterval is the interval the statements waiting in the queue check whether they are interrupted.
+const checkInterruptedInterval = 100 * time.Millisecond
+
+// Queue limits the number of the statements of each class executed at the same time, so a burst of the batch
+// statements doesn't starve the interactive ones. The statements over the limit of their class wait in the queue
+// until the running ones of the class finish, in the order they come.
+type Queue struct {
+	classes [2]*classQueue
+}
+
+type classQueue struct {
+	class Class
+	// limit is the max concurrency of the class, 0 means the class is not limited.
+	limit int
+
+	mu      sync.Mutex
+	running int
+	// waiters are the channels of the statements waiting in the queue in the order they come. A statement is
+	// admitted by closing its channel, which hands the slot of a finished statement over to it.
+	waiters list.List
+}
+
+// NewQueue creates a Queue, the statements of a class are not limited if its max concurrency is 0.
+func NewQueue(maxInteractiveConcurrency, maxBatchConcurrency uint) *Queue {
+	q := &Queue{}
+	for class, concurrency := range []uint{maxInteractiveConcurrency, maxBatchConcurrency} {
+		q.classes[class] = &classQueue{class: Class(class), limit: int(concurrency)}
+	}
+	return q
+}
+
+// TryAdmit admits the statement of the class if it doesn't exceed the max concurrency of the class and no statement
+// of the class is waiting in the queue. The returned function must be called once the statement finishes if it's
+// admitted.
+func (q *Queue) TryAdmit(class Class) (release func(), ok bool) {
+	cq := q.classes[class]
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	if !cq.tryAdmitLocked() {
+		return nil, false
+	}
+	return cq.release, true
+}
+
+// Admit waits in the queue until the statement of the class is admitted, the returned function must be called once
+// the statement finishes. It stops waiting and returns the error if the ctx is done or interrupted returns an error,
+// which is checked periodically.
+func (q *Queue) Admit(ctx context.Context, class Class, interrupted func() error) (release func(), err error) {
+	cq := q.classes[class]
+	cq.mu.Lock()
+	if cq.tryAdmitLocked() {
+		cq.mu.Unlock()
+		return cq.release, nil
+	}
+	admitted := make(chan struct{})
+	waiter := cq.waiters.PushBack(admitted)
+	cq.observeLocked()
+	cq.mu.Unlock()
+
+	start := time.Now()
+	defer func() {
+		metrics.AdmissionQueueWaitHistogram.WithLabelValues(class.String()).Observe(time.Since(start).Seconds())
+	}()
+	ticker := time.NewTicker(checkInterruptedInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-admitted:
+			return cq.release, nil
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-ticker.C:
+			err = interrupted()
+		}
+		if err != nil {
+			cq.leave(waiter)
+			return nil, err
+		}
+	}
+}
+
+// Stats returns the number of the statements of the class queued and running.
+func (q *Queue) Stats(class Class) (queued, running int) {
+	cq := q.classes[class]
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	return cq.waiters.Len(), cq.running
+}
+
+func (cq *classQueue) tryAdmitLocked() bool {
+	if cq.limit > 0 && (cq.running >= cq.limit || cq.waiters.Len() > 0) {
+		return false
+	}
+	cq.running++
+	cq.observeLocked()
+	return true
+}
+
+// leave removes the statement which stops waiting from the queue. If it's admitted at the same time, the slot is
+// released for the next one.
+func (cq *classQueue) leave(waiter *list.Element) {
+	cq.mu.Lock()
+	select {
+	case <-waiter.Value.(chan struct{}):
+		cq.mu.Unlock()
+		cq.release()
+		return
+	default:
+	}
+	cq.waiters.Remove(waiter)
+	cq.observeLocked()
+	cq.mu.Unlock()
+}
+
+func (cq *classQueue) release() {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	if first := cq.waiters.Front(); first != nil {
+		// The slot is handed over to the first statement waiting in the queue, so the running ones don't change.
+		cq.waiters.Remove(first)
+		close(first.Value.(chan struct{}))
+	} else {
+		cq.running--
+	}
+	cq.observeLocked()
+}
+
+func (cq *classQueue) observeLocked() {
+	metrics.AdmissionQueueGauge.WithLabelValues(cq.class.String(), "queued").Set(float64(cq.waiters.Len()))
+	metrics.AdmissionQueueGauge.WithLabelValues(cq.class.String(), "running").Set(float64(cq.running))
+}
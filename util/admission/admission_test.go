@@ -0,0 +1,141 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue(t *testing.T) {
+	q := NewQueue(0, 1)
+
+	// The interactive statements are not limited.
+	var releases []func()
+	for i := 0; i < 3; i++ {
+		release, ok := q.TryAdmit(Interactive)
+		require.True(t, ok)
+		releases = append(releases, release)
+	}
+	queued, running := q.Stats(Interactive)
+	require.Equal(t, 0, queued)
+	require.Equal(t, 3, running)
+	for _, release := range releases {
+		release()
+	}
+
+	// The batch statements over the limit wait until the running one finishes.
+	release, ok := q.TryAdmit(Batch)
+	require.True(t, ok)
+	_, ok = q.TryAdmit(Batch)
+	require.False(t, ok)
+	admitted := make(chan func())
+	go func() {
+		release, err := q.Admit(context.Background(), Batch, func() error { return nil })
+		require.NoError(t, err)
+		admitted <- release
+	}()
+	require.Eventually(t, func() bool {
+		queued, _ := q.Stats(Batch)
+		return queued == 1
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case <-admitted:
+		require.FailNow(t, "the statement is admitted over the limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	release = <-admitted
+	queued, running = q.Stats(Batch)
+	require.Equal(t, 0, queued)
+	require.Equal(t, 1, running)
+
+	// The queued statements stop waiting once they are interrupted or the ctx is done.
+	errKilled := errors.New("killed")
+	_, err := q.Admit(context.Background(), Batch, func() error { return errKilled })
+	require.Equal(t, errKilled, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = q.Admit(ctx, Batch, func() error { return nil })
+	require.Equal(t, context.DeadlineExceeded, err)
+	queued, running = q.Stats(Batch)
+	require.Equal(t, 0, queued)
+	require.Equal(t, 1, running)
+	release()
+}
+
+func TestQueueOrder(t *testing.T) {
+	q := NewQueue(0, 1)
+	release, ok := q.TryAdmit(Batch)
+	require.True(t, ok)
+
+	// The statements waiting in the queue are admitted in the order they come.
+	admitted := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func(i int) {
+			release, err := q.Admit(context.Background(), Batch, func() error { return nil })
+			require.NoError(t, err)
+			admitted <- i
+			release()
+		}(i)
+		require.Eventually(t, func() bool {
+			queued, _ := q.Stats(Batch)
+			return queued == i+1
+		}, time.Second, 10*time.Millisecond)
+	}
+	// A new statement doesn't jump the queue even if it doesn't wait.
+	_, ok = q.TryAdmit(Batch)
+	require.False(t, ok)
+	release()
+	for i := 0; i < 3; i++ {
+		require.Equal(t, i, <-admitted)
+	}
+	require.Eventually(t, func() bool {
+		queued, running := q.Stats(Batch)
+		return queued == 0 && running == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// The slot is handed over to the next statement if the first one stops waiting.
+	release, ok = q.TryAdmit(Batch)
+	require.True(t, ok)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() {
+		_, err := q.Admit(ctx, Batch, func() error { return nil })
+		errCh <- err
+	}()
+	require.Eventually(t, func() bool {
+		queued, _ := q.Stats(Batch)
+		return queued == 1
+	}, time.Second, 10*time.Millisecond)
+	go func() {
+		release, err := q.Admit(context.Background(), Batch, func() error { return nil })
+		require.NoError(t, err)
+		admitted <- 3
+		release()
+	}()
+	require.Eventually(t, func() bool {
+		queued, _ := q.Stats(Batch)
+		return queued == 2
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	require.Equal(t, context.Canceled, <-errCh)
+	release()
+	require.Equal(t, 3, <-admitted)
+}
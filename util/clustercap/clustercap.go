@@ -0,0 +1,141 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercap
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/pingcap/errors"
+)
+
+// Component is a kind of the nodes of the cluster.
+type Component string
+
+// The components whose versions gate the features.
+const (
+	TiDB    Component = "tidb"
+	TiKV    Component = "tikv"
+	TiFlash Component = "tiflash"
+)
+
+// Feature is a feature that can be used only when all the nodes of its component support it.
+type Feature struct {
+	Name       string
+	Component  Component
+	MinVersion semver.Version
+}
+
+var features []*Feature
+
+func register(name string, component Component, minVersion string) *Feature {
+	f := &Feature{Name: name, Component: component, MinVersion: *semver.New(minVersion)}
+	features = append(features, f)
+	return f
+}
+
+// The version-gated features. A feature used by the planner or DDL since some version should be registered
+// here, so it's not used before all the nodes of the cluster are upgraded.
+var (
+	// FeatureMPP is the MPP execution of TiFlash.
+	FeatureMPP = register("MPP execution", TiFlash, "5.0.0")
+	// FeatureHashExchangeWithNewCollation is the hash exchange of MPP on the string keys with the new collation.
+	FeatureHashExchangeWithNewCollation = register("hash exchange with new collation", TiFlash, "5.2.0")
+	// FeaturePlacementPolicy is the placement policies and the placement options of the tables.
+	FeaturePlacementPolicy = register("placement policy", TiDB, "5.3.0")
+	// FeatureCachedTable is the cached tables.
+	FeatureCachedTable = register("cached table", TiDB, "6.0.0")
+)
+
+// Features returns all the version-gated features.
+func Features() []*Feature {
+	return features
+}
+
+// clusterVersions is the min version of the nodes of each component in the cluster. A component is absent if
+// the versions of its nodes are unknown, e.g. the cluster has no such nodes or they are built without a
+// release version, then the features of it are regarded as supported.
+var clusterVersions struct {
+	sync.RWMutex
+	minVersions map[Component]*semver.Version
+}
+
+// ParseVersion parses the version of a node, e.g. "v5.4.0", "5.4.0-alpha" and "5.7.25-TiDB-v5.4.0".
+// The pre-release and build metadata are ignored, so the nightly builds support the features of their release.
+func ParseVersion(version string) (*semver.Version, bool) {
+	if idx := strings.Index(version, "-TiDB-"); idx >= 0 {
+		version = version[idx+len("-TiDB-"):]
+	}
+	v, err := semver.NewVersion(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return nil, false
+	}
+	return &semver.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}, true
+}
+
+// Update replaces the versions of the nodes in the cluster, keyed by the components. The versions that
+// can't be parsed are ignored.
+func Update(versions map[Component][]string) {
+	minVersions := make(map[Component]*semver.Version, len(versions))
+	for component, vs := range versions {
+		for _, version := range vs {
+			v, ok := ParseVersion(version)
+			if !ok {
+				continue
+			}
+			if min, ok := minVersions[component]; !ok || v.LessThan(*min) {
+				minVersions[component] = v
+			}
+		}
+	}
+	clusterVersions.Lock()
+	defer clusterVersions.Unlock()
+	clusterVersions.minVersions = minVersions
+}
+
+// MinVersion returns the min version of the nodes of the component, it returns false if it's unknown.
+func MinVersion(component Component) (semver.Version, bool) {
+	clusterVersions.RLock()
+	defer clusterVersions.RUnlock()
+	v, ok := clusterVersions.minVersions[component]
+	if !ok {
+		return semver.Version{}, false
+	}
+	return *v, true
+}
+
+// IsSupported returns whether all the nodes of the component of the feature support it.
+func IsSupported(f *Feature) bool {
+	v, ok := MinVersion(f.Component)
+	return !ok || !v.LessThan(f.MinVersion)
+}
+
+// Check returns an error if some nodes of the cluster don't support the feature.
+func Check(f *Feature) error {
+	v, ok := MinVersion(f.Component)
+	if !ok || !v.LessThan(f.MinVersion) {
+		return nil
+	}
+	return errors.Errorf("%s requires all the %s nodes to be v%s or later, but some of them are v%s",
+		f.Name, f.Component, f.MinVersion, v)
+}
+
+// Reset forgets the versions of the nodes, so all the features are regarded as supported.
+func Reset() {
+	clusterVersions.Lock()
+	defer clusterVersions.Unlock()
+	clusterVersions.minVersions = nil
+}
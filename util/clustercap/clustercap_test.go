@@ -0,0 +1,79 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	for _, c := range []struct {
+		version  string
+		expected string
+	}{
+		{"v5.4.0", "5.4.0"},
+		{"5.4.1", "5.4.1"},
+		{"6.0.0-alpha", "6.0.0"},
+		{"v6.0.0-nightly+build", "6.0.0"},
+		{"5.7.25-TiDB-v5.3.2", "5.3.2"},
+		{"5.7.25-TiDB-v6.0.0-alpha-123-g1234567", "6.0.0"},
+	} {
+		v, ok := ParseVersion(c.version)
+		require.True(t, ok, c.version)
+		require.Equal(t, c.expected, v.String(), c.version)
+	}
+	for _, version := range []string{"", "None", "5.7.25-TiDB-None", "x.y.z"} {
+		_, ok := ParseVersion(version)
+		require.False(t, ok, version)
+	}
+}
+
+func TestIsSupported(t *testing.T) {
+	defer Reset()
+
+	// All the features are supported before the versions are known.
+	for _, f := range Features() {
+		require.True(t, IsSupported(f), f.Name)
+		require.NoError(t, Check(f))
+	}
+
+	Update(map[Component][]string{
+		TiDB:    {"5.7.25-TiDB-v6.0.0", "5.7.25-TiDB-v5.4.0", "5.7.25-TiDB-None"},
+		TiKV:    {"6.0.0"},
+		TiFlash: {"v5.1.0", "v6.0.0"},
+	})
+	v, ok := MinVersion(TiDB)
+	require.True(t, ok)
+	require.Equal(t, "5.4.0", v.String())
+	require.True(t, IsSupported(FeaturePlacementPolicy))
+	require.False(t, IsSupported(FeatureCachedTable))
+	require.EqualError(t, Check(FeatureCachedTable),
+		"cached table requires all the tidb nodes to be v6.0.0 or later, but some of them are v5.4.0")
+	require.True(t, IsSupported(FeatureMPP))
+	require.False(t, IsSupported(FeatureHashExchangeWithNewCollation))
+
+	// The component without known versions supports all its features.
+	Update(map[Component][]string{TiFlash: {"v6.0.0"}, TiDB: {"None"}})
+	_, ok = MinVersion(TiDB)
+	require.False(t, ok)
+	require.True(t, IsSupported(FeatureCachedTable))
+	require.True(t, IsSupported(FeatureHashExchangeWithNewCollation))
+
+	Reset()
+	_, ok = MinVersion(TiFlash)
+	require.False(t, ok)
+}
@@ -16,6 +16,7 @@ package dbterror
 
 import (
 	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
 )
 
@@ -55,3 +56,9 @@ var (
 func (ec ErrClass) NewStd(code terror.ErrCode) *terror.Error {
 	return ec.NewStdErr(code, errno.MySQLErrName[uint16(code)])
 }
+
+// NewStdErr creates an error of the class with the message, and registers the class of the code.
+func (ec ErrClass) NewStdErr(code terror.ErrCode, message *mysql.ErrMessage) *terror.Error {
+	errno.RegisterErrorInfo(ec.String(), uint16(code))
+	return ec.ErrClass.NewStdErr(code, message)
+}
@@ -86,26 +86,42 @@ func InitializeTempDir() error {
 		return err
 	}
 
-	// If it exists others files except lock file, creates another goroutine to clean them.
-	if len(subDirs) > 2 {
-		go func() {
-			for _, subDir := range subDirs {
-				// Do not remove the lock file.
-				switch subDir.Name() {
-				case lockFile, recordDir:
-					continue
-				}
-				err := os.RemoveAll(filepath.Join(tempDir, subDir.Name()))
-				if err != nil {
-					log.Warn("Remove temporary file error",
-						zap.String("tempStorageSubDir", filepath.Join(tempDir, subDir.Name())), zap.Error(err))
-				}
-			}
-		}()
+	// Remove the files left by the previous instance in another goroutine, like the spilled files
+	// of the queries running when it crashed.
+	if hasOrphanedFiles(subDirs) {
+		go removeOrphanedFiles(tempDir, subDirs)
 	}
 	return nil
 }
 
+// hasOrphanedFiles returns whether there are other files than the lock file and the record directory.
+func hasOrphanedFiles(subDirs []os.DirEntry) bool {
+	for _, subDir := range subDirs {
+		switch subDir.Name() {
+		case lockFile, recordDir:
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// removeOrphanedFiles removes the files in the temp directory except the lock file and the record directory.
+func removeOrphanedFiles(tempDir string, subDirs []os.DirEntry) {
+	for _, subDir := range subDirs {
+		// Do not remove the lock file.
+		switch subDir.Name() {
+		case lockFile, recordDir:
+			continue
+		}
+		err := os.RemoveAll(filepath.Join(tempDir, subDir.Name()))
+		if err != nil {
+			log.Warn("Remove temporary file error",
+				zap.String("tempStorageSubDir", filepath.Join(tempDir, subDir.Name())), zap.Error(err))
+		}
+	}
+}
+
 // CleanUp releases the directory lock when exiting TiDB.
 func CleanUp() {
 	if tempDirLock != nil {
@@ -16,6 +16,7 @@ package disk
 
 import (
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
@@ -53,3 +54,27 @@ func TestRemoveDir(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, checkTempDirExist(), true)
 }
+
+func TestRemoveOrphanedFiles(t *testing.T) {
+	path := t.TempDir()
+	for _, name := range []string{lockFile, recordDir, "chunk.ListInDisk1", "chunk.ListInDisk2"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(path, name), 0755))
+	}
+	subDirs, err := os.ReadDir(path)
+	require.NoError(t, err)
+	require.True(t, hasOrphanedFiles(subDirs))
+
+	// The lock file and the record directory are kept.
+	removeOrphanedFiles(path, subDirs)
+	subDirs, err = os.ReadDir(path)
+	require.NoError(t, err)
+	require.Len(t, subDirs, 2)
+	require.False(t, hasOrphanedFiles(subDirs))
+
+	// A single orphaned file is removed too.
+	require.NoError(t, os.RemoveAll(filepath.Join(path, recordDir)))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "chunk.ListInDisk3"), nil, 0644))
+	subDirs, err = os.ReadDir(path)
+	require.NoError(t, err)
+	require.True(t, hasOrphanedFiles(subDirs))
+}
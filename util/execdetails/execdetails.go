@@ -53,6 +53,26 @@ var StmtExecDetailKey = stmtExecDetailKeyType{}
 // StmtExecDetails contains stmt level execution detail info.
 type StmtExecDetails struct {
 	WriteSQLRespDuration time.Duration
+	// ClientStallDuration is the time blocked in writing the response to the connection, it's mostly
+	// the time waiting for the client to read the response when the socket buffer is full.
+	ClientStallDuration time.Duration
+}
+
+// TiDBExecDuration returns the time TiDB spends on executing the statement itself, which is the execution time
+// excluding the time waiting for TiKV and PD, the backoff and writing the response to the client. The CPU time
+// of a goroutine can't be measured, so it also includes the time waiting for the locks and being scheduled.
+// The waits of the concurrent requests overlap, it's 0 if they add up to more than the execution time.
+func TiDBExecDuration(execTime time.Duration, stmtDetail *StmtExecDetails, tikvDetail *util.ExecDetails) time.Duration {
+	d := execTime - stmtDetail.WriteSQLRespDuration
+	if tikvDetail != nil {
+		d -= time.Duration(atomic.LoadInt64(&tikvDetail.WaitKVRespDuration)) +
+			time.Duration(atomic.LoadInt64(&tikvDetail.WaitPDRespDuration)) +
+			time.Duration(atomic.LoadInt64(&tikvDetail.BackoffDuration))
+	}
+	if d < 0 {
+		return 0
+	}
+	return d
 }
 
 const (
@@ -108,95 +128,111 @@ const (
 	RocksdbBlockReadByteStr = "Rocksdb_block_read_byte"
 )
 
-// String implements the fmt.Stringer interface.
-func (d ExecDetails) String() string {
-	parts := make([]string, 0, 8)
+// SlowLogField is a "key: value" field of the slow log.
+type SlowLogField struct {
+	Key   string
+	Value string
+}
+
+// ToSlowLogFields wraps the ExecDetails as the fields of the slow log.
+func (d ExecDetails) ToSlowLogFields() []SlowLogField {
+	fields := make([]SlowLogField, 0, 8)
 	if d.CopTime > 0 {
-		parts = append(parts, CopTimeStr+": "+strconv.FormatFloat(d.CopTime.Seconds(), 'f', -1, 64))
+		fields = append(fields, SlowLogField{CopTimeStr, strconv.FormatFloat(d.CopTime.Seconds(), 'f', -1, 64)})
 	}
 	if d.TimeDetail.ProcessTime > 0 {
-		parts = append(parts, ProcessTimeStr+": "+strconv.FormatFloat(d.TimeDetail.ProcessTime.Seconds(), 'f', -1, 64))
+		fields = append(fields, SlowLogField{ProcessTimeStr, strconv.FormatFloat(d.TimeDetail.ProcessTime.Seconds(), 'f', -1, 64)})
 	}
 	if d.TimeDetail.WaitTime > 0 {
-		parts = append(parts, WaitTimeStr+": "+strconv.FormatFloat(d.TimeDetail.WaitTime.Seconds(), 'f', -1, 64))
+		fields = append(fields, SlowLogField{WaitTimeStr, strconv.FormatFloat(d.TimeDetail.WaitTime.Seconds(), 'f', -1, 64)})
 	}
 	if d.BackoffTime > 0 {
-		parts = append(parts, BackoffTimeStr+": "+strconv.FormatFloat(d.BackoffTime.Seconds(), 'f', -1, 64))
+		fields = append(fields, SlowLogField{BackoffTimeStr, strconv.FormatFloat(d.BackoffTime.Seconds(), 'f', -1, 64)})
 	}
 	if d.LockKeysDuration > 0 {
-		parts = append(parts, LockKeysTimeStr+": "+strconv.FormatFloat(d.LockKeysDuration.Seconds(), 'f', -1, 64))
+		fields = append(fields, SlowLogField{LockKeysTimeStr, strconv.FormatFloat(d.LockKeysDuration.Seconds(), 'f', -1, 64)})
 	}
 	if d.RequestCount > 0 {
-		parts = append(parts, RequestCountStr+": "+strconv.FormatInt(int64(d.RequestCount), 10))
+		fields = append(fields, SlowLogField{RequestCountStr, strconv.FormatInt(int64(d.RequestCount), 10)})
 	}
 	commitDetails := d.CommitDetail
 	if commitDetails != nil {
 		if commitDetails.PrewriteTime > 0 {
-			parts = append(parts, PreWriteTimeStr+": "+strconv.FormatFloat(commitDetails.PrewriteTime.Seconds(), 'f', -1, 64))
+			fields = append(fields, SlowLogField{PreWriteTimeStr, strconv.FormatFloat(commitDetails.PrewriteTime.Seconds(), 'f', -1, 64)})
 		}
 		if commitDetails.WaitPrewriteBinlogTime > 0 {
-			parts = append(parts, WaitPrewriteBinlogTimeStr+": "+strconv.FormatFloat(commitDetails.WaitPrewriteBinlogTime.Seconds(), 'f', -1, 64))
+			fields = append(fields, SlowLogField{WaitPrewriteBinlogTimeStr, strconv.FormatFloat(commitDetails.WaitPrewriteBinlogTime.Seconds(), 'f', -1, 64)})
 		}
 		if commitDetails.CommitTime > 0 {
-			parts = append(parts, CommitTimeStr+": "+strconv.FormatFloat(commitDetails.CommitTime.Seconds(), 'f', -1, 64))
+			fields = append(fields, SlowLogField{CommitTimeStr, strconv.FormatFloat(commitDetails.CommitTime.Seconds(), 'f', -1, 64)})
 		}
 		if commitDetails.GetCommitTsTime > 0 {
-			parts = append(parts, GetCommitTSTimeStr+": "+strconv.FormatFloat(commitDetails.GetCommitTsTime.Seconds(), 'f', -1, 64))
+			fields = append(fields, SlowLogField{GetCommitTSTimeStr, strconv.FormatFloat(commitDetails.GetCommitTsTime.Seconds(), 'f', -1, 64)})
 		}
 		commitDetails.Mu.Lock()
 		commitBackoffTime := commitDetails.Mu.CommitBackoffTime
 		if commitBackoffTime > 0 {
-			parts = append(parts, CommitBackoffTimeStr+": "+strconv.FormatFloat(time.Duration(commitBackoffTime).Seconds(), 'f', -1, 64))
+			fields = append(fields, SlowLogField{CommitBackoffTimeStr, strconv.FormatFloat(time.Duration(commitBackoffTime).Seconds(), 'f', -1, 64)})
 		}
 		if len(commitDetails.Mu.BackoffTypes) > 0 {
-			parts = append(parts, BackoffTypesStr+": "+fmt.Sprintf("%v", commitDetails.Mu.BackoffTypes))
+			fields = append(fields, SlowLogField{BackoffTypesStr, fmt.Sprintf("%v", commitDetails.Mu.BackoffTypes)})
 		}
 		commitDetails.Mu.Unlock()
 		resolveLockTime := atomic.LoadInt64(&commitDetails.ResolveLockTime)
 		if resolveLockTime > 0 {
-			parts = append(parts, ResolveLockTimeStr+": "+strconv.FormatFloat(time.Duration(resolveLockTime).Seconds(), 'f', -1, 64))
+			fields = append(fields, SlowLogField{ResolveLockTimeStr, strconv.FormatFloat(time.Duration(resolveLockTime).Seconds(), 'f', -1, 64)})
 		}
 		if commitDetails.LocalLatchTime > 0 {
-			parts = append(parts, LocalLatchWaitTimeStr+": "+strconv.FormatFloat(commitDetails.LocalLatchTime.Seconds(), 'f', -1, 64))
+			fields = append(fields, SlowLogField{LocalLatchWaitTimeStr, strconv.FormatFloat(commitDetails.LocalLatchTime.Seconds(), 'f', -1, 64)})
 		}
 		if commitDetails.WriteKeys > 0 {
-			parts = append(parts, WriteKeysStr+": "+strconv.FormatInt(int64(commitDetails.WriteKeys), 10))
+			fields = append(fields, SlowLogField{WriteKeysStr, strconv.FormatInt(int64(commitDetails.WriteKeys), 10)})
 		}
 		if commitDetails.WriteSize > 0 {
-			parts = append(parts, WriteSizeStr+": "+strconv.FormatInt(int64(commitDetails.WriteSize), 10))
+			fields = append(fields, SlowLogField{WriteSizeStr, strconv.FormatInt(int64(commitDetails.WriteSize), 10)})
 		}
 		prewriteRegionNum := atomic.LoadInt32(&commitDetails.PrewriteRegionNum)
 		if prewriteRegionNum > 0 {
-			parts = append(parts, PrewriteRegionStr+": "+strconv.FormatInt(int64(prewriteRegionNum), 10))
+			fields = append(fields, SlowLogField{PrewriteRegionStr, strconv.FormatInt(int64(prewriteRegionNum), 10)})
 		}
 		if commitDetails.TxnRetry > 0 {
-			parts = append(parts, TxnRetryStr+": "+strconv.FormatInt(int64(commitDetails.TxnRetry), 10))
+			fields = append(fields, SlowLogField{TxnRetryStr, strconv.FormatInt(int64(commitDetails.TxnRetry), 10)})
 		}
 	}
 	scanDetail := d.ScanDetail
 	if scanDetail != nil {
 		if scanDetail.ProcessedKeys > 0 {
-			parts = append(parts, ProcessKeysStr+": "+strconv.FormatInt(scanDetail.ProcessedKeys, 10))
+			fields = append(fields, SlowLogField{ProcessKeysStr, strconv.FormatInt(scanDetail.ProcessedKeys, 10)})
 		}
 		if scanDetail.TotalKeys > 0 {
-			parts = append(parts, TotalKeysStr+": "+strconv.FormatInt(scanDetail.TotalKeys, 10))
+			fields = append(fields, SlowLogField{TotalKeysStr, strconv.FormatInt(scanDetail.TotalKeys, 10)})
 		}
 		if scanDetail.RocksdbDeleteSkippedCount > 0 {
-			parts = append(parts, RocksdbDeleteSkippedCountStr+": "+strconv.FormatUint(scanDetail.RocksdbDeleteSkippedCount, 10))
+			fields = append(fields, SlowLogField{RocksdbDeleteSkippedCountStr, strconv.FormatUint(scanDetail.RocksdbDeleteSkippedCount, 10)})
 		}
 		if scanDetail.RocksdbKeySkippedCount > 0 {
-			parts = append(parts, RocksdbKeySkippedCountStr+": "+strconv.FormatUint(scanDetail.RocksdbKeySkippedCount, 10))
+			fields = append(fields, SlowLogField{RocksdbKeySkippedCountStr, strconv.FormatUint(scanDetail.RocksdbKeySkippedCount, 10)})
 		}
 		if scanDetail.RocksdbBlockCacheHitCount > 0 {
-			parts = append(parts, RocksdbBlockCacheHitCountStr+": "+strconv.FormatUint(scanDetail.RocksdbBlockCacheHitCount, 10))
+			fields = append(fields, SlowLogField{RocksdbBlockCacheHitCountStr, strconv.FormatUint(scanDetail.RocksdbBlockCacheHitCount, 10)})
 		}
 		if scanDetail.RocksdbBlockReadCount > 0 {
-			parts = append(parts, RocksdbBlockReadCountStr+": "+strconv.FormatUint(scanDetail.RocksdbBlockReadCount, 10))
+			fields = append(fields, SlowLogField{RocksdbBlockReadCountStr, strconv.FormatUint(scanDetail.RocksdbBlockReadCount, 10)})
 		}
 		if scanDetail.RocksdbBlockReadByte > 0 {
-			parts = append(parts, RocksdbBlockReadByteStr+": "+strconv.FormatUint(scanDetail.RocksdbBlockReadByte, 10))
+			fields = append(fields, SlowLogField{RocksdbBlockReadByteStr, strconv.FormatUint(scanDetail.RocksdbBlockReadByte, 10)})
 		}
 	}
+	return fields
+}
+
+// String implements the fmt.Stringer interface.
+func (d ExecDetails) String() string {
+	fields := d.ToSlowLogFields()
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		parts = append(parts, f.Key+": "+f.Value)
+	}
 	return strings.Join(parts, " ")
 }
 
@@ -345,6 +381,24 @@ func (crs *CopRuntimeStats) GetActRows() (totalRows int64) {
 	return totalRows
 }
 
+// GetProcessTime returns the total processing time of the cop tasks.
+func (crs *CopRuntimeStats) GetProcessTime() (totalTime time.Duration) {
+	for _, instanceStats := range crs.stats {
+		for _, stat := range instanceStats {
+			totalTime += time.Duration(stat.consume)
+		}
+	}
+	return totalTime
+}
+
+// GetProcessedKeys returns the number of the keys scanned by the cop tasks.
+func (crs *CopRuntimeStats) GetProcessedKeys() int64 {
+	if crs.scanDetail == nil {
+		return 0
+	}
+	return crs.scanDetail.ProcessedKeys
+}
+
 func (crs *CopRuntimeStats) String() string {
 	if len(crs.stats) == 0 {
 		return ""
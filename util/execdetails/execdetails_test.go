@@ -111,6 +111,8 @@ func TestCopRuntimeStats(t *testing.T) {
 	expected := "tikv_task:{proc max:2ns, min:1ns, p80:2ns, p95:2ns, iters:3, tasks:2}, " +
 		"scan_detail: {total_process_keys: 10, total_process_keys_size: 10, total_keys: 15, rocksdb: {delete_skipped_count: 5, key_skipped_count: 1, block: {cache_hit_count: 10, read_count: 20, read_byte: 100 Bytes}}}"
 	require.Equal(t, expected, cop.String())
+	require.Equal(t, 3*time.Nanosecond, cop.GetProcessTime())
+	require.Equal(t, int64(10), cop.GetProcessedKeys())
 
 	copStats := cop.stats["8.8.8.8"]
 	require.NotNil(t, copStats)
@@ -300,3 +302,15 @@ func TestFormatDurationForExplain(t *testing.T) {
 		require.Equal(t, ca.s, result)
 	}
 }
+
+func TestTiDBExecDuration(t *testing.T) {
+	stmtDetail := &StmtExecDetails{WriteSQLRespDuration: time.Second}
+	tikvDetail := &util.ExecDetails{
+		WaitKVRespDuration: int64(3 * time.Second),
+		WaitPDRespDuration: int64(time.Second),
+		BackoffDuration:    int64(time.Second),
+	}
+	require.Equal(t, 4*time.Second, TiDBExecDuration(10*time.Second, stmtDetail, tikvDetail))
+	require.Equal(t, 9*time.Second, TiDBExecDuration(10*time.Second, stmtDetail, nil))
+	require.Equal(t, time.Duration(0), TiDBExecDuration(5*time.Second, stmtDetail, tikvDetail))
+}
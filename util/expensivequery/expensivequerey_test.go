@@ -15,14 +15,18 @@
 package expensivequery
 
 import (
+	"os"
 	"testing"
 	"time"
 
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/execdetails"
 	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/testbridge"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
 )
 
@@ -72,3 +76,60 @@ func TestLogFormat(t *testing.T) {
 	logFields = genLogFields(costTime, info)
 	assert.Equal(t, "select * from table where `a` > ?", logFields[6].String)
 }
+
+func TestOOMDiagnostic(t *testing.T) {
+	dir := t.TempDir()
+	restore := config.RestoreFunc()
+	defer restore()
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.TempStoragePath = dir
+	})
+
+	parent := memory.NewTracker(memory.LabelForSQLText, 1000)
+	child := memory.NewTracker(5, -1)
+	child.AttachTo(parent)
+	child.Consume(800)
+	now := time.Now()
+	info := &util.ProcessInfo{
+		ID:              233,
+		Time:            now,
+		Info:            "select * from t",
+		PlanExplainRows: [][]string{{"TableReader_5", "10000.00", "root", "", "data:TableFullScan_4"}},
+		ExplainAnalyzeRows: func(interface{}, *execdetails.RuntimeStatsColl) [][]string {
+			return [][]string{{"TableReader_5", "10000.00", "2000", "root", "", "time:1s, loops:2", "data:TableFullScan_4", "800 Bytes", "N/A"}}
+		},
+		StatsInfo: func(interface{}) map[string]uint64 {
+			return nil
+		},
+		StmtCtx: &stmtctx.StatementContext{
+			MemTracker: parent,
+		},
+	}
+
+	d := &oomDiagnostic{}
+	var fileNames []string
+	for i := 0; i < 3; i++ {
+		fileName := d.dump(info, 2, now.Add(time.Duration(i)*time.Second))
+		require.NotEmpty(t, fileName)
+		fileNames = append(fileNames, fileName)
+	}
+	content, err := os.ReadFile(fileNames[2])
+	require.NoError(t, err)
+	require.Contains(t, string(content), "sql: select * from t")
+	require.Contains(t, string(content), "TableReader_5\t10000.00\t2000\troot\t\ttime:1s, loops:2")
+	require.Contains(t, string(content), "label: 5, consumed: 800 Bytes")
+
+	// Only the last files are kept.
+	_, err = os.Stat(fileNames[0])
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(fileNames[1])
+	require.NoError(t, err)
+
+	// The files dumped before the restart are removed too.
+	d = &oomDiagnostic{}
+	require.NotEmpty(t, d.dump(info, 1, now.Add(3*time.Second)))
+	_, err = os.Stat(fileNames[1])
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(fileNames[2])
+	require.True(t, os.IsNotExist(err))
+}
@@ -34,6 +34,8 @@ import (
 type Handle struct {
 	exitCh chan struct{}
 	sm     atomic.Value
+
+	oomDiagnostic oomDiagnostic
 }
 
 // NewExpensiveQueryHandle builds a new expensive query handler.
@@ -192,18 +192,7 @@ func (record *memoryUsageAlarm) recordSQL(sm util.SessionManager) {
 		var buf strings.Builder
 		for i, info := range list {
 			buf.WriteString(fmt.Sprintf("SQL %v: \n", i))
-			fields := genLogFields(record.lastCheckTime.Sub(info.Time), info)
-			for _, field := range fields {
-				switch field.Type {
-				case zapcore.StringType:
-					buf.WriteString(fmt.Sprintf("%v: %v", field.Key, field.String))
-				case zapcore.Uint8Type, zapcore.Uint16Type, zapcore.Uint32Type, zapcore.Uint64Type:
-					buf.WriteString(fmt.Sprintf("%v: %v", field.Key, uint64(field.Integer)))
-				case zapcore.Int8Type, zapcore.Int16Type, zapcore.Int32Type, zapcore.Int64Type:
-					buf.WriteString(fmt.Sprintf("%v: %v", field.Key, field.Integer))
-				}
-				buf.WriteString("\n")
-			}
+			writeLogFields(&buf, genLogFields(record.lastCheckTime.Sub(info.Time), info))
 		}
 		buf.WriteString("\n")
 		_, err = f.WriteString(buf.String())
@@ -220,6 +209,21 @@ func (record *memoryUsageAlarm) recordSQL(sm util.SessionManager) {
 	})
 }
 
+// writeLogFields writes the log fields of a SQL line by line.
+func writeLogFields(buf *strings.Builder, fields []zap.Field) {
+	for _, field := range fields {
+		switch field.Type {
+		case zapcore.StringType:
+			buf.WriteString(fmt.Sprintf("%v: %v", field.Key, field.String))
+		case zapcore.Uint8Type, zapcore.Uint16Type, zapcore.Uint32Type, zapcore.Uint64Type:
+			buf.WriteString(fmt.Sprintf("%v: %v", field.Key, uint64(field.Integer)))
+		case zapcore.Int8Type, zapcore.Int16Type, zapcore.Int32Type, zapcore.Int64Type:
+			buf.WriteString(fmt.Sprintf("%v: %v", field.Key, field.Integer))
+		}
+		buf.WriteString("\n")
+	}
+}
+
 func (record *memoryUsageAlarm) recordProfile() {
 	items := []struct {
 		name  string
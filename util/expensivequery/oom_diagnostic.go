@@ -0,0 +1,140 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expensivequery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/disk"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+const (
+	oomDiagnosticFilePrefix = "oom_diagnostic"
+	// oomDiagnosticTimeFormat keeps the file names sorted by their dump time.
+	oomDiagnosticTimeFormat = "20060102150405.000000"
+	// oomDiagnosticTopConsumers is the number of the memory trackers consuming the most memory in a file.
+	oomDiagnosticTopConsumers = 10
+)
+
+// oomDiagnostic dumps the diagnostic files of the queries cancelled for exceeding the memory quota, and keeps the
+// last ones of the instance.
+type oomDiagnostic struct {
+	mu          sync.Mutex
+	initialized bool
+	dir         string
+	fileNames   []string
+}
+
+func (d *oomDiagnostic) init() error {
+	d.dir = filepath.Join(config.GetGlobalConfig().TempStoragePath, "record")
+	if err := disk.CheckAndCreateDir(d.dir); err != nil {
+		return err
+	}
+	// Read the last files, they are sorted by their names.
+	files, err := os.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), oomDiagnosticFilePrefix) {
+			d.fileNames = append(d.fileNames, filepath.Join(d.dir, f.Name()))
+		}
+	}
+	d.initialized = true
+	return nil
+}
+
+// dump writes the plan, the top memory consumers and the runtime stats of the query to a file, and removes the
+// files exceeding keep. It returns the name of the file, or empty if the file fails to be written.
+func (d *oomDiagnostic) dump(info *util.ProcessInfo, keep int, now time.Time) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.initialized {
+		if err := d.init(); err != nil {
+			logutil.BgLogger().Error("init oom diagnostic record fail", zap.Error(err))
+			return ""
+		}
+	}
+	fileName := filepath.Join(d.dir, fmt.Sprintf("%s_%s_conn%d", oomDiagnosticFilePrefix, now.Format(oomDiagnosticTimeFormat), info.ID))
+	if err := os.WriteFile(fileName, []byte(formatOOMDiagnostic(info, now)), 0600); err != nil {
+		logutil.BgLogger().Error("write oom diagnostic file fail", zap.Error(err))
+		return ""
+	}
+	d.fileNames = append(d.fileNames, fileName)
+	for len(d.fileNames) > keep {
+		if err := os.Remove(d.fileNames[0]); err != nil && !os.IsNotExist(err) {
+			logutil.BgLogger().Error("remove oom diagnostic file fail", zap.Error(err))
+		}
+		d.fileNames = d.fileNames[1:]
+	}
+	return fileName
+}
+
+func formatOOMDiagnostic(info *util.ProcessInfo, now time.Time) string {
+	var buf strings.Builder
+	buf.WriteString("The SQL cancelled for exceeding the memory quota\n")
+	writeLogFields(&buf, genLogFields(now.Sub(info.Time), info))
+
+	var rows [][]string
+	if info.ExplainAnalyzeRows != nil {
+		rows = info.ExplainAnalyzeRows(info.Plan, info.RuntimeStatsColl)
+	}
+	if len(rows) == 0 {
+		rows = info.PlanExplainRows
+	}
+	buf.WriteString("\nThe plan with its runtime stats\n")
+	for _, row := range rows {
+		buf.WriteString(strings.Join(row, "\t"))
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString("\nThe memory trackers consuming the most memory\n")
+	if memTracker := info.StmtCtx.MemTracker; memTracker != nil {
+		buf.WriteString(fmt.Sprintf("label: %d, consumed: %v, quota: %v\n", memTracker.Label(),
+			memTracker.FormatBytes(memTracker.BytesConsumed()), memTracker.FormatBytes(memTracker.GetBytesLimit())))
+		for _, t := range memTracker.TopConsumers(oomDiagnosticTopConsumers) {
+			buf.WriteString(fmt.Sprintf("label: %d, consumed: %v, max consumed: %v\n", t.Label(),
+				t.FormatBytes(t.BytesConsumed()), t.FormatBytes(t.MaxConsumed())))
+		}
+	}
+	return buf.String()
+}
+
+// DumpOnQueryExceedMemQuota dumps the diagnostic file of the query of connID which is cancelled for exceeding the
+// memory quota, and returns the name of the file. Nothing is dumped if oom-diagnostic-files is 0.
+func (eqh *Handle) DumpOnQueryExceedMemQuota(connID uint64) string {
+	keep := config.GetGlobalConfig().OOMDiagnosticFiles
+	if keep <= 0 {
+		return ""
+	}
+	v := eqh.sm.Load()
+	if v == nil {
+		return ""
+	}
+	info, ok := v.(util.SessionManager).GetProcessInfo(connID)
+	if !ok || info.StmtCtx == nil {
+		return ""
+	}
+	return eqh.oomDiagnostic.dump(info, keep, time.Now())
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extfile
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/parser/charset"
+	"github.com/pingcap/tidb/types"
+)
+
+// csvNull is the value of NULL fields, it's the same as the default of LOAD DATA.
+const csvNull = `\N`
+
+type csvReader struct {
+	file    storage.ExternalFileReader
+	reader  *csv.Reader
+	header  bool
+	columns []string
+	row     []types.Datum
+}
+
+func openCSV(ctx context.Context, store storage.ExternalStorage, name string, src *Source) (RowReader, error) {
+	file, err := store.Open(ctx, name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	reader := csv.NewReader(bufio.NewReader(file))
+	reader.Comma = src.Separator
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	return &csvReader{file: file, reader: reader, header: src.Header}, nil
+}
+
+// Columns implements the RowReader interface.
+func (r *csvReader) Columns() []string {
+	return r.columns
+}
+
+// ReadRow implements the RowReader interface.
+func (r *csvReader) ReadRow() ([]types.Datum, error) {
+	if r.header {
+		record, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		r.header = false
+		r.columns = make([]string, 0, len(record))
+		for _, name := range record {
+			r.columns = append(r.columns, strings.ToLower(name))
+		}
+	}
+	record, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	r.row = r.row[:0]
+	for _, field := range record {
+		var d types.Datum
+		if field != csvNull {
+			d.SetString(field, charset.CollationUTF8MB4)
+		}
+		r.row = append(r.row, d)
+	}
+	return r.row, nil
+}
+
+func (r *csvReader) read() ([]string, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	return record, errors.Trace(err)
+}
+
+// Close implements the RowReader interface.
+func (r *csvReader) Close() error {
+	return r.file.Close()
+}
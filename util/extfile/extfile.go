@@ -0,0 +1,214 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extfile
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/parser/charset"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/types"
+)
+
+// The formats of the external files.
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// RowReader decodes the rows of a file in some format.
+type RowReader interface {
+	// Columns returns the lower-case column names stored in the file, it returns nil if there are none.
+	// It's called after the first row is read.
+	Columns() []string
+	// ReadRow returns the next row, it returns io.EOF if there are no more rows.
+	// The returned row is only valid until the next call.
+	ReadRow() ([]types.Datum, error)
+	Close() error
+}
+
+// Opener opens a file of some format in the external storage.
+type Opener func(ctx context.Context, store storage.ExternalStorage, name string, src *Source) (RowReader, error)
+
+var (
+	openersMu sync.RWMutex
+	openers   = map[string]Opener{FormatCSV: openCSV}
+)
+
+// Register registers the Opener of a format. The Parquet format is registered by the
+// `util/extfile/parquet` package, so the Parquet library is only linked when it's imported.
+func Register(format string, opener Opener) {
+	openersMu.Lock()
+	defer openersMu.Unlock()
+	openers[format] = opener
+}
+
+// Source describes an external file read by the EXTERNAL table function.
+type Source struct {
+	// URL is the location of the file, it's parsed by the external storage of BR.
+	URL string
+	// Format is FormatCSV or FormatParquet.
+	Format string
+	// Header means the first line of a CSV file contains the column names.
+	Header bool
+	// Separator is the field separator of a CSV file.
+	Separator rune
+}
+
+// NewSource creates a Source with the default options: the format is decided by the file extension,
+// and a CSV file is separated by commas and has a header line.
+func NewSource(rawURL string) *Source {
+	s := &Source{URL: rawURL, Format: FormatCSV, Header: true, Separator: ','}
+	if u, err := url.Parse(rawURL); err == nil && strings.EqualFold(path.Ext(u.Path), ".parquet") {
+		s.Format = FormatParquet
+	}
+	return s
+}
+
+// Redacted returns the URL without the query parameters, which may contain the credentials.
+func (s *Source) Redacted() string {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return "?"
+	}
+	u.RawQuery = ""
+	u.User = nil
+	return u.String()
+}
+
+// Column is a column of an external file.
+type Column struct {
+	// Name is the lower-case name of the column.
+	Name string
+	Type *types.FieldType
+}
+
+// Reader reads the rows of an external file.
+type Reader struct {
+	RowReader
+	// columns is the number of columns in each row, a row is padded with NULL or truncated to it.
+	columns int
+}
+
+// Open opens the external file.
+func (s *Source) Open(ctx context.Context) (*Reader, error) {
+	openersMu.RLock()
+	opener, ok := openers[s.Format]
+	openersMu.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("the %s format of external files is not supported", s.Format)
+	}
+
+	u, err := storage.ParseRawURL(s.URL)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	name := path.Base(u.Path)
+	u.Path = path.Dir(u.Path)
+	backend, err := storage.ParseBackend(u.String(), nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	store, err := storage.New(ctx, backend, &storage.ExternalStorageOptions{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	rowReader, err := opener(ctx, store, name, s)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &Reader{RowReader: rowReader}, nil
+}
+
+// Columns infers the columns from the first row of the external file. The names of the columns
+// are taken from the header line of a CSV file, or are `c1`, `c2`, ... if there is no header.
+// The columns of a CSV file are strings, while the integer and float columns of a Parquet file
+// are typed according to their values.
+func (s *Source) Columns(ctx context.Context) ([]Column, error) {
+	r, err := s.Open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	row, err := r.ReadRow()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	names := r.RowReader.Columns()
+	if names == nil {
+		names = make([]string, len(row))
+		for i := range names {
+			names[i] = "c" + strconv.Itoa(i+1)
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.Errorf("can't infer the columns of %s", s.Redacted())
+	}
+	columns := make([]Column, len(names))
+	for i, name := range names {
+		columns[i] = Column{Name: name, Type: inferType(row, i)}
+	}
+	return columns, nil
+}
+
+func inferType(row []types.Datum, i int) *types.FieldType {
+	var ft *types.FieldType
+	if i < len(row) {
+		switch row[i].Kind() {
+		case types.KindInt64:
+			ft = types.NewFieldType(mysql.TypeLonglong)
+		case types.KindUint64:
+			ft = types.NewFieldType(mysql.TypeLonglong)
+			ft.Flag |= mysql.UnsignedFlag
+		case types.KindFloat32, types.KindFloat64:
+			ft = types.NewFieldType(mysql.TypeDouble)
+		}
+	}
+	if ft == nil {
+		ft = types.NewFieldType(mysql.TypeLongBlob)
+		ft.Charset, ft.Collate = charset.CharsetUTF8MB4, charset.CollationUTF8MB4
+	} else {
+		ft.Charset, ft.Collate = charset.CharsetBin, charset.CollationBin
+	}
+	ft.Flen, ft.Decimal = mysql.GetDefaultFieldLengthAndDecimal(ft.Tp)
+	return ft
+}
+
+// SetColumns sets the number of columns of each row returned by Next.
+func (r *Reader) SetColumns(n int) {
+	r.columns = n
+}
+
+// Next returns the next row of the external file, it returns io.EOF if there are no more rows.
+// The returned row is only valid until the next call.
+func (r *Reader) Next() ([]types.Datum, error) {
+	row, err := r.ReadRow()
+	if err != nil {
+		return nil, err
+	}
+	for len(row) < r.columns {
+		row = append(row, types.Datum{})
+	}
+	return row[:r.columns], nil
+}
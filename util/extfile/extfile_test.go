@@ -0,0 +1,82 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extfile
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSource(t *testing.T) {
+	require.Equal(t, FormatParquet, NewSource("s3://bucket/dir/x.PARQUET?access-key=ak").Format)
+	require.Equal(t, FormatCSV, NewSource("/tmp/x.csv").Format)
+	require.Equal(t, FormatCSV, NewSource("/tmp/x").Format)
+	require.Equal(t, "s3://bucket/dir/x.parquet", NewSource("s3://bucket/dir/x.parquet?access-key=ak&secret-access-key=sk").Redacted())
+
+	_, err := NewSource("/tmp/x.parquet").Open(context.Background())
+	require.EqualError(t, err, "the parquet format of external files is not supported")
+}
+
+func TestCSV(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "t.csv")
+	require.NoError(t, os.WriteFile(path, []byte("ID,Name\n1,\"a,b\"\n2\n3,\\N,extra\n"), 0644))
+
+	src := NewSource(path)
+	columns, err := src.Columns(ctx)
+	require.NoError(t, err)
+	require.Len(t, columns, 2)
+	require.Equal(t, "id", columns[0].Name)
+	require.Equal(t, "name", columns[1].Name)
+	require.Equal(t, mysql.TypeLongBlob, columns[0].Type.Tp)
+
+	r, err := src.Open(ctx)
+	require.NoError(t, err)
+	r.SetColumns(len(columns))
+	row, err := r.Next()
+	require.NoError(t, err)
+	require.Equal(t, "1", row[0].GetString())
+	require.Equal(t, "a,b", row[1].GetString())
+	row, err = r.Next()
+	require.NoError(t, err)
+	require.Equal(t, "2", row[0].GetString())
+	require.True(t, row[1].IsNull())
+	row, err = r.Next()
+	require.NoError(t, err)
+	require.Len(t, row, 2)
+	require.True(t, row[1].IsNull())
+	_, err = r.Next()
+	require.Equal(t, io.EOF, err)
+	require.NoError(t, r.Close())
+
+	src.Header = false
+	src.Separator = '|'
+	columns, err = src.Columns(ctx)
+	require.NoError(t, err)
+	require.Len(t, columns, 1)
+	require.Equal(t, "c1", columns[0].Name)
+
+	empty := filepath.Join(dir, "empty.csv")
+	require.NoError(t, os.WriteFile(empty, nil, 0644))
+	_, err = NewSource(empty).Columns(ctx)
+	require.EqualError(t, err, "can't infer the columns of "+empty)
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package parquet registers the Parquet format of the EXTERNAL table function. The Parquet
+// library starts some goroutines when it's initialized, so it's registered only by the binaries
+// importing this package.
+package parquet
+
+import (
+	"context"
+	"io"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/extfile"
+)
+
+func init() {
+	extfile.Register(extfile.FormatParquet, open)
+}
+
+type reader struct {
+	parser *mydump.ParquetParser
+}
+
+func open(ctx context.Context, store storage.ExternalStorage, name string, _ *extfile.Source) (extfile.RowReader, error) {
+	file, err := store.Open(ctx, name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	parser, err := mydump.NewParquetParser(ctx, store, file, name)
+	if err != nil {
+		_ = file.Close()
+		return nil, errors.Trace(err)
+	}
+	return &reader{parser: parser}, nil
+}
+
+// Columns implements the extfile.RowReader interface.
+func (r *reader) Columns() []string {
+	return r.parser.Columns()
+}
+
+// ReadRow implements the extfile.RowReader interface.
+func (r *reader) ReadRow() ([]types.Datum, error) {
+	err := r.parser.ReadRow()
+	if errors.Cause(err) == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return r.parser.LastRow().Row, nil
+}
+
+// Close implements the extfile.RowReader interface.
+func (r *reader) Close() error {
+	return r.parser.Close()
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcutil
+
+import (
+	"context"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/util/logutil"
+	pd "github.com/tikv/pd/client"
+	"go.uber.org/zap"
+)
+
+// SetServiceSafePoint registers or renews the service safe point of an external tool, such as BR or TiCDC.
+// PD doesn't let the GC go past the safe point until the TTL in seconds expires.
+func SetServiceSafePoint(ctx context.Context, pdClient pd.Client, serviceID string, safePoint uint64, ttl int64) error {
+	if err := checkServiceID(serviceID); err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return errors.Errorf("the TTL of the service safe point must be positive, got %d", ttl)
+	}
+	minSafePoint, err := pdClient.UpdateServiceGCSafePoint(ctx, serviceID, ttl, safePoint)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// PD doesn't accept a safe point earlier than the current minimal one and returns the minimal one instead.
+	if minSafePoint > safePoint {
+		return errors.Errorf("the service safe point %d is earlier than the minimal service safe point %d", safePoint, minSafePoint)
+	}
+	logutil.Logger(ctx).Info("set service safe point",
+		zap.String("serviceID", serviceID),
+		zap.Uint64("safePoint", safePoint),
+		zap.Int64("ttl", ttl))
+	return nil
+}
+
+// ExpireServiceSafePoint removes the service safe point right away, so a stale safe point left by
+// a crashed tool doesn't block the GC until its TTL expires.
+func ExpireServiceSafePoint(ctx context.Context, pdClient pd.Client, serviceID string) error {
+	if err := checkServiceID(serviceID); err != nil {
+		return err
+	}
+	// A zero TTL removes the service safe point.
+	if _, err := pdClient.UpdateServiceGCSafePoint(ctx, serviceID, 0, 0); err != nil {
+		return errors.Trace(err)
+	}
+	logutil.Logger(ctx).Info("expire service safe point", zap.String("serviceID", serviceID))
+	return nil
+}
+
+// GCWorkerServiceSafePointID is the service ID of the safe point the GC worker maintains.
+const GCWorkerServiceSafePointID = "gc_worker"
+
+func checkServiceID(serviceID string) error {
+	if serviceID == "" {
+		return errors.New("the service ID of the service safe point can't be empty")
+	}
+	if serviceID == GCWorkerServiceSafePointID {
+		return errors.Errorf("the service safe point %s is maintained by the GC worker", serviceID)
+	}
+	return nil
+}
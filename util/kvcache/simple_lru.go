@@ -183,6 +183,11 @@ func (l *SimpleLRUCache) Size() int {
 	return int(l.size)
 }
 
+// Capacity gets the capacity of the cache.
+func (l *SimpleLRUCache) Capacity() uint {
+	return l.capacity
+}
+
 // Values return all values in cache.
 func (l *SimpleLRUCache) Values() []Value {
 	values := make([]Value, 0, l.cache.Len())
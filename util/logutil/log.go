@@ -44,6 +44,13 @@ const (
 	DefaultRecordPlanInSlowLog = 1
 	// DefaultTiDBEnableSlowLog enables TiDB to log slow queries.
 	DefaultTiDBEnableSlowLog = true
+	// DefaultSlowQueryCollectorBufferSize is the default number of slow query entries buffered for the collector.
+	DefaultSlowQueryCollectorBufferSize = 1024
+	// SlowLogFormatText is the text format of slow query entries, which can be parsed by INFORMATION_SCHEMA.SLOW_QUERY.
+	SlowLogFormatText = "text"
+	// SlowLogFormatJSON is the json format of slow query entries, one json object per entry, which can be parsed by
+	// INFORMATION_SCHEMA.SLOW_QUERY as well.
+	SlowLogFormatJSON = "json"
 )
 
 // EmptyFileLogConfig is an empty FileLogConfig.
@@ -68,6 +75,15 @@ type LogConfig struct {
 
 	// SlowQueryFile filename, default to File log config on empty.
 	SlowQueryFile string
+
+	// SlowQueryFormat is the format of slow query entries, SlowLogFormatText or SlowLogFormatJSON.
+	SlowQueryFormat string
+
+	// SlowQueryCollectorURL is the HTTP or gRPC endpoint that slow query entries are pushed to, empty means disabled.
+	SlowQueryCollectorURL string
+
+	// SlowQueryCollectorBufferSize is the max number of entries buffered for the collector.
+	SlowQueryCollectorBufferSize uint
 }
 
 // NewLogConfig creates a LogConfig.
@@ -79,7 +95,9 @@ func NewLogConfig(level, format, slowQueryFile string, fileCfg FileLogConfig, di
 			DisableTimestamp: disableTimestamp,
 			File:             fileCfg.FileLogConfig,
 		},
-		SlowQueryFile: slowQueryFile,
+		SlowQueryFile:                slowQueryFile,
+		SlowQueryFormat:              SlowLogFormatText,
+		SlowQueryCollectorBufferSize: DefaultSlowQueryCollectorBufferSize,
 	}
 	for _, opt := range opts {
 		opt(&c.Config)
@@ -110,6 +128,7 @@ func InitLogger(cfg *LogConfig) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	initSlowQueryCollector(cfg)
 
 	_, _, err = initGRPCLogger(cfg)
 	if err != nil {
@@ -161,6 +180,7 @@ func ReplaceLogger(cfg *LogConfig) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	initSlowQueryCollector(cfg)
 
 	log.S().Infof("replaced global logger with config: %s", string(cfgJSON))
 
@@ -17,15 +17,26 @@ package logutil
 import (
 	"bufio"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/pingcap/log"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func TestZapLoggerWithKeys(t *testing.T) {
@@ -156,3 +167,97 @@ func TestGlobalLoggerReplace(t *testing.T) {
 	err = os.Remove(fileCfg.Filename)
 	require.NoError(t, err)
 }
+
+func TestSlowLogJSONEncoder(t *testing.T) {
+	enc := &slowLogJSONEncoder{}
+	ts := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	b, err := enc.EncodeEntry(zapcore.Entry{Time: ts, Message: `{"Query":"select 1"}`}, nil)
+	require.NoError(t, err)
+	require.Equal(t, `{"Time":"2021-10-01T12:00:00Z","Query":"select 1"}`+"\n", b.String())
+
+	b, err = enc.EncodeEntry(zapcore.Entry{Time: ts, Message: "not json"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, `{"Time":"2021-10-01T12:00:00Z"}`+"\n", b.String())
+}
+
+func TestSlowQueryCollector(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		mu.Lock()
+		received = append(received, strings.Split(strings.TrimRight(string(body), "\n"), "\n")...)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	require.False(t, SlowQueryCollectorEnabled())
+	require.False(t, PushSlowQuery(`{"Query":"select 1"}`))
+
+	conf := NewLogConfig("info", DefaultLogFormat, "", EmptyFileLogConfig, false)
+	conf.SlowQueryCollectorURL = server.URL
+	conf.SlowQueryCollectorBufferSize = 2
+	initSlowQueryCollector(conf)
+	require.True(t, SlowQueryCollectorEnabled())
+	c := globalSlowQueryCollector.Load().(*slowQueryCollector)
+	require.Equal(t, 2, cap(c.entries))
+	require.True(t, PushSlowQuery(`{"Query":"select 1"}`))
+	require.True(t, PushSlowQuery(`{"Query":"select 2"}`))
+
+	// Closing the collector flushes the buffered entries.
+	initSlowQueryCollector(NewLogConfig("info", DefaultLogFormat, "", EmptyFileLogConfig, false))
+	require.False(t, SlowQueryCollectorEnabled())
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 2)
+	for i, entry := range received {
+		var item map[string]string
+		require.NoError(t, json.Unmarshal([]byte(entry), &item))
+		_, err := time.Parse(SlowLogTimeFormat, item["Time"])
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("select %d", i+1), item["Query"])
+	}
+}
+
+func TestSlowQueryCollectorGRPC(t *testing.T) {
+	received := make(chan string, 1)
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "tidb.slowlog.SlowQueryCollector",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Push",
+			Handler: func(_ interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := &wrapperspb.StringValue{}
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				received <- in.Value
+				return &emptypb.Empty{}, nil
+			},
+		}},
+	}, struct{}{})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = server.Serve(l)
+	}()
+	defer server.Stop()
+
+	c, err := newSlowQueryCollector("grpc://"+l.Addr().String(), 0)
+	require.NoError(t, err)
+	require.Equal(t, DefaultSlowQueryCollectorBufferSize, cap(c.entries))
+	require.NoError(t, c.send([]string{`{"Query":"select 1"}`, `{"Query":"select 2"}`}))
+	require.Equal(t, `{"Query":"select 1"}`+"\n"+`{"Query":"select 2"}`+"\n", <-received)
+	require.NoError(t, c.sender.close())
+}
+
+func TestSlowQueryCollectorDrop(t *testing.T) {
+	c, err := newSlowQueryCollector("http://127.0.0.1:0", 1)
+	require.NoError(t, err)
+	require.True(t, c.push("a"))
+	require.False(t, c.push("b"))
+	require.Equal(t, uint64(1), c.dropped)
+}
@@ -0,0 +1,232 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logutil
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	slowQueryCollectorBatchSize     = 64
+	slowQueryCollectorFlushInterval = time.Second
+	slowQueryCollectorTimeout       = 5 * time.Second
+
+	// slowQueryCollectorGRPCScheme is the url scheme of the collectors served by gRPC.
+	slowQueryCollectorGRPCScheme = "grpc://"
+	// slowQueryCollectorGRPCMethod is the unary method of the gRPC collectors, whose request is a
+	// google.protobuf.StringValue of the newline delimited json entries and response is a google.protobuf.Empty.
+	slowQueryCollectorGRPCMethod = "/tidb.slowlog.SlowQueryCollector/Push"
+)
+
+// slowQueryCollector pushes slow query entries to an HTTP or gRPC endpoint asynchronously.
+// Entries are buffered in a bounded channel and dropped when the buffer is full, so that a slow
+// or unavailable collector never blocks the statements.
+type slowQueryCollector struct {
+	url     string
+	sender  slowQuerySender
+	entries chan string
+	dropped uint64
+	exit    chan struct{}
+	done    chan struct{}
+}
+
+var globalSlowQueryCollector atomic.Value
+
+// slowQuerySender sends a batch of newline delimited json entries to the collector.
+type slowQuerySender interface {
+	send(ctx context.Context, body []byte) error
+	close() error
+}
+
+func newSlowQueryCollector(url string, bufferSize uint) (*slowQueryCollector, error) {
+	if bufferSize == 0 {
+		bufferSize = DefaultSlowQueryCollectorBufferSize
+	}
+	var sender slowQuerySender
+	if strings.HasPrefix(url, slowQueryCollectorGRPCScheme) {
+		conn, err := grpc.Dial(strings.TrimPrefix(url, slowQueryCollectorGRPCScheme), grpc.WithInsecure())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		sender = &grpcSlowQuerySender{conn: conn}
+	} else {
+		sender = &httpSlowQuerySender{url: url, client: &http.Client{Timeout: slowQueryCollectorTimeout}}
+	}
+	return &slowQueryCollector{
+		url:     url,
+		sender:  sender,
+		entries: make(chan string, bufferSize),
+		exit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+func initSlowQueryCollector(cfg *LogConfig) {
+	if old, ok := globalSlowQueryCollector.Load().(*slowQueryCollector); ok && old != nil {
+		old.close()
+	}
+	var c *slowQueryCollector
+	if len(cfg.SlowQueryCollectorURL) > 0 {
+		var err error
+		c, err = newSlowQueryCollector(cfg.SlowQueryCollectorURL, cfg.SlowQueryCollectorBufferSize)
+		if err != nil {
+			BgLogger().Warn("create slow query collector failed", zap.String("url", cfg.SlowQueryCollectorURL), zap.Error(err))
+		} else {
+			go c.run()
+		}
+	}
+	globalSlowQueryCollector.Store(c)
+}
+
+// SlowQueryCollectorEnabled returns whether slow query entries should be pushed by PushSlowQuery.
+func SlowQueryCollectorEnabled() bool {
+	c, ok := globalSlowQueryCollector.Load().(*slowQueryCollector)
+	return ok && c != nil
+}
+
+// PushSlowQuery pushes a slow query entry in json to the collector without blocking, the current time
+// is added to the entry as the slow query logger does.
+// It returns false if the collector is disabled or its buffer is full.
+func PushSlowQuery(entry string) bool {
+	c, ok := globalSlowQueryCollector.Load().(*slowQueryCollector)
+	if !ok || c == nil {
+		return false
+	}
+	return c.push(slowLogJSONWithTime(time.Now(), entry))
+}
+
+func (c *slowQueryCollector) push(entry string) bool {
+	select {
+	case c.entries <- entry:
+		return true
+	default:
+		atomic.AddUint64(&c.dropped, 1)
+		return false
+	}
+}
+
+func (c *slowQueryCollector) run() {
+	defer close(c.done)
+	ticker := time.NewTicker(slowQueryCollectorFlushInterval)
+	defer ticker.Stop()
+	batch := make([]string, 0, slowQueryCollectorBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := c.send(batch); err != nil {
+			BgLogger().Warn("push slow query entries failed", zap.String("url", c.url), zap.Int("entries", len(batch)), zap.Error(err))
+		}
+		batch = batch[:0]
+		if dropped := atomic.SwapUint64(&c.dropped, 0); dropped > 0 {
+			BgLogger().Warn("slow query entries are dropped because the collector buffer is full", zap.Uint64("dropped", dropped))
+		}
+	}
+	for {
+		select {
+		case entry := <-c.entries:
+			batch = append(batch, entry)
+			if len(batch) >= slowQueryCollectorBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-c.exit:
+			// Drain the buffered entries so that they are not lost on reloading the config.
+			for {
+				select {
+				case entry := <-c.entries:
+					batch = append(batch, entry)
+					if len(batch) >= slowQueryCollectorBatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// send sends the entries as newline delimited json.
+func (c *slowQueryCollector) send(entries []string) error {
+	var body bytes.Buffer
+	for _, entry := range entries {
+		body.WriteString(entry)
+		body.WriteByte('\n')
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), slowQueryCollectorTimeout)
+	defer cancel()
+	return c.sender.send(ctx, body.Bytes())
+}
+
+func (c *slowQueryCollector) close() {
+	close(c.exit)
+	<-c.done
+	if err := c.sender.close(); err != nil {
+		BgLogger().Warn("close slow query collector failed", zap.String("url", c.url), zap.Error(err))
+	}
+}
+
+type httpSlowQuerySender struct {
+	url    string
+	client *http.Client
+}
+
+func (s *httpSlowQuerySender) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("unexpected http status %d", resp.StatusCode)
+	}
+	return errors.Trace(err)
+}
+
+func (s *httpSlowQuerySender) close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+type grpcSlowQuerySender struct {
+	conn *grpc.ClientConn
+}
+
+func (s *grpcSlowQuerySender) send(ctx context.Context, body []byte) error {
+	return errors.Trace(s.conn.Invoke(ctx, slowQueryCollectorGRPCMethod, wrapperspb.String(string(body)), &emptypb.Empty{}))
+}
+
+func (s *grpcSlowQuerySender) close() error {
+	return errors.Trace(s.conn.Close())
+}
@@ -35,7 +35,11 @@ func newSlowQueryLogger(cfg *LogConfig) (*zap.Logger, *log.ZapProperties, error)
 	}
 
 	// replace 2018-12-19-unified-log-format text encoder with slow log encoder
-	newCore := log.NewTextCore(&slowLogEncoder{}, prop.Syncer, prop.Level)
+	var encoder zapcore.Encoder = &slowLogEncoder{}
+	if cfg.SlowQueryFormat == SlowLogFormatJSON {
+		encoder = &slowLogJSONEncoder{}
+	}
+	newCore := log.NewTextCore(encoder, prop.Syncer, prop.Level)
 	sqLogger = sqLogger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
 		return newCore
 	}))
@@ -93,3 +97,27 @@ func (e *slowLogEncoder) AddUint8(string, uint8)                          {}
 func (e *slowLogEncoder) AddUintptr(string, uintptr)                      {}
 func (e *slowLogEncoder) AddReflected(string, interface{}) error          { return nil }
 func (e *slowLogEncoder) OpenNamespace(string)                            {}
+
+// slowLogJSONEncoder encodes a slow query entry as a json object in one line. The message of the entry
+// is expected to be a json object, the time of the entry is added as its first field.
+type slowLogJSONEncoder struct {
+	slowLogEncoder
+}
+
+func (e *slowLogJSONEncoder) EncodeEntry(entry zapcore.Entry, _ []zapcore.Field) (*buffer.Buffer, error) {
+	b := _pool.Get()
+	b.AppendString(slowLogJSONWithTime(entry.Time, entry.Message))
+	b.AppendByte('\n')
+	return b, nil
+}
+
+// slowLogJSONWithTime adds the time as the first field of the slow query entry in json.
+func slowLogJSONWithTime(t time.Time, entry string) string {
+	timeField := `{"Time":"` + t.Format(SlowLogTimeFormat) + `"`
+	if len(entry) > 2 && entry[0] == '{' {
+		return timeField + "," + entry[1:]
+	}
+	return timeField + "}"
+}
+
+func (e *slowLogJSONEncoder) Clone() zapcore.Encoder { return e }
@@ -103,10 +103,11 @@ func (a *LogOnExceed) GetPriority() int64 {
 // PanicOnExceed panics when memory usage exceeds memory quota.
 type PanicOnExceed struct {
 	BaseOOMAction
-	mutex   sync.Mutex // For synchronization.
-	acted   bool
-	ConnID  uint64
-	logHook func(uint64)
+	mutex    sync.Mutex // For synchronization.
+	acted    bool
+	ConnID   uint64
+	logHook  func(uint64)
+	dumpHook func(uint64) string
 }
 
 // SetLogHook sets a hook for PanicOnExceed.
@@ -114,6 +115,12 @@ func (a *PanicOnExceed) SetLogHook(hook func(uint64)) {
 	a.logHook = hook
 }
 
+// SetDumpHook sets a hook dumping the diagnostic information of the connection before the panic, the file it
+// returns is referenced in the panic message.
+func (a *PanicOnExceed) SetDumpHook(hook func(uint64) string) {
+	a.dumpHook = hook
+}
+
 // Action panics when memory usage exceeds memory quota.
 func (a *PanicOnExceed) Action(t *Tracker) {
 	a.mutex.Lock()
@@ -126,7 +133,13 @@ func (a *PanicOnExceed) Action(t *Tracker) {
 	if a.logHook != nil {
 		a.logHook(a.ConnID)
 	}
-	panic(PanicMemoryExceed + fmt.Sprintf("[conn_id=%d]", a.ConnID))
+	msg := PanicMemoryExceed + fmt.Sprintf("[conn_id=%d]", a.ConnID)
+	if a.dumpHook != nil {
+		if file := a.dumpHook(a.ConnID); file != "" {
+			msg += fmt.Sprintf("[diagnostic_file=%s]", file)
+		}
+	}
+	panic(msg)
 }
 
 // GetPriority get the priority of the Action
@@ -134,6 +147,36 @@ func (a *PanicOnExceed) GetPriority() int64 {
 	return DefPanicPriority
 }
 
+// PanicOnStorageExceed panics when the temporary storage usage of a statement exceeds its quota.
+type PanicOnStorageExceed struct {
+	BaseOOMAction
+	mutex  sync.Mutex // For synchronization.
+	acted  bool
+	ConnID uint64
+}
+
+// SetLogHook implements the ActionOnExceed interface.
+func (a *PanicOnStorageExceed) SetLogHook(hook func(uint64)) {}
+
+// Action panics when the temporary storage usage exceeds the quota.
+func (a *PanicOnStorageExceed) Action(t *Tracker) {
+	a.mutex.Lock()
+	if a.acted {
+		a.mutex.Unlock()
+		return
+	}
+	a.acted = true
+	a.mutex.Unlock()
+	logutil.BgLogger().Warn("temporary storage exceeds quota", zap.Uint64("conn", a.ConnID),
+		zap.Int64("consumed", t.BytesConsumed()), zap.Int64("quota", t.GetBytesLimit()))
+	panic(PanicStorageExceed + fmt.Sprintf("[conn_id=%d]", a.ConnID))
+}
+
+// GetPriority get the priority of the Action
+func (a *PanicOnStorageExceed) GetPriority() int64 {
+	return DefPanicPriority
+}
+
 var (
 	errMemExceedThreshold = dbterror.ClassUtil.NewStd(errno.ErrMemExceedThreshold)
 )
@@ -141,4 +184,6 @@ var (
 const (
 	// PanicMemoryExceed represents the panic message when out of memory quota.
 	PanicMemoryExceed string = "Out Of Memory Quota!"
+	// PanicStorageExceed represents the panic message when out of the temporary storage quota of a statement.
+	PanicStorageExceed string = "Out Of Temporary Storage Quota!"
 )
@@ -367,6 +367,31 @@ func (t *Tracker) SearchTrackerWithoutLock(label int) *Tracker {
 	return nil
 }
 
+// TopConsumers returns at most n of the descendants of this Tracker which consume the most memory, in the
+// descending order of their consumption.
+func (t *Tracker) TopConsumers(n int) []*Tracker {
+	var descendants []*Tracker
+	t.collectDescendants(&descendants)
+	sort.SliceStable(descendants, func(i, j int) bool {
+		return descendants[i].BytesConsumed() > descendants[j].BytesConsumed()
+	})
+	if len(descendants) > n {
+		descendants = descendants[:n]
+	}
+	return descendants
+}
+
+func (t *Tracker) collectDescendants(descendants *[]*Tracker) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, children := range t.mu.children {
+		for _, child := range children {
+			*descendants = append(*descendants, child)
+			child.collectDescendants(descendants)
+		}
+	}
+}
+
 // String returns the string representation of this Tracker tree.
 func (t *Tracker) String() string {
 	buffer := bytes.NewBufferString("\n")
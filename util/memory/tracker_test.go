@@ -118,6 +118,30 @@ func TestOOMAction(t *testing.T) {
 	require.True(t, action3.called) // HardLimit
 }
 
+func TestPanicOnExceedDumpHook(t *testing.T) {
+	tracker := NewTracker(1, 100)
+	action := &PanicOnExceed{ConnID: 3}
+	var dumpedConnID uint64
+	action.SetDumpHook(func(connID uint64) string {
+		dumpedConnID = connID
+		return "/tmp/oom_diagnostic"
+	})
+	tracker.SetActionOnExceed(action)
+	require.PanicsWithValue(t, PanicMemoryExceed+"[conn_id=3][diagnostic_file=/tmp/oom_diagnostic]", func() {
+		tracker.Consume(10000)
+	})
+	require.Equal(t, uint64(3), dumpedConnID)
+
+	// The file is not referenced if it fails to be dumped.
+	tracker = NewTracker(1, 100)
+	action = &PanicOnExceed{ConnID: 3}
+	action.SetDumpHook(func(uint64) string { return "" })
+	tracker.SetActionOnExceed(action)
+	require.PanicsWithValue(t, PanicMemoryExceed+"[conn_id=3]", func() {
+		tracker.Consume(10000)
+	})
+}
+
 type mockAction struct {
 	BaseOOMAction
 	called   bool
@@ -257,6 +281,32 @@ func TestToString(t *testing.T) {
 `)
 }
 
+func TestTopConsumers(t *testing.T) {
+	parent := NewTracker(1, -1)
+	child1 := NewTracker(2, -1)
+	child2 := NewTracker(3, -1)
+	grandChild := NewTracker(4, -1)
+	child1.AttachTo(parent)
+	child2.AttachTo(parent)
+	grandChild.AttachTo(child1)
+
+	child1.Consume(100)
+	child2.Consume(200)
+	grandChild.Consume(300)
+
+	labels := func(trackers []*Tracker) []int {
+		var labels []int
+		for _, t := range trackers {
+			labels = append(labels, t.Label())
+		}
+		return labels
+	}
+	require.Equal(t, []int{2, 4, 3}, labels(parent.TopConsumers(3)))
+	require.Equal(t, []int{2, 4}, labels(parent.TopConsumers(2)))
+	require.Equal(t, []int{4}, labels(child1.TopConsumers(3)))
+	require.Empty(t, grandChild.TopConsumers(3))
+}
+
 func TestMaxConsumed(t *testing.T) {
 	r := NewTracker(1, -1)
 	c1 := NewTracker(2, -1)
@@ -0,0 +1,175 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netsim
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/tikv/client-go/v2/tikv"
+	"github.com/tikv/client-go/v2/tikvrpc"
+)
+
+// Simulation is the simulated network between TiDB and the stores, it's used to validate the behaviors of
+// the geo-distributed deployments, such as the stale read routing and the follower read, on a single machine.
+// The stores that are not specified are not delayed.
+type Simulation struct {
+	// RTT is the round-trip time of the stores, half of it is spent on the request and the other half
+	// is spent on the response.
+	RTT map[uint64]time.Duration
+	// Bandwidth is the bytes per second that can be transferred between TiDB and the stores.
+	Bandwidth map[uint64]uint64
+}
+
+// IsEmpty returns whether no store is delayed.
+func (s Simulation) IsEmpty() bool {
+	return len(s.RTT) == 0 && len(s.Bandwidth) == 0
+}
+
+// delay returns the time to transfer the message of the given size between TiDB and the store in one direction.
+func (s Simulation) delay(storeID uint64, size int) time.Duration {
+	d := s.RTT[storeID] / 2
+	if bandwidth := s.Bandwidth[storeID]; bandwidth > 0 && size > 0 {
+		d += time.Duration(float64(size) / float64(bandwidth) * float64(time.Second))
+	}
+	return d
+}
+
+// ParseRTT parses the round-trip time of the stores in the format of "store_id=duration,...", e.g. "1=50ms,2=100ms".
+func ParseRTT(s string) (map[uint64]time.Duration, error) {
+	rtt := make(map[uint64]time.Duration)
+	err := parseStoreValues(s, func(storeID uint64, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		if d < 0 {
+			return errors.Errorf("negative round-trip time %s of store %d", value, storeID)
+		}
+		rtt[storeID] = d
+		return nil
+	})
+	return rtt, err
+}
+
+// ParseBandwidth parses the bandwidth of the stores in the format of "store_id=bytes_per_second,...",
+// e.g. "1=1048576,2=10485760".
+func ParseBandwidth(s string) (map[uint64]uint64, error) {
+	bandwidth := make(map[uint64]uint64)
+	err := parseStoreValues(s, func(storeID uint64, value string) error {
+		b, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		bandwidth[storeID] = b
+		return nil
+	})
+	return bandwidth, err
+}
+
+func parseStoreValues(s string, fn func(storeID uint64, value string) error) error {
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return errors.Errorf("invalid store setting %s, the format should be store_id=value", item)
+		}
+		storeID, err := strconv.ParseUint(strings.TrimSpace(kv[0]), 10, 64)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = fn(storeID, strings.TrimSpace(kv[1])); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+type simulationCtxKeyType struct{}
+
+var simulationCtxKey = simulationCtxKeyType{}
+
+// WithSimulation binds the simulated network with ctx, the requests sent with ctx are delayed by the client
+// created by NewClient.
+func WithSimulation(ctx context.Context, s Simulation) context.Context {
+	if s.IsEmpty() {
+		return ctx
+	}
+	return context.WithValue(ctx, simulationCtxKey, s)
+}
+
+// GetSimulationFromCtx gets the simulated network bound by WithSimulation.
+func GetSimulationFromCtx(ctx context.Context) (Simulation, bool) {
+	s, ok := ctx.Value(simulationCtxKey).(Simulation)
+	return s, ok
+}
+
+type client struct {
+	tikv.Client
+}
+
+// NewClient wraps the KV client to delay the requests and the responses by the simulated network bound with
+// their contexts. It should only be used in the failpoint-enabled builds.
+func NewClient(c tikv.Client) tikv.Client {
+	return &client{Client: c}
+}
+
+// SendRequest implements tikv.Client.
+func (c *client) SendRequest(ctx context.Context, addr string, req *tikvrpc.Request, timeout time.Duration) (*tikvrpc.Response, error) {
+	s, ok := GetSimulationFromCtx(ctx)
+	if !ok {
+		return c.Client.SendRequest(ctx, addr, req, timeout)
+	}
+	storeID := req.Context.GetPeer().GetStoreId()
+	if err := sleep(ctx, s.delay(storeID, sizeOf(req.Req))); err != nil {
+		return nil, err
+	}
+	resp, err := c.Client.SendRequest(ctx, addr, req, timeout)
+	if err != nil {
+		return resp, err
+	}
+	// Only the first message is delayed for the streaming responses.
+	if err := sleep(ctx, s.delay(storeID, sizeOf(resp.Resp))); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func sizeOf(msg interface{}) int {
+	if m, ok := msg.(interface{ Size() int }); ok {
+		return m.Size()
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return errors.Trace(ctx.Err())
+	}
+}
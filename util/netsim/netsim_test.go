@@ -0,0 +1,104 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netsim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/kvrpcpb"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/client-go/v2/tikvrpc"
+)
+
+type mockClient struct {
+	sent int
+}
+
+func (c *mockClient) Close() error {
+	return nil
+}
+
+func (c *mockClient) SendRequest(ctx context.Context, addr string, req *tikvrpc.Request, timeout time.Duration) (*tikvrpc.Response, error) {
+	c.sent++
+	return &tikvrpc.Response{Resp: &kvrpcpb.GetResponse{}}, nil
+}
+
+func TestParse(t *testing.T) {
+	rtt, err := ParseRTT("")
+	require.NoError(t, err)
+	require.Empty(t, rtt)
+	rtt, err = ParseRTT("1=50ms, 2 = 1s")
+	require.NoError(t, err)
+	require.Equal(t, map[uint64]time.Duration{1: 50 * time.Millisecond, 2: time.Second}, rtt)
+	for _, s := range []string{"1", "a=50ms", "1=50", "1=-50ms"} {
+		_, err = ParseRTT(s)
+		require.Error(t, err, s)
+	}
+
+	bandwidth, err := ParseBandwidth("1=1048576,2=1024")
+	require.NoError(t, err)
+	require.Equal(t, map[uint64]uint64{1: 1048576, 2: 1024}, bandwidth)
+	for _, s := range []string{"1=1MB", "1=-1", "=1"} {
+		_, err = ParseBandwidth(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestClient(t *testing.T) {
+	mock := &mockClient{}
+	c := NewClient(mock)
+	newReq := func(storeID uint64, key []byte) *tikvrpc.Request {
+		return tikvrpc.NewRequest(tikvrpc.CmdGet, &kvrpcpb.GetRequest{Key: key}, kvrpcpb.Context{Peer: &metapb.Peer{StoreId: storeID}})
+	}
+	send := func(ctx context.Context, req *tikvrpc.Request) (time.Duration, error) {
+		start := time.Now()
+		_, err := c.SendRequest(ctx, "", req, time.Minute)
+		return time.Since(start), err
+	}
+
+	// The requests without the simulation are not delayed.
+	rtt := 200 * time.Millisecond
+	cost, err := send(context.Background(), newReq(1, []byte("k")))
+	require.NoError(t, err)
+	require.Less(t, cost, rtt)
+
+	s := Simulation{
+		RTT:       map[uint64]time.Duration{1: rtt},
+		Bandwidth: map[uint64]uint64{2: 1000},
+	}
+	ctx := WithSimulation(context.Background(), s)
+	cost, err = send(ctx, newReq(1, []byte("k")))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, cost, rtt)
+
+	// It takes at least 200ms to send 200 bytes with the bandwidth of 1000 bytes per second.
+	cost, err = send(ctx, newReq(2, make([]byte, 200)))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, cost, rtt)
+
+	cost, err = send(ctx, newReq(3, make([]byte, 200)))
+	require.NoError(t, err)
+	require.Less(t, cost, rtt)
+
+	// The delay is interrupted once the context is done.
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = send(ctx, newReq(1, []byte("k")))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 4, mock.sent)
+}
@@ -22,7 +22,10 @@ const (
 	Regions      = "/pd/api/v1/regions"
 	RegionByID   = "/pd/api/v1/region/id"
 	StoreRegions = "/pd/api/v1/regions/store"
+	ScanRegions  = "/pd/api/v1/regions/key"
 	Stores       = "/pd/api/v1/stores"
 	Status       = "/pd/api/v1/status"
 	Config       = "/pd/api/v1/config"
+	Operators    = "/pd/api/v1/operators"
+	GCSafePoint  = "/pd/api/v1/gc/safepoint"
 )
@@ -45,6 +45,8 @@ type ProcessInfo struct {
 	CurTxnStartTS    uint64
 	StmtCtx          *stmtctx.StatementContext
 	StatsInfo        func(interface{}) map[string]uint64
+	// ExplainAnalyzeRows renders the plan with its runtime stats collected so far.
+	ExplainAnalyzeRows func(interface{}, *execdetails.RuntimeStatsColl) [][]string
 	// MaxExecutionTime is the timeout for select statement, in milliseconds.
 	// If the query takes too long, kill it.
 	MaxExecutionTime uint64
@@ -66,6 +68,10 @@ func (pi *ProcessInfo) ToRowForShow(full bool) []interface{} {
 		}
 	}
 	t := uint64(time.Since(pi.Time) / time.Second)
+	state := serverStatus2Str(pi.State)
+	if pi.StmtCtx != nil && pi.StmtCtx.Queued.Load() {
+		state = "queued"
+	}
 	var db interface{}
 	if len(pi.DB) > 0 {
 		db = pi.DB
@@ -83,7 +89,7 @@ func (pi *ProcessInfo) ToRowForShow(full bool) []interface{} {
 		db,
 		mysql.Command2Str[pi.Command],
 		t,
-		serverStatus2Str(pi.State),
+		state,
 		info,
 	}
 }
@@ -28,6 +28,7 @@ type conditionChecker struct {
 	checkerCol    *expression.Column
 	shouldReserve bool // check if a access condition should be reserved in filter conditions.
 	length        int
+	sortKeyLike   bool // check if LIKE can be used under non-binary collations, see sortKeyOption.
 }
 
 func (c *conditionChecker) check(condition expression.Expression) bool {
@@ -112,15 +113,19 @@ func (c *conditionChecker) checkScalarFunction(scalar *expression.ScalarFunction
 
 func (c *conditionChecker) checkLikeFunc(scalar *expression.ScalarFunction) bool {
 	_, collation := scalar.CharsetAndCollation()
-	if collate.NewCollationEnabled() && !collate.IsBinCollation(collation) {
+	onSortKey := collate.NewCollationEnabled() && !collate.IsBinCollation(collation)
+	if onSortKey {
 		// The algorithm constructs the range in byte-level: for example, ab% is mapped to [ab, ac] by adding 1 to the last byte.
 		// However, this is incorrect for non-binary collation strings because the sort key order is not the same as byte order.
 		// For example, "`%" is mapped to the range [`, a](where ` is 0x60 and a is 0x61).
 		// Because the collation utf8_general_ci is case-insensitive, a and A have the same sort key.
 		// Finally, the range comes to be [`, A], which is actually an empty range.
 		// See https://github.com/pingcap/tidb/issues/31174 for more details.
-		// In short, when the column type is non-binary collation string, we cannot use `like` expressions to generate the range.
-		return false
+		// In short, when the column type is non-binary collation string, the range can only be built on the sort keys,
+		// and the condition should be reserved because the range is wider than it.
+		if !c.sortKeyLike || !types.IsString(scalar.GetArgs()[0].GetType().Tp) {
+			return false
+		}
 	}
 	if !collate.CompatibleCollate(scalar.GetArgs()[0].GetType().Collate, collation) {
 		return false
@@ -141,6 +146,7 @@ func (c *conditionChecker) checkLikeFunc(scalar *expression.ScalarFunction) bool
 		return false
 	}
 	if len(patternStr) == 0 {
+		c.shouldReserve = c.shouldReserve || onSortKey
 		return true
 	}
 	escape := byte(scalar.GetArgs()[2].(*expression.Constant).Value.GetInt64())
@@ -176,6 +182,7 @@ func (c *conditionChecker) checkLikeFunc(scalar *expression.ScalarFunction) bool
 			break
 		}
 	}
+	c.shouldReserve = c.shouldReserve || onSortKey
 	return true
 }
 
@@ -22,6 +22,7 @@ import (
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/planner/util/fixcontrol"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/types"
@@ -312,6 +313,7 @@ func (d *rangeDetacher) detachCNFCondAndBuildRangeForIndex(conditions []expressi
 		checkerCol:    d.cols[eqOrInCount],
 		length:        d.lengths[eqOrInCount],
 		shouldReserve: d.lengths[eqOrInCount] != types.UnspecifiedLength,
+		sortKeyLike:   d.sortKeyLike,
 	}
 	if considerDNF {
 		pointRes, offset, columnValues, err := extractIndexPointRangesForCNF(d.sctx, conditions, d.cols, d.lengths)
@@ -340,7 +342,15 @@ func (d *rangeDetacher) detachCNFCondAndBuildRangeForIndex(conditions []expressi
 		if eqOrInCount > 0 {
 			newCols := d.cols[eqOrInCount:]
 			newLengths := d.lengths[eqOrInCount:]
-			tailRes, err := DetachCondAndBuildRangeForIndex(d.sctx, newConditions, newCols, newLengths)
+			tailDetacher := &rangeDetacher{
+				sctx:             d.sctx,
+				allConds:         newConditions,
+				cols:             newCols,
+				lengths:          newLengths,
+				mergeConsecutive: true,
+				sortKeyLike:      d.sortKeyLike,
+			}
+			tailRes, err := tailDetacher.detachCondAndBuildRangeForCols()
 			if err != nil {
 				return nil, err
 			}
@@ -617,6 +627,7 @@ func (d *rangeDetacher) detachDNFCondAndBuildRangeForIndex(condition *expression
 		checkerCol:    d.cols[0],
 		shouldReserve: d.lengths[0] != types.UnspecifiedLength,
 		length:        d.lengths[0],
+		sortKeyLike:   d.sortKeyLike,
 	}
 	rb := builder{sc: d.sctx.GetSessionVars().StmtCtx}
 	dnfItems := expression.FlattenDNFConditions(condition)
@@ -667,11 +678,20 @@ func (d *rangeDetacher) detachDNFCondAndBuildRangeForIndex(condition *expression
 				hasResidual = true
 				firstColumnChecker.shouldReserve = d.lengths[0] != types.UnspecifiedLength
 			}
-			points := rb.build(item, collate.GetCollator(newTpSlice[0].Collate))
-			ranges, err := points2Ranges(d.sctx, points, newTpSlice[0])
+			tp := newTpSlice[0]
+			if rb.sortKey = d.newSortKeyOption(0, tp, []expression.Expression{item}); rb.sortKey != nil {
+				tp = rb.sortKey.fieldType()
+			}
+			points := rb.build(item, collate.GetCollator(tp.Collate))
+			ranges, err := points2Ranges(d.sctx, points, tp)
 			if err != nil {
 				return nil, nil, nil, false, errors.Trace(err)
 			}
+			// Take prefix index into consideration. The ranges built on the sort keys have been cut when
+			// building the points, and the ranges of the CNF items above have been fixed as well.
+			if rb.sortKey == nil && hasPrefix(d.lengths) {
+				fixPrefixColRange(ranges, d.lengths, newTpSlice)
+			}
 			totalRanges = append(totalRanges, ranges...)
 			newAccessItems = append(newAccessItems, item)
 			if i == 0 {
@@ -691,10 +711,6 @@ func (d *rangeDetacher) detachDNFCondAndBuildRangeForIndex(condition *expression
 		}
 	}
 
-	// Take prefix index into consideration.
-	if hasPrefix(d.lengths) {
-		fixPrefixColRange(totalRanges, d.lengths, newTpSlice)
-	}
 	totalRanges, err := UnionRanges(d.sctx, totalRanges, d.mergeConsecutive)
 	if err != nil {
 		return nil, nil, nil, false, errors.Trace(err)
@@ -755,6 +771,7 @@ func DetachCondAndBuildRangeForIndex(sctx sessionctx.Context, conditions []expre
 		cols:             cols,
 		lengths:          lengths,
 		mergeConsecutive: true,
+		sortKeyLike:      sortKeyLikeEnabled(sctx),
 	}
 	return d.detachCondAndBuildRangeForCols()
 }
@@ -765,6 +782,15 @@ type rangeDetacher struct {
 	cols             []*expression.Column
 	lengths          []int
 	mergeConsecutive bool
+	// sortKeyLike means LIKE can be used to build the ranges of the string columns under non-binary collations,
+	// the ranges of such columns are built on the sort keys then, see sortKeyOption.
+	sortKeyLike bool
+}
+
+// sortKeyLikeEnabled returns whether the ranges of LIKE are built on the sort keys under non-binary collations,
+// which is controlled by the fix 738.
+func sortKeyLikeEnabled(sctx sessionctx.Context) bool {
+	return fixcontrol.GetBoolWithDefault(sctx.GetSessionVars().OptimizerFixControl, fixcontrol.Fix738, false)
 }
 
 func (d *rangeDetacher) detachCondAndBuildRangeForCols() (*DetachRangeResult, error) {
@@ -808,6 +834,7 @@ func DetachSimpleCondAndBuildRangeForIndex(sctx sessionctx.Context, conditions [
 		cols:             cols,
 		lengths:          lengths,
 		mergeConsecutive: true,
+		sortKeyLike:      sortKeyLikeEnabled(sctx),
 	}
 	res, err := d.detachCNFCondAndBuildRangeForIndex(conditions, newTpSlice, false)
 	return res.Ranges, res.AccessConds, err
@@ -23,7 +23,9 @@ import (
 	"github.com/pingcap/tidb/errno"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/charset"
 	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
@@ -183,6 +185,49 @@ func NullRange() []*Range {
 type builder struct {
 	err error
 	sc  *stmtctx.StatementContext
+	// sortKey is set when the points are built on the sort keys of the column, see sortKeyOption.
+	sortKey *sortKeyOption
+}
+
+// sortKeyOption makes the builder build the points of a string column under a non-binary collation on
+// the sort keys of the collation, which is required to build the range of LIKE: the order of such strings
+// is not the order of their bytes, so `ab%` can't be mapped to [ab, ac) by adding 1 to the last byte.
+// See https://github.com/pingcap/tidb/issues/31174 for more details.
+type sortKeyOption struct {
+	sctx sessionctx.Context
+	// tp is the type of the column.
+	tp *types.FieldType
+	// prefixLen is the length of the prefix index column, the values are cut before they are converted.
+	prefixLen int
+}
+
+// fieldType returns the type of the points built on the sort keys.
+func (o *sortKeyOption) fieldType() *types.FieldType {
+	tp := types.NewFieldTypeWithCollation(mysql.TypeVarString, charset.CollationBin, types.UnspecifiedLength)
+	tp.Charset = charset.CharsetBin
+	return tp
+}
+
+// convert converts the value of the point to its sort key. The same as fixPrefixColRange, the point
+// becomes inclusive if the value is cut by the prefix length.
+func (o *sortKeyOption) convert(p *point) (*point, error) {
+	switch p.value.Kind() {
+	case types.KindNull, types.KindMinNotNull, types.KindMaxValue:
+		return p, nil
+	}
+	casted, err := convertPoint(o.sctx, p, o.tp)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	np := casted.Clone(casted.value)
+	if o.prefixLen != types.UnspecifiedLength {
+		cut := CutDatumByPrefixLen(&np.value, o.prefixLen, o.tp)
+		if cut || (np.start && ReachPrefixLen(&np.value, o.prefixLen, o.tp)) {
+			np.excl = false
+		}
+	}
+	np.value = types.NewBytesDatum(collate.GetCollator(o.tp.Collate).Key(np.value.GetString()))
+	return np, nil
 }
 
 func (r *builder) build(expr expression.Expression, collator collate.Collator) []*point {
@@ -190,7 +235,16 @@ func (r *builder) build(expr expression.Expression, collator collate.Collator) [
 	case *expression.Column:
 		return r.buildFromColumn(x)
 	case *expression.ScalarFunction:
-		return r.buildFromScalarFunc(x, collator)
+		points := r.buildFromScalarFunc(x, collator)
+		if r.sortKey == nil || r.err != nil {
+			return points
+		}
+		switch x.FuncName.L {
+		case ast.LogicAnd, ast.LogicOr, ast.Like:
+			// These points are already built on the sort keys.
+			return points
+		}
+		return r.convertToSortKeys(points)
 	case *expression.Constant:
 		return r.buildFromConstant(x)
 	}
@@ -198,6 +252,18 @@ func (r *builder) build(expr expression.Expression, collator collate.Collator) [
 	return getFullRange()
 }
 
+func (r *builder) convertToSortKeys(points []*point) []*point {
+	for i, p := range points {
+		np, err := r.sortKey.convert(p)
+		if err != nil {
+			r.err = err
+			return getFullRange()
+		}
+		points[i] = np
+	}
+	return points
+}
+
 func (r *builder) buildFromConstant(expr *expression.Constant) []*point {
 	dt, err := expr.Eval(chunk.Row{})
 	if err != nil {
@@ -648,6 +714,9 @@ func (r *builder) newBuildFromPatternLike(expr *expression.ScalarFunction) []*po
 		return getFullRange()
 	}
 	if pattern == "" {
+		if r.sortKey != nil {
+			return r.buildSortKeyFromPatternLike(nil, true)
+		}
 		startPoint := &point{value: types.NewStringDatum(""), start: true}
 		endPoint := &point{value: types.NewStringDatum("")}
 		return []*point{startPoint, endPoint}
@@ -688,6 +757,9 @@ func (r *builder) newBuildFromPatternLike(expr *expression.ScalarFunction) []*po
 	if len(lowValue) == 0 {
 		return []*point{{value: types.MinNotNullDatum(), start: true}, {value: types.MaxValueDatum()}}
 	}
+	if r.sortKey != nil {
+		return r.buildSortKeyFromPatternLike(lowValue, isExactMatch)
+	}
 	if isExactMatch {
 		val := types.NewCollationStringDatum(string(lowValue), tpOfPattern.Collate)
 		return []*point{{value: val, start: true}, {value: val}}
@@ -714,6 +786,39 @@ func (r *builder) newBuildFromPatternLike(expr *expression.ScalarFunction) []*po
 	return []*point{startPoint, endPoint}
 }
 
+// buildSortKeyFromPatternLike builds the points of LIKE on the sort keys. The sort key of a value matching
+// the pattern always starts with the sort key of the prefix, so the range is [key, key+1) where key+1 is
+// got by adding 1 to the last byte of the sort key. The pattern is not always covered by the range, e.g.
+// 'ab ' and 'ab' have the same sort key under PAD SPACE collations, so LIKE is still kept as a filter.
+func (r *builder) buildSortKeyFromPatternLike(prefix []byte, isExactMatch bool) []*point {
+	val := types.NewCollationStringDatum(string(prefix), r.sortKey.tp.Collate)
+	if r.sortKey.prefixLen != types.UnspecifiedLength && CutDatumByPrefixLen(&val, r.sortKey.prefixLen, r.sortKey.tp) {
+		isExactMatch = false
+	}
+	lowValue := collate.GetCollator(r.sortKey.tp.Collate).Key(val.GetString())
+	startPoint := &point{value: types.NewBytesDatum(lowValue), start: true}
+	endPoint := &point{excl: true}
+	if isExactMatch {
+		// [key, key\x00) only contains the key itself. It's not a point range on purpose, otherwise it may
+		// be converted to a point get, which looks up the index by the values rather than their sort keys.
+		highValue := make([]byte, len(lowValue), len(lowValue)+1)
+		copy(highValue, lowValue)
+		endPoint.value = types.NewBytesDatum(append(highValue, 0))
+		return []*point{startPoint, endPoint}
+	}
+	highValue := make([]byte, len(lowValue))
+	copy(highValue, lowValue)
+	endPoint.value = types.MaxValueDatum()
+	for i := len(highValue) - 1; i >= 0; i-- {
+		highValue[i]++
+		if highValue[i] != 0 {
+			endPoint.value = types.NewBytesDatum(highValue[:i+1])
+			break
+		}
+	}
+	return []*point{startPoint, endPoint}
+}
+
 func (r *builder) buildFromNot(expr *expression.ScalarFunction) []*point {
 	switch n := expr.FuncName.L; n {
 	case ast.IsTruthWithoutNull:
@@ -386,19 +386,26 @@ func (d *rangeDetacher) buildCNFIndexRange(newTp []*types.FieldType,
 			return nil, errors.Trace(err)
 		}
 	}
+	var rangeTp *types.FieldType
+	if eqAndInCount < len(d.cols) {
+		rangeTp = newTp[eqAndInCount]
+		if rb.sortKey = d.newSortKeyOption(eqAndInCount, rangeTp, accessCondition[eqAndInCount:]); rb.sortKey != nil {
+			rangeTp = rb.sortKey.fieldType()
+		}
+	}
 	rangePoints := getFullRange()
 	// Build rangePoints for non-equal access conditions.
 	for i := eqAndInCount; i < len(accessCondition); i++ {
-		collator := collate.GetCollator(newTp[eqAndInCount].Collate)
+		collator := collate.GetCollator(rangeTp.Collate)
 		rangePoints = rb.intersection(rangePoints, rb.build(accessCondition[i], collator), collator)
 		if rb.err != nil {
 			return nil, errors.Trace(rb.err)
 		}
 	}
 	if eqAndInCount == 0 {
-		ranges, err = points2Ranges(d.sctx, rangePoints, newTp[0])
+		ranges, err = points2Ranges(d.sctx, rangePoints, rangeTp)
 	} else if eqAndInCount < len(accessCondition) {
-		ranges, err = appendPoints2Ranges(d.sctx, ranges, rangePoints, newTp[eqAndInCount])
+		ranges, err = appendPoints2Ranges(d.sctx, ranges, rangePoints, rangeTp)
 	}
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -406,7 +413,13 @@ func (d *rangeDetacher) buildCNFIndexRange(newTp []*types.FieldType,
 
 	// Take prefix index into consideration.
 	if hasPrefix(d.lengths) {
-		if fixPrefixColRange(ranges, d.lengths, newTp) {
+		lengths := d.lengths
+		if rb.sortKey != nil {
+			// The values of the column have been cut before they are converted to the sort keys.
+			lengths = append([]int(nil), d.lengths...)
+			lengths[eqAndInCount] = types.UnspecifiedLength
+		}
+		if fixPrefixColRange(ranges, lengths, newTp) || rb.sortKey != nil {
 			ranges, err = UnionRanges(d.sctx, ranges, d.mergeConsecutive)
 			if err != nil {
 				return nil, errors.Trace(err)
@@ -417,6 +430,36 @@ func (d *rangeDetacher) buildCNFIndexRange(newTp []*types.FieldType,
 	return ranges, nil
 }
 
+// newSortKeyOption returns a sortKeyOption if the ranges of the column should be built on the sort keys,
+// it's required when LIKE is used to build the range of a string column under a non-binary collation.
+func (d *rangeDetacher) newSortKeyOption(offset int, tp *types.FieldType, conds []expression.Expression) *sortKeyOption {
+	if !d.sortKeyLike || !collate.NewCollationEnabled() || collate.IsBinCollation(tp.Collate) || !types.IsString(tp.Tp) {
+		return nil
+	}
+	for _, cond := range conds {
+		if containsLike(cond) {
+			return &sortKeyOption{sctx: d.sctx, tp: tp, prefixLen: d.lengths[offset]}
+		}
+	}
+	return nil
+}
+
+func containsLike(expr expression.Expression) bool {
+	sf, ok := expr.(*expression.ScalarFunction)
+	if !ok {
+		return false
+	}
+	if sf.FuncName.L == ast.Like {
+		return true
+	}
+	for _, arg := range sf.GetArgs() {
+		if containsLike(arg) {
+			return true
+		}
+	}
+	return false
+}
+
 type sortRange struct {
 	originalValue *Range
 	encodedStart  []byte
@@ -1369,6 +1369,8 @@ func TestIndexRange(t *testing.T) {
 
 	testKit := testkit.NewTestKit(t, store)
 	testKit.MustExec("use test")
+	// Build the ranges of LIKE on the sort keys under non-binary collations.
+	testKit.MustExec("set @@tidb_opt_fix_control = '738:ON'")
 	testKit.MustExec("drop table if exists t")
 	testKit.MustExec(`
 create table t(
@@ -1380,6 +1382,7 @@ create table t(
 	f varchar(10) collate utf8mb4_general_ci,
 	g enum('A','B','C') collate utf8mb4_general_ci,
 	h varchar(10) collate utf8_bin,
+	i varchar(10) collate utf8mb4_unicode_ci,
 	index idx_ab(a(50), b),
 	index idx_cb(c, a),
 	index idx_d(d(2)),
@@ -1387,7 +1390,8 @@ create table t(
 	index idx_f(f),
 	index idx_de(d(2), e),
 	index idx_g(g),
-	index idx_h(h(3))
+	index idx_h(h(3)),
+	index idx_i(i(2))
 )`)
 
 	tests := []struct {
@@ -1649,6 +1653,41 @@ create table t(
 			filterConds: "[eq(test.t.f, B)]",
 			resultStr:   "[[\"a\",\"a\"]]",
 		},
+		{
+			indexPos:    4,
+			exprStr:     "f like 'ab%'",
+			accessConds: "[like(test.t.f, ab%, 92)]",
+			filterConds: "[like(test.t.f, ab%, 92)]",
+			resultStr:   "[[0x00410042,0x00410043)]",
+		},
+		{
+			indexPos:    4,
+			exprStr:     "f like '`%'",
+			accessConds: "[like(test.t.f, `%, 92)]",
+			filterConds: "[like(test.t.f, `%, 92)]",
+			resultStr:   "[[0x0060,0x0061)]",
+		},
+		{
+			indexPos:    4,
+			exprStr:     "f like 'ab'",
+			accessConds: "[like(test.t.f, ab, 92)]",
+			filterConds: "[like(test.t.f, ab, 92)]",
+			resultStr:   "[[0x00410042,0x0041004200)]",
+		},
+		{
+			indexPos:    4,
+			exprStr:     "f like 'a_c' and f > 'AB'",
+			accessConds: "[like(test.t.f, a_c, 92) gt(test.t.f, AB)]",
+			filterConds: "[like(test.t.f, a_c, 92)]",
+			resultStr:   "[(0x00410042,0x0042)]",
+		},
+		{
+			indexPos:    4,
+			exprStr:     "f like 'ab%' or f = 'x'",
+			accessConds: "[or(like(test.t.f, ab%, 92), eq(test.t.f, x))]",
+			filterConds: "[or(like(test.t.f, ab%, 92), eq(test.t.f, x))]",
+			resultStr:   "[[0x00410042,0x00410043) [\"x\",\"x\"]]",
+		},
 		{
 			indexPos:    4,
 			exprStr:     "f like '@%' collate utf8mb4_bin",
@@ -1677,6 +1716,20 @@ create table t(
 			filterConds: "[like(test.t.h, ÿÿ%, 92)]",
 			resultStr:   "[[\"ÿÿ\",\"ÿ\xc3\xc0\")]", // The decoding error is ignored.
 		},
+		{
+			indexPos:    8,
+			exprStr:     "i like 'abc%'",
+			accessConds: "[like(test.t.i, abc%, 92)]",
+			filterConds: "[like(test.t.i, abc%, 92)]",
+			resultStr:   "[[0x0E330E4A,0x0E330E4B)]",
+		},
+		{
+			indexPos:    8,
+			exprStr:     "i > 'abc' and i like 'a%'",
+			accessConds: "[gt(test.t.i, abc) like(test.t.i, a%, 92)]",
+			filterConds: "[gt(test.t.i, abc) like(test.t.i, a%, 92)]",
+			resultStr:   "[[0x0E330E4A,0x0E34)]",
+		},
 	}
 
 	ctx := context.Background()
@@ -382,6 +382,14 @@ func addInfo(addTo *stmtSummaryByDigestElement, addWith *stmtSummaryByDigestElem
 	addTo.sumPDTotal += addWith.sumPDTotal
 	addTo.sumBackoffTotal += addWith.sumBackoffTotal
 	addTo.sumWriteSQLRespTotal += addWith.sumWriteSQLRespTotal
+	addTo.sumClientStallTotal += addWith.sumClientStallTotal
+	if addTo.maxClientStallTotal < addWith.maxClientStallTotal {
+		addTo.maxClientStallTotal = addWith.maxClientStallTotal
+	}
+	addTo.sumTiDBExecTime += addWith.sumTiDBExecTime
+	if addTo.maxTiDBExecTime < addWith.maxTiDBExecTime {
+		addTo.maxTiDBExecTime = addWith.maxTiDBExecTime
+	}
 
 	addTo.sumErrors += addWith.sumErrors
 }
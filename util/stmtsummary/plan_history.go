@@ -0,0 +1,233 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmtsummary
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/hack"
+	"github.com/pingcap/tidb/util/kvcache"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/plancodec"
+	"go.uber.org/zap"
+)
+
+// planHistoryKey is the key of planHistory.digests.
+type planHistoryKey struct {
+	schemaName string
+	digest     string
+	hash       []byte
+}
+
+// Hash implements SimpleLRUCache.Key.
+func (key *planHistoryKey) Hash() []byte {
+	if len(key.hash) == 0 {
+		key.hash = make([]byte, 0, len(key.schemaName)+len(key.digest))
+		key.hash = append(key.hash, hack.Slice(key.digest)...)
+		key.hash = append(key.hash, hack.Slice(key.schemaName)...)
+	}
+	return key.hash
+}
+
+// planHistory keeps the last distinct plans of each SQL digest, so the plan changes of the statements can be
+// detected and compared. Unlike the summaries, the plans don't expire with the refresh interval, they are only
+// evicted by the newer plans of the same SQL digest or the other SQL digests.
+// The internal statements are not recorded.
+type planHistory struct {
+	// It's rare to read concurrently, so RWMutex is not needed.
+	sync.Mutex
+	digests *kvcache.SimpleLRUCache
+}
+
+// planHistoryByDigest is the plans of a SQL digest.
+type planHistoryByDigest struct {
+	sync.Mutex
+	schemaName    string
+	digest        string
+	normalizedSQL string
+	// plans are ordered by the last seen time, the least recently seen ones are evicted first.
+	plans []*planHistoryElement
+}
+
+// planHistoryElement is the stats of a plan of a SQL digest since the plan is seen.
+type planHistoryElement struct {
+	planDigest string
+	// plan is encoded, it's decoded when it's read.
+	plan             string
+	firstSeen        time.Time
+	lastSeen         time.Time
+	execCount        int64
+	sumErrors        int64
+	sumLatency       time.Duration
+	maxLatency       time.Duration
+	minLatency       time.Duration
+	sumProcessedKeys int64
+	sumResultRows    int64
+	sumMem           int64
+}
+
+func newPlanHistory(maxStmtCount uint) *planHistory {
+	return &planHistory{digests: kvcache.NewSimpleLRUCache(maxStmtCount, 0, 0)}
+}
+
+// add records the execution of the statement to the plan history, keeping at most size plans of its SQL digest.
+func (ph *planHistory) add(sei *StmtExecInfo, size int) {
+	if size <= 0 || sei.IsInternal {
+		return
+	}
+	key := &planHistoryKey{schemaName: sei.SchemaName, digest: sei.Digest}
+	key.Hash()
+	history := func() *planHistoryByDigest {
+		ph.Lock()
+		defer ph.Unlock()
+		value, ok := ph.digests.Get(key)
+		if ok {
+			return value.(*planHistoryByDigest)
+		}
+		history := &planHistoryByDigest{
+			schemaName:    sei.SchemaName,
+			digest:        sei.Digest,
+			normalizedSQL: formatSQL(sei.NormalizedSQL),
+		}
+		ph.digests.Put(key, history)
+		return history
+	}()
+	history.add(sei, size)
+}
+
+func (history *planHistoryByDigest) add(sei *StmtExecInfo, size int) {
+	planDigest := sei.PlanDigest
+	if sei.PlanDigestGen != nil && len(planDigest) == 0 {
+		// It comes here only when the plan is 'Point_Get'.
+		planDigest = sei.PlanDigestGen()
+	}
+
+	history.Lock()
+	defer history.Unlock()
+	idx := -1
+	for i, element := range history.plans {
+		if element.planDigest == planDigest {
+			idx = i
+			break
+		}
+	}
+	var element *planHistoryElement
+	if idx >= 0 {
+		element = history.plans[idx]
+		history.plans = append(history.plans[:idx], history.plans[idx+1:]...)
+	} else {
+		// The plan is generated only when it's not seen, because it's costly.
+		plan, _ := sei.PlanGenerator()
+		if len(plan) > maxEncodedPlanSizeInBytes {
+			plan = plancodec.PlanDiscardedEncoded
+		}
+		element = &planHistoryElement{
+			planDigest: planDigest,
+			plan:       plan,
+			firstSeen:  sei.StartTime,
+			minLatency: sei.TotalLatency,
+		}
+	}
+	element.add(sei)
+	history.plans = append(history.plans, element)
+	// `size` might be modified anytime, so check it every time.
+	if len(history.plans) > size {
+		history.plans = history.plans[len(history.plans)-size:]
+	}
+}
+
+func (element *planHistoryElement) add(sei *StmtExecInfo) {
+	if sei.StartTime.After(element.lastSeen) {
+		element.lastSeen = sei.StartTime
+	}
+	element.execCount++
+	if !sei.Succeed {
+		element.sumErrors++
+	}
+	element.sumLatency += sei.TotalLatency
+	if sei.TotalLatency > element.maxLatency {
+		element.maxLatency = sei.TotalLatency
+	}
+	if sei.TotalLatency < element.minLatency {
+		element.minLatency = sei.TotalLatency
+	}
+	if sei.ExecDetail.ScanDetail != nil {
+		element.sumProcessedKeys += sei.ExecDetail.ScanDetail.ProcessedKeys
+	}
+	element.sumResultRows += sei.ResultRows
+	element.sumMem += sei.MemMax
+}
+
+func (ph *planHistory) clear() {
+	ph.Lock()
+	defer ph.Unlock()
+	ph.digests.DeleteAll()
+}
+
+func (ph *planHistory) setCapacity(capacity uint) error {
+	ph.Lock()
+	defer ph.Unlock()
+	return ph.digests.SetCapacity(capacity)
+}
+
+// toDatum returns the rows of the plan history, ordered by the SQL digest and the first seen time of the plans.
+func (ph *planHistory) toDatum(tz *time.Location) [][]types.Datum {
+	ph.Lock()
+	values := ph.digests.Values()
+	ph.Unlock()
+
+	var rows [][]types.Datum
+	for _, value := range values {
+		history := value.(*planHistoryByDigest)
+		history.Lock()
+		plans := make([]planHistoryElement, 0, len(history.plans))
+		for _, element := range history.plans {
+			plans = append(plans, *element)
+		}
+		history.Unlock()
+		sort.Slice(plans, func(i, j int) bool { return plans[i].firstSeen.Before(plans[j].firstSeen) })
+		for _, element := range plans {
+			plan, err := plancodec.DecodePlan(element.plan)
+			if err != nil {
+				logutil.BgLogger().Error("decode plan in plan history failed", zap.String("plan", element.plan), zap.String("digest", history.digest), zap.Error(err))
+				plan = ""
+			}
+			rows = append(rows, types.MakeDatums(
+				convertEmptyToNil(history.schemaName),
+				history.digest,
+				history.normalizedSQL,
+				convertEmptyToNil(element.planDigest),
+				plan,
+				types.NewTime(types.FromGoTime(element.firstSeen.In(tz)), mysql.TypeTimestamp, 0),
+				types.NewTime(types.FromGoTime(element.lastSeen.In(tz)), mysql.TypeTimestamp, 0),
+				element.execCount,
+				element.sumErrors,
+				int64(element.sumLatency),
+				int64(element.maxLatency),
+				int64(element.minLatency),
+				avgInt(int64(element.sumLatency), element.execCount),
+				avgFloat(element.sumProcessedKeys, element.execCount),
+				avgFloat(element.sumResultRows, element.execCount),
+				avgInt(element.sumMem, element.execCount),
+			))
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i][1].GetString() < rows[j][1].GetString() })
+	return rows
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmtsummary
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanHistory(t *testing.T) {
+	ssMap := newStmtSummaryByDigestMap()
+	require.NoError(t, ssMap.SetPlanHistorySize(2))
+
+	addPlan := func(planDigest string, latency time.Duration, startTime time.Time) {
+		sei := generateAnyExecInfo()
+		sei.PlanDigest = planDigest
+		sei.TotalLatency = latency
+		sei.StartTime = startTime
+		ssMap.AddStatement(sei)
+	}
+	start := time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+	addPlan("plan1", 1000, start)
+	addPlan("plan1", 3000, start.Add(time.Second))
+	addPlan("plan2", 5000, start.Add(2*time.Second))
+
+	rows := ssMap.ToPlanHistoryDatum(time.UTC)
+	require.Len(t, rows, 2)
+	require.Equal(t, "digest", rows[0][1].GetString())
+	require.Equal(t, "plan1", rows[0][3].GetString())
+	require.Equal(t, "2022-01-01 10:00:00", rows[0][5].GetMysqlTime().String())
+	require.Equal(t, "2022-01-01 10:00:01", rows[0][6].GetMysqlTime().String())
+	require.Equal(t, int64(2), rows[0][7].GetInt64())
+	require.Equal(t, int64(4000), rows[0][9].GetInt64())
+	require.Equal(t, int64(3000), rows[0][10].GetInt64())
+	require.Equal(t, int64(1000), rows[0][11].GetInt64())
+	require.Equal(t, int64(2000), rows[0][12].GetInt64())
+	require.Equal(t, float64(500), rows[0][13].GetFloat64())
+	require.Equal(t, "plan2", rows[1][3].GetString())
+	require.Equal(t, int64(1), rows[1][7].GetInt64())
+
+	// The least recently seen plan is evicted.
+	addPlan("plan2", 5000, start.Add(3*time.Second))
+	addPlan("plan3", 7000, start.Add(4*time.Second))
+	rows = ssMap.ToPlanHistoryDatum(time.UTC)
+	require.Len(t, rows, 2)
+	require.Equal(t, "plan2", rows[0][3].GetString())
+	require.Equal(t, int64(2), rows[0][7].GetInt64())
+	require.Equal(t, "plan3", rows[1][3].GetString())
+
+	// The internal statements are not recorded.
+	require.NoError(t, ssMap.SetEnabledInternalQuery(true))
+	sei := generateAnyExecInfo()
+	sei.Digest = "internal"
+	sei.IsInternal = true
+	ssMap.AddStatement(sei)
+	require.Len(t, ssMap.ToPlanHistoryDatum(time.UTC), 2)
+
+	// No plan is recorded if the size is 0.
+	require.NoError(t, ssMap.SetPlanHistorySize(0))
+	sei = generateAnyExecInfo()
+	sei.Digest = "digest2"
+	ssMap.AddStatement(sei)
+	require.Len(t, ssMap.ToPlanHistoryDatum(time.UTC), 2)
+
+	ssMap.Clear()
+	require.Empty(t, ssMap.ToPlanHistoryDatum(time.UTC))
+}
+
+func TestPlanHistoryGeneratePlanOnce(t *testing.T) {
+	ph := newPlanHistory(10)
+	generated := 0
+	for i := 0; i < 3; i++ {
+		sei := generateAnyExecInfo()
+		sei.PlanGenerator = func() (string, string) {
+			generated++
+			return "", ""
+		}
+		ph.add(sei, 5)
+	}
+	// The plan is generated only when it's seen first.
+	require.Equal(t, 1, generated)
+	rows := ph.toDatum(time.UTC)
+	require.Len(t, rows, 1)
+	require.Equal(t, int64(3), rows[0][7].GetInt64())
+}
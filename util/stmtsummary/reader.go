@@ -291,6 +291,10 @@ const (
 	AvgPdTimeStr                      = "AVG_PD_TIME"
 	AvgBackoffTotalTimeStr            = "AVG_BACKOFF_TOTAL_TIME"
 	AvgWriteSQLRespTimeStr            = "AVG_WRITE_SQL_RESP_TIME"
+	AvgClientStallTimeStr             = "AVG_CLIENT_STALL_TIME"
+	MaxClientStallTimeStr             = "MAX_CLIENT_STALL_TIME"
+	AvgTiDBExecTimeStr                = "AVG_TIDB_EXEC_TIME"
+	MaxTiDBExecTimeStr                = "MAX_TIDB_EXEC_TIME"
 	MaxResultRowsStr                  = "MAX_RESULT_ROWS"
 	MinResultRowsStr                  = "MIN_RESULT_ROWS"
 	AvgResultRowsStr                  = "AVG_RESULT_ROWS"
@@ -546,16 +550,28 @@ var columnValueFactoryMap = map[string]columnValueFactory{
 		return ssElement.maxDisk
 	},
 	AvgKvTimeStr: func(_ *stmtSummaryReader, ssElement *stmtSummaryByDigestElement, _ *stmtSummaryByDigest) interface{} {
-		return avgInt(int64(ssElement.sumKVTotal), ssElement.commitCount)
+		return avgInt(int64(ssElement.sumKVTotal), ssElement.execCount)
 	},
 	AvgPdTimeStr: func(_ *stmtSummaryReader, ssElement *stmtSummaryByDigestElement, _ *stmtSummaryByDigest) interface{} {
-		return avgInt(int64(ssElement.sumPDTotal), ssElement.commitCount)
+		return avgInt(int64(ssElement.sumPDTotal), ssElement.execCount)
 	},
 	AvgBackoffTotalTimeStr: func(_ *stmtSummaryReader, ssElement *stmtSummaryByDigestElement, _ *stmtSummaryByDigest) interface{} {
-		return avgInt(int64(ssElement.sumBackoffTotal), ssElement.commitCount)
+		return avgInt(int64(ssElement.sumBackoffTotal), ssElement.execCount)
 	},
 	AvgWriteSQLRespTimeStr: func(_ *stmtSummaryReader, ssElement *stmtSummaryByDigestElement, _ *stmtSummaryByDigest) interface{} {
-		return avgInt(int64(ssElement.sumWriteSQLRespTotal), ssElement.commitCount)
+		return avgInt(int64(ssElement.sumWriteSQLRespTotal), ssElement.execCount)
+	},
+	AvgClientStallTimeStr: func(_ *stmtSummaryReader, ssElement *stmtSummaryByDigestElement, _ *stmtSummaryByDigest) interface{} {
+		return avgInt(int64(ssElement.sumClientStallTotal), ssElement.execCount)
+	},
+	MaxClientStallTimeStr: func(_ *stmtSummaryReader, ssElement *stmtSummaryByDigestElement, _ *stmtSummaryByDigest) interface{} {
+		return int64(ssElement.maxClientStallTotal)
+	},
+	AvgTiDBExecTimeStr: func(_ *stmtSummaryReader, ssElement *stmtSummaryByDigestElement, _ *stmtSummaryByDigest) interface{} {
+		return avgInt(int64(ssElement.sumTiDBExecTime), ssElement.execCount)
+	},
+	MaxTiDBExecTimeStr: func(_ *stmtSummaryReader, ssElement *stmtSummaryByDigestElement, _ *stmtSummaryByDigest) interface{} {
+		return int64(ssElement.maxTiDBExecTime)
 	},
 	MaxResultRowsStr: func(_ *stmtSummaryReader, ssElement *stmtSummaryByDigestElement, _ *stmtSummaryByDigest) interface{} {
 		return ssElement.maxResultRows
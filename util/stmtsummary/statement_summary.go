@@ -28,6 +28,7 @@ import (
 
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/execdetails"
 	"github.com/pingcap/tidb/util/hack"
 	"github.com/pingcap/tidb/util/kvcache"
@@ -78,9 +79,12 @@ type stmtSummaryByDigestMap struct {
 	optRefreshInterval     *atomic2.Int64
 	optHistorySize         *atomic2.Int32
 	optMaxSQLLength        *atomic2.Int32
+	optPlanHistorySize     *atomic2.Int32
 
 	// other stores summary of evicted data.
 	other *stmtSummaryByDigestEvicted
+	// plans stores the last distinct plans of each SQL digest.
+	plans *planHistory
 }
 
 // StmtSummaryByDigestMap is a global map containing all statement summaries.
@@ -192,6 +196,10 @@ type stmtSummaryByDigestElement struct {
 	sumPDTotal           time.Duration
 	sumBackoffTotal      time.Duration
 	sumWriteSQLRespTotal time.Duration
+	sumClientStallTotal  time.Duration
+	maxClientStallTotal  time.Duration
+	sumTiDBExecTime      time.Duration
+	maxTiDBExecTime      time.Duration
 	sumResultRows        int64
 	maxResultRows        int64
 	minResultRows        int64
@@ -264,7 +272,9 @@ func newStmtSummaryByDigestMap() *stmtSummaryByDigestMap {
 		optRefreshInterval:     atomic2.NewInt64(1800),
 		optHistorySize:         atomic2.NewInt32(24),
 		optMaxSQLLength:        atomic2.NewInt32(4096),
+		optPlanHistorySize:     atomic2.NewInt32(5),
 		other:                  ssbde,
+		plans:                  newPlanHistory(maxStmtCount),
 	}
 	newSsMap.summaryMap.SetOnEvict(func(k kvcache.Key, v kvcache.Value) {
 		historySize := newSsMap.historySize()
@@ -337,6 +347,7 @@ func (ssMap *stmtSummaryByDigestMap) AddStatement(sei *StmtExecInfo) {
 	// Lock a single entry, not the whole cache.
 	if summary != nil {
 		summary.add(sei, beginTime, intervalSeconds, historySize)
+		ssMap.plans.add(sei, ssMap.planHistorySize())
 	}
 }
 
@@ -347,6 +358,7 @@ func (ssMap *stmtSummaryByDigestMap) Clear() {
 
 	ssMap.summaryMap.DeleteAll()
 	ssMap.other.Clear()
+	ssMap.plans.clear()
 	ssMap.beginTimeForCurInterval = 0
 }
 
@@ -474,6 +486,9 @@ func (ssMap *stmtSummaryByDigestMap) SetMaxStmtCount(value uint) error {
 	// `optMaxStmtCount` and `ssMap` don't need to be strictly atomically updated.
 	ssMap.optMaxStmtCount.Store(uint32(value))
 
+	if err := ssMap.plans.setCapacity(value); err != nil {
+		return err
+	}
 	ssMap.Lock()
 	defer ssMap.Unlock()
 	return ssMap.summaryMap.SetCapacity(value)
@@ -496,6 +511,21 @@ func (ssMap *stmtSummaryByDigestMap) maxSQLLength() int {
 }
 
 // newStmtSummaryByDigest creates a stmtSummaryByDigest from StmtExecInfo.
+// SetPlanHistorySize sets the max number of the plans kept for each SQL digest in the plan history.
+func (ssMap *stmtSummaryByDigestMap) SetPlanHistorySize(value int) error {
+	ssMap.optPlanHistorySize.Store(int32(value))
+	return nil
+}
+
+func (ssMap *stmtSummaryByDigestMap) planHistorySize() int {
+	return int(ssMap.optPlanHistorySize.Load())
+}
+
+// ToPlanHistoryDatum returns the rows of the plan history, the times are in the time zone.
+func (ssMap *stmtSummaryByDigestMap) ToPlanHistoryDatum(tz *time.Location) [][]types.Datum {
+	return ssMap.plans.toDatum(tz)
+}
+
 func (ssbd *stmtSummaryByDigest) init(sei *StmtExecInfo, beginTime int64, intervalSeconds int64, historySize int) {
 	// Use "," to separate table names to support FIND_IN_SET.
 	var buffer bytes.Buffer
@@ -840,6 +870,15 @@ func (ssElement *stmtSummaryByDigestElement) add(sei *StmtExecInfo, intervalSeco
 	ssElement.sumPDTotal += time.Duration(atomic.LoadInt64(&sei.TiKVExecDetails.WaitPDRespDuration))
 	ssElement.sumBackoffTotal += time.Duration(atomic.LoadInt64(&sei.TiKVExecDetails.BackoffDuration))
 	ssElement.sumWriteSQLRespTotal += sei.StmtExecDetails.WriteSQLRespDuration
+	ssElement.sumClientStallTotal += sei.StmtExecDetails.ClientStallDuration
+	if sei.StmtExecDetails.ClientStallDuration > ssElement.maxClientStallTotal {
+		ssElement.maxClientStallTotal = sei.StmtExecDetails.ClientStallDuration
+	}
+	tidbExecTime := execdetails.TiDBExecDuration(sei.TotalLatency-sei.ParseLatency-sei.CompileLatency, &sei.StmtExecDetails, &sei.TiKVExecDetails)
+	ssElement.sumTiDBExecTime += tidbExecTime
+	if tidbExecTime > ssElement.maxTiDBExecTime {
+		ssElement.maxTiDBExecTime = tidbExecTime
+	}
 }
 
 // Truncate SQL to maxSQLLength.
@@ -1423,3 +1423,42 @@ func TestAccessPrivilege(t *testing.T) {
 	datums = reader.GetStmtSummaryHistoryRows()
 	require.Len(t, datums, loops)
 }
+
+func TestTimeBreakdown(t *testing.T) {
+	ssMap := newStmtSummaryByDigestMap()
+	now := time.Now().Unix()
+	ssMap.beginTimeForCurInterval = now + 60
+
+	columnNames := []string{AvgKvTimeStr, AvgWriteSQLRespTimeStr, AvgClientStallTimeStr, MaxClientStallTimeStr, AvgTiDBExecTimeStr, MaxTiDBExecTimeStr}
+	cols := make([]*model.ColumnInfo, len(columnNames))
+	for i := range columnNames {
+		cols[i] = &model.ColumnInfo{ID: int64(i), Name: model.NewCIStr(columnNames[i]), Offset: i}
+	}
+	reader := NewStmtSummaryReader(nil, true, cols, "", time.UTC)
+	reader.ssMap = ssMap
+
+	stmtExecInfo1 := generateAnyExecInfo()
+	stmtExecInfo1.TotalLatency = 10000
+	stmtExecInfo1.ParseLatency = 100
+	stmtExecInfo1.CompileLatency = 900
+	stmtExecInfo1.TiKVExecDetails.WaitKVRespDuration = 4000
+	stmtExecInfo1.TiKVExecDetails.WaitPDRespDuration = 500
+	stmtExecInfo1.TiKVExecDetails.BackoffDuration = 500
+	stmtExecInfo1.StmtExecDetails.WriteSQLRespDuration = 1000
+	stmtExecInfo1.StmtExecDetails.ClientStallDuration = 600
+	ssMap.AddStatement(stmtExecInfo1)
+	datums := reader.GetStmtSummaryCurrentRows()
+	require.Equal(t, 1, len(datums))
+	match(t, datums[0], 4000, 1000, 600, 600, 3000, 3000)
+
+	// The waits of the concurrent requests add up to more than the execution time.
+	stmtExecInfo2 := generateAnyExecInfo()
+	stmtExecInfo2.TotalLatency = 10000
+	stmtExecInfo2.ParseLatency = 100
+	stmtExecInfo2.CompileLatency = 900
+	stmtExecInfo2.TiKVExecDetails.WaitKVRespDuration = 20000
+	ssMap.AddStatement(stmtExecInfo2)
+	datums = reader.GetStmtSummaryCurrentRows()
+	require.Equal(t, 1, len(datums))
+	match(t, datums[0], 12000, 500, 300, 600, 1500, 3000)
+}
@@ -0,0 +1,161 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableworkload
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Read is the workload of a table read by a statement.
+type Read struct {
+	// TableID is the physical table ID, which is the partition ID if only a partition is read.
+	TableID int64
+	// IndexName is empty if the table is read without indexes.
+	IndexName string
+	// ScanKeys is the number of the keys scanned by the coprocessor, or the rows read by the point get.
+	ScanKeys int64
+	// CopTime is the processing time of the coprocessor tasks.
+	CopTime time.Duration
+}
+
+// TableWorkload is the workload of a physical table accumulated since the instance starts.
+type TableWorkload struct {
+	TableID       int64
+	ReadCount     int64
+	ScanKeys      int64
+	CopTime       time.Duration
+	WriteCount    int64
+	WriteRows     int64
+	IndexReads    map[string]int64
+	LastReadTime  time.Time
+	LastWriteTime time.Time
+	// IndexLastReadTime is when each index in IndexReads is read last time.
+	IndexLastReadTime map[string]time.Time
+}
+
+// HotIndex returns the most read index of the table, ties are broken by the name.
+func (w *TableWorkload) HotIndex() (name string, reads int64) {
+	for idx, cnt := range w.IndexReads {
+		if cnt > reads || (cnt == reads && idx < name) {
+			name, reads = idx, cnt
+		}
+	}
+	return name, reads
+}
+
+type tableWorkload struct {
+	sync.Mutex
+	TableWorkload
+}
+
+// tableWorkloads tracks the workload of all the tables accessed by the instance.
+var tableWorkloads = struct {
+	sync.RWMutex
+	tables map[int64]*tableWorkload
+}{
+	tables: make(map[int64]*tableWorkload),
+}
+
+func getTableWorkload(tableID int64) *tableWorkload {
+	tableWorkloads.RLock()
+	w, ok := tableWorkloads.tables[tableID]
+	tableWorkloads.RUnlock()
+	if ok {
+		return w
+	}
+	tableWorkloads.Lock()
+	defer tableWorkloads.Unlock()
+	if w, ok = tableWorkloads.tables[tableID]; !ok {
+		w = &tableWorkload{TableWorkload: TableWorkload{TableID: tableID}}
+		tableWorkloads.tables[tableID] = w
+	}
+	return w
+}
+
+// RecordReads records the tables read by a statement.
+func RecordReads(reads []Read) {
+	now := time.Now()
+	for _, read := range reads {
+		w := getTableWorkload(read.TableID)
+		w.Lock()
+		w.ReadCount++
+		w.ScanKeys += read.ScanKeys
+		w.CopTime += read.CopTime
+		if read.IndexName != "" {
+			if w.IndexReads == nil {
+				w.IndexReads = make(map[string]int64)
+				w.IndexLastReadTime = make(map[string]time.Time)
+			}
+			w.IndexReads[read.IndexName]++
+			w.IndexLastReadTime[read.IndexName] = now
+		}
+		w.LastReadTime = now
+		w.Unlock()
+	}
+}
+
+// RecordWrite records the rows of the table written by a committed transaction.
+func RecordWrite(tableID int64, rows int64) {
+	w := getTableWorkload(tableID)
+	w.Lock()
+	w.WriteCount++
+	w.WriteRows += rows
+	w.LastWriteTime = time.Now()
+	w.Unlock()
+}
+
+// Snapshot returns the workload of all the tables ordered by the table ID.
+func Snapshot() []TableWorkload {
+	tableWorkloads.RLock()
+	tables := make([]*tableWorkload, 0, len(tableWorkloads.tables))
+	for _, w := range tableWorkloads.tables {
+		tables = append(tables, w)
+	}
+	tableWorkloads.RUnlock()
+
+	result := make([]TableWorkload, 0, len(tables))
+	for _, w := range tables {
+		w.Lock()
+		item := w.TableWorkload
+		item.IndexReads = make(map[string]int64, len(w.IndexReads))
+		item.IndexLastReadTime = make(map[string]time.Time, len(w.IndexLastReadTime))
+		for idx, cnt := range w.IndexReads {
+			item.IndexReads[idx] = cnt
+			item.IndexLastReadTime[idx] = w.IndexLastReadTime[idx]
+		}
+		w.Unlock()
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].TableID < result[j].TableID })
+	return result
+}
+
+// Remove stops tracking the tables, e.g. they are dropped.
+func Remove(tableIDs ...int64) {
+	tableWorkloads.Lock()
+	defer tableWorkloads.Unlock()
+	for _, id := range tableIDs {
+		delete(tableWorkloads.tables, id)
+	}
+}
+
+// Reset clears the workload of all the tables.
+func Reset() {
+	tableWorkloads.Lock()
+	defer tableWorkloads.Unlock()
+	tableWorkloads.tables = make(map[int64]*tableWorkload)
+}
@@ -0,0 +1,71 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableworkload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableWorkload(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	RecordReads([]Read{
+		{TableID: 2, ScanKeys: 10, CopTime: time.Millisecond},
+		{TableID: 1, IndexName: "idx_b", ScanKeys: 3, CopTime: time.Millisecond},
+		{TableID: 1, IndexName: "idx_a", ScanKeys: 4},
+	})
+	RecordReads([]Read{{TableID: 1, IndexName: "idx_b", ScanKeys: 1}})
+	RecordWrite(2, 5)
+	RecordWrite(2, 1)
+
+	workloads := Snapshot()
+	require.Len(t, workloads, 2)
+	w := workloads[0]
+	require.Equal(t, int64(1), w.TableID)
+	require.Equal(t, int64(3), w.ReadCount)
+	require.Equal(t, int64(8), w.ScanKeys)
+	require.Equal(t, time.Millisecond, w.CopTime)
+	require.Equal(t, map[string]int64{"idx_a": 1, "idx_b": 2}, w.IndexReads)
+	require.Len(t, w.IndexLastReadTime, 2)
+	require.Equal(t, w.LastReadTime, w.IndexLastReadTime["idx_b"])
+	require.False(t, w.LastReadTime.IsZero())
+	require.True(t, w.LastWriteTime.IsZero())
+	name, reads := w.HotIndex()
+	require.Equal(t, "idx_b", name)
+	require.Equal(t, int64(2), reads)
+
+	w = workloads[1]
+	require.Equal(t, int64(2), w.TableID)
+	require.Equal(t, int64(1), w.ReadCount)
+	require.Equal(t, int64(2), w.WriteCount)
+	require.Equal(t, int64(6), w.WriteRows)
+	require.False(t, w.LastWriteTime.IsZero())
+	name, reads = w.HotIndex()
+	require.Equal(t, "", name)
+	require.Equal(t, int64(0), reads)
+
+	// The snapshot is not changed by the later records.
+	RecordReads([]Read{{TableID: 1, IndexName: "idx_a"}})
+	require.Equal(t, int64(1), workloads[0].IndexReads["idx_a"])
+
+	Remove(1)
+	workloads = Snapshot()
+	require.Len(t, workloads, 1)
+	require.Equal(t, int64(2), workloads[0].TableID)
+}
@@ -27,6 +27,9 @@ type PlanTrace struct {
 	ProperType string       `json:"property"`
 	// ExplainInfo should be implemented by each implemented Plan
 	ExplainInfo string `json:"info"`
+	// CrossoverSelectivity is the selectivity of the index ranges at which the double read costs the same as the
+	// table scan, it's only recorded for the double read candidates.
+	CrossoverSelectivity float64 `json:"crossover_selectivity,omitempty"`
 }
 
 // LogicalOptimizeTracer indicates the trace for the whole logicalOptimize processing